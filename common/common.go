@@ -17,6 +17,9 @@ type Transfer struct {
 	IsNative     bool   `json:"isNative"`
 	TokenAddress string `json:"tokenAddress"`
 	ScaledAmount string `json:"scaledAmount"`
+	// DestinationTag identifies the beneficiary behind a shared address on
+	// chains that support it (e.g. XRPL)
+	DestinationTag *uint32 `json:"destinationTag,omitempty"`
 }
 
 func FileExists(name string) (bool, error) {
@@ -58,6 +61,9 @@ const (
 	CurveStellar   Curve = "stellar_eddsa"
 	CurveRipple    Curve = "ripple_eddsa"
 	CurveCardano   Curve = "cardano_eddsa"
+	// CurveFrost keys are Ed25519 keys generated and used with FROST (RFC 9591) instead of
+	// tss-lib's EdDSA. They sign for the same chains as CurveEddsa keys.
+	CurveFrost Curve = "frost"
 )
 
 func ParseCurve(curve string) (Curve, error) {
@@ -78,6 +84,8 @@ func ParseCurve(curve string) (Curve, error) {
 		return CurveRipple, nil
 	case "cardano_eddsa":
 		return CurveCardano, nil
+	case "frost":
+		return CurveFrost, nil
 	default:
 		return "", fmt.Errorf("invalid curve")
 	}
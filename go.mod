@@ -3,10 +3,13 @@ module github.com/StripChain/strip-node
 go 1.24.2
 
 require (
+	filippo.io/age v1.2.1
+	filippo.io/edwards25519 v1.1.0
 	github.com/algorand/go-algorand-sdk v1.24.0
 	github.com/aptos-labs/aptos-go-sdk v1.6.2
 	github.com/aws/aws-sdk-go-v2 v1.36.3
 	github.com/aws/aws-sdk-go-v2/config v1.18.45
+	github.com/aws/aws-sdk-go-v2/service/kms v1.38.1
 	github.com/aws/aws-sdk-go-v2/service/secretsmanager v1.35.4
 	github.com/blockfrost/blockfrost-go v0.3.0
 	github.com/bnb-chain/tss-lib/v2 v2.0.2
@@ -15,11 +18,11 @@ require (
 	github.com/btcsuite/btcd/btcutil v1.1.6
 	github.com/btcsuite/btcd/chaincfg/chainhash v1.1.0
 	github.com/coming-chat/go-sui/v2 v2.0.1
-	github.com/davecgh/go-spew v1.1.2-0.20180830191138-d8f796af33cc
 	github.com/decred/dcrd/dcrec/edwards/v2 v2.0.3
 	github.com/decred/dcrd/dcrec/secp256k1/v4 v4.4.0
 	github.com/echovl/cardano-go v0.1.14
 	github.com/ethereum/go-ethereum v1.15.8
+	github.com/fardream/go-bcs v0.8.7
 	github.com/fxamacker/cbor/v2 v2.8.0
 	github.com/gagliardetto/binary v0.8.0
 	github.com/gagliardetto/solana-go v1.12.0
@@ -42,7 +45,6 @@ require (
 )
 
 require (
-	filippo.io/edwards25519 v1.1.0 // indirect
 	github.com/Microsoft/go-winio v0.6.2 // indirect
 	github.com/agl/ed25519 v0.0.0-20200225211852-fd4d107ace12 // indirect
 	github.com/algorand/avm-abi v0.2.0 // indirect
@@ -71,12 +73,12 @@ require (
 	github.com/cpuguy83/go-md2man/v2 v2.0.6 // indirect
 	github.com/crate-crypto/go-ipa v0.0.0-20240724233137-53bbb0ceb27a // indirect
 	github.com/crate-crypto/go-kzg-4844 v1.1.0 // indirect
+	github.com/davecgh/go-spew v1.1.2-0.20180830191138-d8f796af33cc // indirect
 	github.com/deckarep/golang-set/v2 v2.8.0 // indirect
 	github.com/decred/dcrd/crypto/blake256 v1.1.0 // indirect
 	github.com/echovl/ed25519 v0.2.0 // indirect
 	github.com/ethereum/c-kzg-4844 v1.0.3 // indirect
 	github.com/ethereum/go-verkle v0.2.2 // indirect
-	github.com/fardream/go-bcs v0.8.7 // indirect
 	github.com/fatih/color v1.18.0 // indirect
 	github.com/fatih/structs v1.1.0 // indirect
 	github.com/fsnotify/fsnotify v1.9.0 // indirect
@@ -48,6 +48,7 @@ type OperationForSigning struct {
 	Type           libs.OperationType       `json:"type"`
 	Solver         string                   `json:"solver"`
 	SolverMetadata string                   `json:"solverMetadata"`
+	DerivationPath string                   `json:"derivationPath,omitempty"`
 }
 
 type IntentForSigning struct {
@@ -475,6 +476,7 @@ func SanitiseIntent(intent libs.Intent) (string, error) {
 			Type:           operation.Type,
 			Solver:         operation.Solver,
 			SolverMetadata: operation.SolverMetadata,
+			DerivationPath: operation.DerivationPath,
 		}
 		if operation.SerializedTxn != nil {
 			opSigning.SerializedTxn = *operation.SerializedTxn
@@ -0,0 +1,61 @@
+package libs
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	pb "github.com/StripChain/strip-node/libs/proto"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// A blame report names the signers a validator found at fault for a failed TSS round. The
+// validator signs it with its node key, so a report can be checked against its reporter
+// and matched with the reports of the other validators of the round.
+
+// blameBody is the signed part of a blame report, its fields in a fixed order
+type blameBody struct {
+	SessionID string   `json:"sessionId"`
+	Task      string   `json:"task"`
+	Round     int32    `json:"round"`
+	Culprits  []string `json:"culprits"`
+	Reason    string   `json:"reason"`
+	Reporter  string   `json:"reporter"`
+}
+
+// BlameReportDigest returns the digest a reporter signs: the Keccak-256 hash of the JSON
+// encoding of every field of the report but its signature
+func BlameReportDigest(report *pb.BlameReport) ([]byte, error) {
+	data, err := json.Marshal(blameBody{
+		SessionID: report.SessionId,
+		Task:      report.Task,
+		Round:     report.Round,
+		Culprits:  report.Culprits,
+		Reason:    report.Reason,
+		Reporter:  report.Reporter,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Keccak256(data), nil
+}
+
+// VerifyBlameReport checks that a report is signed by its reporter, which does not blame
+// itself
+func VerifyBlameReport(report *pb.BlameReport) error {
+	digest, err := BlameReportDigest(report)
+	if err != nil {
+		return err
+	}
+	reporter, err := ReceiptSigner(digest, report.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature of %s: %w", report.Reporter, err)
+	}
+	if reporter != report.Reporter {
+		return fmt.Errorf("report of %s is signed by %s", report.Reporter, reporter)
+	}
+	if slices.Contains(report.Culprits, reporter) {
+		return fmt.Errorf("%s blames itself", reporter)
+	}
+	return nil
+}
@@ -293,12 +293,12 @@ func (b *AlgorandBlockchain) RawPublicKeyToPublicKeyStr(pkBytes []byte) (string,
 func (b *AlgorandBlockchain) ExtractDestinationAddress(serializedTxn string) (string, string, error) {
 	txnBytes, err := base64.StdEncoding.DecodeString(serializedTxn)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to decode serialized transaction", err)
+		return "", "", fmt.Errorf("failed to decode serialized transaction: %v", err)
 	}
 	var txn types.Transaction
 	err = msgpack.Decode(txnBytes, &txn)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to deserialize transaction", err)
+		return "", "", fmt.Errorf("failed to deserialize transaction: %v", err)
 	}
 	destAddress := ""
 	if txn.Type == types.PaymentTx {
@@ -306,7 +306,42 @@ func (b *AlgorandBlockchain) ExtractDestinationAddress(serializedTxn string) (st
 	} else if txn.Type == types.AssetTransferTx {
 		destAddress = txn.AssetTransferTxnFields.AssetReceiver.String()
 	} else {
-		return "", "", fmt.Errorf("unknown transaction type", txn.Type)
+		return "", "", fmt.Errorf("unknown transaction type: %v", txn.Type)
 	}
 	return destAddress, "", nil
 }
+
+func (b *AlgorandBlockchain) ExtractTransferAmount(serializedTxn string) (string, error) {
+	txnBytes, err := base64.StdEncoding.DecodeString(serializedTxn)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode serialized transaction: %v", err)
+	}
+	var txn types.Transaction
+	if err := msgpack.Decode(txnBytes, &txn); err != nil {
+		return "", fmt.Errorf("failed to deserialize transaction: %v", err)
+	}
+	switch txn.Type {
+	case types.PaymentTx:
+		return strconv.FormatUint(uint64(txn.PaymentTxnFields.Amount), 10), nil
+	case types.AssetTransferTx:
+		return strconv.FormatUint(txn.AssetTransferTxnFields.AssetAmount, 10), nil
+	default:
+		return "", fmt.Errorf("unknown transaction type: %v", txn.Type)
+	}
+}
+
+// ComputeDataToSign returns the bytes an account signs for a msgpack transaction: the
+// transaction prefixed with its domain separator
+func (b *AlgorandBlockchain) ComputeDataToSign(serializedTxn string) (string, error) {
+	txnBytes, err := base64.StdEncoding.DecodeString(serializedTxn)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode serialized transaction: %v", err)
+	}
+	var txn types.Transaction
+	if err := msgpack.Decode(txnBytes, &txn); err != nil {
+		return "", fmt.Errorf("failed to deserialize transaction: %v", err)
+	}
+
+	bytesToSign := append([]byte("TX"), msgpack.Encode(txn)...)
+	return base64.StdEncoding.EncodeToString(bytesToSign), nil
+}
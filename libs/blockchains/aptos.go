@@ -523,10 +523,29 @@ func (b *AptosBlockchain) ExtractDestinationAddress(serializedTxn string) (strin
 	destAddress := ""
 	if err := json.Unmarshal([]byte(serializedTxn), &aptosPayload); err != nil {
 		logger.Sugar().Errorw("error parsing Aptos transaction", "error", err)
-		return "", "", fmt.Errorf("error parsing Aptos transaction", err)
+		return "", "", fmt.Errorf("error parsing Aptos transaction: %v", err)
 	}
 	if len(aptosPayload.Args) > 0 {
 		destAddress = aptosPayload.Args[0] // First arg is typically the recipient
 	}
 	return destAddress, "", nil
 }
+
+// ComputeDataToSign returns the signing message of a BCS encoded raw transaction
+func (b *AptosBlockchain) ComputeDataToSign(serializedTxn string) (string, error) {
+	txnBytes, err := hex.DecodeString(strings.TrimPrefix(serializedTxn, "0x"))
+	if err != nil {
+		return "", fmt.Errorf("error decoding transaction data: %v", err)
+	}
+
+	rawTxn := &aptos.RawTransaction{}
+	if err := bcs.Deserialize(rawTxn, txnBytes); err != nil {
+		return "", fmt.Errorf("error unmarshalling raw transaction: %v", err)
+	}
+
+	msg, err := rawTxn.SigningMessage()
+	if err != nil {
+		return "", fmt.Errorf("failed to get signing message: %v", err)
+	}
+	return hex.EncodeToString(msg), nil
+}
@@ -133,48 +133,54 @@ func (b *BitcoinBlockchain) BroadcastTransaction(txn string, signatureHex string
 		return "", fmt.Errorf("error parsing transaction: %v", err)
 	}
 
-	// Step 2: Create DER signature
-	derSignatureHex, err := derEncode(signatureHex)
+	// Step 2: Create the DER signature of every input
+	signatures, err := inputSignatures(signatureHex, len(msgTx.TxIn))
 	if err != nil {
-		return "", fmt.Errorf("error encoding signature: %v", err)
+		return "", err
 	}
-	log.Println("DER signature:", derSignatureHex)
-	derSignature, err := hex.DecodeString(derSignatureHex)
-	if err != nil {
-		return "", fmt.Errorf("error decoding signature: %v", err)
-	}
-
-	// Step 3: Handle transaction signing based on address type
-	if isSegWit {
-		// For SegWit, we use witness data
-		witness := wire.TxWitness{derSignature, scriptPubKey}
-		msgTx.TxIn[0].Witness = witness
-		// Empty the signature script for witness transactions
-		msgTx.TxIn[0].SignatureScript = []byte{}
-	} else {
-		// For legacy P2PKH, signature script should be: <sig> <pubkey>
-		builder := txscript.NewScriptBuilder()
-
-		// Add DER signature with SIGHASH_ALL if not already present
-		if len(derSignature) == 0 || derSignature[len(derSignature)-1] != byte(txscript.SigHashAll) {
-			derSignature = append(derSignature, byte(txscript.SigHashAll))
+	for i, signature := range signatures {
+		derSignatureHex, err := derEncode(signature)
+		if err != nil {
+			return "", fmt.Errorf("error encoding signature: %v", err)
 		}
-		builder.AddData(derSignature)
-
-		// For P2PKH, we need the full public key, not its hash
-		pubKeyBytes, err := hex.DecodeString(*publicKey)
+		log.Println("DER signature:", derSignatureHex)
+		derSignature, err := hex.DecodeString(derSignatureHex)
 		if err != nil {
-			return "", fmt.Errorf("error decoding public key: %v", err)
+			return "", fmt.Errorf("error decoding signature: %v", err)
 		}
-		builder.AddData(pubKeyBytes)
 
-		sigScript, err := builder.Script()
-		if err != nil {
-			return "", fmt.Errorf("error building signature script: %v", err)
+		// Step 3: Handle input signing based on address type
+		if isSegWit {
+			// For SegWit, we use witness data
+			witness := wire.TxWitness{derSignature, scriptPubKey}
+			msgTx.TxIn[i].Witness = witness
+			// Empty the signature script for witness transactions
+			msgTx.TxIn[i].SignatureScript = []byte{}
+		} else {
+			// For legacy P2PKH, signature script should be: <sig> <pubkey>
+			builder := txscript.NewScriptBuilder()
+
+			// Add DER signature with SIGHASH_ALL if not already present
+			if len(derSignature) == 0 || derSignature[len(derSignature)-1] != byte(txscript.SigHashAll) {
+				derSignature = append(derSignature, byte(txscript.SigHashAll))
+			}
+			builder.AddData(derSignature)
+
+			// For P2PKH, we need the full public key, not its hash
+			pubKeyBytes, err := hex.DecodeString(*publicKey)
+			if err != nil {
+				return "", fmt.Errorf("error decoding public key: %v", err)
+			}
+			builder.AddData(pubKeyBytes)
+
+			sigScript, err := builder.Script()
+			if err != nil {
+				return "", fmt.Errorf("error building signature script: %v", err)
+			}
+			msgTx.TxIn[i].SignatureScript = sigScript
+			// Empty the witness for legacy transactions
+			msgTx.TxIn[i].Witness = wire.TxWitness{}
 		}
-		msgTx.TxIn[0].SignatureScript = sigScript
-		// Empty the witness for legacy transactions
-		msgTx.TxIn[0].Witness = wire.TxWitness{}
 	}
 
 	// Step 4: Serialize the signed transaction
@@ -324,11 +330,15 @@ func (b *BitcoinBlockchain) BuildWithdrawTx(account string,
 	txIn := wire.NewTxIn(dummyOutpoint, nil, nil)
 	msgTx.AddTxIn(txIn)
 
-	// Create P2WPKH script for the input
-	_, err = btcutil.DecodeAddress(account, b.chainParams)
+	// The input spends an output of the account, paying at least the amount withdrawn
+	from, err := btcutil.DecodeAddress(account, b.chainParams)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to decode from address: %w", err)
 	}
+	fromScript, err := txscript.PayToAddrScript(from)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create input script: %w", err)
+	}
 
 	// For P2WPKH, we use empty SignatureScript and put the actual script in witness
 	txIn.SignatureScript = []byte{}
@@ -339,20 +349,16 @@ func (b *BitcoinBlockchain) BuildWithdrawTx(account string,
 		return "", "", fmt.Errorf("failed to serialize transaction: %w", err)
 	}
 
-	// firstInputScriptPubKey := inputScriptPubKeys[0]
-	// firstInputValue := inputValues[0]
-
-	// // Assuming the first input is P2WPKH for sighash calculation.
-	// // This is a major assumption and needs to be verified or made dynamic.
-	// sigHashes := txscript.NewTxSigHashes(&msgTx)
-	// sighashBytes, err := txscript.CalcWitnessSigHash(firstInputScriptPubKey, sigHashes, txscript.SigHashAll, &msgTx, 0, int64(firstInputValue))
-	// if err != nil {
-	// 	return "", "", fmt.Errorf("failed to calculate sighash for input 0: %w", err)
-	// }
-	// dataToSign := hex.EncodeToString(sighashBytes)
-	// TODO: Missing data to sign implementation
-	dataToSign := ""
-	return hex.EncodeToString(buf.Bytes()), dataToSign, nil
+	// Every input signs its own sighash, SegWit or legacy depending on the account
+	prevOuts := make([]*wire.TxOut, len(msgTx.TxIn))
+	for i := range prevOuts {
+		prevOuts[i] = wire.NewTxOut(amountSatoshis, fromScript)
+	}
+	hashes, err := inputSigHashes(&msgTx, prevOuts)
+	if err != nil {
+		return "", "", err
+	}
+	return hex.EncodeToString(buf.Bytes()), JoinDataToSign(hashes), nil
 }
 
 func (b *BitcoinBlockchain) RawPublicKeyBytesToAddress(pkBytes []byte, networkType NetworkType) (string, error) {
@@ -368,18 +374,90 @@ func (b *BitcoinBlockchain) ExtractDestinationAddress(serializedTxn string) (str
 	var tx wire.MsgTx
 	txBytes, err := hex.DecodeString(serializedTxn)
 	if err != nil {
-		return "", "", fmt.Errorf("error decoding bitcoin&dogecoin transaction", err)
+		return "", "", fmt.Errorf("error decoding bitcoin&dogecoin transaction: %v", err)
 	}
 	if err := tx.Deserialize(bytes.NewReader(txBytes)); err != nil {
-		return "", "", fmt.Errorf("error deserializing bitcoin&dogecoin transaction", err)
+		return "", "", fmt.Errorf("error deserializing bitcoin&dogecoin transaction: %v", err)
 	}
 	// Get the first output's address (assuming it's the bridge address)
 	if len(tx.TxOut) > 0 {
 		_, addrs, _, err := txscript.ExtractPkScriptAddrs(tx.TxOut[0].PkScript, nil)
 		if err != nil || len(addrs) == 0 {
-			return "", "", fmt.Errorf("error extracting bitcoin&dogecoin address", err)
+			return "", "", fmt.Errorf("error extracting bitcoin&dogecoin address: %v", err)
 		}
 		return addrs[0].String(), "", nil
 	}
 	return "", "", fmt.Errorf("no output destination bitcoin address")
 }
+
+func (b *BitcoinBlockchain) ExtractTransferAmount(serializedTxn string) (string, error) {
+	msgTx, err := parseSerializedTransaction(serializedTxn)
+	if err != nil {
+		return "", fmt.Errorf("error parsing transaction: %v", err)
+	}
+	// The destination is paid by the first output, as in ExtractDestinationAddress
+	if len(msgTx.TxOut) == 0 {
+		return "", fmt.Errorf("no output in bitcoin transaction")
+	}
+	return strconv.FormatInt(msgTx.TxOut[0].Value, 10), nil
+}
+
+// ComputeDataToSign returns the sighash of every input of the transaction. A sighash
+// commits to the output its input spends, which is fetched from the node.
+func (b *BitcoinBlockchain) ComputeDataToSign(serializedTxn string) (string, error) {
+	msgTx, err := parseSerializedTransaction(serializedTxn)
+	if err != nil {
+		return "", fmt.Errorf("error parsing transaction: %v", err)
+	}
+
+	prevOuts := make([]*wire.TxOut, len(msgTx.TxIn))
+	for i, txIn := range msgTx.TxIn {
+		outPoint := txIn.PreviousOutPoint
+		prevTx, err := b.client.GetRawTransaction(&outPoint.Hash)
+		if err != nil {
+			return "", fmt.Errorf("failed to fetch previous tx %s: %w", outPoint.Hash, err)
+		}
+		outputs := prevTx.MsgTx().TxOut
+		if int(outPoint.Index) >= len(outputs) {
+			return "", fmt.Errorf("input %d spends unknown output %s", i, outPoint)
+		}
+		prevOuts[i] = outputs[outPoint.Index]
+	}
+
+	hashes, err := inputSigHashes(msgTx, prevOuts)
+	if err != nil {
+		return "", err
+	}
+	return JoinDataToSign(hashes), nil
+}
+
+// inputSigHashes returns the SIGHASH_ALL hash of every input of a transaction given the
+// outputs they spend. P2WPKH inputs are hashed as in BIP 143, others with the legacy
+// algorithm.
+func inputSigHashes(msgTx *wire.MsgTx, prevOuts []*wire.TxOut) ([]string, error) {
+	if len(prevOuts) != len(msgTx.TxIn) {
+		return nil, fmt.Errorf("got %d previous outputs for %d inputs", len(prevOuts), len(msgTx.TxIn))
+	}
+
+	fetcher := txscript.NewMultiPrevOutFetcher(nil)
+	for i, txIn := range msgTx.TxIn {
+		fetcher.AddPrevOut(txIn.PreviousOutPoint, prevOuts[i])
+	}
+	sigHashes := txscript.NewTxSigHashes(msgTx, fetcher)
+
+	hashes := make([]string, len(msgTx.TxIn))
+	for i, prevOut := range prevOuts {
+		var hash []byte
+		var err error
+		if txscript.IsPayToWitnessPubKeyHash(prevOut.PkScript) {
+			hash, err = txscript.CalcWitnessSigHash(prevOut.PkScript, sigHashes, txscript.SigHashAll, msgTx, i, prevOut.Value)
+		} else {
+			hash, err = txscript.CalcSignatureHash(prevOut.PkScript, txscript.SigHashAll, msgTx, i)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate sighash for input %d: %w", i, err)
+		}
+		hashes[i] = hex.EncodeToString(hash)
+	}
+	return hashes, nil
+}
@@ -32,6 +32,12 @@ type IBlockchain interface {
 	RawPublicKeyBytesToAddress(pkBytes []byte, networkType NetworkType) (string, error)
 	RawPublicKeyToPublicKeyStr(pkBytes []byte) (string, error)
 	ExtractDestinationAddress(serializedTxn string) (string, string, error)
+	// ExtractTransferAmount returns the amount the destination of a serialized transaction
+	// receives, in base units of the token where the chain has them
+	ExtractTransferAmount(serializedTxn string) (string, error)
+	// ComputeDataToSign decodes a serialized transaction and returns the payload its
+	// signer has to sign, encoded as the data to sign of an operation on the chain
+	ComputeDataToSign(serializedTxn string) (string, error)
 }
 
 type Network struct {
@@ -232,6 +238,14 @@ func (b *BaseBlockchain) ExtractDestinationAddress(serializedTxn string) (string
 	return "", "", errors.New("ExtractDestinationAddress not implemented")
 }
 
+func (b *BaseBlockchain) ExtractTransferAmount(serializedTxn string) (string, error) {
+	return "", errors.New("ExtractTransferAmount not implemented")
+}
+
+func (b *BaseBlockchain) ComputeDataToSign(serializedTxn string) (string, error) {
+	return "", errors.New("ComputeDataToSign not implemented")
+}
+
 // TODO: This needs improvement
 func ParseBlockchainID(blockchainID string) (BlockchainID, error) {
 	return BlockchainID(blockchainID), nil
@@ -2,6 +2,7 @@ package blockchains
 
 import (
 	"context"
+	"crypto/ed25519"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
@@ -14,7 +15,6 @@ import (
 	"github.com/StripChain/strip-node/util"
 	"github.com/blockfrost/blockfrost-go"
 	"github.com/echovl/cardano-go"
-	"github.com/fxamacker/cbor/v2"
 )
 
 // NewCardanoBlockchain creates a new Cardano blockchain instance
@@ -41,6 +41,24 @@ func NewCardanoBlockchain(networkType NetworkType) (IBlockchain, error) {
 		},
 	)
 
+	return newCardanoBlockchain(network, newClient), nil
+}
+
+// NewCardanoBlockchainWithProvider creates a Cardano blockchain instance that reads chain
+// state and submits transactions through provider instead of the public Blockfrost API
+func NewCardanoBlockchainWithProvider(networkType NetworkType, provider ICardanoProvider) (IBlockchain, error) {
+	network := Network{
+		networkType: networkType,
+		networkID:   "mainnet",
+	}
+	if networkType != Mainnet {
+		network.networkID = "preprod"
+	}
+
+	return newCardanoBlockchain(network, provider), nil
+}
+
+func newCardanoBlockchain(network Network, client ICardanoProvider) *CardanoBlockchain {
 	return &CardanoBlockchain{
 		BaseBlockchain: BaseBlockchain{
 			chainName:       Cardano,
@@ -50,8 +68,8 @@ func NewCardanoBlockchain(networkType NetworkType) (IBlockchain, error) {
 			decimals:        6,
 			opTimeout:       time.Second * 10,
 		},
-		client: newClient,
-	}, nil
+		client: client,
+	}
 }
 
 // This is a type assertion to ensure that the CardanoBlockchain implements the IBlockchain interface
@@ -60,15 +78,13 @@ var _ IBlockchain = &CardanoBlockchain{}
 // CardanoBlockchain implements the IBlockchain interface for Cardano
 type CardanoBlockchain struct {
 	BaseBlockchain
-	client blockfrost.APIClient
+	client ICardanoProvider
 }
 
 func (b *CardanoBlockchain) BroadcastTransaction(txn string, signatureHex string, publicKey *string) (string, error) {
-
-	// Decode the transaction bytes
-	txBytes, err := hex.DecodeString(strings.TrimPrefix(txn, "0x"))
+	tx, err := decodeCardanoTransaction(txn)
 	if err != nil {
-		return "", fmt.Errorf("failed to decode transaction: %v", err)
+		return "", err
 	}
 
 	// Decode signature and public key
@@ -77,89 +93,33 @@ func (b *CardanoBlockchain) BroadcastTransaction(txn string, signatureHex string
 		return "", fmt.Errorf("failed to decode signature: %v", err)
 	}
 
+	if publicKey == nil {
+		return "", fmt.Errorf("public key is required")
+	}
 	pubKeyBytes, err := hex.DecodeString(strings.TrimPrefix(*publicKey, "0x"))
 	if err != nil {
 		return "", fmt.Errorf("failed to decode public key: %v", err)
 	}
-
-	// Use a custom CBOR decoder that preserves the exact structure
-	decMode, err := cbor.DecOptions{
-		TagsMd:            cbor.TagsAllowed,
-		ExtraReturnErrors: cbor.ExtraDecErrorUnknownField,
-	}.DecMode()
-	if err != nil {
-		return "", fmt.Errorf("failed to create decoder: %v", err)
+	if len(pubKeyBytes) != ed25519.PublicKeySize || len(sigBytes) != ed25519.SignatureSize {
+		return "", fmt.Errorf("invalid public key or signature length: %d, %d", len(pubKeyBytes), len(sigBytes))
 	}
 
-	// Decode the transaction to get its structure
-	var txData interface{}
-	err = decMode.Unmarshal(txBytes, &txData)
+	// The witness signs the hash of the body exactly as it will be submitted
+	hash, err := tx.Hash()
 	if err != nil {
-		return "", fmt.Errorf("failed to decode transaction: %v", err)
-	}
-
-	// For Cardano Shelley transactions, the structure is typically:
-	// [transaction_body, transaction_witness_set, transaction_metadata, auxiliary_data]
-	// We need to ensure we maintain this exact structure
-
-	txArray, ok := txData.([]interface{})
-	if !ok {
-		return "", fmt.Errorf("unexpected transaction structure: %T", txData)
+		return "", fmt.Errorf("failed to hash transaction: %v", err)
 	}
-
-	// Create a witness with the public key and signature
-	vkeyWitness := []interface{}{
-		pubKeyBytes,
-		sigBytes,
+	if !ed25519.Verify(pubKeyBytes, hash, sigBytes) {
+		return "", fmt.Errorf("signature does not match transaction body hash %s", hash)
 	}
 
-	// Check if we have a witness set (index 1)
-	if len(txArray) > 1 {
-		witnessSet, ok := txArray[1].(map[interface{}]interface{})
-		if !ok {
-			// If it's not a map, create a new one
-			witnessSet = make(map[interface{}]interface{})
-			txArray[1] = witnessSet
-		}
-
-		// Check if we have vkey witnesses
-		vkeyWitnesses, ok := witnessSet[uint64(0)].([]interface{})
-		if !ok {
-			// If not, create a new array with our witness
-			witnessSet[uint64(0)] = []interface{}{vkeyWitness}
-		} else {
-			// If we do, append our witness
-			witnessSet[uint64(0)] = append(vkeyWitnesses, vkeyWitness)
-		}
-	} else {
-		// If we don't have a witness set, create one
-		witnessSet := map[interface{}]interface{}{
-			uint64(0): []interface{}{vkeyWitness},
-		}
-		txArray = append(txArray, witnessSet)
-	}
-
-	// Ensure we have all required elements (at least 3 for a valid Shelley transaction)
-	for len(txArray) < 3 {
-		txArray = append(txArray, nil)
-	}
-
-	// Re-encode the transaction
-	encMode, err := cbor.EncOptions{
-		Sort:   cbor.SortCanonical,
-		TagsMd: cbor.TagsAllowed,
-	}.EncMode()
-	if err != nil {
-		return "", fmt.Errorf("failed to create encoder: %v", err)
-	}
-
-	signedTxBytes, err := encMode.Marshal(txArray)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal signed transaction: %v", err)
-	}
+	tx.WitnessSet.VKeyWitnessSet = append(tx.WitnessSet.VKeyWitnessSet, cardano.VKeyWitness{
+		VKey:      pubKeyBytes,
+		Signature: sigBytes,
+	})
 
 	// Submit the transaction
-	txHash, err := b.client.TransactionSubmit(context.Background(), signedTxBytes)
+	txHash, err := b.client.TransactionSubmit(context.Background(), tx.Bytes())
 	if err != nil {
 		return "", fmt.Errorf("error submitting transaction: %v", err)
 	}
@@ -262,99 +222,46 @@ func (b *CardanoBlockchain) BuildWithdrawTx(bridgeAddress string,
 	userAddress string,
 	tokenAddress *string,
 ) (string, string, error) {
-	if tokenAddress == nil {
-		// Parse solver output to get amount
-		var solverData map[string]interface{}
-		if err := json.Unmarshal([]byte(solverOutput), &solverData); err != nil {
-			return "", "", fmt.Errorf("failed to parse solver output: %v", err)
-		}
-
-		txBuilder := cardano.NewTxBuilder(&cardano.ProtocolParams{})
-		amountStr, ok := solverData["amount"].(string)
-		if !ok {
-			return "", "", fmt.Errorf("amount not found in solver output")
-		}
-
-		amount, err := strconv.ParseUint(amountStr, 10, 64)
-		if err != nil {
-			return "", "", fmt.Errorf("failed to parse amount: %v", err)
-		}
-
-		address, err := cardano.NewAddress(userAddress)
-		if err != nil {
-			return "", "", fmt.Errorf("failed to parse address: %v", err)
-		}
-
-		inputTxHash := strings.ToLower(solverData["txHash"].(string))
-		txBuilder.AddInputs(&cardano.TxInput{
-			TxHash: cardano.Hash32(inputTxHash),
-			Amount: &cardano.Value{
-				Coin: cardano.Coin(amount),
-			},
-		})
-		txBuilder.AddOutputs(&cardano.TxOutput{
-			Address: address,
-			Amount: &cardano.Value{
-				Coin: cardano.Coin(amount),
-			},
-		})
-
-		tx, err := txBuilder.Build()
-		if err != nil {
-			return "", "", fmt.Errorf("failed to build transaction: %v", err)
-		}
-		hash, err := tx.Hash()
-		if err != nil {
-			return "", "", fmt.Errorf("failed to hash transaction: %v", err)
-		}
-		serializedTxn := tx.Hex()
-		dataToSign := hash.String()
-		return serializedTxn, dataToSign, nil
-	}
-	// Parse solver output
+	// Parse solver output to get amount
 	var solverData map[string]interface{}
 	if err := json.Unmarshal([]byte(solverOutput), &solverData); err != nil {
 		return "", "", fmt.Errorf("failed to parse solver output: %v", err)
 	}
 
-	txBuilder := cardano.NewTxBuilder(&cardano.ProtocolParams{})
 	amountStr, ok := solverData["amount"].(string)
 	if !ok {
 		return "", "", fmt.Errorf("amount not found in solver output")
 	}
 
 	amount, err := strconv.ParseUint(amountStr, 10, 64)
-	if err != nil {
-		return "", "", fmt.Errorf("failed to parse amount: %v", err)
+	if err != nil || amount == 0 {
+		return "", "", fmt.Errorf("invalid amount: %s", amountStr)
 	}
 
-	address, err := cardano.NewAddress(userAddress)
+	bridge, err := cardano.NewAddress(bridgeAddress)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to parse address: %v", err)
+		return "", "", fmt.Errorf("invalid bridge address: %v", err)
 	}
 
-	inputTxHash := strings.ToLower(solverData["txHash"].(string))
+	recipient, err := cardano.NewAddress(userAddress)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid recipient address: %v", err)
+	}
 
-	// FIX: This is a temporary fillin and should be addressed
-	policyID := cardano.NewPolicyIDFromHash(cardano.Hash28(*tokenAddress))
-	assetName := cardano.NewAssetName(*tokenAddress)
-	txBuilder.AddInputs(&cardano.TxInput{
-		TxHash: cardano.Hash32(inputTxHash),
-		Amount: &cardano.Value{
-			MultiAsset: cardano.NewMultiAsset().Set(policyID, cardano.NewAssets().Set(assetName, cardano.BigNum(amount))),
-		},
-	})
-	txBuilder.AddOutputs(&cardano.TxOutput{
-		Address: address,
-		Amount: &cardano.Value{
-			MultiAsset: cardano.NewMultiAsset().Set(policyID, cardano.NewAssets().Set(assetName, cardano.BigNum(amount))),
-		},
-	})
+	unit := cardanoLovelace
+	if tokenAddress != nil && *tokenAddress != util.ZERO_ADDRESS && *tokenAddress != cardanoLovelace {
+		unit, err = normalizeCardanoAssetUnit(*tokenAddress)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid token address: %v", err)
+		}
+	}
 
-	tx, err := txBuilder.Build()
+	tx, err := b.buildWithdrawTx(context.Background(), bridge, recipient, unit, amount)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to build transaction: %v", err)
+		return "", "", err
 	}
+
+	// Witnesses sign the blake2b-256 hash of the transaction body
 	hash, err := tx.Hash()
 	if err != nil {
 		return "", "", fmt.Errorf("failed to hash transaction: %v", err)
@@ -393,15 +300,83 @@ func (b *CardanoBlockchain) RawPublicKeyToPublicKeyStr(pkBytes []byte) (string,
 }
 
 func (b *CardanoBlockchain) ExtractDestinationAddress(serializedTxn string) (string, string, error) {
-	var tx cardano.Tx
-	txBytes, err := hex.DecodeString(serializedTxn)
-	destAddress := ""
+	tx, err := decodeCardanoTransaction(serializedTxn)
 	if err != nil {
-		return "", "", fmt.Errorf("error decoding Cardano transaction", err)
+		return "", "", err
+	}
+	if len(tx.Body.Outputs) == 0 {
+		return "", "", fmt.Errorf("Cardano transaction has no outputs")
+	}
+
+	// Withdrawals pay the recipient in the first output, change follows
+	output := tx.Body.Outputs[0]
+	destAddress := output.Address.String()
+
+	tokenAddress := ""
+	if output.Amount != nil && output.Amount.MultiAsset != nil {
+		for _, policyID := range output.Amount.MultiAsset.Keys() {
+			for _, assetName := range output.Amount.MultiAsset.Get(policyID).Keys() {
+				if tokenAddress != "" {
+					return "", "", fmt.Errorf("Cardano withdrawal output holds more than one asset")
+				}
+				tokenAddress = hex.EncodeToString(policyID.Bytes()) + hex.EncodeToString(assetName.Bytes())
+			}
+		}
 	}
-	if err := json.Unmarshal(txBytes, &tx); err != nil {
-		return "", "", fmt.Errorf("error parsing Cardano transaction", err)
+
+	return destAddress, tokenAddress, nil
+}
+
+func (b *CardanoBlockchain) ExtractTransferAmount(serializedTxn string) (string, error) {
+	tx, err := decodeCardanoTransaction(serializedTxn)
+	if err != nil {
+		return "", err
+	}
+	if len(tx.Body.Outputs) == 0 {
+		return "", fmt.Errorf("Cardano transaction has no outputs")
+	}
+
+	// The recipient's output holds either lovelace only or a single asset, whose quantity
+	// is the amount transferred
+	output := tx.Body.Outputs[0]
+	if output.Amount == nil {
+		return "", fmt.Errorf("Cardano output has no amount")
+	}
+	if output.Amount.MultiAsset != nil {
+		for _, policyID := range output.Amount.MultiAsset.Keys() {
+			assets := output.Amount.MultiAsset.Get(policyID)
+			for _, assetName := range assets.Keys() {
+				return strconv.FormatUint(uint64(assets.Get(assetName)), 10), nil
+			}
+		}
+	}
+	return strconv.FormatUint(uint64(output.Amount.Coin), 10), nil
+}
+
+// ComputeDataToSign returns the hash of the transaction body, which witnesses sign
+func (b *CardanoBlockchain) ComputeDataToSign(serializedTxn string) (string, error) {
+	tx, err := decodeCardanoTransaction(serializedTxn)
+	if err != nil {
+		return "", err
+	}
+
+	hash, err := tx.Hash()
+	if err != nil {
+		return "", fmt.Errorf("failed to hash transaction: %v", err)
+	}
+	return hash.String(), nil
+}
+
+// decodeCardanoTransaction decodes a hex CBOR transaction
+func decodeCardanoTransaction(serializedTxn string) (*cardano.Tx, error) {
+	txBytes, err := hex.DecodeString(strings.TrimPrefix(serializedTxn, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("error decoding Cardano transaction: %v", err)
+	}
+
+	var tx cardano.Tx
+	if err := tx.UnmarshalCBOR(txBytes); err != nil {
+		return nil, fmt.Errorf("error parsing Cardano transaction: %v", err)
 	}
-	destAddress = tx.Body.Outputs[0].Address.String()
-	return destAddress, "", nil
+	return &tx, nil
 }
@@ -0,0 +1,22 @@
+package blockchains
+
+import (
+	"context"
+
+	"github.com/blockfrost/blockfrost-go"
+)
+
+// ICardanoProvider is the subset of the Blockfrost API used by CardanoBlockchain.
+// blockfrost.APIClient implements it; tests and local networks can provide a stand-in.
+type ICardanoProvider interface {
+	AddressUTXOs(ctx context.Context, address string, query blockfrost.APIQueryParams) ([]blockfrost.AddressUTXO, error)
+	Asset(ctx context.Context, asset string) (blockfrost.Asset, error)
+	BlockLatest(ctx context.Context) (blockfrost.Block, error)
+	LatestEpochParameters(ctx context.Context) (blockfrost.EpochParameters, error)
+	Transaction(ctx context.Context, hash string) (blockfrost.TransactionContent, error)
+	TransactionSubmit(ctx context.Context, cbor []byte) (string, error)
+	TransactionUTXOs(ctx context.Context, hash string) (blockfrost.TransactionUTXOs, error)
+}
+
+// This is a type assertion to ensure that the Blockfrost client implements the ICardanoProvider interface
+var _ ICardanoProvider = blockfrost.APIClient(nil)
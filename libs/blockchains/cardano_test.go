@@ -0,0 +1,438 @@
+package blockchains
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/blockfrost/blockfrost-go"
+	"github.com/echovl/cardano-go"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/blake2b"
+)
+
+const (
+	testCardanoPolicyID = "29d222ce763455e3d7a09a665ce554f00ac89d2e99a1a83d267170c6"
+	testCardanoSlot     = 50_000_000
+)
+
+// mockCardanoProvider is an in-memory stand-in for Blockfrost
+type mockCardanoProvider struct {
+	utxos     []blockfrost.AddressUTXO
+	params    blockfrost.EpochParameters
+	submitted [][]byte
+}
+
+func (m *mockCardanoProvider) AddressUTXOs(ctx context.Context, address string, query blockfrost.APIQueryParams) ([]blockfrost.AddressUTXO, error) {
+	var utxos []blockfrost.AddressUTXO
+	for _, utxo := range m.utxos {
+		if utxo.Address == address {
+			utxos = append(utxos, utxo)
+		}
+	}
+	start := (query.Page - 1) * query.Count
+	if start >= len(utxos) {
+		return []blockfrost.AddressUTXO{}, nil
+	}
+	end := start + query.Count
+	if end > len(utxos) {
+		end = len(utxos)
+	}
+	return utxos[start:end], nil
+}
+
+func (m *mockCardanoProvider) Asset(ctx context.Context, asset string) (blockfrost.Asset, error) {
+	return blockfrost.Asset{}, errors.New("not implemented")
+}
+
+func (m *mockCardanoProvider) BlockLatest(ctx context.Context) (blockfrost.Block, error) {
+	return blockfrost.Block{Slot: testCardanoSlot}, nil
+}
+
+func (m *mockCardanoProvider) LatestEpochParameters(ctx context.Context) (blockfrost.EpochParameters, error) {
+	return m.params, nil
+}
+
+func (m *mockCardanoProvider) Transaction(ctx context.Context, hash string) (blockfrost.TransactionContent, error) {
+	return blockfrost.TransactionContent{}, errors.New("not implemented")
+}
+
+func (m *mockCardanoProvider) TransactionSubmit(ctx context.Context, cbor []byte) (string, error) {
+	m.submitted = append(m.submitted, cbor)
+	var tx cardano.Tx
+	if err := tx.UnmarshalCBOR(cbor); err != nil {
+		return "", err
+	}
+	hash, err := tx.Hash()
+	if err != nil {
+		return "", err
+	}
+	return hash.String(), nil
+}
+
+func (m *mockCardanoProvider) TransactionUTXOs(ctx context.Context, hash string) (blockfrost.TransactionUTXOs, error) {
+	return blockfrost.TransactionUTXOs{}, errors.New("not implemented")
+}
+
+func newTestCardanoProvider() *mockCardanoProvider {
+	coinsPerUTxOSize := "4310"
+	return &mockCardanoProvider{
+		params: blockfrost.EpochParameters{
+			MinFeeA:          44,
+			MinFeeB:          155381,
+			MaxTxSize:        16384,
+			CoinsPerUTxOSize: &coinsPerUTxOSize,
+		},
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func testCardanoKey(seed byte) ed25519.PrivateKey {
+	return ed25519.NewKeyFromSeed(bytes.Repeat([]byte{seed}, ed25519.SeedSize))
+}
+
+func testCardanoAddress(t *testing.T, key ed25519.PrivateKey) string {
+	address, err := (&CardanoBlockchain{}).RawPublicKeyBytesToAddress(key.Public().(ed25519.PublicKey), Testnet)
+	require.NoError(t, err)
+	return address
+}
+
+func testCardanoUTxO(address string, index int, amounts ...blockfrost.AddressAmount) blockfrost.AddressUTXO {
+	return blockfrost.AddressUTXO{
+		Address:     address,
+		TxHash:      hex.EncodeToString(bytes.Repeat([]byte{byte(index + 1)}, 32)),
+		OutputIndex: index,
+		Amount:      amounts,
+	}
+}
+
+// testCardanoTxValues sums the value of a transaction's inputs, as listed by the provider, and outputs
+func testCardanoTxValues(t *testing.T, provider *mockCardanoProvider, tx *cardano.Tx) (cardanoAssets, cardanoAssets) {
+	inputs := cardanoAssets{}
+	for _, input := range tx.Body.Inputs {
+		found := false
+		for _, utxo := range provider.utxos {
+			if utxo.TxHash == input.TxHash.String() && uint64(utxo.OutputIndex) == input.Index {
+				for _, amount := range utxo.Amount {
+					var quantity uint64
+					_, err := fmt.Sscan(amount.Quantity, &quantity)
+					require.NoError(t, err)
+					inputs[amount.Unit] += quantity
+				}
+				found = true
+			}
+		}
+		require.True(t, found, "unknown input %s#%d", input.TxHash, input.Index)
+	}
+
+	outputs := cardanoAssets{}
+	for _, output := range tx.Body.Outputs {
+		outputs.add(testCardanoValueAssets(output.Amount))
+	}
+	return inputs, outputs
+}
+
+func testCardanoValueAssets(value *cardano.Value) cardanoAssets {
+	assets := cardanoAssets{cardanoLovelace: uint64(value.Coin)}
+	if value.MultiAsset == nil {
+		return assets
+	}
+	for _, policyID := range value.MultiAsset.Keys() {
+		policyAssets := value.MultiAsset.Get(policyID)
+		for _, assetName := range policyAssets.Keys() {
+			unit := hex.EncodeToString(policyID.Bytes()) + hex.EncodeToString(assetName.Bytes())
+			assets[unit] += uint64(policyAssets.Get(assetName))
+		}
+	}
+	return assets
+}
+
+func TestCardanoBuildWithdrawTx(t *testing.T) {
+	bridge := testCardanoAddress(t, testCardanoKey(1))
+	recipient := testCardanoAddress(t, testCardanoKey(2))
+	token := testCardanoPolicyID + hex.EncodeToString([]byte("STRIP"))
+	otherToken := testCardanoPolicyID + hex.EncodeToString([]byte("OTHER"))
+
+	tests := []struct {
+		name          string
+		utxos         []blockfrost.AddressUTXO
+		amount        string
+		tokenAddress  *string
+		expectedError string
+		// Expected assets of the recipient output, apart from lovelace for token withdrawals
+		expectedPayment cardanoAssets
+		expectedInputs  int
+		expectedChange  cardanoAssets
+	}{
+		{
+			name: "ADA",
+			utxos: []blockfrost.AddressUTXO{
+				testCardanoUTxO(bridge, 0, blockfrost.AddressAmount{Unit: "lovelace", Quantity: "3000000"}),
+				testCardanoUTxO(bridge, 1, blockfrost.AddressAmount{Unit: "lovelace", Quantity: "10000000"}),
+			},
+			amount:          "5000000",
+			expectedPayment: cardanoAssets{cardanoLovelace: 5000000},
+			expectedInputs:  1,
+			expectedChange:  cardanoAssets{},
+		},
+		{
+			name: "ADA from several UTxOs",
+			utxos: []blockfrost.AddressUTXO{
+				testCardanoUTxO(bridge, 0, blockfrost.AddressAmount{Unit: "lovelace", Quantity: "3000000"}),
+				testCardanoUTxO(bridge, 1, blockfrost.AddressAmount{Unit: "lovelace", Quantity: "4000000"}),
+			},
+			amount:          "5000000",
+			tokenAddress:    stringPtr("0x0000000000000000000000000000000000000000"),
+			expectedPayment: cardanoAssets{cardanoLovelace: 5000000},
+			expectedInputs:  2,
+			expectedChange:  cardanoAssets{},
+		},
+		{
+			name: "native token keeps other assets in change",
+			utxos: []blockfrost.AddressUTXO{
+				testCardanoUTxO(bridge, 0, blockfrost.AddressAmount{Unit: "lovelace", Quantity: "20000000"}),
+				testCardanoUTxO(bridge, 1,
+					blockfrost.AddressAmount{Unit: "lovelace", Quantity: "1500000"},
+					blockfrost.AddressAmount{Unit: token, Quantity: "1000"},
+					blockfrost.AddressAmount{Unit: otherToken, Quantity: "7"},
+				),
+			},
+			amount:          "400",
+			tokenAddress:    &token,
+			expectedPayment: cardanoAssets{token: 400},
+			expectedInputs:  2,
+			expectedChange:  cardanoAssets{token: 600, otherToken: 7},
+		},
+		{
+			name: "native token with dotted unit",
+			utxos: []blockfrost.AddressUTXO{
+				testCardanoUTxO(bridge, 0,
+					blockfrost.AddressAmount{Unit: "lovelace", Quantity: "5000000"},
+					blockfrost.AddressAmount{Unit: token, Quantity: "1000"},
+				),
+			},
+			amount:          "1000",
+			tokenAddress:    stringPtr(testCardanoPolicyID + "." + hex.EncodeToString([]byte("STRIP"))),
+			expectedPayment: cardanoAssets{token: 1000},
+			expectedInputs:  1,
+			expectedChange:  cardanoAssets{},
+		},
+		{
+			name: "insufficient token balance",
+			utxos: []blockfrost.AddressUTXO{
+				testCardanoUTxO(bridge, 0,
+					blockfrost.AddressAmount{Unit: "lovelace", Quantity: "5000000"},
+					blockfrost.AddressAmount{Unit: token, Quantity: "10"},
+				),
+			},
+			amount:        "11",
+			tokenAddress:  &token,
+			expectedError: "insufficient funds",
+		},
+		{
+			name: "not enough ADA for fee",
+			utxos: []blockfrost.AddressUTXO{
+				testCardanoUTxO(bridge, 0, blockfrost.AddressAmount{Unit: "lovelace", Quantity: "5000000"}),
+			},
+			amount:        "5000000",
+			expectedError: "insufficient funds",
+		},
+		{
+			name: "below min UTxO",
+			utxos: []blockfrost.AddressUTXO{
+				testCardanoUTxO(bridge, 0, blockfrost.AddressAmount{Unit: "lovelace", Quantity: "5000000"}),
+			},
+			amount:        "500000",
+			expectedError: "below the minimum UTxO value",
+		},
+		{
+			name:          "invalid token",
+			amount:        "1",
+			tokenAddress:  stringPtr("not-a-unit"),
+			expectedError: "invalid token address",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := newTestCardanoProvider()
+			provider.utxos = tt.utxos
+			chain, err := NewCardanoBlockchainWithProvider(Testnet, provider)
+			require.NoError(t, err)
+			b := chain.(*CardanoBlockchain)
+
+			serializedTxn, dataToSign, err := b.BuildWithdrawTx(bridge, fmt.Sprintf(`{"amount": "%s"}`, tt.amount), recipient, tt.tokenAddress)
+			if tt.expectedError != "" {
+				require.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+
+			tx, err := decodeCardanoTransaction(serializedTxn)
+			require.NoError(t, err)
+
+			// Data to sign is the hash of the serialized body
+			txBytes, err := hex.DecodeString(serializedTxn)
+			require.NoError(t, err)
+			var rawTx []cbor.RawMessage
+			require.NoError(t, cbor.Unmarshal(txBytes, &rawTx))
+			bodyHash := blake2b.Sum256(rawTx[0])
+			require.Equal(t, hex.EncodeToString(bodyHash[:]), dataToSign)
+
+			computed, err := b.ComputeDataToSign(serializedTxn)
+			require.NoError(t, err)
+			require.Equal(t, dataToSign, computed)
+
+			require.Len(t, tx.Body.Inputs, tt.expectedInputs)
+			require.NotNil(t, tx.Body.TTL)
+			require.Equal(t, uint64(testCardanoSlot+cardanoWithdrawTTL), *tx.Body.TTL)
+
+			params, err := b.protocolParams(context.Background())
+			require.NoError(t, err)
+
+			// Recipient output
+			require.Equal(t, recipient, tx.Body.Outputs[0].Address.String())
+			payment := testCardanoValueAssets(tx.Body.Outputs[0].Amount)
+			recipientAddress, err := cardano.NewAddress(recipient)
+			require.NoError(t, err)
+			minimum, err := params.minUTxO(recipientAddress, payment.tokens())
+			require.NoError(t, err)
+			require.GreaterOrEqual(t, payment[cardanoLovelace], minimum)
+			if _, ok := tt.expectedPayment[cardanoLovelace]; !ok {
+				payment = payment.tokens()
+			}
+			require.Equal(t, tt.expectedPayment, payment)
+
+			// Change output
+			if len(tx.Body.Outputs) > 1 {
+				require.Len(t, tx.Body.Outputs, 2)
+				require.Equal(t, bridge, tx.Body.Outputs[1].Address.String())
+				change := testCardanoValueAssets(tx.Body.Outputs[1].Amount)
+				bridgeAddress, err := cardano.NewAddress(bridge)
+				require.NoError(t, err)
+				minimum, err := params.minUTxO(bridgeAddress, change.tokens())
+				require.NoError(t, err)
+				require.GreaterOrEqual(t, change[cardanoLovelace], minimum)
+				require.Equal(t, tt.expectedChange, change.tokens())
+			} else {
+				require.Empty(t, tt.expectedChange)
+			}
+
+			// Value is conserved and the fee covers the signed transaction
+			inputs, outputs := testCardanoTxValues(t, provider, tx)
+			outputs[cardanoLovelace] += uint64(tx.Body.Fee)
+			require.Equal(t, inputs, outputs)
+
+			minFee, err := params.minFee(tx)
+			require.NoError(t, err)
+			require.GreaterOrEqual(t, uint64(tx.Body.Fee), minFee)
+			require.Less(t, uint64(tx.Body.Fee), minFee+1000)
+
+			destination, tokenAddress, err := b.ExtractDestinationAddress(serializedTxn)
+			require.NoError(t, err)
+			require.Equal(t, recipient, destination)
+			if tt.tokenAddress != nil && len(payment.tokens()) > 0 {
+				require.Equal(t, token, tokenAddress)
+			} else {
+				require.Empty(t, tokenAddress)
+			}
+
+			amount, err := b.ExtractTransferAmount(serializedTxn)
+			require.NoError(t, err)
+			transferred := testCardanoValueAssets(tx.Body.Outputs[0].Amount)
+			expectedAmount := transferred[cardanoLovelace]
+			for _, quantity := range transferred.tokens() {
+				expectedAmount = quantity
+			}
+			require.Equal(t, strconv.FormatUint(expectedAmount, 10), amount)
+		})
+	}
+}
+
+func TestCardanoProtocolParams(t *testing.T) {
+	tests := []struct {
+		name          string
+		perByte       *string
+		perWord       string
+		expected      uint64
+		expectedError string
+	}{
+		{name: "per byte", perByte: stringPtr("4310"), perWord: "34482", expected: 4310},
+		{name: "per word fallback", perWord: "34482", expected: 4310},
+		{name: "missing", expectedError: "invalid coins per UTxO word"},
+		{name: "zero", perByte: stringPtr("0"), expectedError: "missing coins per UTxO byte"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := newTestCardanoProvider()
+			provider.params.CoinsPerUTxOSize = tt.perByte
+			provider.params.CoinsPerUtxOWord = tt.perWord
+			chain, err := NewCardanoBlockchainWithProvider(Testnet, provider)
+			require.NoError(t, err)
+
+			params, err := chain.(*CardanoBlockchain).protocolParams(context.Background())
+			if tt.expectedError != "" {
+				require.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, params.coinsPerUTxOByte)
+		})
+	}
+}
+
+func TestCardanoBroadcastTransaction(t *testing.T) {
+	key := testCardanoKey(1)
+	bridge := testCardanoAddress(t, key)
+	recipient := testCardanoAddress(t, testCardanoKey(2))
+
+	provider := newTestCardanoProvider()
+	provider.utxos = []blockfrost.AddressUTXO{
+		testCardanoUTxO(bridge, 0, blockfrost.AddressAmount{Unit: "lovelace", Quantity: "10000000"}),
+	}
+	chain, err := NewCardanoBlockchainWithProvider(Testnet, provider)
+	require.NoError(t, err)
+
+	serializedTxn, dataToSign, err := chain.BuildWithdrawTx(bridge, `{"amount": "2000000"}`, recipient, nil)
+	require.NoError(t, err)
+	hash, err := hex.DecodeString(dataToSign)
+	require.NoError(t, err)
+
+	publicKey := hex.EncodeToString(key.Public().(ed25519.PublicKey))
+
+	t.Run("wrong signature", func(t *testing.T) {
+		signature := hex.EncodeToString(ed25519.Sign(testCardanoKey(3), hash))
+		_, err := chain.BroadcastTransaction(serializedTxn, signature, &publicKey)
+		require.ErrorContains(t, err, "signature does not match")
+		require.Empty(t, provider.submitted)
+	})
+
+	t.Run("valid signature", func(t *testing.T) {
+		signature := hex.EncodeToString(ed25519.Sign(key, hash))
+		txHash, err := chain.BroadcastTransaction(serializedTxn, signature, &publicKey)
+		require.NoError(t, err)
+		require.Equal(t, dataToSign, txHash)
+		require.Len(t, provider.submitted, 1)
+
+		var tx cardano.Tx
+		require.NoError(t, tx.UnmarshalCBOR(provider.submitted[0]))
+		require.Len(t, tx.WitnessSet.VKeyWitnessSet, 1)
+		witness := tx.WitnessSet.VKeyWitnessSet[0]
+		require.True(t, ed25519.Verify(ed25519.PublicKey(witness.VKey), hash, witness.Signature))
+
+		// The fee was computed for the witnessed size
+		params, err := chain.(*CardanoBlockchain).protocolParams(context.Background())
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, uint64(tx.Body.Fee), params.minFeeA*uint64(len(provider.submitted[0]))+params.minFeeB)
+	})
+}
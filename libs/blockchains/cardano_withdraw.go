@@ -0,0 +1,381 @@
+package blockchains
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/blockfrost/blockfrost-go"
+	"github.com/echovl/cardano-go"
+	"github.com/fxamacker/cbor/v2"
+)
+
+const (
+	cardanoLovelace = "lovelace"
+	// Length of a minting policy ID, the first part of an asset unit
+	cardanoPolicyIDLength = 28
+	// Slots (one per second) a withdrawal stays valid after the latest block
+	cardanoWithdrawTTL = 2 * 60 * 60
+	// Bytes of ledger bookkeeping added to the size of an output for the min-UTxO rule (Babbage)
+	cardanoUTxOEntryOverhead = 160
+	// Bytes of a word, the unit of the coinsPerUTxOWord parameter before Babbage
+	cardanoUTxOWordSize = 8
+	// Page size of Blockfrost list endpoints
+	cardanoUTxOPageSize = 100
+	// Length of an ed25519 public key and signature in a vkey witness
+	cardanoVKeyLength      = 32
+	cardanoSignatureLength = 64
+)
+
+var errCardanoInsufficientFunds = errors.New("insufficient funds")
+
+// cardanoAssets holds quantities by asset unit: "lovelace" or the hex policy ID followed by the hex asset name
+type cardanoAssets map[string]uint64
+
+func (a cardanoAssets) add(other cardanoAssets) {
+	for unit, quantity := range other {
+		a[unit] += quantity
+	}
+}
+
+// covers reports whether a holds at least the quantities of other
+func (a cardanoAssets) covers(other cardanoAssets) bool {
+	for unit, quantity := range other {
+		if a[unit] < quantity {
+			return false
+		}
+	}
+	return true
+}
+
+// sub returns a - other, which must be covered by a. Assets left with a zero quantity are dropped.
+func (a cardanoAssets) sub(other cardanoAssets) cardanoAssets {
+	result := cardanoAssets{}
+	for unit, quantity := range a {
+		if left := quantity - other[unit]; left > 0 || unit == cardanoLovelace {
+			result[unit] = left
+		}
+	}
+	return result
+}
+
+// tokens returns the native assets, without lovelace
+func (a cardanoAssets) tokens() cardanoAssets {
+	result := cardanoAssets{}
+	for unit, quantity := range a {
+		if unit != cardanoLovelace && quantity > 0 {
+			result[unit] = quantity
+		}
+	}
+	return result
+}
+
+// value converts the assets to a cardano-go value
+func (a cardanoAssets) value() (*cardano.Value, error) {
+	multiAsset := cardano.NewMultiAsset()
+	for unit, quantity := range a.tokens() {
+		policyID, assetName, err := parseCardanoAssetUnit(unit)
+		if err != nil {
+			return nil, err
+		}
+		assets := multiAsset.Get(policyID)
+		if assets == nil {
+			assets = cardano.NewAssets()
+			multiAsset.Set(policyID, assets)
+		}
+		assets.Set(assetName, cardano.BigNum(quantity))
+	}
+	return cardano.NewValueWithAssets(cardano.Coin(a[cardanoLovelace]), multiAsset), nil
+}
+
+// normalizeCardanoAssetUnit accepts an asset unit as returned by Blockfrost or with a dot
+// between the policy ID and the asset name, and returns it in the Blockfrost form
+func normalizeCardanoAssetUnit(unit string) (string, error) {
+	unit = strings.ToLower(strings.Replace(unit, ".", "", 1))
+	if _, _, err := parseCardanoAssetUnit(unit); err != nil {
+		return "", err
+	}
+	return unit, nil
+}
+
+func parseCardanoAssetUnit(unit string) (cardano.PolicyID, cardano.AssetName, error) {
+	unitBytes, err := hex.DecodeString(unit)
+	if err != nil {
+		return cardano.PolicyID{}, cardano.AssetName{}, fmt.Errorf("invalid asset unit %s: %v", unit, err)
+	}
+	if len(unitBytes) < cardanoPolicyIDLength || len(unitBytes) > cardanoPolicyIDLength+32 {
+		return cardano.PolicyID{}, cardano.AssetName{}, fmt.Errorf("invalid asset unit length: %s", unit)
+	}
+	policyID := cardano.NewPolicyIDFromHash(cardano.Hash28(unitBytes[:cardanoPolicyIDLength]))
+	assetName := cardano.NewAssetName(string(unitBytes[cardanoPolicyIDLength:]))
+	return policyID, assetName, nil
+}
+
+// cardanoUTxO is an unspent output of the bridge address
+type cardanoUTxO struct {
+	txHash cardano.Hash32
+	index  uint64
+	assets cardanoAssets
+}
+
+// cardanoProtocolParams are the protocol parameters needed to balance a transaction
+type cardanoProtocolParams struct {
+	minFeeA          uint64
+	minFeeB          uint64
+	coinsPerUTxOByte uint64
+	maxTxSize        int
+}
+
+func (b *CardanoBlockchain) protocolParams(ctx context.Context) (*cardanoProtocolParams, error) {
+	params, err := b.client.LatestEpochParameters(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get protocol parameters: %v", err)
+	}
+
+	var coins uint64
+	if params.CoinsPerUTxOSize != nil {
+		if coins, err = strconv.ParseUint(*params.CoinsPerUTxOSize, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid coins per UTxO byte: %v", err)
+		}
+	} else {
+		// Babbage converted the per-word price to a per-byte one by dividing by the word size
+		coinsPerWord, err := strconv.ParseUint(params.CoinsPerUtxOWord, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid coins per UTxO word: %v", err)
+		}
+		coins = coinsPerWord / cardanoUTxOWordSize
+	}
+	if coins == 0 {
+		return nil, fmt.Errorf("missing coins per UTxO byte parameter")
+	}
+	if params.MinFeeA < 0 || params.MinFeeB < 0 {
+		return nil, fmt.Errorf("invalid fee parameters: %d, %d", params.MinFeeA, params.MinFeeB)
+	}
+
+	return &cardanoProtocolParams{
+		minFeeA:          uint64(params.MinFeeA),
+		minFeeB:          uint64(params.MinFeeB),
+		coinsPerUTxOByte: coins,
+		maxTxSize:        params.MaxTxSize,
+	}, nil
+}
+
+// minUTxO returns the minimum lovelace an output must hold, (160 + output size) * coinsPerUTxOByte.
+// The size depends on the lovelace quantity itself, so it is iterated to a fixed point.
+func (p *cardanoProtocolParams) minUTxO(address cardano.Address, assets cardanoAssets) (uint64, error) {
+	output := cardanoAssets{}
+	output.add(assets)
+
+	var minimum uint64
+	for {
+		output[cardanoLovelace] = minimum
+		value, err := output.value()
+		if err != nil {
+			return 0, err
+		}
+		outputBytes, err := cbor.Marshal(cardano.NewTxOutput(address, value))
+		if err != nil {
+			return 0, fmt.Errorf("failed to serialize output: %v", err)
+		}
+		required := (cardanoUTxOEntryOverhead + uint64(len(outputBytes))) * p.coinsPerUTxOByte
+		if required <= minimum {
+			return minimum, nil
+		}
+		minimum = required
+	}
+}
+
+// minFee returns the linear fee minFeeA * size + minFeeB of tx once it carries the bridge's vkey witness
+func (p *cardanoProtocolParams) minFee(tx *cardano.Tx) (uint64, error) {
+	witnessed := *tx
+	witnessed.WitnessSet.VKeyWitnessSet = append(append([]cardano.VKeyWitness{}, tx.WitnessSet.VKeyWitnessSet...), cardano.VKeyWitness{
+		VKey:      make([]byte, cardanoVKeyLength),
+		Signature: make([]byte, cardanoSignatureLength),
+	})
+
+	size := len(witnessed.Bytes())
+	if p.maxTxSize > 0 && size > p.maxTxSize {
+		return 0, fmt.Errorf("transaction size %d exceeds the maximum of %d", size, p.maxTxSize)
+	}
+	return p.minFeeA*uint64(size) + p.minFeeB, nil
+}
+
+// addressUTxOs returns all unspent outputs of address
+func (b *CardanoBlockchain) addressUTxOs(ctx context.Context, address string) ([]cardanoUTxO, error) {
+	var utxos []cardanoUTxO
+	for page := 1; ; page++ {
+		results, err := b.client.AddressUTXOs(ctx, address, blockfrost.APIQueryParams{Count: cardanoUTxOPageSize, Page: page})
+		if err != nil {
+			if strings.Contains(err.Error(), "StatusCode:404") {
+				// Addresses that never received funds are unknown to Blockfrost
+				return utxos, nil
+			}
+			return nil, fmt.Errorf("failed to get UTxOs of %s: %v", address, err)
+		}
+
+		for _, result := range results {
+			txHash, err := cardano.NewHash32(result.TxHash)
+			if err != nil {
+				return nil, fmt.Errorf("invalid UTxO transaction hash %s: %v", result.TxHash, err)
+			}
+			utxo := cardanoUTxO{txHash: txHash, index: uint64(result.OutputIndex), assets: cardanoAssets{}}
+			for _, amount := range result.Amount {
+				quantity, err := strconv.ParseUint(amount.Quantity, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid UTxO quantity %s: %v", amount.Quantity, err)
+				}
+				utxo.assets[amount.Unit] += quantity
+			}
+			utxos = append(utxos, utxo)
+		}
+
+		if len(results) < cardanoUTxOPageSize {
+			return utxos, nil
+		}
+	}
+}
+
+// buildWithdrawTx pays amount of unit from the bridge address to recipient. Bridge UTxOs are
+// selected until they cover the payment, the fee and a change output that satisfies the
+// min-UTxO rule. Outputs carrying native assets also get the minimum lovelace they require.
+func (b *CardanoBlockchain) buildWithdrawTx(ctx context.Context, bridge cardano.Address, recipient cardano.Address, unit string, amount uint64) (*cardano.Tx, error) {
+	params, err := b.protocolParams(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tip, err := b.client.BlockLatest(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest block: %v", err)
+	}
+	ttl := uint64(tip.Slot) + cardanoWithdrawTTL
+
+	payment := cardanoAssets{unit: amount}
+	minimum, err := params.minUTxO(recipient, payment)
+	if err != nil {
+		return nil, err
+	}
+	if unit == cardanoLovelace {
+		if amount < minimum {
+			return nil, fmt.Errorf("amount %d is below the minimum UTxO value of %d lovelace", amount, minimum)
+		}
+	} else {
+		payment[cardanoLovelace] = minimum
+	}
+
+	utxos, err := b.addressUTxOs(ctx, bridge.Bech32())
+	if err != nil {
+		return nil, err
+	}
+
+	// Largest holdings of the withdrawn asset first, then largest lovelace amounts, so that
+	// few inputs are needed and token change stays small
+	sort.SliceStable(utxos, func(i, j int) bool {
+		if unit != cardanoLovelace && utxos[i].assets[unit] != utxos[j].assets[unit] {
+			return utxos[i].assets[unit] > utxos[j].assets[unit]
+		}
+		return utxos[i].assets[cardanoLovelace] > utxos[j].assets[cardanoLovelace]
+	})
+
+	var selected []cardanoUTxO
+	inputs := cardanoAssets{}
+	for _, utxo := range utxos {
+		selected = append(selected, utxo)
+		inputs.add(utxo.assets)
+		if !inputs.covers(payment) {
+			continue
+		}
+
+		tx, err := params.balance(selected, inputs, recipient, payment, bridge, ttl)
+		if errors.Is(err, errCardanoInsufficientFunds) {
+			continue
+		}
+		return tx, err
+	}
+
+	return nil, fmt.Errorf("%w: bridge holds %v, withdrawal needs %v plus fees", errCardanoInsufficientFunds, inputs, payment)
+}
+
+// balance builds a transaction spending selected, paying payment to recipient and returning
+// the rest to the change address. Lovelace change below the min-UTxO value is added to the fee.
+func (p *cardanoProtocolParams) balance(selected []cardanoUTxO, inputs cardanoAssets, recipient cardano.Address, payment cardanoAssets, change cardano.Address, ttl uint64) (*cardano.Tx, error) {
+	paymentValue, err := payment.value()
+	if err != nil {
+		return nil, err
+	}
+
+	leftover := inputs.sub(payment)
+	changeTokens := leftover.tokens()
+
+	var fee uint64
+	for {
+		if leftover[cardanoLovelace] < fee {
+			return nil, errCardanoInsufficientFunds
+		}
+
+		txBuilder := cardano.NewTxBuilder(&cardano.ProtocolParams{})
+		for _, utxo := range selected {
+			inputValue, err := utxo.assets.value()
+			if err != nil {
+				return nil, err
+			}
+			txBuilder.AddInputs(cardano.NewTxInput(utxo.txHash, uint(utxo.index), inputValue))
+		}
+		txBuilder.AddOutputs(cardano.NewTxOutput(recipient, paymentValue))
+
+		changeAssets := cardanoAssets{cardanoLovelace: leftover[cardanoLovelace] - fee}
+		changeAssets.add(changeTokens)
+		hasChange := len(changeTokens) > 0 || changeAssets[cardanoLovelace] > 0
+		if hasChange {
+			minimum, err := p.minUTxO(change, changeAssets)
+			if err != nil {
+				return nil, err
+			}
+			if changeAssets[cardanoLovelace] < minimum {
+				if len(changeTokens) > 0 {
+					return nil, errCardanoInsufficientFunds
+				}
+				hasChange = false
+			}
+		}
+		if hasChange {
+			changeValue, err := changeAssets.value()
+			if err != nil {
+				return nil, err
+			}
+			txBuilder.AddOutputs(cardano.NewTxOutput(change, changeValue))
+		}
+
+		txBuilder.SetTTL(ttl)
+		if hasChange {
+			txBuilder.SetFee(cardano.Coin(fee))
+		} else {
+			txBuilder.SetFee(cardano.Coin(leftover[cardanoLovelace]))
+		}
+
+		// The builder checks that inputs equal outputs plus fee
+		tx, err := txBuilder.Build()
+		if err != nil {
+			return nil, fmt.Errorf("failed to build transaction: %v", err)
+		}
+
+		required, err := p.minFee(tx)
+		if err != nil {
+			return nil, err
+		}
+		if !hasChange {
+			if leftover[cardanoLovelace] < required {
+				return nil, errCardanoInsufficientFunds
+			}
+			return tx, nil
+		}
+		if required <= fee {
+			return tx, nil
+		}
+		fee = required
+	}
+}
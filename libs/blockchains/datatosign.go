@@ -0,0 +1,41 @@
+package blockchains
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A transaction needing several signatures, such as a Bitcoin withdrawal spending several
+// inputs, lists one hash per input in its data to sign, separated by BatchSeparator. The
+// hashes are signed in one batch and the signatures are passed to BroadcastTransaction in
+// the same order and with the same separator.
+
+const BatchSeparator = ","
+
+// SplitDataToSign returns the hashes listed in an operation's data to sign
+func SplitDataToSign(dataToSign string) []string {
+	if dataToSign == "" {
+		return nil
+	}
+	return strings.Split(dataToSign, BatchSeparator)
+}
+
+// JoinDataToSign returns the data to sign of a transaction with the given input hashes
+func JoinDataToSign(hashes []string) string {
+	return strings.Join(hashes, BatchSeparator)
+}
+
+// JoinSignatures returns the signatures of a batch as passed to BroadcastTransaction
+func JoinSignatures(signatures []string) string {
+	return strings.Join(signatures, BatchSeparator)
+}
+
+// inputSignatures returns the signature of every input of a transaction. Every input has
+// its own sighash, so there must be exactly one signature per input.
+func inputSignatures(signatures string, inputs int) ([]string, error) {
+	split := strings.Split(signatures, BatchSeparator)
+	if len(split) != inputs {
+		return nil, fmt.Errorf("got %d signatures for %d inputs", len(split), inputs)
+	}
+	return split, nil
+}
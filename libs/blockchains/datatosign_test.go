@@ -0,0 +1,286 @@
+package blockchains
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	algoCrypto "github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/encoding/msgpack"
+	algoTypes "github.com/algorand/go-algorand-sdk/types"
+	aptos "github.com/aptos-labs/aptos-go-sdk"
+	aptosBcs "github.com/aptos-labs/aptos-go-sdk/bcs"
+	aptosCrypto "github.com/aptos-labs/aptos-go-sdk/crypto"
+	"github.com/btcsuite/btcd/btcec/v2"
+	btcecdsa "github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/coming-chat/go-sui/v2/sui_types"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/fardream/go-bcs/bcs"
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/txnbuild"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/blake2b"
+)
+
+func TestDataToSignBatch(t *testing.T) {
+	hashes := []string{"aa", "bb", "cc"}
+	require.Equal(t, hashes, SplitDataToSign(JoinDataToSign(hashes)))
+	require.Equal(t, []string{"aa"}, SplitDataToSign("aa"))
+	require.Empty(t, SplitDataToSign(""))
+}
+
+func TestInputSignatures(t *testing.T) {
+	signatures, err := inputSignatures(JoinSignatures([]string{"s1", "s2"}), 2)
+	require.NoError(t, err)
+	require.Equal(t, []string{"s1", "s2"}, signatures)
+
+	// A signature of one input does not sign the others
+	_, err = inputSignatures("s1", 3)
+	require.Error(t, err)
+
+	_, err = inputSignatures(JoinSignatures([]string{"s1", "s2"}), 3)
+	require.Error(t, err)
+}
+
+func TestEVMComputeDataToSign(t *testing.T) {
+	key, err := crypto.ToECDSA(bytes32(1))
+	require.NoError(t, err)
+	to := crypto.PubkeyToAddress(key.PublicKey)
+
+	chainID := "11155111"
+	chain := &EVMBlockchain{BaseBlockchain: BaseBlockchain{chainName: Ethereum, chainID: &chainID}}
+	signer := types.NewLondonSigner(big.NewInt(11155111))
+
+	txs := map[string]*types.Transaction{
+		"legacy": types.NewTx(&types.LegacyTx{Nonce: 1, GasPrice: big.NewInt(1e9), Gas: 21000, To: &to, Value: big.NewInt(1)}),
+		"dynamic fee": types.NewTx(&types.DynamicFeeTx{
+			ChainID: big.NewInt(11155111), Nonce: 2, GasTipCap: big.NewInt(1e9), GasFeeCap: big.NewInt(2e9), Gas: 21000, To: &to, Value: big.NewInt(1),
+		}),
+	}
+	for name, tx := range txs {
+		t.Run(name, func(t *testing.T) {
+			txBytes, err := rlp.EncodeToBytes(tx)
+			require.NoError(t, err)
+
+			dataToSign, err := chain.ComputeDataToSign(hex.EncodeToString(txBytes))
+			require.NoError(t, err)
+			hash, err := hex.DecodeString(dataToSign)
+			require.NoError(t, err)
+
+			// A signature of the data to sign makes a transaction from the signer
+			signature, err := crypto.Sign(hash, key)
+			require.NoError(t, err)
+			signed, err := tx.WithSignature(signer, signature)
+			require.NoError(t, err)
+			sender, err := types.Sender(signer, signed)
+			require.NoError(t, err)
+			require.Equal(t, to, sender)
+		})
+	}
+
+	// Typed transactions name their chain
+	otherChain := types.NewTx(&types.DynamicFeeTx{ChainID: big.NewInt(1), Gas: 21000, To: &to})
+	txBytes, err := rlp.EncodeToBytes(otherChain)
+	require.NoError(t, err)
+	_, err = chain.ComputeDataToSign(hexutil.Encode(txBytes))
+	require.ErrorContains(t, err, "transaction is for chain 1")
+}
+
+func TestInputSigHashes(t *testing.T) {
+	key, _ := btcec.PrivKeyFromBytes(bytes32(1))
+	pubKeyHash := btcutil.Hash160(key.PubKey().SerializeCompressed())
+	witnessScript, err := txscript.NewScriptBuilder().AddOp(txscript.OP_0).AddData(pubKeyHash).Script()
+	require.NoError(t, err)
+	legacyScript, err := txscript.NewScriptBuilder().
+		AddOp(txscript.OP_DUP).AddOp(txscript.OP_HASH160).AddData(pubKeyHash).
+		AddOp(txscript.OP_EQUALVERIFY).AddOp(txscript.OP_CHECKSIG).Script()
+	require.NoError(t, err)
+
+	msgTx := wire.NewMsgTx(wire.TxVersion)
+	msgTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{1}, 0), nil, nil))
+	msgTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{2}, 1), nil, nil))
+	msgTx.AddTxOut(wire.NewTxOut(90_000, legacyScript))
+	prevOuts := []*wire.TxOut{wire.NewTxOut(50_000, witnessScript), wire.NewTxOut(50_000, legacyScript)}
+
+	hashes, err := inputSigHashes(msgTx, prevOuts)
+	require.NoError(t, err)
+	require.Len(t, hashes, 2)
+
+	// Signatures made by btcd for each input are over the computed hashes
+	fetcher := txscript.NewMultiPrevOutFetcher(nil)
+	for i, txIn := range msgTx.TxIn {
+		fetcher.AddPrevOut(txIn.PreviousOutPoint, prevOuts[i])
+	}
+	witnessSig, err := txscript.RawTxInWitnessSignature(msgTx, txscript.NewTxSigHashes(msgTx, fetcher), 0, 50_000, witnessScript, txscript.SigHashAll, key)
+	require.NoError(t, err)
+	legacySig, err := txscript.RawTxInSignature(msgTx, 1, legacyScript, txscript.SigHashAll, key)
+	require.NoError(t, err)
+
+	for i, sig := range [][]byte{witnessSig, legacySig} {
+		hash, err := hex.DecodeString(hashes[i])
+		require.NoError(t, err)
+		parsed, err := btcecdsa.ParseDERSignature(sig[:len(sig)-1])
+		require.NoError(t, err)
+		require.True(t, parsed.Verify(hash, key.PubKey()), "input %d", i)
+	}
+
+	_, err = inputSigHashes(msgTx, prevOuts[:1])
+	require.Error(t, err)
+}
+
+func TestBitcoinBuildWithdrawTxDataToSign(t *testing.T) {
+	b := &BitcoinBlockchain{chainParams: &chaincfg.TestNet3Params}
+	key, _ := btcec.PrivKeyFromBytes(bytes32(1))
+	pubKeyHash := btcutil.Hash160(key.PubKey().SerializeCompressed())
+	witnessAccount, err := btcutil.NewAddressWitnessPubKeyHash(pubKeyHash, b.chainParams)
+	require.NoError(t, err)
+	legacyAccount, err := btcutil.NewAddressPubKeyHash(pubKeyHash, b.chainParams)
+	require.NoError(t, err)
+
+	for _, account := range []btcutil.Address{witnessAccount, legacyAccount} {
+		serializedTxn, dataToSign, err := b.BuildWithdrawTx(account.EncodeAddress(), `{"amount": "0.001"}`, legacyAccount.EncodeAddress(), nil)
+		require.NoError(t, err)
+
+		// One sighash per input, over the output of the account the input spends
+		msgTx, err := parseSerializedTransaction(serializedTxn)
+		require.NoError(t, err)
+		script, err := txscript.PayToAddrScript(account)
+		require.NoError(t, err)
+		prevOuts := make([]*wire.TxOut, len(msgTx.TxIn))
+		for i := range prevOuts {
+			prevOuts[i] = wire.NewTxOut(100_000, script)
+		}
+		hashes, err := inputSigHashes(msgTx, prevOuts)
+		require.NoError(t, err)
+		require.Equal(t, JoinDataToSign(hashes), dataToSign, account.EncodeAddress())
+	}
+}
+
+func TestAlgorandComputeDataToSign(t *testing.T) {
+	key := ed25519.NewKeyFromSeed(bytes32(1))
+	var sender algoTypes.Address
+	copy(sender[:], key.Public().(ed25519.PublicKey))
+
+	tx := algoTypes.Transaction{
+		Type: algoTypes.PaymentTx,
+		Header: algoTypes.Header{
+			Sender:      sender,
+			Fee:         1000,
+			FirstValid:  1,
+			LastValid:   1001,
+			GenesisHash: algoTypes.Digest{1},
+		},
+		PaymentTxnFields: algoTypes.PaymentTxnFields{Receiver: sender, Amount: 1},
+	}
+
+	dataToSign, err := (&AlgorandBlockchain{}).ComputeDataToSign(base64.StdEncoding.EncodeToString(msgpack.Encode(tx)))
+	require.NoError(t, err)
+	message, err := base64.StdEncoding.DecodeString(dataToSign)
+	require.NoError(t, err)
+
+	_, stxBytes, err := algoCrypto.SignTransaction(key, tx)
+	require.NoError(t, err)
+	var stx algoTypes.SignedTxn
+	require.NoError(t, msgpack.Decode(stxBytes, &stx))
+	require.True(t, ed25519.Verify(key.Public().(ed25519.PublicKey), message, stx.Sig[:]))
+}
+
+func TestStellarComputeDataToSign(t *testing.T) {
+	kp, err := keypair.FromRawSeed([32]byte{1})
+	require.NoError(t, err)
+
+	tx, err := txnbuild.NewTransaction(txnbuild.TransactionParams{
+		SourceAccount:        &txnbuild.SimpleAccount{AccountID: kp.Address(), Sequence: 1},
+		IncrementSequenceNum: true,
+		Operations: []txnbuild.Operation{&txnbuild.Payment{
+			Destination: kp.Address(),
+			Amount:      "1",
+			Asset:       txnbuild.NativeAsset{},
+		}},
+		BaseFee:       txnbuild.MinBaseFee,
+		Preconditions: txnbuild.Preconditions{TimeBounds: txnbuild.NewInfiniteTimeout()},
+	})
+	require.NoError(t, err)
+	tx, err = tx.Sign(network.TestNetworkPassphrase, kp)
+	require.NoError(t, err)
+	serializedTxn, err := tx.Base64()
+	require.NoError(t, err)
+
+	signature := tx.Signatures()[0].Signature
+	for _, tt := range []struct {
+		networkType NetworkType
+		valid       bool
+	}{{Testnet, true}, {Mainnet, false}} {
+		chain := &StellarBlockchain{BaseBlockchain: BaseBlockchain{network: Network{networkType: tt.networkType}}}
+		dataToSign, err := chain.ComputeDataToSign(serializedTxn)
+		require.NoError(t, err)
+		hash, err := base64.StdEncoding.DecodeString(dataToSign)
+		require.NoError(t, err)
+		require.Equal(t, tt.valid, kp.Verify(hash, signature) == nil, tt.networkType)
+	}
+}
+
+func TestSuiComputeDataToSign(t *testing.T) {
+	sender, err := sui_types.NewAddressFromHex("0x1")
+	require.NoError(t, err)
+	gasCoin, err := sui_types.NewObjectIdFromHex("0x2")
+	require.NoError(t, err)
+	digest, err := sui_types.NewDigest("11111111111111111111111111111111")
+	require.NoError(t, err)
+
+	builder := sui_types.NewProgrammableTransactionBuilder()
+	amount := uint64(1)
+	require.NoError(t, builder.TransferSui(*sender, &amount))
+	tx := sui_types.NewProgrammable(*sender, []*sui_types.ObjectRef{{ObjectId: *gasCoin, Version: 1, Digest: *digest}}, builder.Finish(), 1000, 1)
+	txBytes, err := bcs.Marshal(tx)
+	require.NoError(t, err)
+
+	dataToSign, err := (&SuiBlockchain{}).ComputeDataToSign(base64.StdEncoding.EncodeToString(txBytes))
+	require.NoError(t, err)
+
+	// Sui signs the digest of the transaction data behind the intent bytes 0, 0, 0
+	expected := blake2b.Sum256(append([]byte{0, 0, 0}, txBytes...))
+	require.Equal(t, base64.StdEncoding.EncodeToString(expected[:]), dataToSign)
+
+	_, err = (&SuiBlockchain{}).ComputeDataToSign(base64.StdEncoding.EncodeToString(append(txBytes, 0)))
+	require.Error(t, err)
+}
+
+func TestAptosComputeDataToSign(t *testing.T) {
+	key := &aptosCrypto.Ed25519PrivateKey{}
+	require.NoError(t, key.FromBytes(bytes32(1)))
+
+	payload, err := aptos.CoinTransferPayload(nil, aptos.AccountOne, 1)
+	require.NoError(t, err)
+	rawTxn := &aptos.RawTransaction{
+		Sender:                     aptos.AccountOne,
+		SequenceNumber:             1,
+		Payload:                    aptos.TransactionPayload{Payload: payload},
+		MaxGasAmount:               DEFAULT_MAX_GAS_AMOUNT,
+		GasUnitPrice:               DEFAULT_GAS_UNIT_PRICE,
+		ExpirationTimestampSeconds: 1_700_000_000,
+		ChainId:                    2,
+	}
+	txnBytes, err := aptosBcs.Serialize(rawTxn)
+	require.NoError(t, err)
+
+	dataToSign, err := (&AptosBlockchain{}).ComputeDataToSign(hex.EncodeToString(txnBytes))
+	require.NoError(t, err)
+	message, err := hex.DecodeString(dataToSign)
+	require.NoError(t, err)
+
+	authenticator, err := rawTxn.Sign(key)
+	require.NoError(t, err)
+	require.True(t, authenticator.Verify(message))
+}
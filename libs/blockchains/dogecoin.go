@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"math/big"
 	"net/http"
 	"os"
@@ -171,30 +172,32 @@ func (b *DogecoinBlockchain) BroadcastTransaction(txn string, signatureHex strin
 		return "", fmt.Errorf("error parsing transaction: %v", err)
 	}
 
-	// Step 2: Create DER signature
-	derSignatureHex, err := derEncode(signatureHex)
+	// Step 2: Add the signature of every input to the transaction
+	signatures, err := inputSignatures(signatureHex, len(msgTx.TxIn))
 	if err != nil {
-		return "", fmt.Errorf("error encoding signature: %v", err)
-	}
-	derSignature, err := hex.DecodeString(derSignatureHex)
-	if err != nil {
-		return "", fmt.Errorf("error decoding signature: %v", err)
+		return "", err
 	}
+	for i, signature := range signatures {
+		derSignatureHex, err := derEncode(signature)
+		if err != nil {
+			return "", fmt.Errorf("error encoding signature: %v", err)
+		}
+		derSignature, err := hex.DecodeString(derSignatureHex)
+		if err != nil {
+			return "", fmt.Errorf("error decoding signature: %v", err)
+		}
 
-	// Step 3: Add signature to the transaction
-	sigScript, err := txscript.NewScriptBuilder().
-		AddData(derSignature).
-		AddData(pubKeyBytes).
-		Script()
-	if err != nil {
-		return "", fmt.Errorf("error creating signature script: %v", err)
-	}
-	// Set the signature script for the input
-	for i := range msgTx.TxIn {
+		sigScript, err := txscript.NewScriptBuilder().
+			AddData(derSignature).
+			AddData(pubKeyBytes).
+			Script()
+		if err != nil {
+			return "", fmt.Errorf("error creating signature script: %v", err)
+		}
 		msgTx.TxIn[i].SignatureScript = sigScript
 	}
 
-	// Step 4: Serialize the transaction
+	// Step 3: Serialize the transaction
 	var signedTxBuffer bytes.Buffer
 	if err := msgTx.Serialize(&signedTxBuffer); err != nil {
 		return "", fmt.Errorf("error serializing signed transaction: %v", err)
@@ -326,23 +329,32 @@ func (b *DogecoinBlockchain) BuildWithdrawTx(bridgeAddress string,
 			return "", "", fmt.Errorf("failed to serialize transaction: %v", err)
 		}
 
-		// Create a new transaction for dataToSign
-		msgTxToSign := wire.NewMsgTx(wire.TxVersion)
-		txIn = wire.NewTxIn(dummyOutpoint, script, nil)
-		msgTxToSign.AddTxIn(txIn)
-		msgTxToSign.AddTxOut(txOut)
+		// Every input signs a copy of the transaction with the script in that input only
+		hashes := make([]string, len(msgTx.TxIn))
+		for i := range msgTx.TxIn {
+			msgTxToSign := wire.NewMsgTx(wire.TxVersion)
+			for j, in := range msgTx.TxIn {
+				var sigScript []byte
+				if i == j {
+					sigScript = script
+				}
+				msgTxToSign.AddTxIn(wire.NewTxIn(&in.PreviousOutPoint, sigScript, nil))
+			}
+			msgTxToSign.AddTxOut(txOut)
 
-		// Serialize the transaction to sign
-		var txBufToSign bytes.Buffer
-		err = msgTxToSign.Serialize(&txBufToSign)
-		if err != nil {
-			return "", "", fmt.Errorf("failed to serialize transaction: %v", err)
-		}
+			// Serialize the transaction to sign
+			var txBufToSign bytes.Buffer
+			err = msgTxToSign.Serialize(&txBufToSign)
+			if err != nil {
+				return "", "", fmt.Errorf("failed to serialize transaction: %v", err)
+			}
 
-		// Hash-256 the transaction to get dataToSign
-		dataToSign := sha256.Sum256(txBufToSign.Bytes())
+			// Hash-256 the transaction to get dataToSign
+			dataToSign := sha256.Sum256(txBufToSign.Bytes())
+			hashes[i] = hex.EncodeToString(dataToSign[:])
+		}
 
-		return hex.EncodeToString(txBuf.Bytes()), hex.EncodeToString(dataToSign[:]), nil
+		return hex.EncodeToString(txBuf.Bytes()), JoinDataToSign(hashes), nil
 	}
 	return "", "", errors.New("BuildWithdrawTx not implemented")
 }
@@ -355,6 +367,39 @@ func (b *DogecoinBlockchain) RawPublicKeyToPublicKeyStr(pkBytes []byte) (string,
 	return "", errors.New("RawPublicKeyToPublicKeyStr not implemented")
 }
 
+// ComputeDataToSign returns the sighash of every input of the transaction, Dogecoin
+// hashes inputs as legacy Bitcoin does. The outputs spent are fetched from the node.
+func (b *DogecoinBlockchain) ComputeDataToSign(serializedTxn string) (string, error) {
+	msgTx, err := parseSerializedTransaction(serializedTxn)
+	if err != nil {
+		return "", fmt.Errorf("error parsing transaction: %v", err)
+	}
+
+	prevOuts := make([]*wire.TxOut, len(msgTx.TxIn))
+	for i, txIn := range msgTx.TxIn {
+		outPoint := txIn.PreviousOutPoint
+		prevTx, err := b.client.GetTransaction(outPoint.Hash.String())
+		if err != nil {
+			return "", fmt.Errorf("failed to fetch previous tx %s: %w", outPoint.Hash, err)
+		}
+		if int(outPoint.Index) >= len(prevTx.Vout) {
+			return "", fmt.Errorf("input %d spends unknown output %s", i, outPoint)
+		}
+		output := prevTx.Vout[outPoint.Index]
+		pkScript, err := hex.DecodeString(output.ScriptPubKey.Hex)
+		if err != nil {
+			return "", fmt.Errorf("invalid script of output %s: %v", outPoint, err)
+		}
+		prevOuts[i] = wire.NewTxOut(int64(math.Round(output.Value*1e8)), pkScript)
+	}
+
+	hashes, err := inputSigHashes(msgTx, prevOuts)
+	if err != nil {
+		return "", err
+	}
+	return JoinDataToSign(hashes), nil
+}
+
 // GetTransaction gets a transaction by its hash
 func (c *DogeRPCClient) GetTransaction(txHash string) (*Transaction, error) {
 	response, err := c.call("getrawtransaction", []interface{}{txHash, true})
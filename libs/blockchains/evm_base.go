@@ -812,11 +812,11 @@ func (b *EVMBlockchain) ExtractDestinationAddress(serializedTxn string) (string,
 	tokenAddress := ""
 	txBytes, err := hex.DecodeString(serializedTxn)
 	if err != nil {
-		return "", "", fmt.Errorf("error decoding EVM transaction", err)
+		return "", "", fmt.Errorf("error decoding EVM transaction: %v", err)
 	}
 	tx := new(types.Transaction)
 	if err := rlp.DecodeBytes(txBytes, tx); err != nil {
-		return "", "", fmt.Errorf("error deserializing EVM transaction", err)
+		return "", "", fmt.Errorf("error deserializing EVM transaction: %v", err)
 	}
 	if tx.To() == nil {
 		return "", "", fmt.Errorf("EVM transaction has nil To address")
@@ -835,3 +835,46 @@ func (b *EVMBlockchain) ExtractDestinationAddress(serializedTxn string) (string,
 	}
 	return destAddress, tokenAddress, nil
 }
+
+func (b *EVMBlockchain) ExtractTransferAmount(serializedTxn string) (string, error) {
+	txBytes, err := hex.DecodeString(strings.TrimPrefix(serializedTxn, "0x"))
+	if err != nil {
+		return "", fmt.Errorf("error decoding EVM transaction: %v", err)
+	}
+	tx := new(types.Transaction)
+	if err := rlp.DecodeBytes(txBytes, tx); err != nil {
+		return "", fmt.Errorf("error deserializing EVM transaction: %v", err)
+	}
+	if len(tx.Data()) >= 4 && bytes.Equal(tx.Data()[:4], []byte{0xa9, 0x05, 0x9c, 0xbb}) {
+		// ERC20 transfer(address,uint256)
+		if len(tx.Data()) < 68 {
+			return "", fmt.Errorf("ERC20 transfer data too short to extract amount")
+		}
+		return new(big.Int).SetBytes(tx.Data()[36:68]).String(), nil
+	}
+	return tx.Value().String(), nil
+}
+
+// ComputeDataToSign returns the hash the sender signs for an RLP encoded transaction
+func (b *EVMBlockchain) ComputeDataToSign(serializedTxn string) (string, error) {
+	txBytes, err := hex.DecodeString(strings.TrimPrefix(serializedTxn, "0x"))
+	if err != nil {
+		return "", fmt.Errorf("error decoding EVM transaction: %v", err)
+	}
+	tx := new(types.Transaction)
+	if err := rlp.DecodeBytes(txBytes, tx); err != nil {
+		return "", fmt.Errorf("error deserializing EVM transaction: %v", err)
+	}
+
+	chainID, ok := new(big.Int).SetString(*b.chainID, 10)
+	if !ok {
+		return "", fmt.Errorf("invalid chain id %s", *b.chainID)
+	}
+	// Unsigned legacy transactions carry no chain id, the signer hash adds it
+	if tx.Type() != types.LegacyTxType && tx.ChainId().Cmp(chainID) != 0 {
+		return "", fmt.Errorf("transaction is for chain %s, not %s", tx.ChainId(), chainID)
+	}
+
+	// BroadcastTransaction attaches the signature with the same signer
+	return fmt.Sprintf("%x", types.NewLondonSigner(chainID).Hash(tx).Bytes()), nil
+}
@@ -1,22 +1,40 @@
 package blockchains
 
 import (
+	"bytes"
+	"crypto/ed25519"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"math"
+	"math/big"
 	"strings"
 	"time"
 
 	"github.com/StripChain/strip-node/common"
-	"github.com/StripChain/strip-node/util"
 	"github.com/rubblelabs/ripple/data"
 	"github.com/rubblelabs/ripple/websockets"
 )
 
-// NewRippleBlockchain creates a new Stellar blockchain instance
+// NewRippleBlockchain creates a new Ripple blockchain instance
 func NewRippleBlockchain(networkType NetworkType) (IBlockchain, error) {
+	network := rippleNetwork(networkType)
+
+	client, err := websockets.NewRemote(network.nodeURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ripple client: %v", err)
+	}
+
+	return newRippleBlockchain(network, client), nil
+}
+
+// NewRippleBlockchainWithClient creates a Ripple blockchain instance that reads ledger
+// state and submits transactions through client instead of a public rippled node
+func NewRippleBlockchainWithClient(networkType NetworkType, client IRippleClient) (IBlockchain, error) {
+	return newRippleBlockchain(rippleNetwork(networkType), client), nil
+}
+
+func rippleNetwork(networkType NetworkType) Network {
 	network := Network{
 		networkType: networkType,
 		nodeURL:     "wss://s1.ripple.com:51233",
@@ -27,12 +45,10 @@ func NewRippleBlockchain(networkType NetworkType) (IBlockchain, error) {
 		network.nodeURL = "wss://s.altnet.rippletest.net:51233"
 		network.networkID = "testnet"
 	}
+	return network
+}
 
-	client, err := websockets.NewRemote(network.nodeURL)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create ripple client: %v", err)
-	}
-
+func newRippleBlockchain(network Network, client IRippleClient) *RippleBlockchain {
 	return &RippleBlockchain{
 		BaseBlockchain: BaseBlockchain{
 			chainName:       Ripple,
@@ -43,29 +59,30 @@ func NewRippleBlockchain(networkType NetworkType) (IBlockchain, error) {
 			opTimeout:       time.Second * 10,
 		},
 		client: client,
-	}, nil
+	}
 }
 
+// rippleLedgerWindow is how many ledgers a built transaction stays valid for,
+// long enough for the validators to sign it
+const rippleLedgerWindow = 75
+
+// rippleMaxTrustSetFee bounds the fee in drops of a trust set signed for a wallet, which
+// pays it from its own XRP
+const rippleMaxTrustSetFee = 100_000
+
 // This is a type assertion to ensure that the RippleBlockchain implements the IBlockchain interface
 var _ IBlockchain = &RippleBlockchain{}
 
-// RippleBlockchain implements the IBlockchain interface for Stellar
+// RippleBlockchain implements the IBlockchain interface for Ripple
 type RippleBlockchain struct {
 	BaseBlockchain
-	client *websockets.Remote
+	client IRippleClient
 }
 
 func (b *RippleBlockchain) BroadcastTransaction(txn string, signatureHex string, publicKey *string) (string, error) {
-	txBytes, err := hex.DecodeString(strings.TrimPrefix(txn, "0x"))
+	tx, err := decodeRippleTransaction(txn)
 	if err != nil {
-		return "", fmt.Errorf("error decoding transaction: %v", err)
-	}
-
-	// Parse the transaction
-	var tx data.Payment
-	err = json.Unmarshal(txBytes, &tx)
-	if err != nil {
-		return "", fmt.Errorf("error unmarshalling transaction: %v", err)
+		return "", err
 	}
 
 	// Add the signature
@@ -74,29 +91,54 @@ func (b *RippleBlockchain) BroadcastTransaction(txn string, signatureHex string,
 		return "", fmt.Errorf("error decoding signature: %v", err)
 	}
 
-	// Convert public key to bytes and create data.PublicKey
-	publicKeyBytes, err := hex.DecodeString(strings.TrimPrefix(*publicKey, "0x"))
+	if publicKey == nil {
+		return "", fmt.Errorf("public key is required")
+	}
+	account, ripplePublicKey, err := rippleResolveAccount(*publicKey)
 	if err != nil {
 		return "", fmt.Errorf("error decoding public key: %v", err)
 	}
+	if ripplePublicKey == nil {
+		return "", fmt.Errorf("a public key is required to sign, got address %s", *publicKey)
+	}
 
-	var ripplePublicKey data.PublicKey
-	copy(ripplePublicKey[:], publicKeyBytes)
+	base := tx.GetBase()
+	if base.Account != *account {
+		return "", fmt.Errorf("public key does not belong to transaction account %s", base.Account)
+	}
+	if base.SigningPubKey != nil && *base.SigningPubKey != *ripplePublicKey {
+		return "", fmt.Errorf("public key does not match the key the transaction was built for")
+	}
 
 	// Set the signature
 	sigVar := data.VariableLength(sig)
-	tx.SigningPubKey = &ripplePublicKey
-	tx.TxnSignature = &sigVar
+	base.SigningPubKey = ripplePublicKey
+	base.TxnSignature = &sigVar
+
+	message, err := rippleSigningMessage(tx)
+	if err != nil {
+		return "", err
+	}
+	if !ed25519.Verify(ripplePublicKey[1:], message, sig) {
+		return "", fmt.Errorf("signature does not match transaction")
+	}
+
+	// The transaction ID is the hash of the signed transaction
+	hash, _, err := data.Raw(tx)
+	if err != nil {
+		return "", fmt.Errorf("error hashing transaction: %v", err)
+	}
 
 	// Submit the transaction
-	response, err := b.client.Submit(&tx)
+	response, err := b.client.Submit(tx)
 	if err != nil {
 		return "", fmt.Errorf("error submitting transaction: %v", err)
 	}
+	if !response.EngineResult.Success() && !response.EngineResult.Queued() {
+		return "", fmt.Errorf("transaction rejected: %s %s", response.EngineResult, response.EngineResultMessage)
+	}
 
-	txMap := response.Tx.(map[string]interface{})
-	txHash := txMap["hash"].(string)
-	return txHash, nil
+	return hash.String(), nil
 }
 
 func (b *RippleBlockchain) GetTransfers(txHash string, address *string) ([]common.Transfer, error) {
@@ -109,22 +151,37 @@ func (b *RippleBlockchain) GetTransfers(txHash string, address *string) ([]commo
 		return nil, fmt.Errorf("failed to get transaction: %v", err)
 	}
 
+	// Failed transactions only charge the fee
+	if !tx.MetaData.TransactionResult.Success() {
+		return []common.Transfer{}, nil
+	}
+
 	// Handle different transaction types
 	switch txn := tx.Transaction.(type) {
 	case *data.Payment:
-		tokenAddress := txn.Amount.Issuer.String()
-		if txn.Amount.IsNative() {
-			tokenAddress = util.ZERO_ADDRESS
+		// Partial payments may deliver less than Amount, only the delivered amount counts
+		amount := txn.Amount
+		if tx.MetaData.DeliveredAmount != nil {
+			amount = *tx.MetaData.DeliveredAmount
+		} else if txn.Flags != nil && *txn.Flags&data.TxPartialPayment != 0 {
+			return nil, fmt.Errorf("partial payment %s has no delivered amount", txHash)
+		}
+
+		token := "XRP"
+		if !amount.IsNative() {
+			token = rippleCurrencyCode(amount.Currency)
 		}
+
 		return []common.Transfer{
 			{
-				From:         txn.Account.String(),
-				To:           txn.Destination.String(),
-				Amount:       txn.Amount.Value.String(),
-				Token:        txn.Amount.Currency.String(),
-				IsNative:     txn.Amount.IsNative(),
-				TokenAddress: tokenAddress,
-				ScaledAmount: fmt.Sprintf("%d", int64(txn.Amount.Value.Float()*math.Pow(10, float64(b.Decimals())))),
+				From:           txn.Account.String(),
+				To:             txn.Destination.String(),
+				Amount:         amount.Value.String(),
+				Token:          token,
+				IsNative:       amount.IsNative(),
+				TokenAddress:   rippleTokenAddress(amount),
+				ScaledAmount:   rippleAmountToUnits(amount, b.Decimals()),
+				DestinationTag: txn.DestinationTag,
 			},
 		}, nil
 	default:
@@ -147,7 +204,7 @@ func (b *RippleBlockchain) IsTransactionBroadcastedAndConfirmed(txHash string) (
 	}
 
 	// Check if transaction is validated
-	return tx.MetaData.TransactionResult.Success(), nil
+	return tx.Validated && tx.MetaData.TransactionResult.Success(), nil
 }
 
 func (b *RippleBlockchain) BuildWithdrawTx(bridgeAddress string,
@@ -155,161 +212,256 @@ func (b *RippleBlockchain) BuildWithdrawTx(bridgeAddress string,
 	userAddress string,
 	tokenAddress *string,
 ) (string, string, error) {
-	if tokenAddress == nil {
-		var solverData map[string]interface{}
-		if err := json.Unmarshal([]byte(solverOutput), &solverData); err != nil {
-			return "", "", fmt.Errorf("failed to parse solver output: %v", err)
-		}
+	var solverData map[string]interface{}
+	if err := json.Unmarshal([]byte(solverOutput), &solverData); err != nil {
+		return "", "", fmt.Errorf("failed to parse solver output: %v", err)
+	}
 
-		amount, ok := solverData["amount"].(string)
-		if !ok {
-			return "", "", fmt.Errorf("amount not found in solver output")
-		}
+	amountStr, ok := solverData["amount"].(string)
+	if !ok {
+		return "", "", fmt.Errorf("amount not found in solver output")
+	}
 
-		amount2, err := data.NewAmount(amount)
-		if err != nil {
-			return "", "", fmt.Errorf("failed to parse amount: %v", err)
-		}
+	token, err := parseRippleToken(tokenAddress)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid token address: %v", err)
+	}
 
-		bridgeAccount, err := data.NewAccountFromAddress(bridgeAddress)
-		if err != nil {
-			return "", "", fmt.Errorf("failed to parse bridge address: %v", err)
-		}
+	amount, err := rippleAmountFromUnits(amountStr, token, b.Decimals())
+	if err != nil {
+		return "", "", err
+	}
 
-		userAccount, err := data.NewAccountFromAddress(userAddress)
-		if err != nil {
-			return "", "", fmt.Errorf("failed to parse user address: %v", err)
-		}
+	bridgeAccount, bridgePublicKey, err := rippleResolveAccount(bridgeAddress)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid bridge address: %v", err)
+	}
 
-		// Get current network fee
-		fee, err := b.client.Fee()
-		if err != nil {
-			return "", "", fmt.Errorf("failed to get network fee: %v", err)
-		}
+	userAccount, destinationTag, err := rippleDecodeAddress(userAddress)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid recipient address: %v", err)
+	}
 
-		// Create payment transaction
-		payment := &data.Payment{
-			TxBase: data.TxBase{
-				TransactionType: data.PAYMENT,
-				Account:         *bridgeAccount,
-				Fee:             fee.Drops.BaseFee,
-			},
-			Destination: *userAccount,
-			Amount:      *amount2,
-		}
+	payment := &data.Payment{
+		TxBase: data.TxBase{
+			TransactionType: data.PAYMENT,
+			Account:         *bridgeAccount,
+			SigningPubKey:   bridgePublicKey,
+		},
+		Destination:    *userAccount,
+		Amount:         *amount,
+		DestinationTag: destinationTag,
+	}
 
-		// Get account sequence
-		account, err := b.client.AccountInfo(*bridgeAccount)
-		if err != nil {
-			return "", "", fmt.Errorf("failed to get account info: %v", err)
+	if token != nil {
+		if err := b.checkTrustLine(*userAccount, *amount); err != nil {
+			return "", "", err
 		}
 
-		payment.Sequence = *account.AccountData.Sequence
-
-		// Serialize transaction
-		tx := data.NewTransactionWithMetadata(data.PAYMENT)
-		tx.Transaction = payment
-		txBytes, err := tx.MarshalJSON()
-		if err != nil {
-			return "", "", fmt.Errorf("failed to marshal transaction: %v", err)
+		// The issuer takes its transfer fee on top of the delivered amount
+		if token.Issuer != *bridgeAccount && token.Issuer != *userAccount {
+			issuer, err := b.client.AccountInfo(token.Issuer)
+			if err != nil {
+				return "", "", fmt.Errorf("failed to get issuer account info: %v", err)
+			}
+			payment.SendMax, err = rippleSendMax(*amount, issuer.AccountData.TransferRate, b.Decimals())
+			if err != nil {
+				return "", "", fmt.Errorf("failed to compute send max: %v", err)
+			}
 		}
+	}
 
-		hash, _, err := data.SigningHash(payment)
-		if err != nil {
-			return "", "", fmt.Errorf("failed to get signing hash: %v", err)
-		}
+	return b.prepareTransaction(payment)
+}
 
-		serializedTxn := hex.EncodeToString(txBytes)
-		dataToSign := hex.EncodeToString(hash.Bytes())
+// BuildTrustSetTx builds a TrustSet letting account hold up to limit base units of the
+// issued currency tokenAddress ("CUR.rIssuer"). account is the wallet's address or hex public key.
+func (b *RippleBlockchain) BuildTrustSetTx(account string, tokenAddress string, limit string) (string, string, error) {
+	token, err := parseRippleToken(&tokenAddress)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid token address: %v", err)
+	}
+	if token == nil {
+		return "", "", fmt.Errorf("invalid token address: XRP does not need a trust line")
+	}
 
-		return serializedTxn, dataToSign, nil
+	limitAmount, err := rippleAmountFromUnits(limit, token, b.Decimals())
+	if err != nil {
+		return "", "", fmt.Errorf("invalid limit: %v", err)
 	}
 
-	var solverData map[string]interface{}
-	if err := json.Unmarshal([]byte(solverOutput), &solverData); err != nil {
-		return "", "", fmt.Errorf("failed to parse solver output: %v", err)
+	walletAccount, walletPublicKey, err := rippleResolveAccount(account)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid account: %v", err)
+	}
+	if *walletAccount == token.Issuer {
+		return "", "", fmt.Errorf("invalid account: an issuer cannot trust itself")
 	}
 
-	amount, ok := solverData["amount"].(string)
-	if !ok {
-		return "", "", fmt.Errorf("amount not found in solver output")
+	// Holders should not let their balance ripple between trust lines
+	flags := data.TxSetNoRipple
+	trustSet := &data.TrustSet{
+		TxBase: data.TxBase{
+			TransactionType: data.TRUST_SET,
+			Flags:           &flags,
+			Account:         *walletAccount,
+			SigningPubKey:   walletPublicKey,
+		},
+		LimitAmount: *limitAmount,
 	}
 
-	bridgeAccount, err := data.NewAccountFromAddress(bridgeAddress)
+	return b.prepareTransaction(trustSet)
+}
+
+// VerifyTrustSetMessage checks that message, the hex data to sign of a trust set, is the
+// signing message of a TrustSet built by BuildTrustSetTx: for account, of exactly limit base
+// units of tokenAddress, and nothing else. The sequence and ledger window are not checked.
+func (b *RippleBlockchain) VerifyTrustSetMessage(message string, account string, tokenAddress string, limit string) error {
+	token, err := parseRippleToken(&tokenAddress)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to parse bridge address: %v", err)
+		return fmt.Errorf("invalid token address: %v", err)
+	}
+	if token == nil {
+		return fmt.Errorf("invalid token address: XRP does not need a trust line")
+	}
+	limitAmount, err := rippleAmountFromUnits(limit, token, b.Decimals())
+	if err != nil {
+		return fmt.Errorf("invalid limit: %v", err)
+	}
+	walletAccount, walletPublicKey, err := rippleResolveAccount(account)
+	if err != nil {
+		return fmt.Errorf("invalid account: %v", err)
 	}
 
-	userAccount, err := data.NewAccountFromAddress(userAddress)
+	messageBytes, err := hex.DecodeString(strings.TrimPrefix(message, "0x"))
+	if err != nil {
+		return fmt.Errorf("invalid message: %v", err)
+	}
+	prefix := data.HP_TRANSACTION_SIGN.Bytes()
+	if !bytes.HasPrefix(messageBytes, prefix) {
+		return fmt.Errorf("message is not a transaction signing message")
+	}
+	r := bytes.NewReader(messageBytes[len(prefix):])
+	tx, err := data.ReadTransaction(r)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to parse user address: %v", err)
+		return fmt.Errorf("invalid transaction in message: %v", err)
+	}
+	if r.Len() != 0 {
+		return fmt.Errorf("message has trailing bytes")
 	}
 
-	// tokenCurrency, err := data.NewCurrency(tokenCode)
-	// if err != nil {
-	// 	return "", "", fmt.Errorf("failed to parse token code: %v", err)
-	// }
+	trustSet, ok := tx.(*data.TrustSet)
+	if !ok {
+		return fmt.Errorf("message signs a %s, not a trust set", tx.GetTransactionType())
+	}
+	if trustSet.Account != *walletAccount {
+		return fmt.Errorf("trust set is for account %s, not %s", trustSet.Account, walletAccount)
+	}
+	if walletPublicKey != nil && (trustSet.SigningPubKey == nil || *trustSet.SigningPubKey != *walletPublicKey) {
+		return fmt.Errorf("trust set is not signed by the wallet key")
+	}
+	if trustSet.LimitAmount.Currency != limitAmount.Currency || trustSet.LimitAmount.Issuer != limitAmount.Issuer ||
+		trustSet.LimitAmount.IsNative() || trustSet.LimitAmount.Value.Rat().Cmp(limitAmount.Value.Rat()) != 0 {
+		return fmt.Errorf("trust set limit %s does not match %s", trustSet.LimitAmount, limitAmount)
+	}
+	if trustSet.Flags == nil || *trustSet.Flags != data.TxSetNoRipple {
+		return fmt.Errorf("trust set has unexpected flags")
+	}
+	if trustSet.QualityIn != nil || trustSet.QualityOut != nil {
+		return fmt.Errorf("trust set changes the trust line quality")
+	}
+	if trustSet.Fee.Rat().Cmp(big.NewRat(rippleMaxTrustSetFee, 1)) > 0 {
+		return fmt.Errorf("trust set fee %s exceeds %d drops", trustSet.Fee, rippleMaxTrustSetFee)
+	}
+	return nil
+}
 
-	tokenIssuerAccount, err := data.NewAccountFromAddress(*tokenAddress)
-	if err != nil {
-		return "", "", fmt.Errorf("failed to parse token issuer: %v", err)
+// checkTrustLine fails early when the recipient cannot receive amount of an issued currency,
+// instead of letting the payment fail on ledger after it has been signed
+func (b *RippleBlockchain) checkTrustLine(recipient data.Account, amount data.Amount) error {
+	if recipient == amount.Issuer {
+		return nil
 	}
 
-	value, err := data.NewValue(amount, true)
+	lines, err := b.client.AccountLines(recipient, rippleTrustLineLedger)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to parse amount: %v", err)
+		return fmt.Errorf("failed to get recipient trust lines: %v", err)
 	}
-	// Create token amount
-	tokenAmount := data.Amount{
-		Value: value,
-		// Currency: tokenCurrency,
-		Issuer: *tokenIssuerAccount,
+
+	for _, line := range lines.Lines {
+		if line.Account != amount.Issuer || line.Currency != amount.Currency {
+			continue
+		}
+		if line.Freeze || line.FreezePeer {
+			return fmt.Errorf("recipient trust line for %s is frozen", rippleTokenAddress(amount))
+		}
+
+		room := line.Limit.Rat()
+		room.Sub(room, line.Balance.Rat())
+		if room.Cmp(amount.Value.Rat()) < 0 {
+			return fmt.Errorf("recipient trust line for %s has room for %s, need %s",
+				rippleTokenAddress(amount), room.FloatString(int(b.Decimals())), amount.Value)
+		}
+		return nil
 	}
 
+	return fmt.Errorf("recipient %s has no trust line for %s", recipient, rippleTokenAddress(amount))
+}
+
+// prepareTransaction fills in the fee, sequence and expiry of tx and returns it serialized
+// together with the hex message the account key has to sign
+func (b *RippleBlockchain) prepareTransaction(tx data.Transaction) (string, string, error) {
+	base := tx.GetBase()
+
 	// Get current network fee
 	fee, err := b.client.Fee()
 	if err != nil {
 		return "", "", fmt.Errorf("failed to get network fee: %v", err)
 	}
-
-	// Create payment transaction
-	payment := &data.Payment{
-		TxBase: data.TxBase{
-			TransactionType: data.PAYMENT,
-			Account:         *bridgeAccount,
-			Fee:             fee.Drops.BaseFee,
-		},
-		Destination: *userAccount,
-		Amount:      tokenAmount,
-	}
+	base.Fee = fee.Drops.BaseFee
 
 	// Get account sequence
-	account, err := b.client.AccountInfo(*bridgeAccount)
+	account, err := b.client.AccountInfo(base.Account)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to get account info: %v", err)
 	}
+	if account.AccountData.Sequence == nil {
+		return "", "", fmt.Errorf("account %s has no sequence", base.Account)
+	}
+	base.Sequence = *account.AccountData.Sequence
 
-	payment.Sequence = *account.AccountData.Sequence
+	lastLedger := account.LedgerSequence + rippleLedgerWindow
+	base.LastLedgerSequence = &lastLedger
 
 	// Serialize transaction
-	tx := data.NewTransactionWithMetadata(data.PAYMENT)
-	tx.Transaction = payment
-	txBytes, err := tx.MarshalJSON()
+	txm := data.NewTransactionWithMetadata(tx.GetTransactionType())
+	txm.Transaction = tx
+	txBytes, err := txm.MarshalJSON()
 	if err != nil {
 		return "", "", fmt.Errorf("failed to marshal transaction: %v", err)
 	}
 
-	hash, _, err := data.SigningHash(payment)
+	message, err := rippleSigningMessage(tx)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to get signing hash: %v", err)
+		return "", "", err
 	}
 
 	serializedTxn := hex.EncodeToString(txBytes)
-	dataToSign := hex.EncodeToString(hash.Bytes())
+	dataToSign := hex.EncodeToString(message)
 
 	return serializedTxn, dataToSign, nil
 }
 
+// rippleSigningMessage returns the bytes an Ed25519 key signs for tx: the signing
+// prefix followed by the serialized fields, not their hash
+func rippleSigningMessage(tx data.Transaction) ([]byte, error) {
+	_, msg, err := data.SigningHash(tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get signing hash: %v", err)
+	}
+	return append(tx.SigningPrefix().Bytes(), msg...), nil
+}
+
 func (b *RippleBlockchain) RawPublicKeyBytesToAddress(pkBytes []byte, networkType NetworkType) (string, error) {
 	return "", errors.New("RawPublicKeyBytesToAddress not implemented")
 }
@@ -319,20 +471,72 @@ func (b *RippleBlockchain) RawPublicKeyToPublicKeyStr(pkBytes []byte) (string, e
 }
 
 func (b *RippleBlockchain) ExtractDestinationAddress(serializedTxn string) (string, string, error) {
-	// For Ripple, the destination is in the transaction payload
-	// Decode the serialized transaction
-	txBytes, err := hex.DecodeString(strings.TrimPrefix(serializedTxn, "0x"))
+	tx, err := decodeRippleTransaction(serializedTxn)
+	if err != nil {
+		return "", "", err
+	}
+
+	payment, ok := tx.(*data.Payment)
+	if !ok {
+		return "", "", fmt.Errorf("unsupported transaction type: %T", tx)
+	}
+
+	tokenAddress := ""
+	if !payment.Amount.IsNative() {
+		tokenAddress = rippleTokenAddress(payment.Amount)
+	}
+	return payment.Destination.String(), tokenAddress, nil
+}
+
+// ExtractTransferAmount returns the drops of an XRP payment, or the value of a token
+// payment, which has no base unit
+func (b *RippleBlockchain) ExtractTransferAmount(serializedTxn string) (string, error) {
+	tx, err := decodeRippleTransaction(serializedTxn)
 	if err != nil {
-		return "", "", fmt.Errorf("error decoding transaction", err)
+		return "", err
+	}
+
+	payment, ok := tx.(*data.Payment)
+	if !ok {
+		return "", fmt.Errorf("unsupported transaction type: %T", tx)
+	}
+	if payment.Amount.IsNative() {
+		return payment.Amount.Value.Rat().FloatString(0), nil
 	}
+	return payment.Amount.Value.String(), nil
+}
 
-	// Parse the transaction
-	var tx data.Payment
-	err = json.Unmarshal(txBytes, &tx)
+// ComputeDataToSign returns the signing message of the transaction. The message covers
+// the signing public key, so the transaction has to name it.
+func (b *RippleBlockchain) ComputeDataToSign(serializedTxn string) (string, error) {
+	tx, err := decodeRippleTransaction(serializedTxn)
 	if err != nil {
-		return "", "", fmt.Errorf("error unmarshalling transaction", err)
+		return "", err
+	}
+	if tx.GetBase().SigningPubKey == nil {
+		return "", fmt.Errorf("transaction does not name its signing public key")
 	}
-	destAddress := tx.Destination.String()
-	return destAddress, "", nil
 
+	message, err := rippleSigningMessage(tx)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(message), nil
+}
+
+// decodeRippleTransaction decodes a hex JSON transaction of any type
+func decodeRippleTransaction(serializedTxn string) (data.Transaction, error) {
+	txBytes, err := hex.DecodeString(strings.TrimPrefix(serializedTxn, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("error decoding transaction: %v", err)
+	}
+
+	var txm data.TransactionWithMetaData
+	if err := json.Unmarshal(txBytes, &txm); err != nil {
+		return nil, fmt.Errorf("error unmarshalling transaction: %v", err)
+	}
+	if txm.Transaction == nil {
+		return nil, fmt.Errorf("error unmarshalling transaction: unknown transaction type")
+	}
+	return txm.Transaction, nil
 }
@@ -0,0 +1,19 @@
+package blockchains
+
+import (
+	"github.com/rubblelabs/ripple/data"
+	"github.com/rubblelabs/ripple/websockets"
+)
+
+// IRippleClient is the subset of the rippled websocket API used by RippleBlockchain.
+// websockets.Remote implements it; tests and local networks can provide a stand-in.
+type IRippleClient interface {
+	AccountInfo(account data.Account) (*websockets.AccountInfoResult, error)
+	AccountLines(account data.Account, ledgerIndex interface{}) (*websockets.AccountLinesResult, error)
+	Fee() (*websockets.FeeResult, error)
+	Submit(tx data.Transaction) (*websockets.SubmitResult, error)
+	Tx(hash data.Hash256) (*websockets.TxResult, error)
+}
+
+// This is a type assertion to ensure that the websocket client implements the IRippleClient interface
+var _ IRippleClient = &websockets.Remote{}
@@ -0,0 +1,420 @@
+package blockchains
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/StripChain/strip-node/common"
+	"github.com/StripChain/strip-node/util"
+	"github.com/rubblelabs/ripple/data"
+	"github.com/rubblelabs/ripple/websockets"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testRippleIssuer    = "rvYAfWj5gh67oV6fW32ZzP3Aw4Eubs59B"
+	testRippleRecipient = "rGWrZyQqhTp9Xu7G5Pkayo7bXjH4k4QYpf"
+	testRippleLedger    = 90_000_000
+)
+
+// mockRippleClient is an in-memory stand-in for a rippled node
+type mockRippleClient struct {
+	accounts  map[data.Account]data.AccountRoot
+	lines     map[data.Account]data.AccountLineSlice
+	txs       map[data.Hash256]string
+	submitted []data.Transaction
+}
+
+func (m *mockRippleClient) AccountInfo(account data.Account) (*websockets.AccountInfoResult, error) {
+	root, ok := m.accounts[account]
+	if !ok {
+		return nil, errors.New("actNotFound")
+	}
+	return &websockets.AccountInfoResult{LedgerSequence: testRippleLedger, AccountData: root}, nil
+}
+
+func (m *mockRippleClient) AccountLines(account data.Account, ledgerIndex interface{}) (*websockets.AccountLinesResult, error) {
+	return &websockets.AccountLinesResult{Account: account, Lines: m.lines[account]}, nil
+}
+
+func (m *mockRippleClient) Fee() (*websockets.FeeResult, error) {
+	fee, err := data.NewValue("12", true)
+	if err != nil {
+		return nil, err
+	}
+	result := &websockets.FeeResult{}
+	result.Drops.BaseFee = *fee
+	return result, nil
+}
+
+func (m *mockRippleClient) Submit(tx data.Transaction) (*websockets.SubmitResult, error) {
+	m.submitted = append(m.submitted, tx)
+	return &websockets.SubmitResult{EngineResult: data.TransactionResult(0)}, nil
+}
+
+func (m *mockRippleClient) Tx(hash data.Hash256) (*websockets.TxResult, error) {
+	raw, ok := m.txs[hash]
+	if !ok {
+		return nil, errors.New("txnNotFound")
+	}
+	var result websockets.TxResult
+	if err := json.Unmarshal([]byte(raw), &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func testRippleAccount(t *testing.T, address string) data.Account {
+	account, err := data.NewAccountFromAddress(address)
+	require.NoError(t, err)
+	return *account
+}
+
+func testRippleValue(t *testing.T, value string) data.NonNativeValue {
+	v, err := data.NewValue(value, false)
+	require.NoError(t, err)
+	return data.NonNativeValue{Value: *v}
+}
+
+// testRippleWallet returns an Ed25519 key and the hex 0xED-prefixed public key the bridge stores
+func testRippleWallet(t *testing.T, seed byte) (ed25519.PrivateKey, string, data.Account) {
+	key := ed25519.NewKeyFromSeed(bytes32(seed))
+	publicKey := hex.EncodeToString(append([]byte{0xED}, key.Public().(ed25519.PublicKey)...))
+	account, _, err := rippleResolveAccount(publicKey)
+	require.NoError(t, err)
+	return key, publicKey, *account
+}
+
+func bytes32(b byte) []byte {
+	seed := make([]byte, 32)
+	for i := range seed {
+		seed[i] = b
+	}
+	return seed
+}
+
+func newTestRippleBlockchain(t *testing.T, client *mockRippleClient) *RippleBlockchain {
+	chain, err := NewRippleBlockchainWithClient(Testnet, client)
+	require.NoError(t, err)
+	return chain.(*RippleBlockchain)
+}
+
+func TestRippleXAddress(t *testing.T) {
+	account := testRippleAccount(t, "r9cZA1mLK5R5Am25ArfXFmqgNwjZgnfk59")
+	tag := uint32(1)
+
+	// Vectors from the X-address specification
+	require.Equal(t, "X7AcgcsBL6XDcUb289X4mJ8djcdyKaB5hJDWMArnXr61cqZ", rippleEncodeXAddress(account, nil, false))
+	require.Equal(t, "X7AcgcsBL6XDcUb289X4mJ8djcdyKaGZMhc9YTE92ehJ2Fu", rippleEncodeXAddress(account, &tag, false))
+
+	for _, testnet := range []bool{false, true} {
+		for _, tag := range []*uint32{nil, &tag} {
+			decoded, decodedTag, err := rippleDecodeAddress(rippleEncodeXAddress(account, tag, testnet))
+			require.NoError(t, err)
+			require.Equal(t, account, *decoded)
+			require.Equal(t, tag, decodedTag)
+		}
+	}
+
+	decoded, decodedTag, err := rippleDecodeAddress("r9cZA1mLK5R5Am25ArfXFmqgNwjZgnfk59")
+	require.NoError(t, err)
+	require.Equal(t, account, *decoded)
+	require.Nil(t, decodedTag)
+
+	_, _, err = rippleDecodeAddress("X7AcgcsBL6XDcUb289X4mJ8djcdyKaB5hJDWMArnXr61cqY")
+	require.Error(t, err)
+}
+
+func TestRippleBuildWithdrawTx(t *testing.T) {
+	_, bridgePublicKey, bridgeAccount := testRippleWallet(t, 1)
+	issuer := testRippleAccount(t, testRippleIssuer)
+	recipient := testRippleAccount(t, testRippleRecipient)
+	usd := "USD." + testRippleIssuer
+	destinationTag := uint32(424242)
+	feeRate := uint32(1_002_000_000)
+
+	tests := []struct {
+		name            string
+		token           *string
+		amount          string
+		recipient       string
+		issuerRate      *uint32
+		lines           data.AccountLineSlice
+		wantAmount      string
+		wantSendMax     string
+		wantTag         *uint32
+		wantErrContains string
+	}{
+		{
+			name:       "XRP in drops",
+			token:      nil,
+			amount:     "2500000",
+			recipient:  testRippleRecipient,
+			wantAmount: "2.5/XRP",
+		},
+		{
+			name:       "XRP via zero address",
+			token:      stringPtr(util.ZERO_ADDRESS),
+			amount:     "1",
+			recipient:  testRippleRecipient,
+			wantAmount: "0.000001/XRP",
+		},
+		{
+			name:       "IOU to X-address with tag",
+			token:      &usd,
+			amount:     "1500000",
+			recipient:  rippleEncodeXAddress(recipient, &destinationTag, true),
+			lines:      data.AccountLineSlice{{Account: issuer, Currency: mustRippleCurrency(t, "USD"), Limit: testRippleValue(t, "1000")}},
+			wantAmount: "1.5/USD/" + testRippleIssuer,
+			wantTag:    &destinationTag,
+		},
+		{
+			name:        "IOU with issuer transfer fee",
+			token:       &usd,
+			amount:      "1000001",
+			recipient:   testRippleRecipient,
+			issuerRate:  &feeRate,
+			lines:       data.AccountLineSlice{{Account: issuer, Currency: mustRippleCurrency(t, "USD"), Limit: testRippleValue(t, "1000")}},
+			wantAmount:  "1.000001/USD/" + testRippleIssuer,
+			wantSendMax: "1.002002/USD/" + testRippleIssuer,
+		},
+		{
+			name:            "IOU without trust line",
+			token:           &usd,
+			amount:          "1000000",
+			recipient:       testRippleRecipient,
+			wantErrContains: "has no trust line",
+		},
+		{
+			name:      "IOU beyond trust line limit",
+			token:     &usd,
+			amount:    "1000000",
+			recipient: testRippleRecipient,
+			lines: data.AccountLineSlice{{
+				Account:  issuer,
+				Currency: mustRippleCurrency(t, "USD"),
+				Limit:    testRippleValue(t, "10"),
+				Balance:  testRippleValue(t, "9.5"),
+			}},
+			wantErrContains: "has room for 0.500000",
+		},
+		{
+			name:            "bare issuer token address",
+			token:           stringPtr(testRippleIssuer),
+			amount:          "1000000",
+			recipient:       testRippleRecipient,
+			wantErrContains: "invalid token address",
+		},
+		{
+			name:            "zero amount",
+			token:           nil,
+			amount:          "0",
+			recipient:       testRippleRecipient,
+			wantErrContains: "invalid amount",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sequence := uint32(7)
+			client := &mockRippleClient{
+				accounts: map[data.Account]data.AccountRoot{
+					bridgeAccount: {Sequence: &sequence},
+					issuer:        {TransferRate: tt.issuerRate},
+				},
+				lines: map[data.Account]data.AccountLineSlice{recipient: tt.lines},
+			}
+			chain := newTestRippleBlockchain(t, client)
+
+			solverOutput := fmt.Sprintf(`{"amount": "%s"}`, tt.amount)
+			serializedTxn, dataToSign, err := chain.BuildWithdrawTx(bridgePublicKey, solverOutput, tt.recipient, tt.token)
+			if tt.wantErrContains != "" {
+				require.ErrorContains(t, err, tt.wantErrContains)
+				return
+			}
+			require.NoError(t, err)
+
+			tx, err := decodeRippleTransaction(serializedTxn)
+			require.NoError(t, err)
+			payment, ok := tx.(*data.Payment)
+			require.True(t, ok)
+
+			require.Equal(t, bridgeAccount, payment.Account)
+			require.Equal(t, recipient, payment.Destination)
+			require.Equal(t, tt.wantAmount, payment.Amount.String())
+			require.Equal(t, tt.wantTag, payment.DestinationTag)
+			require.Equal(t, sequence, payment.Sequence)
+			require.Equal(t, uint32(testRippleLedger+rippleLedgerWindow), *payment.LastLedgerSequence)
+			if tt.wantSendMax == "" {
+				require.Nil(t, payment.SendMax)
+			} else {
+				require.Equal(t, tt.wantSendMax, payment.SendMax.String())
+			}
+
+			// The signer commits to the full prefixed signing blob, public key included
+			_, msg, err := data.SigningHash(payment)
+			require.NoError(t, err)
+			require.Equal(t, hex.EncodeToString(append(data.HP_TRANSACTION_SIGN.Bytes(), msg...)), dataToSign)
+
+			computed, err := chain.ComputeDataToSign(serializedTxn)
+			require.NoError(t, err)
+			require.Equal(t, dataToSign, computed)
+
+			dest, token, err := chain.ExtractDestinationAddress(serializedTxn)
+			require.NoError(t, err)
+			require.Equal(t, testRippleRecipient, dest)
+			amount, err := chain.ExtractTransferAmount(serializedTxn)
+			require.NoError(t, err)
+			if tt.token == nil || *tt.token == util.ZERO_ADDRESS {
+				require.Empty(t, token)
+				require.Equal(t, tt.amount, amount)
+			} else {
+				require.Equal(t, *tt.token, token)
+				require.Equal(t, strings.Split(tt.wantAmount, "/")[0], amount)
+			}
+		})
+	}
+}
+
+func TestRippleTrustSetAndBroadcast(t *testing.T) {
+	walletKey, walletPublicKey, walletAccount := testRippleWallet(t, 2)
+	_, otherPublicKey, _ := testRippleWallet(t, 3)
+	sequence := uint32(3)
+	client := &mockRippleClient{
+		accounts: map[data.Account]data.AccountRoot{walletAccount: {Sequence: &sequence}},
+	}
+	chain := newTestRippleBlockchain(t, client)
+
+	serializedTxn, dataToSign, err := chain.BuildTrustSetTx(walletPublicKey, "USD."+testRippleIssuer, "1000000000")
+	require.NoError(t, err)
+
+	tx, err := decodeRippleTransaction(serializedTxn)
+	require.NoError(t, err)
+	trustSet, ok := tx.(*data.TrustSet)
+	require.True(t, ok)
+	require.Equal(t, walletAccount, trustSet.Account)
+	require.Equal(t, "1000/USD/"+testRippleIssuer, trustSet.LimitAmount.String())
+	require.Equal(t, data.TxSetNoRipple, *trustSet.Flags)
+
+	_, _, err = chain.ExtractDestinationAddress(serializedTxn)
+	require.ErrorContains(t, err, "unsupported transaction type")
+
+	computed, err := chain.ComputeDataToSign(serializedTxn)
+	require.NoError(t, err)
+	require.Equal(t, dataToSign, computed)
+
+	// The data to sign is checked against the trust set the wallet asked for
+	require.NoError(t, chain.VerifyTrustSetMessage(dataToSign, walletPublicKey, "USD."+testRippleIssuer, "1000000000"))
+	require.ErrorContains(t, chain.VerifyTrustSetMessage(dataToSign, walletPublicKey, "USD."+testRippleIssuer, "2000000000"), "does not match")
+	require.ErrorContains(t, chain.VerifyTrustSetMessage(dataToSign, walletPublicKey, "EUR."+testRippleIssuer, "1000000000"), "does not match")
+	require.ErrorContains(t, chain.VerifyTrustSetMessage(dataToSign, otherPublicKey, "USD."+testRippleIssuer, "1000000000"), "trust set is for account")
+	require.ErrorContains(t, chain.VerifyTrustSetMessage(dataToSign[:len(dataToSign)-2], walletPublicKey, "USD."+testRippleIssuer, "1000000000"), "invalid transaction")
+
+	message, err := hex.DecodeString(dataToSign)
+	require.NoError(t, err)
+	signature := hex.EncodeToString(ed25519.Sign(walletKey, message))
+
+	// A signature over anything else, or a key for another account, is rejected before submission
+	_, err = chain.BroadcastTransaction(serializedTxn, hex.EncodeToString(ed25519.Sign(walletKey, message[1:])), &walletPublicKey)
+	require.ErrorContains(t, err, "signature does not match")
+	_, err = chain.BroadcastTransaction(serializedTxn, signature, &otherPublicKey)
+	require.ErrorContains(t, err, "does not belong to transaction account")
+	require.Empty(t, client.submitted)
+
+	txHash, err := chain.BroadcastTransaction(serializedTxn, signature, &walletPublicKey)
+	require.NoError(t, err)
+	require.Len(t, client.submitted, 1)
+
+	hash, _, err := data.Raw(client.submitted[0])
+	require.NoError(t, err)
+	require.Equal(t, hash.String(), txHash)
+
+	_, _, err = chain.BuildTrustSetTx(walletPublicKey, "XRP", "1000000000")
+	require.ErrorContains(t, err, "does not need a trust line")
+	_, _, err = chain.BuildTrustSetTx(walletPublicKey, "USD."+testRippleIssuer, "-1")
+	require.ErrorContains(t, err, "invalid limit")
+}
+
+func TestRippleGetTransfers(t *testing.T) {
+	const (
+		partialHash = "A1B2C3D4E5F60718293A4B5C6D7E8F90A1B2C3D4E5F60718293A4B5C6D7E8F90"
+		xrpHash     = "0F1E2D3C4B5A69788796A5B4C3D2E1F00F1E2D3C4B5A69788796A5B4C3D2E1F0"
+		failedHash  = "00112233445566778899AABBCCDDEEFF00112233445566778899AABBCCDDEEFF"
+		sender      = "rPT1Sjq2YGrBMTttX4GZHjKu9dyfzbpAYe"
+	)
+
+	txJSON := func(hash string, amount string, flags uint32, result string, delivered string) string {
+		meta := fmt.Sprintf(`{"AffectedNodes":[],"TransactionIndex":1,"TransactionResult":%q`, result)
+		if delivered != "" {
+			meta += `,"delivered_amount":` + delivered
+		}
+		meta += "}"
+		return fmt.Sprintf(`{
+			"Account": %q,
+			"Amount": %s,
+			"Destination": %q,
+			"DestinationTag": 12345,
+			"Fee": "12",
+			"Flags": %d,
+			"Sequence": 10,
+			"SigningPubKey": "ED5F5AC8B98974A3CA843326D9B88CEBD0560177B973EE0B149F782CFAA06DC66A",
+			"TransactionType": "Payment",
+			"hash": %q,
+			"ledger_index": 90000001,
+			"meta": %s,
+			"validated": true
+		}`, sender, amount, testRippleRecipient, flags, hash, meta)
+	}
+
+	usdAmount := `{"currency":"USD","issuer":"` + testRippleIssuer + `","value":"100"}`
+	deliveredUSD := `{"currency":"USD","issuer":"` + testRippleIssuer + `","value":"0.0012345"}`
+
+	client := &mockRippleClient{txs: map[data.Hash256]string{}}
+	for hash, raw := range map[string]string{
+		partialHash: txJSON(partialHash, usdAmount, uint32(data.TxPartialPayment), "tesSUCCESS", deliveredUSD),
+		xrpHash:     txJSON(xrpHash, `"1000000"`, 0, "tesSUCCESS", `"1000000"`),
+		failedHash:  txJSON(failedHash, `"1000000"`, 0, "tecUNFUNDED_PAYMENT", ""),
+	} {
+		h, err := data.NewHash256(hash)
+		require.NoError(t, err)
+		client.txs[*h] = raw
+	}
+	chain := newTestRippleBlockchain(t, client)
+	tag := uint32(12345)
+
+	transfers, err := chain.GetTransfers(partialHash, nil)
+	require.NoError(t, err)
+	require.Equal(t, []common.Transfer{{
+		From:           sender,
+		To:             testRippleRecipient,
+		Amount:         "0.0012345",
+		Token:          "USD",
+		IsNative:       false,
+		TokenAddress:   "USD." + testRippleIssuer,
+		ScaledAmount:   "1234",
+		DestinationTag: &tag,
+	}}, transfers)
+
+	transfers, err = chain.GetTransfers(xrpHash, nil)
+	require.NoError(t, err)
+	require.Len(t, transfers, 1)
+	require.True(t, transfers[0].IsNative)
+	require.Equal(t, util.ZERO_ADDRESS, transfers[0].TokenAddress)
+	require.Equal(t, "1000000", transfers[0].ScaledAmount)
+	require.Equal(t, "1", transfers[0].Amount)
+
+	transfers, err = chain.GetTransfers(failedHash, nil)
+	require.NoError(t, err)
+	require.Empty(t, transfers)
+}
+
+func mustRippleCurrency(t *testing.T, code string) data.Currency {
+	currency, err := data.NewCurrency(code)
+	require.NoError(t, err)
+	return currency
+}
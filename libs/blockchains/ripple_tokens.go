@@ -0,0 +1,258 @@
+package blockchains
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/StripChain/strip-node/util"
+	"github.com/rubblelabs/ripple/crypto"
+	"github.com/rubblelabs/ripple/data"
+)
+
+// rippleTrustLineLedger is the ledger used when reading trust lines before a withdrawal
+const rippleTrustLineLedger = "validated"
+
+// rippleTransferRateParity is the TransferRate of an issuer that charges no transfer fee
+const rippleTransferRateParity = 1_000_000_000
+
+var (
+	// X-address prefixes, see https://xrpaddress.info
+	rippleXAddressMainnetPrefix = []byte{0x05, 0x44}
+	rippleXAddressTestnetPrefix = []byte{0x04, 0x93}
+)
+
+// rippleToken identifies an issued currency by its currency code and issuing account.
+// Token addresses are written as "CUR.rIssuer", where CUR is a 3-character code or
+// a 40-character hex currency.
+type rippleToken struct {
+	Currency data.Currency
+	Issuer   data.Account
+}
+
+// parseRippleToken parses a "CUR.rIssuer" token address. A nil token, ZERO_ADDRESS
+// and "XRP" all denote native XRP and return nil.
+func parseRippleToken(tokenAddress *string) (*rippleToken, error) {
+	if tokenAddress == nil || *tokenAddress == "" || *tokenAddress == util.ZERO_ADDRESS || strings.EqualFold(*tokenAddress, "XRP") {
+		return nil, nil
+	}
+
+	code, issuer, ok := strings.Cut(*tokenAddress, ".")
+	if !ok {
+		return nil, fmt.Errorf("token address %q must be of the form CURRENCY.rIssuer", *tokenAddress)
+	}
+
+	currency, err := data.NewCurrency(code)
+	if err != nil {
+		return nil, err
+	}
+	if currency.IsNative() {
+		return nil, fmt.Errorf("XRP cannot be used as an issued currency")
+	}
+
+	issuerAccount, err := data.NewAccountFromAddress(issuer)
+	if err != nil {
+		return nil, fmt.Errorf("invalid issuer %q: %v", issuer, err)
+	}
+
+	return &rippleToken{Currency: currency, Issuer: *issuerAccount}, nil
+}
+
+// String returns the token address in "CUR.rIssuer" form
+func (t rippleToken) String() string {
+	return rippleCurrencyCode(t.Currency) + "." + t.Issuer.String()
+}
+
+// rippleCurrencyCode returns a currency code that data.NewCurrency parses back to the same currency
+func rippleCurrencyCode(currency data.Currency) string {
+	if currency.Type() == data.CT_STANDARD {
+		return currency.Machine()
+	}
+	return strings.ToUpper(hex.EncodeToString(currency.Bytes()))
+}
+
+// rippleTokenAddress returns the token address of an amount, ZERO_ADDRESS for XRP
+func rippleTokenAddress(amount data.Amount) string {
+	if amount.IsNative() {
+		return util.ZERO_ADDRESS
+	}
+	return rippleToken{Currency: amount.Currency, Issuer: amount.Issuer}.String()
+}
+
+// rippleAmountFromUnits converts an integer amount in base units into a ledger amount.
+// XRP is expressed in drops. Issued currencies carry arbitrary precision on the ledger,
+// so the bridge fixes their base unit at 10^-decimals of the currency.
+func rippleAmountFromUnits(units string, token *rippleToken, decimals uint) (*data.Amount, error) {
+	n, ok := new(big.Int).SetString(units, 10)
+	if !ok || n.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid amount: %s", units)
+	}
+
+	if token == nil {
+		value, err := data.NewValue(n.String(), true)
+		if err != nil {
+			return nil, fmt.Errorf("invalid amount: %v", err)
+		}
+		return &data.Amount{Value: value}, nil
+	}
+
+	value, err := data.NewValue(rippleFormatUnits(n, decimals), false)
+	if err != nil {
+		return nil, fmt.Errorf("invalid amount: %v", err)
+	}
+	return &data.Amount{Value: value, Currency: token.Currency, Issuer: token.Issuer}, nil
+}
+
+// rippleFormatUnits formats integer base units as an exact decimal string
+func rippleFormatUnits(units *big.Int, decimals uint) string {
+	return new(big.Rat).SetFrac(units, rippleUnitScale(decimals)).FloatString(int(decimals))
+}
+
+func rippleUnitScale(decimals uint) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+}
+
+// rippleAmountToUnits converts a ledger amount into integer base units, truncating
+// issued-currency precision beyond decimals
+func rippleAmountToUnits(amount data.Amount, decimals uint) string {
+	rat := amount.Value.Rat()
+	if !amount.IsNative() {
+		rat.Mul(rat, new(big.Rat).SetInt(rippleUnitScale(decimals)))
+	}
+	return new(big.Int).Quo(rat.Num(), rat.Denom()).String()
+}
+
+// rippleSendMax returns the most the sender may spend to deliver amount when the issuer
+// charges a transfer fee, rounded up to decimals. It returns nil when no fee applies.
+func rippleSendMax(amount data.Amount, transferRate *uint32, decimals uint) (*data.Amount, error) {
+	if amount.IsNative() || transferRate == nil || *transferRate <= rippleTransferRateParity {
+		return nil, nil
+	}
+
+	cost := amount.Value.Rat()
+	cost.Mul(cost, big.NewRat(int64(*transferRate), rippleTransferRateParity))
+	cost.Mul(cost, new(big.Rat).SetInt(rippleUnitScale(decimals)))
+
+	// Round up so the payment never runs short of the fee
+	units, rem := new(big.Int).QuoRem(cost.Num(), cost.Denom(), new(big.Int))
+	if rem.Sign() != 0 {
+		units.Add(units, big.NewInt(1))
+	}
+
+	value, err := data.NewValue(rippleFormatUnits(units, decimals), false)
+	if err != nil {
+		return nil, err
+	}
+	return &data.Amount{Value: value, Currency: amount.Currency, Issuer: amount.Issuer}, nil
+}
+
+// rippleAccountFromPublicKey derives the account of an Ed25519 public key, given either
+// as 32 raw bytes or as the 33-byte 0xED-prefixed form used by the XRPL
+func rippleAccountFromPublicKey(pubKey []byte) (*data.Account, *data.PublicKey, error) {
+	var publicKey data.PublicKey
+	switch {
+	case len(pubKey) == 32:
+		publicKey[0] = 0xED
+		copy(publicKey[1:], pubKey)
+	case len(pubKey) == 33 && pubKey[0] == 0xED:
+		copy(publicKey[:], pubKey)
+	default:
+		return nil, nil, fmt.Errorf("invalid Ed25519 public key length: %d", len(pubKey))
+	}
+
+	var account data.Account
+	copy(account[:], crypto.Sha256RipeMD160(publicKey[:]))
+	return &account, &publicKey, nil
+}
+
+// rippleResolveAccount resolves a wallet given either as a classic address or as a hex
+// Ed25519 public key. The public key is returned when known so it can be committed to
+// in the transaction before signing.
+func rippleResolveAccount(address string) (*data.Account, *data.PublicKey, error) {
+	if account, err := data.NewAccountFromAddress(address); err == nil {
+		return account, nil, nil
+	}
+
+	pubKey, err := hex.DecodeString(strings.TrimPrefix(address, "0x"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%q is neither an address nor a public key", address)
+	}
+	return rippleAccountFromPublicKey(pubKey)
+}
+
+// rippleDecodeAddress parses a classic address, a hex public key or an X-address,
+// returning the destination tag carried by the latter
+func rippleDecodeAddress(address string) (*data.Account, *uint32, error) {
+	if strings.HasPrefix(address, "X") || strings.HasPrefix(address, "T") {
+		return rippleDecodeXAddress(address)
+	}
+
+	account, _, err := rippleResolveAccount(address)
+	if err != nil {
+		return nil, nil, err
+	}
+	return account, nil, nil
+}
+
+// rippleDecodeXAddress decodes an X-address into its account and optional destination tag
+func rippleDecodeXAddress(address string) (*data.Account, *uint32, error) {
+	decoded, err := crypto.Base58Decode(address, crypto.ALPHABET)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid X-address: %v", err)
+	}
+	// prefix (2) + account (20) + flag (1) + tag (8) + checksum (4)
+	if len(decoded) != 35 {
+		return nil, nil, fmt.Errorf("invalid X-address length: %d", len(decoded))
+	}
+
+	prefix := decoded[:2]
+	if !bytes.Equal(prefix, rippleXAddressMainnetPrefix) && !bytes.Equal(prefix, rippleXAddressTestnetPrefix) {
+		return nil, nil, fmt.Errorf("invalid X-address prefix: %x", prefix)
+	}
+
+	var account data.Account
+	copy(account[:], decoded[2:22])
+
+	tag := binary.LittleEndian.Uint64(decoded[23:31])
+	switch decoded[22] {
+	case 0:
+		if tag != 0 {
+			return nil, nil, fmt.Errorf("X-address has a tag but no tag flag")
+		}
+		return &account, nil, nil
+	case 1:
+		// 64-bit tags are reserved, the ledger only accepts 32 bits
+		if tag > 0xFFFFFFFF {
+			return nil, nil, fmt.Errorf("X-address tag %d is out of range", tag)
+		}
+		destinationTag := uint32(tag)
+		return &account, &destinationTag, nil
+	default:
+		return nil, nil, fmt.Errorf("invalid X-address flag: %d", decoded[22])
+	}
+}
+
+// rippleEncodeXAddress encodes an account and optional destination tag as an X-address
+func rippleEncodeXAddress(account data.Account, tag *uint32, testnet bool) string {
+	payload := make([]byte, 0, 31)
+	if testnet {
+		payload = append(payload, rippleXAddressTestnetPrefix...)
+	} else {
+		payload = append(payload, rippleXAddressMainnetPrefix...)
+	}
+	payload = append(payload, account[:]...)
+
+	var flag byte
+	var tagBytes [8]byte
+	if tag != nil {
+		flag = 1
+		binary.LittleEndian.PutUint64(tagBytes[:], uint64(*tag))
+	}
+	payload = append(payload, flag)
+	payload = append(payload, tagBytes[:]...)
+
+	return crypto.Base58Encode(payload, crypto.ALPHABET)
+}
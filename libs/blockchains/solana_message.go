@@ -0,0 +1,249 @@
+package blockchains
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	addresslookuptable "github.com/gagliardetto/solana-go/programs/address-lookup-table"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/mr-tron/base58"
+)
+
+var solanaAddressLookupTableProgramID = solana.MustPublicKeyFromBase58("AddressLookupTab1e1111111111111111111111111")
+
+const (
+	// SystemInstruction::Transfer
+	solanaSystemInstructionTransfer = 2
+	// TokenInstruction::Transfer
+	solanaTokenInstructionTransfer = 3
+	// AssociatedTokenAccountInstruction::Create has empty data, CreateIdempotent is 1
+	solanaATAInstructionCreate = 0
+	// Offset of the owner field in an SPL token account
+	solanaTokenAccountOwnerOffset = 32
+)
+
+// decodeSolanaTransaction decodes a base58 transaction with either a legacy or a v0 message
+func decodeSolanaTransaction(serializedTxn string) (*solana.Transaction, error) {
+	decodedTxn, err := base58.Decode(serializedTxn)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding Solana transaction: %v", err)
+	}
+
+	tx, err := solana.TransactionFromDecoder(bin.NewBinDecoder(decodedTxn))
+	if err != nil {
+		return nil, fmt.Errorf("error deserializing Solana transaction: %v", err)
+	}
+
+	return tx, nil
+}
+
+// setSolanaSignature places signature in the slot of the signer identified by publicKey.
+// When publicKey is not given the fee payer is assumed to be the signer.
+// The transaction may come without signatures or with one placeholder per required signer.
+func setSolanaSignature(tx *solana.Transaction, signature solana.Signature, publicKey *string) error {
+	signers := tx.Message.Signers()
+	if len(signers) == 0 {
+		return fmt.Errorf("transaction has no signers")
+	}
+
+	index := 0
+	if publicKey != nil && *publicKey != "" {
+		signer, err := solana.PublicKeyFromBase58(*publicKey)
+		if err != nil {
+			return fmt.Errorf("invalid signer public key: %v", err)
+		}
+		index = -1
+		for i, s := range signers {
+			if s.Equals(signer) {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			return fmt.Errorf("%s is not a signer of the transaction", signer)
+		}
+	}
+
+	if len(tx.Signatures) == 0 {
+		tx.Signatures = make([]solana.Signature, len(signers))
+	}
+	if len(tx.Signatures) != len(signers) {
+		return fmt.Errorf("signature count mismatch: got %d, want %d", len(tx.Signatures), len(signers))
+	}
+
+	tx.Signatures[index] = signature
+	return nil
+}
+
+// resolveAddressTables loads the address lookup tables referenced by a v0 message,
+// so that instruction account indexes past the static keys can be resolved
+func (b *SolanaBlockchain) resolveAddressTables(ctx context.Context, message *solana.Message) error {
+	if !message.IsVersioned() || message.NumLookups() == 0 {
+		return nil
+	}
+
+	tables := make(map[solana.PublicKey]solana.PublicKeySlice)
+	for _, tableID := range message.GetAddressTableLookups().GetTableIDs() {
+		accountInfo, err := b.client.GetAccountInfo(ctx, tableID)
+		if err != nil {
+			return fmt.Errorf("failed to get address lookup table %s: %v", tableID, err)
+		}
+		if !accountInfo.Value.Owner.Equals(solanaAddressLookupTableProgramID) {
+			return fmt.Errorf("account %s is not an address lookup table", tableID)
+		}
+
+		state, err := addresslookuptable.DecodeAddressLookupTableState(accountInfo.GetBinary())
+		if err != nil {
+			return fmt.Errorf("failed to decode address lookup table %s: %v", tableID, err)
+		}
+		tables[tableID] = state.Addresses
+	}
+
+	return message.SetAddressTables(tables)
+}
+
+// solanaInstructionAccounts resolves the account indexes of an instruction, including
+// accounts loaded from address lookup tables
+func solanaInstructionAccounts(message *solana.Message, instruction solana.CompiledInstruction) ([]solana.PublicKey, error) {
+	accounts := make([]solana.PublicKey, 0, len(instruction.Accounts))
+	for _, index := range instruction.Accounts {
+		account, err := message.Account(index)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+	return accounts, nil
+}
+
+// extractTransferDestination returns the recipient wallet and token mint of the first
+// SOL or token transfer in the transaction. Compute budget, ATA creation and other
+// instructions are skipped. Native transfers return an empty token address.
+func (b *SolanaBlockchain) extractTransferDestination(ctx context.Context, tx *solana.Transaction) (string, string, error) {
+	for _, instruction := range tx.Message.Instructions {
+		programID, err := tx.Message.Program(instruction.ProgramIDIndex)
+		if err != nil {
+			return "", "", err
+		}
+		accounts, err := solanaInstructionAccounts(&tx.Message, instruction)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to resolve instruction accounts: %v", err)
+		}
+		data := instruction.Data
+
+		switch {
+		case programID.Equals(solana.SystemProgramID):
+			if len(data) >= 4 && binary.LittleEndian.Uint32(data[:4]) == solanaSystemInstructionTransfer && len(accounts) >= 2 {
+				return accounts[1].String(), "", nil
+			}
+		case programID.Equals(solana.TokenProgramID) || programID.Equals(solana.Token2022ProgramID):
+			if len(data) == 0 {
+				continue
+			}
+			var destination solana.PublicKey
+			var mint *solana.PublicKey
+			switch {
+			case data[0] == solanaTokenInstructionTransfer && len(accounts) >= 2:
+				destination = accounts[1]
+			case data[0] == solanaTokenInstructionTransferChecked && len(accounts) >= 3,
+				data[0] == solanaTokenInstructionTransferFeeExt && len(data) > 1 &&
+					data[1] == solanaTransferFeeInstructionTransferCheckedWithFee && len(accounts) >= 3:
+				destination = accounts[2]
+				mint = &accounts[1]
+			default:
+				continue
+			}
+
+			owner, tokenMint, err := b.tokenAccountOwner(ctx, tx, destination)
+			if err != nil {
+				return "", "", err
+			}
+			if mint != nil && !mint.Equals(tokenMint) {
+				return "", "", fmt.Errorf("destination token account %s does not hold mint %s", destination, mint)
+			}
+			return owner.String(), tokenMint.String(), nil
+		}
+	}
+
+	return "", "", fmt.Errorf("no transfer instruction found in Solana transaction")
+}
+
+// solanaTransferAmount returns the amount moved by the first transfer instruction of a
+// message, the one extractTransferDestination reads the destination of
+func solanaTransferAmount(message *solana.Message) (string, error) {
+	for _, instruction := range message.Instructions {
+		programID, err := message.Program(instruction.ProgramIDIndex)
+		if err != nil {
+			return "", err
+		}
+		data := instruction.Data
+		accounts := len(instruction.Accounts)
+
+		var amount []byte
+		switch {
+		case programID.Equals(solana.SystemProgramID):
+			if len(data) >= 12 && binary.LittleEndian.Uint32(data[:4]) == solanaSystemInstructionTransfer && accounts >= 2 {
+				amount = data[4:12]
+			}
+		case programID.Equals(solana.TokenProgramID) || programID.Equals(solana.Token2022ProgramID):
+			switch {
+			case len(data) >= 9 && data[0] == solanaTokenInstructionTransfer && accounts >= 2,
+				len(data) >= 9 && data[0] == solanaTokenInstructionTransferChecked && accounts >= 3:
+				amount = data[1:9]
+			case len(data) >= 10 && data[0] == solanaTokenInstructionTransferFeeExt &&
+				data[1] == solanaTransferFeeInstructionTransferCheckedWithFee && accounts >= 3:
+				amount = data[2:10]
+			}
+		}
+		if amount != nil {
+			return strconv.FormatUint(binary.LittleEndian.Uint64(amount), 10), nil
+		}
+	}
+
+	return "", fmt.Errorf("no transfer instruction found in Solana transaction")
+}
+
+// tokenAccountOwner returns the owner wallet and mint of a token account. Token accounts
+// that do not exist yet are resolved from an ATA creation instruction in the same transaction.
+func (b *SolanaBlockchain) tokenAccountOwner(ctx context.Context, tx *solana.Transaction, tokenAccount solana.PublicKey) (solana.PublicKey, solana.PublicKey, error) {
+	accountInfo, err := b.client.GetAccountInfo(ctx, tokenAccount)
+	if err != nil && !errors.Is(err, rpc.ErrNotFound) {
+		return solana.PublicKey{}, solana.PublicKey{}, fmt.Errorf("failed to get token account %s: %v", tokenAccount, err)
+	}
+
+	if err == nil {
+		owner := accountInfo.Value.Owner
+		data := accountInfo.GetBinary()
+		if !owner.Equals(solana.TokenProgramID) && !owner.Equals(solana.Token2022ProgramID) ||
+			len(data) < solanaTokenAccountOwnerOffset+solana.PublicKeyLength {
+			return solana.PublicKey{}, solana.PublicKey{}, fmt.Errorf("account %s is not a token account", tokenAccount)
+		}
+		return solana.PublicKeyFromBytes(data[solanaTokenAccountOwnerOffset : solanaTokenAccountOwnerOffset+solana.PublicKeyLength]),
+			solana.PublicKeyFromBytes(data[:solana.PublicKeyLength]), nil
+	}
+
+	for _, instruction := range tx.Message.Instructions {
+		programID, err := tx.Message.Program(instruction.ProgramIDIndex)
+		if err != nil || !programID.Equals(solana.SPLAssociatedTokenAccountProgramID) {
+			continue
+		}
+		if len(instruction.Data) > 0 && instruction.Data[0] != solanaATAInstructionCreate &&
+			instruction.Data[0] != solanaATAInstructionCreateIdempotent {
+			continue
+		}
+		accounts, err := solanaInstructionAccounts(&tx.Message, instruction)
+		if err != nil || len(accounts) < 4 {
+			continue
+		}
+		if accounts[1].Equals(tokenAccount) {
+			return accounts[2], accounts[3], nil
+		}
+	}
+
+	return solana.PublicKey{}, solana.PublicKey{}, fmt.Errorf("token account %s not found", tokenAccount)
+}
@@ -0,0 +1,808 @@
+package blockchains
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/StripChain/strip-node/common"
+	"github.com/StripChain/strip-node/util"
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	addresslookuptable "github.com/gagliardetto/solana-go/programs/address-lookup-table"
+	computebudget "github.com/gagliardetto/solana-go/programs/compute-budget"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/mr-tron/base58"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testSolanaBridge    = "DpZqkyDKkVv2S7Lhbd5dUVcVCPJz2Lypr4W5Cru2sHr7"
+	testSolanaRecipient = "5oNDL3swdJJF1g9DzJiZ4ynHXgszjAEpUkxVYejchzrY"
+	testSolanaMint      = "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+	testSolanaBlockhash = "CBLp4VEPu9T9W2uzURoawLGqgAQ65LvmUwDYRHymgwbd"
+)
+
+type mockSolanaAccount struct {
+	owner solana.PublicKey
+	data  []byte
+}
+
+// mockSolanaRPC is a minimal JSON-RPC stand-in for a Solana node.
+// Addresses missing from accounts are reported as not found.
+// Transactions hold jsonParsed getTransaction results keyed by signature.
+type mockSolanaRPC struct {
+	accounts     map[string]mockSolanaAccount
+	transactions map[string]string
+	epoch        uint64
+	fees         []uint64
+	sent         []*solana.Transaction
+}
+
+func (m *mockSolanaRPC) handle(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			ID     interface{}     `json:"id"`
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+
+		var result interface{}
+		switch request.Method {
+		case "getAccountInfo":
+			var params []interface{}
+			require.NoError(t, json.Unmarshal(request.Params, &params))
+			account, ok := m.accounts[params[0].(string)]
+			var value interface{}
+			if ok {
+				value = map[string]interface{}{
+					"data":       []string{base64.StdEncoding.EncodeToString(account.data), "base64"},
+					"executable": false,
+					"lamports":   2039280,
+					"owner":      account.owner.String(),
+					"rentEpoch":  0,
+				}
+			}
+			result = map[string]interface{}{"context": map[string]interface{}{"slot": 1}, "value": value}
+		case "getLatestBlockhash":
+			result = map[string]interface{}{
+				"context": map[string]interface{}{"slot": 1},
+				"value":   map[string]interface{}{"blockhash": testSolanaBlockhash, "lastValidBlockHeight": 100},
+			}
+		case "getEpochInfo":
+			result = map[string]interface{}{"absoluteSlot": 1, "blockHeight": 1, "epoch": m.epoch, "slotIndex": 0, "slotsInEpoch": 432000}
+		case "getRecentPrioritizationFees":
+			fees := []map[string]interface{}{}
+			for i, fee := range m.fees {
+				fees = append(fees, map[string]interface{}{"slot": i, "prioritizationFee": fee})
+			}
+			result = fees
+		case "getTransaction":
+			var params []interface{}
+			require.NoError(t, json.Unmarshal(request.Params, &params))
+			if tx, ok := m.transactions[params[0].(string)]; ok {
+				result = json.RawMessage(tx)
+			}
+		case "sendTransaction":
+			var params []interface{}
+			require.NoError(t, json.Unmarshal(request.Params, &params))
+			tx, err := solana.TransactionFromBase64(params[0].(string))
+			require.NoError(t, err)
+			m.sent = append(m.sent, tx)
+			result = tx.Signatures[0].String()
+		default:
+			t.Fatalf("unexpected RPC method %s", request.Method)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": request.ID, "result": result})
+	}
+}
+
+func newTestSolanaBlockchain(t *testing.T, mock *mockSolanaRPC) *SolanaBlockchain {
+	server := httptest.NewServer(mock.handle(t))
+	t.Cleanup(server.Close)
+
+	chainID := "901"
+	return &SolanaBlockchain{
+		BaseBlockchain: BaseBlockchain{
+			chainName:   Solana,
+			network:     Network{networkType: Devnet, nodeURL: server.URL, networkID: "devnet"},
+			chainID:     &chainID,
+			tokenSymbol: "SOL",
+		},
+		client: rpc.New(server.URL),
+	}
+}
+
+func testSolanaMintData(decimals uint8) []byte {
+	data := make([]byte, solanaMintSize)
+	data[solanaMintDecimalsOffset] = decimals
+	data[45] = 1 // is_initialized
+	return data
+}
+
+func testSolanaTransferFeeMintData(decimals uint8, older, newer solanaTransferFee) []byte {
+	data := make([]byte, solanaTokenAccountSize)
+	copy(data, testSolanaMintData(decimals))
+	data = append(data, solanaAccountTypeMint)
+	data = binary.LittleEndian.AppendUint16(data, solanaExtensionTypeTransferFeeConfig)
+	data = binary.LittleEndian.AppendUint16(data, solanaTransferFeeConfigSize)
+	data = append(data, make([]byte, 72)...)
+	for _, fee := range []solanaTransferFee{older, newer} {
+		data = binary.LittleEndian.AppendUint64(data, fee.Epoch)
+		data = binary.LittleEndian.AppendUint64(data, fee.MaximumFee)
+		data = binary.LittleEndian.AppendUint16(data, fee.TransferFeeBasisPoints)
+	}
+	return data
+}
+
+func decodeTestSolanaTx(t *testing.T, serializedTxn string, dataToSign string) *solana.Transaction {
+	decoded, err := base58.Decode(serializedTxn)
+	require.NoError(t, err)
+	tx, err := solana.TransactionFromDecoder(bin.NewBinDecoder(decoded))
+	require.NoError(t, err)
+
+	msg, err := tx.Message.MarshalBinary()
+	require.NoError(t, err)
+	require.Equal(t, base58.Encode(msg), dataToSign)
+	return tx
+}
+
+func testSolanaATA(t *testing.T, wallet string, tokenProgramID solana.PublicKey) solana.PublicKey {
+	ata, err := findSolanaAssociatedTokenAddress(
+		solana.MustPublicKeyFromBase58(wallet),
+		solana.MustPublicKeyFromBase58(testSolanaMint),
+		tokenProgramID,
+	)
+	require.NoError(t, err)
+	return ata
+}
+
+func TestSolanaBuildWithdrawTx(t *testing.T) {
+	zeroAddress := util.ZERO_ADDRESS
+	mint := testSolanaMint
+
+	classicRecipientATA := testSolanaATA(t, testSolanaRecipient, solana.TokenProgramID)
+	token2022RecipientATA := testSolanaATA(t, testSolanaRecipient, solana.Token2022ProgramID)
+
+	tests := []struct {
+		name          string
+		mock          *mockSolanaRPC
+		bridge        string
+		recipient     string
+		tokenAddress  *string
+		solverOutput  string
+		expectError   string
+		expectProgram []solana.PublicKey
+		check         func(t *testing.T, tx *solana.Transaction)
+	}{
+		{
+			name:          "Native SOL",
+			mock:          &mockSolanaRPC{fees: []uint64{0, 2_000, 50_000}},
+			bridge:        testSolanaBridge,
+			recipient:     testSolanaRecipient,
+			tokenAddress:  &zeroAddress,
+			solverOutput:  `{"amount":"1000000"}`,
+			expectProgram: []solana.PublicKey{solana.ComputeBudget, solana.ComputeBudget, solana.SystemProgramID},
+			check: func(t *testing.T, tx *solana.Transaction) {
+				// SetComputeUnitPrice carries the median of the recent fees
+				price := tx.Message.Instructions[1].Data
+				require.Equal(t, uint64(2_000), binary.LittleEndian.Uint64(price[1:]))
+			},
+		},
+		{
+			name: "SPL token with existing recipient ATA",
+			mock: &mockSolanaRPC{accounts: map[string]mockSolanaAccount{
+				testSolanaMint:               {owner: solana.TokenProgramID, data: testSolanaMintData(6)},
+				classicRecipientATA.String(): {owner: solana.TokenProgramID, data: make([]byte, solanaTokenAccountSize)},
+			}},
+			bridge:        testSolanaBridge,
+			recipient:     testSolanaRecipient,
+			tokenAddress:  &mint,
+			solverOutput:  `{"amount":"2500000"}`,
+			expectProgram: []solana.PublicKey{solana.ComputeBudget, solana.ComputeBudget, solana.TokenProgramID},
+			check: func(t *testing.T, tx *solana.Transaction) {
+				data := tx.Message.Instructions[2].Data
+				require.Equal(t, byte(solanaTokenInstructionTransferChecked), data[0])
+				require.Equal(t, uint64(2_500_000), binary.LittleEndian.Uint64(data[1:9]))
+				require.Equal(t, byte(6), data[9])
+				// priority fee falls back to the minimum when no recent fees are known
+				price := tx.Message.Instructions[1].Data
+				require.Equal(t, solanaMinComputeUnitPrice, binary.LittleEndian.Uint64(price[1:]))
+			},
+		},
+		{
+			name: "SPL token with missing recipient ATA",
+			mock: &mockSolanaRPC{accounts: map[string]mockSolanaAccount{
+				testSolanaMint: {owner: solana.TokenProgramID, data: testSolanaMintData(6)},
+			}},
+			bridge:       testSolanaBridge,
+			recipient:    testSolanaRecipient,
+			tokenAddress: &mint,
+			solverOutput: `{"amount":"2500000"}`,
+			expectProgram: []solana.PublicKey{
+				solana.ComputeBudget, solana.ComputeBudget,
+				solana.SPLAssociatedTokenAccountProgramID, solana.TokenProgramID,
+			},
+			check: func(t *testing.T, tx *solana.Transaction) {
+				create := tx.Message.Instructions[2]
+				require.Equal(t, []byte{solanaATAInstructionCreateIdempotent}, []byte(create.Data))
+				require.Equal(t, classicRecipientATA, tx.Message.AccountKeys[create.Accounts[1]])
+				require.Equal(t, classicRecipientATA, tx.Message.AccountKeys[tx.Message.Instructions[3].Accounts[2]])
+			},
+		},
+		{
+			name: "Token-2022 with transfer fee",
+			mock: &mockSolanaRPC{
+				epoch: 600,
+				accounts: map[string]mockSolanaAccount{
+					testSolanaMint: {owner: solana.Token2022ProgramID, data: testSolanaTransferFeeMintData(9,
+						solanaTransferFee{Epoch: 0, MaximumFee: 1_000_000, TransferFeeBasisPoints: 10},
+						solanaTransferFee{Epoch: 500, MaximumFee: 1_000_000, TransferFeeBasisPoints: 50},
+					)},
+				},
+			},
+			bridge:       testSolanaBridge,
+			recipient:    testSolanaRecipient,
+			tokenAddress: &mint,
+			solverOutput: `{"amount":"1000001"}`,
+			expectProgram: []solana.PublicKey{
+				solana.ComputeBudget, solana.ComputeBudget,
+				solana.SPLAssociatedTokenAccountProgramID, solana.Token2022ProgramID,
+			},
+			check: func(t *testing.T, tx *solana.Transaction) {
+				create := tx.Message.Instructions[2]
+				require.Equal(t, token2022RecipientATA, tx.Message.AccountKeys[create.Accounts[1]])
+				require.Equal(t, solana.Token2022ProgramID, tx.Message.AccountKeys[create.Accounts[5]])
+
+				data := tx.Message.Instructions[3].Data
+				require.Equal(t, []byte{solanaTokenInstructionTransferFeeExt, solanaTransferFeeInstructionTransferCheckedWithFee}, []byte(data[:2]))
+				require.Equal(t, uint64(1_000_001), binary.LittleEndian.Uint64(data[2:10]))
+				require.Equal(t, byte(9), data[10])
+				// newer fee schedule applies: ceil(1000001 * 50 / 10000)
+				require.Equal(t, uint64(5_001), binary.LittleEndian.Uint64(data[11:19]))
+			},
+		},
+		{
+			name: "Token-2022 without extensions",
+			mock: &mockSolanaRPC{accounts: map[string]mockSolanaAccount{
+				testSolanaMint:                 {owner: solana.Token2022ProgramID, data: testSolanaMintData(2)},
+				token2022RecipientATA.String(): {owner: solana.Token2022ProgramID, data: make([]byte, solanaTokenAccountSize)},
+			}},
+			bridge:        testSolanaBridge,
+			recipient:     testSolanaRecipient,
+			tokenAddress:  &mint,
+			solverOutput:  `{"amount":"100"}`,
+			expectProgram: []solana.PublicKey{solana.ComputeBudget, solana.ComputeBudget, solana.Token2022ProgramID},
+		},
+		{
+			name:         "Invalid recipient address",
+			mock:         &mockSolanaRPC{},
+			bridge:       testSolanaBridge,
+			recipient:    "not-a-solana-address",
+			tokenAddress: &zeroAddress,
+			solverOutput: `{"amount":"1"}`,
+			expectError:  "invalid recipient address",
+		},
+		{
+			name:         "Invalid bridge address",
+			mock:         &mockSolanaRPC{},
+			bridge:       "0OIl",
+			recipient:    testSolanaRecipient,
+			tokenAddress: &zeroAddress,
+			solverOutput: `{"amount":"1"}`,
+			expectError:  "invalid bridge address",
+		},
+		{
+			name:         "Invalid token address",
+			mock:         &mockSolanaRPC{},
+			bridge:       testSolanaBridge,
+			recipient:    testSolanaRecipient,
+			tokenAddress: func() *string { s := "invalid"; return &s }(),
+			solverOutput: `{"amount":"1"}`,
+			expectError:  "invalid token address",
+		},
+		{
+			name: "Token address is not a mint",
+			mock: &mockSolanaRPC{accounts: map[string]mockSolanaAccount{
+				testSolanaMint: {owner: solana.SystemProgramID, data: nil},
+			}},
+			bridge:       testSolanaBridge,
+			recipient:    testSolanaRecipient,
+			tokenAddress: &mint,
+			solverOutput: `{"amount":"1"}`,
+			expectError:  "account is not a token mint",
+		},
+		{
+			name:         "Amount overflows uint64",
+			mock:         &mockSolanaRPC{},
+			bridge:       testSolanaBridge,
+			recipient:    testSolanaRecipient,
+			tokenAddress: &zeroAddress,
+			solverOutput: `{"amount":"18446744073709551616"}`,
+			expectError:  "invalid amount",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestSolanaBlockchain(t, tt.mock)
+			serializedTxn, dataToSign, err := b.BuildWithdrawTx(tt.bridge, tt.solverOutput, tt.recipient, tt.tokenAddress)
+			if tt.expectError != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.expectError)
+				return
+			}
+			require.NoError(t, err)
+
+			tx := decodeTestSolanaTx(t, serializedTxn, dataToSign)
+
+			computed, err := b.ComputeDataToSign(serializedTxn)
+			require.NoError(t, err)
+			require.Equal(t, dataToSign, computed)
+			require.Equal(t, solana.MustPublicKeyFromBase58(tt.bridge), tx.Message.AccountKeys[0])
+			require.Len(t, tx.Message.Instructions, len(tt.expectProgram))
+			for i, program := range tt.expectProgram {
+				programID, err := tx.Message.Program(tx.Message.Instructions[i].ProgramIDIndex)
+				require.NoError(t, err)
+				require.Equal(t, program, programID, "instruction %d", i)
+			}
+			if tt.check != nil {
+				tt.check(t, tx)
+			}
+		})
+	}
+}
+
+func TestSolanaTransferFeeCalculate(t *testing.T) {
+	tests := []struct {
+		name   string
+		fee    solanaTransferFee
+		amount uint64
+		expect uint64
+	}{
+		{"Zero basis points", solanaTransferFee{MaximumFee: 100}, 1_000, 0},
+		{"Rounds up", solanaTransferFee{MaximumFee: 100, TransferFeeBasisPoints: 1}, 1, 1},
+		{"Exact", solanaTransferFee{MaximumFee: 100, TransferFeeBasisPoints: 100}, 1_000, 10},
+		{"Capped at maximum", solanaTransferFee{MaximumFee: 5, TransferFeeBasisPoints: 100}, 1_000, 5},
+		{"No overflow", solanaTransferFee{MaximumFee: 7, TransferFeeBasisPoints: 10_000}, ^uint64(0), 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expect, tt.fee.Calculate(tt.amount))
+		})
+	}
+}
+
+func testSolanaLookupTableData(t *testing.T, addresses solana.PublicKeySlice) []byte {
+	var buf bytes.Buffer
+	state := addresslookuptable.AddressLookupTableState{
+		TypeIndex:        1,
+		DeactivationSlot: math.MaxUint64,
+		Addresses:        addresses,
+	}
+	require.NoError(t, state.MarshalWithEncoder(bin.NewBinEncoder(&buf)))
+	return buf.Bytes()
+}
+
+func testSolanaTokenAccountData(mint, owner solana.PublicKey) []byte {
+	data := make([]byte, solanaTokenAccountSize)
+	copy(data[:32], mint[:])
+	copy(data[32:64], owner[:])
+	return data
+}
+
+// encodeTestSolanaTx serializes tx the way wallets do, with a zeroed signature per required signer
+func encodeTestSolanaTx(t *testing.T, tx *solana.Transaction) string {
+	tx.Signatures = make([]solana.Signature, tx.Message.Header.NumRequiredSignatures)
+	raw, err := tx.MarshalBinary()
+	require.NoError(t, err)
+	return base58.Encode(raw)
+}
+
+func TestSolanaExtractDestinationAddress(t *testing.T) {
+	payer := solana.MustPublicKeyFromBase58(testSolanaRecipient)
+	bridge := solana.MustPublicKeyFromBase58(testSolanaBridge)
+	mint := solana.MustPublicKeyFromBase58(testSolanaMint)
+	table := solana.NewWallet().PublicKey()
+	blockhash := solana.MustHashFromBase58(testSolanaBlockhash)
+
+	senderATA := testSolanaATA(t, testSolanaRecipient, solana.TokenProgramID)
+	bridgeATA := testSolanaATA(t, testSolanaBridge, solana.TokenProgramID)
+	bridgeATA2022 := testSolanaATA(t, testSolanaBridge, solana.Token2022ProgramID)
+
+	tests := []struct {
+		name         string
+		instructions []solana.Instruction
+		tables       map[solana.PublicKey]solana.PublicKeySlice
+		accounts     map[string]mockSolanaAccount
+		expectDest   string
+		expectToken  string
+		expectAmount string
+		expectError  string
+	}{
+		{
+			name:         "Legacy SOL transfer",
+			instructions: []solana.Instruction{system.NewTransferInstruction(1, payer, bridge).Build()},
+			expectDest:   testSolanaBridge,
+			expectAmount: "1",
+		},
+		{
+			name: "V0 SOL transfer to lookup table account after compute budget",
+			instructions: []solana.Instruction{
+				newTestSolanaComputeBudget(),
+				system.NewTransferInstruction(1, payer, bridge).Build(),
+			},
+			tables: map[solana.PublicKey]solana.PublicKeySlice{table: {solana.NewWallet().PublicKey(), bridge}},
+			accounts: map[string]mockSolanaAccount{
+				table.String(): {owner: solanaAddressLookupTableProgramID},
+			},
+			expectDest:   testSolanaBridge,
+			expectAmount: "1",
+		},
+		{
+			name: "V0 token transfer to existing token account in lookup table",
+			instructions: []solana.Instruction{
+				newSolanaTransferCheckedInstruction(solana.TokenProgramID, senderATA, mint, bridgeATA, payer, 10, 6),
+			},
+			tables: map[solana.PublicKey]solana.PublicKeySlice{table: {mint, bridgeATA}},
+			accounts: map[string]mockSolanaAccount{
+				table.String():     {owner: solanaAddressLookupTableProgramID},
+				bridgeATA.String(): {owner: solana.TokenProgramID, data: testSolanaTokenAccountData(mint, bridge)},
+			},
+			expectDest:   testSolanaBridge,
+			expectToken:  testSolanaMint,
+			expectAmount: "10",
+		},
+		{
+			name: "Token-2022 transfer to an ATA created in the same transaction",
+			instructions: []solana.Instruction{
+				newSolanaCreateATAIdempotentInstruction(payer, bridgeATA2022, bridge, mint, solana.Token2022ProgramID),
+				newSolanaTransferCheckedInstruction(solana.Token2022ProgramID, senderATA, mint, bridgeATA2022, payer, 10, 6),
+			},
+			expectDest:   testSolanaBridge,
+			expectToken:  testSolanaMint,
+			expectAmount: "10",
+		},
+		{
+			name: "Mint does not match destination token account",
+			instructions: []solana.Instruction{
+				newSolanaTransferCheckedInstruction(solana.TokenProgramID, senderATA, mint, bridgeATA, payer, 10, 6),
+			},
+			accounts: map[string]mockSolanaAccount{
+				bridgeATA.String(): {owner: solana.TokenProgramID, data: testSolanaTokenAccountData(solana.NewWallet().PublicKey(), bridge)},
+			},
+			expectError: "does not hold mint",
+		},
+		{
+			name:         "No transfer instruction",
+			instructions: []solana.Instruction{newTestSolanaComputeBudget()},
+			expectError:  "no transfer instruction found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := []solana.TransactionOption{solana.TransactionPayer(payer)}
+			if tt.tables != nil {
+				options = append(options, solana.TransactionAddressTables(tt.tables))
+			}
+			tx, err := solana.NewTransaction(tt.instructions, blockhash, options...)
+			require.NoError(t, err)
+			require.Equal(t, tt.tables != nil, tx.Message.IsVersioned())
+
+			for tableID, addresses := range tt.tables {
+				account := tt.accounts[tableID.String()]
+				account.data = testSolanaLookupTableData(t, addresses)
+				tt.accounts[tableID.String()] = account
+			}
+
+			b := newTestSolanaBlockchain(t, &mockSolanaRPC{accounts: tt.accounts})
+			dest, token, err := b.ExtractDestinationAddress(encodeTestSolanaTx(t, tx))
+			if tt.expectError != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.expectError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expectDest, dest)
+			require.Equal(t, tt.expectToken, token)
+
+			amount, err := b.ExtractTransferAmount(encodeTestSolanaTx(t, tx))
+			require.NoError(t, err)
+			require.Equal(t, tt.expectAmount, amount)
+		})
+	}
+}
+
+func newTestSolanaComputeBudget() solana.Instruction {
+	return computebudget.NewSetComputeUnitLimitInstruction(solanaWithdrawComputeUnitLimit).Build()
+}
+
+func TestSolanaBroadcastTransaction(t *testing.T) {
+	signer := solana.NewWallet()
+	signerAddress := signer.PublicKey().String()
+	other := solana.NewWallet()
+	otherAddress := other.PublicKey().String()
+	bridge := solana.MustPublicKeyFromBase58(testSolanaBridge)
+	table := solana.NewWallet().PublicKey()
+	blockhash := solana.MustHashFromBase58(testSolanaBlockhash)
+
+	newTx := func(t *testing.T, versioned bool) *solana.Transaction {
+		var options []solana.TransactionOption
+		options = append(options, solana.TransactionPayer(signer.PublicKey()))
+		if versioned {
+			options = append(options, solana.TransactionAddressTables(map[solana.PublicKey]solana.PublicKeySlice{
+				table: {bridge},
+			}))
+		}
+		tx, err := solana.NewTransaction([]solana.Instruction{
+			system.NewTransferInstruction(1, signer.PublicKey(), bridge).Build(),
+		}, blockhash, options...)
+		require.NoError(t, err)
+		return tx
+	}
+
+	sign := func(t *testing.T, tx *solana.Transaction, key solana.PrivateKey) string {
+		msg, err := tx.Message.MarshalBinary()
+		require.NoError(t, err)
+		signature, err := key.Sign(msg)
+		require.NoError(t, err)
+		return signature.String()
+	}
+
+	tests := []struct {
+		name        string
+		versioned   bool
+		unsigned    bool
+		publicKey   *string
+		signingKey  solana.PrivateKey
+		expectError string
+	}{
+		{name: "Legacy transaction with placeholder signature", publicKey: &signerAddress, signingKey: signer.PrivateKey},
+		{name: "Legacy transaction without signatures", unsigned: true, signingKey: signer.PrivateKey},
+		{name: "V0 transaction with lookup table", versioned: true, publicKey: &signerAddress, signingKey: signer.PrivateKey},
+		{
+			name:        "Public key is not a signer",
+			versioned:   true,
+			publicKey:   &otherAddress,
+			signingKey:  other.PrivateKey,
+			expectError: "is not a signer of the transaction",
+		},
+		{
+			name:        "Signature from the wrong key",
+			versioned:   true,
+			publicKey:   &signerAddress,
+			signingKey:  other.PrivateKey,
+			expectError: "failed to verify signatures",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := newTx(t, tt.versioned)
+			require.Equal(t, tt.versioned, tx.Message.IsVersioned())
+
+			serializedTxn := encodeTestSolanaTx(t, tx)
+			if tt.unsigned {
+				raw, err := tx.Message.MarshalBinary()
+				require.NoError(t, err)
+				serializedTxn = base58.Encode(append([]byte{0}, raw...))
+			}
+
+			mock := &mockSolanaRPC{}
+			b := newTestSolanaBlockchain(t, mock)
+			signature := sign(t, tx, tt.signingKey)
+			hash, err := b.BroadcastTransaction(serializedTxn, signature, tt.publicKey)
+			if tt.expectError != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.expectError)
+				require.Empty(t, mock.sent)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, signature, hash)
+			require.Len(t, mock.sent, 1)
+			require.Equal(t, tt.versioned, mock.sent[0].Message.IsVersioned())
+			require.NoError(t, mock.sent[0].VerifySignatures())
+		})
+	}
+}
+
+// testSolanaParsedTransaction renders a jsonParsed getTransaction result
+func testSolanaParsedTransaction(accountKeys []string, instructions, innerInstructions, preTokenBalances, postTokenBalances string) string {
+	keys := []string{}
+	for _, key := range accountKeys {
+		keys = append(keys, fmt.Sprintf(`{"pubkey":%q,"signer":false,"writable":true,"source":"transaction"}`, key))
+	}
+	return fmt.Sprintf(`{
+		"slot": 1,
+		"blockTime": 1700000000,
+		"version": 0,
+		"meta": {
+			"err": null,
+			"fee": 5000,
+			"innerInstructions": %s,
+			"preBalances": [],
+			"postBalances": [],
+			"preTokenBalances": %s,
+			"postTokenBalances": %s
+		},
+		"transaction": {
+			"signatures": [],
+			"message": {
+				"accountKeys": [%s],
+				"instructions": %s,
+				"recentBlockhash": %q
+			}
+		}
+	}`, innerInstructions, preTokenBalances, postTokenBalances, strings.Join(keys, ","), instructions, testSolanaBlockhash)
+}
+
+func testSolanaTokenBalance(accountIndex int, mint, owner string, programID solana.PublicKey, amount string, decimals uint8) string {
+	return fmt.Sprintf(`{"accountIndex":%d,"mint":%q,"owner":%q,"programId":%q,"uiTokenAmount":{"amount":%q,"decimals":%d}}`,
+		accountIndex, mint, owner, programID, amount, decimals)
+}
+
+func TestSolanaGetTransfers(t *testing.T) {
+	token2022Mint := "2b1kV6DkPAnxd5ixfnxCpjxmKwqjjaYmCZfHsFu24GXo"
+	sourceAccount := testSolanaATA(t, testSolanaBridge, solana.TokenProgramID).String()
+	destinationAccount := testSolanaATA(t, testSolanaRecipient, solana.TokenProgramID).String()
+	source2022Account := testSolanaATA(t, testSolanaBridge, solana.Token2022ProgramID).String()
+	destination2022Account := testSolanaATA(t, testSolanaRecipient, solana.Token2022ProgramID).String()
+	tokenKeys := []string{testSolanaBridge, sourceAccount, destinationAccount, testSolanaRecipient, testSolanaMint, solana.TokenProgramID.String()}
+	token2022Keys := []string{testSolanaBridge, source2022Account, destination2022Account, testSolanaRecipient, token2022Mint, solana.Token2022ProgramID.String()}
+
+	tests := []struct {
+		name     string
+		tx       string
+		expected []common.Transfer
+	}{
+		{
+			name: "native transfer",
+			tx: testSolanaParsedTransaction(
+				[]string{testSolanaBridge, testSolanaRecipient, solana.SystemProgramID.String()},
+				fmt.Sprintf(`[{"program":"system","programId":%q,"parsed":{"type":"transfer","info":{"source":%q,"destination":%q,"lamports":1500000000}}}]`,
+					solana.SystemProgramID, testSolanaBridge, testSolanaRecipient),
+				`[]`, `[]`, `[]`,
+			),
+			expected: []common.Transfer{{
+				From:         testSolanaBridge,
+				To:           testSolanaRecipient,
+				Amount:       "1.500000000",
+				Token:        "SOL",
+				IsNative:     true,
+				TokenAddress: util.ZERO_ADDRESS,
+				ScaledAmount: "1500000000",
+			}},
+		},
+		{
+			name: "token transferChecked after compute budget",
+			tx: testSolanaParsedTransaction(
+				tokenKeys,
+				fmt.Sprintf(`[
+					{"programId":%q,"accounts":[],"data":"3DdGGhkhJbjm"},
+					{"program":"spl-token","programId":%q,"parsed":{"type":"transferChecked","info":{"source":%q,"destination":%q,"mint":%q,"authority":%q,"tokenAmount":{"amount":"2500000","decimals":6}}}}
+				]`, solana.ComputeBudget, solana.TokenProgramID, sourceAccount, destinationAccount, testSolanaMint, testSolanaBridge),
+				`[]`,
+				fmt.Sprintf(`[%s,%s]`,
+					testSolanaTokenBalance(1, testSolanaMint, testSolanaBridge, solana.TokenProgramID, "10000000", 6),
+					testSolanaTokenBalance(2, testSolanaMint, testSolanaRecipient, solana.TokenProgramID, "0", 6)),
+				fmt.Sprintf(`[%s,%s]`,
+					testSolanaTokenBalance(1, testSolanaMint, testSolanaBridge, solana.TokenProgramID, "7500000", 6),
+					testSolanaTokenBalance(2, testSolanaMint, testSolanaRecipient, solana.TokenProgramID, "2500000", 6)),
+			),
+			expected: []common.Transfer{{
+				From:         testSolanaBridge,
+				To:           testSolanaRecipient,
+				Amount:       "2.500000",
+				Token:        testSolanaMint,
+				TokenAddress: testSolanaMint,
+				ScaledAmount: "2500000",
+			}},
+		},
+		{
+			name: "unchecked token transfer invoked by another program",
+			tx: testSolanaParsedTransaction(
+				tokenKeys,
+				fmt.Sprintf(`[{"programId":%q,"accounts":[],"data":"1"}]`, testSolanaRecipient),
+				fmt.Sprintf(`[{"index":0,"instructions":[{"program":"spl-token","programId":%q,"parsed":{"type":"transfer","info":{"source":%q,"destination":%q,"authority":%q,"amount":"1000000"}}}]}]`,
+					solana.TokenProgramID, sourceAccount, destinationAccount, testSolanaBridge),
+				fmt.Sprintf(`[%s]`, testSolanaTokenBalance(1, testSolanaMint, testSolanaBridge, solana.TokenProgramID, "1000000", 6)),
+				fmt.Sprintf(`[%s,%s]`,
+					testSolanaTokenBalance(1, testSolanaMint, testSolanaBridge, solana.TokenProgramID, "0", 6),
+					testSolanaTokenBalance(2, testSolanaMint, testSolanaRecipient, solana.TokenProgramID, "1000000", 6)),
+			),
+			expected: []common.Transfer{{
+				From:         testSolanaBridge,
+				To:           testSolanaRecipient,
+				Amount:       "1.000000",
+				Token:        testSolanaMint,
+				TokenAddress: testSolanaMint,
+				ScaledAmount: "1000000",
+			}},
+		},
+		{
+			name: "token-2022 transfer with fee reports the received amount",
+			tx: testSolanaParsedTransaction(
+				token2022Keys,
+				fmt.Sprintf(`[{"program":"spl-token","programId":%q,"parsed":{"type":"transferCheckedWithFee","info":{"source":%q,"destination":%q,"mint":%q,"authority":%q,"tokenAmount":{"amount":"1000000","decimals":6},"feeAmount":{"amount":"10000","decimals":6}}}}]`,
+					solana.Token2022ProgramID, source2022Account, destination2022Account, token2022Mint, testSolanaBridge),
+				`[]`,
+				fmt.Sprintf(`[%s,%s]`,
+					testSolanaTokenBalance(1, token2022Mint, testSolanaBridge, solana.Token2022ProgramID, "5000000", 6),
+					testSolanaTokenBalance(2, token2022Mint, testSolanaRecipient, solana.Token2022ProgramID, "0", 6)),
+				fmt.Sprintf(`[%s,%s]`,
+					testSolanaTokenBalance(1, token2022Mint, testSolanaBridge, solana.Token2022ProgramID, "4000000", 6),
+					testSolanaTokenBalance(2, token2022Mint, testSolanaRecipient, solana.Token2022ProgramID, "990000", 6)),
+			),
+			expected: []common.Transfer{{
+				From:         testSolanaBridge,
+				To:           testSolanaRecipient,
+				Amount:       "0.990000",
+				Token:        token2022Mint,
+				TokenAddress: token2022Mint,
+				ScaledAmount: "990000",
+			}},
+		},
+		{
+			name: "token balance changes without a parsed transfer",
+			tx: testSolanaParsedTransaction(
+				tokenKeys,
+				fmt.Sprintf(`[{"programId":%q,"accounts":[],"data":"1"}]`, testSolanaRecipient),
+				`[]`,
+				fmt.Sprintf(`[%s,%s]`,
+					testSolanaTokenBalance(1, testSolanaMint, testSolanaBridge, solana.TokenProgramID, "3000000", 6),
+					testSolanaTokenBalance(2, testSolanaMint, testSolanaRecipient, solana.TokenProgramID, "0", 6)),
+				fmt.Sprintf(`[%s,%s]`,
+					testSolanaTokenBalance(1, testSolanaMint, testSolanaBridge, solana.TokenProgramID, "0", 6),
+					testSolanaTokenBalance(2, testSolanaMint, testSolanaRecipient, solana.TokenProgramID, "3000000", 6)),
+			),
+			expected: []common.Transfer{{
+				From:         testSolanaBridge,
+				To:           testSolanaRecipient,
+				Amount:       "3.000000",
+				Token:        testSolanaMint,
+				TokenAddress: testSolanaMint,
+				ScaledAmount: "3000000",
+			}},
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signature := solana.SignatureFromBytes(bytes.Repeat([]byte{byte(i + 1)}, solana.SignatureLength)).String()
+			b := newTestSolanaBlockchain(t, &mockSolanaRPC{transactions: map[string]string{signature: tt.tx}})
+
+			transfers, err := b.GetTransfers(signature, nil)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, transfers)
+		})
+	}
+
+	t.Run("failed transaction", func(t *testing.T) {
+		signature := solana.SignatureFromBytes(bytes.Repeat([]byte{0xff}, solana.SignatureLength)).String()
+		tx := strings.Replace(testSolanaParsedTransaction(
+			[]string{testSolanaBridge, testSolanaRecipient, solana.SystemProgramID.String()},
+			fmt.Sprintf(`[{"program":"system","programId":%q,"parsed":{"type":"transfer","info":{"source":%q,"destination":%q,"lamports":1}}}]`,
+				solana.SystemProgramID, testSolanaBridge, testSolanaRecipient),
+			`[]`, `[]`, `[]`,
+		), `"err": null`, `"err": {"InstructionError":[0,"Custom"]}`, 1)
+		b := newTestSolanaBlockchain(t, &mockSolanaRPC{transactions: map[string]string{signature: tx}})
+
+		transfers, err := b.GetTransfers(signature, nil)
+		require.NoError(t, err)
+		require.Empty(t, transfers)
+	})
+}
@@ -0,0 +1,312 @@
+package blockchains
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/gagliardetto/solana-go"
+	computebudget "github.com/gagliardetto/solana-go/programs/compute-budget"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+const (
+	// Layout of the base SPL mint account (shared by Token and Token-2022)
+	solanaMintSize           = 82
+	solanaMintDecimalsOffset = 44
+	// Token-2022 pads extended mints to the token account size, then writes
+	// the account type byte followed by the TLV-encoded extensions
+	solanaTokenAccountSize   = 165
+	solanaAccountTypeMint    = 1
+	solanaExtensionTypeEmpty = 0
+	// ExtensionType::TransferFeeConfig in the Token-2022 program
+	solanaExtensionTypeTransferFeeConfig = 1
+	solanaTransferFeeConfigSize          = 108
+
+	// Token program instruction discriminators
+	solanaTokenInstructionTransferChecked              = 12
+	solanaTokenInstructionTransferFeeExt               = 26
+	solanaTransferFeeInstructionTransferCheckedWithFee = 1
+	// AssociatedTokenAccountInstruction::CreateIdempotent
+	solanaATAInstructionCreateIdempotent = 1
+
+	// Compute budget used for withdrawals. A withdrawal is at most an ATA
+	// creation plus one transfer, so 200k units leaves plenty of headroom.
+	solanaWithdrawComputeUnitLimit uint32 = 200_000
+	// Priority fee bounds in micro-lamports per compute unit
+	solanaMinComputeUnitPrice uint64 = 1_000
+	solanaMaxComputeUnitPrice uint64 = 5_000_000
+)
+
+// solanaTransferFee mirrors the TransferFee struct of the Token-2022 transfer fee extension
+type solanaTransferFee struct {
+	Epoch                  uint64
+	MaximumFee             uint64
+	TransferFeeBasisPoints uint16
+}
+
+// Calculate returns the fee withheld by the mint for a transfer of amount,
+// matching the on-chain rounding (ceil) and cap.
+func (f solanaTransferFee) Calculate(amount uint64) uint64 {
+	if f.TransferFeeBasisPoints == 0 || amount == 0 {
+		return 0
+	}
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(amount), big.NewInt(int64(f.TransferFeeBasisPoints)))
+	fee.Add(fee, big.NewInt(9_999))
+	fee.Div(fee, big.NewInt(10_000))
+	if !fee.IsUint64() || fee.Uint64() > f.MaximumFee {
+		return f.MaximumFee
+	}
+	return fee.Uint64()
+}
+
+// solanaTransferFeeConfig holds the older and newer fee schedules of a Token-2022 mint
+type solanaTransferFeeConfig struct {
+	OlderTransferFee solanaTransferFee
+	NewerTransferFee solanaTransferFee
+}
+
+// FeeForEpoch returns the fee schedule in effect at the given epoch
+func (c solanaTransferFeeConfig) FeeForEpoch(epoch uint64) solanaTransferFee {
+	if epoch >= c.NewerTransferFee.Epoch {
+		return c.NewerTransferFee
+	}
+	return c.OlderTransferFee
+}
+
+// solanaMint describes the parts of a mint account needed to build a transfer
+type solanaMint struct {
+	ProgramID   solana.PublicKey
+	Decimals    uint8
+	TransferFee *solanaTransferFeeConfig
+}
+
+// parseSolanaMint decodes a mint account owned by either the Token or Token-2022 program
+func parseSolanaMint(owner solana.PublicKey, data []byte) (*solanaMint, error) {
+	if !owner.Equals(solana.TokenProgramID) && !owner.Equals(solana.Token2022ProgramID) {
+		return nil, fmt.Errorf("account is not a token mint, owner: %s", owner)
+	}
+	if len(data) < solanaMintSize {
+		return nil, fmt.Errorf("invalid mint account size: %d", len(data))
+	}
+
+	mint := &solanaMint{
+		ProgramID: owner,
+		Decimals:  data[solanaMintDecimalsOffset],
+	}
+
+	if !owner.Equals(solana.Token2022ProgramID) || len(data) <= solanaTokenAccountSize {
+		return mint, nil
+	}
+
+	if data[solanaTokenAccountSize] != solanaAccountTypeMint {
+		return nil, fmt.Errorf("unexpected token-2022 account type: %d", data[solanaTokenAccountSize])
+	}
+
+	tlv := data[solanaTokenAccountSize+1:]
+	for len(tlv) >= 4 {
+		extensionType := binary.LittleEndian.Uint16(tlv[0:2])
+		length := int(binary.LittleEndian.Uint16(tlv[2:4]))
+		if extensionType == solanaExtensionTypeEmpty {
+			break
+		}
+		if len(tlv) < 4+length {
+			return nil, fmt.Errorf("truncated token-2022 extension %d", extensionType)
+		}
+		value := tlv[4 : 4+length]
+		if extensionType == solanaExtensionTypeTransferFeeConfig {
+			if length != solanaTransferFeeConfigSize {
+				return nil, fmt.Errorf("invalid transfer fee config size: %d", length)
+			}
+			// Skip the two authorities (32 bytes each) and the withheld amount (8 bytes)
+			mint.TransferFee = &solanaTransferFeeConfig{
+				OlderTransferFee: parseSolanaTransferFee(value[72:90]),
+				NewerTransferFee: parseSolanaTransferFee(value[90:108]),
+			}
+		}
+		tlv = tlv[4+length:]
+	}
+
+	return mint, nil
+}
+
+func parseSolanaTransferFee(data []byte) solanaTransferFee {
+	return solanaTransferFee{
+		Epoch:                  binary.LittleEndian.Uint64(data[0:8]),
+		MaximumFee:             binary.LittleEndian.Uint64(data[8:16]),
+		TransferFeeBasisPoints: binary.LittleEndian.Uint16(data[16:18]),
+	}
+}
+
+// findSolanaAssociatedTokenAddress derives the ATA of wallet for mint under the given token program.
+// solana.FindAssociatedTokenAddress only supports the classic token program.
+func findSolanaAssociatedTokenAddress(wallet, mint, tokenProgramID solana.PublicKey) (solana.PublicKey, error) {
+	address, _, err := solana.FindProgramAddress(
+		[][]byte{wallet[:], tokenProgramID[:], mint[:]},
+		solana.SPLAssociatedTokenAccountProgramID,
+	)
+	return address, err
+}
+
+// newSolanaCreateATAIdempotentInstruction creates the recipient's associated token account,
+// succeeding without changes if it already exists
+func newSolanaCreateATAIdempotentInstruction(payer, ata, wallet, mint, tokenProgramID solana.PublicKey) solana.Instruction {
+	return solana.NewInstruction(
+		solana.SPLAssociatedTokenAccountProgramID,
+		solana.AccountMetaSlice{
+			solana.Meta(payer).WRITE().SIGNER(),
+			solana.Meta(ata).WRITE(),
+			solana.Meta(wallet),
+			solana.Meta(mint),
+			solana.Meta(solana.SystemProgramID),
+			solana.Meta(tokenProgramID),
+		},
+		[]byte{solanaATAInstructionCreateIdempotent},
+	)
+}
+
+// newSolanaTransferCheckedInstruction builds TransferChecked for either token program
+func newSolanaTransferCheckedInstruction(tokenProgramID, source, mint, destination, owner solana.PublicKey, amount uint64, decimals uint8) solana.Instruction {
+	data := make([]byte, 0, 10)
+	data = append(data, solanaTokenInstructionTransferChecked)
+	data = binary.LittleEndian.AppendUint64(data, amount)
+	data = append(data, decimals)
+
+	return solana.NewInstruction(tokenProgramID, solanaTransferAccounts(source, mint, destination, owner), data)
+}
+
+// newSolanaTransferCheckedWithFeeInstruction builds the Token-2022 TransferCheckedWithFee instruction,
+// which fails on-chain if fee does not match the fee the mint would withhold
+func newSolanaTransferCheckedWithFeeInstruction(source, mint, destination, owner solana.PublicKey, amount uint64, decimals uint8, fee uint64) solana.Instruction {
+	data := make([]byte, 0, 19)
+	data = append(data, solanaTokenInstructionTransferFeeExt, solanaTransferFeeInstructionTransferCheckedWithFee)
+	data = binary.LittleEndian.AppendUint64(data, amount)
+	data = append(data, decimals)
+	data = binary.LittleEndian.AppendUint64(data, fee)
+
+	return solana.NewInstruction(solana.Token2022ProgramID, solanaTransferAccounts(source, mint, destination, owner), data)
+}
+
+func solanaTransferAccounts(source, mint, destination, owner solana.PublicKey) solana.AccountMetaSlice {
+	return solana.AccountMetaSlice{
+		solana.Meta(source).WRITE(),
+		solana.Meta(mint),
+		solana.Meta(destination).WRITE(),
+		solana.Meta(owner).SIGNER(),
+	}
+}
+
+// getMint fetches and decodes a mint account
+func (b *SolanaBlockchain) getMint(ctx context.Context, mint solana.PublicKey) (*solanaMint, error) {
+	accountInfo, err := b.client.GetAccountInfo(ctx, mint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mint account %s: %v", mint, err)
+	}
+	return parseSolanaMint(accountInfo.Value.Owner, accountInfo.GetBinary())
+}
+
+// accountExists reports whether an account is present on chain
+func (b *SolanaBlockchain) accountExists(ctx context.Context, account solana.PublicKey) (bool, error) {
+	_, err := b.client.GetAccountInfo(ctx, account)
+	if errors.Is(err, rpc.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// buildTokenTransferInstructions returns the instructions moving amount of mint from owner to recipient,
+// creating the recipient's associated token account first when it is missing
+func (b *SolanaBlockchain) buildTokenTransferInstructions(ctx context.Context,
+	owner solana.PublicKey,
+	recipient solana.PublicKey,
+	mintAddress solana.PublicKey,
+	amount uint64,
+) ([]solana.Instruction, error) {
+	mint, err := b.getMint(ctx, mintAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	senderTokenAccount, err := findSolanaAssociatedTokenAddress(owner, mintAddress, mint.ProgramID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sender token account: %v", err)
+	}
+
+	recipientTokenAccount, err := findSolanaAssociatedTokenAddress(recipient, mintAddress, mint.ProgramID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recipient token account: %v", err)
+	}
+
+	var instructions []solana.Instruction
+
+	exists, err := b.accountExists(ctx, recipientTokenAccount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recipient token account info: %v", err)
+	}
+	if !exists {
+		instructions = append(instructions, newSolanaCreateATAIdempotentInstruction(
+			owner, recipientTokenAccount, recipient, mintAddress, mint.ProgramID,
+		))
+	}
+
+	if mint.TransferFee == nil {
+		instructions = append(instructions, newSolanaTransferCheckedInstruction(
+			mint.ProgramID, senderTokenAccount, mintAddress, recipientTokenAccount, owner, amount, mint.Decimals,
+		))
+		return instructions, nil
+	}
+
+	epochInfo, err := b.client.GetEpochInfo(ctx, rpc.CommitmentFinalized)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get epoch info: %v", err)
+	}
+	fee := mint.TransferFee.FeeForEpoch(epochInfo.Epoch).Calculate(amount)
+
+	instructions = append(instructions, newSolanaTransferCheckedWithFeeInstruction(
+		senderTokenAccount, mintAddress, recipientTokenAccount, owner, amount, mint.Decimals, fee,
+	))
+	return instructions, nil
+}
+
+// computeBudgetInstructions sets the compute unit limit and a priority fee derived from
+// recent fees paid for the writable accounts of the transaction
+func (b *SolanaBlockchain) computeBudgetInstructions(ctx context.Context, writableAccounts solana.PublicKeySlice) []solana.Instruction {
+	return []solana.Instruction{
+		computebudget.NewSetComputeUnitLimitInstruction(solanaWithdrawComputeUnitLimit).Build(),
+		computebudget.NewSetComputeUnitPriceInstruction(b.priorityFee(ctx, writableAccounts)).Build(),
+	}
+}
+
+// priorityFee returns the median recent prioritization fee clamped to the configured bounds.
+// RPC failures fall back to the minimum so that a withdrawal is never blocked on fee estimation.
+func (b *SolanaBlockchain) priorityFee(ctx context.Context, writableAccounts solana.PublicKeySlice) uint64 {
+	recentFees, err := b.client.GetRecentPrioritizationFees(ctx, writableAccounts)
+	if err != nil {
+		fmt.Printf("Failed to get recent prioritization fees, using minimum: %v\n", err)
+		return solanaMinComputeUnitPrice
+	}
+	if len(recentFees) == 0 {
+		return solanaMinComputeUnitPrice
+	}
+
+	fees := make([]uint64, 0, len(recentFees))
+	for _, recentFee := range recentFees {
+		fees = append(fees, recentFee.PrioritizationFee)
+	}
+	sort.Slice(fees, func(i, j int) bool { return fees[i] < fees[j] })
+
+	fee := fees[len(fees)/2]
+	if fee < solanaMinComputeUnitPrice {
+		return solanaMinComputeUnitPrice
+	}
+	if fee > solanaMaxComputeUnitPrice {
+		return solanaMaxComputeUnitPrice
+	}
+	return fee
+}
@@ -0,0 +1,415 @@
+package blockchains
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/StripChain/strip-node/common"
+	"github.com/StripChain/strip-node/util"
+	"github.com/StripChain/strip-node/util/logger"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// Decimals of native SOL (lamports)
+const solanaNativeDecimals = 9
+
+// solanaParsedInstruction is an instruction of a jsonParsed getTransaction response.
+// Parsed is an object for programs the node can parse and absent otherwise.
+type solanaParsedInstruction struct {
+	Program   string          `json:"program"`
+	ProgramID string          `json:"programId"`
+	Parsed    json.RawMessage `json:"parsed"`
+}
+
+type solanaUITokenAmount struct {
+	Amount   string `json:"amount"`
+	Decimals uint8  `json:"decimals"`
+}
+
+type solanaParsedInstructionInfo struct {
+	Type string `json:"type"`
+	Info struct {
+		Source      string               `json:"source"`
+		Destination string               `json:"destination"`
+		Mint        string               `json:"mint"`
+		Lamports    uint64               `json:"lamports"`
+		Amount      string               `json:"amount"`
+		TokenAmount *solanaUITokenAmount `json:"tokenAmount"`
+	} `json:"info"`
+}
+
+type solanaTokenBalance struct {
+	AccountIndex  int                 `json:"accountIndex"`
+	Mint          string              `json:"mint"`
+	Owner         string              `json:"owner"`
+	ProgramID     string              `json:"programId"`
+	UITokenAmount solanaUITokenAmount `json:"uiTokenAmount"`
+}
+
+type solanaParsedTransaction struct {
+	Meta *struct {
+		Err               interface{} `json:"err"`
+		InnerInstructions []struct {
+			Index        int                       `json:"index"`
+			Instructions []solanaParsedInstruction `json:"instructions"`
+		} `json:"innerInstructions"`
+		PreTokenBalances  []solanaTokenBalance `json:"preTokenBalances"`
+		PostTokenBalances []solanaTokenBalance `json:"postTokenBalances"`
+	} `json:"meta"`
+	Transaction struct {
+		Message struct {
+			AccountKeys []struct {
+				Pubkey string `json:"pubkey"`
+			} `json:"accountKeys"`
+			Instructions []solanaParsedInstruction `json:"instructions"`
+		} `json:"message"`
+	} `json:"transaction"`
+}
+
+// solanaTokenAccount is what the token balances of a transaction tell about a token account
+type solanaTokenAccount struct {
+	Mint     string
+	Owner    string
+	Decimals uint8
+	Pre      *big.Int
+	Post     *big.Int
+}
+
+func (a *solanaTokenAccount) Delta() *big.Int {
+	return new(big.Int).Sub(a.Post, a.Pre)
+}
+
+// getParsedTransaction fetches a transaction with jsonParsed encoding, returning nil if the node does not know it yet
+func (b *SolanaBlockchain) getParsedTransaction(ctx context.Context, txHash string) (*solanaParsedTransaction, error) {
+	signature, err := solana.SignatureFromBase58(txHash)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transaction hash: %v", err)
+	}
+
+	var tx *solanaParsedTransaction
+	err = b.client.RPCCallForInto(ctx, &tx, "getTransaction", []interface{}{
+		signature.String(),
+		rpc.M{
+			"encoding":                       solana.EncodingJSONParsed,
+			"commitment":                     rpc.CommitmentConfirmed,
+			"maxSupportedTransactionVersion": rpc.MaxSupportedTransactionVersion0,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+func (b *SolanaBlockchain) GetTransfers(txnHash string, address *string) ([]common.Transfer, error) {
+	logger.Sugar().Infow("getting Solana transfers", "chainId", b.network.networkID, "txnHash", txnHash)
+	ctx := context.Background()
+
+	tx, err := b.getParsedTransaction(ctx, txnHash)
+	if err != nil {
+		logger.Sugar().Errorw("error getting Solana transaction", "txnHash", txnHash, "error", err)
+		return nil, err
+	}
+
+	// The transaction may not be visible at the confirmed commitment yet
+	if tx == nil {
+		ticker := time.NewTicker(1 * time.Second)
+		timeout := time.After(10 * time.Second)
+		defer ticker.Stop()
+	request:
+		for {
+			select {
+			case <-ticker.C:
+				tx, err = b.getParsedTransaction(ctx, txnHash)
+				if err != nil {
+					logger.Sugar().Errorw("error getting Solana transaction", "txnHash", txnHash, "error", err)
+					return nil, err
+				}
+				if tx != nil {
+					break request
+				}
+			case <-timeout:
+				logger.Sugar().Warnw("timeout waiting for Solana transaction", "txnHash", txnHash)
+				break request
+			}
+		}
+	}
+
+	if tx == nil {
+		logger.Sugar().Warnw("Solana transaction not found", "txnHash", txnHash)
+		return []common.Transfer{}, nil
+	}
+
+	transfers, err := b.parseTransfers(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Sugar().Infow("extracted Solana transfers", "txnHash", txnHash, "count", len(transfers))
+	if len(transfers) == 0 {
+		logger.Sugar().Warnw("no transfers found in Solana transaction, the transaction type may be unsupported", "txnHash", txnHash)
+	}
+
+	return transfers, nil
+}
+
+// parseTransfers extracts SOL and token transfers from a jsonParsed transaction.
+// Transfers are read from top-level and inner instructions in execution order. Token
+// balances give the owners and mints of token accounts, the amount actually credited
+// when the mint withholds a transfer fee, and the transfers themselves when the
+// moving instructions could not be parsed.
+func (b *SolanaBlockchain) parseTransfers(tx *solanaParsedTransaction) ([]common.Transfer, error) {
+	if tx.Meta == nil {
+		return nil, fmt.Errorf("transaction has no metadata")
+	}
+	if tx.Meta.Err != nil {
+		logger.Sugar().Warnw("Solana transaction failed on chain", "error", tx.Meta.Err)
+		return []common.Transfer{}, nil
+	}
+
+	tokenAccounts, err := solanaTokenAccounts(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Execution order: each top-level instruction followed by the instructions it invoked
+	inner := make(map[int][]solanaParsedInstruction)
+	for _, innerInstructions := range tx.Meta.InnerInstructions {
+		inner[innerInstructions.Index] = innerInstructions.Instructions
+	}
+	var instructions []solanaParsedInstruction
+	for i, instruction := range tx.Transaction.Message.Instructions {
+		instructions = append(instructions, instruction)
+		instructions = append(instructions, inner[i]...)
+	}
+
+	transfers := []common.Transfer{}
+	// Number of token transfers crediting each token account
+	credits := make(map[string]int)
+	var creditedAccounts []string
+
+	for _, instruction := range instructions {
+		if len(instruction.Parsed) == 0 || instruction.Parsed[0] != '{' {
+			continue
+		}
+		var parsed solanaParsedInstructionInfo
+		if err := json.Unmarshal(instruction.Parsed, &parsed); err != nil {
+			return nil, fmt.Errorf("failed to parse %s instruction: %v", instruction.Program, err)
+		}
+		info := parsed.Info
+
+		switch {
+		case instruction.ProgramID == solana.SystemProgramID.String():
+			if parsed.Type != "transfer" && parsed.Type != "transferWithSeed" {
+				continue
+			}
+			num := new(big.Int).SetUint64(info.Lamports)
+			formattedAmount, err := util.FormatUnits(num, solanaNativeDecimals)
+			if err != nil {
+				return nil, err
+			}
+			transfers = append(transfers, common.Transfer{
+				From:         info.Source,
+				To:           info.Destination,
+				Amount:       formattedAmount,
+				Token:        b.TokenSymbol(),
+				IsNative:     true,
+				TokenAddress: util.ZERO_ADDRESS,
+				ScaledAmount: num.String(),
+			})
+
+		case instruction.ProgramID == solana.TokenProgramID.String() ||
+			instruction.ProgramID == solana.Token2022ProgramID.String():
+			if parsed.Type != "transfer" && parsed.Type != "transferChecked" && parsed.Type != "transferCheckedWithFee" {
+				continue
+			}
+
+			amount := info.Amount
+			mint := info.Mint
+			var decimals *uint8
+			if info.TokenAmount != nil {
+				amount = info.TokenAmount.Amount
+				decimals = &info.TokenAmount.Decimals
+			}
+
+			// The unchecked transfer names neither the mint nor its decimals
+			from, to := info.Source, info.Destination
+			if source, ok := tokenAccounts[info.Source]; ok {
+				from = source.Owner
+				if mint == "" {
+					mint = source.Mint
+				}
+				if decimals == nil {
+					decimals = &source.Decimals
+				}
+			}
+			if destination, ok := tokenAccounts[info.Destination]; ok {
+				to = destination.Owner
+			}
+			if mint == "" || decimals == nil {
+				return nil, fmt.Errorf("unknown mint for token account %s", info.Source)
+			}
+
+			num, ok := new(big.Int).SetString(amount, 10)
+			if !ok {
+				return nil, fmt.Errorf("invalid token amount: %s", amount)
+			}
+			formattedAmount, err := util.FormatUnits(num, int(*decimals))
+			if err != nil {
+				return nil, err
+			}
+
+			transfers = append(transfers, common.Transfer{
+				From:         from,
+				To:           to,
+				Amount:       formattedAmount,
+				Token:        mint,
+				IsNative:     false,
+				TokenAddress: mint,
+				ScaledAmount: num.String(),
+			})
+			credits[info.Destination]++
+			creditedAccounts = append(creditedAccounts, info.Destination)
+		}
+	}
+
+	// Token accounts credited by a single transfer report the received amount,
+	// which is lower than the sent amount for mints with a transfer fee
+	tokenTransferIndex := 0
+	for i := range transfers {
+		if transfers[i].IsNative {
+			continue
+		}
+		destination := creditedAccounts[tokenTransferIndex]
+		tokenTransferIndex++
+
+		account, ok := tokenAccounts[destination]
+		if !ok || credits[destination] != 1 {
+			continue
+		}
+		delta := account.Delta()
+		sent, _ := new(big.Int).SetString(transfers[i].ScaledAmount, 10)
+		if delta.Sign() <= 0 || delta.Cmp(sent) >= 0 {
+			continue
+		}
+		formattedAmount, err := util.FormatUnits(delta, int(account.Decimals))
+		if err != nil {
+			return nil, err
+		}
+		transfers[i].Amount = formattedAmount
+		transfers[i].ScaledAmount = delta.String()
+	}
+
+	if len(creditedAccounts) == 0 {
+		balanceTransfers, err := solanaTransfersFromBalances(tx, tokenAccounts)
+		if err != nil {
+			return nil, err
+		}
+		transfers = append(transfers, balanceTransfers...)
+	}
+
+	return transfers, nil
+}
+
+// solanaTokenAccounts indexes the pre and post token balances of a transaction by token account address
+func solanaTokenAccounts(tx *solanaParsedTransaction) (map[string]*solanaTokenAccount, error) {
+	accountKeys := tx.Transaction.Message.AccountKeys
+	tokenAccounts := make(map[string]*solanaTokenAccount)
+
+	for i, balances := range [][]solanaTokenBalance{tx.Meta.PreTokenBalances, tx.Meta.PostTokenBalances} {
+		for _, balance := range balances {
+			if balance.AccountIndex < 0 || balance.AccountIndex >= len(accountKeys) {
+				return nil, fmt.Errorf("token balance account index out of range: %d", balance.AccountIndex)
+			}
+			amount, ok := new(big.Int).SetString(balance.UITokenAmount.Amount, 10)
+			if !ok {
+				return nil, fmt.Errorf("invalid token balance: %s", balance.UITokenAmount.Amount)
+			}
+
+			address := accountKeys[balance.AccountIndex].Pubkey
+			account, ok := tokenAccounts[address]
+			if !ok {
+				account = &solanaTokenAccount{
+					Mint:     balance.Mint,
+					Owner:    balance.Owner,
+					Decimals: balance.UITokenAmount.Decimals,
+					Pre:      new(big.Int),
+					Post:     new(big.Int),
+				}
+				tokenAccounts[address] = account
+			}
+			if i == 0 {
+				account.Pre = amount
+			} else {
+				account.Post = amount
+			}
+		}
+	}
+
+	return tokenAccounts, nil
+}
+
+// solanaTransfersFromBalances derives token transfers from balance changes when no transfer
+// instruction could be parsed. For each mint with exactly one debited owner, every credited
+// owner is reported as receiving its balance increase from that owner.
+func solanaTransfersFromBalances(tx *solanaParsedTransaction, tokenAccounts map[string]*solanaTokenAccount) ([]common.Transfer, error) {
+	type mintChanges struct {
+		debited  []*solanaTokenAccount
+		credited []*solanaTokenAccount
+	}
+	changes := make(map[string]*mintChanges)
+	var mints []string
+
+	// Walk accounts in transaction order so that the result is deterministic
+	for _, key := range tx.Transaction.Message.AccountKeys {
+		account, ok := tokenAccounts[key.Pubkey]
+		if !ok {
+			continue
+		}
+		delta := account.Delta()
+		if delta.Sign() == 0 {
+			continue
+		}
+		change, ok := changes[account.Mint]
+		if !ok {
+			change = &mintChanges{}
+			changes[account.Mint] = change
+			mints = append(mints, account.Mint)
+		}
+		if delta.Sign() < 0 {
+			change.debited = append(change.debited, account)
+		} else {
+			change.credited = append(change.credited, account)
+		}
+	}
+
+	transfers := []common.Transfer{}
+	for _, mint := range mints {
+		change := changes[mint]
+		if len(change.debited) != 1 {
+			continue
+		}
+		for _, account := range change.credited {
+			delta := account.Delta()
+			formattedAmount, err := util.FormatUnits(delta, int(account.Decimals))
+			if err != nil {
+				return nil, err
+			}
+			transfers = append(transfers, common.Transfer{
+				From:         change.debited[0].Owner,
+				To:           account.Owner,
+				Amount:       formattedAmount,
+				Token:        mint,
+				IsNative:     false,
+				TokenAddress: mint,
+				ScaledAmount: delta.String(),
+			})
+		}
+	}
+
+	return transfers, nil
+}
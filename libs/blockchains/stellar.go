@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -364,7 +365,7 @@ func (b *StellarBlockchain) ExtractDestinationAddress(serializedTxn string) (str
 	destAddress := ""
 	err := xdr.SafeUnmarshalBase64(serializedTxn, &txEnv)
 	if err != nil {
-		return "", "", fmt.Errorf("error parsing Stellar transaction", err)
+		return "", "", fmt.Errorf("error parsing Stellar transaction: %v", err)
 	}
 
 	// Get the first operation's destination
@@ -375,3 +376,37 @@ func (b *StellarBlockchain) ExtractDestinationAddress(serializedTxn string) (str
 	}
 	return destAddress, "", nil
 }
+
+func (b *StellarBlockchain) ExtractTransferAmount(serializedTxn string) (string, error) {
+	var txEnv xdr.TransactionEnvelope
+	if err := xdr.SafeUnmarshalBase64(serializedTxn, &txEnv); err != nil {
+		return "", fmt.Errorf("error parsing Stellar transaction: %v", err)
+	}
+
+	// Amounts are in stroops, the destination is paid by the first operation
+	if len(txEnv.Operations()) > 0 {
+		if paymentOp, ok := txEnv.Operations()[0].Body.GetPaymentOp(); ok {
+			return strconv.FormatInt(int64(paymentOp.Amount), 10), nil
+		}
+	}
+	return "", fmt.Errorf("no payment in Stellar transaction")
+}
+
+// ComputeDataToSign returns the hash of an XDR transaction envelope on the network of the
+// blockchain, which its source account signs
+func (b *StellarBlockchain) ComputeDataToSign(serializedTxn string) (string, error) {
+	var envelope xdr.TransactionEnvelope
+	if err := xdr.SafeUnmarshalBase64(serializedTxn, &envelope); err != nil {
+		return "", fmt.Errorf("failed to decode transaction XDR: %w", err)
+	}
+
+	passphrase := network.PublicNetworkPassphrase
+	if b.network.networkType == Testnet {
+		passphrase = network.TestNetworkPassphrase
+	}
+	hash, err := network.HashTransactionInEnvelope(envelope, passphrase)
+	if err != nil {
+		return "", fmt.Errorf("error getting transaction hash: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(hash[:]), nil
+}
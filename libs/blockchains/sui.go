@@ -14,6 +14,8 @@ import (
 	"github.com/coming-chat/go-sui/v2/lib"
 	"github.com/coming-chat/go-sui/v2/sui_types"
 	"github.com/coming-chat/go-sui/v2/types"
+	"github.com/fardream/go-bcs/bcs"
+	"golang.org/x/crypto/blake2b"
 )
 
 const (
@@ -371,10 +373,10 @@ func (b *SuiBlockchain) ExtractDestinationAddress(serializedTxn string) (string,
 	var tx sui_types.TransactionData
 	txBytes, err := base64.StdEncoding.DecodeString(serializedTxn)
 	if err != nil {
-		return "", "", fmt.Errorf("error decoding Sui transaction", err)
+		return "", "", fmt.Errorf("error decoding Sui transaction: %v", err)
 	}
 	if err := json.Unmarshal(txBytes, &tx); err != nil {
-		return "", "", fmt.Errorf("error parsing Sui transaction", err)
+		return "", "", fmt.Errorf("error parsing Sui transaction: %v", err)
 	}
 	if len(tx.V1.Kind.ProgrammableTransaction.Inputs) < 1 {
 		return "", "", fmt.Errorf("wrong format sui transaction")
@@ -382,3 +384,23 @@ func (b *SuiBlockchain) ExtractDestinationAddress(serializedTxn string) (string,
 	destAddress := string(*tx.V1.Kind.ProgrammableTransaction.Inputs[0].Pure)
 	return destAddress, "", nil
 }
+
+// ComputeDataToSign returns the digest a Sui account signs for BCS transaction data: the
+// blake2b hash of the data behind its transaction intent
+func (b *SuiBlockchain) ComputeDataToSign(serializedTxn string) (string, error) {
+	txBytes, err := base64.StdEncoding.DecodeString(serializedTxn)
+	if err != nil {
+		return "", fmt.Errorf("error decoding Sui transaction: %v", err)
+	}
+	var tx sui_types.TransactionData
+	if err := bcs.UnmarshalAll(txBytes, &tx); err != nil {
+		return "", fmt.Errorf("error deserializing Sui transaction: %v", err)
+	}
+
+	message, err := bcs.Marshal(sui_types.NewIntentMessage(sui_types.DefaultIntent(), tx))
+	if err != nil {
+		return "", fmt.Errorf("error serializing Sui intent message: %v", err)
+	}
+	digest := blake2b.Sum256(message)
+	return base64.StdEncoding.EncodeToString(digest[:]), nil
+}
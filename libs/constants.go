@@ -5,4 +5,5 @@ import "time"
 const (
 	SLASHING_CHECK_INTERVAL = 5 * time.Minute
 	HEARTBEAT_TIMEOUT       = 2 * time.Hour
+	RESHARE_CHECK_INTERVAL  = 10 * time.Minute
 )
@@ -66,6 +66,7 @@ type OperationSchema struct {
 	SolverMetadata   string `pg:",type:jsonb"`
 	SolverDataToSign string
 	SolverOutput     string    `pg:",type:jsonb"`
+	DerivationPath   string    `pg:",use_zero"`
 	CreatedAt        time.Time `pg:",notnull,default:CURRENT_TIMESTAMP"`
 }
 
@@ -90,6 +91,7 @@ type WalletSchema struct {
 	RippleEDDSAPublicKey     string                   `json:"rippleEddsaPublicKey"`
 	CardanoPublicKey         string                   `json:"cardanoPublicKey"`
 	Signers                  []string                 `json:"signers" pg:",type:jsonb"`
+	Threshold                int                      `json:"threshold" pg:",use_zero"`
 }
 
 type LockSchema struct {
@@ -106,6 +108,33 @@ type HeartbeatSchema struct {
 	UpdatedAt time.Time `pg:"updated_at,notnull,default:CURRENT_TIMESTAMP"`
 }
 
+type BlameReportSchema struct {
+	tableName struct{}  `pg:"blame_reports"` //lint:ignore U1000 ok
+	Id        int64     `json:"id"`
+	PublicKey string    `json:"publicKey" pg:"publickey,notnull"`
+	SessionID string    `json:"sessionId" pg:",notnull"`
+	Task      string    `json:"task" pg:",notnull"`
+	Round     int       `json:"round" pg:",use_zero"`
+	Reason    string    `json:"reason" pg:",notnull"`
+	Reporter  string    `json:"reporter" pg:",notnull"`
+	CreatedAt time.Time `json:"createdAt" pg:",notnull,default:CURRENT_TIMESTAMP"`
+}
+
+type SigningReceiptSchema struct {
+	tableName      struct{}          `pg:"signing_receipts"` //lint:ignore U1000 ok
+	Id             int64             `json:"id"`
+	SessionID      string            `json:"sessionId" pg:",notnull"`
+	IntentID       string            `json:"intentId" pg:",notnull"`
+	OperationIndex int               `json:"operationIndex" pg:",use_zero"`
+	MessageHash    string            `json:"messageHash" pg:",notnull"`
+	Signers        []string          `json:"signers" pg:",type:jsonb"`
+	Signature      string            `json:"signature" pg:",notnull"`
+	GroupPublicKey string            `json:"groupPublicKey" pg:",notnull"`
+	CoSignatures   map[string]string `json:"coSignatures" pg:",type:jsonb"`
+	StartedAt      time.Time         `json:"startedAt"`
+	CreatedAt      time.Time         `json:"createdAt" pg:",notnull,default:CURRENT_TIMESTAMP"`
+}
+
 // Add these constants for pool configuration
 const (
 	minPoolSize     = 2
@@ -278,6 +307,7 @@ func AddIntent(
 			Solver:           operation.Solver,
 			SolverMetadata:   operation.SolverMetadata,
 			SolverDataToSign: operation.SolverDataToSign,
+			DerivationPath:   operation.DerivationPath,
 		}
 
 		if operation.SerializedTxn != nil {
@@ -326,6 +356,7 @@ func GetIntent(id uuid.UUID) (*libs.Intent, error) {
 			SolverMetadata:   operationSchema.SolverMetadata,
 			SolverDataToSign: operationSchema.SolverDataToSign,
 			SolverOutput:     operationSchema.SolverOutput,
+			DerivationPath:   operationSchema.DerivationPath,
 			CreatedAt:        operationSchema.CreatedAt,
 		}
 	}
@@ -380,6 +411,7 @@ func GetOperation(intentId uuid.UUID, operationIndex int64) (*libs.Operation, er
 			SolverMetadata:   operationSchema.SolverMetadata,
 			SolverDataToSign: operationSchema.SolverDataToSign,
 			SolverOutput:     operationSchema.SolverOutput,
+			DerivationPath:   operationSchema.DerivationPath,
 		}
 	}
 
@@ -422,6 +454,7 @@ func getIntents(intentSchemas *([]IntentSchema)) ([]*libs.Intent, error) {
 				SolverMetadata:   operationSchema.SolverMetadata,
 				SolverDataToSign: operationSchema.SolverDataToSign,
 				SolverOutput:     operationSchema.SolverOutput,
+				DerivationPath:   operationSchema.DerivationPath,
 			}
 		}
 
@@ -681,6 +714,32 @@ func GetWallet(identity string, blockchainId blockchains.BlockchainID) (*WalletS
 	return &walletSchema, nil
 }
 
+func GetWallets() ([]WalletSchema, error) {
+	var wallets []WalletSchema
+	err := GetDB().Model(&wallets).Order("id ASC").Select()
+	if err != nil {
+		return nil, err
+	}
+
+	return wallets, nil
+}
+
+// UpdateWalletSigners records the committee holding the key shares of a wallet after resharing
+var UpdateWalletSigners = func(id int64, signers []string, threshold int) error {
+	walletSchema := &WalletSchema{
+		Id:        id,
+		Signers:   signers,
+		Threshold: threshold,
+	}
+
+	_, err := GetDB().Model(walletSchema).Column("signers", "threshold").WherePK().Update()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 var AddWallet = func(wallet *WalletSchema) (int64, error) {
 	_, err := GetDB().Model(wallet).Insert()
 	if err != nil {
@@ -730,7 +789,7 @@ func GetHeartbeat(publicKey string) (HeartbeatSchema, error) {
 	return *heartbeat, nil
 }
 
-func GetHeartbeats() ([]HeartbeatSchema, error) {
+var GetHeartbeats = func() ([]HeartbeatSchema, error) {
 	var heartbeats []HeartbeatSchema
 	err := GetDB().Model(&heartbeats).Select()
 	if err != nil {
@@ -776,6 +835,29 @@ func GetActiveSigners() ([]HeartbeatSchema, error) {
 	return heartbeats, nil
 }
 
+func AddBlameReport(report *BlameReportSchema) error {
+	_, err := GetDB().Model(report).Insert()
+	return err
+}
+
+func AddSigningReceipt(receipt *SigningReceiptSchema) error {
+	_, err := GetDB().Model(receipt).Insert()
+	return err
+}
+
+// GetBlameReportsSince returns the blame reports of all signers since the given time
+var GetBlameReportsSince = func(since time.Time) ([]BlameReportSchema, error) {
+	var reports []BlameReportSchema
+	err := GetDB().Model(&reports).
+		Where("created_at > ?", since).
+		Order("created_at ASC").
+		Select()
+	if err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
 func VerifyIdentityLockSchema(intent *libs.Intent, operation *libs.Operation) (*LockSchema, error) {
 	lockSchema, err := GetLock(intent.Identity, intent.BlockchainID)
 	if err != nil {
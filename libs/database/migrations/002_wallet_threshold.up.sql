@@ -0,0 +1,2 @@
+-- TSS threshold of the wallet's committee, 0 for wallets created with the default policy
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS threshold INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,12 @@
+-- BlameReportSchema: signers blamed for aborting a TSS round
+CREATE TABLE IF NOT EXISTS blame_reports (
+    id BIGSERIAL PRIMARY KEY,
+    publickey TEXT NOT NULL,
+    session_id TEXT NOT NULL,
+    task TEXT NOT NULL,
+    round INTEGER NOT NULL,
+    reason TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_blame_reports_publickey_created_at ON blame_reports(publickey, created_at);
@@ -0,0 +1,2 @@
+-- Derivation path of the child key that signs the operation, empty for the wallet itself
+ALTER TABLE operations ADD COLUMN IF NOT EXISTS derivation_path TEXT NOT NULL DEFAULT '';
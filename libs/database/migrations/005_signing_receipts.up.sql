@@ -0,0 +1,15 @@
+-- SigningReceiptSchema: co-signed receipts of completed TSS signings
+CREATE TABLE IF NOT EXISTS signing_receipts (
+    id BIGSERIAL PRIMARY KEY,
+    session_id TEXT NOT NULL,
+    intent_id TEXT NOT NULL,
+    operation_index INTEGER NOT NULL,
+    message_hash TEXT NOT NULL,
+    signers JSONB NOT NULL,
+    signature TEXT NOT NULL,
+    group_public_key TEXT NOT NULL,
+    co_signatures JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_signing_receipts_intent_id ON signing_receipts(intent_id, operation_index);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_blame_reports_session_id;
+ALTER TABLE blame_reports DROP COLUMN IF EXISTS reporter;
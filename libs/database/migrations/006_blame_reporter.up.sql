@@ -0,0 +1,4 @@
+-- Signer that reported the blame, a culprit counts once several signers report it
+ALTER TABLE blame_reports ADD COLUMN IF NOT EXISTS reporter TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_blame_reports_session_id ON blame_reports(session_id);
@@ -0,0 +1 @@
+ALTER TABLE signing_receipts DROP COLUMN IF EXISTS started_at;
@@ -0,0 +1,2 @@
+-- Time the signing started, co-signed with the receipt
+ALTER TABLE signing_receipts ADD COLUMN IF NOT EXISTS started_at TIMESTAMPTZ;
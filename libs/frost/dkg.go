@@ -0,0 +1,205 @@
+package frost
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"filippo.io/edwards25519"
+)
+
+// The keys are generated with the Pedersen DKG of the FROST paper: every participant deals
+// a random polynomial of degree threshold, broadcasts commitments to its coefficients with
+// a proof of knowledge of the constant term, and sends each other participant its
+// evaluation at their identifier. A participant's secret share is the sum of the
+// evaluations it received, the group key the sum of the committed constant terms.
+
+// DKGSecret is the polynomial a participant deals. It must be discarded once the shares
+// are sent.
+type DKGSecret struct {
+	identifier   *edwards25519.Scalar
+	coefficients []*edwards25519.Scalar
+}
+
+// DKGPackage is the first-round broadcast of a participant
+type DKGPackage struct {
+	Identifier *edwards25519.Scalar
+	// Commitments are the commitments to the coefficients, the first being the
+	// participant's contribution to the group key
+	Commitments []*edwards25519.Point
+	// ProofR and ProofZ prove knowledge of the constant term
+	ProofR *edwards25519.Point
+	ProofZ *edwards25519.Scalar
+}
+
+type dkgPackageJSON struct {
+	Identifier  []byte   `json:"identifier"`
+	Commitments [][]byte `json:"commitments"`
+	ProofR      []byte   `json:"proofR"`
+	ProofZ      []byte   `json:"proofZ"`
+}
+
+func (p DKGPackage) MarshalJSON() ([]byte, error) {
+	commitments := make([][]byte, len(p.Commitments))
+	for i, c := range p.Commitments {
+		commitments[i] = c.Bytes()
+	}
+	return json.Marshal(dkgPackageJSON{
+		Identifier:  p.Identifier.Bytes(),
+		Commitments: commitments,
+		ProofR:      p.ProofR.Bytes(),
+		ProofZ:      p.ProofZ.Bytes(),
+	})
+}
+
+func (p *DKGPackage) UnmarshalJSON(data []byte) error {
+	var aux dkgPackageJSON
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	identifier, err := ParseScalar(aux.Identifier)
+	if err != nil {
+		return err
+	}
+	commitments := make([]*edwards25519.Point, len(aux.Commitments))
+	for i, c := range aux.Commitments {
+		if commitments[i], err = ParseElement(c); err != nil {
+			return err
+		}
+	}
+	proofR, err := ParseElement(aux.ProofR)
+	if err != nil {
+		return err
+	}
+	proofZ, err := ParseScalar(aux.ProofZ)
+	if err != nil {
+		return err
+	}
+	*p = DKGPackage{Identifier: identifier, Commitments: commitments, ProofR: proofR, ProofZ: proofZ}
+	return nil
+}
+
+func randomScalar(random io.Reader) (*edwards25519.Scalar, error) {
+	b := make([]byte, 64)
+	if _, err := io.ReadFull(random, b); err != nil {
+		return nil, fmt.Errorf("failed to generate scalar: %w", err)
+	}
+	return edwards25519.NewScalar().SetUniformBytes(b)
+}
+
+// dkgChallenge binds the proof of knowledge of a participant to its identifier, its
+// contribution to the group key and the keygen it is made for
+func dkgChallenge(identifier *edwards25519.Scalar, constant *edwards25519.Point, r *edwards25519.Point, context []byte) *edwards25519.Scalar {
+	return hashToScalar([]byte(contextString+"dkg"), identifier.Bytes(), constant.Bytes(), r.Bytes(), context)
+}
+
+// DKGRoundOne deals the polynomial of a participant for a key any threshold+1
+// participants can sign with. context identifies the keygen, e.g. its session, so that
+// proofs cannot be replayed into another one.
+func DKGRoundOne(identifier *edwards25519.Scalar, threshold int, context []byte) (*DKGSecret, *DKGPackage, error) {
+	if threshold < 1 {
+		return nil, nil, fmt.Errorf("invalid threshold %d", threshold)
+	}
+	coefficients := make([]*edwards25519.Scalar, threshold+1)
+	commitments := make([]*edwards25519.Point, threshold+1)
+	for i := range coefficients {
+		coefficient, err := randomScalar(rand.Reader)
+		if err != nil {
+			return nil, nil, err
+		}
+		coefficients[i] = coefficient
+		commitments[i] = new(edwards25519.Point).ScalarBaseMult(coefficient)
+	}
+
+	k, err := randomScalar(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	r := new(edwards25519.Point).ScalarBaseMult(k)
+	c := dkgChallenge(identifier, commitments[0], r, context)
+	z := edwards25519.NewScalar().MultiplyAdd(coefficients[0], c, k)
+
+	return &DKGSecret{identifier: identifier, coefficients: coefficients},
+		&DKGPackage{Identifier: identifier, Commitments: commitments, ProofR: r, ProofZ: z}, nil
+}
+
+// Share returns the evaluation of the polynomial at the identifier of a participant, the
+// second-round message sent privately to it
+func (s *DKGSecret) Share(identifier *edwards25519.Scalar) *edwards25519.Scalar {
+	// Horner's method, from the highest coefficient down
+	value := edwards25519.NewScalar()
+	for i := len(s.coefficients) - 1; i >= 0; i-- {
+		value.MultiplyAdd(value, identifier, s.coefficients[i])
+	}
+	return value
+}
+
+// VerifyDKGPackage checks the first-round broadcast of a participant: the degree of its
+// polynomial and its proof of knowledge
+func VerifyDKGPackage(p *DKGPackage, threshold int, context []byte) error {
+	if len(p.Commitments) != threshold+1 {
+		return fmt.Errorf("got %d commitments for threshold %d", len(p.Commitments), threshold)
+	}
+	c := dkgChallenge(p.Identifier, p.Commitments[0], p.ProofR, context)
+	// R == z * G - c * C_0
+	expected := new(edwards25519.Point).VarTimeDoubleScalarBaseMult(edwards25519.NewScalar().Negate(c), p.Commitments[0], p.ProofZ)
+	if expected.Equal(p.ProofR) != 1 {
+		return errors.New("invalid proof of knowledge")
+	}
+	return nil
+}
+
+// evaluateCommitments returns the public key of the evaluation of a committed polynomial
+// at an identifier
+func evaluateCommitments(commitments []*edwards25519.Point, identifier *edwards25519.Scalar) *edwards25519.Point {
+	value := edwards25519.NewIdentityPoint()
+	for i := len(commitments) - 1; i >= 0; i-- {
+		value.ScalarMult(identifier, value)
+		value.Add(value, commitments[i])
+	}
+	return value
+}
+
+// VerifyDKGShare checks the share a participant received from the dealer of p
+func VerifyDKGShare(identifier *edwards25519.Scalar, share *edwards25519.Scalar, p *DKGPackage) error {
+	if new(edwards25519.Point).ScalarBaseMult(share).Equal(evaluateCommitments(p.Commitments, identifier)) != 1 {
+		return errors.New("share does not match the commitments of its dealer")
+	}
+	return nil
+}
+
+// DKGKey is the outcome of a keygen for one participant
+type DKGKey struct {
+	Secret   *edwards25519.Scalar
+	GroupKey *edwards25519.Point
+	// VerificationShares are the public keys of the secret shares of the participants,
+	// in the order of the identifiers passed to FinishDKG
+	VerificationShares []*edwards25519.Point
+}
+
+// FinishDKG combines the verified shares a participant received, its own included, and
+// the packages of every participant into its key
+func FinishDKG(shares []*edwards25519.Scalar, packages []*DKGPackage, identifiers []*edwards25519.Scalar) (*DKGKey, error) {
+	if len(shares) != len(packages) {
+		return nil, fmt.Errorf("got %d shares for %d participants", len(shares), len(packages))
+	}
+	secret := edwards25519.NewScalar()
+	for _, share := range shares {
+		secret.Add(secret, share)
+	}
+	groupKey := edwards25519.NewIdentityPoint()
+	for _, p := range packages {
+		groupKey.Add(groupKey, p.Commitments[0])
+	}
+
+	verificationShares := make([]*edwards25519.Point, len(identifiers))
+	for i, identifier := range identifiers {
+		verificationShares[i] = edwards25519.NewIdentityPoint()
+		for _, p := range packages {
+			verificationShares[i].Add(verificationShares[i], evaluateCommitments(p.Commitments, identifier))
+		}
+	}
+	return &DKGKey{Secret: secret, GroupKey: groupKey, VerificationShares: verificationShares}, nil
+}
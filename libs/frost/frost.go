@@ -0,0 +1,432 @@
+// Package frost implements FROST threshold Schnorr signatures over Ed25519, the
+// FROST(Ed25519, SHA-512) ciphersuite of RFC 9591, and a Pedersen distributed key
+// generation with proofs of knowledge for its keys.
+//
+// Signing takes two rounds. In the first, each participant commits to a pair of nonces;
+// it does not depend on the message, so it can run as soon as a signing is announced. In
+// the second, each participant sends its signature share, and the shares aggregate into an
+// ordinary Ed25519 signature under the group key.
+package frost
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"slices"
+
+	"filippo.io/edwards25519"
+)
+
+// contextString separates the hashes of the ciphersuite from any other use of SHA-512
+const contextString = "FROST-ED25519-SHA512-v1"
+
+const (
+	// ScalarSize and ElementSize are the sizes of encoded scalars and points
+	ScalarSize  = 32
+	ElementSize = 32
+	// SignatureSize is the size of an Ed25519 signature, R || z
+	SignatureSize = ElementSize + ScalarSize
+)
+
+var (
+	ErrInvalidScalar  = errors.New("invalid scalar")
+	ErrInvalidElement = errors.New("invalid group element")
+)
+
+// order is L, the order of the prime-order subgroup of Ed25519
+var order, _ = new(big.Int).SetString("7237005577332262213973186563042994240857116359379907606001950938285454250989", 10)
+
+// hashToScalar reduces the SHA-512 hash of the inputs modulo the group order, reading the
+// digest as a little-endian integer
+func hashToScalar(inputs ...[]byte) *edwards25519.Scalar {
+	h := sha512.New()
+	for _, input := range inputs {
+		h.Write(input)
+	}
+	s, err := edwards25519.NewScalar().SetUniformBytes(h.Sum(nil))
+	if err != nil {
+		// A SHA-512 digest always has the 64 bytes SetUniformBytes needs
+		panic(err)
+	}
+	return s
+}
+
+func hash(inputs ...[]byte) []byte {
+	h := sha512.New()
+	for _, input := range inputs {
+		h.Write(input)
+	}
+	return h.Sum(nil)
+}
+
+// The hash functions of the ciphersuite, RFC 9591 section 6.1
+func h1(m []byte) *edwards25519.Scalar { return hashToScalar([]byte(contextString+"rho"), m) }
+func h2(m []byte) *edwards25519.Scalar { return hashToScalar(m) }
+func h3(m []byte) *edwards25519.Scalar { return hashToScalar([]byte(contextString+"nonce"), m) }
+func h4(m []byte) []byte               { return hash([]byte(contextString+"msg"), m) }
+func h5(m []byte) []byte               { return hash([]byte(contextString+"com"), m) }
+
+// ParseScalar decodes a canonical little-endian scalar
+func ParseScalar(b []byte) (*edwards25519.Scalar, error) {
+	s, err := edwards25519.NewScalar().SetCanonicalBytes(b)
+	if err != nil {
+		return nil, ErrInvalidScalar
+	}
+	return s, nil
+}
+
+// ParseElement decodes a point, rejecting non-canonical encodings, the identity and
+// points outside the prime-order subgroup
+func ParseElement(b []byte) (*edwards25519.Point, error) {
+	p, err := new(edwards25519.Point).SetBytes(b)
+	if err != nil || !bytes.Equal(p.Bytes(), b) {
+		return nil, ErrInvalidElement
+	}
+	if p.Equal(edwards25519.NewIdentityPoint()) == 1 || !inPrimeOrderSubgroup(p) {
+		return nil, ErrInvalidElement
+	}
+	return p, nil
+}
+
+// inPrimeOrderSubgroup reports whether [L]p is the identity, computed as [L-1]p + p since
+// L itself is zero as a scalar
+func inPrimeOrderSubgroup(p *edwards25519.Point) bool {
+	minusOne := edwards25519.NewScalar().Subtract(edwards25519.NewScalar(), scalarOne())
+	q := new(edwards25519.Point).ScalarMult(minusOne, p)
+	q.Add(q, p)
+	return q.Equal(edwards25519.NewIdentityPoint()) == 1
+}
+
+func scalarOne() *edwards25519.Scalar {
+	one := make([]byte, ScalarSize)
+	one[0] = 1
+	s, _ := edwards25519.NewScalar().SetCanonicalBytes(one)
+	return s
+}
+
+// ScalarFromInt returns n modulo the group order as a scalar
+func ScalarFromInt(n *big.Int) *edwards25519.Scalar {
+	reduced := new(big.Int).Mod(n, order).FillBytes(make([]byte, ScalarSize))
+	slices.Reverse(reduced)
+	s, err := edwards25519.NewScalar().SetCanonicalBytes(reduced)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// IntFromScalar returns the integer value of a scalar
+func IntFromScalar(s *edwards25519.Scalar) *big.Int {
+	b := s.Bytes()
+	slices.Reverse(b)
+	return new(big.Int).SetBytes(b)
+}
+
+// Identifier returns the identifier of a participant from its party key, which must not be
+// zero modulo the group order
+func Identifier(key *big.Int) (*edwards25519.Scalar, error) {
+	id := ScalarFromInt(key)
+	if id.Equal(edwards25519.NewScalar()) == 1 {
+		return nil, fmt.Errorf("party key %s is not a valid identifier", key)
+	}
+	return id, nil
+}
+
+// Nonces are the secret nonces of a participant for one signing. They must never be reused.
+type Nonces struct {
+	hiding  *edwards25519.Scalar
+	binding *edwards25519.Scalar
+}
+
+// Commitment is the public commitment of a participant to its nonces
+type Commitment struct {
+	Identifier *edwards25519.Scalar
+	Hiding     *edwards25519.Point
+	Binding    *edwards25519.Point
+}
+
+type commitmentJSON struct {
+	Identifier []byte `json:"identifier"`
+	Hiding     []byte `json:"hiding"`
+	Binding    []byte `json:"binding"`
+}
+
+func (c Commitment) MarshalJSON() ([]byte, error) {
+	return json.Marshal(commitmentJSON{
+		Identifier: c.Identifier.Bytes(),
+		Hiding:     c.Hiding.Bytes(),
+		Binding:    c.Binding.Bytes(),
+	})
+}
+
+func (c *Commitment) UnmarshalJSON(data []byte) error {
+	var aux commitmentJSON
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	identifier, err := ParseScalar(aux.Identifier)
+	if err != nil {
+		return err
+	}
+	hiding, err := ParseElement(aux.Hiding)
+	if err != nil {
+		return err
+	}
+	binding, err := ParseElement(aux.Binding)
+	if err != nil {
+		return err
+	}
+	*c = Commitment{Identifier: identifier, Hiding: hiding, Binding: binding}
+	return nil
+}
+
+// generateNonce derives a nonce from fresh randomness and the secret share, so that a
+// weak random source alone does not expose the share
+func generateNonce(secret *edwards25519.Scalar, random io.Reader) (*edwards25519.Scalar, error) {
+	randomBytes := make([]byte, 32)
+	if _, err := io.ReadFull(random, randomBytes); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return h3(append(randomBytes, secret.Bytes()...)), nil
+}
+
+// NewNonces draws the nonces of a participant for one signing
+func NewNonces(secret *edwards25519.Scalar, random io.Reader) (*Nonces, error) {
+	hiding, err := generateNonce(secret, random)
+	if err != nil {
+		return nil, err
+	}
+	binding, err := generateNonce(secret, random)
+	if err != nil {
+		return nil, err
+	}
+	return &Nonces{hiding: hiding, binding: binding}, nil
+}
+
+// Commit runs the first round of signing: it draws the nonces of a participant and
+// returns them with the commitment to send to the other participants
+func Commit(identifier *edwards25519.Scalar, secret *edwards25519.Scalar, random io.Reader) (*Nonces, *Commitment, error) {
+	nonces, err := NewNonces(secret, random)
+	if err != nil {
+		return nil, nil, err
+	}
+	return nonces, nonces.Commitment(identifier), nil
+}
+
+// Commitment returns the commitment of a participant to the nonces
+func (n *Nonces) Commitment(identifier *edwards25519.Scalar) *Commitment {
+	return &Commitment{
+		Identifier: identifier,
+		Hiding:     new(edwards25519.Point).ScalarBaseMult(n.hiding),
+		Binding:    new(edwards25519.Point).ScalarBaseMult(n.binding),
+	}
+}
+
+// Bytes encodes the nonces to be stored until a signing uses them
+func (n *Nonces) Bytes() []byte {
+	return append(n.hiding.Bytes(), n.binding.Bytes()...)
+}
+
+// ParseNonces decodes nonces encoded by Bytes
+func ParseNonces(b []byte) (*Nonces, error) {
+	if len(b) != 64 {
+		return nil, fmt.Errorf("invalid nonces length %d", len(b))
+	}
+	hiding, err := ParseScalar(b[:32])
+	if err != nil {
+		return nil, err
+	}
+	binding, err := ParseScalar(b[32:])
+	if err != nil {
+		return nil, err
+	}
+	return &Nonces{hiding: hiding, binding: binding}, nil
+}
+
+// sortCommitments returns the commitments ordered by identifier, checking that each
+// participant committed once
+func sortCommitments(commitments []Commitment) ([]Commitment, error) {
+	sorted := slices.Clone(commitments)
+	slices.SortFunc(sorted, func(a, b Commitment) int {
+		return IntFromScalar(a.Identifier).Cmp(IntFromScalar(b.Identifier))
+	})
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].Identifier.Equal(sorted[i-1].Identifier) == 1 {
+			return nil, errors.New("duplicate commitment of a participant")
+		}
+	}
+	return sorted, nil
+}
+
+func encodeGroupCommitmentList(commitments []Commitment) []byte {
+	encoded := make([]byte, 0, len(commitments)*(ScalarSize+2*ElementSize))
+	for _, c := range commitments {
+		encoded = append(encoded, c.Identifier.Bytes()...)
+		encoded = append(encoded, c.Hiding.Bytes()...)
+		encoded = append(encoded, c.Binding.Bytes()...)
+	}
+	return encoded
+}
+
+// bindingFactors returns the binding factor of every participant, in the order of the
+// sorted commitments
+func bindingFactors(groupKey *edwards25519.Point, commitments []Commitment, message []byte) []*edwards25519.Scalar {
+	prefix := append(append(groupKey.Bytes(), h4(message)...), h5(encodeGroupCommitmentList(commitments))...)
+	factors := make([]*edwards25519.Scalar, len(commitments))
+	for i, c := range commitments {
+		factors[i] = h1(append(slices.Clone(prefix), c.Identifier.Bytes()...))
+	}
+	return factors
+}
+
+func groupCommitment(commitments []Commitment, factors []*edwards25519.Scalar) *edwards25519.Point {
+	r := edwards25519.NewIdentityPoint()
+	for i, c := range commitments {
+		bound := new(edwards25519.Point).ScalarMult(factors[i], c.Binding)
+		r.Add(r, c.Hiding)
+		r.Add(r, bound)
+	}
+	return r
+}
+
+// challenge is the Ed25519 challenge of a signature, which makes the aggregate signature
+// verifiable as an ordinary Ed25519 signature
+func challenge(r *edwards25519.Point, groupKey *edwards25519.Point, message []byte) *edwards25519.Scalar {
+	return h2(append(append(r.Bytes(), groupKey.Bytes()...), message...))
+}
+
+// lagrangeCoefficient returns the Lagrange coefficient at zero of a participant among
+// the given identifiers
+func lagrangeCoefficient(identifiers []*edwards25519.Scalar, identifier *edwards25519.Scalar) (*edwards25519.Scalar, error) {
+	numerator := scalarOne()
+	denominator := scalarOne()
+	found := false
+	for _, x := range identifiers {
+		if x.Equal(identifier) == 1 {
+			found = true
+			continue
+		}
+		numerator.Multiply(numerator, x)
+		denominator.Multiply(denominator, edwards25519.NewScalar().Subtract(x, identifier))
+	}
+	if !found {
+		return nil, errors.New("participant is not among the signers")
+	}
+	if denominator.Equal(edwards25519.NewScalar()) == 1 {
+		return nil, errors.New("duplicate identifier")
+	}
+	return numerator.Multiply(numerator, edwards25519.NewScalar().Invert(denominator)), nil
+}
+
+// signingState is what every participant derives from the commitments of a signing
+type signingState struct {
+	commitments []Commitment
+	factors     []*edwards25519.Scalar
+	challenge   *edwards25519.Scalar
+	r           *edwards25519.Point
+}
+
+func newSigningState(groupKey *edwards25519.Point, message []byte, commitments []Commitment) (*signingState, error) {
+	sorted, err := sortCommitments(commitments)
+	if err != nil {
+		return nil, err
+	}
+	factors := bindingFactors(groupKey, sorted, message)
+	r := groupCommitment(sorted, factors)
+	return &signingState{
+		commitments: sorted,
+		factors:     factors,
+		challenge:   challenge(r, groupKey, message),
+		r:           r,
+	}, nil
+}
+
+// participant returns the index of a participant in the sorted commitments and its
+// Lagrange coefficient
+func (s *signingState) participant(identifier *edwards25519.Scalar) (int, *edwards25519.Scalar, error) {
+	identifiers := make([]*edwards25519.Scalar, len(s.commitments))
+	index := -1
+	for i, c := range s.commitments {
+		identifiers[i] = c.Identifier
+		if c.Identifier.Equal(identifier) == 1 {
+			index = i
+		}
+	}
+	if index < 0 {
+		return 0, nil, errors.New("participant did not commit")
+	}
+	lambda, err := lagrangeCoefficient(identifiers, identifier)
+	if err != nil {
+		return 0, nil, err
+	}
+	return index, lambda, nil
+}
+
+// Sign runs the second round of signing and returns the signature share of a participant.
+// commitments are those of every participant, including its own.
+func Sign(identifier *edwards25519.Scalar, secret *edwards25519.Scalar, groupKey *edwards25519.Point, nonces *Nonces, message []byte, commitments []Commitment) (*edwards25519.Scalar, error) {
+	state, err := newSigningState(groupKey, message, commitments)
+	if err != nil {
+		return nil, err
+	}
+	index, lambda, err := state.participant(identifier)
+	if err != nil {
+		return nil, err
+	}
+	own := state.commitments[index]
+	if own.Hiding.Equal(new(edwards25519.Point).ScalarBaseMult(nonces.hiding)) != 1 ||
+		own.Binding.Equal(new(edwards25519.Point).ScalarBaseMult(nonces.binding)) != 1 {
+		return nil, errors.New("commitment does not match the nonces")
+	}
+
+	// z = hiding + binding * rho + lambda * secret * c
+	z := edwards25519.NewScalar().Multiply(nonces.binding, state.factors[index])
+	z.Add(z, nonces.hiding)
+	lsc := edwards25519.NewScalar().Multiply(lambda, secret)
+	lsc.Multiply(lsc, state.challenge)
+	return z.Add(z, lsc), nil
+}
+
+// VerifyShare checks the signature share of a participant against its verification
+// share, the public key of its secret share
+func VerifyShare(identifier *edwards25519.Scalar, verificationShare *edwards25519.Point, groupKey *edwards25519.Point, message []byte, commitments []Commitment, share *edwards25519.Scalar) error {
+	state, err := newSigningState(groupKey, message, commitments)
+	if err != nil {
+		return err
+	}
+	index, lambda, err := state.participant(identifier)
+	if err != nil {
+		return err
+	}
+	c := state.commitments[index]
+
+	// z * G == hiding + binding * rho + (c * lambda) * PK_i
+	expected := new(edwards25519.Point).ScalarMult(state.factors[index], c.Binding)
+	expected.Add(expected, c.Hiding)
+	cl := edwards25519.NewScalar().Multiply(state.challenge, lambda)
+	expected.Add(expected, new(edwards25519.Point).ScalarMult(cl, verificationShare))
+	if new(edwards25519.Point).ScalarBaseMult(share).Equal(expected) != 1 {
+		return errors.New("invalid signature share")
+	}
+	return nil
+}
+
+// Aggregate combines the signature shares of every participant into an Ed25519 signature
+func Aggregate(groupKey *edwards25519.Point, message []byte, commitments []Commitment, shares []*edwards25519.Scalar) ([]byte, error) {
+	if len(shares) != len(commitments) {
+		return nil, fmt.Errorf("got %d signature shares for %d participants", len(shares), len(commitments))
+	}
+	state, err := newSigningState(groupKey, message, commitments)
+	if err != nil {
+		return nil, err
+	}
+	z := edwards25519.NewScalar()
+	for _, share := range shares {
+		z.Add(z, share)
+	}
+	return append(state.r.Bytes(), z.Bytes()...), nil
+}
@@ -0,0 +1,206 @@
+package frost
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"filippo.io/edwards25519"
+	"github.com/stretchr/testify/require"
+)
+
+// runDKG generates a key for n participants with identifiers 1..n
+func runDKG(t *testing.T, n int, threshold int) ([]*edwards25519.Scalar, []*DKGKey) {
+	context := []byte("test keygen")
+	identifiers := make([]*edwards25519.Scalar, n)
+	for i := range identifiers {
+		id, err := Identifier(big.NewInt(int64(i + 1)))
+		require.NoError(t, err)
+		identifiers[i] = id
+	}
+
+	secrets := make([]*DKGSecret, n)
+	packages := make([]*DKGPackage, n)
+	for i, id := range identifiers {
+		secret, p, err := DKGRoundOne(id, threshold, context)
+		require.NoError(t, err)
+		secrets[i] = secret
+
+		// Packages travel as JSON
+		data, err := json.Marshal(p)
+		require.NoError(t, err)
+		packages[i] = &DKGPackage{}
+		require.NoError(t, json.Unmarshal(data, packages[i]))
+		require.NoError(t, VerifyDKGPackage(packages[i], threshold, context))
+	}
+
+	keys := make([]*DKGKey, n)
+	for i, id := range identifiers {
+		shares := make([]*edwards25519.Scalar, n)
+		for j := range secrets {
+			shares[j] = secrets[j].Share(id)
+			require.NoError(t, VerifyDKGShare(id, shares[j], packages[j]))
+		}
+		key, err := FinishDKG(shares, packages, identifiers)
+		require.NoError(t, err)
+		keys[i] = key
+	}
+	return identifiers, keys
+}
+
+// sign runs both rounds of signing among the given participants
+func sign(t *testing.T, identifiers []*edwards25519.Scalar, keys []*DKGKey, signers []int, message []byte) []byte {
+	nonces := make([]*Nonces, len(signers))
+	commitments := make([]Commitment, len(signers))
+	for i, signer := range signers {
+		n, c, err := Commit(identifiers[signer], keys[signer].Secret, rand.Reader)
+		require.NoError(t, err)
+		require.Equal(t, c, n.Commitment(identifiers[signer]))
+
+		// Nonces are stored between the rounds
+		nonces[i], err = ParseNonces(n.Bytes())
+		require.NoError(t, err)
+		data, err := json.Marshal(c)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(data, &commitments[i]))
+	}
+
+	groupKey := keys[signers[0]].GroupKey
+	shares := make([]*edwards25519.Scalar, len(signers))
+	for i, signer := range signers {
+		share, err := Sign(identifiers[signer], keys[signer].Secret, groupKey, nonces[i], message, commitments)
+		require.NoError(t, err)
+		require.NoError(t, VerifyShare(identifiers[signer], keys[0].VerificationShares[signer], groupKey, message, commitments, share))
+		shares[i] = share
+	}
+
+	signature, err := Aggregate(groupKey, message, commitments, shares)
+	require.NoError(t, err)
+	require.Len(t, signature, SignatureSize)
+	return signature
+}
+
+func TestDKG(t *testing.T) {
+	identifiers, keys := runDKG(t, 4, 2)
+
+	for _, key := range keys[1:] {
+		require.Equal(t, keys[0].GroupKey.Bytes(), key.GroupKey.Bytes())
+	}
+	for i, key := range keys {
+		require.Equal(t, new(edwards25519.Point).ScalarBaseMult(key.Secret).Bytes(), keys[0].VerificationShares[i].Bytes())
+	}
+
+	// Any threshold+1 shares interpolate to the secret of the group key
+	secret := edwards25519.NewScalar()
+	subset := identifiers[1:]
+	for i, id := range subset {
+		lambda, err := lagrangeCoefficient(subset, id)
+		require.NoError(t, err)
+		secret.MultiplyAdd(lambda, keys[i+1].Secret, secret)
+	}
+	require.Equal(t, keys[0].GroupKey.Bytes(), new(edwards25519.Point).ScalarBaseMult(secret).Bytes())
+}
+
+func TestDKGRejectsInvalidPackages(t *testing.T) {
+	context := []byte("test keygen")
+	id, err := Identifier(big.NewInt(1))
+	require.NoError(t, err)
+	secret, p, err := DKGRoundOne(id, 1, context)
+	require.NoError(t, err)
+
+	// A proof made for another keygen is rejected
+	require.Error(t, VerifyDKGPackage(p, 1, []byte("other keygen")))
+	// So is a polynomial of the wrong degree
+	require.Error(t, VerifyDKGPackage(p, 2, context))
+
+	other, err := Identifier(big.NewInt(2))
+	require.NoError(t, err)
+	share := secret.Share(other)
+	require.NoError(t, VerifyDKGShare(other, share, p))
+	require.Error(t, VerifyDKGShare(other, share.Add(share, scalarOne()), p))
+}
+
+func TestSign(t *testing.T) {
+	identifiers, keys := runDKG(t, 4, 2)
+	groupKey := ed25519.PublicKey(keys[0].GroupKey.Bytes())
+	message := []byte("transaction")
+
+	// Any threshold+1 participants sign for the group key
+	for _, signers := range [][]int{{0, 1, 2}, {1, 2, 3}, {0, 1, 2, 3}} {
+		signature := sign(t, identifiers, keys, signers, message)
+		require.True(t, ed25519.Verify(groupKey, message, signature), "signers %v", signers)
+	}
+	require.False(t, ed25519.Verify(groupKey, []byte("other"), sign(t, identifiers, keys, []int{0, 1, 2}, message)))
+}
+
+func TestParseNonces(t *testing.T) {
+	nonces, _, err := Commit(scalarOne(), scalarOne(), rand.Reader)
+	require.NoError(t, err)
+	parsed, err := ParseNonces(nonces.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, nonces.Bytes(), parsed.Bytes())
+
+	_, err = ParseNonces(nonces.Bytes()[:32])
+	require.Error(t, err)
+	invalid := append(nonces.Bytes()[:32], make([]byte, 32)...)
+	for i := range invalid[32:] {
+		invalid[32+i] = 0xff
+	}
+	_, err = ParseNonces(invalid)
+	require.ErrorIs(t, err, ErrInvalidScalar)
+}
+
+func TestVerifyShareRejectsInvalidShares(t *testing.T) {
+	identifiers, keys := runDKG(t, 3, 1)
+	message := []byte("transaction")
+	groupKey := keys[0].GroupKey
+
+	nonces := make([]*Nonces, 2)
+	commitments := make([]Commitment, 2)
+	for i := range nonces {
+		n, c, err := Commit(identifiers[i], keys[i].Secret, rand.Reader)
+		require.NoError(t, err)
+		nonces[i], commitments[i] = n, *c
+	}
+
+	share, err := Sign(identifiers[0], keys[0].Secret, groupKey, nonces[0], message, commitments)
+	require.NoError(t, err)
+	require.NoError(t, VerifyShare(identifiers[0], keys[0].VerificationShares[0], groupKey, message, commitments, share))
+	// A share is only valid for the participant that made it and the message signed
+	require.Error(t, VerifyShare(identifiers[1], keys[0].VerificationShares[1], groupKey, message, commitments, share))
+	require.Error(t, VerifyShare(identifiers[0], keys[0].VerificationShares[0], groupKey, []byte("other"), commitments, share))
+
+	// Nonces only sign for their own commitment
+	_, err = Sign(identifiers[0], keys[0].Secret, groupKey, nonces[1], message, commitments)
+	require.Error(t, err)
+	// Each participant commits once
+	_, err = Sign(identifiers[0], keys[0].Secret, groupKey, nonces[0], message, []Commitment{commitments[0], commitments[0]})
+	require.Error(t, err)
+}
+
+func TestParseElement(t *testing.T) {
+	_, err := ParseElement(edwards25519.NewIdentityPoint().Bytes())
+	require.ErrorIs(t, err, ErrInvalidElement)
+
+	// A point of small order is outside the prime-order subgroup
+	smallOrder := make([]byte, ElementSize)
+	smallOrder[ElementSize-1] = 0x80
+	_, err = ParseElement(smallOrder)
+	require.ErrorIs(t, err, ErrInvalidElement)
+
+	p := new(edwards25519.Point).ScalarBaseMult(scalarOne())
+	parsed, err := ParseElement(p.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, 1, parsed.Equal(p))
+}
+
+func TestScalarFromInt(t *testing.T) {
+	n := big.NewInt(123456789)
+	require.Equal(t, n, IntFromScalar(ScalarFromInt(n)))
+	require.Equal(t, big.NewInt(5), IntFromScalar(ScalarFromInt(new(big.Int).Add(order, big.NewInt(5)))))
+
+	_, err := Identifier(order)
+	require.Error(t, err)
+}
@@ -0,0 +1,157 @@
+package keystore
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+const ageFileHeader = "age-encryption.org/v1"
+
+// ageWrapper wraps data keys as age files
+type ageWrapper struct {
+	name      string
+	recipient age.Recipient
+	identity  age.Identity
+}
+
+// NewAgeWrapper wraps data keys to the X25519 identity in an age identity file. The
+// identity file may itself be encrypted with a passphrase, in which case passphrase is
+// used to decrypt it once at startup.
+func NewAgeWrapper(identityFile string, passphrase string) (IKeyWrapper, error) {
+	contents, err := os.ReadFile(identityFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read age identity file: %w", err)
+	}
+
+	if isAgeFile(contents) {
+		if passphrase == "" {
+			return nil, errors.New("age identity file is encrypted but no passphrase was given")
+		}
+		contents, err = decryptIdentityFile(contents, passphrase)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	identities, err := age.ParseIdentities(bytes.NewReader(contents))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse age identity file: %w", err)
+	}
+	for _, identity := range identities {
+		if x25519, ok := identity.(*age.X25519Identity); ok {
+			return NewAgeWrapperFromIdentity(x25519), nil
+		}
+	}
+	return nil, errors.New("age identity file has no X25519 identity")
+}
+
+// NewAgeWrapperFromIdentity wraps data keys to an X25519 identity
+func NewAgeWrapperFromIdentity(identity *age.X25519Identity) IKeyWrapper {
+	recipient := identity.Recipient()
+	return &ageWrapper{
+		name:      "age:" + recipient.String(),
+		recipient: recipient,
+		identity:  identity,
+	}
+}
+
+// NewAgeRecipientWrapper wraps data keys to an age X25519 recipient (age1...) without
+// holding its identity, e.g. to encrypt backups to an offline recovery key. Its envelopes
+// are opened with the wrapper of the identity, UnwrapKey always fails.
+func NewAgeRecipientWrapper(recipient string) (IKeyWrapper, error) {
+	x25519, err := age.ParseX25519Recipient(recipient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse age recipient: %w", err)
+	}
+	return &ageWrapper{
+		name:      "age:" + x25519.String(),
+		recipient: x25519,
+	}, nil
+}
+
+// NewPassphraseWrapper wraps data keys with a passphrase through age's scrypt recipient.
+// Every unwrap runs scrypt, so this is meant for tests and local networks. workFactor
+// is the scrypt log2(N), zero selects age's default.
+func NewPassphraseWrapper(passphrase string, workFactor int) (IKeyWrapper, error) {
+	recipient, err := age.NewScryptRecipient(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	identity, err := age.NewScryptIdentity(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if workFactor > 0 {
+		recipient.SetWorkFactor(workFactor)
+	}
+
+	return &ageWrapper{
+		name:      "passphrase",
+		recipient: recipient,
+		identity:  identity,
+	}, nil
+}
+
+func (w *ageWrapper) Name() string {
+	return w.name
+}
+
+// WrapKey encrypts the data key as an age file. age has no associated data, the
+// envelope ciphertext is bound to aad instead.
+func (w *ageWrapper) WrapKey(_ context.Context, dataKey []byte, _ []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer, err := age.Encrypt(&buf, w.recipient)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := writer.Write(dataKey); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (w *ageWrapper) UnwrapKey(_ context.Context, wrappedKey []byte, _ []byte) ([]byte, error) {
+	if w.identity == nil {
+		return nil, errors.New("age recipient wrapper cannot unwrap keys")
+	}
+	reader, err := age.Decrypt(bytes.NewReader(wrappedKey), w.identity)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(reader)
+}
+
+func isAgeFile(contents []byte) bool {
+	trimmed := bytes.TrimSpace(contents)
+	return bytes.HasPrefix(trimmed, []byte(ageFileHeader)) || bytes.HasPrefix(trimmed, []byte(armor.Header))
+}
+
+func decryptIdentityFile(contents []byte, passphrase string) ([]byte, error) {
+	identity, err := age.NewScryptIdentity(passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	var src io.Reader = bytes.NewReader(contents)
+	if strings.HasPrefix(strings.TrimSpace(string(contents)), armor.Header) {
+		src = armor.NewReader(bufio.NewReader(bytes.NewReader(bytes.TrimSpace(contents))))
+	}
+
+	reader, err := age.Decrypt(src, identity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt age identity file: %w", err)
+	}
+	return io.ReadAll(reader)
+}
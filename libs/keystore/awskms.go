@@ -0,0 +1,73 @@
+package keystore
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+)
+
+// kmsEncryptionContextKey is the encryption context entry that binds a wrapped key to its secret
+const kmsEncryptionContextKey = "secret"
+
+// IKMSClient is the subset of the AWS KMS API used to wrap data keys
+type IKMSClient interface {
+	Encrypt(ctx context.Context, params *kms.EncryptInput, optFns ...func(*kms.Options)) (*kms.EncryptOutput, error)
+	Decrypt(ctx context.Context, params *kms.DecryptInput, optFns ...func(*kms.Options)) (*kms.DecryptOutput, error)
+}
+
+// This is a type assertion to ensure that the KMS client implements the IKMSClient interface
+var _ IKMSClient = &kms.Client{}
+
+// awsKMSWrapper wraps data keys with a symmetric AWS KMS key
+type awsKMSWrapper struct {
+	client IKMSClient
+	keyID  string
+}
+
+// NewAWSKMSWrapper wraps data keys with the KMS key keyID, which may be a key id, ARN or alias
+func NewAWSKMSWrapper(client IKMSClient, keyID string) (IKeyWrapper, error) {
+	if keyID == "" {
+		return nil, fmt.Errorf("KMS key id cannot be empty")
+	}
+	return &awsKMSWrapper{client: client, keyID: keyID}, nil
+}
+
+// NewAWSKMSWrapperFromEnv creates a KMS client from the default AWS configuration chain
+func NewAWSKMSWrapperFromEnv(ctx context.Context, keyID string) (IKeyWrapper, error) {
+	awsCfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	return NewAWSKMSWrapper(kms.NewFromConfig(awsCfg), keyID)
+}
+
+func (w *awsKMSWrapper) Name() string {
+	return "awskms:" + w.keyID
+}
+
+func (w *awsKMSWrapper) WrapKey(ctx context.Context, dataKey []byte, aad []byte) ([]byte, error) {
+	result, err := w.client.Encrypt(ctx, &kms.EncryptInput{
+		KeyId:             aws.String(w.keyID),
+		Plaintext:         dataKey,
+		EncryptionContext: map[string]string{kmsEncryptionContextKey: string(aad)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.CiphertextBlob, nil
+}
+
+func (w *awsKMSWrapper) UnwrapKey(ctx context.Context, wrappedKey []byte, aad []byte) ([]byte, error) {
+	result, err := w.client.Decrypt(ctx, &kms.DecryptInput{
+		KeyId:             aws.String(w.keyID),
+		CiphertextBlob:    wrappedKey,
+		EncryptionContext: map[string]string{kmsEncryptionContextKey: string(aad)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.Plaintext, nil
+}
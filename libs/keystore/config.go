@@ -0,0 +1,52 @@
+package keystore
+
+import (
+	"context"
+	"fmt"
+)
+
+const (
+	BackendAge        = "age"
+	BackendPassphrase = "passphrase"
+	BackendAWSKMS     = "awskms"
+	BackendVault      = "vault"
+)
+
+// Config selects and configures a key-encryption key backend
+type Config struct {
+	Backend string
+
+	// age identity file, optionally passphrase protected, or the passphrase backend
+	AgeIdentityFile string
+	Passphrase      string
+
+	// AWS KMS key id, ARN or alias
+	KMSKeyID string
+
+	// Vault transit
+	VaultAddress string
+	VaultToken   string
+	VaultMount   string
+	VaultKey     string
+}
+
+// NewKeyWrapper creates the key wrapper selected by cfg.Backend
+func NewKeyWrapper(ctx context.Context, cfg Config) (IKeyWrapper, error) {
+	switch cfg.Backend {
+	case BackendAge:
+		return NewAgeWrapper(cfg.AgeIdentityFile, cfg.Passphrase)
+	case BackendPassphrase:
+		if cfg.Passphrase == "" {
+			return nil, fmt.Errorf("passphrase cannot be empty")
+		}
+		return NewPassphraseWrapper(cfg.Passphrase, 0)
+	case BackendAWSKMS:
+		return NewAWSKMSWrapperFromEnv(ctx, cfg.KMSKeyID)
+	case BackendVault:
+		return NewVaultTransitWrapper(cfg.VaultAddress, cfg.VaultToken, cfg.VaultMount, cfg.VaultKey)
+	case "":
+		return nil, fmt.Errorf("no key store backend configured")
+	default:
+		return nil, fmt.Errorf("unknown key store backend: %s", cfg.Backend)
+	}
+}
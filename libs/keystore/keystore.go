@@ -0,0 +1,190 @@
+// Package keystore encrypts secrets at rest with envelope encryption.
+//
+// Every secret is encrypted with its own random AES-256-GCM data key. The data key is
+// then wrapped by a key-encryption key (KEK) held by a pluggable backend (age, AWS KMS,
+// Vault transit), so rotating the KEK only rewraps data keys and never touches the
+// encrypted secrets themselves.
+package keystore
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// sealedPrefix marks a value as a sealed envelope, anything else is legacy plaintext
+const sealedPrefix = "enc:v1:"
+
+const dataKeySize = 32
+
+// ErrKEKMismatch is returned when an envelope was wrapped by a different KEK than the one used to open it
+var ErrKEKMismatch = errors.New("envelope was wrapped by a different key-encryption key")
+
+// IKeyWrapper wraps and unwraps data keys with a key-encryption key
+type IKeyWrapper interface {
+	// Name identifies the backend and its KEK, it is recorded in every envelope
+	Name() string
+	// WrapKey encrypts dataKey. aad identifies the secret and must be passed back to UnwrapKey.
+	WrapKey(ctx context.Context, dataKey []byte, aad []byte) ([]byte, error)
+	// UnwrapKey decrypts a data key produced by WrapKey
+	UnwrapKey(ctx context.Context, wrappedKey []byte, aad []byte) ([]byte, error)
+}
+
+type envelope struct {
+	KEK        string `json:"kek"`
+	WrappedKey []byte `json:"wrappedKey"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// IsSealed reports whether value is a sealed envelope rather than plaintext
+func IsSealed(value string) bool {
+	return strings.HasPrefix(value, sealedPrefix)
+}
+
+// SealedKEK returns the name of the KEK that wrapped a sealed value
+func SealedKEK(sealed string) (string, error) {
+	env, err := parseEnvelope(sealed)
+	if err != nil {
+		return "", err
+	}
+	return env.KEK, nil
+}
+
+// Seal encrypts plaintext under a fresh data key wrapped by wrapper. aad binds the
+// envelope to the secret it stores, e.g. its database key, so envelopes cannot be swapped.
+func Seal(ctx context.Context, wrapper IKeyWrapper, plaintext []byte, aad []byte) (string, error) {
+	dataKey := make([]byte, dataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", fmt.Errorf("failed to generate data key: %w", err)
+	}
+	defer clear(dataKey)
+
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	wrappedKey, err := wrapper.WrapKey(ctx, dataKey, aad)
+	if err != nil {
+		return "", fmt.Errorf("failed to wrap data key with %s: %w", wrapper.Name(), err)
+	}
+
+	return encodeEnvelope(envelope{
+		KEK:        wrapper.Name(),
+		WrappedKey: wrappedKey,
+		Nonce:      nonce,
+		Ciphertext: aead.Seal(nil, nonce, plaintext, aad),
+	})
+}
+
+// Open decrypts a value produced by Seal with the same aad
+func Open(ctx context.Context, wrapper IKeyWrapper, sealed string, aad []byte) ([]byte, error) {
+	env, err := parseEnvelope(sealed)
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey, err := unwrap(ctx, wrapper, env, aad)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(dataKey)
+
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aead.Open(nil, env.Nonce, env.Ciphertext, aad)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt envelope: %w", err)
+	}
+	return plaintext, nil
+}
+
+// Reseal rewraps the data key of a sealed value from one KEK to another. The
+// ciphertext is left as is, so the secret is never decrypted.
+func Reseal(ctx context.Context, from IKeyWrapper, to IKeyWrapper, sealed string, aad []byte) (string, error) {
+	env, err := parseEnvelope(sealed)
+	if err != nil {
+		return "", err
+	}
+
+	dataKey, err := unwrap(ctx, from, env, aad)
+	if err != nil {
+		return "", err
+	}
+	defer clear(dataKey)
+
+	// Check the data key before committing to it under the new KEK
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+	if _, err := aead.Open(nil, env.Nonce, env.Ciphertext, aad); err != nil {
+		return "", fmt.Errorf("failed to decrypt envelope: %w", err)
+	}
+
+	env.WrappedKey, err = to.WrapKey(ctx, dataKey, aad)
+	if err != nil {
+		return "", fmt.Errorf("failed to wrap data key with %s: %w", to.Name(), err)
+	}
+	env.KEK = to.Name()
+
+	return encodeEnvelope(*env)
+}
+
+func unwrap(ctx context.Context, wrapper IKeyWrapper, env *envelope, aad []byte) ([]byte, error) {
+	if env.KEK != wrapper.Name() {
+		return nil, fmt.Errorf("%w: wrapped by %s, opening with %s", ErrKEKMismatch, env.KEK, wrapper.Name())
+	}
+
+	dataKey, err := wrapper.UnwrapKey(ctx, env.WrappedKey, aad)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key with %s: %w", wrapper.Name(), err)
+	}
+	if len(dataKey) != dataKeySize {
+		return nil, fmt.Errorf("unwrapped data key has %d bytes, expected %d", len(dataKey), dataKeySize)
+	}
+	return dataKey, nil
+}
+
+func newAEAD(dataKey []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(dataKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+func encodeEnvelope(env envelope) (string, error) {
+	encoded, err := json.Marshal(env)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode envelope: %w", err)
+	}
+	return sealedPrefix + string(encoded), nil
+}
+
+func parseEnvelope(sealed string) (*envelope, error) {
+	if !IsSealed(sealed) {
+		return nil, errors.New("value is not a sealed envelope")
+	}
+
+	var env envelope
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(sealed, sealedPrefix)), &env); err != nil {
+		return nil, fmt.Errorf("failed to parse envelope: %w", err)
+	}
+	if env.KEK == "" || len(env.WrappedKey) == 0 || len(env.Nonce) == 0 || len(env.Ciphertext) == 0 {
+		return nil, errors.New("envelope is incomplete")
+	}
+	return &env, nil
+}
@@ -0,0 +1,308 @@
+package keystore
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = `{"Xi":12345,"ShareID":1}`
+
+func testAgeWrapper(t *testing.T) (IKeyWrapper, *age.X25519Identity) {
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	return NewAgeWrapperFromIdentity(identity), identity
+}
+
+func TestSealOpen(t *testing.T) {
+	ctx := context.Background()
+	wrapper, _ := testAgeWrapper(t)
+	aad := []byte("0xabc_ecdsa_ecdsa")
+
+	sealed, err := Seal(ctx, wrapper, []byte(testSecret), aad)
+	require.NoError(t, err)
+	require.True(t, IsSealed(sealed))
+	require.False(t, IsSealed(testSecret))
+	require.NotContains(t, sealed, "ShareID")
+
+	kek, err := SealedKEK(sealed)
+	require.NoError(t, err)
+	require.Equal(t, wrapper.Name(), kek)
+
+	plaintext, err := Open(ctx, wrapper, sealed, aad)
+	require.NoError(t, err)
+	require.Equal(t, testSecret, string(plaintext))
+
+	// Every seal uses a fresh data key and nonce
+	again, err := Seal(ctx, wrapper, []byte(testSecret), aad)
+	require.NoError(t, err)
+	require.NotEqual(t, sealed, again)
+
+	t.Run("wrong aad", func(t *testing.T) {
+		_, err := Open(ctx, wrapper, sealed, []byte("0xdef_ecdsa_ecdsa"))
+		require.Error(t, err)
+	})
+
+	t.Run("tampered ciphertext", func(t *testing.T) {
+		var env envelope
+		require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(sealed, sealedPrefix)), &env))
+		env.Ciphertext[0] ^= 1
+		tampered, err := encodeEnvelope(env)
+		require.NoError(t, err)
+
+		_, err = Open(ctx, wrapper, tampered, aad)
+		require.Error(t, err)
+	})
+
+	t.Run("other KEK", func(t *testing.T) {
+		other, _ := testAgeWrapper(t)
+		_, err := Open(ctx, other, sealed, aad)
+		require.ErrorIs(t, err, ErrKEKMismatch)
+	})
+
+	t.Run("plaintext", func(t *testing.T) {
+		_, err := Open(ctx, wrapper, testSecret, aad)
+		require.Error(t, err)
+	})
+}
+
+func TestReseal(t *testing.T) {
+	ctx := context.Background()
+	from, _ := testAgeWrapper(t)
+	to, _ := testAgeWrapper(t)
+	aad := []byte("key")
+
+	sealed, err := Seal(ctx, from, []byte(testSecret), aad)
+	require.NoError(t, err)
+
+	resealed, err := Reseal(ctx, from, to, sealed, aad)
+	require.NoError(t, err)
+
+	_, err = Open(ctx, from, resealed, aad)
+	require.ErrorIs(t, err, ErrKEKMismatch)
+
+	plaintext, err := Open(ctx, to, resealed, aad)
+	require.NoError(t, err)
+	require.Equal(t, testSecret, string(plaintext))
+
+	// The ciphertext is carried over untouched
+	var before, after envelope
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(sealed, sealedPrefix)), &before))
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(resealed, sealedPrefix)), &after))
+	require.Equal(t, before.Ciphertext, after.Ciphertext)
+	require.Equal(t, before.Nonce, after.Nonce)
+
+	_, err = Reseal(ctx, from, to, sealed, []byte("other"))
+	require.Error(t, err)
+}
+
+func TestPassphraseWrapper(t *testing.T) {
+	ctx := context.Background()
+	wrapper, err := NewPassphraseWrapper("correct horse", 10)
+	require.NoError(t, err)
+
+	sealed, err := Seal(ctx, wrapper, []byte(testSecret), nil)
+	require.NoError(t, err)
+
+	plaintext, err := Open(ctx, wrapper, sealed, nil)
+	require.NoError(t, err)
+	require.Equal(t, testSecret, string(plaintext))
+
+	wrong, err := NewPassphraseWrapper("battery staple", 10)
+	require.NoError(t, err)
+	_, err = Open(ctx, wrong, sealed, nil)
+	require.Error(t, err)
+}
+
+func TestAgeRecipientWrapper(t *testing.T) {
+	ctx := context.Background()
+	wrapper, identity := testAgeWrapper(t)
+	aad := []byte("0xabc_ecdsa_ecdsa")
+
+	recipient, err := NewAgeRecipientWrapper(identity.Recipient().String())
+	require.NoError(t, err)
+	require.Equal(t, wrapper.Name(), recipient.Name())
+
+	sealed, err := Seal(ctx, recipient, []byte(testSecret), aad)
+	require.NoError(t, err)
+
+	// Only the identity opens what was sealed to its recipient
+	_, err = Open(ctx, recipient, sealed, aad)
+	require.Error(t, err)
+	plaintext, err := Open(ctx, wrapper, sealed, aad)
+	require.NoError(t, err)
+	require.Equal(t, testSecret, string(plaintext))
+
+	_, err = NewAgeRecipientWrapper("age1invalid")
+	require.Error(t, err)
+}
+
+func TestAgeIdentityFile(t *testing.T) {
+	ctx := context.Background()
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	dir := t.TempDir()
+
+	plainFile := filepath.Join(dir, "identity.txt")
+	require.NoError(t, os.WriteFile(plainFile, []byte("# created for tests\n"+identity.String()+"\n"), 0600))
+
+	// Encrypted identity files are written armored by age-keygen | age -p -a
+	recipient, err := age.NewScryptRecipient("hunter2")
+	require.NoError(t, err)
+	recipient.SetWorkFactor(10)
+	var buf bytes.Buffer
+	armored := armor.NewWriter(&buf)
+	writer, err := age.Encrypt(armored, recipient)
+	require.NoError(t, err)
+	_, err = writer.Write([]byte(identity.String() + "\n"))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	require.NoError(t, armored.Close())
+	encryptedFile := filepath.Join(dir, "identity.age")
+	require.NoError(t, os.WriteFile(encryptedFile, buf.Bytes(), 0600))
+
+	fromPlain, err := NewAgeWrapper(plainFile, "")
+	require.NoError(t, err)
+	require.Equal(t, "age:"+identity.Recipient().String(), fromPlain.Name())
+
+	fromEncrypted, err := NewAgeWrapper(encryptedFile, "hunter2")
+	require.NoError(t, err)
+	require.Equal(t, fromPlain.Name(), fromEncrypted.Name())
+
+	sealed, err := Seal(ctx, fromPlain, []byte(testSecret), nil)
+	require.NoError(t, err)
+	plaintext, err := Open(ctx, fromEncrypted, sealed, nil)
+	require.NoError(t, err)
+	require.Equal(t, testSecret, string(plaintext))
+
+	_, err = NewAgeWrapper(encryptedFile, "")
+	require.Error(t, err)
+	_, err = NewAgeWrapper(encryptedFile, "wrong")
+	require.Error(t, err)
+}
+
+// fakeKMSClient wraps keys with a XOR pad and records the encryption context
+type fakeKMSClient struct {
+	keyID string
+}
+
+func (c *fakeKMSClient) Encrypt(_ context.Context, params *kms.EncryptInput, _ ...func(*kms.Options)) (*kms.EncryptOutput, error) {
+	if *params.KeyId != c.keyID {
+		return nil, errors.New("NotFoundException")
+	}
+	blob := append([]byte(params.EncryptionContext[kmsEncryptionContextKey]+"|"), xorPad(params.Plaintext)...)
+	return &kms.EncryptOutput{CiphertextBlob: blob, KeyId: params.KeyId}, nil
+}
+
+func (c *fakeKMSClient) Decrypt(_ context.Context, params *kms.DecryptInput, _ ...func(*kms.Options)) (*kms.DecryptOutput, error) {
+	encryptionContext, wrapped, ok := bytes.Cut(params.CiphertextBlob, []byte("|"))
+	if !ok || string(encryptionContext) != params.EncryptionContext[kmsEncryptionContextKey] {
+		return nil, errors.New("InvalidCiphertextException")
+	}
+	return &kms.DecryptOutput{Plaintext: xorPad(wrapped), KeyId: params.KeyId}, nil
+}
+
+func xorPad(in []byte) []byte {
+	out := make([]byte, len(in))
+	for i := range in {
+		out[i] = in[i] ^ 0x5a
+	}
+	return out
+}
+
+func TestAWSKMSWrapper(t *testing.T) {
+	ctx := context.Background()
+	keyID := "arn:aws:kms:us-east-1:111122223333:key/test"
+	wrapper, err := NewAWSKMSWrapper(&fakeKMSClient{keyID: keyID}, keyID)
+	require.NoError(t, err)
+	require.Equal(t, "awskms:"+keyID, wrapper.Name())
+
+	sealed, err := Seal(ctx, wrapper, []byte(testSecret), []byte("share"))
+	require.NoError(t, err)
+
+	plaintext, err := Open(ctx, wrapper, sealed, []byte("share"))
+	require.NoError(t, err)
+	require.Equal(t, testSecret, string(plaintext))
+
+	// The encryption context rejects the wrapped key before the envelope is even tried
+	_, err = Open(ctx, wrapper, sealed, []byte("other"))
+	require.ErrorContains(t, err, "InvalidCiphertextException")
+
+	_, err = NewAWSKMSWrapper(&fakeKMSClient{}, "")
+	require.Error(t, err)
+}
+
+func TestVaultTransitWrapper(t *testing.T) {
+	ctx := context.Background()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "s.token" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		switch r.URL.Path {
+		case "/v1/transit/encrypt/strip":
+			plaintext, err := base64.StdEncoding.DecodeString(body["plaintext"])
+			require.NoError(t, err)
+			ciphertext := "vault:v1:" + base64.StdEncoding.EncodeToString(xorPad(plaintext))
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]string{"ciphertext": ciphertext}})
+		case "/v1/transit/decrypt/strip":
+			wrapped, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(body["ciphertext"], "vault:v1:"))
+			require.NoError(t, err)
+			plaintext := base64.StdEncoding.EncodeToString(xorPad(wrapped))
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]string{"plaintext": plaintext}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	wrapper, err := NewVaultTransitWrapper(server.URL, "s.token", "", "strip")
+	require.NoError(t, err)
+	require.Equal(t, "vault:transit/strip", wrapper.Name())
+
+	sealed, err := Seal(ctx, wrapper, []byte(testSecret), nil)
+	require.NoError(t, err)
+	require.Contains(t, sealed, base64.StdEncoding.EncodeToString([]byte("vault:v1:")))
+
+	plaintext, err := Open(ctx, wrapper, sealed, nil)
+	require.NoError(t, err)
+	require.Equal(t, testSecret, string(plaintext))
+
+	denied, err := NewVaultTransitWrapper(server.URL, "s.wrong", "transit", "strip")
+	require.NoError(t, err)
+	_, err = Open(ctx, denied, sealed, nil)
+	require.ErrorContains(t, err, "permission denied")
+}
+
+func TestNewKeyWrapper(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := NewKeyWrapper(ctx, Config{})
+	require.Error(t, err)
+	_, err = NewKeyWrapper(ctx, Config{Backend: "rot13"})
+	require.Error(t, err)
+	_, err = NewKeyWrapper(ctx, Config{Backend: BackendPassphrase})
+	require.Error(t, err)
+
+	wrapper, err := NewKeyWrapper(ctx, Config{Backend: BackendVault, VaultAddress: "http://vault:8200", VaultToken: "t", VaultKey: "k"})
+	require.NoError(t, err)
+	require.Equal(t, "vault:transit/k", wrapper.Name())
+}
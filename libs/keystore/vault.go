@@ -0,0 +1,121 @@
+package keystore
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const vaultRequestTimeout = 10 * time.Second
+
+// vaultTransitWrapper wraps data keys with a HashiCorp Vault transit key
+type vaultTransitWrapper struct {
+	address string
+	token   string
+	mount   string
+	key     string
+	client  *http.Client
+}
+
+// NewVaultTransitWrapper wraps data keys with the transit key named key under mount,
+// e.g. "transit". Vault key versions are handled by Vault itself.
+func NewVaultTransitWrapper(address string, token string, mount string, key string) (IKeyWrapper, error) {
+	if address == "" || token == "" || key == "" {
+		return nil, fmt.Errorf("vault address, token and key are required")
+	}
+	if mount == "" {
+		mount = "transit"
+	}
+	return &vaultTransitWrapper{
+		address: strings.TrimSuffix(address, "/"),
+		token:   token,
+		mount:   strings.Trim(mount, "/"),
+		key:     key,
+		client:  &http.Client{Timeout: vaultRequestTimeout},
+	}, nil
+}
+
+func (w *vaultTransitWrapper) Name() string {
+	return "vault:" + w.mount + "/" + w.key
+}
+
+// WrapKey encrypts the data key with the transit key. Transit only accepts a context
+// for derived keys, the envelope ciphertext is bound to aad instead.
+func (w *vaultTransitWrapper) WrapKey(ctx context.Context, dataKey []byte, _ []byte) ([]byte, error) {
+	var result struct {
+		Ciphertext string `json:"ciphertext"`
+	}
+	err := w.call(ctx, "encrypt", map[string]string{
+		"plaintext": base64.StdEncoding.EncodeToString(dataKey),
+	}, &result)
+	if err != nil {
+		return nil, err
+	}
+	if result.Ciphertext == "" {
+		return nil, fmt.Errorf("vault returned an empty ciphertext")
+	}
+	return []byte(result.Ciphertext), nil
+}
+
+func (w *vaultTransitWrapper) UnwrapKey(ctx context.Context, wrappedKey []byte, _ []byte) ([]byte, error) {
+	var result struct {
+		Plaintext string `json:"plaintext"`
+	}
+	err := w.call(ctx, "decrypt", map[string]string{
+		"ciphertext": string(wrappedKey),
+	}, &result)
+	if err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(result.Plaintext)
+}
+
+func (w *vaultTransitWrapper) call(ctx context.Context, operation string, body interface{}, result interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/v1/%s/%s/%s", w.address, w.mount, operation, w.key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Vault-Token", w.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("vault %s request failed: %w", operation, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var vaultErr struct {
+			Errors []string `json:"errors"`
+		}
+		if json.Unmarshal(respBody, &vaultErr) == nil && len(vaultErr.Errors) > 0 {
+			return fmt.Errorf("vault %s failed with status %d: %s", operation, resp.StatusCode, strings.Join(vaultErr.Errors, "; "))
+		}
+		return fmt.Errorf("vault %s failed with status %d", operation, resp.StatusCode)
+	}
+
+	envelope := struct {
+		Data interface{} `json:"data"`
+	}{Data: result}
+	if err := json.Unmarshal(respBody, &envelope); err != nil {
+		return fmt.Errorf("failed to decode vault %s response: %w", operation, err)
+	}
+	return nil
+}
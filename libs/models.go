@@ -21,6 +21,7 @@ type Operation struct {
 	SolverMetadata   string                   `json:"solverMetadata"`
 	SolverDataToSign string                   `json:"solverDataToSign"`
 	SolverOutput     string                   `json:"solverOutput"`
+	DerivationPath   string                   `json:"derivationPath,omitempty"`
 	CreatedAt        time.Time                `json:"createdAt"`
 }
 
@@ -47,6 +48,7 @@ const (
 	OperationTypeBurnSynthetic OperationType = "BURN_SYNTHETIC"
 	OperationTypeWithdraw      OperationType = "WITHDRAW"
 	OperationTypeSendToBridge  OperationType = "SEND_TO_BRIDGE"
+	OperationTypeTrustSet      OperationType = "TRUST_SET"
 )
 
 type IntentStatus string
@@ -0,0 +1,67 @@
+package mtls
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/StripChain/strip-node/util/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// PeerCertificate returns the certificate the client of a gRPC call presented
+func PeerCertificate(ctx context.Context) (*x509.Certificate, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, errors.New("no peer in context")
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return nil, errors.New("connection is not TLS")
+	}
+	if len(info.State.PeerCertificates) == 0 {
+		return nil, errors.New("no client certificate")
+	}
+	return info.State.PeerCertificates[0], nil
+}
+
+// CertificateNames returns the names a certificate was issued to: its common name and
+// DNS names
+func CertificateNames(cert *x509.Certificate) []string {
+	names := []string{}
+	if cert.Subject.CommonName != "" {
+		names = append(names, cert.Subject.CommonName)
+	}
+	return append(names, cert.DNSNames...)
+}
+
+// AuthorizeClients returns an interceptor only letting through the calls of clients whose
+// certificate is issued to one of the allowed names. Methods starting with one of the
+// exempt prefixes, such as health checks, are open to any client the TLS layer accepted.
+func AuthorizeClients(allowed []string, exempt ...string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		for _, prefix := range exempt {
+			if strings.HasPrefix(info.FullMethod, prefix) {
+				return handler(ctx, req)
+			}
+		}
+
+		cert, err := PeerCertificate(ctx)
+		if err != nil {
+			return nil, status.Errorf(codes.Unauthenticated, "client certificate required: %v", err)
+		}
+		for _, name := range CertificateNames(cert) {
+			if slices.Contains(allowed, name) {
+				return handler(ctx, req)
+			}
+		}
+		logger.Sugar().Warnw("rejected call from an unauthorized client", "method", info.FullMethod, "client", cert.Subject.String())
+		return nil, status.Errorf(codes.PermissionDenied, "client %s is not authorized", cert.Subject.CommonName)
+	}
+}
@@ -0,0 +1,267 @@
+package mtls
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/StripChain/strip-node/util/logger"
+)
+
+// Dev mode replaces real certificates with ones issued by a throwaway CA. Nodes sharing a
+// directory, such as a docker-compose volume, share the CA: the first to start creates it
+// and every node issues itself a certificate when it starts. The CA key sits next to the
+// certificates, so dev mode must never be used outside of local networks and tests.
+
+const (
+	devCADir          = "ca"
+	devCACertFile     = "ca.pem"
+	devCAKeyFile      = "ca-key.pem"
+	devCAValidity     = 365 * 24 * time.Hour
+	devCertValidity   = 30 * 24 * time.Hour
+	devCertBackdating = time.Hour
+)
+
+// DevCA is a throwaway certificate authority for local networks and tests
+type DevCA struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+}
+
+func randomSerial() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
+
+// NewDevCA creates a CA in memory
+func NewDevCA() (*DevCA, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	serial, err := randomSerial()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "strip-node dev CA"},
+		NotBefore:             now.Add(-devCertBackdating),
+		NotAfter:              now.Add(devCAValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	return &DevCA{cert: cert, key: key, certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}, nil
+}
+
+// CertPEM returns the certificate of the CA
+func (ca *DevCA) CertPEM() []byte {
+	return ca.certPEM
+}
+
+// Issue issues a certificate for name, valid for servers and clients. hosts are the
+// other DNS names and IP addresses the certificate is valid for.
+func (ca *DevCA) Issue(name string, hosts ...string) (certPEM []byte, keyPEM []byte, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := randomSerial()
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    now.Add(-devCertBackdating),
+		NotAfter:     now.Add(devCertValidity),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		DNSNames:     []string{name},
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else if host != name {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), nil
+}
+
+func parseDevCA(certPEM []byte, keyPEM []byte) (*DevCA, error) {
+	certBlock, _ := pem.Decode(certPEM)
+	keyBlock, _ := pem.Decode(keyPEM)
+	if certBlock == nil || keyBlock == nil {
+		return nil, errors.New("invalid dev CA PEM")
+	}
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParseECPrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	return &DevCA{cert: cert, key: key, certPEM: certPEM}, nil
+}
+
+// LoadOrCreateDevCA loads the dev CA of a directory, creating it when there is none yet
+func LoadOrCreateDevCA(dir string) (*DevCA, error) {
+	caDir := filepath.Join(dir, devCADir)
+	if _, err := os.Stat(caDir); errors.Is(err, os.ErrNotExist) {
+		if err := createDevCA(dir, caDir); err != nil {
+			return nil, err
+		}
+	}
+
+	certPEM, err := os.ReadFile(filepath.Join(caDir, devCACertFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dev CA: %w", err)
+	}
+	keyPEM, err := os.ReadFile(filepath.Join(caDir, devCAKeyFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dev CA: %w", err)
+	}
+	return parseDevCA(certPEM, keyPEM)
+}
+
+// createDevCA writes a new CA to a temporary directory renamed to caDir, so nodes starting
+// together never see half a CA. When another node won the race its CA is kept.
+func createDevCA(dir string, caDir string) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create dev TLS directory: %w", err)
+	}
+	ca, err := NewDevCA()
+	if err != nil {
+		return fmt.Errorf("failed to create dev CA: %w", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(ca.key)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.MkdirTemp(dir, ".ca-")
+	if err != nil {
+		return fmt.Errorf("failed to create dev CA: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+	if err := os.WriteFile(filepath.Join(tmp, devCACertFile), ca.certPEM, 0o644); err != nil {
+		return fmt.Errorf("failed to write dev CA: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmp, devCAKeyFile), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		return fmt.Errorf("failed to write dev CA: %w", err)
+	}
+	if err := os.Rename(tmp, caDir); err != nil {
+		if _, statErr := os.Stat(caDir); statErr == nil {
+			return nil
+		}
+		return fmt.Errorf("failed to create dev CA: %w", err)
+	}
+	logger.Sugar().Warnw("created a throwaway dev CA, do not use it outside of local networks", "dir", caDir)
+	return nil
+}
+
+// DevConfig issues the node a certificate from the dev CA of dir and writes it there as
+// <name>.pem and <name>-key.pem, for health probes and other local tools to use as well
+func DevConfig(dir string, name string, hosts ...string) (Config, error) {
+	ca, err := LoadOrCreateDevCA(dir)
+	if err != nil {
+		return Config{}, err
+	}
+	certPEM, keyPEM, err := ca.Issue(name, hosts...)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to issue dev certificate: %w", err)
+	}
+
+	cfg := Config{
+		Cert: filepath.Join(dir, name+".pem"),
+		Key:  filepath.Join(dir, name+"-key.pem"),
+		CA:   filepath.Join(dir, devCADir, devCACertFile),
+	}
+	// A reload between the two writes fails to pair them and keeps the current credentials
+	if err := writeFileAtomic(cfg.Key, keyPEM, 0o600); err != nil {
+		return Config{}, err
+	}
+	if err := writeFileAtomic(cfg.Cert, certPEM, 0o644); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, perm); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// Options configures the credentials of a node
+type Options struct {
+	Config
+	// DevDir enables dev mode, the certificate, key and CA are then ignored
+	DevDir string
+	// DevName is the name the node's dev certificate is issued to
+	DevName string
+	// DevHosts are the other names and addresses of the node's dev certificate
+	DevHosts []string
+	// ReloadInterval is how often the credentials are reloaded
+	ReloadInterval time.Duration
+}
+
+// Setup loads the credentials of a node and reloads them until ctx is done
+func Setup(ctx context.Context, opts Options) (*Credentials, error) {
+	cfg := opts.Config
+	if opts.DevDir != "" {
+		if opts.DevName == "" {
+			return nil, errors.New("dev TLS needs a certificate name")
+		}
+		var err error
+		if cfg, err = DevConfig(opts.DevDir, opts.DevName, opts.DevHosts...); err != nil {
+			return nil, err
+		}
+	}
+
+	creds, err := NewCredentials(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	go creds.Watch(ctx, opts.ReloadInterval)
+	return creds, nil
+}
@@ -0,0 +1,255 @@
+// Package mtls provides the mutual TLS credentials of the gRPC connections between the
+// sequencer and the validators. Certificates, keys and CAs are PEMs read from files or
+// AWS Secrets Manager, and are reloaded while the node runs so they can be rotated
+// without a restart.
+package mtls
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/StripChain/strip-node/libs"
+	"github.com/StripChain/strip-node/util/logger"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+)
+
+// secretARNPrefix marks PEM sources that are AWS Secrets Manager secrets rather than files
+const secretARNPrefix = "arn:"
+
+// DefaultReloadInterval is how often credentials are reloaded when no interval is configured
+const DefaultReloadInterval = time.Minute
+
+// Config locates the PEMs of a node's certificate and key, and of the CA its peers'
+// certificates must chain to. Each is a file path or a Secrets Manager ARN.
+type Config struct {
+	Cert string
+	Key  string
+	CA   string
+}
+
+// SecretFetcher returns the value of a Secrets Manager secret
+type SecretFetcher func(ctx context.Context, arn string) (string, error)
+
+// awsSecretFetcher fetches secrets with a client created from the default AWS configuration
+// the first time one is needed
+func awsSecretFetcher() SecretFetcher {
+	var once sync.Once
+	var client *secretsmanager.Client
+	var clientErr error
+	return func(ctx context.Context, arn string) (string, error) {
+		once.Do(func() {
+			awsCfg, err := config.LoadDefaultConfig(ctx)
+			if err != nil {
+				clientErr = fmt.Errorf("failed to load AWS config: %w", err)
+				return
+			}
+			client = secretsmanager.NewFromConfig(awsCfg)
+		})
+		if clientErr != nil {
+			return "", clientErr
+		}
+		return libs.FetchSecret(ctx, client, arn)
+	}
+}
+
+// material is a loaded certificate with the CA pool it is verified against
+type material struct {
+	cert   *tls.Certificate
+	pool   *x509.CertPool
+	digest [sha256.Size]byte
+}
+
+// Credentials holds the current TLS material of a node. The configurations it returns
+// always use the latest material, so a reload applies to every new handshake.
+type Credentials struct {
+	cfg     Config
+	fetch   SecretFetcher
+	current atomic.Pointer[material]
+}
+
+// NewCredentials loads the credentials located by cfg
+func NewCredentials(ctx context.Context, cfg Config) (*Credentials, error) {
+	return NewCredentialsWithFetcher(ctx, cfg, awsSecretFetcher())
+}
+
+// NewCredentialsWithFetcher loads the credentials located by cfg, fetching secrets with fetch
+func NewCredentialsWithFetcher(ctx context.Context, cfg Config, fetch SecretFetcher) (*Credentials, error) {
+	if cfg.Cert == "" || cfg.Key == "" || cfg.CA == "" {
+		return nil, errors.New("TLS certificate, key and CA are required")
+	}
+	c := &Credentials{cfg: cfg, fetch: fetch}
+	if _, err := c.Reload(ctx); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Credentials) loadPEM(ctx context.Context, source string) ([]byte, error) {
+	if strings.HasPrefix(source, secretARNPrefix) {
+		value, err := c.fetch(ctx, source)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(value), nil
+	}
+	return os.ReadFile(source)
+}
+
+// Reload reads the PEMs again and swaps them in when they changed. Material that does not
+// parse, or whose certificate does not chain to the CA, is rejected and the current one
+// kept. It reports whether the credentials changed.
+func (c *Credentials) Reload(ctx context.Context) (bool, error) {
+	certPEM, err := c.loadPEM(ctx, c.cfg.Cert)
+	if err != nil {
+		return false, fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	keyPEM, err := c.loadPEM(ctx, c.cfg.Key)
+	if err != nil {
+		return false, fmt.Errorf("failed to load TLS key: %w", err)
+	}
+	caPEM, err := c.loadPEM(ctx, c.cfg.CA)
+	if err != nil {
+		return false, fmt.Errorf("failed to load TLS CA: %w", err)
+	}
+
+	digest := sha256.Sum256(bytes.Join([][]byte{certPEM, keyPEM, caPEM}, []byte{0}))
+	if current := c.current.Load(); current != nil && current.digest == digest {
+		return false, nil
+	}
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return false, fmt.Errorf("failed to load TLS key pair: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return false, errors.New("TLS CA contains no certificate")
+	}
+	if _, err := verifyChain(pool, cert.Certificate, "", 0); err != nil {
+		return false, fmt.Errorf("TLS certificate does not chain to the CA: %w", err)
+	}
+
+	c.current.Store(&material{cert: &cert, pool: pool, digest: digest})
+	return true, nil
+}
+
+// Watch reloads the credentials every interval until ctx is done
+func (c *Credentials) Watch(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultReloadInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			changed, err := c.Reload(ctx)
+			if err != nil {
+				logger.Sugar().Errorw("failed to reload TLS credentials, keeping the current ones", "error", err)
+				continue
+			}
+			if changed {
+				logger.Sugar().Infow("reloaded TLS credentials", "subject", c.Certificate().Subject.String())
+			}
+		}
+	}
+}
+
+// Certificate returns the current certificate of the node
+func (c *Credentials) Certificate() *x509.Certificate {
+	cert, err := x509.ParseCertificate(c.current.Load().cert.Certificate[0])
+	if err != nil {
+		return nil
+	}
+	return cert
+}
+
+// verifyChain verifies a peer's certificate chain against the CA pool, for the given
+// server name when it is not empty
+func verifyChain(pool *x509.CertPool, rawCerts [][]byte, serverName string, usage x509.ExtKeyUsage) (*x509.Certificate, error) {
+	if len(rawCerts) == 0 {
+		return nil, errors.New("no certificate presented")
+	}
+	certs := make([]*x509.Certificate, len(rawCerts))
+	for i, raw := range rawCerts {
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return nil, err
+		}
+		certs[i] = cert
+	}
+
+	opts := x509.VerifyOptions{
+		Roots:         pool,
+		Intermediates: x509.NewCertPool(),
+		DNSName:       serverName,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}
+	if usage != 0 {
+		opts.KeyUsages = []x509.ExtKeyUsage{usage}
+	}
+	for _, cert := range certs[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+	if _, err := certs[0].Verify(opts); err != nil {
+		return nil, err
+	}
+	return certs[0], nil
+}
+
+// ServerConfig returns the TLS configuration of a server requiring client certificates
+// that chain to the current CA. Which clients may call which services is up to the server,
+// see AuthorizeClients.
+func (c *Credentials) ServerConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// The client certificate is verified against the current CA by VerifyConnection
+		ClientAuth: tls.RequireAnyClientCert,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return c.current.Load().cert, nil
+		},
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			_, err := verifyChain(c.current.Load().pool, rawCertificates(cs), "", x509.ExtKeyUsageClientAuth)
+			return err
+		},
+	}
+}
+
+// ClientConfig returns the TLS configuration of a client presenting the node's certificate
+// and verifying servers against the current CA
+func (c *Credentials) ClientConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// Go's verification would pin the CA pool of the first handshake, the server
+		// is verified against the current one by VerifyConnection instead
+		InsecureSkipVerify: true,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return c.current.Load().cert, nil
+		},
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			_, err := verifyChain(c.current.Load().pool, rawCertificates(cs), cs.ServerName, x509.ExtKeyUsageServerAuth)
+			return err
+		},
+	}
+}
+
+func rawCertificates(cs tls.ConnectionState) [][]byte {
+	raw := make([][]byte, len(cs.PeerCertificates))
+	for i, cert := range cs.PeerCertificates {
+		raw[i] = cert.Raw
+	}
+	return raw
+}
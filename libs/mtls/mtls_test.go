@@ -0,0 +1,164 @@
+package mtls
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func testCredentials(t *testing.T, dir string, name string) *Credentials {
+	cfg, err := DevConfig(dir, name, "localhost", "127.0.0.1")
+	require.NoError(t, err)
+	creds, err := NewCredentials(context.Background(), cfg)
+	require.NoError(t, err)
+	return creds
+}
+
+// handshake connects a client to a server and returns the common names each side saw
+func handshake(t *testing.T, server *tls.Config, client *tls.Config) (serverSaw string, clientSaw string, err error) {
+	lis, err := tls.Listen("tcp", "127.0.0.1:0", server)
+	require.NoError(t, err)
+	defer lis.Close()
+
+	done := make(chan tls.ConnectionState, 1)
+	go func() {
+		conn, err := lis.Accept()
+		if err != nil {
+			done <- tls.ConnectionState{}
+			return
+		}
+		defer conn.Close()
+		tlsConn := conn.(*tls.Conn)
+		// Reading completes the handshake, a rejected client gets an alert
+		if _, err := tlsConn.Read(make([]byte, 1)); err != nil {
+			done <- tls.ConnectionState{}
+			return
+		}
+		done <- tlsConn.ConnectionState()
+	}()
+
+	conn, err := tls.Dial("tcp", lis.Addr().String(), client)
+	if err != nil {
+		return "", "", err
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte{0}); err != nil {
+		return "", "", err
+	}
+	state := <-done
+	if len(state.PeerCertificates) == 0 {
+		return "", "", errors.New("server rejected the client")
+	}
+	return state.PeerCertificates[0].Subject.CommonName, conn.ConnectionState().PeerCertificates[0].Subject.CommonName, nil
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	validator := testCredentials(t, dir, "validator1")
+	sequencer := testCredentials(t, dir, "sequencer")
+
+	clientConfig := sequencer.ClientConfig()
+	clientConfig.ServerName = "validator1"
+	serverSaw, clientSaw, err := handshake(t, validator.ServerConfig(), clientConfig)
+	require.NoError(t, err)
+	require.Equal(t, "sequencer", serverSaw)
+	require.Equal(t, "validator1", clientSaw)
+
+	// The server name must be one of the certificate's
+	clientConfig.ServerName = "validator2"
+	_, _, err = handshake(t, validator.ServerConfig(), clientConfig)
+	require.Error(t, err)
+
+	// Clients with a certificate of another CA are rejected
+	stranger := testCredentials(t, t.TempDir(), "sequencer")
+	strangerConfig := stranger.ClientConfig()
+	strangerConfig.ServerName = "validator1"
+	_, _, err = handshake(t, validator.ServerConfig(), strangerConfig)
+	require.Error(t, err)
+}
+
+func TestCredentialsReload(t *testing.T) {
+	dir := t.TempDir()
+	validator := testCredentials(t, dir, "validator1")
+	first := validator.Certificate().SerialNumber
+
+	changed, err := validator.Reload(context.Background())
+	require.NoError(t, err)
+	require.False(t, changed)
+
+	// Reissuing the certificate on disk rotates it without a restart
+	_, err = DevConfig(dir, "validator1", "localhost")
+	require.NoError(t, err)
+	changed, err = validator.Reload(context.Background())
+	require.NoError(t, err)
+	require.True(t, changed)
+	require.NotEqual(t, first, validator.Certificate().SerialNumber)
+
+	// A certificate of another CA is rejected and the current one kept
+	other, err := NewDevCA()
+	require.NoError(t, err)
+	certPEM, _, err := other.Issue("validator1")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "validator1.pem"), certPEM, 0o644))
+	current := validator.Certificate().SerialNumber
+	_, err = validator.Reload(context.Background())
+	require.Error(t, err)
+	require.Equal(t, current, validator.Certificate().SerialNumber)
+}
+
+func TestCredentialsFromSecrets(t *testing.T) {
+	ca, err := NewDevCA()
+	require.NoError(t, err)
+	certPEM, keyPEM, err := ca.Issue("sequencer")
+	require.NoError(t, err)
+	secrets := map[string]string{
+		"arn:aws:secretsmanager:cert": string(certPEM),
+		"arn:aws:secretsmanager:key":  string(keyPEM),
+	}
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, ca.CertPEM(), 0o644))
+
+	fetch := func(ctx context.Context, arn string) (string, error) {
+		return secrets[arn], nil
+	}
+	creds, err := NewCredentialsWithFetcher(context.Background(), Config{Cert: "arn:aws:secretsmanager:cert", Key: "arn:aws:secretsmanager:key", CA: caFile}, fetch)
+	require.NoError(t, err)
+	require.Equal(t, "sequencer", creds.Certificate().Subject.CommonName)
+}
+
+func TestAuthorizeClients(t *testing.T) {
+	ca, err := NewDevCA()
+	require.NoError(t, err)
+	interceptor := AuthorizeClients([]string{"sequencer"}, "/grpc.health.v1.Health/")
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+	call := func(name string, method string) error {
+		ctx := context.Background()
+		if name != "" {
+			certPEM, keyPEM, err := ca.Issue(name)
+			require.NoError(t, err)
+			cert, err := tls.X509KeyPair(certPEM, keyPEM)
+			require.NoError(t, err)
+			ctx = peer.NewContext(ctx, &peer.Peer{AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert.Leaf}}}})
+		}
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		return err
+	}
+
+	require.NoError(t, call("sequencer", "/validator.ValidatorService/SignBatch"))
+	require.Equal(t, codes.PermissionDenied, status.Code(call("validator2", "/validator.ValidatorService/SignBatch")))
+	require.Equal(t, codes.Unauthenticated, status.Code(call("", "/validator.ValidatorService/SignBatch")))
+	require.NoError(t, call("validator2", "/grpc.health.v1.Health/Check"))
+}
@@ -28,6 +28,7 @@ const (
 	Curve_CURVE_UNSPECIFIED Curve = 0
 	Curve_CURVE_ECDSA       Curve = 1
 	Curve_CURVE_EDDSA       Curve = 2
+	Curve_CURVE_FROST       Curve = 3 // Ed25519 keys generated and used with FROST (RFC 9591)
 )
 
 // Enum value maps for Curve.
@@ -36,11 +37,13 @@ var (
 		0: "CURVE_UNSPECIFIED",
 		1: "CURVE_ECDSA",
 		2: "CURVE_EDDSA",
+		3: "CURVE_FROST",
 	}
 	Curve_value = map[string]int32{
 		"CURVE_UNSPECIFIED": 0,
 		"CURVE_ECDSA":       1,
 		"CURVE_EDDSA":       2,
+		"CURVE_FROST":       3,
 	}
 )
 
@@ -223,6 +226,7 @@ const (
 	OperationType_BURN                       OperationType = 6
 	OperationType_WITHDRAW                   OperationType = 7
 	OperationType_BURN_SYNTHETIC             OperationType = 8
+	OperationType_TRUST_SET                  OperationType = 9
 )
 
 // Enum value maps for OperationType.
@@ -237,6 +241,7 @@ var (
 		6: "BURN",
 		7: "WITHDRAW",
 		8: "BURN_SYNTHETIC",
+		9: "TRUST_SET",
 	}
 	OperationType_value = map[string]int32{
 		"OPERATION_TYPE_UNSPECIFIED": 0,
@@ -248,6 +253,7 @@ var (
 		"BURN":                       6,
 		"WITHDRAW":                   7,
 		"BURN_SYNTHETIC":             8,
+		"TRUST_SET":                  9,
 	}
 )
 
@@ -333,6 +339,61 @@ func (IntentStatus) EnumDescriptor() ([]byte, []int) {
 	return file_libs_proto_validator_proto_rawDescGZIP(), []int{4}
 }
 
+type SessionStatus int32
+
+const (
+	SessionStatus_SESSION_STATUS_UNSPECIFIED SessionStatus = 0
+	SessionStatus_SESSION_STATUS_PENDING     SessionStatus = 1
+	SessionStatus_SESSION_STATUS_RUNNING     SessionStatus = 2
+	SessionStatus_SESSION_STATUS_COMPLETED   SessionStatus = 3
+	SessionStatus_SESSION_STATUS_FAILED      SessionStatus = 4
+)
+
+// Enum value maps for SessionStatus.
+var (
+	SessionStatus_name = map[int32]string{
+		0: "SESSION_STATUS_UNSPECIFIED",
+		1: "SESSION_STATUS_PENDING",
+		2: "SESSION_STATUS_RUNNING",
+		3: "SESSION_STATUS_COMPLETED",
+		4: "SESSION_STATUS_FAILED",
+	}
+	SessionStatus_value = map[string]int32{
+		"SESSION_STATUS_UNSPECIFIED": 0,
+		"SESSION_STATUS_PENDING":     1,
+		"SESSION_STATUS_RUNNING":     2,
+		"SESSION_STATUS_COMPLETED":   3,
+		"SESSION_STATUS_FAILED":      4,
+	}
+)
+
+func (x SessionStatus) Enum() *SessionStatus {
+	p := new(SessionStatus)
+	*p = x
+	return p
+}
+
+func (x SessionStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SessionStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_libs_proto_validator_proto_enumTypes[5].Descriptor()
+}
+
+func (SessionStatus) Type() protoreflect.EnumType {
+	return &file_libs_proto_validator_proto_enumTypes[5]
+}
+
+func (x SessionStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SessionStatus.Descriptor instead.
+func (SessionStatus) EnumDescriptor() ([]byte, []int) {
+	return file_libs_proto_validator_proto_rawDescGZIP(), []int{5}
+}
+
 type OperationStatus int32
 
 const (
@@ -375,11 +436,11 @@ func (x OperationStatus) String() string {
 }
 
 func (OperationStatus) Descriptor() protoreflect.EnumDescriptor {
-	return file_libs_proto_validator_proto_enumTypes[5].Descriptor()
+	return file_libs_proto_validator_proto_enumTypes[6].Descriptor()
 }
 
 func (OperationStatus) Type() protoreflect.EnumType {
-	return &file_libs_proto_validator_proto_enumTypes[5]
+	return &file_libs_proto_validator_proto_enumTypes[6]
 }
 
 func (x OperationStatus) Number() protoreflect.EnumNumber {
@@ -388,24 +449,25 @@ func (x OperationStatus) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use OperationStatus.Descriptor instead.
 func (OperationStatus) EnumDescriptor() ([]byte, []int) {
-	return file_libs_proto_validator_proto_rawDescGZIP(), []int{5}
+	return file_libs_proto_validator_proto_rawDescGZIP(), []int{6}
 }
 
 type Operation struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	ID            int32                  `protobuf:"varint,1,opt,name=ID,proto3" json:"ID,omitempty"`
-	Type          OperationType          `protobuf:"varint,2,opt,name=type,proto3,enum=validator.OperationType" json:"type,omitempty"`
-	BlockchainId  BlockchainID           `protobuf:"varint,3,opt,name=blockchain_id,json=blockchainId,proto3,enum=validator.BlockchainID" json:"blockchain_id,omitempty"`
-	NetworkType   NetworkType            `protobuf:"varint,4,opt,name=network_type,json=networkType,proto3,enum=validator.NetworkType" json:"network_type,omitempty"`
-	Result        string                 `protobuf:"bytes,5,opt,name=result,proto3" json:"result,omitempty"`
-	SerializedTxn string                 `protobuf:"bytes,6,opt,name=serialized_txn,json=serializedTxn,proto3" json:"serialized_txn,omitempty"`
-	Solver        *Solver                `protobuf:"bytes,7,opt,name=solver,proto3" json:"solver,omitempty"`
-	DataToSign    *string                `protobuf:"bytes,8,opt,name=data_to_sign,json=dataToSign,proto3,oneof" json:"data_to_sign,omitempty"`
-	Status        OperationStatus        `protobuf:"varint,9,opt,name=status,proto3,enum=validator.OperationStatus" json:"status,omitempty"`
-	GenesisHash   string                 `protobuf:"bytes,10,opt,name=genesis_hash,json=genesisHash,proto3" json:"genesis_hash,omitempty"`
-	CreatedAt     *timestamp.Timestamp   `protobuf:"bytes,11,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ID             int32                  `protobuf:"varint,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	Type           OperationType          `protobuf:"varint,2,opt,name=type,proto3,enum=validator.OperationType" json:"type,omitempty"`
+	BlockchainId   BlockchainID           `protobuf:"varint,3,opt,name=blockchain_id,json=blockchainId,proto3,enum=validator.BlockchainID" json:"blockchain_id,omitempty"`
+	NetworkType    NetworkType            `protobuf:"varint,4,opt,name=network_type,json=networkType,proto3,enum=validator.NetworkType" json:"network_type,omitempty"`
+	Result         string                 `protobuf:"bytes,5,opt,name=result,proto3" json:"result,omitempty"`
+	SerializedTxn  string                 `protobuf:"bytes,6,opt,name=serialized_txn,json=serializedTxn,proto3" json:"serialized_txn,omitempty"`
+	Solver         *Solver                `protobuf:"bytes,7,opt,name=solver,proto3" json:"solver,omitempty"`
+	DataToSign     *string                `protobuf:"bytes,8,opt,name=data_to_sign,json=dataToSign,proto3,oneof" json:"data_to_sign,omitempty"`
+	Status         OperationStatus        `protobuf:"varint,9,opt,name=status,proto3,enum=validator.OperationStatus" json:"status,omitempty"`
+	GenesisHash    string                 `protobuf:"bytes,10,opt,name=genesis_hash,json=genesisHash,proto3" json:"genesis_hash,omitempty"`
+	CreatedAt      *timestamp.Timestamp   `protobuf:"bytes,11,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	DerivationPath string                 `protobuf:"bytes,12,opt,name=derivation_path,json=derivationPath,proto3" json:"derivation_path,omitempty"` // Child key of the signing wallet, e.g. m/0/1
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *Operation) Reset() {
@@ -515,6 +577,13 @@ func (x *Operation) GetCreatedAt() *timestamp.Timestamp {
 	return nil
 }
 
+func (x *Operation) GetDerivationPath() string {
+	if x != nil {
+		return x.DerivationPath
+	}
+	return ""
+}
+
 type Solver struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Domain        string                 `protobuf:"bytes,1,opt,name=domain,proto3" json:"domain,omitempty"`
@@ -697,6 +766,8 @@ type KeygenRequest struct {
 	Identity      string                 `protobuf:"bytes,1,opt,name=identity,proto3" json:"identity,omitempty"`
 	IdentityCurve Curve                  `protobuf:"varint,2,opt,name=identity_curve,json=identityCurve,proto3,enum=validator.Curve" json:"identity_curve,omitempty"`
 	Signers       []string               `protobuf:"bytes,3,rep,name=signers,proto3" json:"signers,omitempty"`
+	Threshold     uint32                 `protobuf:"varint,4,opt,name=threshold,proto3" json:"threshold,omitempty"`                                                // TSS threshold, threshold+1 signers can sign. 0 uses the default for the committee
+	Ed25519Curve  Curve                  `protobuf:"varint,5,opt,name=ed25519_curve,json=ed25519Curve,proto3,enum=validator.Curve" json:"ed25519_curve,omitempty"` // Protocol of the Ed25519 key: CURVE_EDDSA, the default, or CURVE_FROST
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -752,6 +823,20 @@ func (x *KeygenRequest) GetSigners() []string {
 	return nil
 }
 
+func (x *KeygenRequest) GetThreshold() uint32 {
+	if x != nil {
+		return x.Threshold
+	}
+	return 0
+}
+
+func (x *KeygenRequest) GetEd25519Curve() Curve {
+	if x != nil {
+		return x.Ed25519Curve
+	}
+	return Curve_CURVE_UNSPECIFIED
+}
+
 type KeygenResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"` // e.g., "Keygen operation completed successfully" or error
@@ -796,29 +881,28 @@ func (x *KeygenResponse) GetMessage() string {
 	return ""
 }
 
-// GetAddresses (/address)
-type GetAddressesRequest struct {
+// StartKeygen - Starts a keygen in the background, its progress is polled with GetKeygenStatus
+type StartKeygenResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Identity      string                 `protobuf:"bytes,1,opt,name=identity,proto3" json:"identity,omitempty"`
-	IdentityCurve Curve                  `protobuf:"varint,2,opt,name=identity_curve,json=identityCurve,proto3,enum=validator.Curve" json:"identity_curve,omitempty"` // Changed from string query param
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetAddressesRequest) Reset() {
-	*x = GetAddressesRequest{}
+func (x *StartKeygenResponse) Reset() {
+	*x = StartKeygenResponse{}
 	mi := &file_libs_proto_validator_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetAddressesRequest) String() string {
+func (x *StartKeygenResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetAddressesRequest) ProtoMessage() {}
+func (*StartKeygenResponse) ProtoMessage() {}
 
-func (x *GetAddressesRequest) ProtoReflect() protoreflect.Message {
+func (x *StartKeygenResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_libs_proto_validator_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -830,47 +914,39 @@ func (x *GetAddressesRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetAddressesRequest.ProtoReflect.Descriptor instead.
-func (*GetAddressesRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use StartKeygenResponse.ProtoReflect.Descriptor instead.
+func (*StartKeygenResponse) Descriptor() ([]byte, []int) {
 	return file_libs_proto_validator_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *GetAddressesRequest) GetIdentity() string {
+func (x *StartKeygenResponse) GetSessionId() string {
 	if x != nil {
-		return x.Identity
+		return x.SessionId
 	}
 	return ""
 }
 
-func (x *GetAddressesRequest) GetIdentityCurve() Curve {
-	if x != nil {
-		return x.IdentityCurve
-	}
-	return Curve_CURVE_UNSPECIFIED
-}
-
-type AddressDetail struct {
+type GetKeygenStatusRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	NetworkType   NetworkType            `protobuf:"varint,1,opt,name=network_type,json=networkType,proto3,enum=validator.NetworkType" json:"network_type,omitempty"`
-	Address       string                 `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *AddressDetail) Reset() {
-	*x = AddressDetail{}
+func (x *GetKeygenStatusRequest) Reset() {
+	*x = GetKeygenStatusRequest{}
 	mi := &file_libs_proto_validator_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *AddressDetail) String() string {
+func (x *GetKeygenStatusRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AddressDetail) ProtoMessage() {}
+func (*GetKeygenStatusRequest) ProtoMessage() {}
 
-func (x *AddressDetail) ProtoReflect() protoreflect.Message {
+func (x *GetKeygenStatusRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_libs_proto_validator_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -882,47 +958,40 @@ func (x *AddressDetail) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AddressDetail.ProtoReflect.Descriptor instead.
-func (*AddressDetail) Descriptor() ([]byte, []int) {
+// Deprecated: Use GetKeygenStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetKeygenStatusRequest) Descriptor() ([]byte, []int) {
 	return file_libs_proto_validator_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *AddressDetail) GetNetworkType() NetworkType {
-	if x != nil {
-		return x.NetworkType
-	}
-	return NetworkType_NETWORK_TYPE_UNSPECIFIED
-}
-
-func (x *AddressDetail) GetAddress() string {
+func (x *GetKeygenStatusRequest) GetSessionId() string {
 	if x != nil {
-		return x.Address
+		return x.SessionId
 	}
 	return ""
 }
 
-// Using nested maps to represent map[BlockchainID]map[NetworkType]string
-type BlockchainAddressMap struct {
-	state            protoimpl.MessageState   `protogen:"open.v1"`
-	NetworkAddresses map[int32]*AddressDetail `protobuf:"bytes,1,rep,name=network_addresses,json=networkAddresses,proto3" json:"network_addresses,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	unknownFields    protoimpl.UnknownFields
-	sizeCache        protoimpl.SizeCache
+type GetKeygenStatusResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        SessionStatus          `protobuf:"varint,1,opt,name=status,proto3,enum=validator.SessionStatus" json:"status,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"` // Set when the keygen failed
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *BlockchainAddressMap) Reset() {
-	*x = BlockchainAddressMap{}
+func (x *GetKeygenStatusResponse) Reset() {
+	*x = GetKeygenStatusResponse{}
 	mi := &file_libs_proto_validator_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *BlockchainAddressMap) String() string {
+func (x *GetKeygenStatusResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*BlockchainAddressMap) ProtoMessage() {}
+func (*GetKeygenStatusResponse) ProtoMessage() {}
 
-func (x *BlockchainAddressMap) ProtoReflect() protoreflect.Message {
+func (x *GetKeygenStatusResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_libs_proto_validator_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -934,42 +1003,51 @@ func (x *BlockchainAddressMap) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use BlockchainAddressMap.ProtoReflect.Descriptor instead.
-func (*BlockchainAddressMap) Descriptor() ([]byte, []int) {
+// Deprecated: Use GetKeygenStatusResponse.ProtoReflect.Descriptor instead.
+func (*GetKeygenStatusResponse) Descriptor() ([]byte, []int) {
 	return file_libs_proto_validator_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *BlockchainAddressMap) GetNetworkAddresses() map[int32]*AddressDetail {
+func (x *GetKeygenStatusResponse) GetStatus() SessionStatus {
 	if x != nil {
-		return x.NetworkAddresses
+		return x.Status
 	}
-	return nil
+	return SessionStatus_SESSION_STATUS_UNSPECIFIED
 }
 
-type GetAddressesResponse struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// Key is BlockchainID enum value
-	Addresses     map[int32]*BlockchainAddressMap `protobuf:"bytes,1,rep,name=addresses,proto3" json:"addresses,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	EddsaAddress  string                          `protobuf:"bytes,2,opt,name=eddsa_address,json=eddsaAddress,proto3" json:"eddsa_address,omitempty"`
-	EcdsaAddress  string                          `protobuf:"bytes,3,opt,name=ecdsa_address,json=ecdsaAddress,proto3" json:"ecdsa_address,omitempty"`
+func (x *GetKeygenStatusResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Reshare - Moves a wallet's key shares to a new committee, keeping its public keys
+type ReshareRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Identity      string                 `protobuf:"bytes,1,opt,name=identity,proto3" json:"identity,omitempty"`
+	IdentityCurve Curve                  `protobuf:"varint,2,opt,name=identity_curve,json=identityCurve,proto3,enum=validator.Curve" json:"identity_curve,omitempty"`
+	OldSigners    []string               `protobuf:"bytes,3,rep,name=old_signers,json=oldSigners,proto3" json:"old_signers,omitempty"`
+	NewSigners    []string               `protobuf:"bytes,4,rep,name=new_signers,json=newSigners,proto3" json:"new_signers,omitempty"`
+	NewThreshold  uint32                 `protobuf:"varint,5,opt,name=new_threshold,json=newThreshold,proto3" json:"new_threshold,omitempty"` // 0 uses the default for the new committee
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetAddressesResponse) Reset() {
-	*x = GetAddressesResponse{}
+func (x *ReshareRequest) Reset() {
+	*x = ReshareRequest{}
 	mi := &file_libs_proto_validator_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetAddressesResponse) String() string {
+func (x *ReshareRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetAddressesResponse) ProtoMessage() {}
+func (*ReshareRequest) ProtoMessage() {}
 
-func (x *GetAddressesResponse) ProtoReflect() protoreflect.Message {
+func (x *ReshareRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_libs_proto_validator_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -981,55 +1059,67 @@ func (x *GetAddressesResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetAddressesResponse.ProtoReflect.Descriptor instead.
-func (*GetAddressesResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use ReshareRequest.ProtoReflect.Descriptor instead.
+func (*ReshareRequest) Descriptor() ([]byte, []int) {
 	return file_libs_proto_validator_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *GetAddressesResponse) GetAddresses() map[int32]*BlockchainAddressMap {
+func (x *ReshareRequest) GetIdentity() string {
 	if x != nil {
-		return x.Addresses
+		return x.Identity
+	}
+	return ""
+}
+
+func (x *ReshareRequest) GetIdentityCurve() Curve {
+	if x != nil {
+		return x.IdentityCurve
+	}
+	return Curve_CURVE_UNSPECIFIED
+}
+
+func (x *ReshareRequest) GetOldSigners() []string {
+	if x != nil {
+		return x.OldSigners
 	}
 	return nil
 }
 
-func (x *GetAddressesResponse) GetEddsaAddress() string {
+func (x *ReshareRequest) GetNewSigners() []string {
 	if x != nil {
-		return x.EddsaAddress
+		return x.NewSigners
 	}
-	return ""
+	return nil
 }
 
-func (x *GetAddressesResponse) GetEcdsaAddress() string {
+func (x *ReshareRequest) GetNewThreshold() uint32 {
 	if x != nil {
-		return x.EcdsaAddress
+		return x.NewThreshold
 	}
-	return ""
+	return 0
 }
 
-// SignIntentOperation (/signature)
-type SignIntentOperationRequest struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	Intent         *Intent                `protobuf:"bytes,1,opt,name=intent,proto3" json:"intent,omitempty"`                                        // Pass the whole intent object
-	OperationIndex uint32                 `protobuf:"varint,2,opt,name=operation_index,json=operationIndex,proto3" json:"operation_index,omitempty"` // From query param
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+type ReshareResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SignIntentOperationRequest) Reset() {
-	*x = SignIntentOperationRequest{}
+func (x *ReshareResponse) Reset() {
+	*x = ReshareResponse{}
 	mi := &file_libs_proto_validator_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SignIntentOperationRequest) String() string {
+func (x *ReshareResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SignIntentOperationRequest) ProtoMessage() {}
+func (*ReshareResponse) ProtoMessage() {}
 
-func (x *SignIntentOperationRequest) ProtoReflect() protoreflect.Message {
+func (x *ReshareResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_libs_proto_validator_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1041,47 +1131,42 @@ func (x *SignIntentOperationRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SignIntentOperationRequest.ProtoReflect.Descriptor instead.
-func (*SignIntentOperationRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use ReshareResponse.ProtoReflect.Descriptor instead.
+func (*ReshareResponse) Descriptor() ([]byte, []int) {
 	return file_libs_proto_validator_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *SignIntentOperationRequest) GetIntent() *Intent {
-	if x != nil {
-		return x.Intent
-	}
-	return nil
-}
-
-func (x *SignIntentOperationRequest) GetOperationIndex() uint32 {
+func (x *ReshareResponse) GetMessage() string {
 	if x != nil {
-		return x.OperationIndex
+		return x.Message
 	}
-	return 0
+	return ""
 }
 
-// Mirrors SignatureResponse struct
-type SignIntentOperationResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Signature     string                 `protobuf:"bytes,1,opt,name=signature,proto3" json:"signature,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+// GetAddresses (/address)
+type GetAddressesRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Identity       string                 `protobuf:"bytes,1,opt,name=identity,proto3" json:"identity,omitempty"`
+	IdentityCurve  Curve                  `protobuf:"varint,2,opt,name=identity_curve,json=identityCurve,proto3,enum=validator.Curve" json:"identity_curve,omitempty"` // Changed from string query param
+	DerivationPath string                 `protobuf:"bytes,3,opt,name=derivation_path,json=derivationPath,proto3" json:"derivation_path,omitempty"`                    // Non-hardened BIP32 path of the child key, empty for the wallet itself
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
-func (x *SignIntentOperationResponse) Reset() {
-	*x = SignIntentOperationResponse{}
+func (x *GetAddressesRequest) Reset() {
+	*x = GetAddressesRequest{}
 	mi := &file_libs_proto_validator_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SignIntentOperationResponse) String() string {
+func (x *GetAddressesRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SignIntentOperationResponse) ProtoMessage() {}
+func (*GetAddressesRequest) ProtoMessage() {}
 
-func (x *SignIntentOperationResponse) ProtoReflect() protoreflect.Message {
+func (x *GetAddressesRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_libs_proto_validator_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1093,67 +1178,1537 @@ func (x *SignIntentOperationResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SignIntentOperationResponse.ProtoReflect.Descriptor instead.
-func (*SignIntentOperationResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use GetAddressesRequest.ProtoReflect.Descriptor instead.
+func (*GetAddressesRequest) Descriptor() ([]byte, []int) {
 	return file_libs_proto_validator_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *SignIntentOperationResponse) GetSignature() string {
+func (x *GetAddressesRequest) GetIdentity() string {
 	if x != nil {
-		return x.Signature
+		return x.Identity
 	}
 	return ""
 }
 
-var File_libs_proto_validator_proto protoreflect.FileDescriptor
+func (x *GetAddressesRequest) GetIdentityCurve() Curve {
+	if x != nil {
+		return x.IdentityCurve
+	}
+	return Curve_CURVE_UNSPECIFIED
+}
 
-const file_libs_proto_validator_proto_rawDesc = "" +
-	"\n" +
-	"\x1alibs/proto/validator.proto\x12\tvalidator\x1a\x1fgoogle/protobuf/timestamp.proto\"\xf6\x03\n" +
-	"\tOperation\x12\x0e\n" +
-	"\x02ID\x18\x01 \x01(\x05R\x02ID\x12,\n" +
-	"\x04type\x18\x02 \x01(\x0e2\x18.validator.OperationTypeR\x04type\x12<\n" +
-	"\rblockchain_id\x18\x03 \x01(\x0e2\x17.validator.BlockchainIDR\fblockchainId\x129\n" +
-	"\fnetwork_type\x18\x04 \x01(\x0e2\x16.validator.NetworkTypeR\vnetworkType\x12\x16\n" +
-	"\x06result\x18\x05 \x01(\tR\x06result\x12%\n" +
-	"\x0eserialized_txn\x18\x06 \x01(\tR\rserializedTxn\x12)\n" +
-	"\x06solver\x18\a \x01(\v2\x11.validator.SolverR\x06solver\x12%\n" +
-	"\fdata_to_sign\x18\b \x01(\tH\x00R\n" +
-	"dataToSign\x88\x01\x01\x122\n" +
-	"\x06status\x18\t \x01(\x0e2\x1a.validator.OperationStatusR\x06status\x12!\n" +
-	"\fgenesis_hash\x18\n" +
-	" \x01(\tR\vgenesisHash\x129\n" +
-	"\n" +
-	"created_at\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAtB\x0f\n" +
-	"\r_data_to_sign\"v\n" +
-	"\x06Solver\x12\x16\n" +
-	"\x06domain\x18\x01 \x01(\tR\x06domain\x12\x1a\n" +
-	"\bmetadata\x18\x02 \x01(\tR\bmetadata\x12 \n" +
-	"\fdata_to_sign\x18\x03 \x01(\tR\n" +
-	"dataToSign\x12\x16\n" +
-	"\x06output\x18\x04 \x01(\tR\x06output\"\xa1\x03\n" +
-	"\x06Intent\x12\x0e\n" +
-	"\x02ID\x18\x01 \x01(\tR\x02ID\x12\x1a\n" +
-	"\bidentity\x18\x02 \x01(\tR\bidentity\x12<\n" +
-	"\rblockchain_id\x18\x03 \x01(\x0e2\x17.validator.BlockchainIDR\fblockchainId\x129\n" +
-	"\fnetwork_type\x18\x04 \x01(\x0e2\x16.validator.NetworkTypeR\vnetworkType\x124\n" +
-	"\n" +
-	"operations\x18\x05 \x03(\v2\x14.validator.OperationR\n" +
-	"operations\x122\n" +
-	"\x06expiry\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\x06expiry\x12\x1c\n" +
-	"\tsignature\x18\a \x01(\tR\tsignature\x12/\n" +
-	"\x06status\x18\b \x01(\x0e2\x17.validator.IntentStatusR\x06status\x129\n" +
-	"\n" +
-	"created_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"~\n" +
+func (x *GetAddressesRequest) GetDerivationPath() string {
+	if x != nil {
+		return x.DerivationPath
+	}
+	return ""
+}
+
+type AddressDetail struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	NetworkType   NetworkType            `protobuf:"varint,1,opt,name=network_type,json=networkType,proto3,enum=validator.NetworkType" json:"network_type,omitempty"`
+	Address       string                 `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddressDetail) Reset() {
+	*x = AddressDetail{}
+	mi := &file_libs_proto_validator_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddressDetail) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddressDetail) ProtoMessage() {}
+
+func (x *AddressDetail) ProtoReflect() protoreflect.Message {
+	mi := &file_libs_proto_validator_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddressDetail.ProtoReflect.Descriptor instead.
+func (*AddressDetail) Descriptor() ([]byte, []int) {
+	return file_libs_proto_validator_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *AddressDetail) GetNetworkType() NetworkType {
+	if x != nil {
+		return x.NetworkType
+	}
+	return NetworkType_NETWORK_TYPE_UNSPECIFIED
+}
+
+func (x *AddressDetail) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+// Using nested maps to represent map[BlockchainID]map[NetworkType]string
+type BlockchainAddressMap struct {
+	state            protoimpl.MessageState   `protogen:"open.v1"`
+	NetworkAddresses map[int32]*AddressDetail `protobuf:"bytes,1,rep,name=network_addresses,json=networkAddresses,proto3" json:"network_addresses,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *BlockchainAddressMap) Reset() {
+	*x = BlockchainAddressMap{}
+	mi := &file_libs_proto_validator_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BlockchainAddressMap) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlockchainAddressMap) ProtoMessage() {}
+
+func (x *BlockchainAddressMap) ProtoReflect() protoreflect.Message {
+	mi := &file_libs_proto_validator_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BlockchainAddressMap.ProtoReflect.Descriptor instead.
+func (*BlockchainAddressMap) Descriptor() ([]byte, []int) {
+	return file_libs_proto_validator_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *BlockchainAddressMap) GetNetworkAddresses() map[int32]*AddressDetail {
+	if x != nil {
+		return x.NetworkAddresses
+	}
+	return nil
+}
+
+type GetAddressesResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Key is BlockchainID enum value
+	Addresses     map[int32]*BlockchainAddressMap `protobuf:"bytes,1,rep,name=addresses,proto3" json:"addresses,omitempty" protobuf_key:"varint,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	EddsaAddress  string                          `protobuf:"bytes,2,opt,name=eddsa_address,json=eddsaAddress,proto3" json:"eddsa_address,omitempty"`
+	EcdsaAddress  string                          `protobuf:"bytes,3,opt,name=ecdsa_address,json=ecdsaAddress,proto3" json:"ecdsa_address,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAddressesResponse) Reset() {
+	*x = GetAddressesResponse{}
+	mi := &file_libs_proto_validator_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAddressesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAddressesResponse) ProtoMessage() {}
+
+func (x *GetAddressesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_libs_proto_validator_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAddressesResponse.ProtoReflect.Descriptor instead.
+func (*GetAddressesResponse) Descriptor() ([]byte, []int) {
+	return file_libs_proto_validator_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetAddressesResponse) GetAddresses() map[int32]*BlockchainAddressMap {
+	if x != nil {
+		return x.Addresses
+	}
+	return nil
+}
+
+func (x *GetAddressesResponse) GetEddsaAddress() string {
+	if x != nil {
+		return x.EddsaAddress
+	}
+	return ""
+}
+
+func (x *GetAddressesResponse) GetEcdsaAddress() string {
+	if x != nil {
+		return x.EcdsaAddress
+	}
+	return ""
+}
+
+// SignIntentOperation (/signature)
+type SignIntentOperationRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Intent         *Intent                `protobuf:"bytes,1,opt,name=intent,proto3" json:"intent,omitempty"`                                        // Pass the whole intent object
+	OperationIndex uint32                 `protobuf:"varint,2,opt,name=operation_index,json=operationIndex,proto3" json:"operation_index,omitempty"` // From query param
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *SignIntentOperationRequest) Reset() {
+	*x = SignIntentOperationRequest{}
+	mi := &file_libs_proto_validator_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignIntentOperationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignIntentOperationRequest) ProtoMessage() {}
+
+func (x *SignIntentOperationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_libs_proto_validator_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignIntentOperationRequest.ProtoReflect.Descriptor instead.
+func (*SignIntentOperationRequest) Descriptor() ([]byte, []int) {
+	return file_libs_proto_validator_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *SignIntentOperationRequest) GetIntent() *Intent {
+	if x != nil {
+		return x.Intent
+	}
+	return nil
+}
+
+func (x *SignIntentOperationRequest) GetOperationIndex() uint32 {
+	if x != nil {
+		return x.OperationIndex
+	}
+	return 0
+}
+
+// Mirrors SignatureResponse struct
+type SignIntentOperationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Signature     string                 `protobuf:"bytes,1,opt,name=signature,proto3" json:"signature,omitempty"`
+	Receipt       *SigningReceipt        `protobuf:"bytes,2,opt,name=receipt,proto3" json:"receipt,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SignIntentOperationResponse) Reset() {
+	*x = SignIntentOperationResponse{}
+	mi := &file_libs_proto_validator_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignIntentOperationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignIntentOperationResponse) ProtoMessage() {}
+
+func (x *SignIntentOperationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_libs_proto_validator_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignIntentOperationResponse.ProtoReflect.Descriptor instead.
+func (*SignIntentOperationResponse) Descriptor() ([]byte, []int) {
+	return file_libs_proto_validator_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *SignIntentOperationResponse) GetSignature() string {
+	if x != nil {
+		return x.Signature
+	}
+	return ""
+}
+
+func (x *SignIntentOperationResponse) GetReceipt() *SigningReceipt {
+	if x != nil {
+		return x.Receipt
+	}
+	return nil
+}
+
+// SigningReceipt records a completed signing. Every validator that took part co-signs the
+// digest of the receipt, see libs.SigningReceiptDigest, with its node key.
+type SigningReceipt struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	SessionId      string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	IntentId       string                 `protobuf:"bytes,2,opt,name=intent_id,json=intentId,proto3" json:"intent_id,omitempty"`
+	OperationIndex uint32                 `protobuf:"varint,3,opt,name=operation_index,json=operationIndex,proto3" json:"operation_index,omitempty"`
+	MessageHash    string                 `protobuf:"bytes,4,opt,name=message_hash,json=messageHash,proto3" json:"message_hash,omitempty"`            // SHA-256 of the signed message, hex
+	Signers        []string               `protobuf:"bytes,5,rep,name=signers,proto3" json:"signers,omitempty"`                                       // Public keys of the participating validators
+	Signature      string                 `protobuf:"bytes,6,opt,name=signature,proto3" json:"signature,omitempty"`                                   // TSS signature, hex: R || S, followed by the recovery byte for ECDSA
+	GroupPublicKey string                 `protobuf:"bytes,7,opt,name=group_public_key,json=groupPublicKey,proto3" json:"group_public_key,omitempty"` // Public key of the signing key, child keys included, hex
+	CoSignatures   []*ReceiptCoSignature  `protobuf:"bytes,8,rep,name=co_signatures,json=coSignatures,proto3" json:"co_signatures,omitempty"`
+	Timestamp      int64                  `protobuf:"varint,9,opt,name=timestamp,proto3" json:"timestamp,omitempty"` // Unix time in seconds the signing started, checked by every signer against its clock
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *SigningReceipt) Reset() {
+	*x = SigningReceipt{}
+	mi := &file_libs_proto_validator_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SigningReceipt) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SigningReceipt) ProtoMessage() {}
+
+func (x *SigningReceipt) ProtoReflect() protoreflect.Message {
+	mi := &file_libs_proto_validator_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SigningReceipt.ProtoReflect.Descriptor instead.
+func (*SigningReceipt) Descriptor() ([]byte, []int) {
+	return file_libs_proto_validator_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *SigningReceipt) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *SigningReceipt) GetIntentId() string {
+	if x != nil {
+		return x.IntentId
+	}
+	return ""
+}
+
+func (x *SigningReceipt) GetOperationIndex() uint32 {
+	if x != nil {
+		return x.OperationIndex
+	}
+	return 0
+}
+
+func (x *SigningReceipt) GetMessageHash() string {
+	if x != nil {
+		return x.MessageHash
+	}
+	return ""
+}
+
+func (x *SigningReceipt) GetSigners() []string {
+	if x != nil {
+		return x.Signers
+	}
+	return nil
+}
+
+func (x *SigningReceipt) GetSignature() string {
+	if x != nil {
+		return x.Signature
+	}
+	return ""
+}
+
+func (x *SigningReceipt) GetGroupPublicKey() string {
+	if x != nil {
+		return x.GroupPublicKey
+	}
+	return ""
+}
+
+func (x *SigningReceipt) GetCoSignatures() []*ReceiptCoSignature {
+	if x != nil {
+		return x.CoSignatures
+	}
+	return nil
+}
+
+func (x *SigningReceipt) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+type ReceiptCoSignature struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Signer        string                 `protobuf:"bytes,1,opt,name=signer,proto3" json:"signer,omitempty"`       // Public key of the validator
+	Signature     []byte                 `protobuf:"bytes,2,opt,name=signature,proto3" json:"signature,omitempty"` // Recoverable secp256k1 signature of the receipt digest
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReceiptCoSignature) Reset() {
+	*x = ReceiptCoSignature{}
+	mi := &file_libs_proto_validator_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReceiptCoSignature) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReceiptCoSignature) ProtoMessage() {}
+
+func (x *ReceiptCoSignature) ProtoReflect() protoreflect.Message {
+	mi := &file_libs_proto_validator_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReceiptCoSignature.ProtoReflect.Descriptor instead.
+func (*ReceiptCoSignature) Descriptor() ([]byte, []int) {
+	return file_libs_proto_validator_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ReceiptCoSignature) GetSigner() string {
+	if x != nil {
+		return x.Signer
+	}
+	return ""
+}
+
+func (x *ReceiptCoSignature) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+// BlameReport identifies the signers at fault for a failed TSS round. It is attached as a
+// detail to the Aborted error of the keygen or signing call that failed, and signed by the
+// validator that reports it.
+type BlameReport struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Task          string                 `protobuf:"bytes,2,opt,name=task,proto3" json:"task,omitempty"` // tss-lib task, e.g. ecdsa-signing
+	Round         int32                  `protobuf:"varint,3,opt,name=round,proto3" json:"round,omitempty"`
+	Culprits      []string               `protobuf:"bytes,4,rep,name=culprits,proto3" json:"culprits,omitempty"` // Public keys of the signers at fault
+	Reason        string                 `protobuf:"bytes,5,opt,name=reason,proto3" json:"reason,omitempty"`
+	Reporter      string                 `protobuf:"bytes,6,opt,name=reporter,proto3" json:"reporter,omitempty"`   // Public key of the validator
+	Signature     []byte                 `protobuf:"bytes,7,opt,name=signature,proto3" json:"signature,omitempty"` // Recoverable secp256k1 signature of the report digest
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BlameReport) Reset() {
+	*x = BlameReport{}
+	mi := &file_libs_proto_validator_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BlameReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlameReport) ProtoMessage() {}
+
+func (x *BlameReport) ProtoReflect() protoreflect.Message {
+	mi := &file_libs_proto_validator_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BlameReport.ProtoReflect.Descriptor instead.
+func (*BlameReport) Descriptor() ([]byte, []int) {
+	return file_libs_proto_validator_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *BlameReport) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *BlameReport) GetTask() string {
+	if x != nil {
+		return x.Task
+	}
+	return ""
+}
+
+func (x *BlameReport) GetRound() int32 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
+func (x *BlameReport) GetCulprits() []string {
+	if x != nil {
+		return x.Culprits
+	}
+	return nil
+}
+
+func (x *BlameReport) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *BlameReport) GetReporter() string {
+	if x != nil {
+		return x.Reporter
+	}
+	return ""
+}
+
+func (x *BlameReport) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+// GetBlameReport returns the blame a validator found for a session it took part in
+type GetBlameReportRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBlameReportRequest) Reset() {
+	*x = GetBlameReportRequest{}
+	mi := &file_libs_proto_validator_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBlameReportRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBlameReportRequest) ProtoMessage() {}
+
+func (x *GetBlameReportRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_libs_proto_validator_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBlameReportRequest.ProtoReflect.Descriptor instead.
+func (*GetBlameReportRequest) Descriptor() ([]byte, []int) {
+	return file_libs_proto_validator_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *GetBlameReportRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+type GetBlameReportResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Report        *BlameReport           `protobuf:"bytes,1,opt,name=report,proto3" json:"report,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBlameReportResponse) Reset() {
+	*x = GetBlameReportResponse{}
+	mi := &file_libs_proto_validator_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBlameReportResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBlameReportResponse) ProtoMessage() {}
+
+func (x *GetBlameReportResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_libs_proto_validator_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBlameReportResponse.ProtoReflect.Descriptor instead.
+func (*GetBlameReportResponse) Descriptor() ([]byte, []int) {
+	return file_libs_proto_validator_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *GetBlameReportResponse) GetReport() *BlameReport {
+	if x != nil {
+		return x.Report
+	}
+	return nil
+}
+
+// SignBatch signs several hashes of one operation with the same wallet, e.g. one per transaction input
+type SignBatchRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Intent         *Intent                `protobuf:"bytes,1,opt,name=intent,proto3" json:"intent,omitempty"`
+	OperationIndex uint32                 `protobuf:"varint,2,opt,name=operation_index,json=operationIndex,proto3" json:"operation_index,omitempty"`
+	Messages       []string               `protobuf:"bytes,3,rep,name=messages,proto3" json:"messages,omitempty"` // Hashes listed in the operation's data to sign
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *SignBatchRequest) Reset() {
+	*x = SignBatchRequest{}
+	mi := &file_libs_proto_validator_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignBatchRequest) ProtoMessage() {}
+
+func (x *SignBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_libs_proto_validator_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignBatchRequest.ProtoReflect.Descriptor instead.
+func (*SignBatchRequest) Descriptor() ([]byte, []int) {
+	return file_libs_proto_validator_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *SignBatchRequest) GetIntent() *Intent {
+	if x != nil {
+		return x.Intent
+	}
+	return nil
+}
+
+func (x *SignBatchRequest) GetOperationIndex() uint32 {
+	if x != nil {
+		return x.OperationIndex
+	}
+	return 0
+}
+
+func (x *SignBatchRequest) GetMessages() []string {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+type SignBatchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Signatures    []string               `protobuf:"bytes,1,rep,name=signatures,proto3" json:"signatures,omitempty"` // In the order of the messages
+	Receipts      []*SigningReceipt      `protobuf:"bytes,2,rep,name=receipts,proto3" json:"receipts,omitempty"`     // In the order of the messages, empty when not collected
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SignBatchResponse) Reset() {
+	*x = SignBatchResponse{}
+	mi := &file_libs_proto_validator_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignBatchResponse) ProtoMessage() {}
+
+func (x *SignBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_libs_proto_validator_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignBatchResponse.ProtoReflect.Descriptor instead.
+func (*SignBatchResponse) Descriptor() ([]byte, []int) {
+	return file_libs_proto_validator_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *SignBatchResponse) GetSignatures() []string {
+	if x != nil {
+		return x.Signatures
+	}
+	return nil
+}
+
+func (x *SignBatchResponse) GetReceipts() []*SigningReceipt {
+	if x != nil {
+		return x.Receipts
+	}
+	return nil
+}
+
+type ListKeySharesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListKeySharesRequest) Reset() {
+	*x = ListKeySharesRequest{}
+	mi := &file_libs_proto_validator_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListKeySharesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListKeySharesRequest) ProtoMessage() {}
+
+func (x *ListKeySharesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_libs_proto_validator_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListKeySharesRequest.ProtoReflect.Descriptor instead.
+func (*ListKeySharesRequest) Descriptor() ([]byte, []int) {
+	return file_libs_proto_validator_proto_rawDescGZIP(), []int{23}
+}
+
+// KeyShareInfo describes a key share held by the validator, without any secret
+type KeyShareInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Identity      string                 `protobuf:"bytes,1,opt,name=identity,proto3" json:"identity,omitempty"`
+	IdentityCurve Curve                  `protobuf:"varint,2,opt,name=identity_curve,json=identityCurve,proto3,enum=validator.Curve" json:"identity_curve,omitempty"`
+	KeyCurve      Curve                  `protobuf:"varint,3,opt,name=key_curve,json=keyCurve,proto3,enum=validator.Curve" json:"key_curve,omitempty"`
+	Signers       []string               `protobuf:"bytes,4,rep,name=signers,proto3" json:"signers,omitempty"`
+	Threshold     uint32                 `protobuf:"varint,5,opt,name=threshold,proto3" json:"threshold,omitempty"`
+	CreatedAt     *timestamp.Timestamp   `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"` // Unset for shares stored before it was recorded
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *KeyShareInfo) Reset() {
+	*x = KeyShareInfo{}
+	mi := &file_libs_proto_validator_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *KeyShareInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KeyShareInfo) ProtoMessage() {}
+
+func (x *KeyShareInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_libs_proto_validator_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KeyShareInfo.ProtoReflect.Descriptor instead.
+func (*KeyShareInfo) Descriptor() ([]byte, []int) {
+	return file_libs_proto_validator_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *KeyShareInfo) GetIdentity() string {
+	if x != nil {
+		return x.Identity
+	}
+	return ""
+}
+
+func (x *KeyShareInfo) GetIdentityCurve() Curve {
+	if x != nil {
+		return x.IdentityCurve
+	}
+	return Curve_CURVE_UNSPECIFIED
+}
+
+func (x *KeyShareInfo) GetKeyCurve() Curve {
+	if x != nil {
+		return x.KeyCurve
+	}
+	return Curve_CURVE_UNSPECIFIED
+}
+
+func (x *KeyShareInfo) GetSigners() []string {
+	if x != nil {
+		return x.Signers
+	}
+	return nil
+}
+
+func (x *KeyShareInfo) GetThreshold() uint32 {
+	if x != nil {
+		return x.Threshold
+	}
+	return 0
+}
+
+func (x *KeyShareInfo) GetCreatedAt() *timestamp.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type ListKeySharesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	KeyShares     []*KeyShareInfo        `protobuf:"bytes,1,rep,name=key_shares,json=keyShares,proto3" json:"key_shares,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListKeySharesResponse) Reset() {
+	*x = ListKeySharesResponse{}
+	mi := &file_libs_proto_validator_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListKeySharesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListKeySharesResponse) ProtoMessage() {}
+
+func (x *ListKeySharesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_libs_proto_validator_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListKeySharesResponse.ProtoReflect.Descriptor instead.
+func (*ListKeySharesResponse) Descriptor() ([]byte, []int) {
+	return file_libs_proto_validator_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ListKeySharesResponse) GetKeyShares() []*KeyShareInfo {
+	if x != nil {
+		return x.KeyShares
+	}
+	return nil
+}
+
+type ListSessionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSessionsRequest) Reset() {
+	*x = ListSessionsRequest{}
+	mi := &file_libs_proto_validator_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSessionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSessionsRequest) ProtoMessage() {}
+
+func (x *ListSessionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_libs_proto_validator_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSessionsRequest.ProtoReflect.Descriptor instead.
+func (*ListSessionsRequest) Descriptor() ([]byte, []int) {
+	return file_libs_proto_validator_proto_rawDescGZIP(), []int{26}
+}
+
+// SessionInfo describes a TSS session the validator runs or waits for
+type SessionInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"` // keygen, signing or reshare, empty while the local party is not started
+	Status        string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	Identity      string                 `protobuf:"bytes,4,opt,name=identity,proto3" json:"identity,omitempty"`
+	KeyCurve      Curve                  `protobuf:"varint,5,opt,name=key_curve,json=keyCurve,proto3,enum=validator.Curve" json:"key_curve,omitempty"`
+	Participants  []string               `protobuf:"bytes,6,rep,name=participants,proto3" json:"participants,omitempty"` // Public keys of the signers
+	Round         int32                  `protobuf:"varint,7,opt,name=round,proto3" json:"round,omitempty"`              // Current round of the local party, 0 when it is not running
+	StartedAt     *timestamp.Timestamp   `protobuf:"bytes,8,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	Error         string                 `protobuf:"bytes,9,opt,name=error,proto3" json:"error,omitempty"` // Why a failed session failed
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SessionInfo) Reset() {
+	*x = SessionInfo{}
+	mi := &file_libs_proto_validator_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SessionInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SessionInfo) ProtoMessage() {}
+
+func (x *SessionInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_libs_proto_validator_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SessionInfo.ProtoReflect.Descriptor instead.
+func (*SessionInfo) Descriptor() ([]byte, []int) {
+	return file_libs_proto_validator_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *SessionInfo) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *SessionInfo) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *SessionInfo) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *SessionInfo) GetIdentity() string {
+	if x != nil {
+		return x.Identity
+	}
+	return ""
+}
+
+func (x *SessionInfo) GetKeyCurve() Curve {
+	if x != nil {
+		return x.KeyCurve
+	}
+	return Curve_CURVE_UNSPECIFIED
+}
+
+func (x *SessionInfo) GetParticipants() []string {
+	if x != nil {
+		return x.Participants
+	}
+	return nil
+}
+
+func (x *SessionInfo) GetRound() int32 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
+func (x *SessionInfo) GetStartedAt() *timestamp.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *SessionInfo) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ListSessionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sessions      []*SessionInfo         `protobuf:"bytes,1,rep,name=sessions,proto3" json:"sessions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSessionsResponse) Reset() {
+	*x = ListSessionsResponse{}
+	mi := &file_libs_proto_validator_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSessionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSessionsResponse) ProtoMessage() {}
+
+func (x *ListSessionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_libs_proto_validator_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSessionsResponse.ProtoReflect.Descriptor instead.
+func (*ListSessionsResponse) Descriptor() ([]byte, []int) {
+	return file_libs_proto_validator_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ListSessionsResponse) GetSessions() []*SessionInfo {
+	if x != nil {
+		return x.Sessions
+	}
+	return nil
+}
+
+type AbortSessionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AbortSessionRequest) Reset() {
+	*x = AbortSessionRequest{}
+	mi := &file_libs_proto_validator_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AbortSessionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AbortSessionRequest) ProtoMessage() {}
+
+func (x *AbortSessionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_libs_proto_validator_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AbortSessionRequest.ProtoReflect.Descriptor instead.
+func (*AbortSessionRequest) Descriptor() ([]byte, []int) {
+	return file_libs_proto_validator_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *AbortSessionRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+type AbortSessionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"` // Status of the session after the call
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AbortSessionResponse) Reset() {
+	*x = AbortSessionResponse{}
+	mi := &file_libs_proto_validator_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AbortSessionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AbortSessionResponse) ProtoMessage() {}
+
+func (x *AbortSessionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_libs_proto_validator_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AbortSessionResponse.ProtoReflect.Descriptor instead.
+func (*AbortSessionResponse) Descriptor() ([]byte, []int) {
+	return file_libs_proto_validator_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *AbortSessionResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type GetPeersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPeersRequest) Reset() {
+	*x = GetPeersRequest{}
+	mi := &file_libs_proto_validator_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPeersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPeersRequest) ProtoMessage() {}
+
+func (x *GetPeersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_libs_proto_validator_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPeersRequest.ProtoReflect.Descriptor instead.
+func (*GetPeersRequest) Descriptor() ([]byte, []int) {
+	return file_libs_proto_validator_proto_rawDescGZIP(), []int{31}
+}
+
+type PeerInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PeerId        string                 `protobuf:"bytes,1,opt,name=peer_id,json=peerId,proto3" json:"peer_id,omitempty"`
+	Signer        string                 `protobuf:"bytes,2,opt,name=signer,proto3" json:"signer,omitempty"` // Public key of the validator behind the peer, when it is one
+	Addresses     []string               `protobuf:"bytes,3,rep,name=addresses,proto3" json:"addresses,omitempty"`
+	Connected     bool                   `protobuf:"varint,4,opt,name=connected,proto3" json:"connected,omitempty"`
+	Subscribed    bool                   `protobuf:"varint,5,opt,name=subscribed,proto3" json:"subscribed,omitempty"` // Whether the peer is on the pubsub topic
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PeerInfo) Reset() {
+	*x = PeerInfo{}
+	mi := &file_libs_proto_validator_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PeerInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PeerInfo) ProtoMessage() {}
+
+func (x *PeerInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_libs_proto_validator_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PeerInfo.ProtoReflect.Descriptor instead.
+func (*PeerInfo) Descriptor() ([]byte, []int) {
+	return file_libs_proto_validator_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *PeerInfo) GetPeerId() string {
+	if x != nil {
+		return x.PeerId
+	}
+	return ""
+}
+
+func (x *PeerInfo) GetSigner() string {
+	if x != nil {
+		return x.Signer
+	}
+	return ""
+}
+
+func (x *PeerInfo) GetAddresses() []string {
+	if x != nil {
+		return x.Addresses
+	}
+	return nil
+}
+
+func (x *PeerInfo) GetConnected() bool {
+	if x != nil {
+		return x.Connected
+	}
+	return false
+}
+
+func (x *PeerInfo) GetSubscribed() bool {
+	if x != nil {
+		return x.Subscribed
+	}
+	return false
+}
+
+type GetPeersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PeerId        string                 `protobuf:"bytes,1,opt,name=peer_id,json=peerId,proto3" json:"peer_id,omitempty"` // This validator's peer ID
+	Peers         []*PeerInfo            `protobuf:"bytes,2,rep,name=peers,proto3" json:"peers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPeersResponse) Reset() {
+	*x = GetPeersResponse{}
+	mi := &file_libs_proto_validator_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPeersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPeersResponse) ProtoMessage() {}
+
+func (x *GetPeersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_libs_proto_validator_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPeersResponse.ProtoReflect.Descriptor instead.
+func (*GetPeersResponse) Descriptor() ([]byte, []int) {
+	return file_libs_proto_validator_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *GetPeersResponse) GetPeerId() string {
+	if x != nil {
+		return x.PeerId
+	}
+	return ""
+}
+
+func (x *GetPeersResponse) GetPeers() []*PeerInfo {
+	if x != nil {
+		return x.Peers
+	}
+	return nil
+}
+
+type GetVersionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetVersionRequest) Reset() {
+	*x = GetVersionRequest{}
+	mi := &file_libs_proto_validator_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetVersionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetVersionRequest) ProtoMessage() {}
+
+func (x *GetVersionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_libs_proto_validator_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetVersionRequest.ProtoReflect.Descriptor instead.
+func (*GetVersionRequest) Descriptor() ([]byte, []int) {
+	return file_libs_proto_validator_proto_rawDescGZIP(), []int{34}
+}
+
+type GetVersionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       string                 `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	Commit        string                 `protobuf:"bytes,2,opt,name=commit,proto3" json:"commit,omitempty"`
+	GoVersion     string                 `protobuf:"bytes,3,opt,name=go_version,json=goVersion,proto3" json:"go_version,omitempty"`
+	StartedAt     *timestamp.Timestamp   `protobuf:"bytes,4,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetVersionResponse) Reset() {
+	*x = GetVersionResponse{}
+	mi := &file_libs_proto_validator_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetVersionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetVersionResponse) ProtoMessage() {}
+
+func (x *GetVersionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_libs_proto_validator_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetVersionResponse.ProtoReflect.Descriptor instead.
+func (*GetVersionResponse) Descriptor() ([]byte, []int) {
+	return file_libs_proto_validator_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *GetVersionResponse) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *GetVersionResponse) GetCommit() string {
+	if x != nil {
+		return x.Commit
+	}
+	return ""
+}
+
+func (x *GetVersionResponse) GetGoVersion() string {
+	if x != nil {
+		return x.GoVersion
+	}
+	return ""
+}
+
+func (x *GetVersionResponse) GetStartedAt() *timestamp.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+var File_libs_proto_validator_proto protoreflect.FileDescriptor
+
+const file_libs_proto_validator_proto_rawDesc = "" +
+	"\n" +
+	"\x1alibs/proto/validator.proto\x12\tvalidator\x1a\x1fgoogle/protobuf/timestamp.proto\"\x9f\x04\n" +
+	"\tOperation\x12\x0e\n" +
+	"\x02ID\x18\x01 \x01(\x05R\x02ID\x12,\n" +
+	"\x04type\x18\x02 \x01(\x0e2\x18.validator.OperationTypeR\x04type\x12<\n" +
+	"\rblockchain_id\x18\x03 \x01(\x0e2\x17.validator.BlockchainIDR\fblockchainId\x129\n" +
+	"\fnetwork_type\x18\x04 \x01(\x0e2\x16.validator.NetworkTypeR\vnetworkType\x12\x16\n" +
+	"\x06result\x18\x05 \x01(\tR\x06result\x12%\n" +
+	"\x0eserialized_txn\x18\x06 \x01(\tR\rserializedTxn\x12)\n" +
+	"\x06solver\x18\a \x01(\v2\x11.validator.SolverR\x06solver\x12%\n" +
+	"\fdata_to_sign\x18\b \x01(\tH\x00R\n" +
+	"dataToSign\x88\x01\x01\x122\n" +
+	"\x06status\x18\t \x01(\x0e2\x1a.validator.OperationStatusR\x06status\x12!\n" +
+	"\fgenesis_hash\x18\n" +
+	" \x01(\tR\vgenesisHash\x129\n" +
+	"\n" +
+	"created_at\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12'\n" +
+	"\x0fderivation_path\x18\f \x01(\tR\x0ederivationPathB\x0f\n" +
+	"\r_data_to_sign\"v\n" +
+	"\x06Solver\x12\x16\n" +
+	"\x06domain\x18\x01 \x01(\tR\x06domain\x12\x1a\n" +
+	"\bmetadata\x18\x02 \x01(\tR\bmetadata\x12 \n" +
+	"\fdata_to_sign\x18\x03 \x01(\tR\n" +
+	"dataToSign\x12\x16\n" +
+	"\x06output\x18\x04 \x01(\tR\x06output\"\xa1\x03\n" +
+	"\x06Intent\x12\x0e\n" +
+	"\x02ID\x18\x01 \x01(\tR\x02ID\x12\x1a\n" +
+	"\bidentity\x18\x02 \x01(\tR\bidentity\x12<\n" +
+	"\rblockchain_id\x18\x03 \x01(\x0e2\x17.validator.BlockchainIDR\fblockchainId\x129\n" +
+	"\fnetwork_type\x18\x04 \x01(\x0e2\x16.validator.NetworkTypeR\vnetworkType\x124\n" +
+	"\n" +
+	"operations\x18\x05 \x03(\v2\x14.validator.OperationR\n" +
+	"operations\x122\n" +
+	"\x06expiry\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\x06expiry\x12\x1c\n" +
+	"\tsignature\x18\a \x01(\tR\tsignature\x12/\n" +
+	"\x06status\x18\b \x01(\x0e2\x17.validator.IntentStatusR\x06status\x129\n" +
+	"\n" +
+	"created_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\xd3\x01\n" +
 	"\rKeygenRequest\x12\x1a\n" +
 	"\bidentity\x18\x01 \x01(\tR\bidentity\x127\n" +
 	"\x0eidentity_curve\x18\x02 \x01(\x0e2\x10.validator.CurveR\ridentityCurve\x12\x18\n" +
-	"\asigners\x18\x03 \x03(\tR\asigners\"*\n" +
+	"\asigners\x18\x03 \x03(\tR\asigners\x12\x1c\n" +
+	"\tthreshold\x18\x04 \x01(\rR\tthreshold\x125\n" +
+	"\red25519_curve\x18\x05 \x01(\x0e2\x10.validator.CurveR\fed25519Curve\"*\n" +
 	"\x0eKeygenResponse\x12\x18\n" +
-	"\amessage\x18\x01 \x01(\tR\amessage\"j\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\"4\n" +
+	"\x13StartKeygenResponse\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\"7\n" +
+	"\x16GetKeygenStatusRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\"a\n" +
+	"\x17GetKeygenStatusResponse\x120\n" +
+	"\x06status\x18\x01 \x01(\x0e2\x18.validator.SessionStatusR\x06status\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\xcc\x01\n" +
+	"\x0eReshareRequest\x12\x1a\n" +
+	"\bidentity\x18\x01 \x01(\tR\bidentity\x127\n" +
+	"\x0eidentity_curve\x18\x02 \x01(\x0e2\x10.validator.CurveR\ridentityCurve\x12\x1f\n" +
+	"\vold_signers\x18\x03 \x03(\tR\n" +
+	"oldSigners\x12\x1f\n" +
+	"\vnew_signers\x18\x04 \x03(\tR\n" +
+	"newSigners\x12#\n" +
+	"\rnew_threshold\x18\x05 \x01(\rR\fnewThreshold\"+\n" +
+	"\x0fReshareResponse\x12\x18\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\"\x93\x01\n" +
 	"\x13GetAddressesRequest\x12\x1a\n" +
 	"\bidentity\x18\x01 \x01(\tR\bidentity\x127\n" +
-	"\x0eidentity_curve\x18\x02 \x01(\x0e2\x10.validator.CurveR\ridentityCurve\"d\n" +
+	"\x0eidentity_curve\x18\x02 \x01(\x0e2\x10.validator.CurveR\ridentityCurve\x12'\n" +
+	"\x0fderivation_path\x18\x03 \x01(\tR\x0ederivationPath\"d\n" +
 	"\rAddressDetail\x129\n" +
 	"\fnetwork_type\x18\x01 \x01(\x0e2\x16.validator.NetworkTypeR\vnetworkType\x12\x18\n" +
 	"\aaddress\x18\x02 \x01(\tR\aaddress\"\xd9\x01\n" +
@@ -1171,13 +2726,104 @@ const file_libs_proto_validator_proto_rawDesc = "" +
 	"\x05value\x18\x02 \x01(\v2\x1f.validator.BlockchainAddressMapR\x05value:\x028\x01\"p\n" +
 	"\x1aSignIntentOperationRequest\x12)\n" +
 	"\x06intent\x18\x01 \x01(\v2\x11.validator.IntentR\x06intent\x12'\n" +
-	"\x0foperation_index\x18\x02 \x01(\rR\x0eoperationIndex\";\n" +
+	"\x0foperation_index\x18\x02 \x01(\rR\x0eoperationIndex\"p\n" +
 	"\x1bSignIntentOperationResponse\x12\x1c\n" +
-	"\tsignature\x18\x01 \x01(\tR\tsignature*@\n" +
+	"\tsignature\x18\x01 \x01(\tR\tsignature\x123\n" +
+	"\areceipt\x18\x02 \x01(\v2\x19.validator.SigningReceiptR\areceipt\"\xdc\x02\n" +
+	"\x0eSigningReceipt\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x1b\n" +
+	"\tintent_id\x18\x02 \x01(\tR\bintentId\x12'\n" +
+	"\x0foperation_index\x18\x03 \x01(\rR\x0eoperationIndex\x12!\n" +
+	"\fmessage_hash\x18\x04 \x01(\tR\vmessageHash\x12\x18\n" +
+	"\asigners\x18\x05 \x03(\tR\asigners\x12\x1c\n" +
+	"\tsignature\x18\x06 \x01(\tR\tsignature\x12(\n" +
+	"\x10group_public_key\x18\a \x01(\tR\x0egroupPublicKey\x12B\n" +
+	"\rco_signatures\x18\b \x03(\v2\x1d.validator.ReceiptCoSignatureR\fcoSignatures\x12\x1c\n" +
+	"\ttimestamp\x18\t \x01(\x03R\ttimestamp\"J\n" +
+	"\x12ReceiptCoSignature\x12\x16\n" +
+	"\x06signer\x18\x01 \x01(\tR\x06signer\x12\x1c\n" +
+	"\tsignature\x18\x02 \x01(\fR\tsignature\"\xc4\x01\n" +
+	"\vBlameReport\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x12\n" +
+	"\x04task\x18\x02 \x01(\tR\x04task\x12\x14\n" +
+	"\x05round\x18\x03 \x01(\x05R\x05round\x12\x1a\n" +
+	"\bculprits\x18\x04 \x03(\tR\bculprits\x12\x16\n" +
+	"\x06reason\x18\x05 \x01(\tR\x06reason\x12\x1a\n" +
+	"\breporter\x18\x06 \x01(\tR\breporter\x12\x1c\n" +
+	"\tsignature\x18\a \x01(\fR\tsignature\"6\n" +
+	"\x15GetBlameReportRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\"H\n" +
+	"\x16GetBlameReportResponse\x12.\n" +
+	"\x06report\x18\x01 \x01(\v2\x16.validator.BlameReportR\x06report\"\x82\x01\n" +
+	"\x10SignBatchRequest\x12)\n" +
+	"\x06intent\x18\x01 \x01(\v2\x11.validator.IntentR\x06intent\x12'\n" +
+	"\x0foperation_index\x18\x02 \x01(\rR\x0eoperationIndex\x12\x1a\n" +
+	"\bmessages\x18\x03 \x03(\tR\bmessages\"j\n" +
+	"\x11SignBatchResponse\x12\x1e\n" +
+	"\n" +
+	"signatures\x18\x01 \x03(\tR\n" +
+	"signatures\x125\n" +
+	"\breceipts\x18\x02 \x03(\v2\x19.validator.SigningReceiptR\breceipts\"\x16\n" +
+	"\x14ListKeySharesRequest\"\x85\x02\n" +
+	"\fKeyShareInfo\x12\x1a\n" +
+	"\bidentity\x18\x01 \x01(\tR\bidentity\x127\n" +
+	"\x0eidentity_curve\x18\x02 \x01(\x0e2\x10.validator.CurveR\ridentityCurve\x12-\n" +
+	"\tkey_curve\x18\x03 \x01(\x0e2\x10.validator.CurveR\bkeyCurve\x12\x18\n" +
+	"\asigners\x18\x04 \x03(\tR\asigners\x12\x1c\n" +
+	"\tthreshold\x18\x05 \x01(\rR\tthreshold\x129\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"O\n" +
+	"\x15ListKeySharesResponse\x126\n" +
+	"\n" +
+	"key_shares\x18\x01 \x03(\v2\x17.validator.KeyShareInfoR\tkeyShares\"\x15\n" +
+	"\x13ListSessionsRequest\"\xae\x02\n" +
+	"\vSessionInfo\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12\x1a\n" +
+	"\bidentity\x18\x04 \x01(\tR\bidentity\x12-\n" +
+	"\tkey_curve\x18\x05 \x01(\x0e2\x10.validator.CurveR\bkeyCurve\x12\"\n" +
+	"\fparticipants\x18\x06 \x03(\tR\fparticipants\x12\x14\n" +
+	"\x05round\x18\a \x01(\x05R\x05round\x129\n" +
+	"\n" +
+	"started_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x12\x14\n" +
+	"\x05error\x18\t \x01(\tR\x05error\"J\n" +
+	"\x14ListSessionsResponse\x122\n" +
+	"\bsessions\x18\x01 \x03(\v2\x16.validator.SessionInfoR\bsessions\"4\n" +
+	"\x13AbortSessionRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\".\n" +
+	"\x14AbortSessionResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\"\x11\n" +
+	"\x0fGetPeersRequest\"\x97\x01\n" +
+	"\bPeerInfo\x12\x17\n" +
+	"\apeer_id\x18\x01 \x01(\tR\x06peerId\x12\x16\n" +
+	"\x06signer\x18\x02 \x01(\tR\x06signer\x12\x1c\n" +
+	"\taddresses\x18\x03 \x03(\tR\taddresses\x12\x1c\n" +
+	"\tconnected\x18\x04 \x01(\bR\tconnected\x12\x1e\n" +
+	"\n" +
+	"subscribed\x18\x05 \x01(\bR\n" +
+	"subscribed\"V\n" +
+	"\x10GetPeersResponse\x12\x17\n" +
+	"\apeer_id\x18\x01 \x01(\tR\x06peerId\x12)\n" +
+	"\x05peers\x18\x02 \x03(\v2\x13.validator.PeerInfoR\x05peers\"\x13\n" +
+	"\x11GetVersionRequest\"\xa0\x01\n" +
+	"\x12GetVersionResponse\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\x12\x16\n" +
+	"\x06commit\x18\x02 \x01(\tR\x06commit\x12\x1d\n" +
+	"\n" +
+	"go_version\x18\x03 \x01(\tR\tgoVersion\x129\n" +
+	"\n" +
+	"started_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt*Q\n" +
 	"\x05Curve\x12\x15\n" +
 	"\x11CURVE_UNSPECIFIED\x10\x00\x12\x0f\n" +
 	"\vCURVE_ECDSA\x10\x01\x12\x0f\n" +
-	"\vCURVE_EDDSA\x10\x02*\xe2\x01\n" +
+	"\vCURVE_EDDSA\x10\x02\x12\x0f\n" +
+	"\vCURVE_FROST\x10\x03*\xe2\x01\n" +
 	"\fBlockchainID\x12\x1d\n" +
 	"\x19BLOCKCHAIN_ID_UNSPECIFIED\x10\x00\x12\v\n" +
 	"\aBITCOIN\x10\x01\x12\f\n" +
@@ -1205,7 +2851,7 @@ const file_libs_proto_validator_proto_rawDesc = "" +
 	"\n" +
 	"\x06DEVNET\x10\x03\x12\n" +
 	"\n" +
-	"\x06REGNET\x10\x04*\xaa\x01\n" +
+	"\x06REGNET\x10\x04*\xb9\x01\n" +
 	"\rOperationType\x12\x1e\n" +
 	"\x1aOPERATION_TYPE_UNSPECIFIED\x10\x00\x12\x0f\n" +
 	"\vTRANSACTION\x10\x01\x12\x12\n" +
@@ -1216,24 +2862,43 @@ const file_libs_proto_validator_proto_rawDesc = "" +
 	"\x04SWAP\x10\x05\x12\b\n" +
 	"\x04BURN\x10\x06\x12\f\n" +
 	"\bWITHDRAW\x10\a\x12\x12\n" +
-	"\x0eBURN_SYNTHETIC\x10\b*\x9d\x01\n" +
+	"\x0eBURN_SYNTHETIC\x10\b\x12\r\n" +
+	"\tTRUST_SET\x10\t*\x9d\x01\n" +
 	"\fIntentStatus\x12\x1d\n" +
 	"\x19INTENT_STATUS_UNSPECIFIED\x10\x00\x12\x1c\n" +
 	"\x18INTENT_STATUS_PROCESSING\x10\x01\x12\x1b\n" +
 	"\x17INTENT_STATUS_COMPLETED\x10\x02\x12\x18\n" +
 	"\x14INTENT_STATUS_FAILED\x10\x03\x12\x19\n" +
-	"\x15INTENT_STATUS_EXPIRED\x10\x04*\xca\x01\n" +
+	"\x15INTENT_STATUS_EXPIRED\x10\x04*\xa0\x01\n" +
+	"\rSessionStatus\x12\x1e\n" +
+	"\x1aSESSION_STATUS_UNSPECIFIED\x10\x00\x12\x1a\n" +
+	"\x16SESSION_STATUS_PENDING\x10\x01\x12\x1a\n" +
+	"\x16SESSION_STATUS_RUNNING\x10\x02\x12\x1c\n" +
+	"\x18SESSION_STATUS_COMPLETED\x10\x03\x12\x19\n" +
+	"\x15SESSION_STATUS_FAILED\x10\x04*\xca\x01\n" +
 	"\x0fOperationStatus\x12 \n" +
 	"\x1cOPERATION_STATUS_UNSPECIFIED\x10\x00\x12\x1c\n" +
 	"\x18OPERATION_STATUS_PENDING\x10\x01\x12\x1c\n" +
 	"\x18OPERATION_STATUS_WAITING\x10\x02\x12\x1e\n" +
 	"\x1aOPERATION_STATUS_COMPLETED\x10\x03\x12\x1b\n" +
 	"\x17OPERATION_STATUS_FAILED\x10\x04\x12\x1c\n" +
-	"\x18OPERATION_STATUS_EXPIRED\x10\x052\x88\x02\n" +
+	"\x18OPERATION_STATUS_EXPIRED\x10\x052\x8c\x05\n" +
 	"\x10ValidatorService\x12=\n" +
-	"\x06Keygen\x12\x18.validator.KeygenRequest\x1a\x19.validator.KeygenResponse\x12O\n" +
+	"\x06Keygen\x12\x18.validator.KeygenRequest\x1a\x19.validator.KeygenResponse\x12G\n" +
+	"\vStartKeygen\x12\x18.validator.KeygenRequest\x1a\x1e.validator.StartKeygenResponse\x12X\n" +
+	"\x0fGetKeygenStatus\x12!.validator.GetKeygenStatusRequest\x1a\".validator.GetKeygenStatusResponse\x12@\n" +
+	"\aReshare\x12\x19.validator.ReshareRequest\x1a\x1a.validator.ReshareResponse\x12O\n" +
 	"\fGetAddresses\x12\x1e.validator.GetAddressesRequest\x1a\x1f.validator.GetAddressesResponse\x12d\n" +
-	"\x13SignIntentOperation\x12%.validator.SignIntentOperationRequest\x1a&.validator.SignIntentOperationResponseB\x0eZ\f./;validatorb\x06proto3"
+	"\x13SignIntentOperation\x12%.validator.SignIntentOperationRequest\x1a&.validator.SignIntentOperationResponse\x12F\n" +
+	"\tSignBatch\x12\x1b.validator.SignBatchRequest\x1a\x1c.validator.SignBatchResponse\x12U\n" +
+	"\x0eGetBlameReport\x12 .validator.GetBlameReportRequest\x1a!.validator.GetBlameReportResponse2\x94\x03\n" +
+	"\fAdminService\x12R\n" +
+	"\rListKeyShares\x12\x1f.validator.ListKeySharesRequest\x1a .validator.ListKeySharesResponse\x12O\n" +
+	"\fListSessions\x12\x1e.validator.ListSessionsRequest\x1a\x1f.validator.ListSessionsResponse\x12O\n" +
+	"\fAbortSession\x12\x1e.validator.AbortSessionRequest\x1a\x1f.validator.AbortSessionResponse\x12C\n" +
+	"\bGetPeers\x12\x1a.validator.GetPeersRequest\x1a\x1b.validator.GetPeersResponse\x12I\n" +
+	"\n" +
+	"GetVersion\x12\x1c.validator.GetVersionRequest\x1a\x1d.validator.GetVersionResponseB\x0eZ\f./;validatorb\x06proto3"
 
 var (
 	file_libs_proto_validator_proto_rawDescOnce sync.Once
@@ -1247,62 +2912,125 @@ func file_libs_proto_validator_proto_rawDescGZIP() []byte {
 	return file_libs_proto_validator_proto_rawDescData
 }
 
-var file_libs_proto_validator_proto_enumTypes = make([]protoimpl.EnumInfo, 6)
-var file_libs_proto_validator_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_libs_proto_validator_proto_enumTypes = make([]protoimpl.EnumInfo, 7)
+var file_libs_proto_validator_proto_msgTypes = make([]protoimpl.MessageInfo, 38)
 var file_libs_proto_validator_proto_goTypes = []any{
 	(Curve)(0),                          // 0: validator.Curve
 	(BlockchainID)(0),                   // 1: validator.BlockchainID
 	(NetworkType)(0),                    // 2: validator.NetworkType
 	(OperationType)(0),                  // 3: validator.OperationType
 	(IntentStatus)(0),                   // 4: validator.IntentStatus
-	(OperationStatus)(0),                // 5: validator.OperationStatus
-	(*Operation)(nil),                   // 6: validator.Operation
-	(*Solver)(nil),                      // 7: validator.Solver
-	(*Intent)(nil),                      // 8: validator.Intent
-	(*KeygenRequest)(nil),               // 9: validator.KeygenRequest
-	(*KeygenResponse)(nil),              // 10: validator.KeygenResponse
-	(*GetAddressesRequest)(nil),         // 11: validator.GetAddressesRequest
-	(*AddressDetail)(nil),               // 12: validator.AddressDetail
-	(*BlockchainAddressMap)(nil),        // 13: validator.BlockchainAddressMap
-	(*GetAddressesResponse)(nil),        // 14: validator.GetAddressesResponse
-	(*SignIntentOperationRequest)(nil),  // 15: validator.SignIntentOperationRequest
-	(*SignIntentOperationResponse)(nil), // 16: validator.SignIntentOperationResponse
-	nil,                                 // 17: validator.BlockchainAddressMap.NetworkAddressesEntry
-	nil,                                 // 18: validator.GetAddressesResponse.AddressesEntry
-	(*timestamp.Timestamp)(nil),         // 19: google.protobuf.Timestamp
+	(SessionStatus)(0),                  // 5: validator.SessionStatus
+	(OperationStatus)(0),                // 6: validator.OperationStatus
+	(*Operation)(nil),                   // 7: validator.Operation
+	(*Solver)(nil),                      // 8: validator.Solver
+	(*Intent)(nil),                      // 9: validator.Intent
+	(*KeygenRequest)(nil),               // 10: validator.KeygenRequest
+	(*KeygenResponse)(nil),              // 11: validator.KeygenResponse
+	(*StartKeygenResponse)(nil),         // 12: validator.StartKeygenResponse
+	(*GetKeygenStatusRequest)(nil),      // 13: validator.GetKeygenStatusRequest
+	(*GetKeygenStatusResponse)(nil),     // 14: validator.GetKeygenStatusResponse
+	(*ReshareRequest)(nil),              // 15: validator.ReshareRequest
+	(*ReshareResponse)(nil),             // 16: validator.ReshareResponse
+	(*GetAddressesRequest)(nil),         // 17: validator.GetAddressesRequest
+	(*AddressDetail)(nil),               // 18: validator.AddressDetail
+	(*BlockchainAddressMap)(nil),        // 19: validator.BlockchainAddressMap
+	(*GetAddressesResponse)(nil),        // 20: validator.GetAddressesResponse
+	(*SignIntentOperationRequest)(nil),  // 21: validator.SignIntentOperationRequest
+	(*SignIntentOperationResponse)(nil), // 22: validator.SignIntentOperationResponse
+	(*SigningReceipt)(nil),              // 23: validator.SigningReceipt
+	(*ReceiptCoSignature)(nil),          // 24: validator.ReceiptCoSignature
+	(*BlameReport)(nil),                 // 25: validator.BlameReport
+	(*GetBlameReportRequest)(nil),       // 26: validator.GetBlameReportRequest
+	(*GetBlameReportResponse)(nil),      // 27: validator.GetBlameReportResponse
+	(*SignBatchRequest)(nil),            // 28: validator.SignBatchRequest
+	(*SignBatchResponse)(nil),           // 29: validator.SignBatchResponse
+	(*ListKeySharesRequest)(nil),        // 30: validator.ListKeySharesRequest
+	(*KeyShareInfo)(nil),                // 31: validator.KeyShareInfo
+	(*ListKeySharesResponse)(nil),       // 32: validator.ListKeySharesResponse
+	(*ListSessionsRequest)(nil),         // 33: validator.ListSessionsRequest
+	(*SessionInfo)(nil),                 // 34: validator.SessionInfo
+	(*ListSessionsResponse)(nil),        // 35: validator.ListSessionsResponse
+	(*AbortSessionRequest)(nil),         // 36: validator.AbortSessionRequest
+	(*AbortSessionResponse)(nil),        // 37: validator.AbortSessionResponse
+	(*GetPeersRequest)(nil),             // 38: validator.GetPeersRequest
+	(*PeerInfo)(nil),                    // 39: validator.PeerInfo
+	(*GetPeersResponse)(nil),            // 40: validator.GetPeersResponse
+	(*GetVersionRequest)(nil),           // 41: validator.GetVersionRequest
+	(*GetVersionResponse)(nil),          // 42: validator.GetVersionResponse
+	nil,                                 // 43: validator.BlockchainAddressMap.NetworkAddressesEntry
+	nil,                                 // 44: validator.GetAddressesResponse.AddressesEntry
+	(*timestamp.Timestamp)(nil),         // 45: google.protobuf.Timestamp
 }
 var file_libs_proto_validator_proto_depIdxs = []int32{
 	3,  // 0: validator.Operation.type:type_name -> validator.OperationType
 	1,  // 1: validator.Operation.blockchain_id:type_name -> validator.BlockchainID
 	2,  // 2: validator.Operation.network_type:type_name -> validator.NetworkType
-	7,  // 3: validator.Operation.solver:type_name -> validator.Solver
-	5,  // 4: validator.Operation.status:type_name -> validator.OperationStatus
-	19, // 5: validator.Operation.created_at:type_name -> google.protobuf.Timestamp
+	8,  // 3: validator.Operation.solver:type_name -> validator.Solver
+	6,  // 4: validator.Operation.status:type_name -> validator.OperationStatus
+	45, // 5: validator.Operation.created_at:type_name -> google.protobuf.Timestamp
 	1,  // 6: validator.Intent.blockchain_id:type_name -> validator.BlockchainID
 	2,  // 7: validator.Intent.network_type:type_name -> validator.NetworkType
-	6,  // 8: validator.Intent.operations:type_name -> validator.Operation
-	19, // 9: validator.Intent.expiry:type_name -> google.protobuf.Timestamp
+	7,  // 8: validator.Intent.operations:type_name -> validator.Operation
+	45, // 9: validator.Intent.expiry:type_name -> google.protobuf.Timestamp
 	4,  // 10: validator.Intent.status:type_name -> validator.IntentStatus
-	19, // 11: validator.Intent.created_at:type_name -> google.protobuf.Timestamp
+	45, // 11: validator.Intent.created_at:type_name -> google.protobuf.Timestamp
 	0,  // 12: validator.KeygenRequest.identity_curve:type_name -> validator.Curve
-	0,  // 13: validator.GetAddressesRequest.identity_curve:type_name -> validator.Curve
-	2,  // 14: validator.AddressDetail.network_type:type_name -> validator.NetworkType
-	17, // 15: validator.BlockchainAddressMap.network_addresses:type_name -> validator.BlockchainAddressMap.NetworkAddressesEntry
-	18, // 16: validator.GetAddressesResponse.addresses:type_name -> validator.GetAddressesResponse.AddressesEntry
-	8,  // 17: validator.SignIntentOperationRequest.intent:type_name -> validator.Intent
-	12, // 18: validator.BlockchainAddressMap.NetworkAddressesEntry.value:type_name -> validator.AddressDetail
-	13, // 19: validator.GetAddressesResponse.AddressesEntry.value:type_name -> validator.BlockchainAddressMap
-	9,  // 20: validator.ValidatorService.Keygen:input_type -> validator.KeygenRequest
-	11, // 21: validator.ValidatorService.GetAddresses:input_type -> validator.GetAddressesRequest
-	15, // 22: validator.ValidatorService.SignIntentOperation:input_type -> validator.SignIntentOperationRequest
-	10, // 23: validator.ValidatorService.Keygen:output_type -> validator.KeygenResponse
-	14, // 24: validator.ValidatorService.GetAddresses:output_type -> validator.GetAddressesResponse
-	16, // 25: validator.ValidatorService.SignIntentOperation:output_type -> validator.SignIntentOperationResponse
-	23, // [23:26] is the sub-list for method output_type
-	20, // [20:23] is the sub-list for method input_type
-	20, // [20:20] is the sub-list for extension type_name
-	20, // [20:20] is the sub-list for extension extendee
-	0,  // [0:20] is the sub-list for field type_name
+	0,  // 13: validator.KeygenRequest.ed25519_curve:type_name -> validator.Curve
+	5,  // 14: validator.GetKeygenStatusResponse.status:type_name -> validator.SessionStatus
+	0,  // 15: validator.ReshareRequest.identity_curve:type_name -> validator.Curve
+	0,  // 16: validator.GetAddressesRequest.identity_curve:type_name -> validator.Curve
+	2,  // 17: validator.AddressDetail.network_type:type_name -> validator.NetworkType
+	43, // 18: validator.BlockchainAddressMap.network_addresses:type_name -> validator.BlockchainAddressMap.NetworkAddressesEntry
+	44, // 19: validator.GetAddressesResponse.addresses:type_name -> validator.GetAddressesResponse.AddressesEntry
+	9,  // 20: validator.SignIntentOperationRequest.intent:type_name -> validator.Intent
+	23, // 21: validator.SignIntentOperationResponse.receipt:type_name -> validator.SigningReceipt
+	24, // 22: validator.SigningReceipt.co_signatures:type_name -> validator.ReceiptCoSignature
+	25, // 23: validator.GetBlameReportResponse.report:type_name -> validator.BlameReport
+	9,  // 24: validator.SignBatchRequest.intent:type_name -> validator.Intent
+	23, // 25: validator.SignBatchResponse.receipts:type_name -> validator.SigningReceipt
+	0,  // 26: validator.KeyShareInfo.identity_curve:type_name -> validator.Curve
+	0,  // 27: validator.KeyShareInfo.key_curve:type_name -> validator.Curve
+	45, // 28: validator.KeyShareInfo.created_at:type_name -> google.protobuf.Timestamp
+	31, // 29: validator.ListKeySharesResponse.key_shares:type_name -> validator.KeyShareInfo
+	0,  // 30: validator.SessionInfo.key_curve:type_name -> validator.Curve
+	45, // 31: validator.SessionInfo.started_at:type_name -> google.protobuf.Timestamp
+	34, // 32: validator.ListSessionsResponse.sessions:type_name -> validator.SessionInfo
+	39, // 33: validator.GetPeersResponse.peers:type_name -> validator.PeerInfo
+	45, // 34: validator.GetVersionResponse.started_at:type_name -> google.protobuf.Timestamp
+	18, // 35: validator.BlockchainAddressMap.NetworkAddressesEntry.value:type_name -> validator.AddressDetail
+	19, // 36: validator.GetAddressesResponse.AddressesEntry.value:type_name -> validator.BlockchainAddressMap
+	10, // 37: validator.ValidatorService.Keygen:input_type -> validator.KeygenRequest
+	10, // 38: validator.ValidatorService.StartKeygen:input_type -> validator.KeygenRequest
+	13, // 39: validator.ValidatorService.GetKeygenStatus:input_type -> validator.GetKeygenStatusRequest
+	15, // 40: validator.ValidatorService.Reshare:input_type -> validator.ReshareRequest
+	17, // 41: validator.ValidatorService.GetAddresses:input_type -> validator.GetAddressesRequest
+	21, // 42: validator.ValidatorService.SignIntentOperation:input_type -> validator.SignIntentOperationRequest
+	28, // 43: validator.ValidatorService.SignBatch:input_type -> validator.SignBatchRequest
+	26, // 44: validator.ValidatorService.GetBlameReport:input_type -> validator.GetBlameReportRequest
+	30, // 45: validator.AdminService.ListKeyShares:input_type -> validator.ListKeySharesRequest
+	33, // 46: validator.AdminService.ListSessions:input_type -> validator.ListSessionsRequest
+	36, // 47: validator.AdminService.AbortSession:input_type -> validator.AbortSessionRequest
+	38, // 48: validator.AdminService.GetPeers:input_type -> validator.GetPeersRequest
+	41, // 49: validator.AdminService.GetVersion:input_type -> validator.GetVersionRequest
+	11, // 50: validator.ValidatorService.Keygen:output_type -> validator.KeygenResponse
+	12, // 51: validator.ValidatorService.StartKeygen:output_type -> validator.StartKeygenResponse
+	14, // 52: validator.ValidatorService.GetKeygenStatus:output_type -> validator.GetKeygenStatusResponse
+	16, // 53: validator.ValidatorService.Reshare:output_type -> validator.ReshareResponse
+	20, // 54: validator.ValidatorService.GetAddresses:output_type -> validator.GetAddressesResponse
+	22, // 55: validator.ValidatorService.SignIntentOperation:output_type -> validator.SignIntentOperationResponse
+	29, // 56: validator.ValidatorService.SignBatch:output_type -> validator.SignBatchResponse
+	27, // 57: validator.ValidatorService.GetBlameReport:output_type -> validator.GetBlameReportResponse
+	32, // 58: validator.AdminService.ListKeyShares:output_type -> validator.ListKeySharesResponse
+	35, // 59: validator.AdminService.ListSessions:output_type -> validator.ListSessionsResponse
+	37, // 60: validator.AdminService.AbortSession:output_type -> validator.AbortSessionResponse
+	40, // 61: validator.AdminService.GetPeers:output_type -> validator.GetPeersResponse
+	42, // 62: validator.AdminService.GetVersion:output_type -> validator.GetVersionResponse
+	50, // [50:63] is the sub-list for method output_type
+	37, // [37:50] is the sub-list for method input_type
+	37, // [37:37] is the sub-list for extension type_name
+	37, // [37:37] is the sub-list for extension extendee
+	0,  // [0:37] is the sub-list for field type_name
 }
 
 func init() { file_libs_proto_validator_proto_init() }
@@ -1316,10 +3044,10 @@ func file_libs_proto_validator_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_libs_proto_validator_proto_rawDesc), len(file_libs_proto_validator_proto_rawDesc)),
-			NumEnums:      6,
-			NumMessages:   13,
+			NumEnums:      7,
+			NumMessages:   38,
 			NumExtensions: 0,
-			NumServices:   1,
+			NumServices:   2,
 		},
 		GoTypes:           file_libs_proto_validator_proto_goTypes,
 		DependencyIndexes: file_libs_proto_validator_proto_depIdxs,
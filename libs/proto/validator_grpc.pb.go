@@ -20,8 +20,13 @@ const _ = grpc.SupportPackageIsVersion9
 
 const (
 	ValidatorService_Keygen_FullMethodName              = "/validator.ValidatorService/Keygen"
+	ValidatorService_StartKeygen_FullMethodName         = "/validator.ValidatorService/StartKeygen"
+	ValidatorService_GetKeygenStatus_FullMethodName     = "/validator.ValidatorService/GetKeygenStatus"
+	ValidatorService_Reshare_FullMethodName             = "/validator.ValidatorService/Reshare"
 	ValidatorService_GetAddresses_FullMethodName        = "/validator.ValidatorService/GetAddresses"
 	ValidatorService_SignIntentOperation_FullMethodName = "/validator.ValidatorService/SignIntentOperation"
+	ValidatorService_SignBatch_FullMethodName           = "/validator.ValidatorService/SignBatch"
+	ValidatorService_GetBlameReport_FullMethodName      = "/validator.ValidatorService/GetBlameReport"
 )
 
 // ValidatorServiceClient is the client API for ValidatorService service.
@@ -29,8 +34,13 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type ValidatorServiceClient interface {
 	Keygen(ctx context.Context, in *KeygenRequest, opts ...grpc.CallOption) (*KeygenResponse, error)
+	StartKeygen(ctx context.Context, in *KeygenRequest, opts ...grpc.CallOption) (*StartKeygenResponse, error)
+	GetKeygenStatus(ctx context.Context, in *GetKeygenStatusRequest, opts ...grpc.CallOption) (*GetKeygenStatusResponse, error)
+	Reshare(ctx context.Context, in *ReshareRequest, opts ...grpc.CallOption) (*ReshareResponse, error)
 	GetAddresses(ctx context.Context, in *GetAddressesRequest, opts ...grpc.CallOption) (*GetAddressesResponse, error)
 	SignIntentOperation(ctx context.Context, in *SignIntentOperationRequest, opts ...grpc.CallOption) (*SignIntentOperationResponse, error)
+	SignBatch(ctx context.Context, in *SignBatchRequest, opts ...grpc.CallOption) (*SignBatchResponse, error)
+	GetBlameReport(ctx context.Context, in *GetBlameReportRequest, opts ...grpc.CallOption) (*GetBlameReportResponse, error)
 }
 
 type validatorServiceClient struct {
@@ -51,6 +61,36 @@ func (c *validatorServiceClient) Keygen(ctx context.Context, in *KeygenRequest,
 	return out, nil
 }
 
+func (c *validatorServiceClient) StartKeygen(ctx context.Context, in *KeygenRequest, opts ...grpc.CallOption) (*StartKeygenResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StartKeygenResponse)
+	err := c.cc.Invoke(ctx, ValidatorService_StartKeygen_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *validatorServiceClient) GetKeygenStatus(ctx context.Context, in *GetKeygenStatusRequest, opts ...grpc.CallOption) (*GetKeygenStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetKeygenStatusResponse)
+	err := c.cc.Invoke(ctx, ValidatorService_GetKeygenStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *validatorServiceClient) Reshare(ctx context.Context, in *ReshareRequest, opts ...grpc.CallOption) (*ReshareResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReshareResponse)
+	err := c.cc.Invoke(ctx, ValidatorService_Reshare_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *validatorServiceClient) GetAddresses(ctx context.Context, in *GetAddressesRequest, opts ...grpc.CallOption) (*GetAddressesResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(GetAddressesResponse)
@@ -71,13 +111,38 @@ func (c *validatorServiceClient) SignIntentOperation(ctx context.Context, in *Si
 	return out, nil
 }
 
+func (c *validatorServiceClient) SignBatch(ctx context.Context, in *SignBatchRequest, opts ...grpc.CallOption) (*SignBatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SignBatchResponse)
+	err := c.cc.Invoke(ctx, ValidatorService_SignBatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *validatorServiceClient) GetBlameReport(ctx context.Context, in *GetBlameReportRequest, opts ...grpc.CallOption) (*GetBlameReportResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetBlameReportResponse)
+	err := c.cc.Invoke(ctx, ValidatorService_GetBlameReport_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ValidatorServiceServer is the server API for ValidatorService service.
 // All implementations must embed UnimplementedValidatorServiceServer
 // for forward compatibility.
 type ValidatorServiceServer interface {
 	Keygen(context.Context, *KeygenRequest) (*KeygenResponse, error)
+	StartKeygen(context.Context, *KeygenRequest) (*StartKeygenResponse, error)
+	GetKeygenStatus(context.Context, *GetKeygenStatusRequest) (*GetKeygenStatusResponse, error)
+	Reshare(context.Context, *ReshareRequest) (*ReshareResponse, error)
 	GetAddresses(context.Context, *GetAddressesRequest) (*GetAddressesResponse, error)
 	SignIntentOperation(context.Context, *SignIntentOperationRequest) (*SignIntentOperationResponse, error)
+	SignBatch(context.Context, *SignBatchRequest) (*SignBatchResponse, error)
+	GetBlameReport(context.Context, *GetBlameReportRequest) (*GetBlameReportResponse, error)
 	mustEmbedUnimplementedValidatorServiceServer()
 }
 
@@ -91,12 +156,27 @@ type UnimplementedValidatorServiceServer struct{}
 func (UnimplementedValidatorServiceServer) Keygen(context.Context, *KeygenRequest) (*KeygenResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Keygen not implemented")
 }
+func (UnimplementedValidatorServiceServer) StartKeygen(context.Context, *KeygenRequest) (*StartKeygenResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartKeygen not implemented")
+}
+func (UnimplementedValidatorServiceServer) GetKeygenStatus(context.Context, *GetKeygenStatusRequest) (*GetKeygenStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetKeygenStatus not implemented")
+}
+func (UnimplementedValidatorServiceServer) Reshare(context.Context, *ReshareRequest) (*ReshareResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Reshare not implemented")
+}
 func (UnimplementedValidatorServiceServer) GetAddresses(context.Context, *GetAddressesRequest) (*GetAddressesResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetAddresses not implemented")
 }
 func (UnimplementedValidatorServiceServer) SignIntentOperation(context.Context, *SignIntentOperationRequest) (*SignIntentOperationResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SignIntentOperation not implemented")
 }
+func (UnimplementedValidatorServiceServer) SignBatch(context.Context, *SignBatchRequest) (*SignBatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SignBatch not implemented")
+}
+func (UnimplementedValidatorServiceServer) GetBlameReport(context.Context, *GetBlameReportRequest) (*GetBlameReportResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlameReport not implemented")
+}
 func (UnimplementedValidatorServiceServer) mustEmbedUnimplementedValidatorServiceServer() {}
 func (UnimplementedValidatorServiceServer) testEmbeddedByValue()                          {}
 
@@ -136,6 +216,60 @@ func _ValidatorService_Keygen_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ValidatorService_StartKeygen_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(KeygenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ValidatorServiceServer).StartKeygen(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ValidatorService_StartKeygen_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ValidatorServiceServer).StartKeygen(ctx, req.(*KeygenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ValidatorService_GetKeygenStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetKeygenStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ValidatorServiceServer).GetKeygenStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ValidatorService_GetKeygenStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ValidatorServiceServer).GetKeygenStatus(ctx, req.(*GetKeygenStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ValidatorService_Reshare_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReshareRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ValidatorServiceServer).Reshare(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ValidatorService_Reshare_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ValidatorServiceServer).Reshare(ctx, req.(*ReshareRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _ValidatorService_GetAddresses_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetAddressesRequest)
 	if err := dec(in); err != nil {
@@ -172,6 +306,42 @@ func _ValidatorService_SignIntentOperation_Handler(srv interface{}, ctx context.
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ValidatorService_SignBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SignBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ValidatorServiceServer).SignBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ValidatorService_SignBatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ValidatorServiceServer).SignBatch(ctx, req.(*SignBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ValidatorService_GetBlameReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBlameReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ValidatorServiceServer).GetBlameReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ValidatorService_GetBlameReport_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ValidatorServiceServer).GetBlameReport(ctx, req.(*GetBlameReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ValidatorService_ServiceDesc is the grpc.ServiceDesc for ValidatorService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -183,6 +353,18 @@ var ValidatorService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Keygen",
 			Handler:    _ValidatorService_Keygen_Handler,
 		},
+		{
+			MethodName: "StartKeygen",
+			Handler:    _ValidatorService_StartKeygen_Handler,
+		},
+		{
+			MethodName: "GetKeygenStatus",
+			Handler:    _ValidatorService_GetKeygenStatus_Handler,
+		},
+		{
+			MethodName: "Reshare",
+			Handler:    _ValidatorService_Reshare_Handler,
+		},
 		{
 			MethodName: "GetAddresses",
 			Handler:    _ValidatorService_GetAddresses_Handler,
@@ -191,6 +373,268 @@ var ValidatorService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "SignIntentOperation",
 			Handler:    _ValidatorService_SignIntentOperation_Handler,
 		},
+		{
+			MethodName: "SignBatch",
+			Handler:    _ValidatorService_SignBatch_Handler,
+		},
+		{
+			MethodName: "GetBlameReport",
+			Handler:    _ValidatorService_GetBlameReport_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "libs/proto/validator.proto",
+}
+
+const (
+	AdminService_ListKeyShares_FullMethodName = "/validator.AdminService/ListKeyShares"
+	AdminService_ListSessions_FullMethodName  = "/validator.AdminService/ListSessions"
+	AdminService_AbortSession_FullMethodName  = "/validator.AdminService/AbortSession"
+	AdminService_GetPeers_FullMethodName      = "/validator.AdminService/GetPeers"
+	AdminService_GetVersion_FullMethodName    = "/validator.AdminService/GetVersion"
+)
+
+// AdminServiceClient is the client API for AdminService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type AdminServiceClient interface {
+	ListKeyShares(ctx context.Context, in *ListKeySharesRequest, opts ...grpc.CallOption) (*ListKeySharesResponse, error)
+	ListSessions(ctx context.Context, in *ListSessionsRequest, opts ...grpc.CallOption) (*ListSessionsResponse, error)
+	AbortSession(ctx context.Context, in *AbortSessionRequest, opts ...grpc.CallOption) (*AbortSessionResponse, error)
+	GetPeers(ctx context.Context, in *GetPeersRequest, opts ...grpc.CallOption) (*GetPeersResponse, error)
+	GetVersion(ctx context.Context, in *GetVersionRequest, opts ...grpc.CallOption) (*GetVersionResponse, error)
+}
+
+type adminServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAdminServiceClient(cc grpc.ClientConnInterface) AdminServiceClient {
+	return &adminServiceClient{cc}
+}
+
+func (c *adminServiceClient) ListKeyShares(ctx context.Context, in *ListKeySharesRequest, opts ...grpc.CallOption) (*ListKeySharesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListKeySharesResponse)
+	err := c.cc.Invoke(ctx, AdminService_ListKeyShares_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) ListSessions(ctx context.Context, in *ListSessionsRequest, opts ...grpc.CallOption) (*ListSessionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSessionsResponse)
+	err := c.cc.Invoke(ctx, AdminService_ListSessions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) AbortSession(ctx context.Context, in *AbortSessionRequest, opts ...grpc.CallOption) (*AbortSessionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AbortSessionResponse)
+	err := c.cc.Invoke(ctx, AdminService_AbortSession_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) GetPeers(ctx context.Context, in *GetPeersRequest, opts ...grpc.CallOption) (*GetPeersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetPeersResponse)
+	err := c.cc.Invoke(ctx, AdminService_GetPeers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) GetVersion(ctx context.Context, in *GetVersionRequest, opts ...grpc.CallOption) (*GetVersionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetVersionResponse)
+	err := c.cc.Invoke(ctx, AdminService_GetVersion_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AdminServiceServer is the server API for AdminService service.
+// All implementations must embed UnimplementedAdminServiceServer
+// for forward compatibility.
+type AdminServiceServer interface {
+	ListKeyShares(context.Context, *ListKeySharesRequest) (*ListKeySharesResponse, error)
+	ListSessions(context.Context, *ListSessionsRequest) (*ListSessionsResponse, error)
+	AbortSession(context.Context, *AbortSessionRequest) (*AbortSessionResponse, error)
+	GetPeers(context.Context, *GetPeersRequest) (*GetPeersResponse, error)
+	GetVersion(context.Context, *GetVersionRequest) (*GetVersionResponse, error)
+	mustEmbedUnimplementedAdminServiceServer()
+}
+
+// UnimplementedAdminServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedAdminServiceServer struct{}
+
+func (UnimplementedAdminServiceServer) ListKeyShares(context.Context, *ListKeySharesRequest) (*ListKeySharesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListKeyShares not implemented")
+}
+func (UnimplementedAdminServiceServer) ListSessions(context.Context, *ListSessionsRequest) (*ListSessionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSessions not implemented")
+}
+func (UnimplementedAdminServiceServer) AbortSession(context.Context, *AbortSessionRequest) (*AbortSessionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AbortSession not implemented")
+}
+func (UnimplementedAdminServiceServer) GetPeers(context.Context, *GetPeersRequest) (*GetPeersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPeers not implemented")
+}
+func (UnimplementedAdminServiceServer) GetVersion(context.Context, *GetVersionRequest) (*GetVersionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetVersion not implemented")
+}
+func (UnimplementedAdminServiceServer) mustEmbedUnimplementedAdminServiceServer() {}
+func (UnimplementedAdminServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeAdminServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AdminServiceServer will
+// result in compilation errors.
+type UnsafeAdminServiceServer interface {
+	mustEmbedUnimplementedAdminServiceServer()
+}
+
+func RegisterAdminServiceServer(s grpc.ServiceRegistrar, srv AdminServiceServer) {
+	// If the following call pancis, it indicates UnimplementedAdminServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&AdminService_ServiceDesc, srv)
+}
+
+func _AdminService_ListKeyShares_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListKeySharesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).ListKeyShares(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_ListKeyShares_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).ListKeyShares(ctx, req.(*ListKeySharesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_ListSessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSessionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).ListSessions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_ListSessions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).ListSessions(ctx, req.(*ListSessionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_AbortSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AbortSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).AbortSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_AbortSession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).AbortSession(ctx, req.(*AbortSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_GetPeers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPeersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).GetPeers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_GetPeers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).GetPeers(ctx, req.(*GetPeersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_GetVersion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetVersionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).GetVersion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_GetVersion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).GetVersion(ctx, req.(*GetVersionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// AdminService_ServiceDesc is the grpc.ServiceDesc for AdminService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AdminService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "validator.AdminService",
+	HandlerType: (*AdminServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListKeyShares",
+			Handler:    _AdminService_ListKeyShares_Handler,
+		},
+		{
+			MethodName: "ListSessions",
+			Handler:    _AdminService_ListSessions_Handler,
+		},
+		{
+			MethodName: "AbortSession",
+			Handler:    _AdminService_AbortSession_Handler,
+		},
+		{
+			MethodName: "GetPeers",
+			Handler:    _AdminService_GetPeers_Handler,
+		},
+		{
+			MethodName: "GetVersion",
+			Handler:    _AdminService_GetVersion_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "libs/proto/validator.proto",
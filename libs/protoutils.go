@@ -16,11 +16,13 @@ import (
 var curveToProto = map[common.Curve]pb.Curve{
 	common.CurveEcdsa: pb.Curve_CURVE_ECDSA,
 	common.CurveEddsa: pb.Curve_CURVE_EDDSA,
+	common.CurveFrost: pb.Curve_CURVE_FROST,
 }
 
 var protoCurveToCommon = map[pb.Curve]common.Curve{
 	pb.Curve_CURVE_ECDSA: common.CurveEcdsa,
 	pb.Curve_CURVE_EDDSA: common.CurveEddsa,
+	pb.Curve_CURVE_FROST: common.CurveFrost,
 }
 
 func ProtoToCommonCurve(c pb.Curve) (common.Curve, error) {
@@ -122,6 +124,7 @@ var operationTypeToProtoMap = map[OperationType]pb.OperationType{
 	OperationTypeBurnSynthetic: pb.OperationType_BURN_SYNTHETIC,
 	OperationTypeWithdraw:      pb.OperationType_WITHDRAW,
 	OperationTypeSendToBridge:  pb.OperationType_SEND_TO_BRIDGE,
+	OperationTypeTrustSet:      pb.OperationType_TRUST_SET,
 }
 
 var protoToOperationTypeMap = map[pb.OperationType]OperationType{
@@ -133,6 +136,7 @@ var protoToOperationTypeMap = map[pb.OperationType]OperationType{
 	pb.OperationType_BURN_SYNTHETIC: OperationTypeBurnSynthetic,
 	pb.OperationType_WITHDRAW:       OperationTypeWithdraw,
 	pb.OperationType_SEND_TO_BRIDGE: OperationTypeSendToBridge,
+	pb.OperationType_TRUST_SET:      OperationTypeTrustSet,
 }
 
 func protoToOperationType(o pb.OperationType) (OperationType, error) {
@@ -326,6 +330,7 @@ func ProtoToLibsOperation(po *pb.Operation) (*Operation, error) {
 		GenesisHash:      po.GenesisHash,
 		Status:           status,
 		SolverOutput:     po.Solver.Output,
+		DerivationPath:   po.DerivationPath,
 		CreatedAt:        po.CreatedAt.AsTime(),
 	}, nil
 }
@@ -395,15 +400,16 @@ func operationToProto(op *Operation) (*pb.Operation, error) {
 	}
 
 	protoOp := &pb.Operation{
-		ID:           int32(op.ID),
-		Type:         opType,
-		BlockchainId: opBlockchainID,
-		NetworkType:  opNetworkType,
-		Result:       op.Result,
-		Status:       opStatus,
-		GenesisHash:  op.GenesisHash,
-		DataToSign:   op.DataToSign,
-		CreatedAt:    timestamppb.New(op.CreatedAt),
+		ID:             int32(op.ID),
+		Type:           opType,
+		BlockchainId:   opBlockchainID,
+		NetworkType:    opNetworkType,
+		Result:         op.Result,
+		Status:         opStatus,
+		GenesisHash:    op.GenesisHash,
+		DataToSign:     op.DataToSign,
+		CreatedAt:      timestamppb.New(op.CreatedAt),
+		DerivationPath: op.DerivationPath,
 		Solver: &pb.Solver{
 			Domain:     op.Solver,
 			Metadata:   op.SolverMetadata,
@@ -0,0 +1,85 @@
+package libs
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	pb "github.com/StripChain/strip-node/libs/proto"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// A signing receipt records who signed what and when: the intent operation, the message,
+// the validators that took part, the signature, the key it verifies under and the time
+// the signing started. Each of those validators co-signs the receipt with its node key, so
+// a receipt cannot be altered without invalidating their co-signatures.
+
+// receiptBody is the co-signed part of a receipt, its fields in a fixed order
+type receiptBody struct {
+	SessionID      string   `json:"sessionId"`
+	IntentID       string   `json:"intentId"`
+	OperationIndex uint32   `json:"operationIndex"`
+	MessageHash    string   `json:"messageHash"`
+	Signers        []string `json:"signers"`
+	Signature      string   `json:"signature"`
+	GroupPublicKey string   `json:"groupPublicKey"`
+	Timestamp      int64    `json:"timestamp"`
+}
+
+// SigningReceiptDigest returns the digest validators co-sign: the Keccak-256 hash of the
+// JSON encoding of every field of the receipt but its co-signatures
+func SigningReceiptDigest(receipt *pb.SigningReceipt) ([]byte, error) {
+	data, err := json.Marshal(receiptBody{
+		SessionID:      receipt.SessionId,
+		IntentID:       receipt.IntentId,
+		OperationIndex: receipt.OperationIndex,
+		MessageHash:    receipt.MessageHash,
+		Signers:        receipt.Signers,
+		Signature:      receipt.Signature,
+		GroupPublicKey: receipt.GroupPublicKey,
+		Timestamp:      receipt.Timestamp,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Keccak256(data), nil
+}
+
+// ReceiptSigner returns the validator that made a co-signature of digest, identified like
+// signers are: by the X coordinate of its node key
+func ReceiptSigner(digest []byte, signature []byte) (string, error) {
+	pub, err := crypto.SigToPub(digest, signature)
+	if err != nil {
+		return "", err
+	}
+	return hexutil.Encode(crypto.CompressPubkey(pub)[1:]), nil
+}
+
+// VerifySigningReceipt checks the co-signatures of a receipt and returns the signers that
+// co-signed it. Every co-signature must be valid and made by one of the receipt's signers.
+func VerifySigningReceipt(receipt *pb.SigningReceipt) ([]string, error) {
+	digest, err := SigningReceiptDigest(receipt)
+	if err != nil {
+		return nil, err
+	}
+
+	coSigners := make([]string, 0, len(receipt.CoSignatures))
+	for _, coSignature := range receipt.CoSignatures {
+		signer, err := ReceiptSigner(digest, coSignature.Signature)
+		if err != nil {
+			return nil, fmt.Errorf("invalid co-signature of %s: %w", coSignature.Signer, err)
+		}
+		if signer != coSignature.Signer {
+			return nil, fmt.Errorf("co-signature of %s is made by %s", coSignature.Signer, signer)
+		}
+		if !slices.Contains(receipt.Signers, signer) {
+			return nil, fmt.Errorf("co-signer %s is not a signer of the receipt", signer)
+		}
+		if slices.Contains(coSigners, signer) {
+			return nil, fmt.Errorf("%s co-signed the receipt twice", signer)
+		}
+		coSigners = append(coSigners, signer)
+	}
+	return coSigners, nil
+}
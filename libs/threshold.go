@@ -0,0 +1,37 @@
+package libs
+
+import "fmt"
+
+// A wallet's signing policy is a (t, n) pair: n signers hold shares of the key and t is the
+// TSS threshold, so any t+1 of them can sign. Wallets created without an explicit policy
+// use DefaultThreshold.
+
+// DefaultThreshold returns the threshold of a committee of n signers without an explicit policy
+func DefaultThreshold(signers int) int {
+	if signers == 1 || signers == 2 {
+		return 1
+	}
+	return signers/2 + 1
+}
+
+// ResolveThreshold returns threshold, or the default for the committee when it is unset
+func ResolveThreshold(threshold int, signers int) int {
+	if threshold == 0 {
+		return DefaultThreshold(signers)
+	}
+	return threshold
+}
+
+// ValidateThresholdPolicy checks that a committee of n signers with threshold t can run TSS
+func ValidateThresholdPolicy(threshold int, signers int, maximumSigners int) error {
+	if signers < 2 {
+		return fmt.Errorf("a committee needs at least 2 signers, got %d", signers)
+	}
+	if signers > maximumSigners {
+		return fmt.Errorf("a committee can have at most %d signers, got %d", maximumSigners, signers)
+	}
+	if threshold < 1 || threshold >= signers {
+		return fmt.Errorf("threshold must be between 1 and %d for %d signers, got %d", signers-1, signers, threshold)
+	}
+	return nil
+}
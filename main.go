@@ -1,9 +1,11 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"log"
 	"strconv"
+	"time"
 
 	"github.com/StripChain/strip-node/ERC20"
 	"github.com/StripChain/strip-node/bridge"
@@ -12,6 +14,7 @@ import (
 	intentoperatorsregistry "github.com/StripChain/strip-node/intentOperatorsRegistry"
 	"github.com/StripChain/strip-node/libs/blockchains"
 	"github.com/StripChain/strip-node/libs/database"
+	"github.com/StripChain/strip-node/libs/mtls"
 	"github.com/StripChain/strip-node/sequencer"
 	"github.com/StripChain/strip-node/solana"
 	"github.com/StripChain/strip-node/solver"
@@ -44,7 +47,7 @@ func main() {
 	validatorPublicKey := flag.String("validatorPublicKey", util.LookupEnvOrString("VALIDATOR_PUBLIC_KEY", ""), "public key of the signer nodes")
 	validatorNodeURL := flag.String("validatorNodeURL", util.LookupEnvOrString("VALIDATOR_NODE_URL", ""), "URL of the signer node")
 	solverDomain := flag.String("solverDomain", util.LookupEnvOrString("SOLVER_DOMAIN", ""), "domain of the solver")
-	heliusApiKey := flag.String("heliusApiKey", util.LookupEnvOrString("HELIUS_API_KEY", "6ccb4a2e-a0e6-4af3-afd0-1e06e1439547"), "helius API key")
+	walletPolicies := flag.String("walletPolicies", util.LookupEnvOrString("WALLET_POLICIES", ""), "JSON object mapping identities to their (t, n) signing policy")
 
 	intentOperatorsRegistryContractAddress := flag.String("intentOperatorsRegistryAddress", util.LookupEnvOrString("SIGNER_HUB_CONTRACT_ADDRESS", "0x716A4f850809d929F85BF1C589c24FB25F884674"), "address of IntentOperatorsRegistry contract")
 	solversRegistryContractAddress := flag.String("solversRegistryAddress", util.LookupEnvOrString("SOLVERS_REGISTRY_CONTRACT_ADDRESS", "0x56A9bCddF533Af1859842074B46B0daD07b7686a"), "address of SolversRegistry contract")
@@ -67,10 +70,14 @@ func main() {
 	postgresUser := flag.String("postgresUser", util.LookupEnvOrString("POSTGRES_USER", "postgres"), "postgres user")
 	postgresPassword := flag.String("postgresPassword", util.LookupEnvOrString("POSTGRES_PASSWORD", "password"), "postgres password")
 
-	// clientCertARN := flag.String("client-cert-arn", util.LookupEnvOrString("CLIENT_CERT_ARN", ""), "ARN for sequencer client cert")
-	// clientKeyARN := flag.String("client-key-arn", util.LookupEnvOrString("CLIENT_KEY_ARN", ""), "ARN for sequencer client key")
-	// // *** Simplified: Assume ONE CA for all validators for this example ***
-	// serverCaARN := flag.String("server-ca-arn", util.LookupEnvOrString("SERVER_CA_ARN", ""), "ARN for the CA signing validator server certs")
+	// mTLS towards the validators
+	grpcInsecure := flag.Bool("grpcInsecure", util.LookupEnvOrBool("GRPC_INSECURE", false), "call validators without TLS, never do this outside of debugging")
+	tlsCert := flag.String("tlsCert", util.LookupEnvOrString("TLS_CERT", ""), "sequencer client certificate PEM, a file or a Secrets Manager ARN")
+	tlsKey := flag.String("tlsKey", util.LookupEnvOrString("TLS_KEY", ""), "sequencer client private key PEM, a file or a Secrets Manager ARN")
+	tlsCA := flag.String("tlsCA", util.LookupEnvOrString("TLS_CA", ""), "PEM of the CA issuing validator certificates, a file or a Secrets Manager ARN")
+	tlsReloadInterval := flag.Int("tlsReloadInterval", util.LookupEnvOrInt("TLS_RELOAD_INTERVAL", 60), "seconds between reloads of the TLS certificate, key and CA")
+	tlsDevDir := flag.String("tlsDevDir", util.LookupEnvOrString("TLS_DEV_DIR", ""), "issue the client certificate from a throwaway CA kept in this directory, for local networks and tests")
+	tlsDevName := flag.String("tlsDevName", util.LookupEnvOrString("TLS_DEV_NAME", "sequencer"), "name of the dev client certificate")
 
 	flag.Parse()
 
@@ -98,49 +105,36 @@ func main() {
 	} else if *isSetSwapRouter {
 		bridge.SetSwapRouter(*rpcURL, *privateKey, *bridgeContractAddress, *swapRouter)
 	} else if *isSequencer {
-		// if *clientCertARN == "" || *clientKeyARN == "" || *serverCaARN == "" {
-		// 	logger.Sugar().Fatal("Client cert ARN, client key ARN, and server CA ARN must be provided via flags/env")
-		// }
-
-		// awsCfg, err := config.LoadDefaultConfig(ctx)
-		// if err != nil {
-		// 	logger.Sugar().Fatalf("Failed to load AWS config: %v", err)
-		// }
-		// smClient := secretsmanager.NewFromConfig(awsCfg)
-
-		// logger.Sugar().Info("Fetching TLS credentials from AWS Secrets Manager...")
-		// clientCertPEM, err := libs.FetchSecret(ctx, smClient, *clientCertARN)
-		// if err != nil {
-		// 	logger.Sugar().Fatalf("Failed to fetch client cert: %v", err)
-		// }
-		// clientKeyPEM, err := libs.FetchSecret(ctx, smClient, *clientKeyARN)
-		// if err != nil {
-		// 	logger.Sugar().Fatalf("Failed to fetch client key: %v", err)
-		// }
-		// serverCaPEM, err := libs.FetchSecret(ctx, smClient, *serverCaARN)
-		// if err != nil {
-		// 	logger.Sugar().Fatalf("Failed to fetch server CA: %v", err)
-		// }
-		// logger.Sugar().Info("Successfully fetched TLS credentials.")
+		var validatorCredentials *mtls.Credentials
+		if !*grpcInsecure {
+			var err error
+			validatorCredentials, err = mtls.Setup(context.Background(), mtls.Options{
+				Config:         mtls.Config{Cert: *tlsCert, Key: *tlsKey, CA: *tlsCA},
+				DevDir:         *tlsDevDir,
+				DevName:        *tlsDevName,
+				ReloadInterval: time.Duration(*tlsReloadInterval) * time.Second,
+			})
+			if err != nil {
+				logger.Sugar().Fatalf("Failed to load validator mTLS credentials: %v", err)
+			}
+		}
 
 		blockchains.InitBlockchainRegistry()
 
 		database.InitialiseDB(*postgresHost, *postgresDB, *postgresUser, *postgresPassword)
 
-		validatorClientManager, err := sequencer.NewValidatorClientManager(
-		// clientCertPEM,
-		// clientKeyPEM,
-		// serverCaPEM,
-		)
+		validatorClientManager, err := sequencer.NewValidatorClientManager(validatorCredentials)
 		if err != nil {
 			logger.Sugar().Fatalf("Failed to initialize ValidatorClientManager: %v", err)
 		}
+		if err := sequencer.SetWalletPolicies(*walletPolicies); err != nil {
+			logger.Sugar().Fatalf("Failed to configure wallet policies: %v", err)
+		}
 		sequencer.StartSequencer(
 			*httpPort,
 			*rpcURL,
 			*intentOperatorsRegistryContractAddress,
 			*solversRegistryContractAddress,
-			*heliusApiKey,
 			*bridgeContractAddress,
 			*privateKey,
 			validatorClientManager,
@@ -163,7 +157,6 @@ func main() {
 		solana.GetSolanaTransfers(
 			"901",
 			"243hStsqpngr2Dv4ktE9wCW6CZTbFYaRiXo1QAK1PTtyLZBL2xz17XTAuud3HN8YmpYhdSRJmP3Rx3pMHdu6Pxqi",
-			*heliusApiKey,
 		)
 		// identity.VerifySignature(
 		// 	"GScvaHyfG3NMNm8AdPjjZt3xRiNtAwHy5z5yY1oaQA4Q",
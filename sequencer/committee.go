@@ -0,0 +1,82 @@
+package sequencer
+
+import (
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/StripChain/strip-node/libs"
+	db "github.com/StripChain/strip-node/libs/database"
+)
+
+// healthySigners returns the registered signers with a recent heartbeat, the most recently
+// seen first so the most responsive signers are preferred for new committees
+func healthySigners(signers []Signer, heartbeats []db.HeartbeatSchema) []Signer {
+	lastSeen := make(map[string]time.Time)
+	for _, heartbeat := range heartbeats {
+		lastSeen[heartbeat.PublicKey] = heartbeat.UpdatedAt
+	}
+
+	cutoff := time.Now().Add(-libs.HEARTBEAT_TIMEOUT)
+	healthy := []Signer{}
+	for _, signer := range signers {
+		if seen, ok := lastSeen[signer.PublicKey]; ok && seen.After(cutoff) {
+			healthy = append(healthy, signer)
+		}
+	}
+
+	sort.SliceStable(healthy, func(i, j int) bool {
+		a, b := lastSeen[healthy[i].PublicKey], lastSeen[healthy[j].PublicKey]
+		if !a.Equal(b) {
+			return a.After(b)
+		}
+		return healthy[i].PublicKey < healthy[j].PublicKey
+	})
+	return healthy
+}
+
+// signerHost returns the host a signer runs on, signers sharing a host fail together
+func signerHost(signer Signer) string {
+	parsed, err := url.Parse(signer.URL)
+	if err != nil || parsed.Hostname() == "" {
+		return signer.URL
+	}
+	return parsed.Hostname()
+}
+
+// selectCommittee picks size members among the healthy signers. Healthy members of the
+// current committee keep their seats, the others are filled in order of the healthy signers,
+// first with signers on hosts the committee does not use yet.
+func selectCommittee(healthy []Signer, current []string, size int) []string {
+	committee := []string{}
+	inCommittee := make(map[string]bool)
+	hosts := make(map[string]bool)
+
+	add := func(signer Signer) {
+		committee = append(committee, signer.PublicKey)
+		inCommittee[signer.PublicKey] = true
+		hosts[signerHost(signer)] = true
+	}
+
+	for _, publicKey := range current {
+		for _, signer := range healthy {
+			if signer.PublicKey == publicKey && len(committee) < size {
+				add(signer)
+			}
+		}
+	}
+
+	for _, newHostsOnly := range []bool{true, false} {
+		for _, signer := range healthy {
+			if len(committee) >= size {
+				return committee
+			}
+			if inCommittee[signer.PublicKey] || (newHostsOnly && hosts[signerHost(signer)]) {
+				continue
+			}
+			add(signer)
+		}
+	}
+
+	return committee
+}
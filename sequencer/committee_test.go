@@ -0,0 +1,79 @@
+package sequencer
+
+import (
+	"testing"
+	"time"
+
+	db "github.com/StripChain/strip-node/libs/database"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthySigners(t *testing.T) {
+	signers := []Signer{
+		{PublicKey: "a", URL: "https://a.example:9000"},
+		{PublicKey: "b", URL: "https://b.example:9000"},
+		{PublicKey: "c", URL: "https://c.example:9000"},
+		{PublicKey: "d", URL: "https://d.example:9000"},
+	}
+	now := time.Now()
+	heartbeats := []db.HeartbeatSchema{
+		{PublicKey: "a", UpdatedAt: now.Add(-time.Hour)},
+		{PublicKey: "b", UpdatedAt: now.Add(-time.Minute)},
+		{PublicKey: "c", UpdatedAt: now.Add(-3 * time.Hour)},
+	}
+
+	healthy := healthySigners(signers, heartbeats)
+	require.Equal(t, []Signer{signers[1], signers[0]}, healthy)
+}
+
+func TestSelectCommittee(t *testing.T) {
+	healthy := []Signer{
+		{PublicKey: "a", URL: "https://host1:9000"},
+		{PublicKey: "b", URL: "https://host1:9001"},
+		{PublicKey: "c", URL: "https://host2:9000"},
+		{PublicKey: "d", URL: "https://host3:9000"},
+	}
+
+	t.Run("prefers distinct hosts", func(t *testing.T) {
+		require.Equal(t, []string{"a", "c", "d"}, selectCommittee(healthy, nil, 3))
+	})
+
+	t.Run("shares hosts when it has to", func(t *testing.T) {
+		require.Equal(t, []string{"a", "c", "d", "b"}, selectCommittee(healthy, nil, 4))
+	})
+
+	t.Run("keeps healthy members", func(t *testing.T) {
+		require.Equal(t, []string{"b", "c"}, selectCommittee(healthy, []string{"b", "c"}, 2))
+	})
+
+	t.Run("replaces unhealthy members", func(t *testing.T) {
+		require.Equal(t, []string{"b", "c", "d"}, selectCommittee(healthy, []string{"x", "b"}, 3))
+	})
+}
+
+func TestWalletPolicy(t *testing.T) {
+	MaximumSigners = 5
+	BridgeContractAddress = "0xbridge"
+	require.NoError(t, SetWalletPolicies(`{"0xtreasury": {"signers": 4, "threshold": 3}}`))
+	defer SetWalletPolicies("")
+
+	signers, threshold, err := walletPolicy("0xuser").resolve(3)
+	require.NoError(t, err)
+	require.Equal(t, 3, signers)
+	require.Equal(t, 2, threshold)
+
+	signers, threshold, err = walletPolicy("0xbridge").resolve(7)
+	require.NoError(t, err)
+	require.Equal(t, 5, signers)
+	require.Equal(t, 4, threshold)
+
+	signers, threshold, err = walletPolicy("0xtreasury").resolve(4)
+	require.NoError(t, err)
+	require.Equal(t, 4, signers)
+	require.Equal(t, 3, threshold)
+
+	_, _, err = walletPolicy("0xtreasury").resolve(3)
+	require.Error(t, err)
+
+	require.Error(t, SetWalletPolicies(`{"0xbad": {"unanimous": true, "threshold": 2}}`))
+}
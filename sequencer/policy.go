@@ -0,0 +1,81 @@
+package sequencer
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/StripChain/strip-node/libs"
+)
+
+// WalletPolicy is the (t, n) signing policy of a wallet: Signers members hold shares of the
+// key and any Threshold+1 of them can sign.
+type WalletPolicy struct {
+	// Signers is the committee size, 0 takes as many healthy signers as MaximumSigners allows
+	Signers int `json:"signers"`
+	// Threshold is the TSS threshold, 0 uses libs.DefaultThreshold for the committee
+	Threshold int `json:"threshold"`
+	// Unanimous requires every member of the committee to sign
+	Unanimous bool `json:"unanimous"`
+	// Frost gives the wallet a FROST Ed25519 key instead of a tss-lib EdDSA one
+	Frost bool `json:"frost"`
+}
+
+// walletPolicies are the policies configured for specific identities, such as treasury wallets
+var walletPolicies = map[string]WalletPolicy{}
+
+// SetWalletPolicies configures the policies of specific identities from a JSON object
+// mapping identities to policies, e.g. {"0xabc": {"signers": 5, "threshold": 3}}
+func SetWalletPolicies(config string) error {
+	policies := map[string]WalletPolicy{}
+	if config != "" {
+		if err := json.Unmarshal([]byte(config), &policies); err != nil {
+			return fmt.Errorf("failed to parse wallet policies: %w", err)
+		}
+	}
+
+	for identity, policy := range policies {
+		if policy.Signers < 0 || policy.Threshold < 0 {
+			return fmt.Errorf("invalid policy for %s: signers and threshold cannot be negative", identity)
+		}
+		if policy.Unanimous && policy.Threshold != 0 {
+			return fmt.Errorf("invalid policy for %s: a unanimous policy cannot set a threshold", identity)
+		}
+	}
+
+	walletPolicies = policies
+	return nil
+}
+
+// walletPolicy returns the policy of an identity. The bridge wallet holds the funds of every
+// user, so unless configured otherwise its whole committee has to sign.
+func walletPolicy(identity string) WalletPolicy {
+	if policy, ok := walletPolicies[identity]; ok {
+		return policy
+	}
+	if identity == BridgeContractAddress {
+		return WalletPolicy{Unanimous: true}
+	}
+	return WalletPolicy{}
+}
+
+// resolve returns the committee size and threshold of the policy given the number of
+// healthy signers available
+func (p WalletPolicy) resolve(available int) (int, int, error) {
+	signers := p.Signers
+	if signers == 0 {
+		signers = min(available, MaximumSigners)
+	}
+	if signers > available {
+		return 0, 0, fmt.Errorf("policy needs %d signers but only %d are healthy", signers, available)
+	}
+
+	threshold := libs.ResolveThreshold(p.Threshold, signers)
+	if p.Unanimous {
+		threshold = signers - 1
+	}
+
+	if err := libs.ValidateThresholdPolicy(threshold, signers, MaximumSigners); err != nil {
+		return 0, 0, err
+	}
+	return signers, threshold, nil
+}
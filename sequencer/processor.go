@@ -51,6 +51,12 @@ type LockMetadata struct {
 	Lock bool `json:"lock"`
 }
 
+// TrustSetMetadata defines the metadata required for the TRUST_SET operation
+type TrustSetMetadata struct {
+	Token string `json:"token"` // Issued currency as CURRENCY.rIssuer
+	Limit string `json:"limit"` // Trust line limit in base units
+}
+
 func ProcessIntent(intentID uuid.UUID) {
 ProcessLoop:
 	for {
@@ -119,7 +125,7 @@ ProcessLoop:
 			}
 
 			// then get the data signed
-			if operation.Type != libs.OperationTypeBridgeDeposit && operation.Type != libs.OperationTypeBurnSynthetic && operation.Type != libs.OperationTypeWithdraw { // for bridge deposit the dataToSign is set later
+			if operation.Type != libs.OperationTypeBridgeDeposit && operation.Type != libs.OperationTypeBurnSynthetic && operation.Type != libs.OperationTypeWithdraw && operation.Type != libs.OperationTypeTrustSet { // for bridge deposit the dataToSign is set later
 				signature, err = getSignature(intent, i)
 				if err != nil {
 					logger.Sugar().Errorw("error getting signature", "error", err)
@@ -1074,7 +1080,15 @@ ProcessLoop:
 					db.UpdateOperationSolverDataToSign(operation.ID, dataToSign)
 					intent.Operations[i].SolverDataToSign = dataToSign
 
-					withdrawSignature, err := getSignature(intent, i)
+					var withdrawSignature string
+					if hashes := blockchains.SplitDataToSign(dataToSign); len(hashes) > 1 {
+						// One signature per input of the transaction, signed in a single batch
+						var signatures []string
+						signatures, err = getSignatures(intent, i, hashes)
+						withdrawSignature = blockchains.JoinSignatures(signatures)
+					} else {
+						withdrawSignature, err = getSignature(intent, i)
+					}
 					if err != nil {
 						logger.Sugar().Errorw("error getting signature", "error", err)
 						db.UpdateOperationStatus(operation.ID, libs.OperationStatusFailed)
@@ -1097,13 +1111,57 @@ ProcessLoop:
 
 					db.UpdateOperationResult(operation.ID, libs.OperationStatusWaiting, result)
 					break OperationLoop
+				case libs.OperationTypeTrustSet:
+					rippleBlockchain, ok := opBlockchain.(*blockchains.RippleBlockchain)
+					if !ok {
+						logger.Sugar().Errorw("Trust lines are only supported on Ripple", "blockchainID", operation.BlockchainID)
+						db.UpdateOperationStatus(operation.ID, libs.OperationStatusFailed)
+						db.UpdateIntentStatus(intent.ID, libs.IntentStatusFailed)
+						break
+					}
+
+					var trustSetMetadata TrustSetMetadata
+					if err := json.Unmarshal([]byte(operation.SolverMetadata), &trustSetMetadata); err != nil {
+						logger.Sugar().Errorw("Failed to unmarshal metadata for trust set operation", "error", err)
+						db.UpdateOperationStatus(operation.ID, libs.OperationStatusFailed)
+						db.UpdateIntentStatus(intent.ID, libs.IntentStatusFailed)
+						break
+					}
+
+					tx, dataToSign, err := rippleBlockchain.BuildTrustSetTx(publicKey, trustSetMetadata.Token, trustSetMetadata.Limit)
+					if err != nil {
+						logger.Sugar().Errorw("error building trust set transaction", "error", err)
+						db.UpdateOperationStatus(operation.ID, libs.OperationStatusFailed)
+						db.UpdateIntentStatus(intent.ID, libs.IntentStatusFailed)
+						break
+					}
+					db.UpdateOperationSolverDataToSign(operation.ID, dataToSign)
+					intent.Operations[i].SolverDataToSign = dataToSign
+
+					trustSetSignature, err := getSignature(intent, i)
+					if err != nil {
+						logger.Sugar().Errorw("error getting signature", "error", err)
+						db.UpdateOperationStatus(operation.ID, libs.OperationStatusFailed)
+						db.UpdateIntentStatus(intent.ID, libs.IntentStatusFailed)
+						break
+					}
+
+					txHash, err := opBlockchain.BroadcastTransaction(tx, trustSetSignature, &publicKey)
+					if err != nil {
+						logger.Sugar().Errorw("error broadcasting trust set transaction", "error", err)
+						db.UpdateOperationStatus(operation.ID, libs.OperationStatusFailed)
+						db.UpdateIntentStatus(intent.ID, libs.IntentStatusFailed)
+						break
+					}
+
+					db.UpdateOperationResult(operation.ID, libs.OperationStatusWaiting, txHash)
 				}
 
 				break OperationLoop
 			case libs.OperationStatusWaiting:
 				// check for confirmations and update the status to completed
 				switch operation.Type {
-				case libs.OperationTypeTransaction, libs.OperationTypeSendToBridge, libs.OperationTypeBridgeDeposit:
+				case libs.OperationTypeTransaction, libs.OperationTypeSendToBridge, libs.OperationTypeBridgeDeposit, libs.OperationTypeTrustSet:
 					confirmed, err := opBlockchain.IsTransactionBroadcastedAndConfirmed(operation.Result)
 					if err != nil {
 						logger.Sugar().Errorw("error checking transaction", "error", err)
@@ -1328,7 +1386,8 @@ func getSignature(intent *libs.Intent, operationIndex int) (string, error) {
 	return signature, nil
 }
 
-func getSignatureEx(intent *libs.Intent, operationIndex int) (string, error) {
+// getOperationSigner returns the signer asked for the signatures of an operation
+func getOperationSigner(intent *libs.Intent, operationIndex int) (Signer, error) {
 	// get wallet
 	transactionType := intent.Operations[operationIndex].Type
 	var wallet *db.WalletSchema
@@ -1336,12 +1395,12 @@ func getSignatureEx(intent *libs.Intent, operationIndex int) (string, error) {
 	if transactionType == libs.OperationTypeWithdraw {
 		wallet, err = db.GetWallet(BridgeContractAddress, blockchains.Ethereum)
 		if err != nil {
-			return "", fmt.Errorf("error getting wallet: %v", err)
+			return Signer{}, fmt.Errorf("error getting wallet: %v", err)
 		}
 	} else {
 		wallet, err = db.GetWallet(intent.Identity, intent.BlockchainID)
 		if err != nil {
-			return "", fmt.Errorf("error getting wallet: %v", err)
+			return Signer{}, fmt.Errorf("error getting wallet: %v", err)
 		}
 	}
 
@@ -1350,7 +1409,15 @@ func getSignatureEx(intent *libs.Intent, operationIndex int) (string, error) {
 	signer, err := GetSigner(signers[0])
 
 	if err != nil {
-		return "", fmt.Errorf("error getting signer: %v", err)
+		return Signer{}, fmt.Errorf("error getting signer: %v", err)
+	}
+	return signer, nil
+}
+
+func getSignatureEx(intent *libs.Intent, operationIndex int) (string, error) {
+	signer, err := getOperationSigner(intent, operationIndex)
+	if err != nil {
+		return "", err
 	}
 
 	operation := intent.Operations[operationIndex]
@@ -1407,6 +1474,7 @@ func getSignatureEx(intent *libs.Intent, operationIndex int) (string, error) {
 		OperationIndex: uint32(operationIndex),
 	})
 	if err != nil {
+		recordBlame(err)
 		return "", fmt.Errorf("error getting signature: %v", err)
 	}
 
@@ -1414,10 +1482,54 @@ func getSignatureEx(intent *libs.Intent, operationIndex int) (string, error) {
 	if len(resp.Signature) == 0 {
 		return "", fmt.Errorf("empty signature in response")
 	}
+	recordSigningReceipt(resp.Receipt)
 
 	return resp.Signature, nil
 }
 
+// getSignatures signs several hashes of an operation, e.g. one per transaction input, in
+// one batch and returns the signatures in the same order
+func getSignatures(intent *libs.Intent, operationIndex int, hashes []string) ([]string, error) {
+	signer, err := getOperationSigner(intent, operationIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Sugar().Infow("Requesting batch signature from validator",
+		"url", signer.URL,
+		"intentID", intent.ID,
+		"operationIndex", operationIndex,
+		"hashes", len(hashes))
+
+	client, err := validatorClientManager.GetClient(signer.URL)
+	if err != nil {
+		return nil, fmt.Errorf("error getting validator client: %v", err)
+	}
+
+	protoIntent, err := libs.IntentToProto(intent)
+	if err != nil {
+		return nil, fmt.Errorf("error converting intent to proto: %v", err)
+	}
+	resp, err := client.SignBatch(context.Background(), &pb.SignBatchRequest{
+		Intent:         protoIntent,
+		OperationIndex: uint32(operationIndex),
+		Messages:       hashes,
+	})
+	if err != nil {
+		recordBlame(err)
+		return nil, fmt.Errorf("error getting signatures: %v", err)
+	}
+
+	if len(resp.Signatures) != len(hashes) {
+		return nil, fmt.Errorf("got %d signatures for %d hashes", len(resp.Signatures), len(hashes))
+	}
+	for _, receipt := range resp.Receipts {
+		recordSigningReceipt(receipt)
+	}
+
+	return resp.Signatures, nil
+}
+
 // Helper function to truncate strings for logging
 func truncateString(s string, maxLen int) string {
 	if len(s) <= maxLen {
@@ -0,0 +1,50 @@
+package sequencer
+
+import (
+	"encoding/hex"
+	"time"
+
+	"github.com/StripChain/strip-node/libs"
+	db "github.com/StripChain/strip-node/libs/database"
+	pb "github.com/StripChain/strip-node/libs/proto"
+	"github.com/StripChain/strip-node/util/logger"
+)
+
+// recordSigningReceipt stores the receipt a validator returned with a signature, after
+// checking its co-signatures. Receipts that do not verify are logged and dropped, they
+// would not prove anything.
+func recordSigningReceipt(receipt *pb.SigningReceipt) {
+	if receipt == nil || receipt.SessionId == "" {
+		logger.Sugar().Warnw("Signature returned without a signing receipt")
+		return
+	}
+
+	coSigners, err := libs.VerifySigningReceipt(receipt)
+	if err != nil {
+		logger.Sugar().Errorw("Invalid signing receipt", "session", receipt.SessionId, "intentID", receipt.IntentId, "error", err)
+		return
+	}
+	if len(coSigners) < len(receipt.Signers) {
+		logger.Sugar().Warnw("Signing receipt is missing co-signatures", "session", receipt.SessionId, "coSigners", coSigners, "signers", receipt.Signers)
+	}
+
+	coSignatures := make(map[string]string, len(receipt.CoSignatures))
+	for _, coSignature := range receipt.CoSignatures {
+		coSignatures[coSignature.Signer] = hex.EncodeToString(coSignature.Signature)
+	}
+	err = db.AddSigningReceipt(&db.SigningReceiptSchema{
+		SessionID:      receipt.SessionId,
+		IntentID:       receipt.IntentId,
+		OperationIndex: int(receipt.OperationIndex),
+		MessageHash:    receipt.MessageHash,
+		Signers:        receipt.Signers,
+		Signature:      receipt.Signature,
+		GroupPublicKey: receipt.GroupPublicKey,
+		CoSignatures:   coSignatures,
+		StartedAt:      time.Unix(receipt.Timestamp, 0),
+		CreatedAt:      time.Now(),
+	})
+	if err != nil {
+		logger.Sugar().Errorw("Failed to store signing receipt", "session", receipt.SessionId, "error", err)
+	}
+}
@@ -0,0 +1,196 @@
+package sequencer
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/StripChain/strip-node/common"
+	"github.com/StripChain/strip-node/libs"
+	"github.com/StripChain/strip-node/libs/blockchains"
+	db "github.com/StripChain/strip-node/libs/database"
+	pb "github.com/StripChain/strip-node/libs/proto"
+	"github.com/StripChain/strip-node/util/logger"
+)
+
+// startResharingWallets watches the registered signers and moves the wallets to a new
+// committee when the set changes, so departed signers stop holding usable key shares.
+// The new committee and threshold follow the wallet's policy.
+func startResharingWallets() {
+	go func() {
+		lastSigners := ""
+		for {
+			signers, err := SignersList()
+			if err != nil {
+				logger.Sugar().Errorw("Failed to get signers", "error", err)
+			} else if current := signerSetKey(signers); current != lastSigners {
+				logger.Sugar().Infow("Signer set changed, resharing wallets", "signers", len(signers))
+				if err := reshareWallets(signers); err != nil {
+					logger.Sugar().Errorw("Failed to reshare wallets", "error", err)
+				} else {
+					lastSigners = current
+				}
+			}
+
+			time.Sleep(libs.RESHARE_CHECK_INTERVAL)
+		}
+	}()
+}
+
+func signerSetKey(signers []Signer) string {
+	keys := make([]string, len(signers))
+	for i, signer := range signers {
+		keys[i] = signer.PublicKey
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
+
+// reshareWallets reshares every wallet whose committee is affected by the registered signers.
+// Wallets of an identity on blockchains with the same key curve share their key shares,
+// so they are reshared together.
+func reshareWallets(signers []Signer) error {
+	wallets, err := db.GetWallets()
+	if err != nil {
+		return fmt.Errorf("failed to get wallets: %w", err)
+	}
+
+	heartbeats, err := db.GetHeartbeats()
+	if err != nil {
+		return fmt.Errorf("failed to get heartbeats: %w", err)
+	}
+	healthy := healthySigners(signers, heartbeats)
+
+	urls := make(map[string]string)
+	for _, signer := range signers {
+		urls[signer.PublicKey] = signer.URL
+	}
+
+	groups := make(map[string][]db.WalletSchema)
+	curves := make(map[string]common.Curve)
+	order := []string{}
+	for _, wallet := range wallets {
+		blockchain, err := blockchains.GetBlockchain(wallet.BlockchainID, blockchains.NetworkType(blockchains.Mainnet))
+		if err != nil {
+			logger.Sugar().Errorw("Failed to get blockchain of wallet", "wallet", wallet.Id, "error", err)
+			continue
+		}
+		key := wallet.Identity + "_" + string(blockchain.KeyCurve())
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+			curves[key] = blockchain.KeyCurve()
+		}
+		groups[key] = append(groups[key], wallet)
+	}
+
+	var failed int
+	for _, key := range order {
+		if err := reshareWalletGroup(groups[key], curves[key], healthy, urls); err != nil {
+			logger.Sugar().Errorw("Failed to reshare wallet", "wallet", key, "error", err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to reshare %d of %d wallets", failed, len(order))
+	}
+	return nil
+}
+
+// reshareCommittee returns the committee and threshold the policy of a wallet asks for given
+// the healthy signers, and whether they differ from the current ones
+func reshareCommittee(identity string, oldSigners []string, oldThreshold int, healthy []Signer) ([]string, int, bool, error) {
+	committeeSize, newThreshold, err := walletPolicy(identity).resolve(len(healthy))
+	if err != nil {
+		return nil, 0, false, fmt.Errorf("failed to resolve wallet policy: %w", err)
+	}
+	newSigners := selectCommittee(healthy, oldSigners, committeeSize)
+	changed := !slices.Equal(newSigners, oldSigners) || newThreshold != oldThreshold
+	return newSigners, newThreshold, changed, nil
+}
+
+func reshareWalletGroup(wallets []db.WalletSchema, identityCurve common.Curve, healthy []Signer, urls map[string]string) error {
+	identity := wallets[0].Identity
+	oldSigners := wallets[0].Signers
+	oldThreshold := libs.ResolveThreshold(wallets[0].Threshold, len(oldSigners))
+
+	newSigners, newThreshold, changed, err := reshareCommittee(identity, oldSigners, oldThreshold, healthy)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return nil
+	}
+
+	// The request goes to a signer that stays in the committee, it holds a share before and after
+	coordinator := ""
+	for _, signer := range newSigners {
+		if slices.Contains(oldSigners, signer) {
+			coordinator = signer
+			break
+		}
+	}
+	if coordinator == "" {
+		return fmt.Errorf("no signer of the current committee is still registered")
+	}
+
+	protoCurve, err := libs.CommonCurveToProto(identityCurve)
+	if err != nil {
+		return fmt.Errorf("failed to convert curve to proto: %w", err)
+	}
+
+	client, err := validatorClientManager.GetClient(urls[coordinator])
+	if err != nil {
+		return fmt.Errorf("failed to get validator client: %w", err)
+	}
+
+	logger.Sugar().Infow("Resharing wallet", "identity", identity, "identityCurve", identityCurve, "oldSigners", oldSigners, "newSigners", newSigners, "newThreshold", newThreshold)
+
+	_, err = client.Reshare(context.Background(), &pb.ReshareRequest{
+		Identity:      identity,
+		IdentityCurve: protoCurve,
+		OldSigners:    oldSigners,
+		NewSigners:    newSigners,
+		NewThreshold:  uint32(newThreshold),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reshare: %w", err)
+	}
+
+	// A member that just joined must derive the same keys from its new share
+	verifier := coordinator
+	for _, signer := range newSigners {
+		if !slices.Contains(oldSigners, signer) {
+			verifier = signer
+			break
+		}
+	}
+	verifierClient, err := validatorClientManager.GetClient(urls[verifier])
+	if err != nil {
+		return fmt.Errorf("failed to get validator client: %w", err)
+	}
+	resp, err := verifierClient.GetAddresses(context.Background(), &pb.GetAddressesRequest{
+		Identity:      identity,
+		IdentityCurve: protoCurve,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get addresses: %w", err)
+	}
+
+	for _, wallet := range wallets {
+		if resp.EddsaAddress != wallet.EDDSAPublicKey || resp.EcdsaAddress != wallet.ECDSAPublicKey {
+			return fmt.Errorf("reshared keys of wallet %d do not match its public keys", wallet.Id)
+		}
+	}
+
+	for _, wallet := range wallets {
+		if err := db.UpdateWalletSigners(wallet.Id, newSigners, newThreshold); err != nil {
+			return fmt.Errorf("failed to update signers of wallet %d: %w", wallet.Id, err)
+		}
+	}
+
+	return nil
+}
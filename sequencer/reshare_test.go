@@ -0,0 +1,75 @@
+package sequencer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReshareCommittee(t *testing.T) {
+	MaximumSigners = 3
+	signers := map[string]Signer{
+		"a": {PublicKey: "a", URL: "https://a.example:9000"},
+		"b": {PublicKey: "b", URL: "https://b.example:9000"},
+		"c": {PublicKey: "c", URL: "https://c.example:9000"},
+		"d": {PublicKey: "d", URL: "https://d.example:9000"},
+	}
+	healthy := func(keys ...string) []Signer {
+		result := []Signer{}
+		for _, key := range keys {
+			result = append(result, signers[key])
+		}
+		return result
+	}
+
+	t.Run("unchanged", func(t *testing.T) {
+		committee, threshold, changed, err := reshareCommittee("0xuser", []string{"a", "b"}, 1, healthy("b", "a"))
+		require.NoError(t, err)
+		require.False(t, changed)
+		require.Equal(t, []string{"a", "b"}, committee)
+		require.Equal(t, 1, threshold)
+	})
+
+	t.Run("departed signer is replaced", func(t *testing.T) {
+		committee, threshold, changed, err := reshareCommittee("0xuser", []string{"a", "b", "c"}, 2, healthy("a", "c", "d"))
+		require.NoError(t, err)
+		require.True(t, changed)
+		require.Equal(t, []string{"a", "c", "d"}, committee)
+		require.Equal(t, 2, threshold)
+	})
+
+	t.Run("new signer joins up to the maximum", func(t *testing.T) {
+		committee, threshold, changed, err := reshareCommittee("0xuser", []string{"a", "b"}, 1, healthy("a", "b", "c", "d"))
+		require.NoError(t, err)
+		require.True(t, changed)
+		require.Equal(t, []string{"a", "b", "c"}, committee)
+		require.Equal(t, 2, threshold)
+	})
+
+	t.Run("committee shrinks to the maximum", func(t *testing.T) {
+		MaximumSigners = 2
+		defer func() { MaximumSigners = 3 }()
+		committee, threshold, changed, err := reshareCommittee("0xuser", []string{"a", "b", "c"}, 2, healthy("a", "b", "c"))
+		require.NoError(t, err)
+		require.True(t, changed)
+		require.Equal(t, []string{"a", "b"}, committee)
+		require.Equal(t, 1, threshold)
+	})
+
+	t.Run("policy changes the threshold only", func(t *testing.T) {
+		require.NoError(t, SetWalletPolicies(`{"0xtreasury": {"unanimous": true}}`))
+		defer SetWalletPolicies("")
+		committee, threshold, changed, err := reshareCommittee("0xtreasury", []string{"a", "b", "c"}, 1, healthy("a", "b", "c"))
+		require.NoError(t, err)
+		require.True(t, changed)
+		require.Equal(t, []string{"a", "b", "c"}, committee)
+		require.Equal(t, 2, threshold)
+	})
+
+	t.Run("too few healthy signers for the policy", func(t *testing.T) {
+		require.NoError(t, SetWalletPolicies(`{"0xtreasury": {"signers": 3}}`))
+		defer SetWalletPolicies("")
+		_, _, _, err := reshareCommittee("0xtreasury", []string{"a", "b", "c"}, 2, healthy("a", "b"))
+		require.Error(t, err)
+	})
+}
@@ -1,8 +1,6 @@
 package sequencer
 
 import (
-	"os"
-
 	intentoperatorsregistry "github.com/StripChain/strip-node/intentOperatorsRegistry"
 	"github.com/StripChain/strip-node/libs"
 	db "github.com/StripChain/strip-node/libs/database"
@@ -12,7 +10,6 @@ import (
 
 var MaximumSigners int
 var RPC_URL, IntentOperatorsRegistryContractAddress, SolversRegistryContractAddress, BridgeContractAddress string
-var HeliusApiKey string
 var PrivateKey string
 
 var validatorClientManager *ValidatorClientManager
@@ -22,7 +19,6 @@ func StartSequencer(
 	rpcURL string,
 	intentOperatorsRegistryContractAddress string,
 	solversRegistryContractAddress string,
-	heliusApiKey string,
 	bridgeContractAddress string,
 	privateKey string,
 	valClientManager *ValidatorClientManager,
@@ -35,9 +31,6 @@ func StartSequencer(
 
 	keepAlive := make(chan string)
 
-	HeliusApiKey = heliusApiKey
-	os.Setenv("HELIUS_API_KEY", heliusApiKey)
-
 	intents, err := db.GetIntentsWithStatus(libs.IntentStatusProcessing)
 	if err != nil {
 		logger.Sugar().Fatalf("Failed to get processing intents: %v", err)
@@ -69,6 +62,7 @@ func StartSequencer(
 
 	go startHTTPServer(httpPort)
 	go startCheckingSigner()
+	go startResharingWallets()
 
 	<-keepAlive
 }
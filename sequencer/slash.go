@@ -4,11 +4,13 @@ import (
 	"context"
 	"encoding/hex"
 	"errors"
+	"fmt"
 	"time"
 
 	intentoperatorsregistry "github.com/StripChain/strip-node/intentOperatorsRegistry"
 	"github.com/StripChain/strip-node/libs"
 	db "github.com/StripChain/strip-node/libs/database"
+	pb "github.com/StripChain/strip-node/libs/proto"
 	"github.com/StripChain/strip-node/util/logger"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"google.golang.org/grpc/health/grpc_health_v1"
@@ -114,8 +116,162 @@ func CheckSignersStatus() {
 	}
 }
 
+const (
+	// blameQuorum is how many signers, besides the culprit, must report a culprit of a
+	// session before the blame counts against it
+	blameQuorum = 2
+	// blameWindow is how long the reports of a session are matched with each other
+	blameWindow = 10 * time.Minute
+	// The other signers find the blame of a round once their own session fails, at the
+	// latest when it times out, so they are asked for their reports for a while
+	blameCollectInterval = 30 * time.Second
+	blameCollectAttempts = 12
+)
+
+// fetchBlameReport asks a signer for its report of a session
+var fetchBlameReport = func(signer Signer, sessionID string) (*pb.BlameReport, error) {
+	client, err := validatorClientManager.GetClient(signer.URL)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	resp, err := client.GetBlameReport(ctx, &pb.GetBlameReportRequest{SessionId: sessionID})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Report, nil
+}
+
+// recordBlame stores the blame reports attached to the error of a validator call, and
+// collects the reports of the other signers of the session. A single validator cannot get
+// another slashed: the slashing check only counts culprits reported by several signers.
+func recordBlame(err error) {
+	st, ok := status.FromError(err)
+	if !ok {
+		return
+	}
+
+	for _, detail := range st.Details() {
+		report, ok := detail.(*pb.BlameReport)
+		if !ok {
+			continue
+		}
+
+		logger.Sugar().Warnw("Signers blamed for aborted TSS round", "session", report.SessionId, "task", report.Task, "round", report.Round, "culprits", report.Culprits, "reason", report.Reason, "reporter", report.Reporter)
+		signers, err := SignersList()
+		if err != nil {
+			logger.Sugar().Errorw("Failed to get signers", "error", err)
+			return
+		}
+		if err := verifyBlame(report, signers); err != nil {
+			logger.Sugar().Errorw("Invalid blame report", "session", report.SessionId, "reporter", report.Reporter, "error", err)
+			continue
+		}
+		storeBlame(report)
+		go collectBlameReports(report, signers)
+	}
+}
+
+// verifyBlame checks that a report is signed by a registered signer
+func verifyBlame(report *pb.BlameReport, signers []Signer) error {
+	if err := libs.VerifyBlameReport(report); err != nil {
+		return err
+	}
+	for _, signer := range signers {
+		if signer.PublicKey == report.Reporter {
+			return nil
+		}
+	}
+	return fmt.Errorf("reporter %s is not a signer", report.Reporter)
+}
+
+// storeBlame stores a verified report against the signers it names
+func storeBlame(report *pb.BlameReport) {
+	for _, culprit := range report.Culprits {
+		err := db.AddBlameReport(&db.BlameReportSchema{
+			PublicKey: culprit,
+			SessionID: report.SessionId,
+			Task:      report.Task,
+			Round:     int(report.Round),
+			Reason:    report.Reason,
+			Reporter:  report.Reporter,
+			CreatedAt: time.Now(),
+		})
+		if err != nil {
+			logger.Sugar().Errorw("Failed to store blame report", "signer", culprit, "session", report.SessionId, "error", err)
+		}
+	}
+}
+
+// collectBlameReports asks the signers but the reporter of a report for their own reports
+// of the session, and stores those that verify
+func collectBlameReports(report *pb.BlameReport, signers []Signer) {
+	pending := make(map[string]Signer)
+	for _, signer := range signers {
+		if signer.PublicKey != report.Reporter {
+			pending[signer.PublicKey] = signer
+		}
+	}
+
+	for attempt := 0; attempt < blameCollectAttempts && len(pending) > 0; attempt++ {
+		if attempt > 0 {
+			time.Sleep(blameCollectInterval)
+		}
+		for publicKey, signer := range pending {
+			found, err := fetchBlameReport(signer, report.SessionId)
+			if err != nil || found == nil {
+				continue
+			}
+			delete(pending, publicKey)
+			if found.SessionId != report.SessionId || found.Reporter != publicKey {
+				logger.Sugar().Errorw("Signer returned the blame report of another session or signer", "signer", publicKey, "session", report.SessionId)
+				continue
+			}
+			if err := verifyBlame(found, signers); err != nil {
+				logger.Sugar().Errorw("Invalid blame report", "session", found.SessionId, "reporter", publicKey, "error", err)
+				continue
+			}
+			storeBlame(found)
+		}
+	}
+}
+
+// blamedSigners returns, by signer, the blame reports of the sessions whose blame reached
+// the quorum since the given time: reports from blameQuorum signers naming it
+func blamedSigners(since time.Time) map[string][]db.BlameReportSchema {
+	reports, err := db.GetBlameReportsSince(since.Add(-blameWindow))
+	if err != nil {
+		logger.Sugar().Errorw("Failed to get blame reports", "error", err)
+		return nil
+	}
+
+	type sessionBlame struct {
+		culprit   string
+		sessionID string
+	}
+	reporters := make(map[sessionBlame]map[string]bool)
+	blamed := make(map[string][]db.BlameReportSchema)
+	for _, report := range reports {
+		key := sessionBlame{report.PublicKey, report.SessionID}
+		if reporters[key] == nil {
+			reporters[key] = make(map[string]bool)
+		}
+		if reporters[key][report.Reporter] {
+			continue
+		}
+		reporters[key][report.Reporter] = true
+		// Reports are in order, the blame counts once, when it reaches the quorum
+		if len(reporters[key]) == blameQuorum && report.CreatedAt.After(since) {
+			blamed[report.PublicKey] = append(blamed[report.PublicKey], report)
+		}
+	}
+	return blamed
+}
+
 func startCheckingSigner() {
 	go func() {
+		lastCheck := time.Now().Add(-libs.SLASHING_CHECK_INTERVAL)
 		for {
 			UpdateSignersList()
 			CheckSignersStatus()
@@ -138,15 +294,22 @@ func startCheckingSigner() {
 				return
 			}
 
+			checkedAt := time.Now()
+			blamed := blamedSigners(lastCheck)
+
 			for _, signer := range signers {
 				if !activeSignersMap[signer.PublicKey] {
 					//TODO: Call the contract to apply detailed slashing logic
 					logger.Sugar().Infow("Slash signer", "signer", signer)
+				} else if reports := blamed[signer.PublicKey]; len(reports) > 0 {
+					//TODO: Call the contract to apply detailed slashing logic
+					logger.Sugar().Infow("Slash signer", "signer", signer, "reason", "blamed for aborted TSS rounds", "reports", len(reports), "lastSession", reports[len(reports)-1].SessionID)
 				} else {
 					logger.Sugar().Infow("Signer is acting properly", "signer", signer.URL)
 				}
 			}
 
+			lastCheck = checkedAt
 			time.Sleep(libs.SLASHING_CHECK_INTERVAL)
 		}
 	}()
@@ -0,0 +1,72 @@
+package sequencer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/StripChain/strip-node/libs"
+	db "github.com/StripChain/strip-node/libs/database"
+	pb "github.com/StripChain/strip-node/libs/proto"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestVerifyBlame(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	reporter := hexutil.Encode(crypto.CompressPubkey(&key.PublicKey)[1:])
+
+	report := &pb.BlameReport{SessionId: "s1", Task: "signing", Round: 2, Culprits: []string{"c"}, Reason: "invalid share", Reporter: reporter}
+	digest, err := libs.BlameReportDigest(report)
+	require.NoError(t, err)
+	report.Signature, err = crypto.Sign(digest, key)
+	require.NoError(t, err)
+
+	require.NoError(t, verifyBlame(report, []Signer{{PublicKey: reporter}, {PublicKey: "c"}}))
+	// Only registered signers report blame
+	require.Error(t, verifyBlame(report, []Signer{{PublicKey: "c"}}))
+
+	// The report cannot be altered or attributed to another signer
+	forged := proto.Clone(report).(*pb.BlameReport)
+	forged.Culprits = []string{"d"}
+	require.Error(t, verifyBlame(forged, []Signer{{PublicKey: reporter}}))
+	forged = proto.Clone(report).(*pb.BlameReport)
+	forged.Reporter = "c"
+	require.Error(t, verifyBlame(forged, []Signer{{PublicKey: reporter}, {PublicKey: "c"}}))
+}
+
+func TestBlamedSigners(t *testing.T) {
+	since := time.Now()
+	at := func(minutes int) time.Time { return since.Add(time.Duration(minutes) * time.Minute) }
+	reports := []db.BlameReportSchema{
+		// Reported by one signer only, twice
+		{PublicKey: "c", SessionID: "s1", Reporter: "a", CreatedAt: at(1)},
+		{PublicKey: "c", SessionID: "s1", Reporter: "a", CreatedAt: at(2)},
+		// Reported by two signers
+		{PublicKey: "c", SessionID: "s2", Reporter: "a", CreatedAt: at(1)},
+		{PublicKey: "c", SessionID: "s2", Reporter: "b", CreatedAt: at(2)},
+		// The quorum was reached before the previous check
+		{PublicKey: "b", SessionID: "s3", Reporter: "a", CreatedAt: at(-3)},
+		{PublicKey: "b", SessionID: "s3", Reporter: "c", CreatedAt: at(-2)},
+		// The quorum is reached with a report received after the previous check
+		{PublicKey: "a", SessionID: "s4", Reporter: "b", CreatedAt: at(-3)},
+		{PublicKey: "a", SessionID: "s4", Reporter: "c", CreatedAt: at(1)},
+		{PublicKey: "a", SessionID: "s4", Reporter: "d", CreatedAt: at(2)},
+	}
+
+	previous := db.GetBlameReportsSince
+	defer func() { db.GetBlameReportsSince = previous }()
+	db.GetBlameReportsSince = func(after time.Time) ([]db.BlameReportSchema, error) {
+		require.Equal(t, since.Add(-blameWindow), after)
+		return reports, nil
+	}
+
+	blamed := blamedSigners(since)
+	require.Len(t, blamed, 2)
+	require.Len(t, blamed["c"], 1)
+	require.Equal(t, "s2", blamed["c"][0].SessionID)
+	require.Len(t, blamed["a"], 1)
+	require.Equal(t, "s4", blamed["a"][0].SessionID)
+}
@@ -4,13 +4,13 @@ import (
 	"fmt"
 	"sync"
 
-	// Added for DialContext timeout
+	"github.com/StripChain/strip-node/libs/mtls"
 	pb "github.com/StripChain/strip-node/libs/proto"
 	"github.com/StripChain/strip-node/util/logger"
 
-	// No Secrets Manager client needed *within* the manager anymore
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure" // Keep for potential fallback/logging
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
 type ValidatorServiceClient struct {
@@ -23,71 +23,31 @@ func (v *ValidatorServiceClient) GetClientConn() *grpc.ClientConn {
 }
 
 type ValidatorClientManager struct {
-	clients       map[string]ValidatorServiceClient
-	mutex         sync.Mutex
-	clientCertPEM string // Sequencer's client cert (PEM)
-	clientKeyPEM  string // Sequencer's client key (PEM)
-	serverCaPEM   string // PEM for the CA signing validator server certs (single CA assumed)
-	// No validatorConf map needed if using single CA and no other per-validator settings
+	clients map[string]ValidatorServiceClient
+	mutex   sync.Mutex
+	// creds are the sequencer's mTLS credentials, connections are in plaintext without them
+	creds *mtls.Credentials
 }
 
-// NewValidatorClientManager accepts PEM strings directly
-func NewValidatorClientManager(
-// clientCertPEM string,
-// clientKeyPEM string,
-// serverCaPEM string,
-) (*ValidatorClientManager, error) {
-
-	// // Basic validation of inputs
-	// if clientCertPEM == "" || clientKeyPEM == "" {
-	// 	return nil, fmt.Errorf("client certificate and key PEMs cannot be empty")
-	// }
-	// if serverCaPEM == "" {
-	// 	// Log warning if fallback to insecure is intended, error otherwise
-	// 	logger.Sugar().Warn("Server CA PEM is empty, connections may default to insecure or fail")
-	// 	// return nil, fmt.Errorf("server CA PEM cannot be empty for mTLS")
-	// }
-
+// NewValidatorClientManager creates a manager connecting to validators with the given mTLS
+// credentials, or in plaintext when creds is nil
+func NewValidatorClientManager(creds *mtls.Credentials) (*ValidatorClientManager, error) {
+	if creds == nil {
+		logger.Sugar().Warn("No TLS credentials, validators are called in plaintext")
+	}
 	return &ValidatorClientManager{
 		clients: make(map[string]ValidatorServiceClient),
-		// clientCertPEM: clientCertPEM,
-		// clientKeyPEM:  clientKeyPEM,
-		// serverCaPEM:   serverCaPEM,
+		creds:   creds,
 	}, nil
 }
 
-// getCredentials creates the gRPC dial option using the stored PEMs
+// getCredentials creates the gRPC dial option of the connections to validators. The
+// credentials are reloaded in place, so new connections always use the latest ones.
 func (m *ValidatorClientManager) getCredentials() (grpc.DialOption, error) {
-	// Handle case where server CA wasn't provided - fallback to insecure or error
-	// if m.serverCaPEM == "" {
-	// 	logger.Sugar().Warn("No server CA provided, creating insecure credentials")
-	// 	return grpc.WithTransportCredentials(insecure.NewCredentials()), nil
-	// 	// Or: return nil, fmt.Errorf("cannot create TLS credentials without server CA")
-	// }
-
-	// // Load the client certificate
-	// clientCert, err := tls.X509KeyPair([]byte(m.clientCertPEM), []byte(m.clientKeyPEM))
-	// if err != nil {
-	// 	return nil, fmt.Errorf("failed to load client keypair: %w", err)
-	// }
-
-	// // Create a CA certificate pool and add the server's CA
-	// caPool := x509.NewCertPool()
-	// if !caPool.AppendCertsFromPEM([]byte(m.serverCaPEM)) {
-	// 	return nil, fmt.Errorf("failed to append server CA cert from PEM")
-	// }
-
-	// // Create TLS configuration
-	// tlsConfig := &tls.Config{
-	// 	Certificates: []tls.Certificate{clientCert}, // Client cert to present
-	// 	RootCAs:      caPool,                        // CAs to trust for server verification
-	// 	MinVersion:   tls.VersionTLS12,
-	// 	// Optionally set ServerName based on target URL if needed for hostname verification
-	// 	// InsecureSkipVerify: false, // Ensure this is false (default)
-	// }
-
-	// creds := credentials.NewTLS(tlsConfig)
-	return grpc.WithTransportCredentials(insecure.NewCredentials()), nil
+	if m.creds == nil {
+		return grpc.WithTransportCredentials(insecure.NewCredentials()), nil
+	}
+	return grpc.WithTransportCredentials(credentials.NewTLS(m.creds.ClientConfig())), nil
 }
 
 func (m *ValidatorClientManager) GetClient(url string) (pb.ValidatorServiceClient, error) {
@@ -3,8 +3,6 @@ package sequencer
 import (
 	"context"
 	"fmt"
-	"math/rand"
-	"time"
 
 	"github.com/StripChain/strip-node/libs"
 	"github.com/StripChain/strip-node/libs/blockchains"
@@ -16,9 +14,9 @@ import (
 // createWallet creates a new wallet with the specified identity and blockchain ID.
 // Note: It selects a list of signers, ensuring the number of signers does not exceed MaximumSigners.
 // The function performs the following steps:
-// 1. Selects a random subset of signers if the total number exceeds MaximumSigners.
+// 1. Resolves the (t, n) policy of the identity and selects n healthy signers on distinct hosts where possible.
 // 2. Determines the required key curve based on the blockchain.
-// 3. Sends a gRPC request to the first signer to generate keys.
+// 3. Sends a gRPC request to the first signer to generate keys with threshold t.
 // 4. Waits for the key generation to complete.
 //
 // Parameters:
@@ -37,18 +35,20 @@ func createWallet(identity string, blockchainID blockchains.BlockchainID) error
 		return fmt.Errorf("failed to get signers: %w", err)
 	}
 
-	if len(signers) > MaximumSigners {
-		// select random number of max signers
-		rand.Seed(time.Now().UnixNano())
-		rand.Shuffle(len(signers), func(i, j int) { signers[i], signers[j] = signers[j], signers[i] })
-		signers = signers[:MaximumSigners]
+	heartbeats, err := db.GetHeartbeats()
+	if err != nil {
+		return fmt.Errorf("failed to get heartbeats: %w", err)
 	}
+	healthy := healthySigners(signers, heartbeats)
 
-	signersPublicKeyList := make([]string, len(signers))
-	for i, signer := range signers {
-		signersPublicKeyList[i] = signer.PublicKey
+	policy := walletPolicy(identity)
+	committeeSize, threshold, err := policy.resolve(len(healthy))
+	if err != nil {
+		return fmt.Errorf("failed to resolve wallet policy: %w", err)
 	}
 
+	signersPublicKeyList := selectCommittee(healthy, nil, committeeSize)
+
 	blockchain, err := blockchains.GetBlockchain(blockchainID, blockchains.NetworkType(blockchains.Mainnet))
 	if err != nil {
 		return fmt.Errorf("failed to get blockchain: %w", err)
@@ -59,7 +59,13 @@ func createWallet(identity string, blockchainID blockchains.BlockchainID) error
 		return fmt.Errorf("failed to convert curve to proto: %w", err)
 	}
 
-	client, err := validatorClientManager.GetClient(signers[0].URL)
+	ed25519Curve := pb.Curve_CURVE_EDDSA
+	if policy.Frost {
+		ed25519Curve = pb.Curve_CURVE_FROST
+	}
+
+	// The committee starts with the most recently seen healthy signer
+	client, err := validatorClientManager.GetClient(healthy[0].URL)
 	if err != nil {
 		return fmt.Errorf("failed to get validator client: %w", err)
 	}
@@ -68,8 +74,11 @@ func createWallet(identity string, blockchainID blockchains.BlockchainID) error
 		Identity:      identity,
 		IdentityCurve: protoCurve,
 		Signers:       signersPublicKeyList,
+		Threshold:     uint32(threshold),
+		Ed25519Curve:  ed25519Curve,
 	})
 	if err != nil {
+		recordBlame(err)
 		return fmt.Errorf("failed to keygen: %w", err)
 	}
 
@@ -85,6 +94,7 @@ func createWallet(identity string, blockchainID blockchains.BlockchainID) error
 		Identity:       identity,
 		BlockchainID:   blockchainID,
 		Signers:        signersPublicKeyList,
+		Threshold:      threshold,
 		EDDSAPublicKey: resp.EddsaAddress,
 		ECDSAPublicKey: resp.EcdsaAddress,
 	}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/StripChain/strip-node/libs/blockchains"
 	db "github.com/StripChain/strip-node/libs/database"
@@ -56,11 +57,22 @@ func setupMockServer(t *testing.T) *httptest.Server {
 	}))
 }
 
+// mockHeartbeats reports every signer as healthy
+func mockHeartbeats(signers []Signer) func() ([]db.HeartbeatSchema, error) {
+	return func() ([]db.HeartbeatSchema, error) {
+		heartbeats := make([]db.HeartbeatSchema, len(signers))
+		for i, signer := range signers {
+			heartbeats[i] = db.HeartbeatSchema{PublicKey: signer.PublicKey, UpdatedAt: time.Now()}
+		}
+		return heartbeats, nil
+	}
+}
+
 // Setup test environment for testing
 // 1. Creates a mock HTTP server
 // 2. Sets up mock signers with predefined public keys
 // 3. Creates an in-memory mock database
-// 4. Overrides global functions (SignersList and AddWallet)
+// 4. Overrides global functions (SignersList, GetHeartbeats and AddWallet)
 func setupTestEnvironment(t *testing.T) (*httptest.Server, func()) {
 	// Create mock server
 	mockServer := setupMockServer(t)
@@ -76,6 +88,7 @@ func setupTestEnvironment(t *testing.T) (*httptest.Server, func()) {
 	// Store original functions
 	originalSignersList := SignersList
 	originalAddWallet := db.AddWallet
+	originalGetHeartbeats := db.GetHeartbeats
 
 	// Create mock database
 	mockDB := &mockDB{wallets: make(map[string]db.WalletSchema)}
@@ -85,12 +98,14 @@ func setupTestEnvironment(t *testing.T) (*httptest.Server, func()) {
 		return mockSigners, nil
 	}
 	db.AddWallet = mockDB.AddWallet
+	db.GetHeartbeats = mockHeartbeats(mockSigners)
 
 	// Return cleanup function
 	cleanup := func() {
 		mockServer.Close()
 		SignersList = originalSignersList
 		db.AddWallet = originalAddWallet
+		db.GetHeartbeats = originalGetHeartbeats
 	}
 
 	return mockServer, cleanup
@@ -197,13 +212,19 @@ func TestCreateWalletServerErrors(t *testing.T) {
 
 	// Override SignersList to return error server
 	originalSignersList := SignersList
+	originalGetHeartbeats := db.GetHeartbeats
+	errorSigners := []Signer{
+		{URL: errorServer.URL, PublicKey: "errorKey1"},
+		{URL: errorServer.URL, PublicKey: "errorKey2"},
+	}
 	SignersList = func() ([]Signer, error) {
-		return []Signer{
-			{URL: errorServer.URL, PublicKey: "errorKey1"},
-			{URL: errorServer.URL, PublicKey: "errorKey2"},
-		}, nil
+		return errorSigners, nil
 	}
-	defer func() { SignersList = originalSignersList }()
+	db.GetHeartbeats = mockHeartbeats(errorSigners)
+	defer func() {
+		SignersList = originalSignersList
+		db.GetHeartbeats = originalGetHeartbeats
+	}()
 
 	err := createWallet("testIdentity", "testCurve")
 	if err == nil {
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/StripChain/strip-node/common"
+	"github.com/StripChain/strip-node/libs"
+	pb "github.com/StripChain/strip-node/libs/proto"
+	"github.com/StripChain/strip-node/util/logger"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	libp2pcrypto "github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// The admin API lets the operator of a validator inspect it: the wallets it holds key
+// shares for, its TSS sessions and its peers. It is served on its own listener, bound to
+// localhost by default, and every call must carry the admin token as a bearer token.
+
+// version is the release of the validator, set at build time with
+// -ldflags "-X main.version=..."
+var version = "dev"
+
+// startedAt is when the validator process started
+var startedAt = time.Now()
+
+type adminServer struct {
+	pb.UnimplementedAdminServiceServer
+	host host.Host
+}
+
+// NewAdminServer creates the admin API of the validator running on host
+func NewAdminServer(host host.Host) *adminServer {
+	return &adminServer{host: host}
+}
+
+// adminAuthenticator rejects the calls that do not carry the admin token
+func adminAuthenticator(token string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		for _, value := range md.Get("authorization") {
+			presented, ok := strings.CutPrefix(value, "Bearer ")
+			if ok && subtle.ConstantTimeCompare([]byte(presented), []byte(token)) == 1 {
+				return handler(ctx, req)
+			}
+		}
+		logger.Sugar().Warnw("rejected unauthenticated admin call", "method", info.FullMethod)
+		return nil, status.Error(codes.Unauthenticated, "invalid admin token")
+	}
+}
+
+func startAdminServer(address string, token string, host host.Host) {
+	lis, err := net.Listen("tcp", address)
+	if err != nil {
+		logger.Sugar().Fatalf("Failed to listen for the admin API on %s: %v", address, err)
+	}
+
+	s := grpc.NewServer(
+		grpc.Creds(insecure.NewCredentials()),
+		grpc.UnaryInterceptor(adminAuthenticator(token)),
+	)
+	pb.RegisterAdminServiceServer(s, NewAdminServer(host))
+
+	logger.Sugar().Infof("admin API listening at %s", lis.Addr().String())
+	if err := s.Serve(lis); err != nil {
+		logger.Sugar().Fatalf("Failed to serve the admin API: %v", err)
+	}
+}
+
+// splitKeyShareKey returns the identity and curves of a key share from its row key
+func splitKeyShareKey(kvKey string) (identity string, identityCurve common.Curve, keyCurve common.Curve, err error) {
+	keyIndex := strings.LastIndex(kvKey, "_")
+	if keyIndex < 0 {
+		return "", "", "", fmt.Errorf("invalid key share key %s", kvKey)
+	}
+	identityIndex := strings.LastIndex(kvKey[:keyIndex], "_")
+	if identityIndex < 0 {
+		return "", "", "", fmt.Errorf("invalid key share key %s", kvKey)
+	}
+	return kvKey[:identityIndex], common.Curve(kvKey[identityIndex+1 : keyIndex]), common.Curve(kvKey[keyIndex+1:]), nil
+}
+
+func (s *adminServer) ListKeyShares(ctx context.Context, req *pb.ListKeySharesRequest) (*pb.ListKeySharesResponse, error) {
+	records, err := getKeyShareRecords()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to read key shares: %v", err)
+	}
+
+	resp := &pb.ListKeySharesResponse{KeyShares: make([]*pb.KeyShareInfo, 0, len(records))}
+	for _, record := range records {
+		identity, identityCurve, keyCurve, err := splitKeyShareKey(record.Key)
+		if err != nil {
+			logger.Sugar().Warnw("skipping key share with an invalid key", "key", record.Key)
+			continue
+		}
+		info := &pb.KeyShareInfo{Identity: identity}
+		// Curves this version does not know are left unspecified
+		info.IdentityCurve, _ = libs.CommonCurveToProto(identityCurve)
+		info.KeyCurve, _ = libs.CommonCurveToProto(keyCurve)
+
+		if record.Signers != "" {
+			if err := json.Unmarshal([]byte(record.Signers), &info.Signers); err != nil {
+				logger.Sugar().Warnw("key share has invalid signers", "key", record.Key, "error", err)
+			}
+		}
+		threshold := libs.ResolveThreshold(0, len(info.Signers))
+		if record.Threshold != "" {
+			if threshold, err = strconv.Atoi(record.Threshold); err != nil {
+				logger.Sugar().Warnw("key share has an invalid threshold", "key", record.Key, "error", err)
+			}
+		}
+		info.Threshold = uint32(threshold)
+		if !record.CreatedAt.IsZero() {
+			info.CreatedAt = timestamppb.New(record.CreatedAt)
+		}
+		resp.KeyShares = append(resp.KeyShares, info)
+	}
+	return resp, nil
+}
+
+func (s *adminServer) ListSessions(ctx context.Context, req *pb.ListSessionsRequest) (*pb.ListSessionsResponse, error) {
+	snapshots := sessions.List()
+	resp := &pb.ListSessionsResponse{Sessions: make([]*pb.SessionInfo, 0, len(snapshots))}
+	for _, snapshot := range snapshots {
+		info := &pb.SessionInfo{
+			SessionId:    snapshot.ID,
+			Type:         snapshot.Info.Type,
+			Status:       string(snapshot.Status),
+			Identity:     snapshot.Info.Identity,
+			Participants: snapshot.Info.Participants,
+			Round:        int32(snapshot.Round),
+			StartedAt:    timestamppb.New(snapshot.StartedAt),
+		}
+		if snapshot.Info.KeyCurve != "" {
+			info.KeyCurve, _ = libs.CommonCurveToProto(snapshot.Info.KeyCurve)
+		}
+		if snapshot.Err != nil {
+			info.Error = snapshot.Err.Error()
+		}
+		resp.Sessions = append(resp.Sessions, info)
+	}
+	return resp, nil
+}
+
+func (s *adminServer) AbortSession(ctx context.Context, req *pb.AbortSessionRequest) (*pb.AbortSessionResponse, error) {
+	if req.SessionId == "" {
+		return nil, status.Error(codes.InvalidArgument, "session_id is required")
+	}
+	if _, _, ok := sessions.Status(req.SessionId); !ok {
+		return nil, status.Errorf(codes.NotFound, "session %s not found", req.SessionId)
+	}
+
+	sessions.Cancel(req.SessionId)
+	sessionStatus, _, _ := sessions.Status(req.SessionId)
+	logger.Sugar().Warnw("session aborted by the operator", "session", req.SessionId, "status", sessionStatus)
+	return &pb.AbortSessionResponse{Status: string(sessionStatus)}, nil
+}
+
+// peerSigner returns the signer public key behind a peer, the X coordinate of its
+// secp256k1 key, or "" when the peer has another kind of key
+func peerSigner(id peer.ID) string {
+	pub, err := id.ExtractPublicKey()
+	if err != nil {
+		return ""
+	}
+	secp, ok := pub.(*libp2pcrypto.Secp256k1PublicKey)
+	if !ok {
+		return ""
+	}
+	raw, err := secp.Raw()
+	if err != nil || len(raw) != 33 {
+		return ""
+	}
+	return hexutil.Encode(raw[1:])
+}
+
+func (s *adminServer) GetPeers(ctx context.Context, req *pb.GetPeersRequest) (*pb.GetPeersResponse, error) {
+	if s.host == nil {
+		return nil, status.Error(codes.Unavailable, "p2p host is not running")
+	}
+
+	subscribed := map[peer.ID]bool{}
+	if topic != nil {
+		for _, id := range topic.ListPeers() {
+			subscribed[id] = true
+		}
+	}
+
+	resp := &pb.GetPeersResponse{PeerId: s.host.ID().String()}
+	for _, id := range s.host.Peerstore().Peers() {
+		if id == s.host.ID() {
+			continue
+		}
+		connected := s.host.Network().Connectedness(id) == network.Connected
+		if !connected && !subscribed[id] {
+			continue
+		}
+		info := &pb.PeerInfo{
+			PeerId:     id.String(),
+			Signer:     peerSigner(id),
+			Connected:  connected,
+			Subscribed: subscribed[id],
+		}
+		for _, addr := range s.host.Peerstore().Addrs(id) {
+			info.Addresses = append(info.Addresses, addr.String())
+		}
+		resp.Peers = append(resp.Peers, info)
+	}
+	return resp, nil
+}
+
+// buildCommit returns the VCS revision the validator was built from, when Go recorded it
+func buildCommit() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" {
+			return setting.Value
+		}
+	}
+	return ""
+}
+
+func (s *adminServer) GetVersion(ctx context.Context, req *pb.GetVersionRequest) (*pb.GetVersionResponse, error) {
+	return &pb.GetVersionResponse{
+		Version:   version,
+		Commit:    buildCommit(),
+		GoVersion: runtime.Version(),
+		StartedAt: timestamppb.New(startedAt),
+	}, nil
+}
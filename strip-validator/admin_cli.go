@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	pb "github.com/StripChain/strip-node/libs/proto"
+	"github.com/StripChain/strip-node/util"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// adminCallTimeout bounds a call of the admin command
+const adminCallTimeout = 10 * time.Second
+
+// errAdminUsage is returned for admin commands called with the wrong arguments
+var errAdminUsage = errors.New("usage: strip-validator admin [-addr host:port] [-token token] keys|sessions|abort <session id>|peers|version")
+
+// runAdminCommand runs `strip-validator admin`, which calls the admin API of a running
+// validator and prints the result
+func runAdminCommand(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("admin", flag.ContinueOnError)
+	addr := flags.String("addr", util.LookupEnvOrString("ADMIN_ADDR", "127.0.0.1:50052"), "address of the admin API")
+	token := flags.String("token", util.LookupEnvOrString("ADMIN_TOKEN", ""), "admin API token")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return errAdminUsage
+	}
+
+	conn, err := grpc.NewClient(*addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", *addr, err)
+	}
+	defer conn.Close()
+	client := pb.NewAdminServiceClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), adminCallTimeout)
+	defer cancel()
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+*token)
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	defer w.Flush()
+
+	switch command := flags.Arg(0); command {
+	case "keys":
+		resp, err := client.ListKeyShares(ctx, &pb.ListKeySharesRequest{})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, "IDENTITY\tIDENTITY CURVE\tKEY CURVE\tTHRESHOLD\tSIGNERS\tCREATED")
+		for _, share := range resp.KeyShares {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d/%d\t%s\t%s\n", share.Identity, share.IdentityCurve, share.KeyCurve, share.Threshold, len(share.Signers), strings.Join(share.Signers, ","), formatAdminTime(share.CreatedAt))
+		}
+	case "sessions":
+		resp, err := client.ListSessions(ctx, &pb.ListSessionsRequest{})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, "SESSION\tTYPE\tSTATUS\tROUND\tAGE\tIDENTITY\tPARTICIPANTS\tERROR")
+		for _, session := range resp.Sessions {
+			age := time.Since(session.StartedAt.AsTime()).Truncate(time.Second)
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\t%d\t%s\n", session.SessionId, session.Type, session.Status, session.Round, age, session.Identity, len(session.Participants), session.Error)
+		}
+	case "abort":
+		if flags.NArg() != 2 {
+			return errAdminUsage
+		}
+		resp, err := client.AbortSession(ctx, &pb.AbortSessionRequest{SessionId: flags.Arg(1)})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "session %s is %s\n", flags.Arg(1), resp.Status)
+	case "peers":
+		resp, err := client.GetPeers(ctx, &pb.GetPeersRequest{})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "self: %s\n", resp.PeerId)
+		fmt.Fprintln(w, "PEER\tSIGNER\tCONNECTED\tSUBSCRIBED\tADDRESSES")
+		for _, peer := range resp.Peers {
+			fmt.Fprintf(w, "%s\t%s\t%t\t%t\t%s\n", peer.PeerId, peer.Signer, peer.Connected, peer.Subscribed, strings.Join(peer.Addresses, ","))
+		}
+	case "version":
+		resp, err := client.GetVersion(ctx, &pb.GetVersionRequest{})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "version:\t%s\ncommit:\t%s\ngo:\t%s\nstarted:\t%s\n", resp.Version, resp.Commit, resp.GoVersion, formatAdminTime(resp.StartedAt))
+	default:
+		return fmt.Errorf("unknown admin command %s\n%w", command, errAdminUsage)
+	}
+	return nil
+}
+
+func formatAdminTime(t *timestamppb.Timestamp) string {
+	if t == nil {
+		return "-"
+	}
+	return t.AsTime().Local().Format(time.RFC3339)
+}
+
+// adminMain runs the admin command and exits
+func adminMain(args []string) {
+	if err := runAdminCommand(args, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
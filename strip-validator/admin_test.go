@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/StripChain/strip-node/common"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestAdminAuthenticator(t *testing.T) {
+	interceptor := adminAuthenticator("secret")
+	info := &grpc.UnaryServerInfo{FullMethod: "/validator.AdminService/GetVersion"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+
+	tests := []struct {
+		name     string
+		metadata metadata.MD
+		want     codes.Code
+	}{
+		{"valid token", metadata.Pairs("authorization", "Bearer secret"), codes.OK},
+		{"wrong token", metadata.Pairs("authorization", "Bearer other"), codes.Unauthenticated},
+		{"token without scheme", metadata.Pairs("authorization", "secret"), codes.Unauthenticated},
+		{"no token", nil, codes.Unauthenticated},
+	}
+
+	for _, tt := range tests {
+		ctx := context.Background()
+		if tt.metadata != nil {
+			ctx = metadata.NewIncomingContext(ctx, tt.metadata)
+		}
+		_, err := interceptor(ctx, nil, info, handler)
+		if got := status.Code(err); got != tt.want {
+			t.Errorf("%s: code = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSplitKeyShareKey(t *testing.T) {
+	identity, identityCurve, keyCurve, err := splitKeyShareKey("0xabc_ecdsa_eddsa")
+	if err != nil || identity != "0xabc" || identityCurve != common.CurveEcdsa || keyCurve != common.CurveEddsa {
+		t.Errorf("splitKeyShareKey = %s, %s, %s, %v", identity, identityCurve, keyCurve, err)
+	}
+	// Identities can contain the separator
+	if identity, _, _, err := splitKeyShareKey("a_b_ecdsa_ecdsa"); err != nil || identity != "a_b" {
+		t.Errorf("splitKeyShareKey = %s, %v, want a_b", identity, err)
+	}
+	if _, _, _, err := splitKeyShareKey("ecdsa"); err == nil {
+		t.Error("invalid key was accepted")
+	}
+}
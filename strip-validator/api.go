@@ -7,6 +7,7 @@ import (
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -22,7 +23,6 @@ import (
 	identityVerification "github.com/StripChain/strip-node/identity"
 	"github.com/StripChain/strip-node/libs"
 	"github.com/StripChain/strip-node/libs/blockchains"
-	db "github.com/StripChain/strip-node/libs/database"
 	"github.com/StripChain/strip-node/ripple"
 	"github.com/StripChain/strip-node/util/logger"
 	"github.com/stellar/go/strkey"
@@ -35,6 +35,7 @@ import (
 	"github.com/coming-chat/go-sui/v2/lib"
 	"github.com/decred/dcrd/dcrec/edwards/v2"
 	"github.com/mr-tron/base58"
+	"google.golang.org/protobuf/proto"
 )
 
 type BurnMetadata struct {
@@ -59,8 +60,11 @@ type WithdrawMetadata struct {
 	Unlock bool   `json:"unlock"`
 }
 
-var messageChan = make(map[string]chan (Message))
-var keygenGeneratedChan = make(map[string]chan (string))
+// TrustSetMetadata defines the metadata required for the TRUST_SET operation
+type TrustSetMetadata struct {
+	Token string `json:"token"` // Issued currency as CURRENCY.rIssuer
+	Limit string `json:"limit"` // Trust line limit in base units
+}
 
 var (
 	ECDSA_CURVE       = "ecdsa"
@@ -76,29 +80,51 @@ var (
 	CARDANO_CURVE = "cardano_eddsa"
 )
 
-func generateKeygenMessage(identity string, identityCurve common.Curve, keyCurve common.Curve, signers []string) {
+func generateKeygenMessage(sessionID string, identity string, identityCurve common.Curve, keyCurve common.Curve, signers []string, threshold int) {
 	message := Message{
+		SessionID:     sessionID,
 		Type:          MESSAGE_TYPE_GENERATE_START_KEYGEN,
 		Identity:      identity,
 		IdentityCurve: identityCurve,
 		KeyCurve:      keyCurve,
 		Signers:       signers,
+		Threshold:     threshold,
 	}
 
 	broadcast(message)
 }
 
-func generateSignatureMessage(identity string, blockchainID blockchains.BlockchainID, identityCurve common.Curve, keyCurve common.Curve, msg []byte) {
+// generateSignatureMessage starts the signing of msg by a wallet and returns the session the
+// signature is handed back through
+func generateSignatureMessage(requestID string, origin signingOrigin, identity string, blockchainID blockchains.BlockchainID, identityCurve common.Curve, keyCurve common.Curve, msg []byte) string {
+	return generateDerivedSignatureMessage(requestID, origin, identity, "", blockchainID, identityCurve, keyCurve, msg)
+}
+
+// generateDerivedSignatureMessage starts the signing of msg by the child key of a wallet at
+// derivationPath, or by the wallet itself when the path is empty. origin is the operation
+// recorded in the receipt of the signing.
+func generateDerivedSignatureMessage(requestID string, origin signingOrigin, identity string, derivationPath string, blockchainID blockchains.BlockchainID, identityCurve common.Curve, keyCurve common.Curve, msg []byte) string {
 	message := Message{
-		Type:          MESSAGE_TYPE_START_SIGN,
-		Hash:          msg,
-		Identity:      identity,
-		IdentityCurve: identityCurve,
-		BlockchainID:  blockchainID,
-		KeyCurve:      keyCurve,
+		SessionID:      signSessionID(requestID, identity, derivationPath, identityCurve, keyCurve, msg),
+		Type:           MESSAGE_TYPE_START_SIGN,
+		Hash:           msg,
+		Identity:       identity,
+		IdentityCurve:  identityCurve,
+		BlockchainID:   blockchainID,
+		KeyCurve:       keyCurve,
+		DerivationPath: derivationPath,
+		IntentID:       origin.IntentID,
+		OperationIndex: origin.OperationIndex,
+	}
+	if origin.intent != nil {
+		intent, err := proto.Marshal(origin.intent)
+		if err != nil {
+			logger.Sugar().Errorw("failed to encode the intent of a signing", "session", message.SessionID, "error", err)
+		}
+		message.Intent = intent
 	}
 
-	broadcast(message)
+	return startSigning(message)
 }
 
 type CreateWallet struct {
@@ -106,6 +132,7 @@ type CreateWallet struct {
 	IdentityCurve common.Curve `json:"identityCurve"`
 	KeyCurve      common.Curve `json:"keyCurve"`
 	Signers       []string     `json:"signers"`
+	Threshold     int          `json:"threshold,omitempty"`
 }
 
 type SignMessage struct {
@@ -180,46 +207,32 @@ func startHTTPServer(port string) {
 			return
 		}
 
-		// Create channel for keygen results with error handling
-		keygenGeneratedChan[key] = make(chan string)
-		errorChan := make(chan error, 1)
-
-		go func() {
-			defer func() {
-				if r := recover(); r != nil {
-					logger.Sugar().Errorw("Panic in keygen operation", "error", fmt.Sprintf("%v", r))
-					errorChan <- fmt.Errorf("internal server error: panic in keygen operation")
-				}
-			}()
-
-			generateKeygenMessage(createWallet.Identity, createWallet.IdentityCurve, createWallet.KeyCurve, createWallet.Signers)
-		}()
+		sessionID := newSessionID()
+		sessions.Expect(sessionID)
+		generateKeygenMessage(sessionID, createWallet.Identity, createWallet.IdentityCurve, createWallet.KeyCurve, createWallet.Signers, createWallet.Threshold)
 
-		logger.Sugar().Infow("Waiting for keygen operation to complete", "key", key)
+		logger.Sugar().Infow("Waiting for keygen operation to complete", "key", key, "session", sessionID)
 
-		// Add timeout to prevent hanging requests
-		select {
-		case <-keygenGeneratedChan[key]:
-			logger.Sugar().Infow("Keygen operation completed successfully",
-				"identity", createWallet.Identity,
-				"identityCurve", createWallet.IdentityCurve,
-				"keyCurve", createWallet.KeyCurve)
-			w.WriteHeader(http.StatusOK)
-			w.Write([]byte("Keygen operation completed successfully"))
-		case err := <-errorChan:
+		if _, err := sessions.Wait(r.Context(), sessionID); err != nil {
 			logger.Sugar().Errorw("Keygen operation failed",
-				"identity", createWallet.Identity,
-				"error", err.Error())
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-		case <-time.After(5 * time.Minute): // 5 minute timeout should be sufficient for keygen
-			logger.Sugar().Errorw("Keygen operation timed out",
 				"identity", createWallet.Identity,
 				"identityCurve", createWallet.IdentityCurve,
-				"keyCurve", createWallet.KeyCurve)
-			http.Error(w, "keygen operation timed out", http.StatusGatewayTimeout)
+				"keyCurve", createWallet.KeyCurve,
+				"error", err.Error())
+			if errors.Is(err, ErrSessionTimeout) {
+				http.Error(w, "keygen operation timed out", http.StatusGatewayTimeout)
+			} else {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
 		}
 
-		delete(keygenGeneratedChan, key)
+		logger.Sugar().Infow("Keygen operation completed successfully",
+			"identity", createWallet.Identity,
+			"identityCurve", createWallet.IdentityCurve,
+			"keyCurve", createWallet.KeyCurve)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("Keygen operation completed successfully"))
 	})
 
 	http.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
@@ -248,7 +261,12 @@ func startHTTPServer(port string) {
 			Addresses: make(map[blockchains.BlockchainID]map[blockchains.NetworkType]string),
 		}
 		for _, keyCurve := range []common.Curve{common.CurveEcdsa, common.CurveEddsa} {
-			keyShare, err := GetKeyShare(identity, identityCurveEnum, keyCurve)
+			walletCurve, err := walletKeyCurve(identity, identityCurveEnum, keyCurve)
+			if err != nil {
+				http.Error(w, "error from postgres", http.StatusBadRequest)
+				return
+			}
+			keyShare, err := GetKeyShare(identity, identityCurveEnum, walletCurve)
 
 			if err != nil {
 				http.Error(w, "error from postgres", http.StatusBadRequest)
@@ -541,307 +559,12 @@ func startHTTPServer(port string) {
 				msg = *operation.DataToSign
 			}
 		case libs.OperationTypeSendToBridge:
-			// Verify only operation for bridging
-			// Get bridgewallet by calling /getBridgeAddress from sequencer api
-			req, err := http.NewRequest("GET", SequencerHost+"/getBridgeAddress", nil)
-			if err != nil {
-				logger.Sugar().Errorw("error creating request", "error", err)
-				return
-			}
-
-			req.Header.Set("Content-Type", "application/json")
-			client := &http.Client{}
-			resp, err := client.Do(req)
-			if err != nil {
-				logger.Sugar().Errorw("error sending request", "error", err)
-				return
-			}
-
-			defer resp.Body.Close()
-
-			body, err := io.ReadAll(resp.Body)
-			if err != nil {
-				logger.Sugar().Errorw("error reading response body", "error", err)
-				return
-			}
-
-			var bridgeWallet db.WalletSchema
-			err = json.Unmarshal(body, &bridgeWallet)
-			if err != nil {
-				logger.Sugar().Errorw("error unmarshalling response body", "error", err)
+			// The destination is checked against the bridge wallet this node derives itself
+			if err := verifyBridgeDestination(opBlockchain, operation); err != nil {
+				http.Error(w, fmt.Sprintf("{\"error\":\"%s\"}", err.Error()), http.StatusBadRequest)
 				return
 			}
 
-			// if operation.KeyCurve == "ecdsa" || operation.KeyCurve == "bitcoin_ecdsa" || operation.KeyCurve == "dogecoin_ecdsa" {
-			// 	chain, err := common.GetChain(operation.ChainId)
-			// 	if err != nil {
-			// 		logger.Sugar().Errorw("error getting chain", "error", err)
-			// 		return
-			// 	}
-
-			// 	// Extract destination address from serialized transaction
-			// 	var destAddress string
-			// 	if chain.ChainType == "bitcoin" || chain.ChainType == "dogecoin" {
-			// 		// For Bitcoin, decode the serialized transaction to get output address
-			// 		var tx wire.MsgTx
-			// 		txBytes, err := hex.DecodeString(operation.SerializedTxn)
-			// 		if err != nil {
-			// 			logger.Sugar().Errorw("error decoding bitcoin&dogecoin transaction", "error", err)
-			// 			return
-			// 		}
-			// 		if err := tx.Deserialize(bytes.NewReader(txBytes)); err != nil {
-			// 			logger.Sugar().Errorw("error deserializing bitcoin&dogecoin transaction", "error", err)
-			// 			return
-			// 		}
-			// 		// Get the first output's address (assuming it's the bridge address)
-			// 		if len(tx.TxOut) > 0 {
-			// 			_, addrs, _, err := txscript.ExtractPkScriptAddrs(tx.TxOut[0].PkScript, nil)
-			// 			if err != nil || len(addrs) == 0 {
-			// 				logger.Sugar().Errorw("error extracting bitcoin&dogecoin address", "error", err)
-			// 				return
-			// 			}
-			// 			destAddress = addrs[0].String()
-			// 		}
-			// 	} else {
-			// 		// For EVM chains, decode the transaction to get the 'to' address
-			// 		txBytes, err := hex.DecodeString(operation.SerializedTxn)
-			// 		if err != nil {
-			// 			logger.Sugar().Errorw("error decoding EVM transaction", "error", err)
-			// 			return
-			// 		}
-			// 		tx := new(types.Transaction)
-			// 		if err := rlp.DecodeBytes(txBytes, tx); err != nil {
-			// 			logger.Sugar().Errorw("error deserializing EVM transaction", "error", err)
-			// 			return
-			// 		}
-			// 		// Check if this is an ERC20 transfer (function signature: transfer(address,uint256))
-			// 		// ERC20 transfer function signature is: 0xa9059cbb
-			// 		isERC20Transfer := false
-			// 		contractAddress := tx.To().Hex()
-			// 		if tx.Data() != nil && len(tx.Data()) >= 4 && hex.EncodeToString(tx.Data()[:4]) == "a9059cbb" {
-			// 			// This is an ERC20 transfer - extract the recipient address from the data
-			// 			// The recipient address is the first parameter of the transfer function (32-byte padded)
-			// 			isERC20Transfer = true
-			// 			if len(tx.Data()) >= 36 {
-			// 				// Extract the recipient address from position 4:36 (32 bytes)
-			// 				recipientBytes := tx.Data()[4:36]
-			// 				// Convert to address (take last 20 bytes for proper Ethereum address length)
-			// 				destAddress = "0x" + hex.EncodeToString(recipientBytes[12:])
-			// 				logger.Sugar().Infow("detected ERC20 transfer", "contract", contractAddress, "recipient", destAddress)
-			// 			} else {
-			// 				logger.Sugar().Errorw("invalid ERC20 transfer data length", "data", hex.EncodeToString(tx.Data()))
-			// 				return
-			// 			}
-			// 		} else {
-			// 			// For regular ETH transfers, use the 'to' address directly
-			// 			destAddress = contractAddress
-			// 		}
-
-			// 		// Verify destination address matches bridge wallet
-			// 		var expectedAddress string
-			// 		if chain.ChainType == "bitcoin" {
-			// 			expectedAddress = bridgeWallet.BitcoinMainnetPublicKey
-			// 		} else if chain.ChainType == "dogecoin" {
-			// 			expectedAddress = bridgeWallet.DogecoinMainnetPublicKey
-			// 		} else {
-			// 			// For Ethereum chains, determine if we need to use the bridge contract address
-			// 			if chain.ChainType == "evm" && !isERC20Transfer {
-			// 				// For native ETH transfers, get the bridge address from the dedicated endpoint
-			// 				bridgeReq, err := http.NewRequest("GET", SequencerHost+"/getBridgeAddress", nil)
-			// 				if err != nil {
-			// 					logger.Sugar().Errorw("error creating bridge address request", "error", err)
-			// 					return
-			// 				}
-
-			// 				bridgeReq.Header.Set("Content-Type", "application/json")
-			// 				bridgeClient := &http.Client{}
-			// 				bridgeResp, err := bridgeClient.Do(bridgeReq)
-			// 				if err != nil {
-			// 					logger.Sugar().Errorw("error fetching bridge address", "error", err)
-			// 					return
-			// 				}
-
-			// 				defer bridgeResp.Body.Close()
-
-			// 				bridgeBody, err := io.ReadAll(bridgeResp.Body)
-			// 				if err != nil {
-			// 					logger.Sugar().Errorw("error reading bridge address response", "error", err)
-			// 					return
-			// 				}
-
-			// 				var bridgeAddressWallet db.WalletSchema
-			// 				err = json.Unmarshal(bridgeBody, &bridgeAddressWallet)
-			// 				if err != nil {
-			// 					logger.Sugar().Errorw("error unmarshalling bridge address response", "error", err)
-			// 					return
-			// 				}
-
-			// 				expectedAddress = bridgeAddressWallet.ECDSAPublicKey
-			// 				logger.Sugar().Infow("using bridge contract address for native ETH transfer", "address", expectedAddress)
-			// 			} else {
-			// 				expectedAddress = bridgeWallet.ECDSAPublicKey
-			// 			}
-			// 		}
-
-			// 		// Verify the extracted destination matches the bridge wallet
-			// 		if !strings.EqualFold(destAddress, expectedAddress) {
-			// 			logger.Sugar().Errorw("Invalid bridge destination address", "expected", expectedAddress, "got", destAddress)
-			// 			return
-			// 		}
-			// 	}
-			// } else if operation.KeyCurve == "eddsa" || operation.KeyCurve == "aptos_eddsa" || operation.KeyCurve == "stellar_eddsa" || operation.KeyCurve == "algorand_eddsa" || operation.KeyCurve == "ripple_eddsa" || operation.KeyCurve == "cardano_eddsa" || operation.KeyCurve == "sui_eddsa" {
-			// 	chain, err := common.GetChain(operation.ChainId)
-			// 	if err != nil {
-			// 		logger.Sugar().Errorw("error getting chain", "error", err)
-			// 		return
-			// 	}
-
-			// 	// Verify destination address matches bridge wallet based on chain type
-			// 	var validDestination bool
-			// 	var destAddress string
-
-			// 	// Extract destination address from serialized transaction based on chain type
-			// 	switch chain.ChainType {
-			// 	case "solana":
-			// 		// Decode base58 transaction and extract destination
-			// 		decodedTxn, err := base58.Decode(operation.SerializedTxn)
-			// 		if err != nil {
-			// 			logger.Sugar().Errorw("error decoding Solana transaction", "error", err)
-			// 			return
-			// 		}
-			// 		tx, err := solanasdk.TransactionFromDecoder(bin.NewBinDecoder(decodedTxn))
-			// 		if err != nil || len(tx.Message.Instructions) == 0 {
-			// 			logger.Sugar().Errorw("error deserializing Solana transaction", "error", err)
-			// 			return
-			// 		}
-			// 		// Get the first instruction's destination account index
-			// 		destAccountIndex := tx.Message.Instructions[0].Accounts[1]
-			// 		// Get the actual account address from the message accounts
-			// 		destAddress = tx.Message.AccountKeys[destAccountIndex].String()
-			// 	case "aptos":
-			// 		// For Aptos, the destination is in the transaction payload
-			// 		var aptosPayload struct {
-			// 			Function string   `json:"function"`
-			// 			Args     []string `json:"arguments"`
-			// 		}
-			// 		if err := json.Unmarshal([]byte(operation.SerializedTxn), &aptosPayload); err != nil {
-			// 			logger.Sugar().Errorw("error parsing Aptos transaction", "error", err)
-			// 			return
-			// 		}
-			// 		if len(aptosPayload.Args) > 0 {
-			// 			destAddress = aptosPayload.Args[0] // First arg is typically the recipient
-			// 		}
-			// 	case "stellar":
-			// 		// For Stellar, parse the XDR transaction envelope
-			// 		var txEnv xdr.TransactionEnvelope
-			// 		err := xdr.SafeUnmarshalBase64(operation.SerializedTxn, &txEnv)
-			// 		if err != nil {
-			// 			logger.Sugar().Errorw("error parsing Stellar transaction", "error", err)
-			// 			return
-			// 		}
-
-			// 		// Get the first operation's destination
-			// 		if len(txEnv.Operations()) > 0 {
-			// 			if paymentOp, ok := txEnv.Operations()[0].Body.GetPaymentOp(); ok {
-			// 				destAddress = paymentOp.Destination.Address()
-			// 			}
-			// 		}
-			// 	case "algorand":
-			// 		txnBytes, err := base64.StdEncoding.DecodeString(operation.SerializedTxn)
-			// 		if err != nil {
-			// 			logger.Sugar().Errorw("failed to decode serialized transaction", "error", err)
-			// 			return
-			// 		}
-			// 		var txn algorandTypes.Transaction
-			// 		err = msgpack.Decode(txnBytes, &txn)
-			// 		if err != nil {
-			// 			logger.Sugar().Errorw("failed to deserialize transaction", "error", err)
-			// 			return
-			// 		}
-			// 		if txn.Type == algorandTypes.PaymentTx {
-			// 			destAddress = txn.PaymentTxnFields.Receiver.String()
-			// 		} else if txn.Type == algorandTypes.AssetTransferTx {
-			// 			destAddress = txn.AssetTransferTxnFields.AssetReceiver.String()
-			// 		} else {
-			// 			logger.Sugar().Errorw("Unknown transaction type", "type", txn.Type)
-			// 			return
-			// 		}
-			// 	case "ripple":
-			// 		// For Ripple, the destination is in the transaction payload
-			// 		// Decode the serialized transaction
-			// 		txBytes, err := hex.DecodeString(strings.TrimPrefix(operation.SerializedTxn, "0x"))
-			// 		if err != nil {
-			// 			logger.Sugar().Errorw("error decoding transaction", "error", err)
-			// 			return
-			// 		}
-
-			// 		// Parse the transaction
-			// 		var tx data.Payment
-			// 		err = json.Unmarshal(txBytes, &tx)
-			// 		if err != nil {
-			// 			logger.Sugar().Errorw("error unmarshalling transaction", "error", err)
-			// 			return
-			// 		}
-			// 		destAddress = tx.Destination.String()
-			// 	case "cardano":
-			// 		var tx cardanolib.Tx
-			// 		txBytes, err := hex.DecodeString(operation.SerializedTxn)
-			// 		if err != nil {
-			// 			logger.Sugar().Errorw("error decoding Cardano transaction", "error", err)
-			// 			return
-			// 		}
-			// 		if err := json.Unmarshal(txBytes, &tx); err != nil {
-			// 			logger.Sugar().Errorw("error parsing Cardano transaction", "error", err)
-			// 			return
-			// 		}
-			// 		destAddress = tx.Body.Outputs[0].Address.String()
-			// 	case "sui":
-			// 		var tx sui_types.TransactionData
-			// 		txBytes, err := base64.StdEncoding.DecodeString(operation.SerializedTxn)
-			// 		if err != nil {
-			// 			logger.Sugar().Errorw("error decoding Sui transaction", "error", err)
-			// 			return
-			// 		}
-			// 		if err := json.Unmarshal(txBytes, &tx); err != nil {
-			// 			logger.Sugar().Errorw("error parsing Sui transaction", "error", err)
-			// 			return
-			// 		}
-			// 		if len(tx.V1.Kind.ProgrammableTransaction.Inputs) < 1 {
-			// 			logger.Sugar().Errorw("wrong format sui transaction", "error", err)
-			// 			return
-			// 		}
-			// 		destAddress = string(*tx.V1.Kind.ProgrammableTransaction.Inputs[0].Pure)
-			// 	}
-
-			// 	// Verify the extracted destination matches the bridge wallet
-			// 	if destAddress == "" {
-			// 		logger.Sugar().Errorw("Failed to extract destination address from %s transaction", chain.ChainType)
-			// 		validDestination = false
-			// 	} else {
-			// 		switch chain.ChainType {
-			// 		case "solana":
-			// 			validDestination = strings.EqualFold(destAddress, bridgeWallet.EDDSAPublicKey)
-			// 		case "aptos":
-			// 			validDestination = strings.EqualFold(destAddress, bridgeWallet.AptosEDDSAPublicKey)
-			// 		case "stellar":
-			// 			validDestination = strings.EqualFold(destAddress, bridgeWallet.StellarPublicKey)
-			// 		case "algorand":
-			// 			validDestination = strings.EqualFold(destAddress, bridgeWallet.AlgorandEDDSAPublicKey)
-			// 		case "ripple":
-			// 			validDestination = strings.EqualFold(destAddress, bridgeWallet.RippleEDDSAPublicKey)
-			// 		case "cardano":
-			// 			validDestination = strings.EqualFold(destAddress, bridgeWallet.CardanoPublicKey)
-			// 		case "sui":
-			// 			validDestination = strings.EqualFold(destAddress, bridgeWallet.SuiPublicKey)
-			// 		}
-			// 	}
-
-			// 	if !validDestination {
-			// 		logger.Sugar().Errorw("Invalid bridge destination address for", "chain", chain.ChainType)
-			// 		return
-			// 	}
-			// }
-
 			// Set message
 			msg = ""
 			if operation.DataToSign != nil {
@@ -1126,37 +849,6 @@ func startHTTPServer(port string) {
 				return
 			}
 
-			// Get bridgewallet by calling /getwallet from sequencer api
-			req, err := http.NewRequest("GET", SequencerHost+"/getWallet?identity="+intent.Identity+"&blockchain="+string(intent.BlockchainID), nil)
-			if err != nil {
-				logger.Sugar().Errorw("error creating request", "error", err)
-				return
-			}
-
-			req.Header.Set("Content-Type", "application/json")
-			client := &http.Client{}
-			resp, err := client.Do(req)
-			if err != nil {
-				logger.Sugar().Errorw("error sending request", "error", err)
-				return
-			}
-
-			defer resp.Body.Close()
-
-			body, err := io.ReadAll(resp.Body)
-			if err != nil {
-				logger.Sugar().Errorw("error reading response body", "error", err)
-				return
-			}
-
-			var bridgeWallet db.WalletSchema
-			err = json.Unmarshal(body, &bridgeWallet)
-			if err != nil {
-				logger.Sugar().Errorw("error unmarshalling response body", "error", err)
-				return
-			}
-
-			// Skip wallet verification to bypass the JSON unmarshalling issue
 			// Use the data to sign directly, like in the BURN operation
 			logger.Sugar().Infow("BURN_SYNTHETIC: Using direct signature approach",
 				"operationId", operation.ID,
@@ -1248,6 +940,25 @@ func startHTTPServer(port string) {
 				return
 			}
 
+			// Set message
+			msg = operation.SolverDataToSign
+		case libs.OperationTypeTrustSet:
+			if operation.BlockchainID != blockchains.Ripple {
+				logger.Sugar().Errorw("Trust lines are only supported on Ripple", "blockchainID", operation.BlockchainID)
+				return
+			}
+
+			var trustSetMetadata TrustSetMetadata
+			err := json.Unmarshal([]byte(operation.SolverMetadata), &trustSetMetadata)
+			if err != nil || trustSetMetadata.Token == "" || trustSetMetadata.Limit == "" {
+				logger.Sugar().Errorw("Invalid metadata for trust set operation at signing", "error", err)
+				return
+			}
+			if err := verifyTrustSet(opBlockchain, intent.Identity, intentBlockchain.KeyCurve(), operation, trustSetMetadata); err != nil {
+				http.Error(w, fmt.Sprintf("{\"error\":\"%s\"}", err.Error()), http.StatusBadRequest)
+				return
+			}
+
 			// Set message
 			msg = operation.SolverDataToSign
 		}
@@ -1255,7 +966,16 @@ func startHTTPServer(port string) {
 		identity := intent.Identity
 
 		identityCurve := intentBlockchain.KeyCurve()
-		keyCurve := opBlockchain.KeyCurve()
+		keyCurve, err := walletKeyCurve(identity, identityCurve, opBlockchain.KeyCurve())
+		if err != nil {
+			http.Error(w, fmt.Sprintf("{\"error\":\"%s\"}", err.Error()), http.StatusInternalServerError)
+			return
+		}
+		bridgeKeyCurve, err := walletKeyCurve(BridgeContractAddress, common.CurveEcdsa, common.CurveEddsa)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("{\"error\":\"%s\"}", err.Error()), http.StatusInternalServerError)
+			return
+		}
 		log.Println("msg", msg)
 
 		// verify signature
@@ -1282,6 +1002,17 @@ func startHTTPServer(port string) {
 			return
 		}
 
+		// The other signers verify the intent themselves before they sign
+		protoIntent, err := libs.IntentToProto(&intent)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("{\"error\":\"%s\"}", err.Error()), http.StatusInternalServerError)
+			return
+		}
+		origin := signingOrigin{IntentID: intent.ID.String(), OperationIndex: operationIndex, intent: protoIntent}
+
+		requestID := newSessionID()
+		var sessionID string
+
 		switch operation.BlockchainID {
 		case blockchains.Solana:
 			msgBytes, err := base58.Decode(msg)
@@ -1294,29 +1025,29 @@ func startHTTPServer(port string) {
 				operation.Type == libs.OperationTypeBurnSynthetic ||
 				operation.Type == libs.OperationTypeWithdraw {
 				logger.Sugar().Infow("Generating signature message for withdraw on Solana")
-				go generateSignatureMessage(BridgeContractAddress, operation.BlockchainID, common.CurveEcdsa, common.CurveEddsa, msgBytes)
+				sessionID = generateSignatureMessage(requestID, origin, BridgeContractAddress, operation.BlockchainID, common.CurveEcdsa, bridgeKeyCurve, msgBytes)
 			} else {
 				logger.Sugar().Infow("Generating signature message for other operations on Solana")
-				go generateSignatureMessage(identity, operation.BlockchainID, identityCurve, keyCurve, msgBytes)
+				sessionID = generateSignatureMessage(requestID, origin, identity, operation.BlockchainID, identityCurve, keyCurve, msgBytes)
 			}
 		case blockchains.Bitcoin:
-			go generateSignatureMessage(identity, operation.BlockchainID, identityCurve, keyCurve, []byte(msg))
+			sessionID = generateSignatureMessage(requestID, origin, identity, operation.BlockchainID, identityCurve, keyCurve, []byte(msg))
 		case blockchains.Dogecoin:
-			go generateSignatureMessage(identity, operation.BlockchainID, identityCurve, keyCurve, []byte(msg))
+			sessionID = generateSignatureMessage(requestID, origin, identity, operation.BlockchainID, identityCurve, keyCurve, []byte(msg))
 		case blockchains.Sui:
 			// For Sui, we need to format the message according to Sui's standards
 			// The message should be prefixed with "Sui Message:" for personal messages
 			// suiMsg := []byte("Sui Message:" + msg)
 			// go generateSignatureMessage(identity, identityCurve, keyCurve, suiMsg)
 			msgBytes, _ := lib.NewBase64Data(msg)
-			go generateSignatureMessage(identity, operation.BlockchainID, identityCurve, keyCurve, *msgBytes)
+			sessionID = generateSignatureMessage(requestID, origin, identity, operation.BlockchainID, identityCurve, keyCurve, *msgBytes)
 		case blockchains.Stellar:
 			msgBytes, err := base64.StdEncoding.DecodeString(msg)
 			if err != nil {
 				http.Error(w, fmt.Sprintf("{\"error\":\"%s\"}", err.Error()), http.StatusInternalServerError)
 				return
 			}
-			go generateSignatureMessage(identity, operation.BlockchainID, identityCurve, keyCurve, msgBytes)
+			sessionID = generateSignatureMessage(requestID, origin, identity, operation.BlockchainID, identityCurve, keyCurve, msgBytes)
 		case blockchains.Algorand:
 			// For Algorand, decode the base32 message first
 			// msgBytes, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(msg)
@@ -1325,14 +1056,22 @@ func startHTTPServer(port string) {
 				http.Error(w, fmt.Sprintf("{\"error\":\"%s\"}", err.Error()), http.StatusInternalServerError)
 				return
 			}
-			go generateSignatureMessage(identity, operation.BlockchainID, identityCurve, keyCurve, msgBytes)
+			sessionID = generateSignatureMessage(requestID, origin, identity, operation.BlockchainID, identityCurve, keyCurve, msgBytes)
 		case blockchains.Ripple, blockchains.Cardano, blockchains.Aptos:
 			msgBytes, err := hex.DecodeString(msg)
 			if err != nil {
 				http.Error(w, fmt.Sprintf("{\"error\":\"%s\"}", err.Error()), http.StatusInternalServerError)
 				return
 			}
-			go generateSignatureMessage(identity, operation.BlockchainID, identityCurve, keyCurve, msgBytes)
+			if operation.BlockchainID == blockchains.Ripple &&
+				(operation.Type == libs.OperationTypeSwap ||
+					operation.Type == libs.OperationTypeBurn ||
+					operation.Type == libs.OperationTypeBurnSynthetic ||
+					operation.Type == libs.OperationTypeWithdraw) {
+				sessionID = generateSignatureMessage(requestID, origin, BridgeContractAddress, operation.BlockchainID, common.CurveEcdsa, bridgeKeyCurve, msgBytes)
+			} else {
+				sessionID = generateSignatureMessage(requestID, origin, identity, operation.BlockchainID, identityCurve, keyCurve, msgBytes)
+			}
 		default:
 			if blockchains.IsEVMBlockchain(operation.BlockchainID) {
 				if operation.Type == libs.OperationTypeBridgeDeposit ||
@@ -1340,9 +1079,9 @@ func startHTTPServer(port string) {
 					operation.Type == libs.OperationTypeBurn ||
 					operation.Type == libs.OperationTypeBurnSynthetic ||
 					operation.Type == libs.OperationTypeWithdraw {
-					go generateSignatureMessage(BridgeContractAddress, operation.BlockchainID, identityCurve, keyCurve, []byte(msg))
+					sessionID = generateSignatureMessage(requestID, origin, BridgeContractAddress, operation.BlockchainID, identityCurve, keyCurve, []byte(msg))
 				} else {
-					go generateSignatureMessage(identity, operation.BlockchainID, identityCurve, keyCurve, []byte(msg))
+					sessionID = generateSignatureMessage(requestID, origin, identity, operation.BlockchainID, identityCurve, keyCurve, []byte(msg))
 				}
 			} else {
 				http.Error(w, "{\"error\":\"Invalid key curve for signature\"}", http.StatusBadRequest)
@@ -1350,17 +1089,16 @@ func startHTTPServer(port string) {
 			}
 		}
 
-		// Create a channel using the message as the key. The key format varies by chain:
-		// - Solana: base58 encoded string (from client)
-		// - Bitcoin/Aptos/Ripple/Cardano: hex encoded string
-		// - Algorand: base64 encoded string (from client)
-		// - Stellar: base32 encoded string (from client)
-		// - Ethereum: raw bytes as string
-		// This same format must be used in message.go when looking up the channel
-		messageChan[msg] = make(chan Message)
-
-		// Wait for the signature to be sent back through the channel
-		sig := <-messageChan[msg]
+		// Wait for the signature to be sent back through the session
+		sig, _, err := waitSignature(r.Context(), sessionID)
+		if errors.Is(err, errSigningBusy) {
+			http.Error(w, fmt.Sprintf("{\"error\":\"%s\"}", err.Error()), http.StatusServiceUnavailable)
+			return
+		}
+		if err != nil {
+			http.Error(w, fmt.Sprintf("{\"error\":\"%s\"}", err.Error()), http.StatusInternalServerError)
+			return
+		}
 
 		signatureResponse := SignatureResponse{}
 
@@ -1434,8 +1172,6 @@ func startHTTPServer(port string) {
 			http.Error(w, fmt.Sprintf("{\"error\":\"Error building the response: %v\"}", err), http.StatusInternalServerError)
 			return
 		}
-
-		delete(messageChan, msg)
 	})
 
 	log.Fatal(http.ListenAndServe("0.0.0.0:"+port, nil))
@@ -150,7 +150,7 @@ func TestKeygenEndpoint(t *testing.T) {
 					}
 					key := createWallet.Identity + "_" + string(createWallet.IdentityCurve) + "_" + string(createWallet.KeyCurve)
 
-					keygenGeneratedChan[key] = make(chan string)
+					sessions.Expect(key)
 
 					w.WriteHeader(http.StatusOK)
 				}).ServeHTTP(w, r)
@@ -399,9 +399,11 @@ func TestSignatureEndpoint(t *testing.T) {
 		Data []byte `json:"data"`
 	}
 
+	serializedTxnA := "eb808477359400825208945e721f69f4c3c91befeb94b1e068d2e64a82a7f488016345785d8a000080808080"
+	dataToSignA := "bc0efa2d6c1a0fcb888e82d400a8273e88ee641b7b615e071dafdc0f4b44c91f"
 	operationA := libs.Operation{
-		SerializedTxn:  "eb808477359400825208945e721f69f4c3c91befeb94b1e068d2e64a82a7f488016345785d8a000080808080",
-		DataToSign:     "bc0efa2d6c1a0fcb888e82d400a8273e88ee641b7b615e071dafdc0f4b44c91f",
+		SerializedTxn:  &serializedTxnA,
+		DataToSign:     &dataToSignA,
 		BlockchainID:   blockchains.Ethereum,
 		GenesisHash:    "",
 		Type:           libs.OperationTypeTransaction,
@@ -409,9 +411,11 @@ func TestSignatureEndpoint(t *testing.T) {
 		SolverMetadata: "",
 	}
 
+	serializedTxnB := "eb018477359400825208941e79929f2a49c27f820340d83b9d0dec35b2137788016345785d8a000080808080"
+	dataToSignB := "2a007c39f2eb743d9afde9e7043d4a22bd262f324fce1c4afc0eb200483eb959"
 	operationB := libs.Operation{
-		SerializedTxn: "eb018477359400825208941e79929f2a49c27f820340d83b9d0dec35b2137788016345785d8a000080808080",
-		DataToSign:    "2a007c39f2eb743d9afde9e7043d4a22bd262f324fce1c4afc0eb200483eb959",
+		SerializedTxn: &serializedTxnB,
+		DataToSign:    &dataToSignB,
 		BlockchainID:  blockchains.Ethereum,
 		GenesisHash:   "",
 		Status:        libs.OperationStatusPending,
@@ -0,0 +1,377 @@
+package main
+
+import (
+	"context"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/StripChain/strip-node/common"
+	"github.com/StripChain/strip-node/libs"
+	"github.com/StripChain/strip-node/libs/keystore"
+	"github.com/StripChain/strip-node/util/logger"
+	"github.com/bnb-chain/tss-lib/v2/crypto"
+	ecdsaKeygen "github.com/bnb-chain/tss-lib/v2/ecdsa/keygen"
+	eddsaKeygen "github.com/bnb-chain/tss-lib/v2/eddsa/keygen"
+	"github.com/bnb-chain/tss-lib/v2/tss"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethCrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+// Key share backups are JSON archives whose shares are sealed to an operator recovery key,
+// an age X25519 recipient whose identity is kept offline. Validators only need the
+// recipient to write backups, restoring them needs the identity. Archives are signed with
+// the node key, and a node only restores the archives it signed itself, so shares written
+// by anyone else holding the recipient are never imported.
+
+// keyShareBackupVersion is the version of the backup archives this validator writes and
+// reads, archives are signed since version 2
+const keyShareBackupVersion = 2
+
+// keyShareBackupDir gets an incremental backup after every keygen and resharing, none
+// are written when it is empty
+var keyShareBackupDir string
+
+// keyShareRecovery seals the shares of incremental backups
+var keyShareRecovery keystore.IKeyWrapper
+
+// KeyShareBackup is a backup archive of the key shares of a validator. The signature is
+// the node's signature of the archive without it.
+type KeyShareBackup struct {
+	Version   int                   `json:"version"`
+	Node      string                `json:"node"`
+	CreatedAt time.Time             `json:"createdAt"`
+	Shares    []KeyShareBackupEntry `json:"shares"`
+	Signature string                `json:"signature,omitempty"`
+}
+
+// KeyShareBackupEntry is a key share in a backup. The share is sealed to the recovery key
+// with its row key as associated data, the public key is the wallet's, to check the share
+// against when it is restored.
+type KeyShareBackupEntry struct {
+	Key       string       `json:"key"`
+	KeyCurve  common.Curve `json:"keyCurve"`
+	PublicKey string       `json:"publicKey"`
+	Share     string       `json:"share"`
+	Signers   string       `json:"signers"`
+	Threshold string       `json:"threshold,omitempty"`
+}
+
+// keyCurveOfShare returns the key curve of a key share from its row key, which ends with it
+func keyCurveOfShare(kvKey string) (common.Curve, error) {
+	index := strings.LastIndex(kvKey, "_")
+	if index < 0 {
+		return "", fmt.Errorf("invalid key share key %s", kvKey)
+	}
+	switch curve := common.Curve(kvKey[index+1:]); curve {
+	case common.CurveEcdsa, common.CurveEddsa, common.CurveFrost:
+		return curve, nil
+	default:
+		return "", fmt.Errorf("key share %s has unsupported key curve %s", kvKey, curve)
+	}
+}
+
+// keySharePublicKey returns the public key of a key share after checking that the share
+// is the one its public shares commit to
+func keySharePublicKey(keyCurve common.Curve, share string) (string, error) {
+	switch keyCurve {
+	case common.CurveEcdsa:
+		var save ecdsaKeygen.LocalPartySaveData
+		if err := json.Unmarshal([]byte(share), &save); err != nil {
+			return "", fmt.Errorf("failed to unmarshal key share: %w", err)
+		}
+		if save.ECDSAPub == nil {
+			return "", errors.New("key share has no public key")
+		}
+		if err := checkShareCommitment(tss.S256(), save.Xi, save.ShareID, save.Ks, save.BigXj); err != nil {
+			return "", err
+		}
+		return ecdsaSharePublicKey(&save), nil
+	case common.CurveEddsa, common.CurveFrost:
+		var save eddsaKeygen.LocalPartySaveData
+		if err := json.Unmarshal([]byte(share), &save); err != nil {
+			return "", fmt.Errorf("failed to unmarshal key share: %w", err)
+		}
+		if save.EDDSAPub == nil {
+			return "", errors.New("key share has no public key")
+		}
+		if err := checkShareCommitment(tss.Edwards(), save.Xi, save.ShareID, save.Ks, save.BigXj); err != nil {
+			return "", err
+		}
+		return eddsaSharePublicKey(&save), nil
+	default:
+		return "", fmt.Errorf("unsupported key curve %s", keyCurve)
+	}
+}
+
+// checkShareCommitment checks that the secret share of a party matches its public share
+func checkShareCommitment(curve elliptic.Curve, xi *big.Int, shareID *big.Int, ks []*big.Int, bigXj []*crypto.ECPoint) error {
+	if xi == nil || shareID == nil {
+		return errors.New("key share has no secret share")
+	}
+	for j, k := range ks {
+		if k == nil || k.Cmp(shareID) != 0 {
+			continue
+		}
+		if j >= len(bigXj) || bigXj[j] == nil || !crypto.ScalarBaseMult(curve, xi).Equals(bigXj[j]) {
+			return errors.New("key share does not match its public share")
+		}
+		return nil
+	}
+	return errors.New("key share is not one of its committee")
+}
+
+// keyShareBackupDigest returns the digest a node signs an archive with: the Keccak-256
+// hash of the JSON encoding of the archive without its signature
+func keyShareBackupDigest(backup KeyShareBackup) ([]byte, error) {
+	backup.Signature = ""
+	data, err := json.Marshal(backup)
+	if err != nil {
+		return nil, err
+	}
+	return ethCrypto.Keccak256(data), nil
+}
+
+// signKeyShareBackup signs an archive with the node key
+func signKeyShareBackup(backup *KeyShareBackup) error {
+	digest, err := keyShareBackupDigest(*backup)
+	if err != nil {
+		return err
+	}
+	privateKey, err := nodeKey()
+	if err != nil {
+		return fmt.Errorf("failed to read the node key: %w", err)
+	}
+	signature, err := ethCrypto.Sign(digest, privateKey)
+	if err != nil {
+		return fmt.Errorf("failed to sign key share backup: %w", err)
+	}
+	backup.Signature = hexutil.Encode(signature)
+	return nil
+}
+
+// verifyKeyShareBackup checks that an archive was written and signed by node
+func verifyKeyShareBackup(backup KeyShareBackup, node string) error {
+	if backup.Node != node {
+		return fmt.Errorf("backup of node %s cannot be restored on node %s", backup.Node, node)
+	}
+	signature, err := hexutil.Decode(backup.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+	digest, err := keyShareBackupDigest(backup)
+	if err != nil {
+		return err
+	}
+	signer, err := libs.ReceiptSigner(digest, signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+	if signer != node {
+		return fmt.Errorf("backup is signed by %s", signer)
+	}
+	return nil
+}
+
+// sealBackupEntry decrypts a stored key share and seals it to the recovery key
+func sealBackupEntry(ctx context.Context, recovery keystore.IKeyWrapper, record keyShareRecord) (KeyShareBackupEntry, error) {
+	keyCurve, err := keyCurveOfShare(record.Key)
+	if err != nil {
+		return KeyShareBackupEntry{}, err
+	}
+	share, err := openKeyShare(record.Key, record.Share)
+	if err != nil {
+		return KeyShareBackupEntry{}, err
+	}
+	publicKey, err := keySharePublicKey(keyCurve, share)
+	if err != nil {
+		return KeyShareBackupEntry{}, fmt.Errorf("key share %s: %w", record.Key, err)
+	}
+	sealed, err := keystore.Seal(ctx, recovery, []byte(share), []byte(record.Key))
+	if err != nil {
+		return KeyShareBackupEntry{}, fmt.Errorf("failed to encrypt key share %s: %w", record.Key, err)
+	}
+
+	return KeyShareBackupEntry{
+		Key:       record.Key,
+		KeyCurve:  keyCurve,
+		PublicKey: publicKey,
+		Share:     sealed,
+		Signers:   record.Signers,
+		Threshold: record.Threshold,
+	}, nil
+}
+
+// writeKeyShareBackup writes the key shares stored under kvKeys, or all of them, to a
+// backup archive at path
+func writeKeyShareBackup(ctx context.Context, recovery keystore.IKeyWrapper, path string, kvKeys ...string) (int, error) {
+	records, err := getKeyShareRecords(kvKeys...)
+	if err != nil {
+		return 0, err
+	}
+
+	backup := KeyShareBackup{
+		Version:   keyShareBackupVersion,
+		Node:      NodePublicKey,
+		CreatedAt: time.Now().UTC(),
+		Shares:    make([]KeyShareBackupEntry, 0, len(records)),
+	}
+	for _, record := range records {
+		entry, err := sealBackupEntry(ctx, recovery, record)
+		if err != nil {
+			return 0, err
+		}
+		backup.Shares = append(backup.Shares, entry)
+	}
+	if err := signKeyShareBackup(&backup); err != nil {
+		return 0, err
+	}
+
+	data, err := json.MarshalIndent(backup, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	// Written aside and renamed so an interrupted backup never leaves a truncated archive
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return 0, fmt.Errorf("failed to write key share backup: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return 0, fmt.Errorf("failed to write key share backup: %w", err)
+	}
+	return len(backup.Shares), nil
+}
+
+// BackupKeyShares writes every key share of this node to a backup archive at path
+func BackupKeyShares(ctx context.Context, recovery keystore.IKeyWrapper, path string) (int, error) {
+	return writeKeyShareBackup(ctx, recovery, path)
+}
+
+// backupKeyShareIncrement writes the key share of a wallet to its own archive in the
+// backup directory, after a keygen or resharing changed it. A failed backup is logged,
+// the wallet's share is already stored.
+func backupKeyShareIncrement(identity string, identityCurve common.Curve, keyCurve common.Curve) {
+	if keyShareBackupDir == "" || keyShareRecovery == nil {
+		return
+	}
+
+	kvKey := keyShareKey(identity, identityCurve, keyCurve)
+	digest := sha256.Sum256([]byte(kvKey))
+	// Archive names sort by time, so restoring the directory applies the latest share last
+	name := fmt.Sprintf("%s-%s.json", time.Now().UTC().Format("20060102T150405.000000000Z"), hex.EncodeToString(digest[:8]))
+	if _, err := writeKeyShareBackup(context.Background(), keyShareRecovery, filepath.Join(keyShareBackupDir, name), kvKey); err != nil {
+		logger.Sugar().Errorw("Failed to back up key share", "key", kvKey, "error", err)
+		return
+	}
+	logger.Sugar().Infow("Backed up key share", "key", kvKey, "archive", name)
+}
+
+// readKeyShareBackups reads a backup archive, or every archive of a backup directory, checks
+// they were signed by node and returns the latest entry of each key share
+func readKeyShareBackups(path string, node string) ([]KeyShareBackupEntry, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key share backup: %w", err)
+	}
+	files := []string{path}
+	if info.IsDir() {
+		if files, err = filepath.Glob(filepath.Join(path, "*.json")); err != nil {
+			return nil, err
+		}
+		sort.Strings(files)
+	}
+
+	latest := map[string]int{}
+	entries := []KeyShareBackupEntry{}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read key share backup: %w", err)
+		}
+		var backup KeyShareBackup
+		if err := json.Unmarshal(data, &backup); err != nil {
+			return nil, fmt.Errorf("failed to parse key share backup %s: %w", file, err)
+		}
+		if backup.Version != keyShareBackupVersion {
+			return nil, fmt.Errorf("key share backup %s has unsupported version %d", file, backup.Version)
+		}
+		if err := verifyKeyShareBackup(backup, node); err != nil {
+			return nil, fmt.Errorf("key share backup %s: %w", file, err)
+		}
+		for _, entry := range backup.Shares {
+			if index, ok := latest[entry.Key]; ok {
+				entries[index] = entry
+				continue
+			}
+			latest[entry.Key] = len(entries)
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// openBackupEntry decrypts a key share of a backup and checks it is a share of the
+// wallet's public key
+func openBackupEntry(ctx context.Context, recovery keystore.IKeyWrapper, entry KeyShareBackupEntry) (string, error) {
+	keyCurve, err := keyCurveOfShare(entry.Key)
+	if err != nil {
+		return "", err
+	}
+	if keyCurve != entry.KeyCurve {
+		return "", fmt.Errorf("key share %s is recorded with key curve %s", entry.Key, entry.KeyCurve)
+	}
+	share, err := keystore.Open(ctx, recovery, entry.Share, []byte(entry.Key))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt key share %s: %w", entry.Key, err)
+	}
+	publicKey, err := keySharePublicKey(keyCurve, string(share))
+	if err != nil {
+		return "", fmt.Errorf("key share %s: %w", entry.Key, err)
+	}
+	if publicKey != entry.PublicKey {
+		return "", fmt.Errorf("key share %s does not belong to wallet %s", entry.Key, entry.PublicKey)
+	}
+	return string(share), nil
+}
+
+// RestoreKeyShares re-imports the key shares of a backup archive or directory written by
+// this node. Every share is checked before any is stored, and shares the node already
+// holds are left alone.
+func RestoreKeyShares(ctx context.Context, recovery keystore.IKeyWrapper, path string) (restored int, skipped int, err error) {
+	entries, err := readKeyShareBackups(path, NodePublicKey)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	records := make([]keyShareRecord, len(entries))
+	for i, entry := range entries {
+		share, err := openBackupEntry(ctx, recovery, entry)
+		if err != nil {
+			return 0, 0, err
+		}
+		records[i] = keyShareRecord{Key: entry.Key, Share: share, Signers: entry.Signers, Threshold: entry.Threshold}
+	}
+
+	for _, record := range records {
+		stored, err := restoreKeyShare(record)
+		if err != nil {
+			return restored, skipped, fmt.Errorf("failed to restore key share %s: %w", record.Key, err)
+		}
+		if stored {
+			restored++
+		} else {
+			logger.Sugar().Warnw("Key share already exists, leaving it as it is", "key", record.Key)
+			skipped++
+		}
+	}
+	return restored, skipped, nil
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/StripChain/strip-node/common"
+	ecdsaKeygen "github.com/bnb-chain/tss-lib/v2/ecdsa/keygen"
+	"github.com/bnb-chain/tss-lib/v2/tss"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestKeyCurveOfShare(t *testing.T) {
+	tests := []struct {
+		key     string
+		want    common.Curve
+		wantErr bool
+	}{
+		{key: "0xabc_ecdsa_ecdsa", want: common.CurveEcdsa},
+		{key: "0xabc_ecdsa_eddsa", want: common.CurveEddsa},
+		{key: "0xabc_ecdsa_sr25519", wantErr: true},
+		{key: "noseparator", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := keyCurveOfShare(tt.key)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("keyCurveOfShare(%q) = %s, want an error", tt.key, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("keyCurveOfShare(%q) = %s, %v, want %s", tt.key, got, err, tt.want)
+		}
+	}
+}
+
+func TestKeySharePublicKey(t *testing.T) {
+	curve := tss.S256()
+	_, shares, bigXj := testShares(t, curve)
+	other, _, _ := testShares(t, curve)
+
+	key := ecdsaKeygen.NewLocalPartySaveData(len(shares))
+	key.Xi, key.ShareID = shares[1].Share, shares[1].ID
+	for j, share := range shares {
+		key.Ks[j] = share.ID
+	}
+	copy(key.BigXj, bigXj)
+	key.ECDSAPub = bigXj[0]
+
+	share, err := json.Marshal(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey, err := keySharePublicKey(common.CurveEcdsa, string(share))
+	if err != nil {
+		t.Fatalf("keySharePublicKey: %v", err)
+	}
+	if publicKey != ecdsaSharePublicKey(&key) {
+		t.Errorf("public key = %s, want %s", publicKey, ecdsaSharePublicKey(&key))
+	}
+
+	// A share its committee does not commit to is rejected
+	key.Xi = other
+	tampered, err := json.Marshal(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keySharePublicKey(common.CurveEcdsa, string(tampered)); err == nil {
+		t.Error("tampered key share was accepted")
+	}
+	key.Xi, key.ShareID = shares[1].Share, big.NewInt(9)
+	foreign, err := json.Marshal(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keySharePublicKey(common.CurveEcdsa, string(foreign)); err == nil {
+		t.Error("key share of another committee was accepted")
+	}
+}
+
+// useTestNodeKey sets a throwaway node key for the duration of a test
+func useTestNodeKey(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	previousKey, previousPublicKey := NodePrivateKey, NodePublicKey
+	t.Cleanup(func() { NodePrivateKey, NodePublicKey = previousKey, previousPublicKey })
+	NodePrivateKey = hexutil.Encode(crypto.FromECDSA(key))
+	NodePublicKey = signerPublicKey(&key.PublicKey)
+}
+
+func writeTestBackup(t *testing.T, path string, backup KeyShareBackup) {
+	data, err := json.Marshal(backup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadKeyShareBackupsKeepsLatest(t *testing.T) {
+	useTestNodeKey(t)
+	dir := t.TempDir()
+	write := func(name string, entries ...KeyShareBackupEntry) {
+		backup := KeyShareBackup{Version: keyShareBackupVersion, Node: NodePublicKey, Shares: entries}
+		if err := signKeyShareBackup(&backup); err != nil {
+			t.Fatal(err)
+		}
+		writeTestBackup(t, filepath.Join(dir, name), backup)
+	}
+	write("20250101T000000.000000000Z-full.json",
+		KeyShareBackupEntry{Key: "a_ecdsa_ecdsa", Share: "old"},
+		KeyShareBackupEntry{Key: "b_ecdsa_eddsa", Share: "b"},
+	)
+	write("20250102T000000.000000000Z-increment.json", KeyShareBackupEntry{Key: "a_ecdsa_ecdsa", Share: "new"})
+
+	entries, err := readKeyShareBackups(dir, NodePublicKey)
+	if err != nil {
+		t.Fatalf("readKeyShareBackups: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Share != "new" || entries[1].Share != "b" {
+		t.Errorf("entries = %+v", entries)
+	}
+
+	writeTestBackup(t, filepath.Join(dir, "20250103T000000.000000000Z-future.json"), KeyShareBackup{Version: keyShareBackupVersion + 1})
+	if _, err := readKeyShareBackups(dir, NodePublicKey); err == nil {
+		t.Error("backup with an unsupported version was accepted")
+	}
+}
+
+func TestReadKeyShareBackupsVerifiesSignature(t *testing.T) {
+	useTestNodeKey(t)
+	path := filepath.Join(t.TempDir(), "backup.json")
+	backup := KeyShareBackup{Version: keyShareBackupVersion, Node: NodePublicKey, Shares: []KeyShareBackupEntry{{Key: "a_ecdsa_ecdsa", Share: "a"}}}
+	if err := signKeyShareBackup(&backup); err != nil {
+		t.Fatal(err)
+	}
+	writeTestBackup(t, path, backup)
+	if _, err := readKeyShareBackups(path, NodePublicKey); err != nil {
+		t.Fatalf("readKeyShareBackups: %v", err)
+	}
+
+	// Archives of another node are not restored
+	node := NodePublicKey
+	if _, err := readKeyShareBackups(path, "0x01"); err == nil {
+		t.Error("backup of another node was accepted")
+	}
+
+	tampered := backup
+	tampered.Shares = []KeyShareBackupEntry{{Key: "a_ecdsa_ecdsa", Share: "forged"}}
+	writeTestBackup(t, path, tampered)
+	if _, err := readKeyShareBackups(path, node); err == nil {
+		t.Error("altered backup was accepted")
+	}
+
+	unsigned := backup
+	unsigned.Signature = ""
+	writeTestBackup(t, path, unsigned)
+	if _, err := readKeyShareBackups(path, node); err == nil {
+		t.Error("unsigned backup was accepted")
+	}
+
+	// A backup signed by another key under the name of this node
+	useTestNodeKey(t)
+	forged := tampered
+	if err := signKeyShareBackup(&forged); err != nil {
+		t.Fatal(err)
+	}
+	writeTestBackup(t, path, forged)
+	if _, err := readKeyShareBackups(path, node); err == nil {
+		t.Error("backup signed by another key was accepted")
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/StripChain/strip-node/libs"
+	pb "github.com/StripChain/strip-node/libs/proto"
+	"github.com/bnb-chain/tss-lib/v2/tss"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// BlameError is a failed protocol run together with the signers identified as at fault.
+// tss-lib reports them as the culprits of a *tss.Error, which only knows party IDs.
+type BlameError struct {
+	SessionID string
+	Task      string
+	Round     int
+	Culprits  []string
+	Err       error
+}
+
+func (e *BlameError) Error() string {
+	return fmt.Sprintf("session %s blamed %v: %v", e.SessionID, e.Culprits, e.Err)
+}
+
+func (e *BlameError) Unwrap() error {
+	return e.Err
+}
+
+// Report returns the blame as sent to the sequencer, signed with the node key
+func (e *BlameError) Report() (*pb.BlameReport, error) {
+	report := &pb.BlameReport{
+		SessionId: e.SessionID,
+		Task:      e.Task,
+		Round:     int32(e.Round),
+		Culprits:  e.Culprits,
+		Reason:    e.Err.Error(),
+		Reporter:  NodePublicKey,
+	}
+	digest, err := libs.BlameReportDigest(report)
+	if err != nil {
+		return nil, err
+	}
+	privateKey, err := nodeKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the node key: %w", err)
+	}
+	if report.Signature, err = crypto.Sign(digest, privateKey); err != nil {
+		return nil, fmt.Errorf("failed to sign blame report: %w", err)
+	}
+	return report, nil
+}
+
+// committee maps the parties of a run to the signers behind them
+type committee struct {
+	sessionID string
+	// ids are the party IDs in the order of signers
+	ids     []*tss.PartyID
+	signers []string
+}
+
+// signerOf returns the signer behind a party, or "" when it is not in the committee
+func (c committee) signerOf(party *tss.PartyID) string {
+	if party == nil {
+		return ""
+	}
+	for i, id := range c.ids {
+		if i < len(c.signers) && id.KeyInt().Cmp(party.KeyInt()) == 0 {
+			return c.signers[i]
+		}
+	}
+	return ""
+}
+
+// blame turns a tss-lib error naming culprits into a BlameError. Other errors are
+// returned unchanged.
+func (c committee) blame(err error) error {
+	var blamed *BlameError
+	if errors.As(err, &blamed) {
+		return err
+	}
+
+	var tssErr *tss.Error
+	if !errors.As(err, &tssErr) || len(tssErr.Culprits()) == 0 {
+		return err
+	}
+
+	culprits := []string{}
+	for _, culprit := range tssErr.Culprits() {
+		if signer := c.signerOf(culprit); signer != "" {
+			culprits = append(culprits, signer)
+		}
+	}
+	if len(culprits) == 0 {
+		return err
+	}
+
+	return &BlameError{
+		SessionID: c.sessionID,
+		Task:      tssErr.Task(),
+		Round:     tssErr.Round(),
+		Culprits:  culprits,
+		Err:       err,
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/StripChain/strip-node/libs"
+	"github.com/bnb-chain/tss-lib/v2/tss"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestCommitteeBlame(t *testing.T) {
+	parties, ids := getParties(3)
+	members := committee{sessionID: "s1", ids: ids, signers: []string{"0xa", "0xb", "0xc"}}
+
+	cause := errors.New("invalid commitment")
+	err := members.blame(tss.NewError(cause, "signing", 2, parties[0], parties[2]))
+
+	var blamed *BlameError
+	if !errors.As(err, &blamed) {
+		t.Fatalf("blame = %v, want a BlameError", err)
+	}
+	if len(blamed.Culprits) != 1 || blamed.Culprits[0] != "0xc" {
+		t.Errorf("culprits = %v, want [0xc]", blamed.Culprits)
+	}
+	if !errors.Is(err, cause) {
+		t.Error("blame should wrap the tss error")
+	}
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	previousKey, previousPublicKey := NodePrivateKey, NodePublicKey
+	defer func() { NodePrivateKey, NodePublicKey = previousKey, previousPublicKey }()
+	NodePrivateKey = hexutil.Encode(crypto.FromECDSA(key))
+	NodePublicKey = signerPublicKey(&key.PublicKey)
+
+	report, err := blamed.Report()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.SessionId != "s1" || report.Task != "signing" || report.Round != 2 {
+		t.Errorf("report = %v", report)
+	}
+	// The sequencer checks that the report is signed by the validator
+	if err := libs.VerifyBlameReport(report); err != nil {
+		t.Errorf("VerifyBlameReport = %v, want a report signed by this node", err)
+	}
+	report.Culprits = []string{"0xa"}
+	if err := libs.VerifyBlameReport(report); err == nil {
+		t.Error("VerifyBlameReport should fail for an altered report")
+	}
+
+	// Errors without culprits are not blamed on anyone
+	plain := tss.NewError(cause, "signing", 2, parties[0])
+	if err := members.blame(plain); err != plain {
+		t.Errorf("blame = %v, want the error unchanged", err)
+	}
+}
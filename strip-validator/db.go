@@ -1,23 +1,71 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/StripChain/strip-node/common"
+	"github.com/StripChain/strip-node/libs"
+	"github.com/StripChain/strip-node/libs/keystore"
 	"github.com/StripChain/strip-node/util/logger"
 	"github.com/go-pg/pg/v10"
 	"github.com/go-pg/pg/v10/orm"
+	"github.com/google/uuid"
 )
 
 var client *pg.DB
 
+// keyValues stores the rows keygen, signing and resharing read and write: key shares, their
+// signers and thresholds, and the pre-params pool
+var keyValues IKeyValueStore
+
+// keyShareWrapper wraps the data keys that encrypt key shares at rest
+var keyShareWrapper keystore.IKeyWrapper
+
+// signersKeySuffix marks the KVStore rows holding the signers of a key share, they are not secret
+const signersKeySuffix = "_signers"
+
+// thresholdKeySuffix marks the KVStore rows holding the threshold of a key share,
+// shares generated before thresholds were configurable have none and use the default
+const thresholdKeySuffix = "_threshold"
+
+// preParamsKeyPrefix marks the KVStore rows of the ECDSA pre-params pool. They are sealed
+// like key shares, so key share migration and rotation cover them as well.
+const preParamsKeyPrefix = "preparams_"
+
+// frostNoncesKeyPrefix marks the KVStore rows of the FROST nonce pool, sealed like the
+// pre-params and followed by the row key of the wallet's key share
+const frostNoncesKeyPrefix = "frostnonces_"
+
 type KVStore struct {
 	Id    int64
 	Key   string
 	Value string
+	// CreatedAt is zero for rows stored before it was recorded
+	CreatedAt time.Time `pg:"default:now()"`
+}
+
+// PolicySpend is an amount transferred by an operation the signing policy allowed,
+// counted towards the daily limits of the wallet
+type PolicySpend struct {
+	Id             int64
+	IntentId       string `pg:",unique:policy_spend_operation"`
+	OperationIndex int    `pg:",unique:policy_spend_operation,use_zero"`
+	Identity       string
+	BlockchainId   string
+	Token          string `pg:",use_zero"`
+	Day            string
+	Amount         string
 }
 
 func createKeyValueSchema(db *pg.DB) error {
 	models := []interface{}{
 		(*KVStore)(nil),
+		(*PolicySpend)(nil),
 	}
 
 	for _, model := range models {
@@ -28,7 +76,14 @@ func createKeyValueSchema(db *pg.DB) error {
 			return err
 		}
 	}
-	return nil
+
+	// Tables created by older versions lack created_at, their rows keep it null
+	_, err := db.Model((*KVStore)(nil)).Exec("ALTER TABLE ?TableName ADD COLUMN IF NOT EXISTS created_at timestamptz")
+	if err != nil {
+		return err
+	}
+	_, err = db.Model((*KVStore)(nil)).Exec("ALTER TABLE ?TableName ALTER COLUMN created_at SET DEFAULT now()")
+	return err
 }
 
 func InitialiseDB(host string, database string, username string, password string) {
@@ -39,6 +94,7 @@ func InitialiseDB(host string, database string, username string, password string
 		Database: database,
 		Addr:     host,
 	})
+	keyValues = pgKeyValueStore{db: client}
 
 	err := createKeyValueSchema(client)
 	if err != nil {
@@ -46,54 +102,470 @@ func InitialiseDB(host string, database string, username string, password string
 	}
 }
 
+// InitialiseKeyShareEncryption sets the key wrapper used to encrypt key shares at rest
+func InitialiseKeyShareEncryption(wrapper keystore.IKeyWrapper) {
+	keyShareWrapper = wrapper
+}
+
+// IKeyValueStore stores KVStore rows by key
+type IKeyValueStore interface {
+	// Get returns the value of the row with the key, false when there is none
+	Get(key string) (string, bool, error)
+	// Replace deletes the rows with the deleted keys and inserts rows, in one transaction
+	Replace(deleted []string, rows ...*KVStore) error
+	// TakeFirst deletes the oldest row whose key starts with prefix and returns it, nil when
+	// there is none. Concurrent callers never get the same row.
+	TakeFirst(prefix string) (*KVStore, error)
+	// Count returns the number of rows whose key starts with prefix
+	Count(prefix string) (int, error)
+}
+
+// pgKeyValueStore stores the rows in the kv_stores table
+type pgKeyValueStore struct {
+	db *pg.DB
+}
+
+func (s pgKeyValueStore) Get(key string) (string, bool, error) {
+	var rows []KVStore
+	if err := s.db.Model(&rows).Where("key = ?", key).Select(); err != nil {
+		return "", false, err
+	}
+	if len(rows) == 0 {
+		return "", false, nil
+	}
+	return rows[0].Value, true, nil
+}
+
+func (s pgKeyValueStore) Replace(deleted []string, rows ...*KVStore) error {
+	if len(deleted) == 0 {
+		_, err := s.db.Model(&rows).Insert()
+		return err
+	}
+	return s.db.RunInTransaction(context.Background(), func(tx *pg.Tx) error {
+		if _, err := tx.Model(&KVStore{}).WhereIn("key IN (?)", deleted).Delete(); err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		_, err := tx.Model(&rows).Insert()
+		return err
+	})
+}
+
+func (s pgKeyValueStore) TakeFirst(prefix string) (*KVStore, error) {
+	var kv KVStore
+	_, err := s.db.QueryOne(&kv, `
+		DELETE FROM kv_stores WHERE id = (
+			SELECT id FROM kv_stores WHERE key LIKE ? ORDER BY id LIMIT 1 FOR UPDATE SKIP LOCKED
+		) RETURNING id, key, value`, likePrefix(prefix))
+	if errors.Is(err, pg.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &kv, nil
+}
+
+func (s pgKeyValueStore) Count(prefix string) (int, error) {
+	return s.db.Model(&KVStore{}).Where("key LIKE ?", likePrefix(prefix)).Count()
+}
+
+// likePrefix returns the LIKE pattern matching keys that start with prefix. Row keys hold
+// underscores, which LIKE would otherwise take for any character.
+func likePrefix(prefix string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(prefix) + "%"
+}
+
+func keyShareKey(identity string, identityCurve common.Curve, keyCurve common.Curve) string {
+	return identity + "_" + string(identityCurve) + "_" + string(keyCurve)
+}
+
 func AddKeyShare(identity string, identityCurve common.Curve, keyCurve common.Curve, key string) error {
 	logger.Sugar().Infof("Adding key share to postgres %s_%s_%s", identity, identityCurve, keyCurve)
-	kvStore := &KVStore{
-		Key:   identity + "_" + string(identityCurve) + "_" + string(keyCurve),
-		Value: key,
+	if keyShareWrapper == nil {
+		return errors.New("key share encryption is not initialised")
 	}
 
-	_, err := client.Model(kvStore).Insert()
-	return err
+	kvKey := keyShareKey(identity, identityCurve, keyCurve)
+	// The row key is the associated data so a sealed share cannot be moved to another identity
+	sealed, err := keystore.Seal(context.Background(), keyShareWrapper, []byte(key), []byte(kvKey))
+	if err != nil {
+		return fmt.Errorf("failed to encrypt key share: %w", err)
+	}
+
+	return keyValues.Replace(nil, &KVStore{Key: kvKey, Value: sealed})
 }
 
 func GetKeyShare(identity string, identityCurve common.Curve, keyCurve common.Curve) (string, error) {
-	var keys []KVStore
-	err := client.Model(&keys).Where("key = ?", identity+"_"+string(identityCurve)+"_"+string(keyCurve)).Select()
+	kvKey := keyShareKey(identity, identityCurve, keyCurve)
+	value, ok, err := keyValues.Get(kvKey)
+	if err != nil || !ok {
+		return "", err
+	}
+
+	return openKeyShare(kvKey, value)
+}
 
+// openKeyShare decrypts a key share as stored in its KVStore row
+func openKeyShare(kvKey string, value string) (string, error) {
+	if !keystore.IsSealed(value) {
+		logger.Sugar().Warnf("Key share %s is stored in plaintext, run the validator with -migrateKeyShares", kvKey)
+		return value, nil
+	}
+
+	if keyShareWrapper == nil {
+		return "", errors.New("key share encryption is not initialised")
+	}
+	key, err := keystore.Open(context.Background(), keyShareWrapper, value, []byte(kvKey))
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("failed to decrypt key share %s: %w", kvKey, err)
+	}
+	return string(key), nil
+}
+
+// keyShareRecord is a key share row with the signers and threshold stored next to it
+type keyShareRecord struct {
+	Key       string
+	Share     string
+	Signers   string
+	Threshold string
+	CreatedAt time.Time
+}
+
+// getKeyShareRecords returns the key shares stored under the given row keys, or every key
+// share when none are given. Pre-params and FROST nonces are not key shares and are left out.
+func getKeyShareRecords(kvKeys ...string) ([]keyShareRecord, error) {
+	var rows []KVStore
+	query := client.Model(&rows).Order("id ASC")
+	if len(kvKeys) > 0 {
+		keys := make([]string, 0, 3*len(kvKeys))
+		for _, kvKey := range kvKeys {
+			keys = append(keys, kvKey, kvKey+signersKeySuffix, kvKey+thresholdKeySuffix)
+		}
+		query = query.WhereIn("key IN (?)", keys)
+	}
+	if err := query.Select(); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(rows))
+	for _, row := range rows {
+		values[row.Key] = row.Value
+	}
+
+	records := []keyShareRecord{}
+	for _, row := range rows {
+		if strings.HasSuffix(row.Key, signersKeySuffix) || strings.HasSuffix(row.Key, thresholdKeySuffix) || strings.HasPrefix(row.Key, preParamsKeyPrefix) || strings.HasPrefix(row.Key, frostNoncesKeyPrefix) {
+			continue
+		}
+		records = append(records, keyShareRecord{
+			Key:       row.Key,
+			Share:     row.Value,
+			Signers:   values[row.Key+signersKeySuffix],
+			Threshold: values[row.Key+thresholdKeySuffix],
+			CreatedAt: row.CreatedAt,
+		})
+	}
+	return records, nil
+}
+
+// restoreKeyShare stores a key share with its signers and threshold, encrypted like new
+// key shares, unless the node already holds a share under the row key. It reports whether
+// the share was stored.
+func restoreKeyShare(record keyShareRecord) (bool, error) {
+	if keyShareWrapper == nil {
+		return false, errors.New("key share encryption is not initialised")
+	}
+	sealed, err := keystore.Seal(context.Background(), keyShareWrapper, []byte(record.Share), []byte(record.Key))
+	if err != nil {
+		return false, fmt.Errorf("failed to encrypt key share: %w", err)
+	}
+
+	restored := false
+	err = client.RunInTransaction(context.Background(), func(tx *pg.Tx) error {
+		exists, err := tx.Model(&KVStore{}).Where("key = ?", record.Key).Exists()
+		if err != nil || exists {
+			return err
+		}
+
+		rows := []*KVStore{
+			{Key: record.Key, Value: sealed},
+			{Key: record.Key + signersKeySuffix, Value: record.Signers},
+		}
+		if record.Threshold != "" {
+			rows = append(rows, &KVStore{Key: record.Key + thresholdKeySuffix, Value: record.Threshold})
+		}
+		if _, err := tx.Model(&rows).Insert(); err != nil {
+			return err
+		}
+		restored = true
+		return nil
+	})
+	return restored, err
+}
+
+// ReplaceKeyShare swaps the key share, signers and threshold of a wallet for the ones produced
+// by resharing, in one transaction so signing never sees a share with the wrong signers
+func ReplaceKeyShare(identity string, identityCurve common.Curve, keyCurve common.Curve, key string, signers string, threshold int) error {
+	logger.Sugar().Infof("Replacing key share in postgres %s_%s_%s", identity, identityCurve, keyCurve)
+	if keyShareWrapper == nil {
+		return errors.New("key share encryption is not initialised")
 	}
 
-	if len(keys) == 0 {
-		return "", nil
+	kvKey := keyShareKey(identity, identityCurve, keyCurve)
+	sealed, err := keystore.Seal(context.Background(), keyShareWrapper, []byte(key), []byte(kvKey))
+	if err != nil {
+		return fmt.Errorf("failed to encrypt key share: %w", err)
 	}
 
-	return keys[0].Value, nil
+	return keyValues.Replace([]string{kvKey, kvKey + signersKeySuffix, kvKey + thresholdKeySuffix},
+		&KVStore{Key: kvKey, Value: sealed},
+		&KVStore{Key: kvKey + signersKeySuffix, Value: signers},
+		&KVStore{Key: kvKey + thresholdKeySuffix, Value: strconv.Itoa(threshold)},
+	)
+}
+
+// DeleteKeyShare removes the key share, signers and threshold of a wallet this node no longer signs for
+func DeleteKeyShare(identity string, identityCurve common.Curve, keyCurve common.Curve) error {
+	logger.Sugar().Infof("Deleting key share from postgres %s_%s_%s", identity, identityCurve, keyCurve)
+	kvKey := keyShareKey(identity, identityCurve, keyCurve)
+	return keyValues.Replace([]string{kvKey, kvKey + signersKeySuffix, kvKey + thresholdKeySuffix})
+}
+
+// MigrateKeyShares encrypts every key share still stored in plaintext. It is safe to
+// run again after an interruption, already encrypted shares are left alone.
+func MigrateKeyShares(ctx context.Context, wrapper keystore.IKeyWrapper) (int, error) {
+	keys, err := getAllKeyShares()
+	if err != nil {
+		return 0, err
+	}
+
+	migrated := 0
+	for _, kv := range keys {
+		if keystore.IsSealed(kv.Value) {
+			continue
+		}
+
+		sealed, err := keystore.Seal(ctx, wrapper, []byte(kv.Value), []byte(kv.Key))
+		if err != nil {
+			return migrated, fmt.Errorf("failed to encrypt key share %s: %w", kv.Key, err)
+		}
+		if err := updateKeyShare(kv, sealed); err != nil {
+			return migrated, err
+		}
+		migrated++
+	}
+	return migrated, nil
+}
+
+// RotateKeyShares rewraps the data key of every encrypted key share from one key-encryption
+// key to another. Shares already wrapped by the new key are skipped so an interrupted
+// rotation can be resumed.
+func RotateKeyShares(ctx context.Context, from keystore.IKeyWrapper, to keystore.IKeyWrapper) (int, error) {
+	keys, err := getAllKeyShares()
+	if err != nil {
+		return 0, err
+	}
+
+	rotated := 0
+	for _, kv := range keys {
+		if !keystore.IsSealed(kv.Value) {
+			return rotated, fmt.Errorf("key share %s is stored in plaintext, migrate it before rotating", kv.Key)
+		}
+
+		kek, err := keystore.SealedKEK(kv.Value)
+		if err != nil {
+			return rotated, fmt.Errorf("key share %s: %w", kv.Key, err)
+		}
+		// Rewrapping under the same name still moves Vault and KMS keys to their latest version
+		if kek == to.Name() && from.Name() != to.Name() {
+			continue
+		}
+
+		resealed, err := keystore.Reseal(ctx, from, to, kv.Value, []byte(kv.Key))
+		if err != nil {
+			return rotated, fmt.Errorf("failed to rotate key share %s: %w", kv.Key, err)
+		}
+		if err := updateKeyShare(kv, resealed); err != nil {
+			return rotated, err
+		}
+		rotated++
+	}
+	return rotated, nil
+}
+
+func getAllKeyShares() ([]KVStore, error) {
+	var keys []KVStore
+	err := client.Model(&keys).Order("id ASC").Select()
+	if err != nil {
+		return nil, err
+	}
+
+	shares := keys[:0]
+	for _, kv := range keys {
+		if !strings.HasSuffix(kv.Key, signersKeySuffix) && !strings.HasSuffix(kv.Key, thresholdKeySuffix) {
+			shares = append(shares, kv)
+		}
+	}
+	return shares, nil
+}
+
+func updateKeyShare(kv KVStore, value string) error {
+	// Only replace the value that was read so a concurrent writer is never overwritten
+	result, err := client.Model(&KVStore{}).
+		Set("value = ?", value).
+		Where("id = ?", kv.Id).
+		Where("value = ?", kv.Value).
+		Update()
+	if err != nil {
+		return fmt.Errorf("failed to update key share %s: %w", kv.Key, err)
+	}
+	if result.RowsAffected() != 1 {
+		return fmt.Errorf("key share %s changed while it was being updated", kv.Key)
+	}
+	return nil
 }
 
 func AddSignersForKeyShare(identity string, identityCurve common.Curve, keyCurve common.Curve, signers string) error {
 	logger.Sugar().Infof("Adding signers to postgres %s_%s_%s", identity, identityCurve, keyCurve)
-	kvStore := &KVStore{
-		Key:   identity + "_" + string(identityCurve) + "_" + string(keyCurve) + "_" + "signers",
-		Value: signers,
+	return keyValues.Replace(nil, &KVStore{Key: keyShareKey(identity, identityCurve, keyCurve) + signersKeySuffix, Value: signers})
+}
+
+func GetSignersForKeyShare(identity string, identityCurve common.Curve, keyCurve common.Curve) (string, error) {
+	value, _, err := keyValues.Get(keyShareKey(identity, identityCurve, keyCurve) + signersKeySuffix)
+	return value, err
+}
+
+func AddThresholdForKeyShare(identity string, identityCurve common.Curve, keyCurve common.Curve, threshold int) error {
+	logger.Sugar().Infof("Adding threshold to postgres %s_%s_%s", identity, identityCurve, keyCurve)
+	return keyValues.Replace(nil, &KVStore{Key: keyShareKey(identity, identityCurve, keyCurve) + thresholdKeySuffix, Value: strconv.Itoa(threshold)})
+}
+
+// GetThresholdForKeyShare returns the threshold of a key share for a committee of totalSigners
+func GetThresholdForKeyShare(identity string, identityCurve common.Curve, keyCurve common.Curve, totalSigners int) (int, error) {
+	value, ok, err := keyValues.Get(keyShareKey(identity, identityCurve, keyCurve) + thresholdKeySuffix)
+	if err != nil {
+		return 0, err
 	}
 
-	_, err := client.Model(kvStore).Insert()
-	return err
+	if !ok {
+		return libs.DefaultThreshold(totalSigners), nil
+	}
+
+	return strconv.Atoi(value)
 }
 
-func GetSignersForKeyShare(identity string, identityCurve common.Curve, keyCurve common.Curve) (string, error) {
-	var keys []KVStore
-	err := client.Model(&keys).Where("key = ?", identity+"_"+string(identityCurve)+"_"+string(keyCurve)+"_signers").Select()
+// AddPreParams stores a set of ECDSA pre-params in the pool
+func AddPreParams(preParams string) error {
+	if keyShareWrapper == nil {
+		return errors.New("key share encryption is not initialised")
+	}
+
+	kvKey := preParamsKeyPrefix + uuid.New().String()
+	sealed, err := keystore.Seal(context.Background(), keyShareWrapper, []byte(preParams), []byte(kvKey))
+	if err != nil {
+		return fmt.Errorf("failed to encrypt pre-params: %w", err)
+	}
+
+	return keyValues.Replace(nil, &KVStore{Key: kvKey, Value: sealed})
+}
+
+// TakePreParams removes a set of pre-params from the pool and returns it, an empty string
+// when the pool is empty. Pre-params must never be used twice, so the row is deleted
+// in the same statement that reads it.
+func TakePreParams() (string, error) {
+	kv, err := keyValues.TakeFirst(preParamsKeyPrefix)
+	if err != nil || kv == nil {
+		return "", err
+	}
+
+	if keyShareWrapper == nil {
+		return "", errors.New("key share encryption is not initialised")
+	}
+	preParams, err := keystore.Open(context.Background(), keyShareWrapper, kv.Value, []byte(kv.Key))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt pre-params %s: %w", kv.Key, err)
+	}
+	return string(preParams), nil
+}
 
+// CountPreParams returns the number of pre-params in the pool
+func CountPreParams() (int, error) {
+	return keyValues.Count(preParamsKeyPrefix)
+}
+
+// frostNoncesKey returns the prefix of the pooled FROST nonces of the key share stored
+// under kvKey
+func frostNoncesKey(kvKey string) string {
+	return frostNoncesKeyPrefix + kvKey + "_"
+}
+
+// AddFrostNonces stores FROST nonces in the pool of the key share stored under kvKey
+func AddFrostNonces(kvKey string, nonces string) error {
+	if keyShareWrapper == nil {
+		return errors.New("key share encryption is not initialised")
+	}
+
+	key := frostNoncesKey(kvKey) + uuid.New().String()
+	sealed, err := keystore.Seal(context.Background(), keyShareWrapper, []byte(nonces), []byte(key))
 	if err != nil {
+		return fmt.Errorf("failed to encrypt FROST nonces: %w", err)
+	}
+
+	return keyValues.Replace(nil, &KVStore{Key: key, Value: sealed})
+}
+
+// TakeFrostNonces removes FROST nonces from the pool of the key share stored under kvKey
+// and returns them, an empty string when the pool is empty. Like pre-params, nonces are
+// deleted in the same statement that reads them so that no two signings share them.
+func TakeFrostNonces(kvKey string) (string, error) {
+	kv, err := keyValues.TakeFirst(frostNoncesKey(kvKey))
+	if err != nil || kv == nil {
 		return "", err
 	}
 
-	if len(keys) == 0 {
-		return "", nil
+	if keyShareWrapper == nil {
+		return "", errors.New("key share encryption is not initialised")
+	}
+	nonces, err := keystore.Open(context.Background(), keyShareWrapper, kv.Value, []byte(kv.Key))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt FROST nonces %s: %w", kv.Key, err)
+	}
+	return string(nonces), nil
+}
+
+// CountFrostNonces returns the number of FROST nonces in the pool of the key share stored
+// under kvKey
+func CountFrostNonces(kvKey string) (int, error) {
+	return keyValues.Count(frostNoncesKey(kvKey))
+}
+
+// AddPolicySpend records the amount transferred by an operation, once per operation
+func AddPolicySpend(spend *PolicySpend) error {
+	_, err := client.Model(spend).OnConflict("DO NOTHING").Insert()
+	return err
+}
+
+// GetPolicySpends returns the amounts a wallet transferred of a token on a day, leaving
+// out the given operation
+func GetPolicySpends(identity string, blockchainID string, token string, day string, intentID string, operationIndex int) ([]string, error) {
+	var spends []PolicySpend
+	err := client.Model(&spends).
+		Where("identity = ?", identity).
+		Where("blockchain_id = ?", blockchainID).
+		Where("token = ?", token).
+		Where("day = ?", day).
+		Where("NOT (intent_id = ? AND operation_index = ?)", intentID, operationIndex).
+		Select()
+	if err != nil {
+		return nil, err
 	}
 
-	return keys[0].Value, nil
+	amounts := make([]string, len(spends))
+	for i, spend := range spends {
+		amounts[i] = spend.Amount
+	}
+	return amounts, nil
 }
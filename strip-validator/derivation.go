@@ -0,0 +1,155 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	tssCommon "github.com/bnb-chain/tss-lib/v2/common"
+	"github.com/bnb-chain/tss-lib/v2/crypto"
+	"github.com/bnb-chain/tss-lib/v2/crypto/ckd"
+	ecdsaKeygen "github.com/bnb-chain/tss-lib/v2/ecdsa/keygen"
+	ecdsaSigning "github.com/bnb-chain/tss-lib/v2/ecdsa/signing"
+	eddsaKeygen "github.com/bnb-chain/tss-lib/v2/eddsa/keygen"
+	"github.com/bnb-chain/tss-lib/v2/tss"
+	"github.com/decred/dcrd/dcrec/edwards/v2"
+)
+
+// chainCodeTag separates the chain codes of TSS keys from other hashes of their public keys
+const chainCodeTag = "strip-node/chain-code"
+
+// maxDerivationDepth is the deepest derivation path, as in BIP32
+const maxDerivationDepth = 255
+
+// parseDerivationPath parses a non-hardened BIP32 path such as m/0/1. The empty path and m
+// are the master key.
+func parseDerivationPath(path string) ([]uint32, error) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "m"), "/")
+	if path == "" {
+		return nil, nil
+	}
+
+	segments := strings.Split(path, "/")
+	if len(segments) > maxDerivationDepth {
+		return nil, fmt.Errorf("derivation path is deeper than %d", maxDerivationDepth)
+	}
+	indices := make([]uint32, len(segments))
+	for i, segment := range segments {
+		if strings.HasSuffix(segment, "'") || strings.HasSuffix(segment, "h") {
+			return nil, fmt.Errorf("hardened index %s cannot be derived from a TSS key", segment)
+		}
+		index, err := strconv.ParseUint(segment, 10, 32)
+		if err != nil || index >= ckd.HardenedKeyStart {
+			return nil, fmt.Errorf("invalid derivation path index %q", segment)
+		}
+		indices[i] = uint32(index)
+	}
+	return indices, nil
+}
+
+// masterChainCode returns the chain code of a TSS key. Keygen produces none, so it is
+// derived from the public key, which every signer holds.
+func masterChainCode(publicKey []byte) []byte {
+	digest := sha256.Sum256(append([]byte(chainCodeTag), publicKey...))
+	return digest[:]
+}
+
+// deriveEcdsaKey turns a key share into the share of the child key at path, with BIP32
+// non-hardened derivation. It returns the delta the signing parties add to their share.
+func deriveEcdsaKey(key *ecdsaKeygen.LocalPartySaveData, path []uint32) (*big.Int, error) {
+	if len(path) == 0 {
+		return nil, nil
+	}
+	if key.ECDSAPub == nil {
+		return nil, errors.New("missing ECDSA public key data")
+	}
+	compressed, err := getCompressedPublicKeyBytes(key)
+	if err != nil {
+		return nil, err
+	}
+
+	curve := tss.S256()
+	master := &ckd.ExtendedKey{
+		PublicKey: ecdsa.PublicKey{Curve: curve, X: key.ECDSAPub.X(), Y: key.ECDSAPub.Y()},
+		ChainCode: masterChainCode(compressed),
+		ParentFP:  []byte{0x00, 0x00, 0x00, 0x00},
+	}
+	delta, child, err := ckd.DeriveChildKeyFromHierarchy(path, master, curve.Params().N, curve)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive ECDSA key: %w", err)
+	}
+
+	keys := []ecdsaKeygen.LocalPartySaveData{*key}
+	if err := ecdsaSigning.UpdatePublicKeyAndAdjustBigXj(delta, keys, &child.PublicKey, curve); err != nil {
+		return nil, fmt.Errorf("failed to derive ECDSA key: %w", err)
+	}
+	*key = keys[0]
+	return delta, nil
+}
+
+// deriveEddsaKey turns a key share into the share of the child key at path. Ed25519 has no
+// BIP32, so each step tweaks the key like non-hardened BIP32 does: the HMAC-SHA512 of the
+// public key and index under the chain code gives the tweak, reduced modulo the group
+// order, and the next chain code. The tweak is added to the share so signing needs no change.
+func deriveEddsaKey(key *eddsaKeygen.LocalPartySaveData, path []uint32) error {
+	if len(path) == 0 {
+		return nil
+	}
+	if key.EDDSAPub == nil {
+		return errors.New("missing EDDSA public key data")
+	}
+
+	curve := tss.Edwards()
+	order := tssCommon.ModInt(curve.Params().N)
+	publicKey := key.EDDSAPub
+	chainCode := masterChainCode(eddsaPublicKeyBytes(publicKey))
+	delta := big.NewInt(0)
+	for _, index := range path {
+		data := make([]byte, 36)
+		copy(data, eddsaPublicKeyBytes(publicKey))
+		binary.BigEndian.PutUint32(data[32:], index)
+
+		mac := hmac.New(sha512.New, chainCode)
+		mac.Write(data)
+		sum := mac.Sum(nil)
+
+		tweak := new(big.Int).Mod(new(big.Int).SetBytes(sum[:32]), curve.Params().N)
+		if tweak.Sign() == 0 {
+			return fmt.Errorf("invalid EDDSA key at index %d", index)
+		}
+		child, err := publicKey.Add(crypto.ScalarBaseMult(curve, tweak))
+		if err != nil {
+			return fmt.Errorf("failed to derive EDDSA key: %w", err)
+		}
+		publicKey = child
+		chainCode = sum[32:]
+		delta = order.Add(delta, tweak)
+	}
+
+	// x + delta has shares x_i + delta, like the ECDSA derivation in tss-lib
+	deltaG := crypto.ScalarBaseMult(curve, delta)
+	bigXj := make([]*crypto.ECPoint, len(key.BigXj))
+	for j, bigX := range key.BigXj {
+		adjusted, err := bigX.Add(deltaG)
+		if err != nil {
+			return fmt.Errorf("failed to derive EDDSA key: %w", err)
+		}
+		bigXj[j] = adjusted
+	}
+	key.Xi = order.Add(key.Xi, delta)
+	key.BigXj = bigXj
+	key.EDDSAPub = publicKey
+	return nil
+}
+
+func eddsaPublicKeyBytes(point *crypto.ECPoint) []byte {
+	pk := edwards.PublicKey{Curve: point.Curve(), X: point.X(), Y: point.Y()}
+	return pk.Serialize()
+}
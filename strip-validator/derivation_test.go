@@ -0,0 +1,179 @@
+package main
+
+import (
+	"crypto/elliptic"
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/bnb-chain/tss-lib/v2/common"
+	"github.com/bnb-chain/tss-lib/v2/crypto"
+	"github.com/bnb-chain/tss-lib/v2/crypto/vss"
+	ecdsaKeygen "github.com/bnb-chain/tss-lib/v2/ecdsa/keygen"
+	eddsaKeygen "github.com/bnb-chain/tss-lib/v2/eddsa/keygen"
+	"github.com/bnb-chain/tss-lib/v2/tss"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+func TestParseDerivationPath(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    []uint32
+		wantErr bool
+	}{
+		{path: "", want: nil},
+		{path: "m", want: nil},
+		{path: "m/0/1", want: []uint32{0, 1}},
+		{path: "44/2147483647", want: []uint32{44, 2147483647}},
+		{path: "m/0'/1", wantErr: true},
+		{path: "m/0h", wantErr: true},
+		{path: "m/2147483648", wantErr: true},
+		{path: "m//1", wantErr: true},
+		{path: "m/-1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseDerivationPath(tt.path)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseDerivationPath(%q) = %v, want an error", tt.path, got)
+			}
+			continue
+		}
+		if err != nil || len(got) != len(tt.want) {
+			t.Errorf("parseDerivationPath(%q) = %v, %v, want %v", tt.path, got, err, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("parseDerivationPath(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		}
+	}
+}
+
+// testShares splits a random secret between three parties, any two of which can sign
+func testShares(t *testing.T, curve elliptic.Curve) (*big.Int, vss.Shares, []*crypto.ECPoint) {
+	secret := common.GetRandomPositiveInt(rand.Reader, curve.Params().N)
+	ids := []*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3)}
+	_, shares, err := vss.Create(curve, 1, secret, ids, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bigXj := make([]*crypto.ECPoint, len(shares))
+	for j, share := range shares {
+		bigXj[j] = crypto.ScalarBaseMult(curve, share.Share)
+	}
+	return secret, shares, bigXj
+}
+
+// checkDerivedShares checks that the shares x_i + delta of the parties are a sharing of the
+// child key and match the public shares the parties hold
+func checkDerivedShares(t *testing.T, curve elliptic.Curve, shares vss.Shares, xi []*big.Int, bigXj []*crypto.ECPoint, publicKey *crypto.ECPoint) {
+	derived := make(vss.Shares, len(shares))
+	for i, share := range shares {
+		derived[i] = &vss.Share{Threshold: share.Threshold, ID: share.ID, Share: xi[i]}
+		if !crypto.ScalarBaseMult(curve, xi[i]).Equals(bigXj[i]) {
+			t.Errorf("public share %d does not match the derived share", i)
+		}
+	}
+	secret, err := derived[:2].ReConstruct(curve)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !crypto.ScalarBaseMult(curve, secret).Equals(publicKey) {
+		t.Error("derived shares do not reconstruct the child key")
+	}
+}
+
+func TestDeriveEcdsaKey(t *testing.T) {
+	curve := tss.S256()
+	secret, shares, bigXj := testShares(t, curve)
+	path := []uint32{0, 7}
+
+	keys := make([]ecdsaKeygen.LocalPartySaveData, len(shares))
+	deltas := make([]*big.Int, len(shares))
+	for i, share := range shares {
+		keys[i] = ecdsaKeygen.NewLocalPartySaveData(len(shares))
+		keys[i].Xi, keys[i].ShareID = share.Share, share.ID
+		copy(keys[i].BigXj, bigXj)
+		keys[i].ECDSAPub = crypto.ScalarBaseMult(curve, secret)
+
+		var err error
+		if deltas[i], err = deriveEcdsaKey(&keys[i], path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Every signer derives the same child key
+	for i := range keys {
+		if deltas[i].Cmp(deltas[0]) != 0 || !keys[i].ECDSAPub.Equals(keys[0].ECDSAPub) {
+			t.Fatalf("signer %d derived another key", i)
+		}
+	}
+
+	// tss-lib adds the delta to the share when signing
+	xi := make([]*big.Int, len(shares))
+	for i, share := range shares {
+		xi[i] = common.ModInt(curve.Params().N).Add(share.Share, deltas[i])
+	}
+	checkDerivedShares(t, curve, shares, xi, keys[0].BigXj, keys[0].ECDSAPub)
+
+	// The child key is the BIP32 public derivation of the wallet key
+	master := crypto.ScalarBaseMult(curve, secret)
+	compressed := elliptic.MarshalCompressed(curve, master.X(), master.Y())
+	extended := hdkeychain.NewExtendedKey(chaincfg.MainNetParams.HDPublicKeyID[:], compressed, masterChainCode(compressed), []byte{0, 0, 0, 0}, 0, 0, false)
+	for _, index := range path {
+		var err error
+		if extended, err = extended.Derive(index); err != nil {
+			t.Fatal(err)
+		}
+	}
+	child, err := extended.ECPubKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if child.X().Cmp(keys[0].ECDSAPub.X()) != 0 || child.Y().Cmp(keys[0].ECDSAPub.Y()) != 0 {
+		t.Error("derived key does not match BIP32")
+	}
+}
+
+func TestDeriveEddsaKey(t *testing.T) {
+	curve := tss.Edwards()
+	secret, shares, bigXj := testShares(t, curve)
+
+	derive := func(path []uint32) []eddsaKeygen.LocalPartySaveData {
+		keys := make([]eddsaKeygen.LocalPartySaveData, len(shares))
+		for i, share := range shares {
+			keys[i] = eddsaKeygen.NewLocalPartySaveData(len(shares))
+			keys[i].Xi, keys[i].ShareID = share.Share, share.ID
+			copy(keys[i].BigXj, bigXj)
+			keys[i].EDDSAPub = crypto.ScalarBaseMult(curve, secret)
+			if err := deriveEddsaKey(&keys[i], path); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return keys
+	}
+
+	keys := derive([]uint32{3, 1})
+	xi := make([]*big.Int, len(keys))
+	for i := range keys {
+		if !keys[i].EDDSAPub.Equals(keys[0].EDDSAPub) {
+			t.Fatalf("signer %d derived another key", i)
+		}
+		xi[i] = keys[i].Xi
+	}
+	checkDerivedShares(t, curve, shares, xi, keys[0].BigXj, keys[0].EDDSAPub)
+
+	if keys[0].EDDSAPub.Equals(crypto.ScalarBaseMult(curve, secret)) {
+		t.Error("child key should differ from the wallet key")
+	}
+	if derive([]uint32{3, 2})[0].EDDSAPub.Equals(keys[0].EDDSAPub) {
+		t.Error("different paths should derive different keys")
+	}
+	if !derive(nil)[0].EDDSAPub.Equals(crypto.ScalarBaseMult(curve, secret)) {
+		t.Error("the empty path should be the wallet key")
+	}
+}
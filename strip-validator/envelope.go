@@ -0,0 +1,154 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
+)
+
+// Every message between validators is signed by the node key of its sender. Point-to-point
+// TSS messages, such as keygen and resharing shares, are also encrypted with ECIES to the
+// node key of the signer they are addressed to, binding the session ID to the ciphertext.
+// Each message carries a sequence number, and a message seen before in its session is
+// dropped.
+
+var errUnsealedMessage = errors.New("point-to-point message is not encrypted")
+
+// signerPublicKey returns how a signer is identified by its node key: the X coordinate of
+// the public key, as registered in the IntentOperatorsRegistry
+func signerPublicKey(pub *ecdsa.PublicKey) string {
+	return hexutil.Encode(crypto.CompressPubkey(pub)[1:])
+}
+
+func nodeKey() (*ecdsa.PrivateKey, error) {
+	return crypto.HexToECDSA(strings.TrimPrefix(NodePrivateKey, "0x"))
+}
+
+// sealFor encrypts the payload of a point-to-point message of a session to a signer
+func sealFor(signer string, sessionID string, payload []byte) ([]byte, error) {
+	x, err := hexutil.Decode(signer)
+	if err != nil || len(x) != 32 {
+		return nil, fmt.Errorf("invalid signer public key %s", signer)
+	}
+
+	// Signers are identified by the X coordinate only, so either point with that X can be
+	// used: ECIES derives its keys from the X coordinate of the shared point, which is the
+	// same for both.
+	pub, err := crypto.DecompressPubkey(append([]byte{0x02}, x...))
+	if err != nil {
+		return nil, fmt.Errorf("invalid signer public key %s: %w", signer, err)
+	}
+
+	return ecies.Encrypt(rand.Reader, ecies.ImportECDSAPublic(pub), payload, nil, []byte(sessionID))
+}
+
+// openMessage returns the payload of a protocol message, decrypting it when it is a
+// point-to-point message, which must have been sealed for this node
+func openMessage(msg Message) ([]byte, error) {
+	if msg.To == -1 {
+		return msg.Message, nil
+	}
+	if !msg.Encrypted {
+		return nil, errUnsealedMessage
+	}
+
+	key, err := nodeKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the node key: %w", err)
+	}
+	return ecies.ImportECDSA(key).Decrypt(msg.Message, nil, []byte(msg.SessionID))
+}
+
+// sealMessage encrypts a point-to-point message to the committee member it is addressed
+// to. Broadcasts are left as they are.
+func sealMessage(message *Message, recipient string) error {
+	if message.To == -1 {
+		return nil
+	}
+	if recipient == "" {
+		return fmt.Errorf("no signer for party %d", message.To)
+	}
+
+	sealed, err := sealFor(recipient, message.SessionID, message.Message)
+	if err != nil {
+		return err
+	}
+	message.Message = sealed
+	message.Encrypted = true
+	message.recipient = recipient
+	return nil
+}
+
+var messageSeq atomic.Uint64
+
+func nextMessageSeq() uint64 {
+	return messageSeq.Add(1)
+}
+
+// replayGuard remembers the sequence numbers seen per session and sender for as long as
+// a session can be running
+type replayGuard struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	sessions  map[string]*seenMessages
+	lastPrune time.Time
+}
+
+type seenMessages struct {
+	expires time.Time
+	seqs    map[string]map[uint64]struct{}
+}
+
+func newReplayGuard(ttl time.Duration) *replayGuard {
+	return &replayGuard{
+		ttl:       ttl,
+		sessions:  make(map[string]*seenMessages),
+		lastPrune: time.Now(),
+	}
+}
+
+var replays = newReplayGuard(sessionTimeout + sessionRetention)
+
+// Accept records a message and reports whether it is seen for the first time. Messages
+// without a session or sequence number are not accepted.
+func (g *replayGuard) Accept(sessionID string, sender string, seq uint64) bool {
+	if sessionID == "" || seq == 0 {
+		return false
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(g.lastPrune) > g.ttl/2 {
+		for id, seen := range g.sessions {
+			if now.After(seen.expires) {
+				delete(g.sessions, id)
+			}
+		}
+		g.lastPrune = now
+	}
+
+	seen, ok := g.sessions[sessionID]
+	if !ok {
+		seen = &seenMessages{expires: now.Add(g.ttl), seqs: make(map[string]map[uint64]struct{})}
+		g.sessions[sessionID] = seen
+	}
+	if seen.seqs[sender] == nil {
+		seen.seqs[sender] = make(map[uint64]struct{})
+	}
+	if _, replayed := seen.seqs[sender][seq]; replayed {
+		return false
+	}
+	seen.seqs[sender][seq] = struct{}{}
+	return true
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+func TestSealedMessages(t *testing.T) {
+	previous := NodePrivateKey
+	defer func() { NodePrivateKey = previous }()
+
+	// Keys with either parity of Y must be usable as recipients
+	for i := 0; i < 4; i++ {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		NodePrivateKey = hexutil.Encode(crypto.FromECDSA(key))
+
+		message := Message{SessionID: "s1", To: 1, Message: []byte("share")}
+		if err := sealMessage(&message, signerPublicKey(&key.PublicKey)); err != nil {
+			t.Fatalf("sealMessage: %v", err)
+		}
+		if !message.Encrypted || string(message.Message) == "share" {
+			t.Fatal("point-to-point message should be encrypted")
+		}
+
+		payload, err := openMessage(message)
+		if err != nil || string(payload) != "share" {
+			t.Fatalf("openMessage = %q, %v, want the share", payload, err)
+		}
+
+		moved := message
+		moved.SessionID = "s2"
+		if _, err := openMessage(moved); err == nil {
+			t.Error("a message moved to another session should not open")
+		}
+	}
+
+	if _, err := openMessage(Message{SessionID: "s1", To: 1, Message: []byte("share")}); !errors.Is(err, errUnsealedMessage) {
+		t.Errorf("openMessage of a cleartext share = %v, want %v", err, errUnsealedMessage)
+	}
+	if payload, err := openMessage(Message{SessionID: "s1", To: -1, Message: []byte("commitment")}); err != nil || string(payload) != "commitment" {
+		t.Errorf("openMessage of a broadcast = %q, %v", payload, err)
+	}
+}
+
+func TestReplayGuard(t *testing.T) {
+	g := newReplayGuard(sessionTimeout)
+
+	if !g.Accept("s1", "0xa", 1) {
+		t.Fatal("first message should be accepted")
+	}
+	if g.Accept("s1", "0xa", 1) {
+		t.Error("replayed message should be rejected")
+	}
+	if !g.Accept("s1", "0xb", 1) || !g.Accept("s2", "0xa", 1) {
+		t.Error("sequence numbers are per session and sender")
+	}
+	if g.Accept("s1", "0xa", 0) || g.Accept("", "0xa", 2) {
+		t.Error("messages without a session or sequence number should be rejected")
+	}
+}
+
+func TestPeerRateLimiter(t *testing.T) {
+	l := newPeerRateLimiter(1, 3)
+
+	for i := 0; i < 3; i++ {
+		if !l.Allow(peer.ID("a")) {
+			t.Fatalf("message %d within the burst should be allowed", i)
+		}
+	}
+	if l.Allow(peer.ID("a")) {
+		t.Error("message over the burst should be rejected")
+	}
+	if !l.Allow(peer.ID("b")) {
+		t.Error("limits are per peer")
+	}
+}
+
+func TestSignerPeerIDs(t *testing.T) {
+	previous := NodePrivateKey
+	defer func() { NodePrivateKey = previous }()
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	NodePrivateKey = hexutil.Encode(crypto.FromECDSA(key))
+
+	identity, err := nodePeerKey()
+	if err != nil {
+		t.Fatalf("nodePeerKey: %v", err)
+	}
+	self, err := peer.IDFromPrivateKey(identity)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids, err := signerPeerIDs(signerPublicKey(&key.PublicKey))
+	if err != nil {
+		t.Fatalf("signerPeerIDs: %v", err)
+	}
+	if ids[0] != self && ids[1] != self {
+		t.Errorf("peer IDs %v of the signer should include %s", ids, self)
+	}
+}
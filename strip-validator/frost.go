@@ -0,0 +1,426 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"filippo.io/edwards25519"
+	"github.com/StripChain/strip-node/common"
+	"github.com/StripChain/strip-node/libs/frost"
+	"github.com/StripChain/strip-node/util/logger"
+	"github.com/bnb-chain/tss-lib/v2/crypto"
+	eddsaKeygen "github.com/bnb-chain/tss-lib/v2/eddsa/keygen"
+	"github.com/bnb-chain/tss-lib/v2/tss"
+	"github.com/decred/dcrd/dcrec/edwards/v2"
+)
+
+// FROST keys (common.CurveFrost) are stored like tss-lib EdDSA key shares: Xi is the secret
+// share of the party ShareID, BigXj are the verification shares of the parties Ks and
+// EDDSAPub is the group key. Addresses, child key derivation, resharing and backups handle
+// them like EdDSA keys, only keygen and signing run the FROST protocols; TestHarnessReshare
+// checks that a FROST key reshared by tss-lib still signs. Signing nonces come from a pool
+// (frostnonces.go).
+//
+// As with tss-lib, every signer of the wallet takes part in a signing, although frost.Sign
+// and frost.Aggregate accept any threshold+1 of them. Signing with a subset is deferred: the
+// signers would have to agree on the subset without knowing which of them are live, so the
+// validator starting the signing would choose and announce it, and the verification of
+// START_SIGN, signing slots, receipts and blame all assume the whole committee today.
+
+// Rounds of the FROST protocols
+const (
+	// frostRoundCommit is the first round of both protocols: the DKG packages in keygen,
+	// the nonce commitments in signing
+	frostRoundCommit = 1
+	// frostRoundShare is the second round: the dealt secret shares in keygen, the
+	// signature shares in signing
+	frostRoundShare = 2
+)
+
+const (
+	frostTaskKeygen  = "frost-keygen"
+	frostTaskSigning = "frost-signing"
+)
+
+// frostMessage is the payload of a FROST protocol message
+type frostMessage struct {
+	Round      int               `json:"round"`
+	Package    *frost.DKGPackage `json:"package,omitempty"`
+	Commitment *frost.Commitment `json:"commitment,omitempty"`
+	Share      []byte            `json:"share,omitempty"`
+}
+
+// frostInbox collects the messages of the other parties of a FROST run, the first
+// message of each party in each round
+type frostInbox struct {
+	mu       sync.Mutex
+	messages map[int]map[int]frostMessage
+	// updated is closed and replaced when a message arrives
+	updated chan struct{}
+}
+
+func newFrostInbox() *frostInbox {
+	return &frostInbox{
+		messages: map[int]map[int]frostMessage{frostRoundCommit: {}, frostRoundShare: {}},
+		updated:  make(chan struct{}),
+	}
+}
+
+func (b *frostInbox) put(from int, message frostMessage) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	round, ok := b.messages[message.Round]
+	if !ok {
+		return
+	}
+	if _, ok := round[from]; ok {
+		return
+	}
+	round[from] = message
+	close(b.updated)
+	b.updated = make(chan struct{})
+}
+
+// wait returns the messages of a round once every other party sent one, or fails when
+// the session finishes first
+func (b *frostInbox) wait(session *Session, round int, parties int) (map[int]frostMessage, error) {
+	for {
+		b.mu.Lock()
+		received := b.messages[round]
+		if len(received) == parties-1 {
+			messages := make(map[int]frostMessage, len(received))
+			for from, message := range received {
+				messages[from] = message
+			}
+			b.mu.Unlock()
+			return messages, nil
+		}
+		updated := b.updated
+		b.mu.Unlock()
+
+		select {
+		case <-updated:
+		case <-session.Done():
+			return nil, sessionError(session.ID)
+		}
+	}
+}
+
+// frostBlame attributes the failure of a FROST round to the signer of a party
+func frostBlame(members committee, task string, round int, party int, err error) error {
+	return &BlameError{
+		SessionID: members.sessionID,
+		Task:      task,
+		Round:     round,
+		Culprits:  []string{members.signers[party]},
+		Err:       err,
+	}
+}
+
+// frostHandler returns a session handler passing the FROST messages addressed to the
+// party index to the inbox. Like partyHandler, a party only speaks for the signer behind it.
+func frostHandler(members committee, index int, task string, inbox *frostInbox) func(Message) error {
+	return func(msg Message) error {
+		if msg.To != -1 && msg.To != index {
+			return nil
+		}
+
+		// Our own messages come back through pubsub
+		if msg.From == index {
+			return nil
+		}
+
+		if msg.From < 0 || msg.From >= len(members.signers) {
+			return fmt.Errorf("message from unknown party %d", msg.From)
+		}
+
+		if msg.sender != "" && msg.sender != members.signers[msg.From] {
+			logger.Sugar().Warnw("dropping message sent for another party", "session", msg.SessionID, "from", msg.From, "sender", msg.sender)
+			return nil
+		}
+
+		payload, err := openMessage(msg)
+		if err != nil {
+			return frostBlame(members, task, -1, msg.From, err)
+		}
+		var message frostMessage
+		if err := json.Unmarshal(payload, &message); err != nil {
+			return frostBlame(members, task, -1, msg.From, fmt.Errorf("invalid message: %w", err))
+		}
+		inbox.put(msg.From, message)
+		return nil
+	}
+}
+
+// sendFrostMessage sends a FROST message of the party index, to the party to or to every
+// party when to is -1. Messages to one party are sealed for the signer behind it.
+func sendFrostMessage(template Message, members committee, index int, to int, message frostMessage) error {
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+
+	out := template
+	out.From = index
+	out.To = to
+	out.IsBroadcast = to == -1
+	out.Message = payload
+	if to != -1 {
+		if err := sealMessage(&out, members.signers[to]); err != nil {
+			return fmt.Errorf("failed to encrypt message to party %d: %w", to, err)
+		}
+	}
+
+	go broadcast(out)
+	return nil
+}
+
+// frostIdentifiers returns the FROST identifiers of the parties of a key
+func frostIdentifiers(keys []*big.Int) ([]*edwards25519.Scalar, error) {
+	identifiers := make([]*edwards25519.Scalar, len(keys))
+	for i, key := range keys {
+		identifier, err := frost.Identifier(key)
+		if err != nil {
+			return nil, err
+		}
+		identifiers[i] = identifier
+	}
+	return identifiers, nil
+}
+
+func elementFromECPoint(point *crypto.ECPoint) (*edwards25519.Point, error) {
+	return frost.ParseElement(eddsaPublicKeyBytes(point))
+}
+
+func ecPointFromElement(point *edwards25519.Point) (*crypto.ECPoint, error) {
+	pk, err := edwards.ParsePubKey(point.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return crypto.NewECPoint(tss.Edwards(), pk.GetX(), pk.GetY())
+}
+
+// runFrostKeygen generates a FROST key for a committee and returns the share of this node
+func runFrostKeygen(sessionID string, identity string, identityCurve common.Curve, signers []string, threshold int) (*eddsaKeygen.LocalPartySaveData, error) {
+	index := SliceIndexOfString(signers, NodePublicKey)
+	_, partiesIds := getParties(len(signers))
+	keys := make([]*big.Int, len(partiesIds))
+	for i, id := range partiesIds {
+		keys[i] = id.KeyInt()
+	}
+	identifiers, err := frostIdentifiers(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	members := committee{sessionID: sessionID, ids: partiesIds, signers: signers}
+	inbox := newFrostInbox()
+	sessions.Describe(sessionID, SessionInfo{Type: SessionTypeKeygen, Identity: identity, KeyCurve: common.CurveFrost, Participants: signers})
+	session, err := sessions.Start(sessionID, frostHandler(members, index, frostTaskKeygen, inbox))
+	if err != nil {
+		return nil, err
+	}
+
+	template := Message{
+		SessionID:     sessionID,
+		Type:          MESSAGE_TYPE_GENERATE_KEYGEN,
+		Identity:      identity,
+		IdentityCurve: identityCurve,
+		KeyCurve:      common.CurveFrost,
+		Signers:       signers,
+	}
+
+	// The proofs of knowledge are bound to the session
+	secret, own, err := frost.DKGRoundOne(identifiers[index], threshold, []byte(sessionID))
+	if err != nil {
+		return nil, err
+	}
+	if err := sendFrostMessage(template, members, index, -1, frostMessage{Round: frostRoundCommit, Package: own}); err != nil {
+		return nil, err
+	}
+
+	received, err := inbox.wait(session, frostRoundCommit, len(signers))
+	if err != nil {
+		return nil, err
+	}
+	packages := make([]*frost.DKGPackage, len(signers))
+	packages[index] = own
+	for party, message := range received {
+		p := message.Package
+		if p == nil || p.Identifier.Equal(identifiers[party]) != 1 {
+			return nil, frostBlame(members, frostTaskKeygen, frostRoundCommit, party, errors.New("missing or misaddressed DKG package"))
+		}
+		if err := frost.VerifyDKGPackage(p, threshold, []byte(sessionID)); err != nil {
+			return nil, frostBlame(members, frostTaskKeygen, frostRoundCommit, party, err)
+		}
+		packages[party] = p
+	}
+
+	for party := range signers {
+		if party == index {
+			continue
+		}
+		share := secret.Share(identifiers[party])
+		if err := sendFrostMessage(template, members, index, party, frostMessage{Round: frostRoundShare, Share: share.Bytes()}); err != nil {
+			return nil, err
+		}
+	}
+
+	dealt, err := inbox.wait(session, frostRoundShare, len(signers))
+	if err != nil {
+		return nil, err
+	}
+	shares := make([]*edwards25519.Scalar, len(signers))
+	shares[index] = secret.Share(identifiers[index])
+	for party, message := range dealt {
+		share, err := frost.ParseScalar(message.Share)
+		if err == nil {
+			err = frost.VerifyDKGShare(identifiers[index], share, packages[party])
+		}
+		if err != nil {
+			return nil, frostBlame(members, frostTaskKeygen, frostRoundShare, party, err)
+		}
+		shares[party] = share
+	}
+
+	key, err := frost.FinishDKG(shares, packages, identifiers)
+	if err != nil {
+		return nil, err
+	}
+
+	return frostSaveData(key, keys, index)
+}
+
+// frostSaveData converts the FROST key of the party index to the key share format of
+// tss-lib EdDSA
+func frostSaveData(key *frost.DKGKey, keys []*big.Int, index int) (*eddsaKeygen.LocalPartySaveData, error) {
+	var err error
+	save := eddsaKeygen.NewLocalPartySaveData(len(keys))
+	save.Xi = frost.IntFromScalar(key.Secret)
+	save.ShareID = keys[index]
+	copy(save.Ks, keys)
+	for i, verificationShare := range key.VerificationShares {
+		if save.BigXj[i], err = ecPointFromElement(verificationShare); err != nil {
+			return nil, fmt.Errorf("invalid verification share: %w", err)
+		}
+	}
+	if save.EDDSAPub, err = ecPointFromElement(key.GroupKey); err != nil {
+		return nil, fmt.Errorf("invalid group key: %w", err)
+	}
+	return &save, nil
+}
+
+// runFrostSignature signs the hash of a signing message template with a FROST key share,
+// already derived for the path of the signing, and returns the Ed25519 signature
+func runFrostSignature(session *Session, template Message, key *eddsaKeygen.LocalPartySaveData, signers []string, index int) ([]byte, error) {
+	if len(key.Ks) != len(signers) || index < 0 || index >= len(signers) {
+		return nil, errors.New("key share does not match the signers")
+	}
+	identifiers, err := frostIdentifiers(key.Ks)
+	if err != nil {
+		return nil, err
+	}
+	groupKey, err := elementFromECPoint(key.EDDSAPub)
+	if err != nil {
+		return nil, fmt.Errorf("invalid group key: %w", err)
+	}
+	verificationShares := make([]*edwards25519.Point, len(key.BigXj))
+	for i, bigX := range key.BigXj {
+		if verificationShares[i], err = elementFromECPoint(bigX); err != nil {
+			return nil, fmt.Errorf("invalid verification share: %w", err)
+		}
+	}
+	secret := frost.ScalarFromInt(key.Xi)
+
+	_, partiesIds := getPartiesFromKeys(key.Ks)
+	members := committee{sessionID: template.SessionID, ids: partiesIds, signers: signers}
+	inbox := newFrostInbox()
+	sessions.Describe(template.SessionID, SessionInfo{Type: SessionTypeSigning, Identity: template.Identity, KeyCurve: common.CurveFrost, Participants: signers})
+	if _, err := sessions.Start(template.SessionID, frostHandler(members, index, frostTaskSigning, inbox)); err != nil {
+		return nil, err
+	}
+
+	nonces, err := takeFrostNonces(keyShareKey(template.Identity, template.IdentityCurve, common.CurveFrost), secret)
+	if err != nil {
+		return nil, err
+	}
+	own := nonces.Commitment(identifiers[index])
+	if err := sendFrostMessage(template, members, index, -1, frostMessage{Round: frostRoundCommit, Commitment: own}); err != nil {
+		return nil, err
+	}
+
+	received, err := inbox.wait(session, frostRoundCommit, len(signers))
+	if err != nil {
+		return nil, err
+	}
+	commitments := []frost.Commitment{*own}
+	for party, message := range received {
+		c := message.Commitment
+		if c == nil || c.Identifier.Equal(identifiers[party]) != 1 {
+			return nil, frostBlame(members, frostTaskSigning, frostRoundCommit, party, errors.New("missing or misaddressed commitment"))
+		}
+		commitments = append(commitments, *c)
+	}
+
+	share, err := frost.Sign(identifiers[index], secret, groupKey, nonces, template.Hash, commitments)
+	if err != nil {
+		return nil, err
+	}
+	if err := sendFrostMessage(template, members, index, -1, frostMessage{Round: frostRoundShare, Share: share.Bytes()}); err != nil {
+		return nil, err
+	}
+
+	sent, err := inbox.wait(session, frostRoundShare, len(signers))
+	if err != nil {
+		return nil, err
+	}
+	shares := []*edwards25519.Scalar{share}
+	for party, message := range sent {
+		share, err := frost.ParseScalar(message.Share)
+		if err == nil {
+			err = frost.VerifyShare(identifiers[party], verificationShares[party], groupKey, template.Hash, commitments, share)
+		}
+		if err != nil {
+			return nil, frostBlame(members, frostTaskSigning, frostRoundShare, party, err)
+		}
+		shares = append(shares, share)
+	}
+
+	signature, err := frost.Aggregate(groupKey, template.Hash, commitments, shares)
+	if err != nil {
+		return nil, err
+	}
+	if !ed25519.Verify(groupKey.Bytes(), template.Hash, signature) {
+		return nil, errors.New("aggregate signature does not verify")
+	}
+	return signature, nil
+}
+
+// walletKeyCurve returns the key curve of the key a wallet signs for chains of keyCurve
+// with: CurveFrost for the Ed25519 key of wallets created with FROST, keyCurve otherwise
+func walletKeyCurve(identity string, identityCurve common.Curve, keyCurve common.Curve) (common.Curve, error) {
+	if keyCurve != common.CurveEddsa {
+		return keyCurve, nil
+	}
+	signers, err := GetSignersForKeyShare(identity, identityCurve, common.CurveFrost)
+	if err != nil {
+		return "", fmt.Errorf("failed to read signers: %w", err)
+	}
+	if signers != "" {
+		return common.CurveFrost, nil
+	}
+	return common.CurveEddsa, nil
+}
+
+// walletKeyCurves returns the key curves of the keys of a wallet
+func walletKeyCurves(identity string, identityCurve common.Curve) ([]common.Curve, error) {
+	ed25519Curve, err := walletKeyCurve(identity, identityCurve, common.CurveEddsa)
+	if err != nil {
+		return nil, err
+	}
+	return []common.Curve{common.CurveEcdsa, ed25519Curve}, nil
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"filippo.io/edwards25519"
+	"github.com/StripChain/strip-node/common"
+	"github.com/StripChain/strip-node/libs/frost"
+	"github.com/StripChain/strip-node/libs/keystore"
+	eddsaKeygen "github.com/bnb-chain/tss-lib/v2/eddsa/keygen"
+)
+
+// frostKeyShares runs a FROST keygen in memory and converts the keys to key shares
+func frostKeyShares(t *testing.T, n int, threshold int) []*eddsaKeygen.LocalPartySaveData {
+	keys := make([]*big.Int, n)
+	for i := range keys {
+		keys[i] = big.NewInt(int64(i + 1))
+	}
+	identifiers, err := frostIdentifiers(keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secrets := make([]*frost.DKGSecret, n)
+	packages := make([]*frost.DKGPackage, n)
+	for i, identifier := range identifiers {
+		if secrets[i], packages[i], err = frost.DKGRoundOne(identifier, threshold, []byte("session")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	saves := make([]*eddsaKeygen.LocalPartySaveData, n)
+	for i, identifier := range identifiers {
+		shares := make([]*edwards25519.Scalar, n)
+		for j, secret := range secrets {
+			shares[j] = secret.Share(identifier)
+		}
+		key, err := frost.FinishDKG(shares, packages, identifiers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if saves[i], err = frostSaveData(key, keys, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return saves
+}
+
+func TestFrostSaveData(t *testing.T) {
+	saves := frostKeyShares(t, 3, 1)
+
+	for _, save := range saves {
+		// The shares pass the checks of tss-lib EdDSA shares, e.g. of backups
+		share, err := json.Marshal(save)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := keySharePublicKey(common.CurveFrost, string(share)); err != nil {
+			t.Errorf("keySharePublicKey = %v, want a valid share", err)
+		}
+
+		groupKey, err := elementFromECPoint(save.EDDSAPub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		point, err := ecPointFromElement(groupKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !point.Equals(save.EDDSAPub) {
+			t.Error("group key does not round-trip")
+		}
+	}
+}
+
+func TestFrostSigningWithDerivedShares(t *testing.T) {
+	saves := frostKeyShares(t, 3, 1)
+	path, err := parseDerivationPath("m/44/501/0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, save := range saves {
+		if err := deriveEddsaKey(save, path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	identifiers, err := frostIdentifiers(saves[0].Ks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	groupKey, err := elementFromECPoint(saves[0].EDDSAPub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := []byte("transaction")
+
+	nonces := make([]*frost.Nonces, len(saves))
+	commitments := make([]frost.Commitment, len(saves))
+	for i, save := range saves {
+		n, c, err := frost.Commit(identifiers[i], frost.ScalarFromInt(save.Xi), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		nonces[i], commitments[i] = n, *c
+	}
+	shares := make([]*edwards25519.Scalar, len(saves))
+	for i, save := range saves {
+		if shares[i], err = frost.Sign(identifiers[i], frost.ScalarFromInt(save.Xi), groupKey, nonces[i], hash, commitments); err != nil {
+			t.Fatal(err)
+		}
+		verificationShare, err := elementFromECPoint(saves[0].BigXj[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := frost.VerifyShare(identifiers[i], verificationShare, groupKey, hash, commitments, shares[i]); err != nil {
+			t.Errorf("VerifyShare = %v, want a valid share of the derived key", err)
+		}
+	}
+	signature, err := frost.Aggregate(groupKey, hash, commitments, shares)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := verifyRawSignature(common.CurveFrost, groupKey.Bytes(), hash, signature); err != nil {
+		t.Errorf("verifyRawSignature = %v, want a valid FROST signature of the derived key", err)
+	}
+	if err := verifyRawSignature(common.CurveFrost, groupKey.Bytes(), []byte("other"), signature); err == nil {
+		t.Error("verifyRawSignature should fail for another message")
+	}
+}
+
+func TestFrostNoncePool(t *testing.T) {
+	previousStore, previousWrapper := keyValues, keyShareWrapper
+	defer func() { keyValues, keyShareWrapper = previousStore, previousWrapper }()
+	keyValues = &memoryKeyValueStore{}
+	wrapper, err := keystore.NewPassphraseWrapper("test", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	InitialiseKeyShareEncryption(wrapper)
+
+	save := frostKeyShares(t, 3, 1)[0]
+	share, err := json.Marshal(save)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := AddKeyShare("0xuser", common.CurveEcdsa, common.CurveFrost, string(share)); err != nil {
+		t.Fatal(err)
+	}
+	kvKey := keyShareKey("0xuser", common.CurveEcdsa, common.CurveFrost)
+	if err := fillFrostNonces(kvKey, 3); err != nil {
+		t.Fatal(err)
+	}
+	if count, err := CountFrostNonces(kvKey); err != nil || count != 3 {
+		t.Fatalf("CountFrostNonces = %d, %v, want 3", count, err)
+	}
+
+	// Every signing takes its own nonces out of the pool
+	secret := frost.ScalarFromInt(save.Xi)
+	seen := map[string]bool{}
+	for i := 0; i < 4; i++ {
+		nonces, err := takeFrostNonces(kvKey, secret)
+		if err != nil {
+			t.Fatal(err)
+		}
+		encoded := string(nonces.Bytes())
+		if seen[encoded] {
+			t.Fatal("takeFrostNonces returned the same nonces twice")
+		}
+		seen[encoded] = true
+	}
+	if count, err := CountFrostNonces(kvKey); err != nil || count != 0 {
+		t.Errorf("CountFrostNonces = %d, %v, want the pool emptied", count, err)
+	}
+
+	// The pool of a wallet does not hold the nonces of another
+	other := keyShareKey("0xuser2", common.CurveEcdsa, common.CurveFrost)
+	if err := fillFrostNonces(kvKey, 1); err != nil {
+		t.Fatal(err)
+	}
+	if count, err := CountFrostNonces(other); err != nil || count != 0 {
+		t.Errorf("CountFrostNonces(other) = %d, %v, want 0", count, err)
+	}
+	// Nor is it filled without a share
+	if err := fillFrostNonces(other, 1); err != nil {
+		t.Fatal(err)
+	}
+	if count, err := CountFrostNonces(other); err != nil || count != 0 {
+		t.Errorf("CountFrostNonces(other) = %d, %v, want 0 without a key share", count, err)
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"filippo.io/edwards25519"
+	"github.com/StripChain/strip-node/libs/frost"
+	"github.com/StripChain/strip-node/util/logger"
+	eddsaKeygen "github.com/bnb-chain/tss-lib/v2/eddsa/keygen"
+)
+
+// Like the ECDSA pre-params, the nonces of FROST signings are drawn ahead of time: a
+// background pool keeps nonces ready for every FROST wallet this node signs for, sealed in
+// the database like key shares, and every signing takes its own pair. A pair leaves the pool
+// in the statement that reads it, so no two signings ever use the same nonces.
+//
+// Signing still runs both rounds: the commitments to the pooled nonces are sent in the
+// first round rather than published in advance, as that would need the signers of each
+// signing to be agreed upon beforehand (see frost.go).
+
+// frostNoncesCheckInterval is how often the pool is topped up when nothing was taken
+const frostNoncesCheckInterval = time.Minute
+
+var (
+	// frostNoncesTaken wakes the pool up after nonces were taken
+	frostNoncesTaken = make(chan struct{}, 1)
+
+	// frostNonceWallets are the row keys of the FROST key shares the pool fills, wallets
+	// join it when created and when they first sign after a restart
+	frostNonceWallets   = map[string]bool{}
+	frostNonceWalletsMu sync.Mutex
+)
+
+// startFrostNoncePool keeps size nonces in the pool of every FROST wallet, a size of 0
+// disables it
+func startFrostNoncePool(size int) {
+	if size <= 0 {
+		return
+	}
+
+	for {
+		frostNonceWalletsMu.Lock()
+		wallets := make([]string, 0, len(frostNonceWallets))
+		for kvKey := range frostNonceWallets {
+			wallets = append(wallets, kvKey)
+		}
+		frostNonceWalletsMu.Unlock()
+
+		for _, kvKey := range wallets {
+			if err := fillFrostNonces(kvKey, size); err != nil {
+				logger.Sugar().Errorw("failed to add FROST nonces to the pool", "wallet", kvKey, "error", err)
+			}
+		}
+
+		select {
+		case <-frostNoncesTaken:
+		case <-time.After(frostNoncesCheckInterval):
+		}
+	}
+}
+
+// addFrostNonceWallet adds the FROST key share stored under kvKey to the pool
+func addFrostNonceWallet(kvKey string) {
+	frostNonceWalletsMu.Lock()
+	defer frostNonceWalletsMu.Unlock()
+	frostNonceWallets[kvKey] = true
+}
+
+// fillFrostNonces tops the pool of the key share stored under kvKey up to size nonces
+func fillFrostNonces(kvKey string, size int) error {
+	count, err := CountFrostNonces(kvKey)
+	if err != nil || count >= size {
+		return err
+	}
+
+	value, ok, err := keyValues.Get(kvKey)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		// The share was moved away by a resharing, its remaining nonces are never taken
+		frostNonceWalletsMu.Lock()
+		delete(frostNonceWallets, kvKey)
+		frostNonceWalletsMu.Unlock()
+		return nil
+	}
+	keyShare, err := openKeyShare(kvKey, value)
+	if err != nil {
+		return err
+	}
+	secret, err := frostSecret(keyShare)
+	if err != nil {
+		return err
+	}
+
+	for ; count < size; count++ {
+		nonces, err := frost.NewNonces(secret, rand.Reader)
+		if err != nil {
+			return err
+		}
+		if err := AddFrostNonces(kvKey, hex.EncodeToString(nonces.Bytes())); err != nil {
+			return err
+		}
+	}
+	logger.Sugar().Infof("added FROST nonces to the pool of %s, %d of %d", kvKey, count, size)
+	return nil
+}
+
+// frostSecret returns the secret share of a stored FROST key share
+func frostSecret(keyShare string) (*edwards25519.Scalar, error) {
+	var key eddsaKeygen.LocalPartySaveData
+	if err := json.Unmarshal([]byte(keyShare), &key); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal key share: %w", err)
+	}
+	if key.Xi == nil {
+		return nil, fmt.Errorf("key share has no secret")
+	}
+	return frost.ScalarFromInt(key.Xi), nil
+}
+
+// takeFrostNonces returns nonces from the pool of the key share stored under kvKey,
+// drawing them with the secret share when the pool is empty
+func takeFrostNonces(kvKey string, secret *edwards25519.Scalar) (*frost.Nonces, error) {
+	addFrostNonceWallet(kvKey)
+	select {
+	case frostNoncesTaken <- struct{}{}:
+	default:
+	}
+
+	nonces, err := pooledFrostNonces(kvKey)
+	if err != nil {
+		logger.Sugar().Errorw("failed to take FROST nonces from the pool, drawing them", "error", err)
+	}
+	if nonces != nil {
+		return nonces, nil
+	}
+
+	return frost.NewNonces(secret, rand.Reader)
+}
+
+func pooledFrostNonces(kvKey string) (*frost.Nonces, error) {
+	data, err := TakeFrostNonces(kvKey)
+	if err != nil || data == "" {
+		return nil, err
+	}
+
+	b, err := hex.DecodeString(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode FROST nonces: %w", err)
+	}
+	return frost.ParseNonces(b)
+}
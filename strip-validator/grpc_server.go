@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"crypto/sha512"
 	"encoding/base32"
@@ -9,10 +10,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"math/big"
 	"net"
-	"net/http"
 	"time"
 
 	"github.com/StripChain/strip-node/ERC20"
@@ -20,7 +19,7 @@ import (
 	"github.com/StripChain/strip-node/bridge"
 	"github.com/StripChain/strip-node/dogecoin"
 	identityVerification "github.com/StripChain/strip-node/identity"
-	db "github.com/StripChain/strip-node/libs/database"
+	"github.com/StripChain/strip-node/libs/mtls"
 	pb "github.com/StripChain/strip-node/libs/proto"
 	"github.com/StripChain/strip-node/ripple"
 	"github.com/StripChain/strip-node/solver"
@@ -29,6 +28,7 @@ import (
 	"golang.org/x/crypto/blake2b"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/status"
 
@@ -60,6 +60,42 @@ func NewValidatorServer(host host.Host) *validatorServer {
 }
 
 func (s *validatorServer) Keygen(ctx context.Context, req *pb.KeygenRequest) (*pb.KeygenResponse, error) {
+	resp, err := s.StartKeygen(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	curves, err := keygenCurves(req.Ed25519Curve)
+	if err != nil {
+		return nil, err
+	}
+	for _, curve := range curves {
+		sessionID := keygenSessionID(resp.SessionId, curve)
+		logger.Sugar().Infow("Waiting for keygen operation to complete", "session", sessionID)
+		if _, err := sessions.Wait(ctx, sessionID); err != nil {
+			return nil, sessionStatusError("keygen", err)
+		}
+		logger.Sugar().Infow("gRPC Keygen operation completed successfully", "curve", curve)
+	}
+	return &pb.KeygenResponse{Message: "Keygen operation completed successfully"}, nil
+}
+
+// keygenCurves returns the curves a wallet gets a key for: ECDSA and the Ed25519 protocol
+// of the request, tss-lib EdDSA unless FROST is asked for
+func keygenCurves(ed25519Curve pb.Curve) ([]common.Curve, error) {
+	switch ed25519Curve {
+	case pb.Curve_CURVE_UNSPECIFIED, pb.Curve_CURVE_EDDSA:
+		return []common.Curve{common.CurveEcdsa, common.CurveEddsa}, nil
+	case pb.Curve_CURVE_FROST:
+		return []common.Curve{common.CurveEcdsa, common.CurveFrost}, nil
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "invalid ed25519 curve: %v", ed25519Curve)
+	}
+}
+
+// StartKeygen starts the keygen of both curves of a wallet and returns without waiting for
+// it, the progress is reported by GetKeygenStatus on this validator
+func (s *validatorServer) StartKeygen(ctx context.Context, req *pb.KeygenRequest) (*pb.StartKeygenResponse, error) {
 	logger.Sugar().Infow("Received gRPC Keygen request",
 		"identity", req.Identity,
 		"identityCurve", req.IdentityCurve,
@@ -69,107 +105,277 @@ func (s *validatorServer) Keygen(ctx context.Context, req *pb.KeygenRequest) (*p
 	if err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid identity curve: %v", err)
 	}
+	if err := validateCommittee(req.Signers); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid signers: %v", err)
+	}
+
+	curves, err := keygenCurves(req.Ed25519Curve)
+	if err != nil {
+		return nil, err
+	}
+
+	threshold := libs.ResolveThreshold(int(req.Threshold), len(req.Signers))
+	if err := libs.ValidateThresholdPolicy(threshold, len(req.Signers), MaximumSigners); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid threshold policy: %v", err)
+	}
+
+	id := newSessionID()
+	for _, curve := range curves {
+		sessionID := keygenSessionID(id, curve)
+		sessions.Expect(sessionID)
+		logger.Sugar().Infow("Calling generateKeygenMessage for gRPC request", "session", sessionID)
+		generateKeygenMessage(sessionID, req.Identity, identityCurve, curve, req.Signers, threshold)
+	}
+	return &pb.StartKeygenResponse{SessionId: id}, nil
+}
+
+// GetKeygenStatus reports the progress of a keygen started with StartKeygen on this validator
+func (s *validatorServer) GetKeygenStatus(ctx context.Context, req *pb.GetKeygenStatusRequest) (*pb.GetKeygenStatusResponse, error) {
+	ed25519Curve := pb.Curve_CURVE_EDDSA
+	if _, _, ok := sessions.Status(keygenSessionID(req.SessionId, common.CurveFrost)); ok {
+		ed25519Curve = pb.Curve_CURVE_FROST
+	}
+	curves, err := keygenCurves(ed25519Curve)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.GetKeygenStatusResponse{Status: pb.SessionStatus_SESSION_STATUS_COMPLETED}
+	for _, curve := range curves {
+		sessionStatus, reason, ok := sessions.Status(keygenSessionID(req.SessionId, curve))
+		if !ok {
+			return nil, status.Errorf(codes.NotFound, "keygen session %s not found", req.SessionId)
+		}
+
+		switch sessionStatus {
+		case SessionStatusFailed:
+			return &pb.GetKeygenStatusResponse{Status: pb.SessionStatus_SESSION_STATUS_FAILED, Error: reason.Error()}, nil
+		case SessionStatusRunning:
+			resp.Status = pb.SessionStatus_SESSION_STATUS_RUNNING
+		case SessionStatusPending:
+			if resp.Status == pb.SessionStatus_SESSION_STATUS_COMPLETED {
+				resp.Status = pb.SessionStatus_SESSION_STATUS_PENDING
+			}
+		}
+	}
+	return resp, nil
+}
+
+// sessionStatusError converts the error a session failed with to a gRPC status. Rounds
+// aborted by misbehaving signers carry a BlameReport naming them.
+func sessionStatusError(operation string, err error) error {
+	var blamed *BlameError
+	switch {
+	case errors.As(err, &blamed):
+		report, reportErr := blamed.Report()
+		if reportErr != nil {
+			logger.Sugar().Errorw("failed to sign blame report", "session", blamed.SessionID, "error", reportErr)
+			return status.Errorf(codes.Aborted, "%s aborted: %v", operation, err)
+		}
+		st, detailErr := status.New(codes.Aborted, fmt.Sprintf("%s aborted: %v", operation, err)).WithDetails(report)
+		if detailErr != nil {
+			return status.Errorf(codes.Aborted, "%s aborted: %v", operation, err)
+		}
+		return st.Err()
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, "client cancelled request")
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, ErrSessionTimeout):
+		return status.Errorf(codes.DeadlineExceeded, "%s operation timed out", operation)
+	case errors.Is(err, errSigningDenied):
+		return status.Errorf(codes.PermissionDenied, "%s denied: %v", operation, err)
+	case errors.Is(err, errSigningBusy):
+		return status.Errorf(codes.ResourceExhausted, "%s rejected, the signers are busy: %v", operation, err)
+	default:
+		return status.Errorf(codes.Internal, "%s failed: %v", operation, err)
+	}
+}
+
+// GetBlameReport returns the signed blame this validator found for a session, so that the
+// sequencer can match the report of the validator it called with the others'
+func (s *validatorServer) GetBlameReport(ctx context.Context, req *pb.GetBlameReportRequest) (*pb.GetBlameReportResponse, error) {
+	sessionStatus, reason, ok := sessions.Status(req.SessionId)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "session %s not found", req.SessionId)
+	}
+	var blamed *BlameError
+	if sessionStatus != SessionStatusFailed || !errors.As(reason, &blamed) {
+		return nil, status.Errorf(codes.NotFound, "no blame for session %s", req.SessionId)
+	}
+	report, err := blamed.Report()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to sign blame report: %v", err)
+	}
+	return &pb.GetBlameReportResponse{Report: report}, nil
+}
 
-	curvesToGenerate := []common.Curve{common.CurveEcdsa, common.CurveEddsa}
-	for _, curve := range curvesToGenerate {
+// Reshare moves the key shares of a wallet to a new committee of signers. It must be called
+// on a signer of the current committee, which starts the resharing of both curves.
+func (s *validatorServer) Reshare(ctx context.Context, req *pb.ReshareRequest) (*pb.ReshareResponse, error) {
+	logger.Sugar().Infow("Received gRPC Reshare request",
+		"identity", req.Identity,
+		"identityCurve", req.IdentityCurve,
+		"oldSignersCount", len(req.OldSigners),
+		"newSignersCount", len(req.NewSigners))
+
+	identityCurve, err := libs.ProtoToCommonCurve(req.IdentityCurve)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid identity curve: %v", err)
+	}
+	if err := validateCommittee(req.NewSigners); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid new signers: %v", err)
+	}
+	newThreshold := libs.ResolveThreshold(int(req.NewThreshold), len(req.NewSigners))
+	if err := libs.ValidateThresholdPolicy(newThreshold, len(req.NewSigners), MaximumSigners); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid threshold policy: %v", err)
+	}
 
-		// TODO: Make this asynchronous
+	curves, err := walletKeyCurves(req.Identity, identityCurve)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+	for _, curve := range curves {
 		key := req.Identity + "_" + string(identityCurve) + "_" + string(curve)
 
-		opChan := make(chan string)
-		errorChan := make(chan error, 1)
+		signersString, err := GetSignersForKeyShare(req.Identity, identityCurve, curve)
+		if err != nil {
+			return nil, status.Errorf(codes.NotFound, "signers not found for %s: %v", key, err)
+		}
+		signers := []string{}
+		json.Unmarshal([]byte(signersString), &signers)
 
-		// FIXME: This is not thread safe and we shouldn't use a global map
-		keygenGeneratedChan[key] = opChan
+		threshold, err := GetThresholdForKeyShare(req.Identity, identityCurve, curve, len(signers))
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to read threshold of %s: %v", key, err)
+		}
 
-		go func() {
-			defer func() {
-				// Ensure channel is deleted even on panic or completion
-				// delete(keygenGeneratedChan, key)
-				if r := recover(); r != nil {
-					logger.Sugar().Errorw("Panic in gRPC keygen goroutine", "key", key, "error", fmt.Sprintf("%v", r))
-					errorChan <- status.Errorf(codes.Internal, "internal server error: panic in keygen operation")
-				}
-			}()
-			logger.Sugar().Infow("Calling generateKeygenMessage for gRPC request", "key", key)
-			generateKeygenMessage(req.Identity, identityCurve, curve, req.Signers)
-		}()
-
-		logger.Sugar().Infow("Waiting for keygen operation to complete", "key", key)
-		select {
-		case <-opChan:
-			logger.Sugar().Infow("gRPC Keygen operation completed successfully", "curve", curve)
-		case err := <-errorChan:
-			logger.Sugar().Errorw("gRPC Keygen operation failed", "key", key, "error", err)
-			// Propagate gRPC status error if available
-			if s, ok := status.FromError(err); ok {
-				return nil, s.Err()
+		if equalSigners(signers, req.NewSigners) && threshold == newThreshold {
+			// Already reshared, a retry after a partial failure
+			continue
+		}
+		if !equalSigners(signers, req.OldSigners) {
+			return nil, status.Errorf(codes.FailedPrecondition, "old signers do not match the committee of %s", key)
+		}
+
+		keyShare, err := GetKeyShare(req.Identity, identityCurve, curve)
+		if err != nil || keyShare == "" {
+			return nil, status.Errorf(codes.NotFound, "key share not found for %s", key)
+		}
+
+		var partyKeys []*big.Int
+		var publicKey string
+		if curve == common.CurveEcdsa {
+			var rawKey ecdsaKeygen.LocalPartySaveData
+			if err := json.Unmarshal([]byte(keyShare), &rawKey); err != nil {
+				return nil, status.Errorf(codes.Internal, "failed to unmarshal key share: %v", err)
 			}
-			return nil, status.Errorf(codes.Internal, "keygen failed: %+v", err)
-		case <-ctx.Done():
-			logger.Sugar().Warnw("gRPC Keygen request cancelled by client", "key", key)
-			return nil, status.Error(codes.Canceled, "client cancelled request")
-		case <-time.After(5 * time.Minute):
-			logger.Sugar().Errorw("gRPC Keygen operation timed out", "key", key)
-			return nil, status.Error(codes.DeadlineExceeded, "keygen operation timed out")
+			partyKeys = rawKey.Ks
+			publicKey = ecdsaSharePublicKey(&rawKey)
+		} else {
+			var rawKey eddsaKeygen.LocalPartySaveData
+			if err := json.Unmarshal([]byte(keyShare), &rawKey); err != nil {
+				return nil, status.Errorf(codes.Internal, "failed to unmarshal key share: %v", err)
+			}
+			partyKeys = rawKey.Ks
+			publicKey = eddsaSharePublicKey(&rawKey)
 		}
+
+		sessionID := newSessionID()
+		sessions.Expect(sessionID)
+		generateReshareMessage(sessionID, req.Identity, identityCurve, curve, signers, threshold, partyKeys, req.NewSigners, newThreshold, publicKey)
+
+		logger.Sugar().Infow("Waiting for reshare operation to complete", "key", key, "session", sessionID)
+		if _, err := sessions.Wait(ctx, sessionID); err != nil {
+			logger.Sugar().Errorw("gRPC Reshare operation failed", "key", key, "error", err)
+			return nil, sessionStatusError("reshare", err)
+		}
+		logger.Sugar().Infow("gRPC Reshare operation completed successfully", "curve", curve)
 	}
-	return &pb.KeygenResponse{Message: "Keygen operation completed successfully"}, nil
+	return &pb.ReshareResponse{Message: "Reshare operation completed successfully"}, nil
 }
 
 func (s *validatorServer) GetAddresses(ctx context.Context, req *pb.GetAddressesRequest) (*pb.GetAddressesResponse, error) {
-	logger.Sugar().Infow("GetAddresses requested", "identity", req.Identity, "identityCurve", req.IdentityCurve)
+	logger.Sugar().Infow("GetAddresses requested", "identity", req.Identity, "identityCurve", req.IdentityCurve, "derivationPath", req.DerivationPath)
 
 	identityCurveEnum, err := libs.ProtoToCommonCurve(req.IdentityCurve)
 	if err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid identity curve: %v", err)
 	}
+	return deriveAddresses(req.Identity, identityCurveEnum, req.DerivationPath)
+}
 
+// deriveAddresses derives the addresses of a wallet on every registered blockchain from
+// the key shares of this node
+func deriveAddresses(identity string, identityCurveEnum common.Curve, derivationPath string) (*pb.GetAddressesResponse, error) {
 	var rawKeyEddsa *eddsaKeygen.LocalPartySaveData
 	var rawKeyEcdsa *ecdsaKeygen.LocalPartySaveData
 
-	response := &pb.GetAddressesResponse{
-		Addresses: make(map[int32]*pb.BlockchainAddressMap),
+	keyCurvesToCheck, err := walletKeyCurves(identity, identityCurveEnum)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%v", err)
 	}
-
-	keyCurvesToCheck := []common.Curve{common.CurveEcdsa, common.CurveEddsa}
 	foundAnyKey := false
 
 	for _, keyCurve := range keyCurvesToCheck {
-		keyShare, err := GetKeyShare(req.Identity, identityCurveEnum, keyCurve)
+		keyShare, err := GetKeyShare(identity, identityCurveEnum, keyCurve)
 		if err != nil {
-			logger.Sugar().Errorw("Error getting key share from storage", "identity", req.Identity, "curve", keyCurve, "error", err)
+			logger.Sugar().Errorw("Error getting key share from storage", "identity", identity, "curve", keyCurve, "error", err)
 			return nil, status.Errorf(codes.Internal, "error retrieving key share for curve %s: %v", keyCurve, err)
 		}
 
 		if keyShare == "" {
-			logger.Sugar().Warnw("Key share not found in storage", "identity", req.Identity, "curve", keyCurve)
+			logger.Sugar().Warnw("Key share not found in storage", "identity", identity, "curve", keyCurve)
 			continue
 		}
 
 		foundAnyKey = true
 
 		switch keyCurve {
-		case common.CurveEddsa:
+		case common.CurveEddsa, common.CurveFrost:
 			err = json.Unmarshal([]byte(keyShare), &rawKeyEddsa)
 			if err != nil {
-				logger.Sugar().Errorw("Failed to unmarshal EDDSA key share", "identity", req.Identity, "error", err)
+				logger.Sugar().Errorw("Failed to unmarshal EDDSA key share", "identity", identity, "error", err)
 				return nil, status.Errorf(codes.Internal, "failed to process EDDSA key share")
 			}
 		case common.CurveEcdsa:
 			err = json.Unmarshal([]byte(keyShare), &rawKeyEcdsa)
 			if err != nil {
-				logger.Sugar().Errorw("Failed to unmarshal ECDSA key share", "identity", req.Identity, "error", err)
+				logger.Sugar().Errorw("Failed to unmarshal ECDSA key share", "identity", identity, "error", err)
 				return nil, status.Errorf(codes.Internal, "failed to process ECDSA key share")
 			}
 		default:
-			logger.Sugar().Warnw("Unsupported key curve", "identity", req.Identity, "curve", keyCurve)
+			logger.Sugar().Warnw("Unsupported key curve", "identity", identity, "curve", keyCurve)
 			return nil, status.Errorf(codes.Internal, "unsupported key curve: %s", keyCurve)
 		}
 	}
 
 	if !foundAnyKey {
-		return nil, status.Errorf(codes.NotFound, "no key shares found for identity %s with identitycurve %s", req.Identity, identityCurveEnum)
+		return nil, status.Errorf(codes.NotFound, "no key shares found for identity %s with identitycurve %s", identity, identityCurveEnum)
+	}
+
+	// Addresses of a derivation path are those of the child keys
+	path, err := parseDerivationPath(derivationPath)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid derivation path: %v", err)
+	}
+	return walletAddresses(rawKeyEcdsa, rawKeyEddsa, path)
+}
+
+// walletAddresses returns the addresses of the keys of a wallet, or of their child keys at
+// path, on every registered blockchain. Only the public keys of the shares are used.
+func walletAddresses(rawKeyEcdsa *ecdsaKeygen.LocalPartySaveData, rawKeyEddsa *eddsaKeygen.LocalPartySaveData, path []uint32) (*pb.GetAddressesResponse, error) {
+	response := &pb.GetAddressesResponse{
+		Addresses: make(map[int32]*pb.BlockchainAddressMap),
+	}
+
+	if rawKeyEcdsa != nil {
+		if _, err := deriveEcdsaKey(rawKeyEcdsa, path); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to derive ECDSA key: %v", err)
+		}
+	}
+	if rawKeyEddsa != nil {
+		if err := deriveEddsaKey(rawKeyEddsa, path); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to derive EDDSA key: %v", err)
+		}
 	}
 
 	registeredChains := blockchains.GetRegisteredBlockchains()
@@ -331,6 +537,169 @@ func (s *validatorServer) GetAddresses(ctx context.Context, req *pb.GetAddresses
 }
 
 func (s *validatorServer) SignIntentOperation(ctx context.Context, req *pb.SignIntentOperationRequest) (*pb.SignIntentOperationResponse, error) {
+	op, err := resolveOperationSigning(req)
+	if err != nil {
+		return nil, err
+	}
+
+	msgBytes, err := decodeSigningMessage(op.operation.BlockchainID, op.msg)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Sugar().Infow("Calling generateSignatureMessage for gRPC request", "msg", op.msg, "identity", op.signingIdentity)
+	sessionID := generateDerivedSignatureMessage(newSessionID(), op.origin(), op.signingIdentity, op.operation.DerivationPath, op.operation.BlockchainID, op.identityCurve, op.keyCurve, msgBytes)
+
+	logger.Sugar().Infow("Waiting for signature result", "msg", op.msg)
+	sigResult, _, err := waitSignature(ctx, sessionID)
+	if err != nil {
+		logger.Sugar().Errorw("gRPC SignIntentOperation failed", "msg", op.msg, "error", err)
+		return nil, sessionStatusError("signature", err)
+	}
+	logger.Sugar().Infow("Received signature result via channel", "address", sigResult.Address, "sigLen", len(sigResult.Message))
+
+	signature, err := encodeSignature(op.operation.BlockchainID, op.msg, sigResult)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Sugar().Infow("Successfully generated signature via gRPC", "intentID", op.intent.ID, "opIndex", op.operationIndex)
+	return &pb.SignIntentOperationResponse{Signature: signature, Receipt: signingReceipt(ctx, sigResult)}, nil
+}
+
+// signingReceipt returns the receipt of a signature, or nil when it could not be collected.
+// A missing receipt does not fail the signing, the signature is valid without it.
+func signingReceipt(ctx context.Context, sigResult Message) *pb.SigningReceipt {
+	receipt, err := collectReceipt(ctx, sigResult)
+	if err != nil {
+		logger.Sugar().Warnw("failed to collect signing receipt", "session", sigResult.SessionID, "error", err)
+		return nil
+	}
+	return receipt
+}
+
+// SignBatch signs several hashes of one operation with the same wallet, e.g. one per input
+// of a Bitcoin transaction. Every hash gets its own signing session under a shared request,
+// so the signers run them in parallel, and the signatures are returned together.
+func (s *validatorServer) SignBatch(ctx context.Context, req *pb.SignBatchRequest) (*pb.SignBatchResponse, error) {
+	if len(req.Messages) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "no messages to sign")
+	}
+
+	op, err := resolveOperationSigning(&pb.SignIntentOperationRequest{Intent: req.Intent, OperationIndex: req.OperationIndex})
+	if err != nil {
+		return nil, err
+	}
+
+	// Only hashes listed in the operation's data to sign may be signed
+	listed := make(map[string]bool)
+	for _, hash := range blockchains.SplitDataToSign(op.msg) {
+		listed[hash] = true
+	}
+
+	msgBytes := make([][]byte, len(req.Messages))
+	requested := make(map[string]bool, len(req.Messages))
+	for i, msg := range req.Messages {
+		if !listed[msg] {
+			return nil, status.Errorf(codes.InvalidArgument, "message %d is not part of the data to sign of operation %d", i, op.operationIndex)
+		}
+		if requested[msg] {
+			return nil, status.Errorf(codes.InvalidArgument, "message %d is a duplicate", i)
+		}
+		requested[msg] = true
+
+		msgBytes[i], err = decodeSigningMessage(op.operation.BlockchainID, msg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	logger.Sugar().Infow("Starting batch signing", "intentID", op.intent.ID, "opIndex", op.operationIndex, "messages", len(req.Messages), "identity", op.signingIdentity)
+
+	requestID := newSessionID()
+	sessionIDs := make([]string, len(msgBytes))
+	for i := range msgBytes {
+		sessionIDs[i] = generateDerivedSignatureMessage(requestID, op.origin(), op.signingIdentity, op.operation.DerivationPath, op.operation.BlockchainID, op.identityCurve, op.keyCurve, msgBytes[i])
+	}
+
+	// Stop the local parties of sessions still running when the batch fails
+	defer func() {
+		for _, sessionID := range sessionIDs {
+			sessions.Cancel(sessionID)
+		}
+	}()
+
+	signatures := make([]string, len(sessionIDs))
+	results := make([]Message, len(sessionIDs))
+	for i := range sessionIDs {
+		// A retried signing runs under a new session, which the deferred cancel must stop
+		var sigResult Message
+		sigResult, sessionIDs[i], err = waitSignature(ctx, sessionIDs[i])
+		if err != nil {
+			logger.Sugar().Errorw("gRPC SignBatch failed", "intentID", op.intent.ID, "message", i, "error", err)
+			return nil, sessionStatusError("signature", err)
+		}
+
+		signatures[i], err = encodeSignature(op.operation.BlockchainID, req.Messages[i], sigResult)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = sigResult
+	}
+
+	// The co-signatures of all the receipts arrive in parallel, so waiting for them in turn
+	// takes about as long as for one
+	signingReceipts := make([]*pb.SigningReceipt, len(results))
+	for i, sigResult := range results {
+		signingReceipts[i] = signingReceipt(ctx, sigResult)
+		if signingReceipts[i] == nil {
+			signingReceipts[i] = &pb.SigningReceipt{}
+		}
+	}
+
+	logger.Sugar().Infow("Successfully generated batch signatures via gRPC", "intentID", op.intent.ID, "opIndex", op.operationIndex, "count", len(signatures))
+	return &pb.SignBatchResponse{Signatures: signatures, Receipts: signingReceipts}, nil
+}
+
+// operationSigning is an operation of a verified intent and the wallet that signs it
+type operationSigning struct {
+	request         *pb.Intent
+	intent          *libs.Intent
+	operationIndex  int
+	operation       libs.Operation
+	msg             string
+	signingIdentity string
+	identityCurve   common.Curve
+	keyCurve        common.Curve
+}
+
+// origin returns the intent operation recorded in the receipts of the signing
+func (op *operationSigning) origin() signingOrigin {
+	return signingOrigin{IntentID: op.intent.ID.String(), OperationIndex: op.operationIndex, intent: op.request}
+}
+
+// matches checks that a START_SIGN message signs a message of the operation with its wallet
+func (op *operationSigning) matches(msg Message) error {
+	if msg.IntentID != op.intent.ID.String() {
+		return fmt.Errorf("signing is for intent %s, not %s", msg.IntentID, op.intent.ID)
+	}
+	if msg.Identity != op.signingIdentity || msg.IdentityCurve != op.identityCurve || msg.KeyCurve != op.keyCurve ||
+		msg.DerivationPath != op.operation.DerivationPath || msg.BlockchainID != op.operation.BlockchainID {
+		return errors.New("signing is not made with the wallet of the operation")
+	}
+	// A batch signs the hashes listed in the data to sign one by one
+	for _, candidate := range append([]string{op.msg}, blockchains.SplitDataToSign(op.msg)...) {
+		msgBytes, err := decodeSigningMessage(op.operation.BlockchainID, candidate)
+		if err == nil && bytes.Equal(msgBytes, msg.Hash) {
+			return nil
+		}
+	}
+	return errors.New("signed message is not the data to sign of the operation")
+}
+
+// resolveOperationSigning verifies the intent of a signing request and determines the
+// message of the operation and the wallet signing it
+func resolveOperationSigning(req *pb.SignIntentOperationRequest) (*operationSigning, error) {
 	if req.Intent == nil {
 		return nil, status.Error(codes.InvalidArgument, "missing intent")
 	}
@@ -338,7 +707,7 @@ func (s *validatorServer) SignIntentOperation(ctx context.Context, req *pb.SignI
 		return nil, status.Errorf(codes.InvalidArgument, "invalid operation index %d (intent has %d operations)", req.OperationIndex, len(req.Intent.Operations))
 	}
 
-	logger.Sugar().Infow("gRPC SignIntentOperation requested",
+	logger.Sugar().Infow("Signing of intent operation requested",
 		"intentID", req.Intent.ID,
 		"opIndex", req.OperationIndex)
 
@@ -354,6 +723,9 @@ func (s *validatorServer) SignIntentOperation(ctx context.Context, req *pb.SignI
 		return nil, status.Errorf(codes.Internal, "failed to map operations from intent")
 	}
 	operation := intent.Operations[operationIndex]
+	if _, err := parseDerivationPath(operation.DerivationPath); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid derivation path: %v", err)
+	}
 
 	if intent.Expiry.Before(time.Now().UTC()) {
 		logger.Sugar().Errorw("Intent has expired", "intentID", intent.ID, "expiry", intent.Expiry)
@@ -377,313 +749,20 @@ func (s *validatorServer) SignIntentOperation(ctx context.Context, req *pb.SignI
 		if operation.DataToSign != nil {
 			msg = *operation.DataToSign
 		}
-	case libs.OperationTypeSendToBridge:
-		// Verify only operation for bridging
-		// Get bridgewallet by calling /getBridgeAddress from sequencer api
-		req, err := http.NewRequest("GET", SequencerHost+"/getBridgeAddress", nil)
-		if err != nil {
-			logger.Sugar().Errorw("error creating request", "error", err)
-			return nil, status.Errorf(codes.Internal, "error creating request: %v", err)
-		}
-
-		req.Header.Set("Content-Type", "application/json")
-		client := &http.Client{}
-		resp, err := client.Do(req)
-		if err != nil {
-			logger.Sugar().Errorw("error sending request", "error", err)
-			return nil, status.Errorf(codes.Internal, "error sending request: %v", err)
+		if err := verifyDataToSign(opBlockchain, operation.SerializedTxn, msg); err != nil {
+			return nil, err
 		}
-
-		defer resp.Body.Close()
-
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			logger.Sugar().Errorw("error reading response body", "error", err)
-			return nil, status.Errorf(codes.Internal, "error reading response body: %v", err)
+	case libs.OperationTypeSendToBridge:
+		// The destination is checked against the bridge wallet this node derives itself
+		if err := verifyBridgeDestination(opBlockchain, operation); err != nil {
+			return nil, err
 		}
-
-		var bridgeWallet db.WalletSchema
-		err = json.Unmarshal(body, &bridgeWallet)
-		if err != nil {
-			logger.Sugar().Errorw("error unmarshalling response body", "error", err)
-			return nil, status.Errorf(codes.Internal, "error unmarshalling response body: %v", err)
-		}
-
-		// if operation.KeyCurve == "ecdsa" || operation.KeyCurve == "bitcoin_ecdsa" || operation.KeyCurve == "dogecoin_ecdsa" {
-		// 	chain, err := common.GetChain(operation.ChainId)
-		// 	if err != nil {
-		// 		logger.Sugar().Errorw("error getting chain", "error", err)
-		// 		return
-		// 	}
-
-		// 	// Extract destination address from serialized transaction
-		// 	var destAddress string
-		// 	if chain.ChainType == "bitcoin" || chain.ChainType == "dogecoin" {
-		// 		// For Bitcoin, decode the serialized transaction to get output address
-		// 		var tx wire.MsgTx
-		// 		txBytes, err := hex.DecodeString(operation.SerializedTxn)
-		// 		if err != nil {
-		// 			logger.Sugar().Errorw("error decoding bitcoin&dogecoin transaction", "error", err)
-		// 			return
-		// 		}
-		// 		if err := tx.Deserialize(bytes.NewReader(txBytes)); err != nil {
-		// 			logger.Sugar().Errorw("error deserializing bitcoin&dogecoin transaction", "error", err)
-		// 			return
-		// 		}
-		// 		// Get the first output's address (assuming it's the bridge address)
-		// 		if len(tx.TxOut) > 0 {
-		// 			_, addrs, _, err := txscript.ExtractPkScriptAddrs(tx.TxOut[0].PkScript, nil)
-		// 			if err != nil || len(addrs) == 0 {
-		// 				logger.Sugar().Errorw("error extracting bitcoin&dogecoin address", "error", err)
-		// 				return
-		// 			}
-		// 			destAddress = addrs[0].String()
-		// 		}
-		// 	} else {
-		// 		// For EVM chains, decode the transaction to get the 'to' address
-		// 		txBytes, err := hex.DecodeString(operation.SerializedTxn)
-		// 		if err != nil {
-		// 			logger.Sugar().Errorw("error decoding EVM transaction", "error", err)
-		// 			return
-		// 		}
-		// 		tx := new(types.Transaction)
-		// 		if err := rlp.DecodeBytes(txBytes, tx); err != nil {
-		// 			logger.Sugar().Errorw("error deserializing EVM transaction", "error", err)
-		// 			return
-		// 		}
-		// 		// Check if this is an ERC20 transfer (function signature: transfer(address,uint256))
-		// 		// ERC20 transfer function signature is: 0xa9059cbb
-		// 		isERC20Transfer := false
-		// 		contractAddress := tx.To().Hex()
-		// 		if tx.Data() != nil && len(tx.Data()) >= 4 && hex.EncodeToString(tx.Data()[:4]) == "a9059cbb" {
-		// 			// This is an ERC20 transfer - extract the recipient address from the data
-		// 			// The recipient address is the first parameter of the transfer function (32-byte padded)
-		// 			isERC20Transfer = true
-		// 			if len(tx.Data()) >= 36 {
-		// 				// Extract the recipient address from position 4:36 (32 bytes)
-		// 				recipientBytes := tx.Data()[4:36]
-		// 				// Convert to address (take last 20 bytes for proper Ethereum address length)
-		// 				destAddress = "0x" + hex.EncodeToString(recipientBytes[12:])
-		// 				logger.Sugar().Infow("detected ERC20 transfer", "contract", contractAddress, "recipient", destAddress)
-		// 			} else {
-		// 				logger.Sugar().Errorw("invalid ERC20 transfer data length", "data", hex.EncodeToString(tx.Data()))
-		// 				return
-		// 			}
-		// 		} else {
-		// 			// For regular ETH transfers, use the 'to' address directly
-		// 			destAddress = contractAddress
-		// 		}
-
-		// 		// Verify destination address matches bridge wallet
-		// 		var expectedAddress string
-		// 		if chain.ChainType == "bitcoin" {
-		// 			expectedAddress = bridgeWallet.BitcoinMainnetPublicKey
-		// 		} else if chain.ChainType == "dogecoin" {
-		// 			expectedAddress = bridgeWallet.DogecoinMainnetPublicKey
-		// 		} else {
-		// 			// For Ethereum chains, determine if we need to use the bridge contract address
-		// 			if chain.ChainType == "evm" && !isERC20Transfer {
-		// 				// For native ETH transfers, get the bridge address from the dedicated endpoint
-		// 				bridgeReq, err := http.NewRequest("GET", SequencerHost+"/getBridgeAddress", nil)
-		// 				if err != nil {
-		// 					logger.Sugar().Errorw("error creating bridge address request", "error", err)
-		// 					return
-		// 				}
-
-		// 				bridgeReq.Header.Set("Content-Type", "application/json")
-		// 				bridgeClient := &http.Client{}
-		// 				bridgeResp, err := bridgeClient.Do(bridgeReq)
-		// 				if err != nil {
-		// 					logger.Sugar().Errorw("error fetching bridge address", "error", err)
-		// 					return
-		// 				}
-
-		// 				defer bridgeResp.Body.Close()
-
-		// 				bridgeBody, err := io.ReadAll(bridgeResp.Body)
-		// 				if err != nil {
-		// 					logger.Sugar().Errorw("error reading bridge address response", "error", err)
-		// 					return
-		// 				}
-
-		// 				var bridgeAddressWallet db.WalletSchema
-		// 				err = json.Unmarshal(bridgeBody, &bridgeAddressWallet)
-		// 				if err != nil {
-		// 					logger.Sugar().Errorw("error unmarshalling bridge address response", "error", err)
-		// 					return
-		// 				}
-
-		// 				expectedAddress = bridgeAddressWallet.ECDSAPublicKey
-		// 				logger.Sugar().Infow("using bridge contract address for native ETH transfer", "address", expectedAddress)
-		// 			} else {
-		// 				expectedAddress = bridgeWallet.ECDSAPublicKey
-		// 			}
-		// 		}
-
-		// 		// Verify the extracted destination matches the bridge wallet
-		// 		if !strings.EqualFold(destAddress, expectedAddress) {
-		// 			logger.Sugar().Errorw("Invalid bridge destination address", "expected", expectedAddress, "got", destAddress)
-		// 			return
-		// 		}
-		// 	}
-		// } else if operation.KeyCurve == "eddsa" || operation.KeyCurve == "aptos_eddsa" || operation.KeyCurve == "stellar_eddsa" || operation.KeyCurve == "algorand_eddsa" || operation.KeyCurve == "ripple_eddsa" || operation.KeyCurve == "cardano_eddsa" || operation.KeyCurve == "sui_eddsa" {
-		// 	chain, err := common.GetChain(operation.ChainId)
-		// 	if err != nil {
-		// 		logger.Sugar().Errorw("error getting chain", "error", err)
-		// 		return
-		// 	}
-
-		// 	// Verify destination address matches bridge wallet based on chain type
-		// 	var validDestination bool
-		// 	var destAddress string
-
-		// 	// Extract destination address from serialized transaction based on chain type
-		// 	switch chain.ChainType {
-		// 	case "solana":
-		// 		// Decode base58 transaction and extract destination
-		// 		decodedTxn, err := base58.Decode(operation.SerializedTxn)
-		// 		if err != nil {
-		// 			logger.Sugar().Errorw("error decoding Solana transaction", "error", err)
-		// 			return
-		// 		}
-		// 		tx, err := solanasdk.TransactionFromDecoder(bin.NewBinDecoder(decodedTxn))
-		// 		if err != nil || len(tx.Message.Instructions) == 0 {
-		// 			logger.Sugar().Errorw("error deserializing Solana transaction", "error", err)
-		// 			return
-		// 		}
-		// 		// Get the first instruction's destination account index
-		// 		destAccountIndex := tx.Message.Instructions[0].Accounts[1]
-		// 		// Get the actual account address from the message accounts
-		// 		destAddress = tx.Message.AccountKeys[destAccountIndex].String()
-		// 	case "aptos":
-		// 		// For Aptos, the destination is in the transaction payload
-		// 		var aptosPayload struct {
-		// 			Function string   `json:"function"`
-		// 			Args     []string `json:"arguments"`
-		// 		}
-		// 		if err := json.Unmarshal([]byte(operation.SerializedTxn), &aptosPayload); err != nil {
-		// 			logger.Sugar().Errorw("error parsing Aptos transaction", "error", err)
-		// 			return
-		// 		}
-		// 		if len(aptosPayload.Args) > 0 {
-		// 			destAddress = aptosPayload.Args[0] // First arg is typically the recipient
-		// 		}
-		// 	case "stellar":
-		// 		// For Stellar, parse the XDR transaction envelope
-		// 		var txEnv xdr.TransactionEnvelope
-		// 		err := xdr.SafeUnmarshalBase64(operation.SerializedTxn, &txEnv)
-		// 		if err != nil {
-		// 			logger.Sugar().Errorw("error parsing Stellar transaction", "error", err)
-		// 			return
-		// 		}
-
-		// 		// Get the first operation's destination
-		// 		if len(txEnv.Operations()) > 0 {
-		// 			if paymentOp, ok := txEnv.Operations()[0].Body.GetPaymentOp(); ok {
-		// 				destAddress = paymentOp.Destination.Address()
-		// 			}
-		// 		}
-		// 	case "algorand":
-		// 		txnBytes, err := base64.StdEncoding.DecodeString(operation.SerializedTxn)
-		// 		if err != nil {
-		// 			logger.Sugar().Errorw("failed to decode serialized transaction", "error", err)
-		// 			return
-		// 		}
-		// 		var txn algorandTypes.Transaction
-		// 		err = msgpack.Decode(txnBytes, &txn)
-		// 		if err != nil {
-		// 			logger.Sugar().Errorw("failed to deserialize transaction", "error", err)
-		// 			return
-		// 		}
-		// 		if txn.Type == algorandTypes.PaymentTx {
-		// 			destAddress = txn.PaymentTxnFields.Receiver.String()
-		// 		} else if txn.Type == algorandTypes.AssetTransferTx {
-		// 			destAddress = txn.AssetTransferTxnFields.AssetReceiver.String()
-		// 		} else {
-		// 			logger.Sugar().Errorw("Unknown transaction type", "type", txn.Type)
-		// 			return
-		// 		}
-		// 	case "ripple":
-		// 		// For Ripple, the destination is in the transaction payload
-		// 		// Decode the serialized transaction
-		// 		txBytes, err := hex.DecodeString(strings.TrimPrefix(operation.SerializedTxn, "0x"))
-		// 		if err != nil {
-		// 			logger.Sugar().Errorw("error decoding transaction", "error", err)
-		// 			return
-		// 		}
-
-		// 		// Parse the transaction
-		// 		var tx data.Payment
-		// 		err = json.Unmarshal(txBytes, &tx)
-		// 		if err != nil {
-		// 			logger.Sugar().Errorw("error unmarshalling transaction", "error", err)
-		// 			return
-		// 		}
-		// 		destAddress = tx.Destination.String()
-		// 	case "cardano":
-		// 		var tx cardanolib.Tx
-		// 		txBytes, err := hex.DecodeString(operation.SerializedTxn)
-		// 		if err != nil {
-		// 			logger.Sugar().Errorw("error decoding Cardano transaction", "error", err)
-		// 			return
-		// 		}
-		// 		if err := json.Unmarshal(txBytes, &tx); err != nil {
-		// 			logger.Sugar().Errorw("error parsing Cardano transaction", "error", err)
-		// 			return
-		// 		}
-		// 		destAddress = tx.Body.Outputs[0].Address.String()
-		// 	case "sui":
-		// 		var tx sui_types.TransactionData
-		// 		txBytes, err := base64.StdEncoding.DecodeString(operation.SerializedTxn)
-		// 		if err != nil {
-		// 			logger.Sugar().Errorw("error decoding Sui transaction", "error", err)
-		// 			return
-		// 		}
-		// 		if err := json.Unmarshal(txBytes, &tx); err != nil {
-		// 			logger.Sugar().Errorw("error parsing Sui transaction", "error", err)
-		// 			return
-		// 		}
-		// 		if len(tx.V1.Kind.ProgrammableTransaction.Inputs) < 1 {
-		// 			logger.Sugar().Errorw("wrong format sui transaction", "error", err)
-		// 			return
-		// 		}
-		// 		destAddress = string(*tx.V1.Kind.ProgrammableTransaction.Inputs[0].Pure)
-		// 	}
-
-		// 	// Verify the extracted destination matches the bridge wallet
-		// 	if destAddress == "" {
-		// 		logger.Sugar().Errorw("Failed to extract destination address from %s transaction", chain.ChainType)
-		// 		validDestination = false
-		// 	} else {
-		// 		switch chain.ChainType {
-		// 		case "solana":
-		// 			validDestination = strings.EqualFold(destAddress, bridgeWallet.EDDSAPublicKey)
-		// 		case "aptos":
-		// 			validDestination = strings.EqualFold(destAddress, bridgeWallet.AptosEDDSAPublicKey)
-		// 		case "stellar":
-		// 			validDestination = strings.EqualFold(destAddress, bridgeWallet.StellarPublicKey)
-		// 		case "algorand":
-		// 			validDestination = strings.EqualFold(destAddress, bridgeWallet.AlgorandEDDSAPublicKey)
-		// 		case "ripple":
-		// 			validDestination = strings.EqualFold(destAddress, bridgeWallet.RippleEDDSAPublicKey)
-		// 		case "cardano":
-		// 			validDestination = strings.EqualFold(destAddress, bridgeWallet.CardanoPublicKey)
-		// 		case "sui":
-		// 			validDestination = strings.EqualFold(destAddress, bridgeWallet.SuiPublicKey)
-		// 		}
-		// 	}
-
-		// 	if !validDestination {
-		// 		logger.Sugar().Errorw("Invalid bridge destination address for", "chain", chain.ChainType)
-		// 		return
-		// 	}
-		// }
-
-		// Set message
-		msg = ""
 		if operation.DataToSign != nil {
 			msg = *operation.DataToSign
 		}
+		if err := verifyDataToSign(opBlockchain, operation.SerializedTxn, msg); err != nil {
+			return nil, err
+		}
 	case libs.OperationTypeSolver:
 		intentBytes, err := json.Marshal(intent)
 		if err != nil {
@@ -942,38 +1021,15 @@ func (s *validatorServer) SignIntentOperation(ctx context.Context, req *pb.SignI
 			return nil, status.Errorf(codes.Internal, "error unmarshalling burn synthetic metadata: %v", err)
 		}
 
-		// Get bridgewallet by calling /getwallet from sequencer api
-		req, err := http.NewRequest("GET", fmt.Sprintf("%s/getWallet?identity=%s&blockchain=%s", SequencerHost, intent.Identity, intent.BlockchainID), nil)
+		// The wallet is derived from the key shares of this node rather than asked to the sequencer
+		wallet, err := blockchainWallet(intent.Identity, intent.BlockchainID)
 		if err != nil {
-			logger.Sugar().Errorw("error creating request", "error", err)
-			return nil, status.Errorf(codes.Internal, "error creating request: %v", err)
-		}
-
-		req.Header.Set("Content-Type", "application/json")
-		client := &http.Client{}
-		resp, err := client.Do(req)
-		if err != nil {
-			logger.Sugar().Errorw("error sending request", "error", err)
-			return nil, status.Errorf(codes.Internal, "error sending request: %v", err)
-		}
-
-		defer resp.Body.Close()
-
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			logger.Sugar().Errorw("error reading response body", "error", err)
-			return nil, status.Errorf(codes.Internal, "error reading response body: %v", err)
-		}
-
-		var bridgeWallet db.WalletSchema
-		err = json.Unmarshal(body, &bridgeWallet)
-		if err != nil {
-			logger.Sugar().Errorw("error unmarshalling response body", "error", err)
-			return nil, status.Errorf(codes.Internal, "error unmarshalling response body: %v", err)
+			logger.Sugar().Errorw("error deriving wallet", "identity", intent.Identity, "error", err)
+			return nil, status.Errorf(codes.Internal, "error deriving wallet: %v", err)
 		}
 
 		// Verify the user has sufficient token balance
-		balance, err := ERC20.GetBalance(RPC_URL, burnSyntheticMetadata.Token, bridgeWallet.ECDSAPublicKey)
+		balance, err := ERC20.GetBalance(RPC_URL, burnSyntheticMetadata.Token, wallet.EcdsaAddress)
 		if err != nil {
 			logger.Sugar().Errorw("Error getting token balance:", "error", err)
 			return nil, status.Errorf(codes.Internal, "error getting token balance: %v", err)
@@ -1052,6 +1108,24 @@ func (s *validatorServer) SignIntentOperation(ctx context.Context, req *pb.SignI
 			return nil, status.Errorf(codes.Internal, "token mismatch")
 		}
 
+		// Set message
+		msg = operation.SolverDataToSign
+	case libs.OperationTypeTrustSet:
+		if operation.BlockchainID != blockchains.Ripple {
+			logger.Sugar().Errorw("Trust lines are only supported on Ripple", "blockchainID", operation.BlockchainID)
+			return nil, status.Errorf(codes.InvalidArgument, "trust set is not supported on %s", operation.BlockchainID)
+		}
+
+		var trustSetMetadata TrustSetMetadata
+		err := json.Unmarshal([]byte(operation.SolverMetadata), &trustSetMetadata)
+		if err != nil || trustSetMetadata.Token == "" || trustSetMetadata.Limit == "" {
+			logger.Sugar().Errorw("Invalid metadata for trust set operation at signing", "error", err)
+			return nil, status.Errorf(codes.InvalidArgument, "trust set requires token and limit metadata")
+		}
+		if err := verifyTrustSet(opBlockchain, intent.Identity, intentBlockchain.KeyCurve(), operation, trustSetMetadata); err != nil {
+			return nil, err
+		}
+
 		// Set message
 		msg = operation.SolverDataToSign
 	default:
@@ -1090,9 +1164,67 @@ func (s *validatorServer) SignIntentOperation(ctx context.Context, req *pb.SignI
 	identity := intent.Identity
 	identityCurve := intentBlockchain.KeyCurve()
 	keyCurve := opBlockchain.KeyCurve()
+	// Determine identity for signing (special case for bridge ops on EVM)
+	signingIdentity := identity
+	if (blockchains.IsEVMBlockchain(operation.BlockchainID) || operation.BlockchainID == blockchains.Solana || operation.BlockchainID == blockchains.Ripple) &&
+		(operation.Type == libs.OperationTypeBridgeDeposit ||
+			operation.Type == libs.OperationTypeSwap ||
+			operation.Type == libs.OperationTypeBurn ||
+			operation.Type == libs.OperationTypeBurnSynthetic ||
+			operation.Type == libs.OperationTypeWithdraw) {
+		signingIdentity = BridgeContractAddress
+		identityCurve = common.CurveEcdsa
+		logger.Sugar().Infow("Using BridgeContractAddress as signing identity", "address", signingIdentity)
+	}
+
+	keyCurve, err = walletKeyCurve(signingIdentity, identityCurve, keyCurve)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%v", err)
+	}
+
+	// The policy is checked last so only verified operations count towards daily limits
+	if err := enforceSigningPolicy(intent, operationIndex, opBlockchain, signingIdentity); err != nil {
+		return nil, err
+	}
+
+	return &operationSigning{
+		request:         req.Intent,
+		intent:          intent,
+		operationIndex:  operationIndex,
+		operation:       operation,
+		msg:             msg,
+		signingIdentity: signingIdentity,
+		identityCurve:   identityCurve,
+		keyCurve:        keyCurve,
+	}, nil
+}
+
+// verifyDataToSign recomputes the payload of a transaction from its serialized form and
+// checks that it is the data to sign of the operation, so that nothing but the transaction
+// of the intent gets signed
+func verifyDataToSign(chain blockchains.IBlockchain, serializedTxn *string, dataToSign string) error {
+	if serializedTxn == nil || *serializedTxn == "" {
+		return status.Error(codes.InvalidArgument, "transaction operation has no serialized transaction")
+	}
+
+	computed, err := chain.ComputeDataToSign(*serializedTxn)
+	if err != nil {
+		logger.Sugar().Errorw("Failed to compute data to sign", "chain", chain.ChainName(), "error", err)
+		return status.Errorf(codes.InvalidArgument, "failed to compute data to sign from the serialized transaction: %v", err)
+	}
+	if computed != dataToSign {
+		logger.Sugar().Errorw("Data to sign does not match the serialized transaction", "chain", chain.ChainName(), "dataToSign", dataToSign, "computed", computed)
+		return status.Error(codes.InvalidArgument, "data to sign does not match the serialized transaction")
+	}
+	return nil
+}
+
+// decodeSigningMessage converts the message of an operation to the bytes the signers sign
+func decodeSigningMessage(blockchainID blockchains.BlockchainID, msg string) ([]byte, error) {
 	var msgBytes []byte
+	var err error
 
-	switch operation.BlockchainID {
+	switch blockchainID {
 	case blockchains.Solana:
 		msgBytes, err = base58.Decode(msg)
 		if err != nil {
@@ -1114,7 +1246,7 @@ func (s *validatorServer) SignIntentOperation(ctx context.Context, req *pb.SignI
 	case blockchains.Stellar, blockchains.Algorand:
 		mBytes, err := base64.StdEncoding.DecodeString(msg)
 		if err != nil {
-			return nil, status.Errorf(codes.InvalidArgument, "invalid base64 message for %s: %v", operation.BlockchainID, err)
+			return nil, status.Errorf(codes.InvalidArgument, "invalid base64 message for %s: %v", blockchainID, err)
 		}
 		msgBytes = mBytes
 	case blockchains.Ripple, blockchains.Cardano, blockchains.Aptos:
@@ -1124,112 +1256,59 @@ func (s *validatorServer) SignIntentOperation(ctx context.Context, req *pb.SignI
 		}
 		msgBytes = mBytes
 	default:
-		if blockchains.IsEVMBlockchain(operation.BlockchainID) {
+		if blockchains.IsEVMBlockchain(blockchainID) {
 			// EVM expects raw bytes of the message string
 			msgBytes = []byte(msg)
 		} else {
-			logger.Sugar().Errorw("Unsupported blockchain for signing", "id", operation.BlockchainID)
-			return nil, status.Errorf(codes.InvalidArgument, "unsupported blockchain for signing: %s", operation.BlockchainID)
+			logger.Sugar().Errorw("Unsupported blockchain for signing", "id", blockchainID)
+			return nil, status.Errorf(codes.InvalidArgument, "unsupported blockchain for signing: %s", blockchainID)
 		}
 	}
 
-	sigChan := make(chan Message)
-
-	// FIXME: This is not thread safe and we shouldn't use a global map
-	messageChan[msg] = sigChan
-
-	// Determine identity for signing (special case for bridge ops on EVM)
-	signingIdentity := identity
-	if (blockchains.IsEVMBlockchain(operation.BlockchainID) || operation.BlockchainID == blockchains.Solana) &&
-		(operation.Type == libs.OperationTypeBridgeDeposit ||
-			operation.Type == libs.OperationTypeSwap ||
-			operation.Type == libs.OperationTypeBurn ||
-			operation.Type == libs.OperationTypeBurnSynthetic ||
-			operation.Type == libs.OperationTypeWithdraw) {
-		signingIdentity = BridgeContractAddress
-		identityCurve = common.CurveEcdsa
-		logger.Sugar().Infow("Using BridgeContractAddress as signing identity", "address", signingIdentity)
-	}
-
-	go func() {
-		defer func() {
-			// Ensure channel is removed from map if goroutine panics
-			// TODO: Add locking
-			if r := recover(); r != nil {
-				logger.Sugar().Errorw("Panic in generateSignatureMessage goroutine", "error", r)
-				// We might not be able to signal back easily if the channel is gone
-				// Best effort: Log it. The select will time out.
-				delete(messageChan, msg)
-			}
-		}()
-		logger.Sugar().Infow("Calling generateSignatureMessage for gRPC request", "msg", msg, "identity", signingIdentity)
-		generateSignatureMessage(signingIdentity, operation.BlockchainID, identityCurve, keyCurve, msgBytes)
-		// NOTE: We rely on generateSignatureMessage's logic to eventually send the result
-		// back via the messageChan[chanKey].
-	}()
-
-	logger.Sugar().Infow("Waiting for signature result", "msg", msg)
-	select {
-	case sigResult := <-sigChan:
-		// TODO: Add locking
-		delete(messageChan, msg)
-		logger.Sugar().Infow("Received signature result via channel", "address", sigResult.Address, "sigLen", len(sigResult.Message))
+	return msgBytes, nil
+}
 
-		signature := ""
-		switch operation.BlockchainID {
-		case blockchains.Bitcoin, blockchains.Dogecoin, blockchains.Sui:
-			signature = string(sigResult.Message)
-		case blockchains.Aptos, blockchains.Ripple, blockchains.Cardano, blockchains.Stellar:
-			signature = hex.EncodeToString(sigResult.Message)
-		case blockchains.Algorand:
-			signature = base64.StdEncoding.EncodeToString(sigResult.Message)
-			type algodMsg struct {
-				IsRealTransaction bool
-				Msg               string
-			}
-			m := algodMsg{IsRealTransaction: sigResult.AlgorandFlags.IsRealTransaction, Msg: msg}
-			jsonBytes, err := json.Marshal(m)
-			if err != nil {
-				logger.Sugar().Errorw("Error marshaling algodMsg to JSON", "error", err)
-				return nil, status.Errorf(codes.Internal, "error marshaling algodMsg to JSON: %v", err)
-			}
-			v, err := identityVerification.VerifySignature(sigResult.Address, blockchains.Algorand, string(jsonBytes), signature)
-			if !v {
-				logger.Sugar().Errorf("invalid signature %s, err %v", signature, err)
-			}
-		case blockchains.Solana:
-			signature = base58.Encode(sigResult.Message)
-		default:
-			if blockchains.IsEVMBlockchain(operation.BlockchainID) {
-				signature = string(sigResult.Message)
-			} else {
-				logger.Sugar().Errorw("Unexpected blockchain ID in signature response handling", "id", operation.BlockchainID)
-				return nil, status.Error(codes.Internal, "internal error handling signature response")
-			}
+// encodeSignature formats a signature the way the operation's blockchain expects it
+func encodeSignature(blockchainID blockchains.BlockchainID, msg string, sigResult Message) (string, error) {
+	signature := ""
+	switch blockchainID {
+	case blockchains.Bitcoin, blockchains.Dogecoin, blockchains.Sui:
+		signature = string(sigResult.Message)
+	case blockchains.Aptos, blockchains.Ripple, blockchains.Cardano, blockchains.Stellar:
+		signature = hex.EncodeToString(sigResult.Message)
+	case blockchains.Algorand:
+		signature = base64.StdEncoding.EncodeToString(sigResult.Message)
+		type algodMsg struct {
+			IsRealTransaction bool
+			Msg               string
 		}
-
-		if signature == "" {
-			logger.Sugar().Errorw("Empty signature received from async process", "intentID", intent.ID, "opIndex", operationIndex)
-			return nil, status.Error(codes.Internal, "failed to generate signature (empty result)")
+		m := algodMsg{IsRealTransaction: sigResult.AlgorandFlags.IsRealTransaction, Msg: msg}
+		jsonBytes, err := json.Marshal(m)
+		if err != nil {
+			logger.Sugar().Errorw("Error marshaling algodMsg to JSON", "error", err)
+			return "", status.Errorf(codes.Internal, "error marshaling algodMsg to JSON: %v", err)
 		}
+		v, err := identityVerification.VerifySignature(sigResult.Address, blockchains.Algorand, string(jsonBytes), signature)
+		if !v {
+			logger.Sugar().Errorf("invalid signature %s, err %v", signature, err)
+		}
+	case blockchains.Solana:
+		signature = base58.Encode(sigResult.Message)
+	default:
+		if blockchains.IsEVMBlockchain(blockchainID) {
+			signature = string(sigResult.Message)
+		} else {
+			logger.Sugar().Errorw("Unexpected blockchain ID in signature response handling", "id", blockchainID)
+			return "", status.Error(codes.Internal, "internal error handling signature response")
+		}
+	}
 
-		logger.Sugar().Infow("Successfully generated signature via gRPC", "intentID", intent.ID, "opIndex", operationIndex)
-		return &pb.SignIntentOperationResponse{Signature: signature}, nil
-
-	case <-ctx.Done():
-		logger.Sugar().Warnw("gRPC SignIntentOperation request cancelled by client", "msg", msg)
-		// Cleanup handled by defer in select or goroutine.
-		// TODO: Add locking for delete if select wins race against goroutine finishing
-		delete(messageChan, msg)
-		return nil, status.Error(codes.Canceled, "client cancelled request")
-
-	case <-time.After(5 * time.Minute): // Match HTTP timeout? Or configurable?
-		logger.Sugar().Errorw("gRPC SignIntentOperation timed out", "msg", msg)
-		// Cleanup handled by defer in select or goroutine.
-		// TODO: Add locking for delete if select wins race against goroutine finishing
-		delete(messageChan, msg)
-		return nil, status.Error(codes.DeadlineExceeded, "signature operation timed out")
+	if signature == "" {
+		logger.Sugar().Errorw("Empty signature received from async process", "address", sigResult.Address, "blockchain", blockchainID)
+		return "", status.Error(codes.Internal, "failed to generate signature (empty result)")
 	}
+
+	return signature, nil
 }
 
 // AddAddressDetail safely adds an address to the nested map structure
@@ -1279,37 +1358,12 @@ func getUncompressedPublicKeyBytes(key *ecdsaKeygen.LocalPartySaveData) ([]byte,
 	return hex.DecodeString(hexStr)
 }
 
-func startGRPCServer(port string, host host.Host, serverCertPEM, serverKeyPEM, clientCaPEM string) {
+func startGRPCServer(port string, host host.Host, creds *mtls.Credentials, allowedClients []string) {
 	lis, err := net.Listen("tcp", ":"+port)
 	if err != nil {
 		logger.Sugar().Fatalf("Failed to listen for gRPC on port %s: %v", port, err)
 	}
 
-	logger.Sugar().Debug("Configuring gRPC server mTLS...")
-
-	// Load server's certificate and private key
-	// serverCert, err := tls.X509KeyPair([]byte(serverCertPEM), []byte(serverKeyPEM))
-	// if err != nil {
-	// 	logger.Sugar().Fatalf("Failed to load server key pair: %v", err)
-	// }
-
-	// Create a certificate pool for client CAs
-	// clientCAPool := x509.NewCertPool()
-	// if !clientCAPool.AppendCertsFromPEM([]byte(clientCaPEM)) {
-	// 	logger.Sugar().Fatal("Failed to append client CA certificate to pool")
-	// }
-
-	// tlsConfig := &tls.Config{
-	// 	Certificates: []tls.Certificate{serverCert},
-	// 	ClientAuth:   tls.RequireAndVerifyClientCert,
-	// 	ClientCAs:    clientCAPool,
-	// 	MinVersion:   tls.VersionTLS12,
-	// }
-
-	// Create gRPC server credentials
-	// serverCreds := credentials.NewTLS(tlsConfig)
-	// logger.Sugar().Debug("mTLS configuration loaded successfully.")
-
 	healthServer := health.NewServer()
 	// An empty service name "" refers to the status of the overall server.
 	healthServer.SetServingStatus("", grpc_health_v1.HealthCheckResponse_SERVING)
@@ -1318,17 +1372,27 @@ func startGRPCServer(port string, host host.Host, serverCertPEM, serverKeyPEM, c
 		host,
 	)
 
-	// Create gRPC server with mTLS credentials
 	opts := []grpc.ServerOption{
-		// grpc.Creds(serverCreds),
 		grpc.Creds(insecure.NewCredentials()),
 	}
+	if creds != nil {
+		// Any client of the CA may probe the health of the validator, only the allowed
+		// clients, the sequencer, may call it
+		opts = []grpc.ServerOption{
+			grpc.Creds(credentials.NewTLS(creds.ServerConfig())),
+			grpc.UnaryInterceptor(mtls.AuthorizeClients(allowedClients, "/grpc.health.v1.Health/")),
+		}
+	}
 	s := grpc.NewServer(opts...)
 
 	grpc_health_v1.RegisterHealthServer(s, healthServer)
 	pb.RegisterValidatorServiceServer(s, serverImplementation)
 
-	logger.Sugar().Infof("gRPC server with mTLS listening at %s", lis.Addr().String())
+	if creds != nil {
+		logger.Sugar().Infow("gRPC server with mTLS listening", "address", lis.Addr().String(), "certificate", creds.Certificate().Subject.String(), "allowedClients", allowedClients)
+	} else {
+		logger.Sugar().Infof("gRPC server without TLS listening at %s", lis.Addr().String())
+	}
 	if err := s.Serve(lis); err != nil {
 		logger.Sugar().Fatalf("Failed to serve gRPC: %v", err)
 	}
@@ -0,0 +1,995 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/StripChain/strip-node/common"
+	"github.com/StripChain/strip-node/libs/blockchains"
+	"github.com/StripChain/strip-node/libs/keystore"
+	pb "github.com/StripChain/strip-node/libs/proto"
+	ecdsaKeygen "github.com/bnb-chain/tss-lib/v2/ecdsa/keygen"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// The harness runs each validator as a child process of the test binary, since a validator
+// keeps its identity, sessions and key shares in package state. The children are wired
+// to the test instead of libp2p and Postgres: their transport hands every message to the
+// test, which delivers it to the other children in the order it was sent, signers come
+// from a fixed registry and key shares live in a memory store. The store is saved to a
+// file so that a validator killed mid-round restarts with its key shares.
+
+const (
+	harnessNodeEnv    = "STRIP_HARNESS_NODE"
+	harnessKeyEnv     = "STRIP_HARNESS_KEY"
+	harnessSignersEnv = "STRIP_HARNESS_SIGNERS"
+	harnessStoreEnv   = "STRIP_HARNESS_STORE"
+	harnessSlotsEnv   = "STRIP_HARNESS_SLOTS"
+)
+
+const (
+	harnessKeygenTimeout = 10 * time.Minute
+	harnessSignTimeout   = 5 * time.Minute
+	// harnessCrashTimeout bounds the runs expected to stall after a crash
+	harnessCrashTimeout = 20 * time.Second
+)
+
+// harnessFrame is a message between the test and a validator
+type harnessFrame struct {
+	// Kind is publish or send from a validator, deliver to it, command to it and result from it
+	Kind    string          `json:"kind"`
+	To      string          `json:"to,omitempty"`
+	Data    []byte          `json:"data,omitempty"`
+	ID      int             `json:"id,omitempty"`
+	Command *harnessCommand `json:"command,omitempty"`
+	Result  *harnessResult  `json:"result,omitempty"`
+}
+
+type harnessCommand struct {
+	Keygen  *harnessKeygen  `json:"keygen,omitempty"`
+	Reshare *harnessReshare `json:"reshare,omitempty"`
+	Sign    *harnessSign    `json:"sign,omitempty"`
+	// Keys asks for the key curves the validator holds shares of for an identity
+	Keys    string        `json:"keys,omitempty"`
+	Timeout time.Duration `json:"timeout"`
+}
+
+type harnessKeygen struct {
+	Identity     string   `json:"identity"`
+	Ed25519Curve pb.Curve `json:"ed25519Curve"`
+	Signers      []string `json:"signers"`
+	Threshold    int      `json:"threshold"`
+}
+
+type harnessReshare struct {
+	Identity     string   `json:"identity"`
+	OldSigners   []string `json:"oldSigners"`
+	NewSigners   []string `json:"newSigners"`
+	NewThreshold int      `json:"newThreshold"`
+}
+
+type harnessSign struct {
+	Identity       string                   `json:"identity"`
+	BlockchainID   blockchains.BlockchainID `json:"blockchainID"`
+	KeyCurve       common.Curve             `json:"keyCurve"`
+	DerivationPath string                   `json:"derivationPath"`
+	Message        []byte                   `json:"message"`
+}
+
+type harnessResult struct {
+	Error     string         `json:"error,omitempty"`
+	KeyCurve  common.Curve   `json:"keyCurve,omitempty"`
+	Signature *Message       `json:"signature,omitempty"`
+	GroupKey  []byte         `json:"groupKey,omitempty"`
+	KeyCurves []common.Curve `json:"keyCurves,omitempty"`
+	// Committees are the signers of each key share held
+	Committees map[common.Curve][]string `json:"committees,omitempty"`
+}
+
+// memoryKeyValueStore keeps the rows of a validator in memory, saving them to a file
+// after each change when it has a path
+type memoryKeyValueStore struct {
+	mu     sync.Mutex
+	path   string
+	nextID int64
+	rows   []KVStore
+}
+
+func loadMemoryKeyValueStore(path string) (*memoryKeyValueStore, error) {
+	s := &memoryKeyValueStore{path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &s.rows); err != nil {
+		return nil, err
+	}
+	for _, row := range s.rows {
+		if row.Id > s.nextID {
+			s.nextID = row.Id
+		}
+	}
+	return s, nil
+}
+
+func (s *memoryKeyValueStore) save() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := json.Marshal(s.rows)
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+func (s *memoryKeyValueStore) Get(key string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, row := range s.rows {
+		if row.Key == key {
+			return row.Value, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+func (s *memoryKeyValueStore) Replace(deleted []string, rows ...*KVStore) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	kept := s.rows[:0]
+	for _, row := range s.rows {
+		if SliceIndexOfString(deleted, row.Key) < 0 {
+			kept = append(kept, row)
+		}
+	}
+	s.rows = kept
+	for _, row := range rows {
+		s.nextID++
+		s.rows = append(s.rows, KVStore{Id: s.nextID, Key: row.Key, Value: row.Value, CreatedAt: time.Now()})
+	}
+	return s.save()
+}
+
+func (s *memoryKeyValueStore) TakeFirst(prefix string) (*KVStore, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, row := range s.rows {
+		if strings.HasPrefix(row.Key, prefix) {
+			s.rows = append(s.rows[:i], s.rows[i+1:]...)
+			return &row, s.save()
+		}
+	}
+	return nil, nil
+}
+
+func (s *memoryKeyValueStore) Count(prefix string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	count := 0
+	for _, row := range s.rows {
+		if strings.HasPrefix(row.Key, prefix) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// harnessRegistry registers a fixed set of signers
+type harnessRegistry []string
+
+func (r harnessRegistry) IsSigner(publicKey string) (bool, error) {
+	return SliceIndexOfString(r, publicKey) >= 0, nil
+}
+
+// frameWriter writes the frames of one side of a pipe
+type frameWriter struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func (w *frameWriter) write(frame harnessFrame) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.enc.Encode(frame)
+}
+
+// harnessTransport hands the messages of a validator to the test
+type harnessTransport struct {
+	out *frameWriter
+}
+
+func (t harnessTransport) Publish(data []byte) error {
+	return t.out.write(harnessFrame{Kind: "publish", Data: data})
+}
+
+func (t harnessTransport) Send(signer string, data []byte) error {
+	return t.out.write(harnessFrame{Kind: "send", To: signer, Data: data})
+}
+
+// harnessSigningVerifier lets validators join the signings of the harness, which sign test
+// messages rather than intent operations
+type harnessSigningVerifier struct{}
+
+func (harnessSigningVerifier) VerifySigning(msg Message) (signingOrigin, error) {
+	return signingOrigin{}, nil
+}
+
+// TestHarnessNode is the validator process the harness starts, it does nothing otherwise
+func TestHarnessNode(t *testing.T) {
+	index := os.Getenv(harnessNodeEnv)
+	if index == "" {
+		t.Skip("run by the harness as a validator")
+	}
+
+	NodePrivateKey = os.Getenv(harnessKeyEnv)
+	key, err := nodeKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	NodePublicKey = signerPublicKey(&key.PublicKey)
+	signers := strings.Split(os.Getenv(harnessSignersEnv), ",")
+	signerRegistry = harnessRegistry(signers)
+	signingVerifier = harnessSigningVerifier{}
+	MaximumSigners = len(signers)
+
+	// A low work factor, scrypt runs on every key share read
+	wrapper, err := keystore.NewPassphraseWrapper("harness", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	InitialiseKeyShareEncryption(wrapper)
+	store, err := loadMemoryKeyValueStore(os.Getenv(harnessStoreEnv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyValues = store
+	if slots := os.Getenv(harnessSlotsEnv); slots != "" {
+		n, err := strconv.Atoi(slots)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signingSlots = make(chan struct{}, n)
+	}
+
+	i, err := strconv.Atoi(index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := seedPreParams(i, len(signers)); err != nil {
+		t.Fatal(err)
+	}
+
+	out := &frameWriter{enc: json.NewEncoder(os.NewFile(3, "harness-out"))}
+	transport = harnessTransport{out: out}
+
+	dec := json.NewDecoder(os.NewFile(4, "harness-in"))
+	for {
+		var frame harnessFrame
+		if err := dec.Decode(&frame); err != nil {
+			// The test closed the pipe, the validator stops
+			return
+		}
+		switch frame.Kind {
+		case "deliver":
+			go handleIncomingMessage(frame.Data)
+		case "command":
+			go func(frame harnessFrame) {
+				out.write(harnessFrame{Kind: "result", ID: frame.ID, Result: runHarnessCommand(frame.Command)})
+			}(frame)
+		}
+	}
+}
+
+// harnessPreParams is the number of ECDSA keygens and reshares a validator can join
+// without generating pre-params
+const harnessPreParams = 4
+
+// seedPreParams fills the empty pre-params pool of a validator from the tss-lib fixtures,
+// generating pre-params takes minutes. Each keygen of the committee gets distinct ones.
+func seedPreParams(index int, nodes int) error {
+	count, err := CountPreParams()
+	if err != nil || count > 0 {
+		return err
+	}
+	fixtures, _, err := ecdsaKeygen.LoadKeygenTestFixtures(5)
+	if err != nil {
+		return err
+	}
+	for k := 0; k < harnessPreParams; k++ {
+		data, err := json.Marshal(fixtures[(index+k*nodes)%len(fixtures)].LocalPreParams)
+		if err != nil {
+			return err
+		}
+		if err := AddPreParams(string(data)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runHarnessCommand(command *harnessCommand) *harnessResult {
+	ctx, cancel := context.WithTimeout(context.Background(), command.Timeout)
+	defer cancel()
+
+	result := &harnessResult{}
+	var err error
+	switch {
+	case command.Keygen != nil:
+		_, err = (&validatorServer{}).Keygen(ctx, &pb.KeygenRequest{
+			Identity:      command.Keygen.Identity,
+			IdentityCurve: pb.Curve_CURVE_ECDSA,
+			Signers:       command.Keygen.Signers,
+			Threshold:     uint32(command.Keygen.Threshold),
+			Ed25519Curve:  command.Keygen.Ed25519Curve,
+		})
+	case command.Reshare != nil:
+		_, err = (&validatorServer{}).Reshare(ctx, &pb.ReshareRequest{
+			Identity:      command.Reshare.Identity,
+			IdentityCurve: pb.Curve_CURVE_ECDSA,
+			OldSigners:    command.Reshare.OldSigners,
+			NewSigners:    command.Reshare.NewSigners,
+			NewThreshold:  uint32(command.Reshare.NewThreshold),
+		})
+	case command.Sign != nil:
+		result, err = harnessSignature(ctx, command.Sign)
+	case command.Keys != "":
+		result.KeyCurves, result.Committees, err = heldKeyCurves(command.Keys)
+	default:
+		err = errors.New("empty command")
+	}
+	if err != nil {
+		return &harnessResult{Error: err.Error()}
+	}
+	return result
+}
+
+func harnessSignature(ctx context.Context, sign *harnessSign) (*harnessResult, error) {
+	keyCurve, err := walletKeyCurve(sign.Identity, common.CurveEcdsa, sign.KeyCurve)
+	if err != nil {
+		return nil, err
+	}
+	sessionID := generateDerivedSignatureMessage(newSessionID(), signingOrigin{}, sign.Identity, sign.DerivationPath, sign.BlockchainID, common.CurveEcdsa, keyCurve, sign.Message)
+	signature, _, err := waitSignature(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	groupKey, err := signingGroupKey(sign.Identity, common.CurveEcdsa, keyCurve, sign.DerivationPath)
+	if err != nil {
+		return nil, err
+	}
+	return &harnessResult{KeyCurve: keyCurve, Signature: &signature, GroupKey: groupKey}, nil
+}
+
+func heldKeyCurves(identity string) ([]common.Curve, map[common.Curve][]string, error) {
+	held := []common.Curve{}
+	committees := map[common.Curve][]string{}
+	for _, keyCurve := range []common.Curve{common.CurveEcdsa, common.CurveEddsa, common.CurveFrost} {
+		share, err := GetKeyShare(identity, common.CurveEcdsa, keyCurve)
+		if err != nil {
+			return nil, nil, err
+		}
+		if share == "" {
+			continue
+		}
+		held = append(held, keyCurve)
+		signers, err := GetSignersForKeyShare(identity, common.CurveEcdsa, keyCurve)
+		if err != nil {
+			return nil, nil, err
+		}
+		committee := []string{}
+		if err := json.Unmarshal([]byte(signers), &committee); err != nil {
+			return nil, nil, err
+		}
+		committees[keyCurve] = committee
+	}
+	return held, committees, nil
+}
+
+// harness is a committee of validator processes
+type harness struct {
+	t     *testing.T
+	dir   string
+	mu    sync.Mutex
+	nodes []*harnessNode
+	// crash is asked about every message a validator sends, returning true kills the
+	// validator before the message reaches anyone
+	crash  func(node int, msg Message) bool
+	nextID int
+}
+
+type harnessNode struct {
+	index     int
+	key       string
+	publicKey string
+	cmd       *exec.Cmd
+	in        *os.File
+	out       *frameWriter
+	alive     bool
+	results   map[int]chan *harnessResult
+	exited    chan struct{}
+}
+
+func newHarness(t *testing.T, n int) *harness {
+	if testing.Short() {
+		t.Skip("starts validator processes")
+	}
+
+	h := &harness{t: t, dir: t.TempDir()}
+	for i := 0; i < n; i++ {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		h.nodes = append(h.nodes, &harnessNode{
+			index:     i,
+			key:       hex.EncodeToString(crypto.FromECDSA(key)),
+			publicKey: signerPublicKey(&key.PublicKey),
+		})
+	}
+	t.Cleanup(h.stop)
+	for i := range h.nodes {
+		h.start(i)
+	}
+	return h
+}
+
+func (h *harness) signers() []string {
+	signers := make([]string, len(h.nodes))
+	for i, node := range h.nodes {
+		signers[i] = node.publicKey
+	}
+	return signers
+}
+
+func (h *harness) logPath(i int) string {
+	return filepath.Join(h.dir, fmt.Sprintf("node-%d.log", i))
+}
+
+// start runs the validator i, with the key shares it had when it was stopped
+func (h *harness) start(i int) {
+	h.t.Helper()
+	node := h.nodes[i]
+
+	inR, inW, err := os.Pipe()
+	if err != nil {
+		h.t.Fatal(err)
+	}
+	outR, outW, err := os.Pipe()
+	if err != nil {
+		h.t.Fatal(err)
+	}
+	logs, err := os.OpenFile(h.logPath(i), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		h.t.Fatal(err)
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestHarnessNode$", "-test.count=1")
+	cmd.Env = append(os.Environ(),
+		harnessNodeEnv+"="+strconv.Itoa(i),
+		harnessKeyEnv+"="+node.key,
+		harnessSignersEnv+"="+strings.Join(h.signers(), ","),
+		harnessStoreEnv+"="+filepath.Join(h.dir, fmt.Sprintf("node-%d.json", i)),
+	)
+	// The child writes frames to fd 3 and reads them from fd 4
+	cmd.ExtraFiles = []*os.File{outW, inR}
+	cmd.Stdout = logs
+	cmd.Stderr = logs
+	if err := cmd.Start(); err != nil {
+		h.t.Fatal(err)
+	}
+	outW.Close()
+	inR.Close()
+	logs.Close()
+
+	h.mu.Lock()
+	node.cmd = cmd
+	node.in = inW
+	node.out = &frameWriter{enc: json.NewEncoder(inW)}
+	node.alive = true
+	node.results = map[int]chan *harnessResult{}
+	node.exited = make(chan struct{})
+	h.mu.Unlock()
+
+	go h.route(node, outR)
+	go func(exited chan struct{}) {
+		cmd.Wait()
+		close(exited)
+	}(node.exited)
+}
+
+// route delivers the messages of a validator and hands back its command results
+func (h *harness) route(node *harnessNode, r *os.File) {
+	defer r.Close()
+	dec := json.NewDecoder(r)
+	for {
+		var frame harnessFrame
+		if err := dec.Decode(&frame); err != nil {
+			h.kill(node)
+			return
+		}
+
+		switch frame.Kind {
+		case "publish", "send":
+			if h.crashes(node, frame.Data) {
+				h.kill(node)
+				return
+			}
+			h.deliver(frame)
+		case "result":
+			h.mu.Lock()
+			result, ok := node.results[frame.ID]
+			delete(node.results, frame.ID)
+			h.mu.Unlock()
+			if ok {
+				result <- frame.Result
+			}
+		}
+	}
+}
+
+func (h *harness) crashes(node *harnessNode, data []byte) bool {
+	h.mu.Lock()
+	crash := h.crash
+	h.mu.Unlock()
+	if crash == nil {
+		return false
+	}
+	var msg Message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return false
+	}
+	return crash(node.index, msg)
+}
+
+func (h *harness) deliver(frame harnessFrame) {
+	h.mu.Lock()
+	recipients := []*harnessNode{}
+	for _, node := range h.nodes {
+		if node.alive && (frame.Kind == "publish" || node.publicKey == frame.To) {
+			recipients = append(recipients, node)
+		}
+	}
+	h.mu.Unlock()
+
+	for _, node := range recipients {
+		// A validator that died since is simply not reached
+		node.out.write(harnessFrame{Kind: "deliver", Data: frame.Data})
+	}
+}
+
+// kill stops a validator at once, like a crash
+func (h *harness) kill(node *harnessNode) {
+	h.mu.Lock()
+	if !node.alive {
+		h.mu.Unlock()
+		return
+	}
+	node.alive = false
+	for id, result := range node.results {
+		close(result)
+		delete(node.results, id)
+	}
+	h.mu.Unlock()
+
+	node.cmd.Process.Kill()
+	node.in.Close()
+	<-node.exited
+}
+
+func (h *harness) stop() {
+	for _, node := range h.nodes {
+		if node.cmd != nil {
+			h.kill(node)
+		}
+	}
+	if h.t.Failed() {
+		for i := range h.nodes {
+			logs, _ := os.ReadFile(h.logPath(i))
+			h.t.Logf("validator %d:\n%s", i, logs)
+		}
+	}
+}
+
+// crashOn kills validator i when it sends its first message of the given type for a key
+// of keyCurve
+func (h *harness) crashOn(i int, messageType MessageType, keyCurve common.Curve) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.crash = func(node int, msg Message) bool {
+		return node == i && msg.Type == messageType && msg.KeyCurve == keyCurve
+	}
+}
+
+func (h *harness) alive(i int) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.nodes[i].alive
+}
+
+func (h *harness) clearCrash() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.crash = nil
+}
+
+// call runs a command on validator i and waits for its result
+func (h *harness) call(i int, command harnessCommand) (*harnessResult, error) {
+	node := h.nodes[i]
+	h.mu.Lock()
+	if !node.alive {
+		h.mu.Unlock()
+		return nil, fmt.Errorf("validator %d is down", i)
+	}
+	h.nextID++
+	id := h.nextID
+	result := make(chan *harnessResult, 1)
+	node.results[id] = result
+	h.mu.Unlock()
+
+	if err := node.out.write(harnessFrame{Kind: "command", ID: id, Command: &command}); err != nil {
+		return nil, err
+	}
+	select {
+	case r, ok := <-result:
+		if !ok {
+			return nil, fmt.Errorf("validator %d crashed", i)
+		}
+		if r.Error != "" {
+			return nil, errors.New(r.Error)
+		}
+		return r, nil
+	case <-time.After(command.Timeout + time.Minute):
+		return nil, fmt.Errorf("validator %d did not answer", i)
+	}
+}
+
+// keygen creates a wallet for the committee from validator i and waits until every
+// validator holds its key shares. A threshold of 0 is the default of the committee.
+func (h *harness) keygen(i int, identity string, ed25519Curve pb.Curve, threshold int, timeout time.Duration) error {
+	_, err := h.call(i, harnessCommand{
+		Keygen:  &harnessKeygen{Identity: identity, Ed25519Curve: ed25519Curve, Signers: h.signers(), Threshold: threshold},
+		Timeout: timeout,
+	})
+	if err != nil {
+		return err
+	}
+
+	want, err := keygenCurves(ed25519Curve)
+	if err != nil {
+		return err
+	}
+	nodes := make([]int, len(h.nodes))
+	for j := range nodes {
+		nodes[j] = j
+	}
+	return h.waitForKeys(nodes, identity, want)
+}
+
+// reshare moves a wallet to the committee of the validators nodes from validator i and
+// waits until each of them holds its new key shares
+func (h *harness) reshare(i int, identity string, nodes []int, threshold int, timeout time.Duration) error {
+	newSigners := make([]string, len(nodes))
+	for j, node := range nodes {
+		newSigners[j] = h.nodes[node].publicKey
+	}
+	result, err := h.call(i, harnessCommand{Keys: identity, Timeout: time.Second})
+	if err != nil {
+		return err
+	}
+	_, err = h.call(i, harnessCommand{
+		Reshare: &harnessReshare{Identity: identity, OldSigners: h.signers(), NewSigners: newSigners, NewThreshold: threshold},
+		Timeout: timeout,
+	})
+	if err != nil {
+		return err
+	}
+	return h.waitForKeys(nodes, identity, result.KeyCurves)
+}
+
+// waitForKeys waits until the validators nodes hold key shares of the wallet for exactly
+// the key curves want, all of them for the committee of those validators
+func (h *harness) waitForKeys(nodes []int, identity string, want []common.Curve) error {
+	want = append([]common.Curve{}, want...)
+	sort.Slice(want, func(a, b int) bool { return want[a] < want[b] })
+	signers := make([]string, len(nodes))
+	for j, node := range nodes {
+		signers[j] = h.nodes[node].publicKey
+	}
+
+	deadline := time.Now().Add(time.Minute)
+	for _, j := range nodes {
+		for {
+			result, err := h.call(j, harnessCommand{Keys: identity, Timeout: time.Second})
+			if err != nil {
+				return err
+			}
+			held := result.KeyCurves
+			sort.Slice(held, func(a, b int) bool { return held[a] < held[b] })
+			done := fmt.Sprint(held) == fmt.Sprint(want)
+			for _, committee := range result.Committees {
+				done = done && equalSigners(committee, signers)
+			}
+			if done {
+				break
+			}
+			if time.Now().After(deadline) {
+				return fmt.Errorf("validator %d holds key shares %v of committees %v, want %v of %v", j, held, result.Committees, want, signers)
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+	return nil
+}
+
+func (h *harness) sign(i int, sign harnessSign, timeout time.Duration) (*harnessResult, error) {
+	return h.call(i, harnessCommand{Sign: &sign, Timeout: timeout})
+}
+
+// harnessMessage returns a message to sign for a chain: the hex of a digest for ECDSA
+// chains, bytes without leading zeros for Ed25519 chains
+func harnessMessage(keyCurve common.Curve, text string) []byte {
+	if keyCurve == common.CurveEcdsa {
+		return []byte(hex.EncodeToString(crypto.Keccak256([]byte(text))))
+	}
+	return []byte(text)
+}
+
+// checkSignature checks that a signature verifies under the group key of the signing and
+// is encoded for its chain
+func checkSignature(t *testing.T, result *harnessResult, chain blockchains.BlockchainID) {
+	t.Helper()
+	signature := result.Signature
+	if signature.BlockchainID != chain {
+		t.Errorf("signature for %s, want %s", signature.BlockchainID, chain)
+	}
+	if len(signature.Message) == 0 || signature.Address == "" {
+		t.Errorf("signature for %s has no encoded signature or address", chain)
+	}
+	if err := verifyRawSignature(result.KeyCurve, result.GroupKey, signature.Hash, signature.RawSignature); err != nil {
+		t.Errorf("signature for %s does not verify: %v", chain, err)
+	}
+}
+
+var harnessChains = []struct {
+	chain    blockchains.BlockchainID
+	keyCurve common.Curve
+}{
+	{blockchains.Solana, common.CurveEddsa},
+	{blockchains.Sui, common.CurveEddsa},
+	{blockchains.Aptos, common.CurveEddsa},
+	{blockchains.Stellar, common.CurveEddsa},
+	{blockchains.Algorand, common.CurveEddsa},
+	{blockchains.Ripple, common.CurveEddsa},
+	{blockchains.Cardano, common.CurveEddsa},
+	{blockchains.Ethereum, common.CurveEcdsa},
+	{blockchains.Bitcoin, common.CurveEcdsa},
+	{blockchains.Dogecoin, common.CurveEcdsa},
+}
+
+func TestHarnessKeygenAndSigning(t *testing.T) {
+	h := newHarness(t, 3)
+
+	for _, tc := range []struct {
+		name         string
+		ed25519Curve pb.Curve
+		ed25519Key   common.Curve
+	}{
+		{"eddsa", pb.Curve_CURVE_EDDSA, common.CurveEddsa},
+		{"frost", pb.Curve_CURVE_FROST, common.CurveFrost},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			identity := "0xharness-" + tc.name
+			if err := h.keygen(0, identity, tc.ed25519Curve, 0, harnessKeygenTimeout); err != nil {
+				t.Fatalf("keygen: %v", err)
+			}
+
+			for i, c := range harnessChains {
+				// Every validator starts signings, of the wallet and of a child key
+				derivationPath := ""
+				if i%2 == 1 {
+					derivationPath = "m/0/" + strconv.Itoa(i)
+				}
+				result, err := h.sign(i%len(h.nodes), harnessSign{
+					Identity:       identity,
+					BlockchainID:   c.chain,
+					KeyCurve:       c.keyCurve,
+					DerivationPath: derivationPath,
+					Message:        harnessMessage(c.keyCurve, "harness transaction on "+string(c.chain)),
+				}, harnessSignTimeout)
+				if err != nil {
+					t.Fatalf("signing for %s: %v", c.chain, err)
+				}
+
+				want := common.CurveEcdsa
+				if c.keyCurve == common.CurveEddsa {
+					want = tc.ed25519Key
+				}
+				if result.KeyCurve != want {
+					t.Errorf("%s signed with %s, want %s", c.chain, result.KeyCurve, want)
+				}
+				checkSignature(t, result, c.chain)
+			}
+		})
+	}
+}
+
+func TestHarnessCrashMidRound(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		ed25519Curve pb.Curve
+		// keyCurve is the key whose protocols crash
+		keyCurve common.Curve
+		chain    blockchains.BlockchainID
+	}{
+		{"ecdsa", pb.Curve_CURVE_EDDSA, common.CurveEcdsa, blockchains.Ethereum},
+		{"eddsa", pb.Curve_CURVE_EDDSA, common.CurveEddsa, blockchains.Solana},
+		{"frost", pb.Curve_CURVE_FROST, common.CurveFrost, blockchains.Solana},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// A committee of its own, so that the pre-params seeded for its keygens last
+			h := newHarness(t, 3)
+
+			// A validator dying during keygen stalls it until the caller gives up
+			h.crashOn(2, MESSAGE_TYPE_GENERATE_KEYGEN, tc.keyCurve)
+			if err := h.keygen(0, "0xharness-crashed-"+tc.name, tc.ed25519Curve, 0, harnessCrashTimeout); err == nil {
+				t.Fatal("keygen should fail when a signer crashes")
+			}
+			if h.alive(2) {
+				t.Fatal("validator 2 should have crashed")
+			}
+			h.clearCrash()
+			h.start(2)
+
+			identity := "0xharness-crash-" + tc.name
+			if err := h.keygen(0, identity, tc.ed25519Curve, 0, harnessKeygenTimeout); err != nil {
+				t.Fatalf("keygen after a restart: %v", err)
+			}
+
+			chainCurve := common.CurveEcdsa
+			if tc.keyCurve != common.CurveEcdsa {
+				chainCurve = common.CurveEddsa
+			}
+			sign := harnessSign{
+				Identity:     identity,
+				BlockchainID: tc.chain,
+				KeyCurve:     chainCurve,
+				Message:      harnessMessage(chainCurve, "harness transaction on "+string(tc.chain)),
+			}
+
+			h.crashOn(1, MESSAGE_TYPE_SIGN, tc.keyCurve)
+			if _, err := h.sign(0, sign, harnessCrashTimeout); err == nil {
+				t.Fatal("signing should fail when a signer crashes")
+			}
+			if h.alive(1) {
+				t.Fatal("validator 1 should have crashed signing")
+			}
+			h.clearCrash()
+
+			// The restarted validator signs with the key shares it had
+			h.start(1)
+			result, err := h.sign(1, sign, harnessSignTimeout)
+			if err != nil {
+				t.Fatalf("signing after a restart: %v", err)
+			}
+			if result.KeyCurve != tc.keyCurve {
+				t.Errorf("signed with %s, want %s", result.KeyCurve, tc.keyCurve)
+			}
+			checkSignature(t, result, tc.chain)
+		})
+	}
+}
+
+func TestHarnessReshare(t *testing.T) {
+	h := newHarness(t, 3)
+
+	for _, tc := range []struct {
+		name         string
+		ed25519Curve pb.Curve
+		ed25519Key   common.Curve
+	}{
+		{"eddsa", pb.Curve_CURVE_EDDSA, common.CurveEddsa},
+		{"frost", pb.Curve_CURVE_FROST, common.CurveFrost},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			identity := "0xharness-reshare-" + tc.name
+			if err := h.keygen(0, identity, tc.ed25519Curve, 1, harnessKeygenTimeout); err != nil {
+				t.Fatalf("keygen: %v", err)
+			}
+
+			// Validator 2 leaves, validators 0 and 1 meet the threshold and reshare alone
+			if err := h.reshare(0, identity, []int{0, 1}, 1, harnessKeygenTimeout); err != nil {
+				t.Fatalf("reshare: %v", err)
+			}
+
+			// The new committee signs without the validator that left, a FROST key with FROST
+			if h.alive(2) {
+				h.kill(h.nodes[2])
+			}
+			defer h.start(2)
+			for i, c := range []struct {
+				chain    blockchains.BlockchainID
+				keyCurve common.Curve
+				want     common.Curve
+			}{
+				{blockchains.Solana, common.CurveEddsa, tc.ed25519Key},
+				{blockchains.Ethereum, common.CurveEcdsa, common.CurveEcdsa},
+			} {
+				result, err := h.sign(i, harnessSign{
+					Identity:     identity,
+					BlockchainID: c.chain,
+					KeyCurve:     c.keyCurve,
+					Message:      harnessMessage(c.keyCurve, "reshared transaction on "+string(c.chain)),
+				}, harnessSignTimeout)
+				if err != nil {
+					t.Fatalf("signing for %s after resharing: %v", c.chain, err)
+				}
+				if result.KeyCurve != c.want {
+					t.Errorf("%s signed with %s, want %s", c.chain, result.KeyCurve, c.want)
+				}
+				checkSignature(t, result, c.chain)
+			}
+		})
+	}
+}
+
+func TestHarnessSigningSlots(t *testing.T) {
+	// One slot per validator, so that concurrent signings started on different validators
+	// take each other's slots
+	t.Setenv(harnessSlotsEnv, "1")
+	h := newHarness(t, 3)
+
+	identity := "0xharness-slots"
+	if err := h.keygen(0, identity, pb.Curve_CURVE_EDDSA, 0, harnessKeygenTimeout); err != nil {
+		t.Fatalf("keygen: %v", err)
+	}
+
+	const signings = 6
+	errs := make([]error, signings)
+	var wg sync.WaitGroup
+	for i := 0; i < signings; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c := harnessChains[i%2*7]
+			result, err := h.sign(i%len(h.nodes), harnessSign{
+				Identity:     identity,
+				BlockchainID: c.chain,
+				KeyCurve:     c.keyCurve,
+				Message:      harnessMessage(c.keyCurve, "concurrent transaction "+strconv.Itoa(i)),
+			}, harnessSignTimeout)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			checkSignature(t, result, c.chain)
+		}(i)
+	}
+	wg.Wait()
+
+	// Every signing finishes, rejected ones are retried instead of waiting on each other
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("signing %d: %v", i, err)
+		}
+	}
+}
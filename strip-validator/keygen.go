@@ -2,100 +2,82 @@ package main
 
 import (
 	"encoding/json"
-	"time"
+	"fmt"
 
 	"github.com/StripChain/strip-node/common"
+	"github.com/StripChain/strip-node/libs"
 	"github.com/StripChain/strip-node/util/logger"
 	ecdsaKeygen "github.com/bnb-chain/tss-lib/v2/ecdsa/keygen"
 	eddsaKeygen "github.com/bnb-chain/tss-lib/v2/eddsa/keygen"
 	"github.com/bnb-chain/tss-lib/v2/tss"
 )
 
-func updateKeygen(identity string, identityCurve common.Curve, keyCurve common.Curve, from int, bz []byte, isBroadcast bool, to int, signers []string) {
-	TotalSigners := len(signers)
-	parties, _ := getParties(TotalSigners)
-
-	key := identity + "_" + string(identityCurve) + "_" + string(keyCurve)
-
-	//wait for 1 minute for party to be ready
-	for i := 0; i < 6; i++ {
-		if !partyProcesses[key].Exists {
-			time.Sleep(10 * time.Second)
-		} else {
-			break
-		}
-	}
+// keygenSessionID returns the session of the keygen of one curve started as id
+func keygenSessionID(id string, keyCurve common.Curve) string {
+	return id + "_" + string(keyCurve)
+}
 
-	if !partyProcesses[key].Exists {
+func updateKeygen(msg Message) {
+	if !SliceContainsString(msg.Signers, NodePublicKey) {
 		return
 	}
 
-	party := *partyProcesses[key].Party
-
-	if to != -1 && to != party.PartyID().Index {
-		return
-	}
+	sessions.Deliver(msg)
+}
 
-	if party.PartyID().Index == from {
+func generateKeygen(sessionID string, identity string, identityCurve common.Curve, keyCurve common.Curve, signers []string, threshold int) {
+	if SliceIndexOfString(signers, NodePublicKey) == -1 {
+		logger.Sugar().Errorw("signer is not in consortium for keygen generation")
 		return
 	}
 
-	pMsg, err := tss.ParseWireMessage(bz, parties[from], isBroadcast)
-	if err != nil {
-		panic(err)
-	}
-
-	ok, err1 := party.Update(pMsg)
-	if err1 != nil {
-		panic(err1)
+	if err := runKeygen(sessionID, identity, identityCurve, keyCurve, signers, threshold); err != nil {
+		logger.Sugar().Errorw("keygen failed", "session", sessionID, "error", err)
+		sessions.Fail(sessionID, err)
 	}
-
-	logger.Sugar().Infof("processed keygen message with status: %v", ok)
 }
 
-func generateKeygen(identity string, identityCurve common.Curve, keyCurve common.Curve, signers []string) {
+func runKeygen(sessionID string, identity string, identityCurve common.Curve, keyCurve common.Curve, signers []string, threshold int) error {
 	Index := SliceIndexOfString(signers, NodePublicKey)
 
-	if Index == -1 {
-		logger.Sugar().Errorw("signer is not in consortium for keygen generation")
-		return
-	}
-
 	TotalSigners := len(signers)
+	threshold = libs.ResolveThreshold(threshold, TotalSigners)
 
-	if TotalSigners > MaximumSigners {
-		logger.Sugar().Errorw("too many signers for keygen generation")
-		return
-	}
-
-	if TotalSigners == 0 {
-		logger.Sugar().Errorw("not enough signers for keygen generation")
-		return
+	if err := libs.ValidateThresholdPolicy(threshold, TotalSigners, MaximumSigners); err != nil {
+		return fmt.Errorf("invalid threshold policy: %w", err)
 	}
 
 	keyShare, err := GetKeyShare(identity, identityCurve, keyCurve)
 	if err != nil {
-		logger.Sugar().Errorw("error from postgres", "error", err)
-		return
-	}
-
-	if keyShare == "" {
-		logger.Sugar().Infof("key share not found. continuing to generate key share")
+		return fmt.Errorf("failed to read key share: %w", err)
 	}
 
 	if keyShare != "" {
 		logger.Sugar().Infof("key share found. stopping to generate key share")
-		return
+		sessions.Complete(sessionID, Message{})
+		return nil
 	}
 
-	key := identity + "_" + string(identityCurve) + "_" + string(keyCurve)
+	logger.Sugar().Infof("key share not found. continuing to generate key share")
+
+	if keyCurve == common.CurveFrost {
+		save, err := runFrostKeygen(sessionID, identity, identityCurve, signers, threshold)
+		if err != nil {
+			return err
+		}
+		if err := saveKeygen(sessionID, identity, identityCurve, keyCurve, save, signers, threshold); err != nil {
+			return err
+		}
+		addFrostNonceWallet(keyShareKey(identity, identityCurve, keyCurve))
+		return nil
+	}
 
-	delete(partyProcesses, key)
 	parties, partiesIds := getParties(TotalSigners)
 
 	ctx := tss.NewPeerContext(parties)
 
 	outChanKeygen := make(chan tss.Message)
+	errChan := make(chan *tss.Error, 1)
 
 	// EdDSA channels (for EdDSA-based curves)
 	saveChanEddsa := make(chan *eddsaKeygen.LocalPartySaveData)
@@ -103,25 +85,37 @@ func generateKeygen(identity string, identityCurve common.Curve, keyCurve common
 	// ECDSA channels (for ECDSA-based curves)
 	saveChanEcdsa := make(chan *ecdsaKeygen.LocalPartySaveData)
 
+	var localParty tss.Party
 	switch keyCurve {
 	case common.CurveEddsa:
-		params := tss.NewParameters(tss.Edwards(), ctx, partiesIds[Index], len(parties), int(CalculateThreshold(TotalSigners)))
-		localParty := eddsaKeygen.NewLocalParty(params, outChanKeygen, saveChanEddsa)
-		partyProcesses[key] = PartyProcess{&localParty, true}
-		go localParty.Start()
+		params := tss.NewParameters(tss.Edwards(), ctx, partiesIds[Index], len(parties), threshold)
+		localParty = eddsaKeygen.NewLocalParty(params, outChanKeygen, saveChanEddsa)
 	case common.CurveEcdsa:
-		params := tss.NewParameters(tss.S256(), ctx, partiesIds[Index], len(parties), int(CalculateThreshold(TotalSigners)))
-		preParams, err := ecdsaKeygen.GeneratePreParams(2 * time.Minute)
+		params := tss.NewParameters(tss.S256(), ctx, partiesIds[Index], len(parties), threshold)
+		preParams, err := takePreParams()
 		if err != nil {
-			panic(err)
+			return fmt.Errorf("failed to generate pre-params: %w", err)
 		}
-		localParty := ecdsaKeygen.NewLocalParty(params, outChanKeygen, saveChanEcdsa, *preParams)
-		partyProcesses[key] = PartyProcess{&localParty, true}
-		go localParty.Start()
+		localParty = ecdsaKeygen.NewLocalParty(params, outChanKeygen, saveChanEcdsa, *preParams)
+	default:
+		return fmt.Errorf("invalid key curve: %s", keyCurve)
+	}
+
+	members := committee{sessionID: sessionID, ids: partiesIds, signers: signers}
+	sessions.Describe(sessionID, SessionInfo{Type: SessionTypeKeygen, Identity: identity, KeyCurve: keyCurve, Participants: signers}, localParty)
+	session, err := sessions.Start(sessionID, partyHandler(localParty, parties, members))
+	if err != nil {
+		return err
 	}
 
-	completed := false
-	for !completed {
+	go func() {
+		if err := localParty.Start(); err != nil {
+			errChan <- err
+		}
+	}()
+
+	var save interface{}
+	for save == nil {
 		select {
 		case msg := <-outChanKeygen:
 			dest := msg.GetTo()
@@ -134,6 +128,7 @@ func generateKeygen(identity string, identityCurve common.Curve, keyCurve common
 			}
 
 			message := Message{
+				SessionID:     sessionID,
 				Type:          MESSAGE_TYPE_GENERATE_KEYGEN,
 				From:          msg.GetFrom().Index,
 				To:            to,
@@ -145,60 +140,56 @@ func generateKeygen(identity string, identityCurve common.Curve, keyCurve common
 				Signers:       signers,
 			}
 
-			go broadcast(message)
-
-		case save := <-saveChanEddsa:
-			logger.Sugar().Infof("saving key")
-
-			out, err := json.Marshal(save)
-			if err != nil {
-				logger.Sugar().Errorw("error marshalling save", "error", err)
-			}
-
-			_json := string(out)
-			AddKeyShare(identity, identityCurve, keyCurve, _json)
-
-			signersOut, err := json.Marshal(signers)
-			if err != nil {
-				logger.Sugar().Errorw("error marshalling signers", "error", err)
+			if dest != nil {
+				if err := sealMessage(&message, members.signerOf(dest[0])); err != nil {
+					return fmt.Errorf("failed to encrypt message to party %d: %w", to, err)
+				}
 			}
 
-			AddSignersForKeyShare(identity, identityCurve, keyCurve, string(signersOut))
-
-			completed = true
-			delete(partyProcesses, key)
+			go broadcast(message)
 
-			if val, ok := keygenGeneratedChan[key]; ok {
-				val <- "generated keygen"
-			}
+		case data := <-saveChanEddsa:
+			save = data
+		case data := <-saveChanEcdsa:
+			save = data
+		case err := <-errChan:
+			return members.blame(err)
+		case <-session.Done():
+			return sessionError(sessionID)
+		}
+	}
 
-			logger.Sugar().Infof("completed saving of new keygen")
+	return saveKeygen(sessionID, identity, identityCurve, keyCurve, save, signers, threshold)
+}
 
-		case save := <-saveChanEcdsa:
-			logger.Sugar().Infof("saving key")
-			out, err := json.Marshal(save)
-			if err != nil {
-				logger.Sugar().Errorw("error marshalling save", "error", err)
-			}
+// saveKeygen stores the key share a keygen produced and completes its session
+func saveKeygen(sessionID string, identity string, identityCurve common.Curve, keyCurve common.Curve, save interface{}, signers []string, threshold int) error {
+	logger.Sugar().Infof("saving key")
 
-			_json := string(out)
-			AddKeyShare(identity, identityCurve, keyCurve, _json)
+	out, err := json.Marshal(save)
+	if err != nil {
+		return fmt.Errorf("failed to marshal key share: %w", err)
+	}
 
-			signersOut, err := json.Marshal(signers)
-			if err != nil {
-				logger.Sugar().Errorw("error marshalling signers", "error", err)
-			}
+	if err := AddKeyShare(identity, identityCurve, keyCurve, string(out)); err != nil {
+		return fmt.Errorf("failed to save key share: %w", err)
+	}
 
-			AddSignersForKeyShare(identity, identityCurve, keyCurve, string(signersOut))
+	signersOut, err := json.Marshal(signers)
+	if err != nil {
+		return fmt.Errorf("failed to marshal signers: %w", err)
+	}
 
-			completed = true
-			delete(partyProcesses, key)
+	if err := AddSignersForKeyShare(identity, identityCurve, keyCurve, string(signersOut)); err != nil {
+		return fmt.Errorf("failed to save signers: %w", err)
+	}
+	if err := AddThresholdForKeyShare(identity, identityCurve, keyCurve, threshold); err != nil {
+		return fmt.Errorf("failed to save threshold: %w", err)
+	}
+	backupKeyShareIncrement(identity, identityCurve, keyCurve)
 
-			if val, ok := keygenGeneratedChan[key]; ok {
-				val <- "generated keygen"
-			}
+	sessions.Complete(sessionID, Message{})
 
-			logger.Sugar().Infof("completed saving of new keygen")
-		}
-	}
+	logger.Sugar().Infof("completed saving of new keygen")
+	return nil
 }
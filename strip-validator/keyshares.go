@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"flag"
+
+	"github.com/StripChain/strip-node/libs/keystore"
+	"github.com/StripChain/strip-node/util"
+)
+
+// keyShareFlags holds the flags configuring a key-encryption key backend for key shares
+type keyShareFlags struct {
+	backend         *string
+	ageIdentityFile *string
+	passphrase      *string
+	kmsKeyID        *string
+	vaultAddress    *string
+	vaultToken      *string
+	vaultMount      *string
+	vaultKey        *string
+}
+
+// registerKeyShareFlags registers the backend flags under a name and environment prefix,
+// so the current and the new key-encryption key can be configured side by side for rotation
+func registerKeyShareFlags(namePrefix string, envPrefix string, usage string) keyShareFlags {
+	return keyShareFlags{
+		backend:         flag.String(namePrefix+"Backend", util.LookupEnvOrString(envPrefix+"BACKEND", ""), usage+" backend: age, passphrase, awskms or vault"),
+		ageIdentityFile: flag.String(namePrefix+"AgeIdentity", util.LookupEnvOrString(envPrefix+"AGE_IDENTITY", ""), usage+" age identity file"),
+		passphrase:      flag.String(namePrefix+"Passphrase", util.LookupEnvOrString(envPrefix+"PASSPHRASE", ""), usage+" passphrase, or the passphrase of the age identity file"),
+		kmsKeyID:        flag.String(namePrefix+"KMSKeyID", util.LookupEnvOrString(envPrefix+"KMS_KEY_ID", ""), usage+" AWS KMS key id, ARN or alias"),
+		vaultAddress:    flag.String(namePrefix+"VaultAddress", util.LookupEnvOrString(envPrefix+"VAULT_ADDR", ""), usage+" Vault address"),
+		vaultToken:      flag.String(namePrefix+"VaultToken", util.LookupEnvOrString(envPrefix+"VAULT_TOKEN", ""), usage+" Vault token"),
+		vaultMount:      flag.String(namePrefix+"VaultMount", util.LookupEnvOrString(envPrefix+"VAULT_MOUNT", "transit"), usage+" Vault transit mount"),
+		vaultKey:        flag.String(namePrefix+"VaultKey", util.LookupEnvOrString(envPrefix+"VAULT_KEY", ""), usage+" Vault transit key name"),
+	}
+}
+
+func (f keyShareFlags) wrapper(ctx context.Context) (keystore.IKeyWrapper, error) {
+	return keystore.NewKeyWrapper(ctx, keystore.Config{
+		Backend:         *f.backend,
+		AgeIdentityFile: *f.ageIdentityFile,
+		Passphrase:      *f.passphrase,
+		KMSKeyID:        *f.kmsKeyID,
+		VaultAddress:    *f.vaultAddress,
+		VaultToken:      *f.vaultToken,
+		VaultMount:      *f.vaultMount,
+		VaultKey:        *f.vaultKey,
+	})
+}
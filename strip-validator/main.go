@@ -1,47 +1,54 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"log"
+	"os"
+	"strings"
+	"time"
 
 	intentoperatorsregistry "github.com/StripChain/strip-node/intentOperatorsRegistry"
 	"github.com/StripChain/strip-node/libs/blockchains"
+	"github.com/StripChain/strip-node/libs/keystore"
+	"github.com/StripChain/strip-node/libs/mtls"
 	"github.com/StripChain/strip-node/util"
 	"github.com/StripChain/strip-node/util/logger"
-	"github.com/bnb-chain/tss-lib/v2/tss"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/multiformats/go-multiaddr"
 )
 
 var RPC_URL, IntentOperatorsRegistryContractAddress, SolversRegistryContractAddress, BridgeContractAddress, NodePrivateKey, NodePublicKey string
 var MaximumSigners int
-var HeliusApiKey string
 var SequencerHost string
 
-type PartyProcess struct {
-	Party  *tss.Party
-	Exists bool
-}
-
-var partyProcesses = make(map[string]PartyProcess)
-
 func main() {
+	// The admin command calls the admin API of a running validator instead of starting one
+	if len(os.Args) > 1 && os.Args[1] == "admin" {
+		adminMain(os.Args[2:])
+	}
 
 	listenHost := flag.String("host", util.LookupEnvOrString("LISTEN_HOST", "0.0.0.0"), "The bootstrap node host listen address\n")
 	port := flag.Int("port", util.LookupEnvOrInt("PORT", 4001), "The bootstrap node listen port")
 	bootnodeURL := flag.String("bootnode", util.LookupEnvOrString("BOOTNODE_URL", ""), "is the process a signer")
 	grpcPort := flag.String("grpcPort", util.LookupEnvOrString("GRPC_PORT", "50051"), "grpc API port")
+	adminAddr := flag.String("adminAddr", util.LookupEnvOrString("ADMIN_ADDR", "127.0.0.1:50052"), "listen address of the admin API")
+	adminToken := flag.String("adminToken", util.LookupEnvOrString("ADMIN_TOKEN", ""), "bearer token of the admin API, which is disabled when empty")
 	validatorPublicKey := flag.String("validatorPublicKey", util.LookupEnvOrString("VALIDATOR_PUBLIC_KEY", ""), "public key of the validator nodes")
 	validatorPrivateKey := flag.String("validatorPrivateKey", util.LookupEnvOrString("VALIDATOR_PRIVATE_KEY", ""), "private key of the validator nodes")
-	// serverCertARN := flag.String("server-cert-arn", util.LookupEnvOrString("SERVER_CERT_ARN", ""), "ARN of the gRPC server certificate in Secrets Manager")
-	// serverKeyARN := flag.String("server-key-arn", util.LookupEnvOrString("SERVER_KEY_ARN", ""), "ARN of the gRPC server private key in Secrets Manager")
-	// clientCaARN := flag.String("client-ca-arn", util.LookupEnvOrString("CLIENT_CA_ARN", ""), "ARN of the client CA certificate for gRPC mTLS in Secrets Manager")
+	grpcInsecure := flag.Bool("grpcInsecure", util.LookupEnvOrBool("GRPC_INSECURE", false), "serve gRPC without TLS, never do this outside of debugging")
+	tlsCert := flag.String("tlsCert", util.LookupEnvOrString("TLS_CERT", ""), "gRPC server certificate PEM, a file or a Secrets Manager ARN")
+	tlsKey := flag.String("tlsKey", util.LookupEnvOrString("TLS_KEY", ""), "gRPC server private key PEM, a file or a Secrets Manager ARN")
+	tlsCA := flag.String("tlsCA", util.LookupEnvOrString("TLS_CA", ""), "PEM of the CA issuing client certificates, a file or a Secrets Manager ARN")
+	tlsAllowedClients := flag.String("tlsAllowedClients", util.LookupEnvOrString("TLS_ALLOWED_CLIENTS", "sequencer"), "comma separated names of the client certificates allowed to call the validator")
+	tlsReloadInterval := flag.Int("tlsReloadInterval", util.LookupEnvOrInt("TLS_RELOAD_INTERVAL", 60), "seconds between reloads of the TLS certificate, key and CA")
+	tlsDevDir := flag.String("tlsDevDir", util.LookupEnvOrString("TLS_DEV_DIR", ""), "issue the gRPC certificate from a throwaway CA kept in this directory, for local networks and tests")
+	tlsDevName := flag.String("tlsDevName", util.LookupEnvOrString("TLS_DEV_NAME", ""), "name of the dev certificate, the hostname by default")
 
 	intentOperatorsRegistryContractAddress := flag.String("intentOperatorsRegistryAddress", util.LookupEnvOrString("SIGNER_HUB_CONTRACT_ADDRESS", "0x716A4f850809d929F85BF1C589c24FB25F884674"), "address of IntentOperatorsRegistry contract")
 	solversRegistryContractAddress := flag.String("solversRegistryAddress", util.LookupEnvOrString("SOLVERS_REGISTRY_CONTRACT_ADDRESS", "0x56A9bCddF533Af1859842074B46B0daD07b7686a"), "address of SolversRegistry contract")
 	bridgeContractAddress := flag.String("bridgeContractAddress", util.LookupEnvOrString("BRIDGE_CONTRACT_ADDRESS", "0x79E3A2B39e77dfB5C9C6a370D4a8a4fa42c482c0"), "address of Bridge contract")
 	rpcURL := flag.String("rpcURL", util.LookupEnvOrString("RPC_URL", "http://localhost:8545"), "ethereum node RPC URL")
-	heliusApiKey := flag.String("heliusApiKey", util.LookupEnvOrString("HELIUS_API_KEY", "6ccb4a2e-a0e6-4af3-afd0-1e06e1439547"), "helius API key")
 	sequencerHost := flag.String("sequencerHost", util.LookupEnvOrString("SEQUENCER_HOST", "http://sequencer:8082"), "sequencer url")
 	// maximumSigners := flag.Int("maximumSigners", util.LookupEnvOrInt("MAXIMUM_SIGNERS", 3), "maximum number of signers for an account")
 
@@ -50,6 +57,24 @@ func main() {
 	postgresUser := flag.String("postgresUser", util.LookupEnvOrString("POSTGRES_USER", "postgres"), "postgres user")
 	postgresPassword := flag.String("postgresPassword", util.LookupEnvOrString("POSTGRES_PASSWORD", "password"), "postgres password")
 
+	keyShareKEK := registerKeyShareFlags("keyShare", "KEY_SHARE_", "key share encryption")
+	newKeyShareKEK := registerKeyShareFlags("newKeyShare", "NEW_KEY_SHARE_", "key share encryption to rotate to,")
+	maxSigningSessions := flag.Int("maxSigningSessions", util.LookupEnvOrInt("MAX_SIGNING_SESSIONS", 16), "maximum number of signing sessions running at once, others are rejected and retried by the validator that started them")
+	p2pRateLimit := flag.Int("p2pRateLimit", util.LookupEnvOrInt("P2P_RATE_LIMIT", 100), "messages per second each peer can publish on the topic")
+	p2pRateBurst := flag.Int("p2pRateBurst", util.LookupEnvOrInt("P2P_RATE_BURST", 500), "messages a peer can publish at once above its rate")
+	preParamsPoolSize := flag.Int("preParamsPoolSize", util.LookupEnvOrInt("PRE_PARAMS_POOL_SIZE", 4), "number of ECDSA pre-params kept ready for keygen and resharing, 0 disables the pool")
+	frostNoncePoolSize := flag.Int("frostNoncePoolSize", util.LookupEnvOrInt("FROST_NONCE_POOL_SIZE", 16), "number of FROST nonces kept ready for the signings of each FROST wallet, 0 disables the pool")
+	policyFile := flag.String("policyFile", util.LookupEnvOrString("POLICY_FILE", ""), "signed signing policy document, operations are not restricted when empty")
+	policySigner := flag.String("policySigner", util.LookupEnvOrString("POLICY_SIGNER", ""), "ethereum address that signs the signing policy")
+	migrateKeyShares := flag.Bool("migrateKeyShares", false, "encrypt key shares stored in plaintext and exit")
+	rotateKeyShares := flag.Bool("rotateKeyShares", false, "rewrap key shares from the keyShare key to the newKeyShare key and exit")
+	backupKeyShares := flag.String("backupKeyShares", "", "write a backup of all key shares, encrypted to the recovery key, to this file and exit")
+	restoreKeyShares := flag.String("restoreKeyShares", "", "restore the key shares of a backup file or directory and exit")
+	recoveryRecipient := flag.String("recoveryRecipient", util.LookupEnvOrString("RECOVERY_RECIPIENT", ""), "age recipient (age1...) of the operator recovery key key share backups are encrypted to")
+	recoveryIdentity := flag.String("recoveryIdentity", util.LookupEnvOrString("RECOVERY_IDENTITY", ""), "age identity file of the operator recovery key, to restore key shares")
+	recoveryPassphrase := flag.String("recoveryPassphrase", util.LookupEnvOrString("RECOVERY_PASSPHRASE", ""), "passphrase of the recovery identity file")
+	keyShareBackupDirectory := flag.String("keyShareBackupDir", util.LookupEnvOrString("KEY_SHARE_BACKUP_DIR", ""), "directory getting a backup of every new or reshared key share, disabled when empty")
+
 	flag.Parse()
 
 	SolversRegistryContractAddress = *solversRegistryContractAddress
@@ -57,7 +82,6 @@ func main() {
 	BridgeContractAddress = *bridgeContractAddress
 
 	NodePublicKey = *validatorPublicKey
-	HeliusApiKey = *heliusApiKey
 	SequencerHost = *sequencerHost
 
 	IntentOperatorsRegistryContractAddress = *intentOperatorsRegistryContractAddress
@@ -69,26 +93,98 @@ func main() {
 	}
 	defer logger.Sync()
 
-	// awsCfg, err := config.LoadDefaultConfig(context.TODO())
-	// if err != nil {
-	// 	logger.Sugar().Fatalf("Failed to load AWS config: %v", err)
-	// }
-	// smClient := secretsmanager.NewFromConfig(awsCfg)
-
-	// logger.Sugar().Info("Fetching gRPC mTLS credentials from AWS Secrets Manager...")
-	// serverCertPEM, err := libs.FetchSecret(context.TODO(), smClient, *serverCertARN)
-	// if err != nil {
-	// 	logger.Sugar().Fatalf("Failed to fetch server certificate: %v", err)
-	// }
-	// serverKeyPEM, err := libs.FetchSecret(context.TODO(), smClient, *serverKeyARN)
-	// if err != nil {
-	// 	logger.Sugar().Fatalf("Failed to fetch server key: %v", err)
-	// }
-	// clientCaPEM, err := libs.FetchSecret(context.TODO(), smClient, *clientCaARN)
-	// if err != nil {
-	// 	logger.Sugar().Fatalf("Failed to fetch client CA certificate: %v", err)
-	// }
-	// logger.Sugar().Info("Successfully fetched gRPC mTLS credentials.")
+	kek, err := keyShareKEK.wrapper(context.Background())
+	if err != nil {
+		logger.Sugar().Fatalf("Failed to initialise key share encryption: %v", err)
+	}
+	InitialiseKeyShareEncryption(kek)
+
+	if *recoveryRecipient != "" {
+		keyShareRecovery, err = keystore.NewAgeRecipientWrapper(*recoveryRecipient)
+		if err != nil {
+			logger.Sugar().Fatalf("Failed to initialise the recovery key: %v", err)
+		}
+	}
+	if *keyShareBackupDirectory != "" {
+		if keyShareRecovery == nil {
+			logger.Sugar().Fatalf("recoveryRecipient is required to back up key shares to %s", *keyShareBackupDirectory)
+		}
+		if err := os.MkdirAll(*keyShareBackupDirectory, 0o700); err != nil {
+			logger.Sugar().Fatalf("Failed to create key share backup directory: %v", err)
+		}
+		keyShareBackupDir = *keyShareBackupDirectory
+	}
+
+	// Key share maintenance runs offline, before the node joins the network
+	if *migrateKeyShares || *rotateKeyShares || *backupKeyShares != "" || *restoreKeyShares != "" {
+		InitialiseDB(*postgresHost, *postgresDB, *postgresUser, *postgresPassword)
+
+		if *migrateKeyShares {
+			migrated, err := MigrateKeyShares(context.Background(), kek)
+			if err != nil {
+				logger.Sugar().Fatalf("Failed to migrate key shares after %d: %v", migrated, err)
+			}
+			logger.Sugar().Infof("Encrypted %d key shares with %s", migrated, kek.Name())
+		}
+
+		if *rotateKeyShares {
+			newWrapper, err := newKeyShareKEK.wrapper(context.Background())
+			if err != nil {
+				logger.Sugar().Fatalf("Failed to initialise new key share encryption: %v", err)
+			}
+			rotated, err := RotateKeyShares(context.Background(), kek, newWrapper)
+			if err != nil {
+				logger.Sugar().Fatalf("Failed to rotate key shares after %d: %v", rotated, err)
+			}
+			logger.Sugar().Infof("Rotated %d key shares from %s to %s", rotated, kek.Name(), newWrapper.Name())
+		}
+
+		if *backupKeyShares != "" {
+			if keyShareRecovery == nil {
+				logger.Sugar().Fatalf("recoveryRecipient is required to back up key shares")
+			}
+			count, err := BackupKeyShares(context.Background(), keyShareRecovery, *backupKeyShares)
+			if err != nil {
+				logger.Sugar().Fatalf("Failed to back up key shares: %v", err)
+			}
+			logger.Sugar().Infof("Backed up %d key shares to %s", count, *backupKeyShares)
+		}
+
+		if *restoreKeyShares != "" {
+			recovery, err := keystore.NewAgeWrapper(*recoveryIdentity, *recoveryPassphrase)
+			if err != nil {
+				logger.Sugar().Fatalf("Failed to load the recovery identity: %v", err)
+			}
+			restored, skipped, err := RestoreKeyShares(context.Background(), recovery, *restoreKeyShares)
+			if err != nil {
+				logger.Sugar().Fatalf("Failed to restore key shares after %d: %v", restored, err)
+			}
+			logger.Sugar().Infof("Restored %d key shares from %s, %d already existed", restored, *restoreKeyShares, skipped)
+		}
+		return
+	}
+
+	var grpcCredentials *mtls.Credentials
+	if *grpcInsecure {
+		logger.Sugar().Warn("gRPC mTLS is disabled, the sign API is served in plaintext to anyone")
+	} else {
+		devName := *tlsDevName
+		if devName == "" {
+			if devName, err = os.Hostname(); err != nil {
+				logger.Sugar().Fatalf("Failed to read the hostname: %v", err)
+			}
+		}
+		grpcCredentials, err = mtls.Setup(context.Background(), mtls.Options{
+			Config:         mtls.Config{Cert: *tlsCert, Key: *tlsKey, CA: *tlsCA},
+			DevDir:         *tlsDevDir,
+			DevName:        devName,
+			DevHosts:       []string{"localhost", "127.0.0.1"},
+			ReloadInterval: time.Duration(*tlsReloadInterval) * time.Second,
+		})
+		if err != nil {
+			logger.Sugar().Fatalf("Failed to load gRPC mTLS credentials: %v", err)
+		}
+	}
 
 	instance, err := intentoperatorsregistry.GetIntentOperatorsRegistryContract(RPC_URL, IntentOperatorsRegistryContractAddress)
 	if err != nil {
@@ -107,8 +203,27 @@ func main() {
 
 	MaximumSigners = int(_maxSigners.Int64())
 
+	if *maxSigningSessions < 1 {
+		logger.Sugar().Fatalf("maxSigningSessions must be at least 1, got %d", *maxSigningSessions)
+	}
+	signingSlots = make(chan struct{}, *maxSigningSessions)
+
+	if *policyFile != "" {
+		if *policySigner == "" {
+			logger.Sugar().Fatalf("policySigner is required to load the signing policy %s", *policyFile)
+		}
+		signingPolicy, err = LoadSigningPolicy(*policyFile, *policySigner)
+		if err != nil {
+			logger.Sugar().Fatalf("Failed to load signing policy: %v", err)
+		}
+		logger.Sugar().Infof("Loaded signing policy %s signed by %s", *policyFile, *policySigner)
+	}
+
 	InitialiseDB(*postgresHost, *postgresDB, *postgresUser, *postgresPassword)
 
+	go startPreParamsPool(*preParamsPoolSize)
+	go startFrostNoncePool(*frostNoncePoolSize)
+
 	blockchains.InitBlockchainRegistry()
 	// Initialize host first
 	var addr multiaddr.Multiaddr
@@ -121,9 +236,15 @@ func main() {
 
 	// Start HTTP server after host is initialized
 	// go startHTTPServer(*httpPort)
-	go startGRPCServer(*grpcPort, h, "", "", "")
+	go startGRPCServer(*grpcPort, h, grpcCredentials, strings.Split(*tlsAllowedClients, ","))
+	if *adminToken != "" {
+		go startAdminServer(*adminAddr, *adminToken, h)
+	}
 
-	err = subscribe(h)
+	if *p2pRateLimit < 1 || *p2pRateBurst < 1 {
+		logger.Sugar().Fatalf("p2pRateLimit and p2pRateBurst must be at least 1, got %d and %d", *p2pRateLimit, *p2pRateBurst)
+	}
+	err = subscribe(h, newPeerRateLimiter(*p2pRateLimit, *p2pRateBurst))
 	if err != nil {
 		logger.Sugar().Fatalf("Failed to subscribe to libp2p topic: %v", err)
 	}
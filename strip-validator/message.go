@@ -1,21 +1,16 @@
 package main
 
 import (
-	"context"
-	"encoding/base64"
-	"encoding/hex"
 	"encoding/json"
-	"log"
-	"strings"
+	"fmt"
+	"math/big"
 
 	"github.com/StripChain/strip-node/common"
 	intentoperatorsregistry "github.com/StripChain/strip-node/intentOperatorsRegistry"
 	"github.com/StripChain/strip-node/libs/blockchains"
 	"github.com/StripChain/strip-node/util/logger"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
-	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/mr-tron/base58/base58"
 )
 
 type MessageType uint
@@ -27,217 +22,211 @@ const (
 	MESSAGE_TYPE_START_SIGN            MessageType = 3
 	MESSAGE_TYPE_SIGN                  MessageType = 4
 	MESSAGE_TYPE_SIGNATURE             MessageType = 5
+	MESSAGE_TYPE_START_RESHARE         MessageType = 6
+	MESSAGE_TYPE_RESHARE               MessageType = 7
+	MESSAGE_TYPE_RECEIPT               MessageType = 8
+	MESSAGE_TYPE_SIGNING_BUSY          MessageType = 9
+	MESSAGE_TYPE_SIGNING_DENIED        MessageType = 10
 )
 
 type Message struct {
+	SessionID          string                   `json:"sessionId,omitempty"`
 	Identity           string                   `json:"identity"`
 	BlockchainID       blockchains.BlockchainID `json:"blockchainID"`
 	IdentityCurve      common.Curve             `json:"identityCurve"`
 	KeyCurve           common.Curve             `json:"keyCurve"`
+	DerivationPath     string                   `json:"derivationPath,omitempty"`
 	From               int                      `json:"from"`
 	To                 int                      `json:"to"`
 	Message            []byte                   `json:"message"`
 	Type               MessageType              `json:"type"`
 	IsToNewCommittee   bool                     `json:"isToNewCommittee"`
+	IsToOldCommittee   bool                     `json:"isToOldCommittee"`
 	IsFromNewCommittee bool                     `json:"isFromNewCommittee"`
 	IsBroadcast        bool                     `json:"isBroadcast"`
 	Hash               []byte                   `json:"hash"`
 	Address            string                   `json:"address"`
 	Signature          []byte                   `json:"signature"`
+	Seq                uint64                   `json:"seq"`
+	Encrypted          bool                     `json:"encrypted,omitempty"`
 	Signers            []string                 `json:"signers"`
+	NewSigners         []string                 `json:"newSigners,omitempty"`
+	Threshold          int                      `json:"threshold,omitempty"`
+	NewThreshold       int                      `json:"newThreshold,omitempty"`
+	PartyKeys          []*big.Int               `json:"partyKeys,omitempty"`
+	IntentID           string                   `json:"intentId,omitempty"`
+	OperationIndex     int                      `json:"operationIndex,omitempty"`
+	Intent             []byte                   `json:"intent,omitempty"`
+	Timestamp          int64                    `json:"timestamp,omitempty"`
+	RawSignature       []byte                   `json:"rawSignature,omitempty"`
 	AlgorandFlags      *struct {
 		IsRealTransaction bool `json:"isRealTransaction"`
 	} `json:"algorandFlags,omitempty"`
+
+	// sender is the signer that broadcast the message, set once its signature is verified
+	sender string
+	// recipient is the signer a point-to-point message is sent to directly
+	recipient string
+}
+
+// ISignerRegistry tells which public keys belong to registered signers
+type ISignerRegistry interface {
+	IsSigner(publicKey string) (bool, error)
+}
+
+// contractSignerRegistry looks signers up in the IntentOperatorsRegistry contract
+type contractSignerRegistry struct{}
+
+func (contractSignerRegistry) IsSigner(publicKey string) (bool, error) {
+	instance, err := intentoperatorsregistry.GetIntentOperatorsRegistryContract(RPC_URL, IntentOperatorsRegistryContractAddress)
+	if err != nil {
+		return false, fmt.Errorf("failed to get intent operators registry contract: %w", err)
+	}
+	return instance.Signers(&bind.CallOpts{}, common.PublicKeyStrToBytes32(publicKey))
 }
 
+// signerRegistry authenticates the senders of incoming messages
+var signerRegistry ISignerRegistry = contractSignerRegistry{}
+
 type IsValid struct {
 	Result bool `json:"result"`
 }
 
+// handleIncomingMessage verifies a message from the topic and dispatches it. Malformed or
+// unauthenticated messages and replays are dropped, they must never stop the node.
 func handleIncomingMessage(message []byte) {
 	msg := Message{}
-	json.Unmarshal(message, &msg)
+	if err := json.Unmarshal(message, &msg); err != nil {
+		logger.Sugar().Warnw("dropping malformed message", "error", err)
+		return
+	}
 
 	_validateMsg := msg
 	// _signature := msg.Signature
 	_validateMsg.Signature = nil
 	messageBytes, err := json.Marshal(_validateMsg)
-
 	if err != nil {
-		panic(err)
+		logger.Sugar().Warnw("dropping message that cannot be encoded", "error", err)
+		return
 	}
 
 	hash := crypto.Keccak256Hash(messageBytes)
 
 	sigPublicKey, err := crypto.Ecrecover(hash.Bytes(), msg.Signature)
 	if err != nil {
-		log.Fatal(err)
+		logger.Sugar().Warnw("dropping message with an invalid signature", "session", msg.SessionID, "error", err)
+		return
 	}
 	pubKey, err := crypto.UnmarshalPubkey(sigPublicKey)
 	if err != nil {
-		log.Fatal(err)
+		logger.Sugar().Warnw("dropping message with an invalid signature", "session", msg.SessionID, "error", err)
+		return
 	}
 
-	compressedPubKey := crypto.CompressPubkey(pubKey)
-	compressedPubKeyStr := hexutil.Encode(compressedPubKey)
+	compressedPubKeyStr := signerPublicKey(pubKey)
 
-	compressedPubKeyStr = compressedPubKeyStr[4:]
-	compressedPubKeyStr = "0x" + compressedPubKeyStr
-
-	instance, err := intentoperatorsregistry.GetIntentOperatorsRegistryContract(RPC_URL, IntentOperatorsRegistryContractAddress)
+	signerExists, err := signerRegistry.IsSigner(compressedPubKeyStr)
 	if err != nil {
-		logger.Sugar().Errorw("failed to get intent operators registry contract", "error", err)
-		log.Fatal(err)
+		logger.Sugar().Errorw("failed to check the signer of a message", "signer", compressedPubKeyStr, "error", err)
+		return
 	}
 
-	signerExists, err := instance.Signers(&bind.CallOpts{}, common.PublicKeyStrToBytes32(compressedPubKeyStr))
-	if err != nil {
-		logger.Sugar().Errorw("message from not registered signer", "error", err)
-		log.Fatal(err)
+	if !signerExists {
+		return
 	}
+	msg.sender = compressedPubKeyStr
 
-	if !signerExists {
+	if !replays.Accept(msg.SessionID, msg.sender, msg.Seq) {
+		logger.Sugar().Warnw("dropping replayed message", "session", msg.SessionID, "sender", msg.sender, "seq", msg.Seq)
 		return
 	}
 
 	if msg.Type == MESSAGE_TYPE_GENERATE_START_KEYGEN {
-		go generateKeygen(msg.Identity, msg.IdentityCurve, msg.KeyCurve, msg.Signers)
+		go generateKeygen(msg.SessionID, msg.Identity, msg.IdentityCurve, msg.KeyCurve, msg.Signers, msg.Threshold)
 	} else if msg.Type == MESSAGE_TYPE_GENERATE_KEYGEN {
-		go updateKeygen(msg.Identity, msg.IdentityCurve, msg.KeyCurve, msg.From, msg.Message, msg.IsBroadcast, msg.To, msg.Signers)
+		go updateKeygen(msg)
 	} else if msg.Type == MESSAGE_TYPE_START_SIGN {
-		go generateSignature(msg.Identity, msg.BlockchainID, msg.IdentityCurve, msg.KeyCurve, msg.Hash)
+		if !isSignSessionOf(msg) {
+			logger.Sugar().Warnw("signing session does not match the message", "session", msg.SessionID, "identity", msg.Identity)
+			return
+		}
+		go joinSignature(msg)
 	} else if msg.Type == MESSAGE_TYPE_SIGN {
-		go updateSignature(msg.Identity, msg.IdentityCurve, msg.KeyCurve, msg.From, msg.Message, msg.IsBroadcast, msg.To)
+		if !isSignSessionOf(msg) {
+			logger.Sugar().Warnw("signing session does not match the message", "session", msg.SessionID, "identity", msg.Identity)
+			return
+		}
+		go updateSignature(msg)
+	} else if msg.Type == MESSAGE_TYPE_START_RESHARE {
+		go generateReshare(msg.SessionID, msg.Identity, msg.IdentityCurve, msg.KeyCurve, msg.Signers, msg.Threshold, msg.PartyKeys, msg.NewSigners, msg.NewThreshold, msg.Address)
+	} else if msg.Type == MESSAGE_TYPE_RESHARE {
+		go updateReshare(msg)
 	} else if msg.Type == MESSAGE_TYPE_SIGNATURE {
-		// When looking up the channel to send back the signature, we must encode the hash
-		// in the same format that was used when creating the channel in api.go.
-		// This ensures we find the correct channel for each chain's message format.
-
-		sendMsg := make(chan bool)
-		switch msg.BlockchainID {
-		case blockchains.Solana:
-			// Solana: Client sends base58 string -> decode -> process -> encode back to base58
-			// Channel key must match the original base58 format from client
-			if val, ok := messageChan[base58.Encode(msg.Hash)]; ok {
-				val <- msg
-				go func() {
-					sendMsg <- true
-				}()
-			}
-		case blockchains.Bitcoin:
-			// Bitcoin: Client sends string -> hash -> process -> encode to hex
-			// Channel key must match the hex encoded hash
-			if val, ok := messageChan[string(msg.Hash)]; ok {
-				val <- msg
-				go func() {
-					sendMsg <- true
-				}()
-			}
-		case blockchains.Dogecoin:
-			// Dogecoin: Client sends string -> hash -> process -> encode to hex
-			// Channel key must match the hex encoded hash
-			// if val, ok := messageChan[hex.EncodeToString(msg.Hash)]; ok {
-			if val, ok := messageChan[string(msg.Hash)]; ok {
-				val <- msg
-				go func() {
-					sendMsg <- true
-				}()
-			}
-		case blockchains.Aptos, blockchains.Cardano:
-			// Aptos: Client sends string -> process -> encode to hex
-			// Channel key must match the hex encoded format
-			if val, ok := messageChan[hex.EncodeToString(msg.Hash)]; ok {
-				val <- msg
-				go func() {
-					sendMsg <- true
-				}()
-			}
-		case blockchains.Ripple:
-			// Ripple: Client sends string -> process -> encode to hex
-			// Channel key must match the hex encoded format
-			if val, ok := messageChan[strings.ToUpper(hex.EncodeToString(msg.Hash))]; ok {
-				val <- msg
-				go func() {
-					sendMsg <- true
-				}()
-			}
-		case blockchains.Stellar:
-			// Stellar: Client sends base64 string -> decode -> process -> encode back to base64
-			// Channel key must match the original base64 format from client, using StrKey encoding
-			if val, ok := messageChan[base64.StdEncoding.EncodeToString(msg.Hash)]; ok {
-				val <- msg
-				go func() {
-					sendMsg <- true
-				}()
-			}
-		case blockchains.Sui:
-			// Sui: Client sends base64 string -> decode -> process -> encode back to base64
-			// Channel key must match the original base32 format from client
-			if val, ok := messageChan[base64.StdEncoding.EncodeToString(msg.Hash)]; ok {
-				val <- msg
-				go func() {
-					sendMsg <- true
-				}()
-			}
-		case blockchains.Algorand:
-			// Algorand: Client sends base64 string -> decode -> process -> encode back to base64
-			// Channel key must match the original base32 format from client
-			if val, ok := messageChan[base64.StdEncoding.EncodeToString(msg.Hash)]; ok {
-				val <- msg
-				go func() {
-					sendMsg <- true
-				}()
-			}
-		default:
-			// Ethereum: Client sends string -> process raw bytes
-			// Channel key must match the raw bytes as string
-			if val, ok := messageChan[string(msg.Hash)]; ok {
-				val <- msg
-				go func() {
-					sendMsg <- true
-				}()
-			}
+		// Hands the signature to the request waiting for it, if it was made on this node
+		if isSignSessionOf(msg) {
+			sessions.Complete(msg.SessionID, msg)
+			go coSignReceipt(msg)
 		}
-
-		val := <-sendMsg
-		if val {
-			logger.Sugar().Infof("message sent: %v", val)
+	} else if msg.Type == MESSAGE_TYPE_SIGNING_BUSY {
+		if isSignSessionOf(msg) {
+			go signingRejected(msg, errSigningBusy)
+		}
+	} else if msg.Type == MESSAGE_TYPE_SIGNING_DENIED {
+		if isSignSessionOf(msg) {
+			go signingRejected(msg, fmt.Errorf("%w by %s: %s", errSigningDenied, msg.sender, msg.Message))
+		}
+	} else if msg.Type == MESSAGE_TYPE_RECEIPT {
+		if isSignSessionOf(msg) {
+			receipts.AddCoSignature(msg.SessionID, msg.sender, msg.Message)
 		}
 	}
 }
 
 func broadcast(message Message) {
+	// The sequence number is signed with the message, so a replay is recognised as one
+	message.Seq = nextMessageSeq()
 
 	messageBytes, err := json.Marshal(message)
-
 	if err != nil {
-		panic(err)
+		logger.Sugar().Errorw("failed to encode message", "session", message.SessionID, "error", err)
+		return
 	}
 
 	hash := crypto.Keccak256Hash(messageBytes)
 
-	cleanedPrivateKey := strings.Replace(NodePrivateKey, "0x", "", 1)
-	privateKey, err := crypto.HexToECDSA(cleanedPrivateKey)
+	privateKey, err := nodeKey()
 	if err != nil {
-		log.Fatal(err)
+		logger.Sugar().Errorw("failed to read the node key", "error", err)
+		return
 	}
 
 	signature, err := crypto.Sign(hash.Bytes(), privateKey)
 	if err != nil {
-		log.Fatal(err)
+		logger.Sugar().Errorw("failed to sign message", "session", message.SessionID, "error", err)
+		return
 	}
 
 	message.Signature = signature
 
 	out, err := json.Marshal(message)
 	if err != nil {
-		panic(err)
+		logger.Sugar().Errorw("failed to encode message", "session", message.SessionID, "error", err)
+		return
 	}
 
-	ctx := context.Background()
+	if message.recipient != "" {
+		if message.recipient == NodePublicKey {
+			go handleIncomingMessage(out)
+			return
+		}
+		err := transport.Send(message.recipient, out)
+		if err == nil {
+			return
+		}
+		logger.Sugar().Warnw("failed to send message directly, publishing it on the topic", "session", message.SessionID, "recipient", message.recipient, "error", err)
+	}
 
-	if err := topic.Publish(ctx, out); err != nil {
-		logger.Sugar().Errorw("failed to publish message", "error", err)
-		panic(err)
+	if err := transport.Publish(out); err != nil {
+		logger.Sugar().Errorw("failed to publish message", "session", message.SessionID, "error", err)
 	}
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/libp2p/go-libp2p"
 	dht "github.com/libp2p/go-libp2p-kad-dht"
@@ -13,11 +14,36 @@ import (
 	drouting "github.com/libp2p/go-libp2p/p2p/discovery/routing"
 	dutil "github.com/libp2p/go-libp2p/p2p/discovery/util"
 	"github.com/multiformats/go-multiaddr"
+	"golang.org/x/time/rate"
 )
 
 var topic *pubsub.Topic
 var topicNameFlag = "renode"
 
+// ITransport carries the signed messages of this node to the other validators
+type ITransport interface {
+	// Publish sends a message to every validator, this node included
+	Publish(data []byte) error
+	// Send sends a message to one signer only
+	Send(signer string, data []byte) error
+}
+
+// p2pTransport publishes messages on the topic and sends them directly over libp2p streams
+type p2pTransport struct{}
+
+func (p2pTransport) Publish(data []byte) error {
+	return topic.Publish(context.Background(), data)
+}
+
+func (p2pTransport) Send(signer string, data []byte) error {
+	return sendDirect(signer, data)
+}
+
+var transport ITransport = p2pTransport{}
+
+// peerRouting is the DHT used to find the addresses of other validators
+var peerRouting *dht.IpfsDHT
+
 func initDHT(ctx context.Context, h host.Host, bootnode []multiaddr.Multiaddr) (*dht.IpfsDHT, error) {
 	// Start a DHT, for use in peer discovery. We can't just make a new DHT
 	// client because we want each peer to maintain its own local copy of the
@@ -55,6 +81,7 @@ func discoverPeers(h host.Host, bootnode []multiaddr.Multiaddr) error {
 	if err != nil {
 		return fmt.Errorf("failed to initialize DHT: %w", err)
 	}
+	peerRouting = kademliaDHT
 	routingDiscovery := drouting.NewRoutingDiscovery(kademliaDHT)
 	dutil.Advertise(ctx, routingDiscovery, topicNameFlag)
 
@@ -82,7 +109,72 @@ func discoverPeers(h host.Host, bootnode []multiaddr.Multiaddr) error {
 	return nil
 }
 
-func subscribe(h host.Host) error {
+// peerIdleTimeout is how long the rate limiter of a peer that stopped sending is kept
+const peerIdleTimeout = 10 * time.Minute
+
+// peerRateLimiter limits the messages each peer can publish on the topic. Messages over
+// the limit are neither processed nor forwarded.
+type peerRateLimiter struct {
+	mu        sync.Mutex
+	limit     rate.Limit
+	burst     int
+	peers     map[peer.ID]*peerRate
+	lastPrune time.Time
+}
+
+type peerRate struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+func newPeerRateLimiter(perSecond int, burst int) *peerRateLimiter {
+	return &peerRateLimiter{
+		limit:     rate.Limit(perSecond),
+		burst:     burst,
+		peers:     make(map[peer.ID]*peerRate),
+		lastPrune: time.Now(),
+	}
+}
+
+// Allow reports whether a peer can publish one more message now
+func (l *peerRateLimiter) Allow(id peer.ID) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.lastPrune) > peerIdleTimeout {
+		for id, p := range l.peers {
+			if now.Sub(p.lastSeen) > peerIdleTimeout {
+				delete(l.peers, id)
+			}
+		}
+		l.lastPrune = now
+	}
+
+	p, ok := l.peers[id]
+	if !ok {
+		p = &peerRate{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.peers[id] = p
+	}
+	p.lastSeen = now
+	return p.limiter.AllowN(now, 1)
+}
+
+// validator returns the topic validator applying the limits to messages of other peers
+func (l *peerRateLimiter) validator(self peer.ID) pubsub.ValidatorEx {
+	return func(ctx context.Context, from peer.ID, msg *pubsub.Message) pubsub.ValidationResult {
+		origin := msg.GetFrom()
+		if origin == self {
+			return pubsub.ValidationAccept
+		}
+		if !l.Allow(origin) {
+			return pubsub.ValidationIgnore
+		}
+		return pubsub.ValidationAccept
+	}
+}
+
+func subscribe(h host.Host, limiter *peerRateLimiter) error {
 	ctx := context.Background()
 
 	ps, err := pubsub.NewGossipSub(ctx, h)
@@ -90,6 +182,14 @@ func subscribe(h host.Host) error {
 		return fmt.Errorf("failed to create gossipsub: %w", err)
 	}
 
+	if err := ps.RegisterTopicValidator(topicNameFlag, limiter.validator(h.ID())); err != nil {
+		return fmt.Errorf("failed to register topic validator: %w", err)
+	}
+
+	p2pHost = h
+	h.SetStreamHandler(tssProtocol, limiter.streamHandler)
+	h.SetStreamHandler(walletProtocol, limiter.walletHandler)
+
 	topic, err = ps.Join(topicNameFlag)
 	if err != nil {
 		return fmt.Errorf("failed to join topic: %w", err)
@@ -117,7 +217,13 @@ func handleMessageFromSub(ctx context.Context, sub *pubsub.Subscription) error {
 }
 
 func createHost(listenHost string, port int, bootnodeURL string) (host.Host, multiaddr.Multiaddr, error) {
-	h, err := libp2p.New(libp2p.ListenAddrStrings(fmt.Sprintf("/ip4/%s/tcp/%d", listenHost, port)))
+	// The node key is the libp2p identity, so other validators know the peer ID of a signer
+	identity, err := nodePeerKey()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read node key: %w", err)
+	}
+
+	h, err := libp2p.New(libp2p.Identity(identity), libp2p.ListenAddrStrings(fmt.Sprintf("/ip4/%s/tcp/%d", listenHost, port)))
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create host: %w", err)
 	}
@@ -0,0 +1,471 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	identityVerification "github.com/StripChain/strip-node/identity"
+	"github.com/StripChain/strip-node/libs"
+	"github.com/StripChain/strip-node/libs/blockchains"
+	"github.com/StripChain/strip-node/util/logger"
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// policyVersion is the version of signing policy documents this validator understands
+const policyVersion = 1
+
+// Rules of the signing policy, reported with the denials they cause
+const (
+	ruleMaxOperationsPerIntent = "maxOperationsPerIntent"
+	ruleAllowedChains          = "allowedChains"
+	ruleDeniedChains           = "deniedChains"
+	ruleAllowlist              = "allowlist"
+	ruleDenylist               = "denylist"
+	ruleDailyLimit             = "dailyLimit"
+	ruleHours                  = "hours"
+)
+
+// signingPolicy is the policy operations are checked against before signing, nil when
+// the validator runs without one
+var signingPolicy *SigningPolicy
+
+// policyMu serialises policy checks so concurrent operations cannot overrun a daily limit
+var policyMu sync.Mutex
+
+// SignedPolicy is the policy document every validator loads. The signature is an EIP-191
+// signature of the policy signer over the policy exactly as it appears in the file.
+type SignedPolicy struct {
+	Policy    json.RawMessage `json:"policy"`
+	Signature string          `json:"signature"`
+}
+
+// SigningPolicy restricts the operations validators sign. Empty rules do not restrict anything.
+type SigningPolicy struct {
+	Version                int                        `json:"version"`
+	MaxOperationsPerIntent int                        `json:"maxOperationsPerIntent,omitempty"`
+	AllowedChains          []blockchains.BlockchainID `json:"allowedChains,omitempty"`
+	DeniedChains           []blockchains.BlockchainID `json:"deniedChains,omitempty"`
+	// Allowlist and Denylist hold the destinations operations may or may not transfer to
+	Allowlist   []string      `json:"allowlist,omitempty"`
+	Denylist    []string      `json:"denylist,omitempty"`
+	DailyLimits []DailyLimit  `json:"dailyLimits,omitempty"`
+	Hours       *SigningHours `json:"hours,omitempty"`
+}
+
+// DailyLimit caps the amount of a token a wallet transfers on a chain per UTC day
+type DailyLimit struct {
+	// Identity is the wallet the limit applies to, every wallet has its own limit when empty
+	Identity     string                   `json:"identity,omitempty"`
+	BlockchainID blockchains.BlockchainID `json:"blockchainID"`
+	// Token is the token address, or empty for the native currency
+	Token string `json:"token,omitempty"`
+	// Amount is in base units of the token, like the amounts extracted from transactions
+	Amount string `json:"amount"`
+
+	amount *big.Rat
+}
+
+// SigningHours is the time of day operations are signed in. End before Start spans midnight.
+type SigningHours struct {
+	// Location is an IANA time zone, UTC when empty
+	Location string `json:"location,omitempty"`
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	// Days are the days of the week (Mon, Tue, ...) operations are signed on, every day when empty
+	Days []string `json:"days,omitempty"`
+
+	location   *time.Location
+	start, end int
+}
+
+// PolicyDenial is an operation the signing policy does not allow
+type PolicyDenial struct {
+	Rule   string
+	Reason string
+}
+
+func (d *PolicyDenial) Error() string {
+	return fmt.Sprintf("denied by signing policy rule %s: %s", d.Rule, d.Reason)
+}
+
+// policyCheck is an operation as seen by the signing policy
+type policyCheck struct {
+	identity       string
+	blockchainID   blockchains.BlockchainID
+	operationCount int
+	// transfer is set for operations that transfer out of a wallet
+	transfer *policyTransfer
+}
+
+// policyTransfer is what an operation transfers. Destination and amount are empty when
+// they cannot be extracted from the operation.
+type policyTransfer struct {
+	destination string
+	token       string
+	amount      string
+}
+
+// spentFunc returns the amounts of a token a wallet already transferred on a day
+type spentFunc func(identity string, blockchainID blockchains.BlockchainID, token string, day string) ([]string, error)
+
+// LoadSigningPolicy reads a signed policy document and verifies it was signed by signer
+func LoadSigningPolicy(path string, signer string) (*SigningPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing policy: %w", err)
+	}
+	return parseSignedPolicy(data, signer)
+}
+
+func parseSignedPolicy(data []byte, signer string) (*SigningPolicy, error) {
+	if !ethCommon.IsHexAddress(signer) {
+		return nil, fmt.Errorf("invalid signing policy signer %q", signer)
+	}
+	// Signatures are checked against the checksummed address
+	signer = ethCommon.HexToAddress(signer).Hex()
+
+	var signed SignedPolicy
+	if err := json.Unmarshal(data, &signed); err != nil {
+		return nil, fmt.Errorf("failed to parse signing policy: %w", err)
+	}
+	if len(signed.Policy) == 0 {
+		return nil, errors.New("signing policy document has no policy")
+	}
+
+	signature, err := hexutil.Decode(signed.Signature)
+	if err != nil || len(signature) != 65 {
+		return nil, errors.New("signing policy signature must be a 65 byte hex string")
+	}
+	verified, err := identityVerification.VerifySignature(signer, blockchains.Ethereum, string(signed.Policy), signed.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify signing policy signature: %w", err)
+	}
+	if !verified {
+		return nil, fmt.Errorf("signing policy is not signed by %s", signer)
+	}
+
+	var policy SigningPolicy
+	decoder := json.NewDecoder(strings.NewReader(string(signed.Policy)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&policy); err != nil {
+		return nil, fmt.Errorf("failed to parse signing policy: %w", err)
+	}
+	if err := policy.validate(); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// validate checks the rules of the policy and prepares them for evaluation
+func (p *SigningPolicy) validate() error {
+	if p.Version != policyVersion {
+		return fmt.Errorf("unsupported signing policy version %d", p.Version)
+	}
+	if p.MaxOperationsPerIntent < 0 {
+		return fmt.Errorf("maxOperationsPerIntent must not be negative, got %d", p.MaxOperationsPerIntent)
+	}
+	for i := range p.DailyLimits {
+		limit := &p.DailyLimits[i]
+		if limit.BlockchainID == "" {
+			return fmt.Errorf("daily limit %d has no blockchain", i)
+		}
+		amount, ok := new(big.Rat).SetString(limit.Amount)
+		if !ok || amount.Sign() < 0 {
+			return fmt.Errorf("daily limit %d has invalid amount %q", i, limit.Amount)
+		}
+		limit.amount = amount
+	}
+	if p.Hours != nil {
+		return p.Hours.validate()
+	}
+	return nil
+}
+
+func (h *SigningHours) validate() error {
+	location, err := time.LoadLocation(h.Location)
+	if err != nil {
+		return fmt.Errorf("invalid signing hours location: %w", err)
+	}
+	h.location = location
+	if h.start, err = parseTimeOfDay(h.Start); err != nil {
+		return err
+	}
+	if h.end, err = parseTimeOfDay(h.End); err != nil {
+		return err
+	}
+	for _, day := range h.Days {
+		if !isWeekday(day) {
+			return fmt.Errorf("invalid signing hours day %q", day)
+		}
+	}
+	return nil
+}
+
+// parseTimeOfDay returns the minutes since midnight of an HH:MM time
+func parseTimeOfDay(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid signing hours time %q, expected HH:MM", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func isWeekday(day string) bool {
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		if weekday.String()[:3] == day {
+			return true
+		}
+	}
+	return false
+}
+
+// contains reports whether now is within the signing hours
+func (h *SigningHours) contains(now time.Time) bool {
+	local := now.In(h.location)
+	if len(h.Days) > 0 {
+		day := local.Weekday().String()[:3]
+		found := false
+		for _, d := range h.Days {
+			found = found || d == day
+		}
+		if !found {
+			return false
+		}
+	}
+	minute := local.Hour()*60 + local.Minute()
+	if h.start <= h.end {
+		return minute >= h.start && minute < h.end
+	}
+	return minute >= h.start || minute < h.end
+}
+
+// isEVMAddress reports whether an address is a 0x prefixed hex EVM address
+func isEVMAddress(address string) bool {
+	return strings.HasPrefix(address, "0x") && ethCommon.IsHexAddress(address)
+}
+
+// sameAddress compares addresses exactly, but for hex EVM addresses which are not case
+// sensitive
+func sameAddress(a, b string) bool {
+	if isEVMAddress(a) && isEVMAddress(b) {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
+
+// normaliseAddress returns the form addresses are stored in
+func normaliseAddress(address string) string {
+	if isEVMAddress(address) {
+		return strings.ToLower(address)
+	}
+	return address
+}
+
+func containsAddress(addresses []string, address string) bool {
+	for _, a := range addresses {
+		if sameAddress(a, address) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsChain(chains []blockchains.BlockchainID, chain blockchains.BlockchainID) bool {
+	for _, c := range chains {
+		if c == chain {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluate checks an operation against the policy. It returns a *PolicyDenial when a rule
+// does not allow the operation, and only depends on its arguments so every validator
+// reaches the same decision.
+func (p *SigningPolicy) evaluate(check policyCheck, now time.Time, spent spentFunc) error {
+	if p.MaxOperationsPerIntent > 0 && check.operationCount > p.MaxOperationsPerIntent {
+		return &PolicyDenial{ruleMaxOperationsPerIntent, fmt.Sprintf("intent has %d operations, at most %d are allowed", check.operationCount, p.MaxOperationsPerIntent)}
+	}
+	if len(p.AllowedChains) > 0 && !containsChain(p.AllowedChains, check.blockchainID) {
+		return &PolicyDenial{ruleAllowedChains, fmt.Sprintf("blockchain %s is not allowed", check.blockchainID)}
+	}
+	if containsChain(p.DeniedChains, check.blockchainID) {
+		return &PolicyDenial{ruleDeniedChains, fmt.Sprintf("blockchain %s is denied", check.blockchainID)}
+	}
+	if p.Hours != nil && !p.Hours.contains(now) {
+		return &PolicyDenial{ruleHours, fmt.Sprintf("operations are signed between %s and %s", p.Hours.Start, p.Hours.End)}
+	}
+
+	transfer := check.transfer
+	if transfer == nil {
+		return nil
+	}
+	if len(p.Allowlist) > 0 && (transfer.destination == "" || !containsAddress(p.Allowlist, transfer.destination)) {
+		return &PolicyDenial{ruleAllowlist, fmt.Sprintf("destination %q is not allowed", transfer.destination)}
+	}
+	if len(p.Denylist) > 0 && (transfer.destination == "" || containsAddress(p.Denylist, transfer.destination)) {
+		return &PolicyDenial{ruleDenylist, fmt.Sprintf("destination %q is denied", transfer.destination)}
+	}
+
+	day := now.UTC().Format("2006-01-02")
+	for _, limit := range p.DailyLimits {
+		if limit.BlockchainID != check.blockchainID {
+			continue
+		}
+		if limit.Identity != "" && !sameAddress(limit.Identity, check.identity) {
+			continue
+		}
+		// A transfer that cannot be decoded may be of any token the chain has limits for
+		amount, ok := new(big.Rat).SetString(transfer.amount)
+		if !ok {
+			return &PolicyDenial{ruleDailyLimit, "transferred amount is unknown"}
+		}
+		if !sameAddress(limit.Token, transfer.token) {
+			continue
+		}
+		amounts, err := spent(normaliseAddress(check.identity), check.blockchainID, normaliseAddress(transfer.token), day)
+		if err != nil {
+			return err
+		}
+		total := new(big.Rat).Set(amount)
+		for _, a := range amounts {
+			previous, ok := new(big.Rat).SetString(a)
+			if !ok {
+				return fmt.Errorf("invalid recorded amount %q", a)
+			}
+			total.Add(total, previous)
+		}
+		if total.Cmp(limit.amount) > 0 {
+			return &PolicyDenial{ruleDailyLimit, fmt.Sprintf("transfers of %s today would exceed the limit of %s", total.FloatString(0), limit.Amount)}
+		}
+	}
+	return nil
+}
+
+// withdrawDestination returns the wallet a withdrawal pays: the wallet of the intent on
+// the blockchain of the operation
+var withdrawDestination = func(intent *libs.Intent, operation libs.Operation) (string, error) {
+	wallet, err := blockchainWallet(intent.Identity, intent.BlockchainID)
+	if err != nil {
+		return "", err
+	}
+	return walletAddress(wallet, operation.BlockchainID, operation.NetworkType)
+}
+
+// newPolicyCheck describes an operation of an intent for the signing policy
+func newPolicyCheck(intent *libs.Intent, operationIndex int, chain blockchains.IBlockchain, signingIdentity string) policyCheck {
+	operation := intent.Operations[operationIndex]
+	check := policyCheck{
+		identity:       signingIdentity,
+		blockchainID:   operation.BlockchainID,
+		operationCount: len(intent.Operations),
+	}
+
+	// Transfers that cannot be decoded are left empty, the rules that need them deny it
+	switch operation.Type {
+	case libs.OperationTypeTransaction, libs.OperationTypeSendToBridge:
+		check.transfer = &policyTransfer{}
+		if operation.SerializedTxn == nil {
+			return check
+		}
+		destination, token, err := chain.ExtractDestinationAddress(*operation.SerializedTxn)
+		if err != nil {
+			logger.Sugar().Warnw("Failed to extract destination for signing policy", "intentID", intent.ID, "opIndex", operationIndex, "error", err)
+			return check
+		}
+		check.transfer.destination = destination
+		check.transfer.token = token
+		amount, err := chain.ExtractTransferAmount(*operation.SerializedTxn)
+		if err != nil {
+			logger.Sugar().Warnw("Failed to extract amount for signing policy", "intentID", intent.ID, "opIndex", operationIndex, "error", err)
+			return check
+		}
+		check.transfer.amount = amount
+	case libs.OperationTypeSolver:
+		// What a solver operation transfers is up to the solver, the policy cannot tell
+		check.transfer = &policyTransfer{}
+	case libs.OperationTypeBurn:
+		// Burns return the amount swapped by the previous operation to the bridge contract
+		var metadata BurnMetadata
+		json.Unmarshal([]byte(operation.SolverMetadata), &metadata)
+		check.transfer = &policyTransfer{destination: BridgeContractAddress, token: metadata.Token}
+		if operationIndex > 0 {
+			check.transfer.amount = intent.Operations[operationIndex-1].SolverOutput
+		}
+	case libs.OperationTypeBurnSynthetic:
+		var metadata BurnSyntheticMetadata
+		json.Unmarshal([]byte(operation.SolverMetadata), &metadata)
+		check.transfer = &policyTransfer{destination: BridgeContractAddress, token: metadata.Token, amount: metadata.Amount}
+	case libs.OperationTypeWithdraw:
+		// Withdrawals pay out the amount burnt by the previous operation
+		var metadata WithdrawMetadata
+		json.Unmarshal([]byte(operation.SolverMetadata), &metadata)
+		check.transfer = &policyTransfer{token: metadata.Token}
+		if operationIndex > 0 {
+			check.transfer.amount = intent.Operations[operationIndex-1].SolverOutput
+		}
+		destination, err := withdrawDestination(intent, operation)
+		if err != nil {
+			logger.Sugar().Warnw("Failed to derive withdrawal destination for signing policy", "intentID", intent.ID, "opIndex", operationIndex, "error", err)
+			return check
+		}
+		check.transfer.destination = destination
+	}
+	// Bridge deposits, swaps and trust lines do not transfer out of a wallet
+	return check
+}
+
+// enforceSigningPolicy checks an operation against the signing policy and records the
+// amount it transfers towards the daily limits once it is allowed. Every signer of the
+// operation enforces it before joining the signing.
+func enforceSigningPolicy(intent *libs.Intent, operationIndex int, chain blockchains.IBlockchain, signingIdentity string) error {
+	if signingPolicy == nil {
+		return nil
+	}
+
+	policyMu.Lock()
+	defer policyMu.Unlock()
+
+	intentID := intent.ID.String()
+	check := newPolicyCheck(intent, operationIndex, chain, signingIdentity)
+	now := time.Now()
+	// Amounts already recorded for this operation are not counted again when it is retried
+	spent := func(identity string, blockchainID blockchains.BlockchainID, token string, day string) ([]string, error) {
+		return GetPolicySpends(identity, string(blockchainID), token, day, intentID, operationIndex)
+	}
+	if err := signingPolicy.evaluate(check, now, spent); err != nil {
+		var denial *PolicyDenial
+		if errors.As(err, &denial) {
+			logger.Sugar().Warnw("Operation denied by signing policy", "intentID", intentID, "opIndex", operationIndex, "rule", denial.Rule, "reason", denial.Reason)
+			return status.Error(codes.PermissionDenied, denial.Error())
+		}
+		logger.Sugar().Errorw("Failed to evaluate signing policy", "intentID", intentID, "opIndex", operationIndex, "error", err)
+		return status.Errorf(codes.Internal, "failed to evaluate signing policy: %v", err)
+	}
+
+	if check.transfer != nil && check.transfer.amount != "" {
+		err := AddPolicySpend(&PolicySpend{
+			IntentId:       intentID,
+			OperationIndex: operationIndex,
+			Identity:       normaliseAddress(signingIdentity),
+			BlockchainId:   string(check.blockchainID),
+			Token:          normaliseAddress(check.transfer.token),
+			Day:            now.UTC().Format("2006-01-02"),
+			Amount:         check.transfer.amount,
+		})
+		if err != nil {
+			logger.Sugar().Errorw("Failed to record transfer for signing policy", "intentID", intentID, "opIndex", operationIndex, "error", err)
+			return status.Errorf(codes.Internal, "failed to record transfer for signing policy: %v", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/StripChain/strip-node/libs"
+	"github.com/StripChain/strip-node/libs/blockchains"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func signTestPolicy(t *testing.T, policy string) ([]byte, string) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := crypto.Keccak256([]byte("\x19Ethereum Signed Message:\n" + strconv.Itoa(len(policy)) + policy))
+	signature, err := crypto.Sign(hash, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature[64] += 27
+
+	document, err := json.Marshal(SignedPolicy{Policy: json.RawMessage(policy), Signature: hexutil.Encode(signature)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return document, crypto.PubkeyToAddress(key.PublicKey).Hex()
+}
+
+func TestParseSignedPolicy(t *testing.T) {
+	document, signer := signTestPolicy(t, `{"version":1,"maxOperationsPerIntent":2,"dailyLimits":[{"blockchainID":"ETHEREUM","amount":"100"}]}`)
+
+	policy, err := parseSignedPolicy(document, signer)
+	if err != nil {
+		t.Fatalf("parseSignedPolicy: %v", err)
+	}
+	if policy.MaxOperationsPerIntent != 2 || len(policy.DailyLimits) != 1 || policy.DailyLimits[0].amount == nil {
+		t.Errorf("policy = %+v", policy)
+	}
+
+	// Any other signer is rejected
+	_, other := signTestPolicy(t, "{}")
+	if _, err := parseSignedPolicy(document, other); err == nil {
+		t.Error("policy signed by another key was accepted")
+	}
+
+	// Changing the policy invalidates the signature
+	var signed SignedPolicy
+	if err := json.Unmarshal(document, &signed); err != nil {
+		t.Fatal(err)
+	}
+	signed.Policy = json.RawMessage(`{"version":1,"maxOperationsPerIntent":20}`)
+	tampered, _ := json.Marshal(signed)
+	if _, err := parseSignedPolicy(tampered, signer); err == nil {
+		t.Error("tampered policy was accepted")
+	}
+
+	for _, invalid := range []string{
+		`{"version":2}`,
+		`{"version":1,"unknown":true}`,
+		`{"version":1,"dailyLimits":[{"blockchainID":"ETHEREUM","amount":"-1"}]}`,
+		`{"version":1,"hours":{"start":"9am","end":"17:00"}}`,
+		`{"version":1,"hours":{"start":"09:00","end":"17:00","days":["Monday"]}}`,
+	} {
+		document, signer := signTestPolicy(t, invalid)
+		if _, err := parseSignedPolicy(document, signer); err == nil {
+			t.Errorf("invalid policy %s was accepted", invalid)
+		}
+	}
+}
+
+func testPolicy(t *testing.T, policy string) *SigningPolicy {
+	var p SigningPolicy
+	if err := json.Unmarshal([]byte(policy), &p); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.validate(); err != nil {
+		t.Fatal(err)
+	}
+	return &p
+}
+
+func TestSigningPolicyEvaluate(t *testing.T) {
+	policy := testPolicy(t, `{
+		"version": 1,
+		"maxOperationsPerIntent": 3,
+		"deniedChains": ["BITCOIN"],
+		"denylist": ["0x00000000000000000000000000000000000000bb"],
+		"dailyLimits": [
+			{"blockchainID": "ETHEREUM", "amount": "100"},
+			{"identity": "0x000000000000000000000000000000000000AAAA", "blockchainID": "ETHEREUM", "token": "0x00000000000000000000000000000000000000cD", "amount": "10"}
+		]
+	}`)
+	now := time.Date(2025, 3, 4, 12, 0, 0, 0, time.UTC)
+	spent := func(identity string, blockchainID blockchains.BlockchainID, token string, day string) ([]string, error) {
+		if day != "2025-03-04" {
+			return nil, fmt.Errorf("unexpected day %s", day)
+		}
+		if token == "0x00000000000000000000000000000000000000cd" {
+			return []string{"4"}, nil
+		}
+		return []string{"50", "30"}, nil
+	}
+	transfer := func(destination, token, amount string) *policyTransfer {
+		return &policyTransfer{destination: destination, token: token, amount: amount}
+	}
+
+	tests := []struct {
+		name  string
+		check policyCheck
+		rule  string
+	}{
+		{"allowed transfer", policyCheck{"0x000000000000000000000000000000000000aaaa", blockchains.Ethereum, 1, transfer("0x01", "", "20")}, ""},
+		{"too many operations", policyCheck{"0x000000000000000000000000000000000000aaaa", blockchains.Ethereum, 4, nil}, ruleMaxOperationsPerIntent},
+		{"denied chain", policyCheck{"0x000000000000000000000000000000000000aaaa", blockchains.Bitcoin, 1, nil}, ruleDeniedChains},
+		{"denied destination", policyCheck{"0x000000000000000000000000000000000000aaaa", blockchains.Ethereum, 1, transfer("0x00000000000000000000000000000000000000BB", "", "1")}, ruleDenylist},
+		{"unknown destination", policyCheck{"0x000000000000000000000000000000000000aaaa", blockchains.Ethereum, 1, transfer("", "", "1")}, ruleDenylist},
+		{"native limit exceeded", policyCheck{"0x000000000000000000000000000000000000aaaa", blockchains.Ethereum, 1, transfer("0x01", "", "21")}, ruleDailyLimit},
+		{"unknown amount", policyCheck{"0x000000000000000000000000000000000000aaaa", blockchains.Ethereum, 1, transfer("0x01", "", "")}, ruleDailyLimit},
+		{"unknown token and amount", policyCheck{"0x000000000000000000000000000000000000aaaa", blockchains.Ethereum, 1, transfer("0x01", "0xToken", "")}, ruleDailyLimit},
+		{"wallet token limit", policyCheck{"0x000000000000000000000000000000000000AaAa", blockchains.Ethereum, 1, transfer("0x01", "0x00000000000000000000000000000000000000CD", "7")}, ruleDailyLimit},
+		{"token limit of another wallet", policyCheck{"0x000000000000000000000000000000000000bbbb", blockchains.Ethereum, 1, transfer("0x01", "0x00000000000000000000000000000000000000cd", "7")}, ""},
+		{"chain without limits", policyCheck{"0x000000000000000000000000000000000000aaaa", blockchains.Solana, 1, transfer("dest", "", "1000")}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.evaluate(tt.check, now, spent)
+			if tt.rule == "" {
+				if err != nil {
+					t.Fatalf("evaluate = %v, want allowed", err)
+				}
+				return
+			}
+			var denial *PolicyDenial
+			if !errors.As(err, &denial) {
+				t.Fatalf("evaluate = %v, want a denial", err)
+			}
+			if denial.Rule != tt.rule {
+				t.Errorf("rule = %s, want %s", denial.Rule, tt.rule)
+			}
+		})
+	}
+}
+
+func TestSigningPolicyAllowlist(t *testing.T) {
+	policy := testPolicy(t, `{"version": 1, "allowedChains": ["SOLANA"], "allowlist": ["Dest1"]}`)
+	now := time.Now()
+
+	if err := policy.evaluate(policyCheck{"id", blockchains.Solana, 1, &policyTransfer{destination: "Dest1"}}, now, nil); err != nil {
+		t.Errorf("evaluate = %v, want allowed", err)
+	}
+	// Non EVM addresses are case sensitive
+	var denial *PolicyDenial
+	err := policy.evaluate(policyCheck{"id", blockchains.Solana, 1, &policyTransfer{destination: "dest1"}}, now, nil)
+	if !errors.As(err, &denial) || denial.Rule != ruleAllowlist {
+		t.Errorf("evaluate = %v, want an allowlist denial", err)
+	}
+	err = policy.evaluate(policyCheck{"id", blockchains.Ethereum, 1, nil}, now, nil)
+	if !errors.As(err, &denial) || denial.Rule != ruleAllowedChains {
+		t.Errorf("evaluate = %v, want an allowedChains denial", err)
+	}
+	// Operations that are not transactions transfer nothing the lists apply to
+	if err := policy.evaluate(policyCheck{"id", blockchains.Solana, 1, nil}, now, nil); err != nil {
+		t.Errorf("evaluate = %v, want allowed", err)
+	}
+}
+
+func TestSameAddress(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"0x00000000000000000000000000000000000000aB", "0x00000000000000000000000000000000000000Ab", true},
+		{"0x00000000000000000000000000000000000000aB", "0x00000000000000000000000000000000000000ac", false},
+		// Only hex EVM addresses are compared without case
+		{"0xToken", "0xtoken", false},
+		{"rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh", "rhb9cjawyb4rj91vrwn96dkukg4bwdtyth", false},
+		{"Dest1", "Dest1", true},
+	}
+	for _, tt := range tests {
+		if got := sameAddress(tt.a, tt.b); got != tt.want {
+			t.Errorf("sameAddress(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSigningHours(t *testing.T) {
+	office := testPolicy(t, `{"version": 1, "hours": {"location": "America/New_York", "start": "09:00", "end": "17:00", "days": ["Mon", "Tue", "Wed", "Thu", "Fri"]}}`).Hours
+	overnight := testPolicy(t, `{"version": 1, "hours": {"start": "22:00", "end": "06:00"}}`).Hours
+
+	tests := []struct {
+		hours *SigningHours
+		now   string
+		want  bool
+	}{
+		{office, "2025-03-04T14:00:00Z", true},  // Tuesday 09:00 in New York
+		{office, "2025-03-04T13:59:00Z", false}, // Tuesday 08:59 in New York
+		{office, "2025-03-04T22:00:00Z", false}, // Tuesday 17:00 in New York
+		{office, "2025-03-08T15:00:00Z", false}, // Saturday
+		{overnight, "2025-03-04T23:30:00Z", true},
+		{overnight, "2025-03-04T05:59:00Z", true},
+		{overnight, "2025-03-04T06:00:00Z", false},
+		{overnight, "2025-03-04T12:00:00Z", false},
+	}
+
+	for _, tt := range tests {
+		now, err := time.Parse(time.RFC3339, tt.now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := tt.hours.contains(now); got != tt.want {
+			t.Errorf("contains(%s) in %s-%s = %v, want %v", tt.now, tt.hours.Start, tt.hours.End, got, tt.want)
+		}
+	}
+}
+
+func TestNewPolicyCheckBridgeOperations(t *testing.T) {
+	previousBridge, previousDestination := BridgeContractAddress, withdrawDestination
+	defer func() { BridgeContractAddress, withdrawDestination = previousBridge, previousDestination }()
+	BridgeContractAddress = "0xBridge"
+	withdrawDestination = func(intent *libs.Intent, operation libs.Operation) (string, error) {
+		return "0xUser", nil
+	}
+
+	intent := &libs.Intent{Operations: []libs.Operation{
+		{Type: libs.OperationTypeSwap, BlockchainID: blockchains.Ethereum, SolverOutput: "40"},
+		{Type: libs.OperationTypeBurn, BlockchainID: blockchains.Ethereum, SolverMetadata: `{"token": "0xSynthetic"}`, SolverOutput: "40"},
+		{Type: libs.OperationTypeWithdraw, BlockchainID: blockchains.Arbitrum, SolverMetadata: `{"token": "0xToken"}`},
+		{Type: libs.OperationTypeBurnSynthetic, BlockchainID: blockchains.Ethereum, SolverMetadata: `{"token": "0xSynthetic", "amount": "25"}`},
+		{Type: libs.OperationTypeSolver, BlockchainID: blockchains.Ethereum},
+	}}
+
+	tests := []struct {
+		name     string
+		index    int
+		transfer *policyTransfer
+	}{
+		{"swap", 0, nil},
+		{"burn", 1, &policyTransfer{destination: "0xBridge", token: "0xSynthetic", amount: "40"}},
+		{"withdraw", 2, &policyTransfer{destination: "0xUser", token: "0xToken", amount: "40"}},
+		{"burn synthetic", 3, &policyTransfer{destination: "0xBridge", token: "0xSynthetic", amount: "25"}},
+		{"solver", 4, &policyTransfer{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := newPolicyCheck(intent, tt.index, nil, "0xBridge")
+			if check.blockchainID != intent.Operations[tt.index].BlockchainID || check.operationCount != len(intent.Operations) {
+				t.Errorf("check = %+v", check)
+			}
+			if (check.transfer == nil) != (tt.transfer == nil) || (tt.transfer != nil && *check.transfer != *tt.transfer) {
+				t.Errorf("transfer = %+v, want %+v", check.transfer, tt.transfer)
+			}
+		})
+	}
+
+	// Solver operations are denied by any rule on what operations transfer
+	policy := testPolicy(t, `{"version": 1, "allowlist": ["0xBridge"]}`)
+	var denial *PolicyDenial
+	err := policy.evaluate(newPolicyCheck(intent, 4, nil, "0xBridge"), time.Now(), nil)
+	if !errors.As(err, &denial) || denial.Rule != ruleAllowlist {
+		t.Errorf("evaluate = %v, want an allowlist denial", err)
+	}
+	if err := policy.evaluate(newPolicyCheck(intent, 1, nil, "0xBridge"), time.Now(), nil); err != nil {
+		t.Errorf("evaluate = %v, want allowed", err)
+	}
+}
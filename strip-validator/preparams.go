@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/StripChain/strip-node/util/logger"
+	ecdsaKeygen "github.com/bnb-chain/tss-lib/v2/ecdsa/keygen"
+)
+
+// Generating the Paillier keys and safe primes of the ECDSA pre-params takes up to minutes,
+// which used to dominate wallet creation. A background pool keeps pre-params ready, sealed
+// in the database like key shares, and every keygen or resharing takes its own set.
+//
+// tss-lib's GG18 signing has no offline phase, so signatures cannot be presigned and every
+// signing still runs all of its rounds.
+
+const (
+	// preParamsTimeout bounds the generation of one set of pre-params
+	preParamsTimeout = 2 * time.Minute
+	// preParamsCheckInterval is how often the pool is topped up when nothing was taken
+	preParamsCheckInterval = time.Minute
+)
+
+// preParamsTaken wakes the pool up after a set of pre-params was taken
+var preParamsTaken = make(chan struct{}, 1)
+
+// startPreParamsPool keeps size sets of pre-params in the pool, a size of 0 disables it
+func startPreParamsPool(size int) {
+	if size <= 0 {
+		return
+	}
+
+	for {
+		count, err := CountPreParams()
+		if err != nil {
+			logger.Sugar().Errorw("failed to count pre-params", "error", err)
+		}
+
+		if err == nil && count < size {
+			if err := fillPreParams(); err != nil {
+				logger.Sugar().Errorw("failed to add pre-params to the pool", "error", err)
+			} else {
+				logger.Sugar().Infof("added pre-params to the pool, %d of %d", count+1, size)
+				continue
+			}
+		}
+
+		select {
+		case <-preParamsTaken:
+		case <-time.After(preParamsCheckInterval):
+		}
+	}
+}
+
+func fillPreParams() error {
+	preParams, err := ecdsaKeygen.GeneratePreParams(preParamsTimeout)
+	if err != nil {
+		return err
+	}
+
+	out, err := json.Marshal(preParams)
+	if err != nil {
+		return err
+	}
+	return AddPreParams(string(out))
+}
+
+// takePreParams returns pre-params from the pool, generating them when the pool is empty
+func takePreParams() (*ecdsaKeygen.LocalPreParams, error) {
+	select {
+	case preParamsTaken <- struct{}{}:
+	default:
+	}
+
+	preParams, err := pooledPreParams()
+	if err != nil {
+		logger.Sugar().Errorw("failed to take pre-params from the pool, generating them", "error", err)
+	}
+	if preParams != nil {
+		return preParams, nil
+	}
+
+	return ecdsaKeygen.GeneratePreParams(preParamsTimeout)
+}
+
+func pooledPreParams() (*ecdsaKeygen.LocalPreParams, error) {
+	data, err := TakePreParams()
+	if err != nil || data == "" {
+		return nil, err
+	}
+
+	var preParams ecdsaKeygen.LocalPreParams
+	if err := json.Unmarshal([]byte(data), &preParams); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal pre-params: %w", err)
+	}
+	if !preParams.ValidateWithProof() {
+		return nil, fmt.Errorf("pooled pre-params are invalid")
+	}
+	return &preParams, nil
+}
@@ -0,0 +1,374 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/StripChain/strip-node/common"
+	"github.com/StripChain/strip-node/libs"
+	pb "github.com/StripChain/strip-node/libs/proto"
+	"github.com/StripChain/strip-node/util/logger"
+	ecdsaKeygen "github.com/bnb-chain/tss-lib/v2/ecdsa/keygen"
+	eddsaKeygen "github.com/bnb-chain/tss-lib/v2/eddsa/keygen"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Every validator taking part in a signing co-signs a receipt of it once the signature is
+// known, after verifying the signature under its own share of the key, and sends its
+// co-signature to the validator that started the signing. That validator waits a moment
+// for the co-signatures and returns the receipt with the signature to the sequencer.
+
+// receiptTimeout bounds the wait for the co-signatures of a receipt once the signature is known
+const receiptTimeout = 5 * time.Second
+
+// receiptClockTolerance bounds how far the start time of a signing, recorded in its receipt,
+// may be from the clock of a signer joining it
+const receiptClockTolerance = 30 * time.Second
+
+// signingOrigin identifies the intent operation a signing is for and when it started
+type signingOrigin struct {
+	IntentID       string
+	OperationIndex int
+	// Timestamp is the Unix time in seconds the signing started
+	Timestamp int64
+
+	// intent is sent with the signing for the other signers to verify the operation
+	intent *pb.Intent
+}
+
+// pendingReceipt is the receipt state of a signing session
+type pendingReceipt struct {
+	origin      signingOrigin
+	coordinator string
+	// coSigned is set once this node co-signed the receipt
+	coSigned     bool
+	coSignatures map[string][]byte
+	// updated is closed and replaced when a co-signature arrives
+	updated chan struct{}
+}
+
+// receiptBook keeps the receipts of the signing sessions of this node
+type receiptBook struct {
+	mu        sync.Mutex
+	receipts  map[string]*pendingReceipt
+	retention time.Duration
+}
+
+func newReceiptBook(retention time.Duration) *receiptBook {
+	return &receiptBook{receipts: make(map[string]*pendingReceipt), retention: retention}
+}
+
+var receipts = newReceiptBook(sessionTimeout + sessionRetention)
+
+// Begin records who started a signing session and for which operation. Only the first
+// record of a session counts.
+func (b *receiptBook) Begin(sessionID string, coordinator string, origin signingOrigin) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.receipts[sessionID]; ok {
+		return
+	}
+	b.receipts[sessionID] = &pendingReceipt{
+		origin:       origin,
+		coordinator:  coordinator,
+		coSignatures: make(map[string][]byte),
+		updated:      make(chan struct{}),
+	}
+	time.AfterFunc(b.retention, func() {
+		b.mu.Lock()
+		delete(b.receipts, sessionID)
+		b.mu.Unlock()
+	})
+}
+
+// claimCoSign returns the receipt of a session if this node has yet to co-sign it
+func (b *receiptBook) claimCoSign(sessionID string) (pendingReceipt, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	r, ok := b.receipts[sessionID]
+	if !ok || r.coSigned {
+		return pendingReceipt{}, false
+	}
+	r.coSigned = true
+	return *r, true
+}
+
+// Origin returns the operation a session signs for
+func (b *receiptBook) Origin(sessionID string) (signingOrigin, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	r, ok := b.receipts[sessionID]
+	if !ok {
+		return signingOrigin{}, false
+	}
+	return r.origin, true
+}
+
+// AddCoSignature records the co-signature of a signer, for sessions this node started
+func (b *receiptBook) AddCoSignature(sessionID string, signer string, signature []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	r, ok := b.receipts[sessionID]
+	if !ok || r.coordinator != NodePublicKey {
+		return
+	}
+	if _, ok := r.coSignatures[signer]; ok {
+		return
+	}
+	r.coSignatures[signer] = signature
+	close(r.updated)
+	r.updated = make(chan struct{})
+}
+
+// Wait returns the co-signatures of a session once every signer co-signed it or ctx is done
+func (b *receiptBook) Wait(ctx context.Context, sessionID string, signers []string) map[string][]byte {
+	for {
+		b.mu.Lock()
+		r, ok := b.receipts[sessionID]
+		if !ok {
+			b.mu.Unlock()
+			return nil
+		}
+		coSignatures := make(map[string][]byte, len(r.coSignatures))
+		for signer, signature := range r.coSignatures {
+			coSignatures[signer] = signature
+		}
+		updated := r.updated
+		b.mu.Unlock()
+
+		complete := true
+		for _, signer := range signers {
+			if _, ok := coSignatures[signer]; !ok {
+				complete = false
+				break
+			}
+		}
+		if complete {
+			return coSignatures
+		}
+
+		select {
+		case <-updated:
+		case <-ctx.Done():
+			return coSignatures
+		}
+	}
+}
+
+// checkSigningTimestamp checks that a signing started within receiptClockTolerance of now
+func checkSigningTimestamp(timestamp int64, now time.Time) error {
+	started := time.Unix(timestamp, 0)
+	if started.Before(now.Add(-receiptClockTolerance)) || started.After(now.Add(receiptClockTolerance)) {
+		return fmt.Errorf("signing started at %s, not within %s of %s", started.UTC().Format(time.RFC3339), receiptClockTolerance, now.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// rawSignature returns a TSS signature as recorded in receipts
+func rawSignature(signature []byte, recovery []byte) []byte {
+	return append(append([]byte{}, signature...), recovery...)
+}
+
+// signingGroupKey returns the public key a signing verifies under, the child key of the
+// wallet at derivationPath
+func signingGroupKey(identity string, identityCurve common.Curve, keyCurve common.Curve, derivationPath string) ([]byte, error) {
+	keyShare, err := GetKeyShare(identity, identityCurve, keyCurve)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key share: %w", err)
+	}
+	if keyShare == "" {
+		return nil, errNoKeyShare
+	}
+	path, err := parseDerivationPath(derivationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	switch keyCurve {
+	case common.CurveEcdsa:
+		var key ecdsaKeygen.LocalPartySaveData
+		if err := json.Unmarshal([]byte(keyShare), &key); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal key share: %w", err)
+		}
+		if _, err := deriveEcdsaKey(&key, path); err != nil {
+			return nil, err
+		}
+		return getCompressedPublicKeyBytes(&key)
+	case common.CurveEddsa, common.CurveFrost:
+		var key eddsaKeygen.LocalPartySaveData
+		if err := json.Unmarshal([]byte(keyShare), &key); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal key share: %w", err)
+		}
+		if err := deriveEddsaKey(&key, path); err != nil {
+			return nil, err
+		}
+		return eddsaPublicKeyBytes(key.EDDSAPub), nil
+	default:
+		return nil, fmt.Errorf("invalid key curve: %s", keyCurve)
+	}
+}
+
+// verifyRawSignature checks a TSS signature of hash under the group key, hash being the
+// message as the signing parties received it
+func verifyRawSignature(keyCurve common.Curve, groupKey []byte, hash []byte, signature []byte) error {
+	switch keyCurve {
+	case common.CurveEcdsa:
+		digest, ok := new(big.Int).SetString(string(hash), 16)
+		if !ok {
+			return errors.New("invalid ECDSA message")
+		}
+		if len(signature) != 65 {
+			return errors.New("invalid ECDSA signature length")
+		}
+		pub, err := crypto.SigToPub(digest.FillBytes(make([]byte, 32)), signature)
+		if err != nil {
+			return fmt.Errorf("invalid ECDSA signature: %w", err)
+		}
+		if hex.EncodeToString(crypto.CompressPubkey(pub)) != hex.EncodeToString(groupKey) {
+			return errors.New("ECDSA signature is not made by the group key")
+		}
+		return nil
+	case common.CurveEddsa:
+		// The parties sign the message as a number, without its leading zeros
+		message := new(big.Int).SetBytes(hash).Bytes()
+		if len(groupKey) != ed25519.PublicKeySize || !ed25519.Verify(groupKey, message, signature) {
+			return errors.New("EDDSA signature is not made by the group key")
+		}
+		return nil
+	case common.CurveFrost:
+		// FROST signs the message as is
+		if len(groupKey) != ed25519.PublicKeySize || !ed25519.Verify(groupKey, hash, signature) {
+			return errors.New("FROST signature is not made by the group key")
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid key curve: %s", keyCurve)
+	}
+}
+
+// buildReceipt returns the receipt of a signature, without co-signatures, after verifying
+// the signature under this node's share of the key
+func buildReceipt(origin signingOrigin, result Message) (*pb.SigningReceipt, error) {
+	signersString, err := GetSignersForKeyShare(result.Identity, result.IdentityCurve, result.KeyCurve)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signers: %w", err)
+	}
+	signers := []string{}
+	if err := json.Unmarshal([]byte(signersString), &signers); err != nil {
+		return nil, fmt.Errorf("failed to parse signers: %w", err)
+	}
+
+	groupKey, err := signingGroupKey(result.Identity, result.IdentityCurve, result.KeyCurve, result.DerivationPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyRawSignature(result.KeyCurve, groupKey, result.Hash, result.RawSignature); err != nil {
+		return nil, err
+	}
+
+	messageHash := sha256.Sum256(result.Hash)
+	return &pb.SigningReceipt{
+		SessionId:      result.SessionID,
+		IntentId:       origin.IntentID,
+		OperationIndex: uint32(origin.OperationIndex),
+		MessageHash:    hex.EncodeToString(messageHash[:]),
+		Signers:        signers,
+		Signature:      hex.EncodeToString(result.RawSignature),
+		GroupPublicKey: hex.EncodeToString(groupKey),
+		Timestamp:      origin.Timestamp,
+	}, nil
+}
+
+// coSignReceipt co-signs the receipt of a signature this node took part in and sends the
+// co-signature to the validator that started the signing
+func coSignReceipt(result Message) {
+	r, ok := receipts.claimCoSign(result.SessionID)
+	if !ok {
+		return
+	}
+
+	receipt, err := buildReceipt(r.origin, result)
+	if errors.Is(err, errNoKeyShare) {
+		return
+	}
+	if err != nil {
+		logger.Sugar().Errorw("failed to build signing receipt", "session", result.SessionID, "error", err)
+		return
+	}
+	digest, err := libs.SigningReceiptDigest(receipt)
+	if err != nil {
+		logger.Sugar().Errorw("failed to build signing receipt", "session", result.SessionID, "error", err)
+		return
+	}
+	privateKey, err := nodeKey()
+	if err != nil {
+		logger.Sugar().Errorw("failed to read the node key", "error", err)
+		return
+	}
+	signature, err := crypto.Sign(digest, privateKey)
+	if err != nil {
+		logger.Sugar().Errorw("failed to co-sign signing receipt", "session", result.SessionID, "error", err)
+		return
+	}
+
+	if r.coordinator == NodePublicKey {
+		receipts.AddCoSignature(result.SessionID, NodePublicKey, signature)
+		return
+	}
+	broadcast(Message{
+		SessionID:      result.SessionID,
+		Type:           MESSAGE_TYPE_RECEIPT,
+		Hash:           result.Hash,
+		Identity:       result.Identity,
+		IdentityCurve:  result.IdentityCurve,
+		KeyCurve:       result.KeyCurve,
+		BlockchainID:   result.BlockchainID,
+		DerivationPath: result.DerivationPath,
+		Message:        signature,
+		recipient:      r.coordinator,
+	})
+}
+
+// collectReceipt returns the receipt of a signing this node started, with the
+// co-signatures received within receiptTimeout. Co-signatures that do not verify are left out.
+func collectReceipt(ctx context.Context, result Message) (*pb.SigningReceipt, error) {
+	origin, ok := receipts.Origin(result.SessionID)
+	if !ok {
+		return nil, fmt.Errorf("no receipt for session %s", result.SessionID)
+	}
+	receipt, err := buildReceipt(origin, result)
+	if err != nil {
+		return nil, err
+	}
+	digest, err := libs.SigningReceiptDigest(receipt)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, receiptTimeout)
+	defer cancel()
+	coSignatures := receipts.Wait(ctx, result.SessionID, receipt.Signers)
+	for _, signer := range receipt.Signers {
+		signature, ok := coSignatures[signer]
+		if !ok {
+			continue
+		}
+		if coSigner, err := libs.ReceiptSigner(digest, signature); err != nil || coSigner != signer {
+			logger.Sugar().Warnw("dropping invalid receipt co-signature", "session", result.SessionID, "signer", signer, "error", err)
+			continue
+		}
+		receipt.CoSignatures = append(receipt.CoSignatures, &pb.ReceiptCoSignature{Signer: signer, Signature: signature})
+	}
+	if len(receipt.CoSignatures) < len(receipt.Signers) {
+		logger.Sugar().Warnw("signing receipt is missing co-signatures", "session", result.SessionID, "coSignatures", len(receipt.CoSignatures), "signers", len(receipt.Signers))
+	}
+	return receipt, nil
+}
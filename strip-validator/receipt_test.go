@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/StripChain/strip-node/common"
+	"github.com/StripChain/strip-node/libs"
+	pb "github.com/StripChain/strip-node/libs/proto"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestReceiptBook(t *testing.T) {
+	book := newReceiptBook(time.Minute)
+	origin := signingOrigin{IntentID: "intent", OperationIndex: 2}
+
+	book.Begin("s1", NodePublicKey, origin)
+	// A later record of the session, e.g. the START_SIGN of this node, does not replace it
+	book.Begin("s1", "0xother", signingOrigin{})
+	if got, ok := book.Origin("s1"); !ok || got != origin {
+		t.Errorf("Origin = %v, %v, want %v", got, ok, origin)
+	}
+
+	if _, ok := book.claimCoSign("s1"); !ok {
+		t.Error("claimCoSign should succeed once")
+	}
+	if _, ok := book.claimCoSign("s1"); ok {
+		t.Error("claimCoSign should succeed only once")
+	}
+
+	go func() {
+		book.AddCoSignature("s1", "0xa", []byte{1})
+		book.AddCoSignature("s1", "0xb", []byte{2})
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	coSignatures := book.Wait(ctx, "s1", []string{"0xa", "0xb"})
+	if len(coSignatures) != 2 {
+		t.Errorf("Wait = %v, want both co-signatures", coSignatures)
+	}
+
+	// Only the validator that started a signing collects its co-signatures
+	book.Begin("s2", "0xother", origin)
+	book.AddCoSignature("s2", "0xa", []byte{1})
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if coSignatures := book.Wait(ctx, "s2", []string{"0xa"}); len(coSignatures) != 0 {
+		t.Errorf("Wait = %v, want no co-signatures", coSignatures)
+	}
+}
+
+func TestVerifyRawSignature(t *testing.T) {
+	ecdsaKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := crypto.Keccak256([]byte("transaction"))
+	signature, err := crypto.Sign(digest, ecdsaKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// ECDSA parties receive the digest in hex
+	hash := []byte(hex.EncodeToString(digest))
+	groupKey := crypto.CompressPubkey(&ecdsaKey.PublicKey)
+	if err := verifyRawSignature(common.CurveEcdsa, groupKey, hash, signature); err != nil {
+		t.Errorf("verifyRawSignature = %v, want a valid ECDSA signature", err)
+	}
+	other, _ := crypto.GenerateKey()
+	if err := verifyRawSignature(common.CurveEcdsa, crypto.CompressPubkey(&other.PublicKey), hash, signature); err == nil {
+		t.Error("verifyRawSignature should fail for another key")
+	}
+
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := []byte("transaction")
+	if err := verifyRawSignature(common.CurveEddsa, public, message, ed25519.Sign(private, message)); err != nil {
+		t.Errorf("verifyRawSignature = %v, want a valid EdDSA signature", err)
+	}
+	if err := verifyRawSignature(common.CurveEddsa, public, []byte("other"), ed25519.Sign(private, message)); err == nil {
+		t.Error("verifyRawSignature should fail for another message")
+	}
+}
+
+func TestReceiptCoSignatures(t *testing.T) {
+	keys := make(map[string]func([]byte) []byte)
+	signers := []string{}
+	for i := 0; i < 2; i++ {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		signer := hexutil.Encode(crypto.CompressPubkey(&key.PublicKey)[1:])
+		signers = append(signers, signer)
+		keys[signer] = func(digest []byte) []byte {
+			signature, err := crypto.Sign(digest, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return signature
+		}
+	}
+
+	receipt := &pb.SigningReceipt{SessionId: "s1", IntentId: "intent", MessageHash: "00", Signers: signers, Signature: "01", GroupPublicKey: "02", Timestamp: 1_700_000_000}
+	digest, err := libs.SigningReceiptDigest(receipt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, signer := range signers {
+		receipt.CoSignatures = append(receipt.CoSignatures, &pb.ReceiptCoSignature{Signer: signer, Signature: keys[signer](digest)})
+	}
+	if coSigners, err := libs.VerifySigningReceipt(receipt); err != nil || len(coSigners) != 2 {
+		t.Errorf("VerifySigningReceipt = %v, %v, want both signers", coSigners, err)
+	}
+
+	// Altering the receipt invalidates the co-signatures
+	altered := proto.Clone(receipt).(*pb.SigningReceipt)
+	altered.Signature = "03"
+	if _, err := libs.VerifySigningReceipt(altered); err == nil {
+		t.Error("VerifySigningReceipt should fail for an altered receipt")
+	}
+	altered = proto.Clone(receipt).(*pb.SigningReceipt)
+	altered.Timestamp++
+	if _, err := libs.VerifySigningReceipt(altered); err == nil {
+		t.Error("VerifySigningReceipt should fail for an altered timestamp")
+	}
+}
+
+func TestCheckSigningTimestamp(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	for _, tt := range []struct {
+		offset time.Duration
+		ok     bool
+	}{
+		{0, true},
+		{-receiptClockTolerance, true},
+		{receiptClockTolerance, true},
+		{-receiptClockTolerance - time.Second, false},
+		{receiptClockTolerance + time.Second, false},
+	} {
+		err := checkSigningTimestamp(now.Add(tt.offset).Unix(), now)
+		if (err == nil) != tt.ok {
+			t.Errorf("checkSigningTimestamp(now%+v) = %v, want ok %v", tt.offset, err, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,519 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/StripChain/strip-node/common"
+	"github.com/StripChain/strip-node/libs"
+	"github.com/StripChain/strip-node/util/logger"
+	ecdsaKeygen "github.com/bnb-chain/tss-lib/v2/ecdsa/keygen"
+	ecdsaResharing "github.com/bnb-chain/tss-lib/v2/ecdsa/resharing"
+	eddsaKeygen "github.com/bnb-chain/tss-lib/v2/eddsa/keygen"
+	eddsaResharing "github.com/bnb-chain/tss-lib/v2/eddsa/resharing"
+	"github.com/bnb-chain/tss-lib/v2/tss"
+	"github.com/decred/dcrd/dcrec/edwards/v2"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Resharing moves the key shares of a wallet from its committee of signers to a new one.
+// The new shares reconstruct the same secret, so the public key and every address derived
+// from it stay the same, while the old shares can no longer be combined with the new ones.
+//
+// tss-lib requires the party keys of the two committees to be disjoint, so the new
+// committee takes the keys following the largest old key. Signing rebuilds the parties
+// from the keys saved with the share (see getPartiesFromKeys).
+
+// reshareSession is the state of a resharing run that incoming messages are routed to.
+// A node staying in the committee runs both an old and a new party.
+type reshareSession struct {
+	oldParties tss.SortedPartyIDs
+	newParties tss.SortedPartyIDs
+	oldMembers committee
+	newMembers committee
+	// members are both committees, for blaming culprits of either: the party keys of the
+	// new committee follow those of the old one
+	members  committee
+	oldParty tss.Party
+	newParty tss.Party
+	errCh    chan *tss.Error
+}
+
+// reshareWallets holds the wallets being reshared on this node, only one run of a wallet
+// can replace its key shares at a time
+var reshareWallets sync.Map
+
+func generateReshareMessage(sessionID string, identity string, identityCurve common.Curve, keyCurve common.Curve, oldSigners []string, oldThreshold int, oldKeys []*big.Int, newSigners []string, newThreshold int, publicKey string) {
+	message := Message{
+		SessionID:     sessionID,
+		Type:          MESSAGE_TYPE_START_RESHARE,
+		Identity:      identity,
+		IdentityCurve: identityCurve,
+		KeyCurve:      keyCurve,
+		Signers:       oldSigners,
+		Threshold:     oldThreshold,
+		PartyKeys:     oldKeys,
+		NewSigners:    newSigners,
+		NewThreshold:  newThreshold,
+		Address:       publicKey,
+	}
+
+	broadcast(message)
+}
+
+// reshareOldCommittee returns the indices of the old signers that run the old side of the
+// protocol. The signers staying in the committee suffice when they meet the old threshold,
+// so departing signers never have to take part; otherwise every old signer does.
+func reshareOldCommittee(oldSigners []string, oldThreshold int, newSigners []string) []int {
+	staying := []int{}
+	for i, signer := range oldSigners {
+		if SliceContainsString(newSigners, signer) {
+			staying = append(staying, i)
+		}
+	}
+	if len(staying) >= oldThreshold+1 {
+		return staying
+	}
+
+	all := make([]int, len(oldSigners))
+	for i := range all {
+		all[i] = i
+	}
+	return all
+}
+
+// reshareNewKeys returns the party keys of the new committee, following the largest old key
+func reshareNewKeys(oldKeys []*big.Int, count int) []*big.Int {
+	next := big.NewInt(0)
+	for _, key := range oldKeys {
+		if key.Cmp(next) > 0 {
+			next.Set(key)
+		}
+	}
+
+	keys := make([]*big.Int, count)
+	for i := range keys {
+		next = new(big.Int).Add(next, big.NewInt(1))
+		keys[i] = next
+	}
+	return keys
+}
+
+func validateCommittee(signers []string) error {
+	if len(signers) == 0 {
+		return errors.New("committee has no signers")
+	}
+	if len(signers) > MaximumSigners {
+		return fmt.Errorf("committee has %d signers, the maximum is %d", len(signers), MaximumSigners)
+	}
+	for i, signer := range signers {
+		if SliceIndexOfString(signers, signer) != i {
+			return fmt.Errorf("signer %s appears twice in the committee", signer)
+		}
+	}
+	return nil
+}
+
+// ecdsaSharePublicKey returns the compressed public key of an ECDSA key share as hex
+func ecdsaSharePublicKey(save *ecdsaKeygen.LocalPartySaveData) string {
+	return hex.EncodeToString(crypto.CompressPubkey(save.ECDSAPub.ToECDSAPubKey()))
+}
+
+// eddsaSharePublicKey returns the public key of an EdDSA key share as hex
+func eddsaSharePublicKey(save *eddsaKeygen.LocalPartySaveData) string {
+	pk := edwards.PublicKey{
+		Curve: tss.Edwards(),
+		X:     save.EDDSAPub.X(),
+		Y:     save.EDDSAPub.Y(),
+	}
+	return hex.EncodeToString(pk.Serialize())
+}
+
+func sameKeys(a []*big.Int, b []*big.Int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] == nil || b[i] == nil || a[i].Cmp(b[i]) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func generateReshare(sessionID string, identity string, identityCurve common.Curve, keyCurve common.Curve, oldSigners []string, oldThreshold int, oldKeys []*big.Int, newSigners []string, newThreshold int, publicKey string) {
+	key := identity + "_" + string(identityCurve) + "_" + string(keyCurve)
+
+	if !SliceContainsString(oldSigners, NodePublicKey) && !SliceContainsString(newSigners, NodePublicKey) {
+		return
+	}
+
+	err := runReshare(sessionID, identity, identityCurve, keyCurve, oldSigners, oldThreshold, oldKeys, newSigners, newThreshold, publicKey)
+	if err != nil {
+		logger.Sugar().Errorw("resharing failed", "key", key, "session", sessionID, "error", err)
+		sessions.Fail(sessionID, err)
+		return
+	}
+
+	logger.Sugar().Infof("completed resharing of %s", key)
+	sessions.Complete(sessionID, Message{})
+}
+
+func runReshare(sessionID string, identity string, identityCurve common.Curve, keyCurve common.Curve, oldSigners []string, oldThreshold int, oldKeys []*big.Int, newSigners []string, newThreshold int, publicKey string) error {
+	key := identity + "_" + string(identityCurve) + "_" + string(keyCurve)
+
+	if len(oldKeys) != len(oldSigners) {
+		return fmt.Errorf("got %d party keys for %d old signers", len(oldKeys), len(oldSigners))
+	}
+	if err := validateCommittee(newSigners); err != nil {
+		return err
+	}
+	if err := libs.ValidateThresholdPolicy(oldThreshold, len(oldSigners), len(oldSigners)); err != nil {
+		return fmt.Errorf("invalid old threshold: %w", err)
+	}
+	if err := libs.ValidateThresholdPolicy(newThreshold, len(newSigners), MaximumSigners); err != nil {
+		return fmt.Errorf("invalid new threshold: %w", err)
+	}
+	if _, running := reshareWallets.LoadOrStore(key, sessionID); running {
+		return errors.New("resharing is already running")
+	}
+	defer reshareWallets.Delete(key)
+
+	oldCommittee := reshareOldCommittee(oldSigners, oldThreshold, newSigners)
+	oldIds := []*tss.PartyID{}
+	oldMembers := []string{}
+	var oldPartyId *tss.PartyID
+	for _, i := range oldCommittee {
+		party := tss.NewPartyID(oldKeys[i].String(), "", new(big.Int).Set(oldKeys[i]))
+		oldIds = append(oldIds, party)
+		oldMembers = append(oldMembers, oldSigners[i])
+		if oldSigners[i] == NodePublicKey {
+			oldPartyId = party
+		}
+	}
+	oldParties := tss.SortPartyIDs(oldIds)
+
+	newParties, newIds := getPartiesFromKeys(reshareNewKeys(oldKeys, len(newSigners)))
+	var newPartyId *tss.PartyID
+	if index := SliceIndexOfString(newSigners, NodePublicKey); index != -1 {
+		newPartyId = newIds[index]
+	}
+
+	if oldPartyId == nil && newPartyId == nil {
+		// A departing signer that the remaining committee does not need
+		return nil
+	}
+
+	oldCtx := tss.NewPeerContext(oldParties)
+	newCtx := tss.NewPeerContext(newParties)
+
+	session := &reshareSession{
+		oldParties: oldParties,
+		newParties: newParties,
+		oldMembers: committee{sessionID: sessionID, ids: oldIds, signers: oldMembers},
+		newMembers: committee{sessionID: sessionID, ids: newIds, signers: newSigners},
+		members: committee{
+			sessionID: sessionID,
+			ids:       append(append([]*tss.PartyID{}, oldIds...), newIds...),
+			signers:   append(append([]string{}, oldMembers...), newSigners...),
+		},
+		errCh: make(chan *tss.Error, 2),
+	}
+
+	oldOut := make(chan tss.Message)
+	newOut := make(chan tss.Message)
+	oldEndEcdsa := make(chan *ecdsaKeygen.LocalPartySaveData, 1)
+	newEndEcdsa := make(chan *ecdsaKeygen.LocalPartySaveData, 1)
+	oldEndEddsa := make(chan *eddsaKeygen.LocalPartySaveData, 1)
+	newEndEddsa := make(chan *eddsaKeygen.LocalPartySaveData, 1)
+
+	if oldPartyId != nil {
+		keyShare, err := GetKeyShare(identity, identityCurve, keyCurve)
+		if err != nil {
+			return fmt.Errorf("failed to read key share: %w", err)
+		}
+		if keyShare == "" {
+			return errors.New("key share not found")
+		}
+
+		signersString, err := GetSignersForKeyShare(identity, identityCurve, keyCurve)
+		if err != nil {
+			return fmt.Errorf("failed to read signers: %w", err)
+		}
+		storedSigners := []string{}
+		json.Unmarshal([]byte(signersString), &storedSigners)
+		if !equalSigners(storedSigners, oldSigners) {
+			return errors.New("old signers do not match the signers of the key share")
+		}
+		storedThreshold, err := GetThresholdForKeyShare(identity, identityCurve, keyCurve, len(storedSigners))
+		if err != nil {
+			return fmt.Errorf("failed to read threshold: %w", err)
+		}
+		if storedThreshold != oldThreshold {
+			return errors.New("old threshold does not match the threshold of the key share")
+		}
+
+		switch keyCurve {
+		case common.CurveEcdsa:
+			var save ecdsaKeygen.LocalPartySaveData
+			if err := json.Unmarshal([]byte(keyShare), &save); err != nil {
+				return fmt.Errorf("failed to unmarshal key share: %w", err)
+			}
+			if !sameKeys(save.Ks, oldKeys) {
+				return errors.New("party keys do not match the key share")
+			}
+			if ecdsaSharePublicKey(&save) != publicKey {
+				return errors.New("public key does not match the key share")
+			}
+			// The old side runs with the shares of the signers taking part only
+			params := tss.NewReSharingParameters(tss.S256(), oldCtx, newCtx, oldPartyId, len(oldParties), oldThreshold, len(newSigners), newThreshold)
+			session.oldParty = ecdsaResharing.NewLocalParty(params, ecdsaKeygen.BuildLocalSaveDataSubset(save, oldParties), oldOut, oldEndEcdsa)
+		case common.CurveEddsa, common.CurveFrost:
+			var save eddsaKeygen.LocalPartySaveData
+			if err := json.Unmarshal([]byte(keyShare), &save); err != nil {
+				return fmt.Errorf("failed to unmarshal key share: %w", err)
+			}
+			if !sameKeys(save.Ks, oldKeys) {
+				return errors.New("party keys do not match the key share")
+			}
+			if eddsaSharePublicKey(&save) != publicKey {
+				return errors.New("public key does not match the key share")
+			}
+			params := tss.NewReSharingParameters(tss.Edwards(), oldCtx, newCtx, oldPartyId, len(oldParties), oldThreshold, len(newSigners), newThreshold)
+			session.oldParty = eddsaResharing.NewLocalParty(params, eddsaKeygen.BuildLocalSaveDataSubset(save, oldParties), oldOut, oldEndEddsa)
+		default:
+			return fmt.Errorf("invalid key curve: %s", keyCurve)
+		}
+	}
+
+	if newPartyId != nil {
+		switch keyCurve {
+		case common.CurveEcdsa:
+			preParams, err := takePreParams()
+			if err != nil {
+				return fmt.Errorf("failed to generate pre-params: %w", err)
+			}
+			save := ecdsaKeygen.NewLocalPartySaveData(len(newSigners))
+			save.LocalPreParams = *preParams
+			params := tss.NewReSharingParameters(tss.S256(), oldCtx, newCtx, newPartyId, len(oldParties), oldThreshold, len(newSigners), newThreshold)
+			session.newParty = ecdsaResharing.NewLocalParty(params, save, newOut, newEndEcdsa)
+		case common.CurveEddsa, common.CurveFrost:
+			save := eddsaKeygen.NewLocalPartySaveData(len(newSigners))
+			params := tss.NewReSharingParameters(tss.Edwards(), oldCtx, newCtx, newPartyId, len(oldParties), oldThreshold, len(newSigners), newThreshold)
+			session.newParty = eddsaResharing.NewLocalParty(params, save, newOut, newEndEddsa)
+		default:
+			return fmt.Errorf("invalid key curve: %s", keyCurve)
+		}
+	}
+
+	participants := append([]string{}, oldSigners...)
+	for _, signer := range newSigners {
+		if SliceIndexOfString(oldSigners, signer) < 0 {
+			participants = append(participants, signer)
+		}
+	}
+	sessions.Describe(sessionID, SessionInfo{Type: SessionTypeReshare, Identity: identity, KeyCurve: keyCurve, Participants: participants}, session.oldParty, session.newParty)
+	reshare, err := sessions.Start(sessionID, session.handle)
+	if err != nil {
+		return err
+	}
+
+	pending := 0
+	for _, party := range []tss.Party{session.newParty, session.oldParty} {
+		if party == nil {
+			continue
+		}
+		pending++
+		go func(party tss.Party) {
+			if err := party.Start(); err != nil {
+				session.errCh <- err
+			}
+		}(party)
+	}
+
+	var newShare string
+	for pending > 0 {
+		select {
+		case msg := <-oldOut:
+			if err := session.publish(sessionID, identity, identityCurve, keyCurve, oldSigners, newSigners, msg, false); err != nil {
+				return err
+			}
+		case msg := <-newOut:
+			if err := session.publish(sessionID, identity, identityCurve, keyCurve, oldSigners, newSigners, msg, true); err != nil {
+				return err
+			}
+		case <-oldEndEcdsa:
+			pending--
+		case <-oldEndEddsa:
+			pending--
+		case save := <-newEndEcdsa:
+			if ecdsaSharePublicKey(save) != publicKey {
+				return errors.New("reshared key share has a different public key")
+			}
+			out, err := json.Marshal(save)
+			if err != nil {
+				return fmt.Errorf("failed to marshal key share: %w", err)
+			}
+			newShare = string(out)
+			pending--
+		case save := <-newEndEddsa:
+			if eddsaSharePublicKey(save) != publicKey {
+				return errors.New("reshared key share has a different public key")
+			}
+			out, err := json.Marshal(save)
+			if err != nil {
+				return fmt.Errorf("failed to marshal key share: %w", err)
+			}
+			newShare = string(out)
+			pending--
+		case err := <-session.errCh:
+			return session.members.blame(err)
+		case <-reshare.Done():
+			return sessionError(sessionID)
+		}
+	}
+
+	if newPartyId == nil {
+		return DeleteKeyShare(identity, identityCurve, keyCurve)
+	}
+
+	signersOut, err := json.Marshal(newSigners)
+	if err != nil {
+		return fmt.Errorf("failed to marshal signers: %w", err)
+	}
+	if err := ReplaceKeyShare(identity, identityCurve, keyCurve, newShare, string(signersOut), newThreshold); err != nil {
+		return err
+	}
+	backupKeyShareIncrement(identity, identityCurve, keyCurve)
+	return nil
+}
+
+// publish broadcasts a message of a local party, encrypting it to its recipient when it
+// is addressed to a single party
+func (session *reshareSession) publish(sessionID string, identity string, identityCurve common.Curve, keyCurve common.Curve, oldSigners []string, newSigners []string, msg tss.Message, fromNewCommittee bool) error {
+	bytes, _, err := msg.WireBytes()
+	if err != nil {
+		return fmt.Errorf("failed to encode resharing message: %w", err)
+	}
+
+	// Broadcasts address a whole committee, anything else goes to a single party
+	to := -1
+	var dest *tss.PartyID
+	if recipients := msg.GetTo(); !msg.IsBroadcast() && len(recipients) > 0 {
+		dest = recipients[0]
+		to = dest.Index
+	}
+
+	message := Message{
+		SessionID:          sessionID,
+		Type:               MESSAGE_TYPE_RESHARE,
+		From:               msg.GetFrom().Index,
+		To:                 to,
+		Message:            bytes,
+		IsBroadcast:        msg.IsBroadcast(),
+		IsToOldCommittee:   msg.IsToOldCommittee() || msg.IsToOldAndNewCommittees(),
+		IsToNewCommittee:   !msg.IsToOldCommittee() || msg.IsToOldAndNewCommittees(),
+		IsFromNewCommittee: fromNewCommittee,
+		Identity:           identity,
+		IdentityCurve:      identityCurve,
+		KeyCurve:           keyCurve,
+		Signers:            oldSigners,
+		NewSigners:         newSigners,
+	}
+
+	if dest != nil {
+		members := session.newMembers
+		if msg.IsToOldCommittee() {
+			members = session.oldMembers
+		}
+		if err := sealMessage(&message, members.signerOf(dest)); err != nil {
+			return fmt.Errorf("failed to encrypt resharing message to party %d: %w", to, err)
+		}
+	}
+
+	go broadcast(message)
+	return nil
+}
+
+func updateReshare(msg Message) {
+	if !SliceContainsString(msg.Signers, NodePublicKey) && !SliceContainsString(msg.NewSigners, NodePublicKey) {
+		return
+	}
+
+	sessions.Deliver(msg)
+}
+
+// handle routes a resharing message to the local parties it is addressed to. Like
+// partyHandler, a party only speaks for the signer behind it in its committee.
+func (session *reshareSession) handle(msg Message) error {
+	senders, members := session.oldParties, session.oldMembers
+	if msg.IsFromNewCommittee {
+		senders, members = session.newParties, session.newMembers
+	}
+	if msg.From < 0 || msg.From >= len(senders) {
+		return fmt.Errorf("resharing message from unknown party %d", msg.From)
+	}
+	from := senders[msg.From]
+
+	if signer := members.signerOf(from); msg.sender != "" && msg.sender != signer {
+		logger.Sugar().Warnw("dropping resharing message sent for another party", "session", msg.SessionID, "from", msg.From, "fromNewCommittee", msg.IsFromNewCommittee, "sender", msg.sender)
+		return nil
+	}
+
+	if msg.IsToOldCommittee && session.oldParty != nil {
+		if err := session.deliver(session.oldParty, false, msg, from); err != nil {
+			return err
+		}
+	}
+	if msg.IsToNewCommittee && session.newParty != nil {
+		if err := session.deliver(session.newParty, true, msg, from); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (session *reshareSession) deliver(party tss.Party, isNewCommittee bool, msg Message, from *tss.PartyID) error {
+	if msg.To != -1 && msg.To != party.PartyID().Index {
+		return nil
+	}
+
+	// Our own messages come back through pubsub
+	if isNewCommittee == msg.IsFromNewCommittee && party.PartyID().Index == msg.From {
+		return nil
+	}
+
+	payload, err := openMessage(msg)
+	if err != nil {
+		return session.members.blame(tss.NewError(fmt.Errorf("failed to open resharing message: %w", err), "open", -1, party.PartyID(), from))
+	}
+
+	pMsg, err := tss.ParseWireMessage(payload, from, msg.IsBroadcast)
+	if err != nil {
+		return session.members.blame(tss.NewError(fmt.Errorf("failed to parse resharing message: %w", err), "parse", -1, party.PartyID(), from))
+	}
+
+	ok, tssErr := party.Update(pMsg)
+	if tssErr != nil {
+		return session.members.blame(tssErr)
+	}
+
+	logger.Sugar().Infof("processed resharing message with status: %v", ok)
+	return nil
+}
+
+func equalSigners(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
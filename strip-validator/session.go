@@ -0,0 +1,406 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/StripChain/strip-node/common"
+	"github.com/StripChain/strip-node/util/logger"
+	"github.com/bnb-chain/tss-lib/v2/tss"
+	"github.com/google/uuid"
+)
+
+// Sessions track the TSS protocol runs of this node. The node starting a run picks its
+// session ID and every message of the run carries it, so concurrent runs for the same
+// wallet, such as two signings, never share state. Messages arriving before the local
+// party is ready are buffered and delivered as soon as it is registered.
+
+const (
+	// sessionTimeout bounds a whole protocol run, including the wait for the local party
+	sessionTimeout = 5 * time.Minute
+	// sessionRetention is how long a finished session is kept for status queries
+	sessionRetention = 10 * time.Minute
+	// maxBufferedMessages bounds the messages kept for a session without a local party
+	maxBufferedMessages = 128
+)
+
+type SessionStatus string
+
+const (
+	SessionStatusPending   SessionStatus = "pending"
+	SessionStatusRunning   SessionStatus = "running"
+	SessionStatusCompleted SessionStatus = "completed"
+	SessionStatusFailed    SessionStatus = "failed"
+)
+
+var (
+	ErrSessionTimeout   = errors.New("session timed out")
+	ErrSessionCancelled = errors.New("session cancelled")
+	ErrSessionNotFound  = errors.New("session not found")
+
+	// errSessionCompleted is returned to a local party whose session another party
+	// completed first, e.g. by broadcasting the signature
+	errSessionCompleted = errors.New("session completed by another party")
+)
+
+// Session types reported by the admin API
+const (
+	SessionTypeKeygen  = "keygen"
+	SessionTypeSigning = "signing"
+	SessionTypeReshare = "reshare"
+)
+
+// SessionInfo describes what a session runs, for operators
+type SessionInfo struct {
+	Type         string
+	Identity     string
+	KeyCurve     common.Curve
+	Participants []string
+	// parties are the local parties of the run, whose rounds give its progress
+	parties []tss.Party
+}
+
+// SessionSnapshot is the state of a session at one point in time
+type SessionSnapshot struct {
+	ID        string
+	Status    SessionStatus
+	Info      SessionInfo
+	Round     int
+	StartedAt time.Time
+	Err       error
+}
+
+// Session is a protocol run. Its context is cancelled once the run finishes, fails or
+// times out, which stops the goroutine driving the local party.
+type Session struct {
+	ID     string
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+	// created is when this node first heard of the session
+	created time.Time
+
+	// guarded by SessionManager.mu
+	info    SessionInfo
+	status  SessionStatus
+	handler func(Message) error
+	buffer  []Message
+	result  Message
+	err     error
+}
+
+func (s *Session) Context() context.Context {
+	return s.ctx
+}
+
+// Done is closed when the session has finished
+func (s *Session) Done() <-chan struct{} {
+	return s.done
+}
+
+// SessionManager is the registry of the sessions of this node
+type SessionManager struct {
+	mu        sync.Mutex
+	sessions  map[string]*Session
+	timeout   time.Duration
+	retention time.Duration
+}
+
+func NewSessionManager(timeout time.Duration, retention time.Duration) *SessionManager {
+	return &SessionManager{
+		sessions:  make(map[string]*Session),
+		timeout:   timeout,
+		retention: retention,
+	}
+}
+
+var sessions = NewSessionManager(sessionTimeout, sessionRetention)
+
+func newSessionID() string {
+	return uuid.New().String()
+}
+
+// session returns the session with the given ID, creating a pending one. m.mu must be held.
+func (m *SessionManager) session(id string) *Session {
+	if s, ok := m.sessions[id]; ok {
+		return s
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+	s := &Session{
+		ID:      id,
+		ctx:     ctx,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+		created: time.Now(),
+		status:  SessionStatusPending,
+	}
+	m.sessions[id] = s
+
+	go func() {
+		<-ctx.Done()
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			m.finish(id, Message{}, ErrSessionTimeout)
+		}
+		time.AfterFunc(m.retention, func() {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			if m.sessions[id] == s {
+				delete(m.sessions, id)
+			}
+		})
+	}()
+
+	return s
+}
+
+// Expect registers a session this node waits for without necessarily taking part in it.
+// It must be called before the run is started so that its outcome cannot be missed.
+func (m *SessionManager) Expect(id string) *Session {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.session(id)
+}
+
+// Start registers the handler the messages of a session are delivered to, usually the
+// update of the local party, and delivers the messages buffered so far
+func (m *SessionManager) Start(id string, handler func(Message) error) (*Session, error) {
+	m.mu.Lock()
+	s := m.session(id)
+	if s.status != SessionStatusPending {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("session %s is already %s", id, s.status)
+	}
+	if s.handler != nil {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("session %s is already started", id)
+	}
+	s.handler = handler
+	s.status = SessionStatusRunning
+	buffered := s.buffer
+	s.buffer = nil
+	m.mu.Unlock()
+
+	for _, msg := range buffered {
+		m.handle(s, handler, msg)
+	}
+	return s, nil
+}
+
+// Describe records what a session runs. parties are the local parties of the run, if any.
+func (m *SessionManager) Describe(id string, info SessionInfo, parties ...tss.Party) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	info.parties = parties
+	m.session(id).info = info
+}
+
+// List returns a snapshot of every session, oldest first
+func (m *SessionManager) List() []SessionSnapshot {
+	m.mu.Lock()
+	snapshots := make([]SessionSnapshot, 0, len(m.sessions))
+	for _, s := range m.sessions {
+		snapshots = append(snapshots, SessionSnapshot{
+			ID:        s.ID,
+			Status:    s.status,
+			Info:      s.info,
+			StartedAt: s.created,
+			Err:       s.err,
+		})
+	}
+	m.mu.Unlock()
+
+	for i := range snapshots {
+		if snapshots[i].Status == SessionStatusRunning {
+			snapshots[i].Round = partiesRound(snapshots[i].Info.parties)
+		}
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		if snapshots[i].StartedAt.Equal(snapshots[j].StartedAt) {
+			return snapshots[i].ID < snapshots[j].ID
+		}
+		return snapshots[i].StartedAt.Before(snapshots[j].StartedAt)
+	})
+	return snapshots
+}
+
+// partiesRound returns the round of the first running party, 0 when none is running.
+// tss-lib only exposes the round through the description of a party.
+func partiesRound(parties []tss.Party) int {
+	for _, party := range parties {
+		if party == nil || !party.Running() {
+			continue
+		}
+		description := party.String()
+		index := strings.LastIndex(description, "round: ")
+		if index < 0 {
+			continue
+		}
+		if round, err := strconv.Atoi(description[index+len("round: "):]); err == nil {
+			return round
+		}
+	}
+	return 0
+}
+
+// Deliver routes a protocol message to its session, buffering it until the session starts
+func (m *SessionManager) Deliver(msg Message) {
+	if msg.SessionID == "" {
+		logger.Sugar().Warnw("dropping protocol message without a session", "type", msg.Type, "identity", msg.Identity)
+		return
+	}
+
+	m.mu.Lock()
+	s := m.session(msg.SessionID)
+	handler := s.handler
+	if handler == nil {
+		if s.status == SessionStatusPending {
+			if len(s.buffer) < maxBufferedMessages {
+				s.buffer = append(s.buffer, msg)
+			} else {
+				logger.Sugar().Warnw("session buffer is full, dropping message", "session", s.ID)
+			}
+		}
+		m.mu.Unlock()
+		return
+	}
+	m.mu.Unlock()
+
+	m.handle(s, handler, msg)
+}
+
+func (m *SessionManager) handle(s *Session, handler func(Message) error, msg Message) {
+	if err := handler(msg); err != nil {
+		logger.Sugar().Errorw("failed to process session message", "session", s.ID, "error", err)
+		m.Fail(s.ID, err)
+	}
+}
+
+// Complete finishes a session with its result. Only the first outcome of a session counts.
+func (m *SessionManager) Complete(id string, result Message) {
+	m.finish(id, result, nil)
+}
+
+// Fail finishes a session with an error
+func (m *SessionManager) Fail(id string, err error) {
+	m.finish(id, Message{}, err)
+}
+
+// Cancel stops a session that has not finished yet
+func (m *SessionManager) Cancel(id string) {
+	m.finish(id, Message{}, ErrSessionCancelled)
+}
+
+func (m *SessionManager) finish(id string, result Message, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.sessions[id]
+	if !ok || s.status == SessionStatusCompleted || s.status == SessionStatusFailed {
+		return
+	}
+
+	if err != nil {
+		s.status = SessionStatusFailed
+		s.err = err
+	} else {
+		s.status = SessionStatusCompleted
+		s.result = result
+	}
+	s.handler = nil
+	s.buffer = nil
+	close(s.done)
+	s.cancel()
+}
+
+// Wait blocks until a session finishes and returns its outcome
+func (m *SessionManager) Wait(ctx context.Context, id string) (Message, error) {
+	m.mu.Lock()
+	s, ok := m.sessions[id]
+	m.mu.Unlock()
+	if !ok {
+		return Message{}, ErrSessionNotFound
+	}
+
+	select {
+	case <-s.done:
+	case <-ctx.Done():
+		return Message{}, ctx.Err()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return s.result, s.err
+}
+
+// Status returns the status of a session and, when it failed, why
+func (m *SessionManager) Status(id string) (status SessionStatus, reason error, ok bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.sessions[id]
+	if !ok {
+		return "", nil, false
+	}
+	return s.status, s.err, true
+}
+
+// sessionError returns why a session finished before the local party did
+func sessionError(id string) error {
+	status, reason, _ := sessions.Status(id)
+	if status == SessionStatusCompleted {
+		return errSessionCompleted
+	}
+	if reason != nil {
+		return reason
+	}
+	return fmt.Errorf("session %s finished without this party", id)
+}
+
+// partyHandler returns a session handler updating a local party with the messages
+// addressed to it. Failures are attributed to the committee members at fault.
+func partyHandler(party tss.Party, parties tss.SortedPartyIDs, members committee) func(Message) error {
+	return func(msg Message) error {
+		if msg.To != -1 && msg.To != party.PartyID().Index {
+			return nil
+		}
+
+		// Our own messages come back through pubsub
+		if party.PartyID().Index == msg.From {
+			return nil
+		}
+
+		if msg.From < 0 || msg.From >= len(parties) {
+			return fmt.Errorf("message from unknown party %d", msg.From)
+		}
+
+		// A party only speaks for the signer behind it, so culprits are the signers at fault
+		if signer := members.signerOf(parties[msg.From]); msg.sender != "" && msg.sender != signer {
+			logger.Sugar().Warnw("dropping message sent for another party", "session", msg.SessionID, "from", msg.From, "sender", msg.sender)
+			return nil
+		}
+
+		payload, err := openMessage(msg)
+		if err != nil {
+			return members.blame(tss.NewError(err, "open", -1, party.PartyID(), parties[msg.From]))
+		}
+
+		pMsg, err := tss.ParseWireMessage(payload, parties[msg.From], msg.IsBroadcast)
+		if err != nil {
+			return members.blame(tss.NewError(err, "parse", -1, party.PartyID(), parties[msg.From]))
+		}
+
+		ok, tssErr := party.Update(pMsg)
+		if tssErr != nil {
+			return members.blame(tssErr)
+		}
+
+		logger.Sugar().Infof("processed session message with status: %v", ok)
+		return nil
+	}
+}
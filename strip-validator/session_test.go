@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSessionBuffersEarlyMessages(t *testing.T) {
+	m := NewSessionManager(time.Minute, time.Minute)
+
+	m.Deliver(Message{SessionID: "s1", From: 1})
+	m.Deliver(Message{SessionID: "s1", From: 2})
+	m.Deliver(Message{SessionID: "s2", From: 3})
+
+	received := []int{}
+	if _, err := m.Start("s1", func(msg Message) error {
+		received = append(received, msg.From)
+		return nil
+	}); err != nil {
+		t.Fatalf("Start returned error: %v", err)
+	}
+
+	m.Deliver(Message{SessionID: "s1", From: 4})
+
+	if len(received) != 3 || received[0] != 1 || received[1] != 2 || received[2] != 4 {
+		t.Errorf("got messages %v, want [1 2 4]", received)
+	}
+
+	if _, err := m.Start("s1", func(Message) error { return nil }); err == nil {
+		t.Error("starting a session twice should fail")
+	}
+}
+
+func TestSessionOutcome(t *testing.T) {
+	m := NewSessionManager(time.Minute, time.Minute)
+
+	m.Expect("sign")
+	go m.Complete("sign", Message{Address: "signer"})
+
+	result, err := m.Wait(context.Background(), "sign")
+	if err != nil || result.Address != "signer" {
+		t.Fatalf("Wait = %v, %v, want the completed result", result, err)
+	}
+
+	// Only the first outcome counts
+	m.Fail("sign", errors.New("late failure"))
+	if status, _, _ := m.Status("sign"); status != SessionStatusCompleted {
+		t.Errorf("status = %s, want %s", status, SessionStatusCompleted)
+	}
+
+	failure := errors.New("party failed")
+	if _, err := m.Start("keygen", func(Message) error { return failure }); err != nil {
+		t.Fatalf("Start returned error: %v", err)
+	}
+	m.Deliver(Message{SessionID: "keygen"})
+	if status, reason, _ := m.Status("keygen"); status != SessionStatusFailed || !errors.Is(reason, failure) {
+		t.Errorf("Status = %s, %v, want the handler error", status, reason)
+	}
+
+	if _, err := m.Wait(context.Background(), "unknown"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Wait on an unknown session = %v, want %v", err, ErrSessionNotFound)
+	}
+}
+
+func TestSessionTimeout(t *testing.T) {
+	m := NewSessionManager(20*time.Millisecond, time.Minute)
+
+	session := m.Expect("slow")
+	m.Deliver(Message{SessionID: "slow"})
+
+	if _, err := m.Wait(context.Background(), "slow"); !errors.Is(err, ErrSessionTimeout) {
+		t.Fatalf("Wait = %v, want %v", err, ErrSessionTimeout)
+	}
+	select {
+	case <-session.Context().Done():
+	default:
+		t.Error("session context should be cancelled")
+	}
+
+	if _, err := m.Start("slow", func(Message) error { return nil }); err == nil {
+		t.Error("starting a timed out session should fail")
+	}
+}
+
+func TestSessionList(t *testing.T) {
+	m := NewSessionManager(time.Minute, time.Minute)
+
+	m.Expect("s1")
+	m.Describe("s2", SessionInfo{Type: SessionTypeSigning, Identity: "0xabc", Participants: []string{"a", "b"}})
+	if _, err := m.Start("s2", func(Message) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	m.Cancel("s1")
+
+	list := m.List()
+	if len(list) != 2 || list[0].ID != "s1" || list[1].ID != "s2" {
+		t.Fatalf("List = %+v", list)
+	}
+	if list[0].Status != SessionStatusFailed || !errors.Is(list[0].Err, ErrSessionCancelled) {
+		t.Errorf("cancelled session = %+v", list[0])
+	}
+	if list[1].Status != SessionStatusRunning || list[1].Info.Type != SessionTypeSigning || len(list[1].Info.Participants) != 2 {
+		t.Errorf("running session = %+v", list[1])
+	}
+	// Sessions without a local party have no round
+	if list[1].Round != 0 {
+		t.Errorf("round = %d, want 0", list[1].Round)
+	}
+}
+
+func TestSignSessionID(t *testing.T) {
+	id := signSessionID("request", "0xwallet", "", "ecdsa", "eddsa", []byte("withdraw 1"))
+	msg := Message{SessionID: id, Identity: "0xwallet", IdentityCurve: "ecdsa", KeyCurve: "eddsa", Hash: []byte("withdraw 1")}
+
+	if !isSignSessionOf(msg) {
+		t.Fatalf("message should belong to session %s", id)
+	}
+	if other := signSessionID("request", "0xwallet", "", "ecdsa", "eddsa", []byte("withdraw 2")); other == id {
+		t.Error("messages with different hashes should get different sessions")
+	}
+	if other := signSessionID("other", "0xwallet", "", "ecdsa", "eddsa", []byte("withdraw 1")); other == id {
+		t.Error("requests for the same hash should get different sessions")
+	}
+
+	routed := msg
+	routed.Hash = []byte("withdraw 2")
+	if isSignSessionOf(routed) {
+		t.Error("a message for another hash should not belong to the session")
+	}
+	routed = msg
+	routed.Identity = "0xother"
+	if isSignSessionOf(routed) {
+		t.Error("a message for another wallet should not belong to the session")
+	}
+	routed = msg
+	routed.DerivationPath = "m/0/1"
+	if isSignSessionOf(routed) {
+		t.Error("a message for a child key should not belong to the session of the wallet")
+	}
+}
@@ -2,19 +2,26 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"crypto/ed25519"
+	"crypto/sha256"
 	"crypto/sha512"
 	"encoding/base32"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
+	"math/rand"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/StripChain/strip-node/bitcoin"
 	"github.com/StripChain/strip-node/common"
 	"github.com/StripChain/strip-node/libs/blockchains"
+	pb "github.com/StripChain/strip-node/libs/proto"
 	"github.com/StripChain/strip-node/ripple"
 	"github.com/StripChain/strip-node/util/logger"
 	cmn "github.com/bnb-chain/tss-lib/v2/common"
@@ -30,10 +37,89 @@ import (
 	"github.com/mr-tron/base58"
 	"github.com/stellar/go/strkey"
 	"golang.org/x/crypto/blake2b"
+	"google.golang.org/protobuf/proto"
 )
 
-func updateSignature(identity string, identityCurve common.Curve, keyCurve common.Curve, from int, bz []byte, isBroadcast bool, to int) {
-	signersString, err := GetSignersForKeyShare(identity, identityCurve, keyCurve)
+var errNoKeyShare = errors.New("key share not found")
+
+// errSigningBusy fails a signing session that a signer had no free slot for. Queueing it
+// instead could deadlock: two nodes could each hold the slots the other's sessions wait for.
+var errSigningBusy = errors.New("a signer has no free signing slot")
+
+// errSigningDenied fails a signing session that a signer did not verify
+var errSigningDenied = errors.New("signing denied")
+
+// ISigningVerifier checks a signing started by another validator before this node joins it
+type ISigningVerifier interface {
+	VerifySigning(msg Message) (signingOrigin, error)
+}
+
+// intentSigningVerifier verifies the intent operation a START_SIGN message carries as if
+// this node had been asked to sign it, and that the message is what the operation signs
+type intentSigningVerifier struct{}
+
+func (intentSigningVerifier) VerifySigning(msg Message) (signingOrigin, error) {
+	if len(msg.Intent) == 0 {
+		return signingOrigin{}, errors.New("signing carries no intent")
+	}
+	intent := &pb.Intent{}
+	if err := proto.Unmarshal(msg.Intent, intent); err != nil {
+		return signingOrigin{}, fmt.Errorf("invalid intent: %w", err)
+	}
+	op, err := resolveOperationSigning(&pb.SignIntentOperationRequest{Intent: intent, OperationIndex: uint32(msg.OperationIndex)})
+	if err != nil {
+		return signingOrigin{}, err
+	}
+	if err := op.matches(msg); err != nil {
+		return signingOrigin{}, err
+	}
+	return op.origin(), nil
+}
+
+// signingVerifier verifies the signings this node is asked to join
+var signingVerifier ISigningVerifier = intentSigningVerifier{}
+
+// signingSlots caps the signing sessions running at once on this node. A session started
+// while all slots are taken is rejected on every signer, and retried by the node that
+// started it.
+var signingSlots = make(chan struct{}, 16)
+
+const (
+	// signingAttempts bounds how often a rejected signing is started again
+	signingAttempts = 10
+	// signingBackoff and signingMaxBackoff bound the random wait before a rejected signing
+	// is retried, which doubles with every attempt
+	signingBackoff    = time.Second
+	signingMaxBackoff = 30 * time.Second
+)
+
+// signingStarts are the START_SIGN messages of the signings started on this node, by
+// session, kept until the signing finishes to start it again when it is rejected
+var signingStarts = struct {
+	sync.Mutex
+	messages map[string]Message
+}{messages: map[string]Message{}}
+
+// signSessionID scopes a signing session by the request, the wallet and the message signed,
+// so a wallet can sign many messages at once, e.g. the bridge wallet's withdrawals
+func signSessionID(requestID string, identity string, derivationPath string, identityCurve common.Curve, keyCurve common.Curve, hash []byte) string {
+	digest := sha256.Sum256(hash)
+	wallet := identity + "_" + string(identityCurve) + "_" + string(keyCurve)
+	if derivationPath != "" {
+		wallet += "@" + derivationPath
+	}
+	return requestID + ":" + wallet + ":" + hex.EncodeToString(digest[:8])
+}
+
+// isSignSessionOf reports whether a signing message belongs to the session it names, so
+// that it cannot be routed to the session of another wallet or message
+func isSignSessionOf(msg Message) bool {
+	requestID, _, _ := strings.Cut(msg.SessionID, ":")
+	return requestID != "" && msg.SessionID == signSessionID(requestID, msg.Identity, msg.DerivationPath, msg.IdentityCurve, msg.KeyCurve, msg.Hash)
+}
+
+func updateSignature(msg Message) {
+	signersString, err := GetSignersForKeyShare(msg.Identity, msg.IdentityCurve, msg.KeyCurve)
 	if err != nil {
 		logger.Sugar().Errorw("error from postgres", "error", err)
 		return
@@ -43,134 +129,283 @@ func updateSignature(identity string, identityCurve common.Curve, keyCurve commo
 		return
 	}
 
+	sessions.Deliver(msg)
+}
+
+// joinSignature takes part in a signing started by a validator, once the operation it signs
+// and its start time are verified. Signings this node started were verified when they were
+// requested.
+func joinSignature(msg Message) {
+	origin := signingOrigin{IntentID: msg.IntentID, OperationIndex: msg.OperationIndex, Timestamp: msg.Timestamp}
+	if msg.sender != NodePublicKey {
+		err := checkSigningTimestamp(msg.Timestamp, time.Now())
+		if err == nil {
+			origin, err = signingVerifier.VerifySigning(msg)
+			origin.Timestamp = msg.Timestamp
+		}
+		if err != nil {
+			logger.Sugar().Warnw("denying signing", "session", msg.SessionID, "coordinator", msg.sender, "error", err)
+			sessions.Expect(msg.SessionID)
+			sessions.Fail(msg.SessionID, fmt.Errorf("%w: %v", errSigningDenied, err))
+			go broadcast(Message{
+				SessionID:      msg.SessionID,
+				Type:           MESSAGE_TYPE_SIGNING_DENIED,
+				Identity:       msg.Identity,
+				IdentityCurve:  msg.IdentityCurve,
+				KeyCurve:       msg.KeyCurve,
+				BlockchainID:   msg.BlockchainID,
+				DerivationPath: msg.DerivationPath,
+				Hash:           msg.Hash,
+				Message:        []byte(err.Error()),
+			})
+			return
+		}
+	}
+
+	// The receipt records the operation and start time this node verified
+	receipts.Begin(msg.SessionID, msg.sender, origin)
+	generateSignature(msg.SessionID, msg.Identity, msg.DerivationPath, msg.BlockchainID, msg.IdentityCurve, msg.KeyCurve, msg.Hash)
+}
+
+// signingRejected fails a signing session rejected by a signer of its wallet, because it
+// had no free slot or did not verify the operation, so that the other signers free their
+// slots at once and the node that started it learns why
+func signingRejected(msg Message, reason error) {
+	signersString, err := GetSignersForKeyShare(msg.Identity, msg.IdentityCurve, msg.KeyCurve)
+	if err != nil {
+		logger.Sugar().Errorw("error from postgres", "error", err)
+		return
+	}
 	signers := []string{}
 	json.Unmarshal([]byte(signersString), &signers)
+	if SliceIndexOfString(signers, msg.sender) == -1 {
+		logger.Sugar().Warnw("dropping signing rejection from a non-signer", "session", msg.SessionID, "sender", msg.sender)
+		return
+	}
 
-	TotalSigners := len(signers)
+	// The rejection can arrive before the session starts here, it must not take a slot then
+	sessions.Expect(msg.SessionID)
+	sessions.Fail(msg.SessionID, reason)
+}
+
+// startSigning broadcasts the START_SIGN message of a signing, stamped with the time it
+// starts, and returns its session
+func startSigning(message Message) string {
+	message.Timestamp = time.Now().Unix()
+	sessions.Expect(message.SessionID)
+	receipts.Begin(message.SessionID, NodePublicKey, signingOrigin{IntentID: message.IntentID, OperationIndex: message.OperationIndex, Timestamp: message.Timestamp})
+
+	signingStarts.Lock()
+	signingStarts.messages[message.SessionID] = message
+	signingStarts.Unlock()
+	// Signings nobody waits for are forgotten with their session
+	time.AfterFunc(sessionTimeout, func() { forgetSigning(message.SessionID) })
+
+	broadcast(message)
+	return message.SessionID
+}
 
-	parties, _ := getParties(TotalSigners)
+func forgetSigning(sessionID string) (Message, bool) {
+	signingStarts.Lock()
+	defer signingStarts.Unlock()
+	message, ok := signingStarts.messages[sessionID]
+	delete(signingStarts.messages, sessionID)
+	return message, ok
+}
 
-	//wait for 1 minute for party to be ready
-	for i := 0; i < 6; i++ {
-		if !partyProcesses[identity+"_"+string(identityCurve)+"_"+string(keyCurve)].Exists {
-			time.Sleep(10 * time.Second)
-		} else {
-			break
+// waitSignature waits for a signing started on this node. When a signer rejects it for lack
+// of a free slot, it is started again under a new request after a random backoff. It returns
+// the signature and the session that produced it.
+func waitSignature(ctx context.Context, sessionID string) (Message, string, error) {
+	for attempt := 1; ; attempt++ {
+		result, err := sessions.Wait(ctx, sessionID)
+		message, ok := forgetSigning(sessionID)
+		if !errors.Is(err, errSigningBusy) || !ok || attempt == signingAttempts {
+			return result, sessionID, err
 		}
-	}
 
-	if !partyProcesses[identity+"_"+string(identityCurve)+"_"+string(keyCurve)].Exists {
-		return
-	}
+		backoff := time.Duration(rand.Int63n(int64(min(signingBackoff<<(attempt-1), signingMaxBackoff))))
+		logger.Sugar().Infow("signing rejected, retrying", "session", sessionID, "attempt", attempt, "backoff", backoff)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return Message{}, sessionID, ctx.Err()
+		}
 
-	party := *partyProcesses[identity+"_"+string(identityCurve)+"_"+string(keyCurve)].Party
+		message.SessionID = signSessionID(newSessionID(), message.Identity, message.DerivationPath, message.IdentityCurve, message.KeyCurve, message.Hash)
+		sessionID = startSigning(message)
+	}
+}
 
-	if to != -1 && to != party.PartyID().Index {
+func generateSignature(sessionID string, identity string, derivationPath string, blockchainID blockchains.BlockchainID, identityCurve common.Curve, keyCurve common.Curve, hash []byte) {
+	message, err := runSignature(sessionID, identity, derivationPath, blockchainID, identityCurve, keyCurve, hash)
+	if errors.Is(err, errSessionCompleted) || errors.Is(err, errNoKeyShare) {
+		// Another signer already broadcast the signature, or this node does not hold the wallet
 		return
 	}
-
-	if party.PartyID().Index == from {
+	if errors.Is(err, errSigningBusy) {
+		logger.Sugar().Infow("signing rejected, no free signing slot", "session", sessionID)
+		sessions.Fail(sessionID, err)
 		return
 	}
-
-	pMsg, err := tss.ParseWireMessage(bz, parties[from], isBroadcast)
 	if err != nil {
-		panic(err)
-	}
-
-	ok, err1 := party.Update(pMsg)
-	if err1 != nil {
-		panic(err1)
+		logger.Sugar().Errorw("signing failed", "session", sessionID, "error", err)
+		sessions.Fail(sessionID, err)
+		return
 	}
 
-	logger.Sugar().Infof("processed signature generation message with status: %v", ok)
+	message.SessionID = sessionID
+	message.DerivationPath = derivationPath
+	sessions.Complete(sessionID, message)
+	go broadcast(message)
+	go coSignReceipt(message)
 }
 
-func generateSignature(identity string, blockchainID blockchains.BlockchainID, identityCurve common.Curve, keyCurve common.Curve, hash []byte) {
+func runSignature(sessionID string, identity string, derivationPath string, blockchainID blockchains.BlockchainID, identityCurve common.Curve, keyCurve common.Curve, hash []byte) (Message, error) {
 	keyShare, err := GetKeyShare(identity, identityCurve, keyCurve)
 
 	if err != nil {
-		logger.Sugar().Errorw("error from postgres", "error", err)
-		return
+		return Message{}, fmt.Errorf("failed to read key share: %w", err)
 	}
 
 	if keyShare == "" {
-		logger.Sugar().Errorw("key share not found. stopping to sign")
-		return
+		return Message{}, errNoKeyShare
 	}
 
-	if keyShare != "" {
-		logger.Sugar().Infof("key share found. continuing to sign")
-	}
+	logger.Sugar().Infof("key share found. continuing to sign")
 
 	signersString, err := GetSignersForKeyShare(identity, identityCurve, keyCurve)
 	if err != nil {
-		logger.Sugar().Errorw("error from postgres", "error", err)
-		return
+		return Message{}, fmt.Errorf("failed to read signers: %w", err)
 	}
 
 	if signersString == "" {
-		logger.Sugar().Errorw("signers not found. stopping to sign")
-		return
+		return Message{}, errors.New("signers not found")
 	}
 
-	if signersString != "" {
-		logger.Sugar().Infof("signers found. continuing to sign")
+	logger.Sugar().Infof("signers found. continuing to sign")
+
+	session := sessions.Expect(sessionID)
+	select {
+	case <-session.Done():
+		// Rejected by another signer before this node got to it
+		return Message{}, sessionError(sessionID)
+	default:
+	}
+	select {
+	case signingSlots <- struct{}{}:
+		defer func() { <-signingSlots }()
+	default:
+		go broadcast(Message{
+			SessionID:      sessionID,
+			Type:           MESSAGE_TYPE_SIGNING_BUSY,
+			Identity:       identity,
+			IdentityCurve:  identityCurve,
+			KeyCurve:       keyCurve,
+			BlockchainID:   blockchainID,
+			DerivationPath: derivationPath,
+			Hash:           hash,
+		})
+		return Message{}, errSigningBusy
 	}
 
 	signers := []string{}
 	json.Unmarshal([]byte(signersString), &signers)
 
-	Index := SliceIndexOfString(signers, NodePublicKey)
+	path, err := parseDerivationPath(derivationPath)
+	if err != nil {
+		return Message{}, err
+	}
 
-	delete(partyProcesses, identity+"_"+string(identityCurve)+"_"+string(keyCurve))
+	Index := SliceIndexOfString(signers, NodePublicKey)
 
 	TotalSigners := len(signers)
-
-	parties, partiesIds := getParties(TotalSigners)
-
-	ctx := tss.NewPeerContext(parties)
+	threshold, err := GetThresholdForKeyShare(identity, identityCurve, keyCurve, TotalSigners)
+	if err != nil {
+		return Message{}, fmt.Errorf("failed to read threshold: %w", err)
+	}
 
 	outChanKeygen := make(chan tss.Message)
 	saveChan := make(chan *cmn.SignatureData)
+	errChan := make(chan *tss.Error, 1)
 
 	var rawKeyEddsa *eddsaKeygen.LocalPartySaveData
 	var rawKeyEcdsa *ecdsaKeygen.LocalPartySaveData
 
+	var localParty tss.Party
+	var parties tss.SortedPartyIDs
+	var partiesIds []*tss.PartyID
 	switch keyCurve {
 	case common.CurveEddsa:
-		params := tss.NewParameters(tss.Edwards(), ctx, partiesIds[Index], len(parties), int(CalculateThreshold(TotalSigners)))
 		msg := (&big.Int{}).SetBytes(hash)
 
 		err = json.Unmarshal([]byte(keyShare), &rawKeyEddsa)
 		if err != nil {
-			logger.Sugar().Errorw("error unmarshalling key share", "error", err)
-			return
+			return Message{}, fmt.Errorf("failed to unmarshal key share: %w", err)
 		}
-		localParty := eddsaSigning.NewLocalParty(msg, params, *rawKeyEddsa, outChanKeygen, saveChan)
-		partyProcesses[identity+"_"+string(identityCurve)+"_"+string(keyCurve)] = PartyProcess{&localParty, true}
-
-		go localParty.Start()
+		// The parties come from the key share, they change when the wallet is reshared
+		parties, partiesIds = getPartiesFromKeys(rawKeyEddsa.Ks)
+		ctx := tss.NewPeerContext(parties)
+		params := tss.NewParameters(tss.Edwards(), ctx, partiesIds[Index], len(parties), threshold)
+		if err := deriveEddsaKey(rawKeyEddsa, path); err != nil {
+			return Message{}, err
+		}
+		localParty = eddsaSigning.NewLocalParty(msg, params, *rawKeyEddsa, outChanKeygen, saveChan)
 	case common.CurveEcdsa:
-		params := tss.NewParameters(tss.S256(), ctx, partiesIds[Index], len(parties), int(CalculateThreshold(TotalSigners)))
 		// msg := new(big.Int).SetBytes(crypto.Keccak256(hash))
 		msg, _ := new(big.Int).SetString(string(hash), 16)
 		err = json.Unmarshal([]byte(keyShare), &rawKeyEcdsa)
 		if err != nil {
-			logger.Sugar().Errorw("error unmarshalling key share", "error", err)
-			return
+			return Message{}, fmt.Errorf("failed to unmarshal key share: %w", err)
 		}
-		localParty := ecdsaSigning.NewLocalParty(msg, params, *rawKeyEcdsa, outChanKeygen, saveChan)
-		partyProcesses[identity+"_"+string(identityCurve)+"_"+string(keyCurve)] = PartyProcess{&localParty, true}
-
-		go localParty.Start()
+		parties, partiesIds = getPartiesFromKeys(rawKeyEcdsa.Ks)
+		ctx := tss.NewPeerContext(parties)
+		params := tss.NewParameters(tss.S256(), ctx, partiesIds[Index], len(parties), threshold)
+		delta, err := deriveEcdsaKey(rawKeyEcdsa, path)
+		if err != nil {
+			return Message{}, err
+		}
+		localParty = ecdsaSigning.NewLocalPartyWithKDD(msg, params, *rawKeyEcdsa, delta, outChanKeygen, saveChan)
+	case common.CurveFrost:
+		err = json.Unmarshal([]byte(keyShare), &rawKeyEddsa)
+		if err != nil {
+			return Message{}, fmt.Errorf("failed to unmarshal key share: %w", err)
+		}
+		if err := deriveEddsaKey(rawKeyEddsa, path); err != nil {
+			return Message{}, err
+		}
+		template := Message{
+			SessionID:      sessionID,
+			Type:           MESSAGE_TYPE_SIGN,
+			BlockchainID:   blockchainID,
+			Hash:           hash,
+			Identity:       identity,
+			IdentityCurve:  identityCurve,
+			KeyCurve:       keyCurve,
+			DerivationPath: derivationPath,
+		}
+		signature, err := runFrostSignature(session, template, rawKeyEddsa, signers, Index)
+		if err != nil {
+			return Message{}, err
+		}
+		return signatureMessage(blockchainID, identity, identityCurve, keyCurve, hash, &cmn.SignatureData{Signature: signature}, rawKeyEddsa, nil)
 	default:
-		logger.Sugar().Errorw("invalid key curve", "keyCurve", keyCurve)
-		return
+		return Message{}, fmt.Errorf("invalid key curve: %s", keyCurve)
+	}
+
+	members := committee{sessionID: sessionID, ids: partiesIds, signers: signers}
+	sessions.Describe(sessionID, SessionInfo{Type: SessionTypeSigning, Identity: identity, KeyCurve: keyCurve, Participants: signers}, localParty)
+	if _, err := sessions.Start(sessionID, partyHandler(localParty, parties, members)); err != nil {
+		return Message{}, err
 	}
 
-	completed := false
-	for !completed {
+	go func() {
+		if err := localParty.Start(); err != nil {
+			errChan <- err
+		}
+	}()
+
+	for {
 		select {
 		case msg := <-outChanKeygen:
 			dest := msg.GetTo()
@@ -183,322 +418,327 @@ func generateSignature(identity string, blockchainID blockchains.BlockchainID, i
 			}
 
 			message := Message{
-				Type:          MESSAGE_TYPE_SIGN,
-				From:          msg.GetFrom().Index,
-				BlockchainID:  blockchainID,
-				To:            to,
-				Message:       bytes,
-				IsBroadcast:   msg.IsBroadcast(),
-				Hash:          hash,
-				Identity:      identity,
-				IdentityCurve: identityCurve,
-				KeyCurve:      keyCurve,
+				SessionID:      sessionID,
+				Type:           MESSAGE_TYPE_SIGN,
+				From:           msg.GetFrom().Index,
+				BlockchainID:   blockchainID,
+				To:             to,
+				Message:        bytes,
+				IsBroadcast:    msg.IsBroadcast(),
+				Hash:           hash,
+				Identity:       identity,
+				IdentityCurve:  identityCurve,
+				KeyCurve:       keyCurve,
+				DerivationPath: derivationPath,
+			}
+
+			if dest != nil {
+				if err := sealMessage(&message, members.signerOf(dest[0])); err != nil {
+					return Message{}, fmt.Errorf("failed to encrypt message to party %d: %w", to, err)
+				}
 			}
 
 			go broadcast(message)
 			logger.Sugar().Infof("Message broadcasted")
 
+		case err := <-errChan:
+			return Message{}, members.blame(err)
+		case <-session.Done():
+			return Message{}, sessionError(sessionID)
 		case save := <-saveChan:
-			completed = true
-
-			switch blockchainID {
-			case blockchains.Solana:
-				pk := edwards.PublicKey{
-					Curve: rawKeyEddsa.EDDSAPub.Curve(),
-					X:     rawKeyEddsa.EDDSAPub.X(),
-					Y:     rawKeyEddsa.EDDSAPub.Y(),
-				}
-
-				publicKeyStr := base58.Encode(pk.Serialize())
-
-				message := Message{
-					Type:          MESSAGE_TYPE_SIGNATURE,
-					Hash:          hash,
-					Message:       save.Signature,
-					Address:       publicKeyStr,
-					Identity:      identity,
-					IdentityCurve: identityCurve,
-					KeyCurve:      keyCurve,
-					BlockchainID:  blockchainID,
-				}
-
-				delete(partyProcesses, identity+"_"+string(identityCurve)+"_"+string(keyCurve))
-
-				go broadcast(message)
-			case blockchains.Bitcoin:
-				xStr := fmt.Sprintf("%064x", rawKeyEcdsa.ECDSAPub.X())
-				prefix := "02"
-				if rawKeyEcdsa.ECDSAPub.Y().Bit(0) == 1 {
-					prefix = "03"
-				}
-				uncompressedPubKeyStr := prefix + xStr
-				logger.Sugar().Infof("Uncompressed public key: %s", uncompressedPubKeyStr)
-				compressedPubKeyStr, err := bitcoin.ConvertToCompressedPublicKey(uncompressedPubKeyStr)
-				if err != nil {
-					logger.Sugar().Errorw("error converting to compressed public key", "error", err)
-					return
-				}
-				logger.Sugar().Infof("Compressed public key: %s", compressedPubKeyStr)
-
-				final := hex.EncodeToString(save.Signature)
-
-				message := Message{
-					Type:          MESSAGE_TYPE_SIGNATURE,
-					Hash:          hash,
-					Message:       []byte(final),
-					Address:       compressedPubKeyStr, // we pass the public key in string format, hex string with length 130 starts with 04
-					Identity:      identity,
-					IdentityCurve: identityCurve,
-					BlockchainID:  blockchainID,
-					KeyCurve:      keyCurve,
-				}
-
-				delete(partyProcesses, identity+"_"+string(identityCurve)+"_"+string(keyCurve))
-
-				go broadcast(message)
-			case blockchains.Dogecoin:
-				x := toHexInt(rawKeyEcdsa.ECDSAPub.X())
-				y := toHexInt(rawKeyEcdsa.ECDSAPub.Y())
-				publicKeyStr := "04" + x + y
-				compressedPubKeyStr, err := bitcoin.ConvertToCompressedPublicKey(publicKeyStr)
-				if err != nil {
-					logger.Sugar().Errorw("Error converting to compressed public key:", "error", err)
-					return
-				}
-
-				final := hex.EncodeToString(save.Signature)
-				logger.Sugar().Infof("Final message: %s", final)
-
-				message := Message{
-					Type:          MESSAGE_TYPE_SIGNATURE,
-					Hash:          hash,
-					Message:       []byte(final),
-					Address:       compressedPubKeyStr,
-					Identity:      identity,
-					IdentityCurve: identityCurve,
-					KeyCurve:      keyCurve,
-					BlockchainID:  blockchainID,
-				}
+			return signatureMessage(blockchainID, identity, identityCurve, keyCurve, hash, save, rawKeyEddsa, rawKeyEcdsa)
+		}
+	}
+}
 
-				delete(partyProcesses, identity+"_"+string(identityCurve)+"_"+string(keyCurve))
+// signatureMessage encodes a signature as the blockchain expects it, together with the
+// address of the key that made it
+func signatureMessage(blockchainID blockchains.BlockchainID, identity string, identityCurve common.Curve, keyCurve common.Curve, hash []byte, save *cmn.SignatureData, rawKeyEddsa *eddsaKeygen.LocalPartySaveData, rawKeyEcdsa *ecdsaKeygen.LocalPartySaveData) (Message, error) {
+	switch blockchainID {
+	case blockchains.Solana:
+		pk := edwards.PublicKey{
+			Curve: rawKeyEddsa.EDDSAPub.Curve(),
+			X:     rawKeyEddsa.EDDSAPub.X(),
+			Y:     rawKeyEddsa.EDDSAPub.Y(),
+		}
 
-				go broadcast(message)
-			case blockchains.Sui:
-				// Get the Ed25519 public key
-				pk := edwards.PublicKey{
-					Curve: tss.Edwards(),
-					X:     rawKeyEddsa.EDDSAPub.X(),
-					Y:     rawKeyEddsa.EDDSAPub.Y(),
-				}
+		publicKeyStr := base58.Encode(pk.Serialize())
+
+		message := Message{
+			Type:          MESSAGE_TYPE_SIGNATURE,
+			Hash:          hash,
+			Message:       save.Signature,
+			Address:       publicKeyStr,
+			Identity:      identity,
+			IdentityCurve: identityCurve,
+			KeyCurve:      keyCurve,
+			BlockchainID:  blockchainID,
+		}
 
-				// Serialize the full Ed25519 public key
-				pkBytes := pk.Serialize()
-
-				// Convert to Sui address format (Blake2b-256 hash of public key)
-				flag := byte(0x00)
-				hasher, _ := blake2b.New256(nil)
-				hasher.Write([]byte{flag})
-				hasher.Write(pkBytes)
-
-				arr := hasher.Sum(nil)
-				suiAddress := "0x" + hex.EncodeToString(arr)
-
-				// For Sui, we need to encode the signature in base64
-				var signatureBytes [ed25519.PublicKeySize + ed25519.SignatureSize + 1]byte
-				signatureBuffer := bytes.NewBuffer([]byte{})
-				scheme := sui_types.SignatureScheme{ED25519: &lib.EmptyEnum{}}
-				signatureBuffer.WriteByte(scheme.Flag())
-				signatureBuffer.Write(save.Signature)
-				signatureBuffer.Write(pkBytes[:])
-				copy(signatureBytes[:], signatureBuffer.Bytes())
-
-				signatureBase64 := base64.StdEncoding.EncodeToString(signatureBytes[:])
-
-				message := Message{
-					Type:          MESSAGE_TYPE_SIGNATURE,
-					Hash:          hash,
-					Message:       []byte(signatureBase64),
-					Address:       suiAddress,
-					Identity:      identity,
-					IdentityCurve: identityCurve,
-					KeyCurve:      keyCurve,
-					BlockchainID:  blockchainID,
-				}
+		message.RawSignature = rawSignature(save.Signature, save.SignatureRecovery)
+		return message, nil
+	case blockchains.Bitcoin:
+		xStr := fmt.Sprintf("%064x", rawKeyEcdsa.ECDSAPub.X())
+		prefix := "02"
+		if rawKeyEcdsa.ECDSAPub.Y().Bit(0) == 1 {
+			prefix = "03"
+		}
+		uncompressedPubKeyStr := prefix + xStr
+		logger.Sugar().Infof("Uncompressed public key: %s", uncompressedPubKeyStr)
+		compressedPubKeyStr, err := bitcoin.ConvertToCompressedPublicKey(uncompressedPubKeyStr)
+		if err != nil {
+			return Message{}, fmt.Errorf("failed to convert to compressed public key: %w", err)
+		}
+		logger.Sugar().Infof("Compressed public key: %s", compressedPubKeyStr)
+
+		final := hex.EncodeToString(save.Signature)
+
+		message := Message{
+			Type:          MESSAGE_TYPE_SIGNATURE,
+			Hash:          hash,
+			Message:       []byte(final),
+			Address:       compressedPubKeyStr, // we pass the public key in string format, hex string with length 130 starts with 04
+			Identity:      identity,
+			IdentityCurve: identityCurve,
+			BlockchainID:  blockchainID,
+			KeyCurve:      keyCurve,
+		}
 
-				delete(partyProcesses, identity+"_"+string(identityCurve)+"_"+string(keyCurve))
+		message.RawSignature = rawSignature(save.Signature, save.SignatureRecovery)
+		return message, nil
+	case blockchains.Dogecoin:
+		x := toHexInt(rawKeyEcdsa.ECDSAPub.X())
+		y := toHexInt(rawKeyEcdsa.ECDSAPub.Y())
+		publicKeyStr := "04" + x + y
+		compressedPubKeyStr, err := bitcoin.ConvertToCompressedPublicKey(publicKeyStr)
+		if err != nil {
+			return Message{}, fmt.Errorf("failed to convert to compressed public key: %w", err)
+		}
 
-				go broadcast(message)
-			case blockchains.Aptos:
-				pk := edwards.PublicKey{
-					Curve: tss.Edwards(),
-					X:     rawKeyEddsa.EDDSAPub.X(),
-					Y:     rawKeyEddsa.EDDSAPub.Y(),
-				}
+		final := hex.EncodeToString(save.Signature)
+		logger.Sugar().Infof("Final message: %s", final)
+
+		message := Message{
+			Type:          MESSAGE_TYPE_SIGNATURE,
+			Hash:          hash,
+			Message:       []byte(final),
+			Address:       compressedPubKeyStr,
+			Identity:      identity,
+			IdentityCurve: identityCurve,
+			KeyCurve:      keyCurve,
+			BlockchainID:  blockchainID,
+		}
 
-				publicKeyStr := hex.EncodeToString(pk.Serialize())
-
-				message := Message{
-					Type:          MESSAGE_TYPE_SIGNATURE,
-					Hash:          hash,
-					Message:       save.Signature,
-					Address:       publicKeyStr,
-					Identity:      identity,
-					IdentityCurve: identityCurve,
-					KeyCurve:      keyCurve,
-					BlockchainID:  blockchainID,
-				}
+		message.RawSignature = rawSignature(save.Signature, save.SignatureRecovery)
+		return message, nil
+	case blockchains.Sui:
+		// Get the Ed25519 public key
+		pk := edwards.PublicKey{
+			Curve: tss.Edwards(),
+			X:     rawKeyEddsa.EDDSAPub.X(),
+			Y:     rawKeyEddsa.EDDSAPub.Y(),
+		}
 
-				delete(partyProcesses, identity+"_"+string(identityCurve)+"_"+string(keyCurve))
+		// Serialize the full Ed25519 public key
+		pkBytes := pk.Serialize()
+
+		// Convert to Sui address format (Blake2b-256 hash of public key)
+		flag := byte(0x00)
+		hasher, _ := blake2b.New256(nil)
+		hasher.Write([]byte{flag})
+		hasher.Write(pkBytes)
+
+		arr := hasher.Sum(nil)
+		suiAddress := "0x" + hex.EncodeToString(arr)
+
+		// For Sui, we need to encode the signature in base64
+		var signatureBytes [ed25519.PublicKeySize + ed25519.SignatureSize + 1]byte
+		signatureBuffer := bytes.NewBuffer([]byte{})
+		scheme := sui_types.SignatureScheme{ED25519: &lib.EmptyEnum{}}
+		signatureBuffer.WriteByte(scheme.Flag())
+		signatureBuffer.Write(save.Signature)
+		signatureBuffer.Write(pkBytes[:])
+		copy(signatureBytes[:], signatureBuffer.Bytes())
+
+		signatureBase64 := base64.StdEncoding.EncodeToString(signatureBytes[:])
+
+		message := Message{
+			Type:          MESSAGE_TYPE_SIGNATURE,
+			Hash:          hash,
+			Message:       []byte(signatureBase64),
+			Address:       suiAddress,
+			Identity:      identity,
+			IdentityCurve: identityCurve,
+			KeyCurve:      keyCurve,
+			BlockchainID:  blockchainID,
+		}
 
-				go broadcast(message)
-			case blockchains.Stellar:
-				pk := edwards.PublicKey{
-					Curve: tss.Edwards(),
-					X:     rawKeyEddsa.EDDSAPub.X(),
-					Y:     rawKeyEddsa.EDDSAPub.Y(),
-				}
+		message.RawSignature = rawSignature(save.Signature, save.SignatureRecovery)
+		return message, nil
+	case blockchains.Aptos:
+		pk := edwards.PublicKey{
+			Curve: tss.Edwards(),
+			X:     rawKeyEddsa.EDDSAPub.X(),
+			Y:     rawKeyEddsa.EDDSAPub.Y(),
+		}
 
-				// Get the public key bytes
-				pkBytes := pk.Serialize()
-				if len(pkBytes) != 32 {
-					logger.Sugar().Errorw("invalid public key length")
-					return
-				}
+		publicKeyStr := hex.EncodeToString(pk.Serialize())
+
+		message := Message{
+			Type:          MESSAGE_TYPE_SIGNATURE,
+			Hash:          hash,
+			Message:       save.Signature,
+			Address:       publicKeyStr,
+			Identity:      identity,
+			IdentityCurve: identityCurve,
+			KeyCurve:      keyCurve,
+			BlockchainID:  blockchainID,
+		}
 
-				// Version byte for ED25519 public key in Stellar
-				versionByte := strkey.VersionByteAccountID // 6 << 3, or 48
+		message.RawSignature = rawSignature(save.Signature, save.SignatureRecovery)
+		return message, nil
+	case blockchains.Stellar:
+		pk := edwards.PublicKey{
+			Curve: tss.Edwards(),
+			X:     rawKeyEddsa.EDDSAPub.X(),
+			Y:     rawKeyEddsa.EDDSAPub.Y(),
+		}
 
-				// Use Stellar SDK's strkey package to encode
-				address, err := strkey.Encode(versionByte, pkBytes)
-				if err != nil {
-					logger.Sugar().Errorw("error encoding Stellar address", "error", err)
-					return
-				}
+		// Get the public key bytes
+		pkBytes := pk.Serialize()
+		if len(pkBytes) != 32 {
+			return Message{}, errors.New("invalid public key length")
+		}
 
-				message := Message{
-					Type:          MESSAGE_TYPE_SIGNATURE,
-					Hash:          hash,
-					Message:       save.Signature,
-					Address:       address,
-					Identity:      identity,
-					IdentityCurve: identityCurve,
-					KeyCurve:      keyCurve,
-					BlockchainID:  blockchainID,
-				}
+		// Version byte for ED25519 public key in Stellar
+		versionByte := strkey.VersionByteAccountID // 6 << 3, or 48
 
-				delete(partyProcesses, identity+"_"+string(identityCurve)+"_"+string(keyCurve))
+		// Use Stellar SDK's strkey package to encode
+		address, err := strkey.Encode(versionByte, pkBytes)
+		if err != nil {
+			return Message{}, fmt.Errorf("failed to encode Stellar address: %w", err)
+		}
 
-				go broadcast(message)
-			case blockchains.Algorand:
-				pk := edwards.PublicKey{
-					Curve: tss.Edwards(),
-					X:     rawKeyEddsa.EDDSAPub.X(),
-					Y:     rawKeyEddsa.EDDSAPub.Y(),
-				}
+		message := Message{
+			Type:          MESSAGE_TYPE_SIGNATURE,
+			Hash:          hash,
+			Message:       save.Signature,
+			Address:       address,
+			Identity:      identity,
+			IdentityCurve: identityCurve,
+			KeyCurve:      keyCurve,
+			BlockchainID:  blockchainID,
+		}
 
-				// Get the public key bytes
-				pkBytes := pk.Serialize()
-
-				// Calculate checksum (last 4 bytes of SHA512/256 hash)
-				hasher := sha512.New512_256()
-				hasher.Write(pkBytes)
-				checksum := hasher.Sum(nil)[28:] // Last 4 bytes
-
-				// Concatenate public key and checksum
-				addressBytes := append(pkBytes, checksum...)
-
-				// Encode in base32 without padding
-				address := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(addressBytes)
-
-				message := Message{
-					Type:          MESSAGE_TYPE_SIGNATURE,
-					Hash:          hash,
-					Message:       save.Signature,
-					Address:       address,
-					Identity:      identity,
-					IdentityCurve: identityCurve,
-					KeyCurve:      keyCurve,
-					BlockchainID:  blockchainID,
-					AlgorandFlags: &struct {
-						IsRealTransaction bool `json:"isRealTransaction"`
-					}{
-						IsRealTransaction: true,
-					},
-				}
+		message.RawSignature = rawSignature(save.Signature, save.SignatureRecovery)
+		return message, nil
+	case blockchains.Algorand:
+		pk := edwards.PublicKey{
+			Curve: tss.Edwards(),
+			X:     rawKeyEddsa.EDDSAPub.X(),
+			Y:     rawKeyEddsa.EDDSAPub.Y(),
+		}
 
-				delete(partyProcesses, identity+"_"+string(identityCurve)+"_"+string(keyCurve))
-				go broadcast(message)
-			case blockchains.Ripple:
-				message := Message{
-					Type:          MESSAGE_TYPE_SIGNATURE,
-					Hash:          hash,
-					Message:       save.Signature,
-					Address:       ripple.PublicKeyToAddress(rawKeyEddsa),
-					Identity:      identity,
-					IdentityCurve: identityCurve,
-					KeyCurve:      keyCurve,
-					BlockchainID:  blockchainID,
-				}
+		// Get the public key bytes
+		pkBytes := pk.Serialize()
+
+		// Calculate checksum (last 4 bytes of SHA512/256 hash)
+		hasher := sha512.New512_256()
+		hasher.Write(pkBytes)
+		checksum := hasher.Sum(nil)[28:] // Last 4 bytes
+
+		// Concatenate public key and checksum
+		addressBytes := append(pkBytes, checksum...)
+
+		// Encode in base32 without padding
+		address := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(addressBytes)
+
+		message := Message{
+			Type:          MESSAGE_TYPE_SIGNATURE,
+			Hash:          hash,
+			Message:       save.Signature,
+			Address:       address,
+			Identity:      identity,
+			IdentityCurve: identityCurve,
+			KeyCurve:      keyCurve,
+			BlockchainID:  blockchainID,
+			AlgorandFlags: &struct {
+				IsRealTransaction bool `json:"isRealTransaction"`
+			}{
+				IsRealTransaction: true,
+			},
+		}
 
-				delete(partyProcesses, identity+"_"+string(identityCurve)+"_"+string(keyCurve))
-				go broadcast(message)
-			case blockchains.Cardano:
+		message.RawSignature = rawSignature(save.Signature, save.SignatureRecovery)
+		return message, nil
+	case blockchains.Ripple:
+		message := Message{
+			Type:          MESSAGE_TYPE_SIGNATURE,
+			Hash:          hash,
+			Message:       save.Signature,
+			Address:       ripple.PublicKeyToAddress(rawKeyEddsa),
+			Identity:      identity,
+			IdentityCurve: identityCurve,
+			KeyCurve:      keyCurve,
+			BlockchainID:  blockchainID,
+		}
 
-				pk := edwards.PublicKey{
-					Curve: rawKeyEddsa.EDDSAPub.Curve(),
-					X:     rawKeyEddsa.EDDSAPub.X(),
-					Y:     rawKeyEddsa.EDDSAPub.Y(),
-				}
+		message.RawSignature = rawSignature(save.Signature, save.SignatureRecovery)
+		return message, nil
+	case blockchains.Cardano:
 
-				publicKeyStr := hex.EncodeToString(pk.Serialize())
-
-				message := Message{
-					Type:          MESSAGE_TYPE_SIGNATURE,
-					Hash:          hash,
-					Message:       save.Signature,
-					Address:       publicKeyStr,
-					Identity:      identity,
-					IdentityCurve: identityCurve,
-					KeyCurve:      keyCurve,
-					BlockchainID:  blockchainID,
-				}
+		pk := edwards.PublicKey{
+			Curve: rawKeyEddsa.EDDSAPub.Curve(),
+			X:     rawKeyEddsa.EDDSAPub.X(),
+			Y:     rawKeyEddsa.EDDSAPub.Y(),
+		}
 
-				delete(partyProcesses, identity+"_"+string(identityCurve)+"_"+string(keyCurve))
-				go broadcast(message)
-			default:
-				final := hex.EncodeToString(save.Signature) + hex.EncodeToString(save.SignatureRecovery)
+		publicKeyStr := hex.EncodeToString(pk.Serialize())
+
+		message := Message{
+			Type:          MESSAGE_TYPE_SIGNATURE,
+			Hash:          hash,
+			Message:       save.Signature,
+			Address:       publicKeyStr,
+			Identity:      identity,
+			IdentityCurve: identityCurve,
+			KeyCurve:      keyCurve,
+			BlockchainID:  blockchainID,
+		}
 
-				data, err := hex.DecodeString(string(hash))
-				if err != nil {
-					panic(err)
-				}
+		message.RawSignature = rawSignature(save.Signature, save.SignatureRecovery)
+		return message, nil
+	default:
+		final := hex.EncodeToString(save.Signature) + hex.EncodeToString(save.SignatureRecovery)
 
-				sdata, err := hex.DecodeString(final)
-				if err != nil {
-					panic(err)
-				}
-				pubkey, err := crypto.Ecrecover(data, sdata)
-				if err != nil {
-					panic(err)
-				}
+		data, err := hex.DecodeString(string(hash))
+		if err != nil {
+			return Message{}, fmt.Errorf("failed to decode hash: %w", err)
+		}
 
-				message := Message{
-					Type:          MESSAGE_TYPE_SIGNATURE,
-					Hash:          hash,
-					Message:       []byte(final),
-					Address:       publicKeyToAddress(pubkey),
-					Identity:      identity,
-					IdentityCurve: identityCurve,
-					KeyCurve:      keyCurve,
-					BlockchainID:  blockchainID,
-				}
+		sdata, err := hex.DecodeString(final)
+		if err != nil {
+			return Message{}, fmt.Errorf("failed to decode signature: %w", err)
+		}
+		pubkey, err := crypto.Ecrecover(data, sdata)
+		if err != nil {
+			return Message{}, fmt.Errorf("failed to recover public key: %w", err)
+		}
 
-				logger.Sugar().Infof("Address of the generated signature: %s", publicKeyToAddress(pubkey))
+		message := Message{
+			Type:          MESSAGE_TYPE_SIGNATURE,
+			Hash:          hash,
+			Message:       []byte(final),
+			Address:       publicKeyToAddress(pubkey),
+			Identity:      identity,
+			IdentityCurve: identityCurve,
+			KeyCurve:      keyCurve,
+			BlockchainID:  blockchainID,
+		}
 
-				delete(partyProcesses, identity+"_"+string(identityCurve)+"_"+string(keyCurve))
+		logger.Sugar().Infof("Address of the generated signature: %s", publicKeyToAddress(pubkey))
 
-				go broadcast(message)
-			}
-		}
+		message.RawSignature = rawSignature(save.Signature, save.SignatureRecovery)
+		return message, nil
 	}
 }
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/StripChain/strip-node/common"
+	"github.com/StripChain/strip-node/libs"
+	"github.com/StripChain/strip-node/libs/blockchains"
+	"github.com/google/uuid"
+)
+
+func TestOperationSigningMatches(t *testing.T) {
+	op := &operationSigning{
+		intent:          &libs.Intent{ID: uuid.New()},
+		operationIndex:  1,
+		operation:       libs.Operation{BlockchainID: blockchains.Ethereum, DerivationPath: "m/0/1"},
+		msg:             blockchains.JoinDataToSign([]string{"aa", "bb"}),
+		signingIdentity: "0xuser",
+		identityCurve:   common.CurveEcdsa,
+		keyCurve:        common.CurveEcdsa,
+	}
+	request := func(hash string) Message {
+		return Message{
+			IntentID:       op.intent.ID.String(),
+			OperationIndex: 1,
+			Identity:       "0xuser",
+			IdentityCurve:  common.CurveEcdsa,
+			KeyCurve:       common.CurveEcdsa,
+			BlockchainID:   blockchains.Ethereum,
+			DerivationPath: "m/0/1",
+			Hash:           []byte(hash),
+		}
+	}
+
+	// The whole data to sign, or one hash of a batch
+	for _, hash := range []string{op.msg, "aa", "bb"} {
+		if err := op.matches(request(hash)); err != nil {
+			t.Errorf("matches(%q) = %v, want a match", hash, err)
+		}
+	}
+
+	mismatches := map[string]func(*Message){
+		"message":         func(m *Message) { m.Hash = []byte("cc") },
+		"intent":          func(m *Message) { m.IntentID = uuid.NewString() },
+		"wallet":          func(m *Message) { m.Identity = "0xother" },
+		"key curve":       func(m *Message) { m.KeyCurve = common.CurveEddsa },
+		"derivation path": func(m *Message) { m.DerivationPath = "" },
+		"chain":           func(m *Message) { m.BlockchainID = blockchains.Arbitrum },
+	}
+	for name, change := range mismatches {
+		msg := request("aa")
+		change(&msg)
+		if err := op.matches(msg); err == nil {
+			t.Errorf("matches should fail for another %s", name)
+		}
+	}
+}
+
+// denyingVerifier denies every signing
+type denyingVerifier struct{}
+
+func (denyingVerifier) VerifySigning(msg Message) (signingOrigin, error) {
+	return signingOrigin{}, errors.New("data to sign does not match the serialized transaction")
+}
+
+func TestJoinSignatureDenies(t *testing.T) {
+	previous := signingVerifier
+	defer func() { signingVerifier = previous }()
+	signingVerifier = denyingVerifier{}
+
+	msg := Message{SessionID: "denied:0xuser", Identity: "0xuser", Timestamp: time.Now().Unix(), sender: "0xcoordinator"}
+	joinSignature(msg)
+
+	// The session fails at once, before the signing takes a slot or the receipt is begun
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := sessions.Wait(ctx, msg.SessionID); !errors.Is(err, errSigningDenied) {
+		t.Errorf("Wait = %v, want errSigningDenied", err)
+	}
+	if _, ok := receipts.Origin(msg.SessionID); ok {
+		t.Error("a denied signing should not begin a receipt")
+	}
+}
+
+// acceptingVerifier accepts every signing as one for its origin
+type acceptingVerifier struct{ origin signingOrigin }
+
+func (v acceptingVerifier) VerifySigning(msg Message) (signingOrigin, error) {
+	return v.origin, nil
+}
+
+func TestJoinSignatureDeniesStaleStart(t *testing.T) {
+	previous := signingVerifier
+	defer func() { signingVerifier = previous }()
+	signingVerifier = acceptingVerifier{}
+
+	for _, offset := range []time.Duration{-5 * time.Minute, 5 * time.Minute} {
+		msg := Message{SessionID: "stale:" + offset.String(), Identity: "0xuser", Timestamp: time.Now().Add(offset).Unix(), sender: "0xcoordinator"}
+		joinSignature(msg)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		if _, err := sessions.Wait(ctx, msg.SessionID); !errors.Is(err, errSigningDenied) {
+			t.Errorf("Wait = %v, want errSigningDenied for a signing started %s from now", err, offset)
+		}
+		cancel()
+	}
+}
+
+func TestJoinSignatureRecordsVerifiedOrigin(t *testing.T) {
+	previousVerifier, previousStore := signingVerifier, keyValues
+	defer func() { signingVerifier, keyValues = previousVerifier, previousStore }()
+	verified := signingOrigin{IntentID: "verified", OperationIndex: 2}
+	signingVerifier = acceptingVerifier{origin: verified}
+	// This node holds no share of the wallet, so it does not go on to sign
+	keyValues = &memoryKeyValueStore{}
+
+	msg := Message{SessionID: "verified:0xuser", Identity: "0xuser", IntentID: "claimed", OperationIndex: 0, Timestamp: time.Now().Unix(), sender: "0xcoordinator"}
+	joinSignature(msg)
+
+	// The receipt is for the operation this node verified, stamped with the start time it checked
+	origin, ok := receipts.Origin(msg.SessionID)
+	if !ok || origin.IntentID != verified.IntentID || origin.OperationIndex != verified.OperationIndex || origin.Timestamp != msg.Timestamp {
+		t.Errorf("origin = %+v, %v, want %+v at %d", origin, ok, verified, msg.Timestamp)
+	}
+}
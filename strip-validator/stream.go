@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/StripChain/strip-node/util/logger"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	libp2pcrypto "github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/peerstore"
+	"github.com/libp2p/go-libp2p/core/protocol"
+)
+
+// Point-to-point TSS messages are sent over a direct stream to their recipient instead of
+// the topic, so that only the recipient receives them. A validator's libp2p identity is
+// its node key, so the peer ID of a signer follows from its public key and the DHT only
+// has to find its addresses. When the recipient cannot be reached the message falls back
+// to the topic, it is encrypted to the recipient either way.
+
+const tssProtocol = protocol.ID("/strip/tss/1.0.0")
+
+const (
+	// directSendTimeout bounds finding the recipient and writing a message to it
+	directSendTimeout = 10 * time.Second
+	// maxStreamMessageSize matches the largest message accepted on the topic
+	maxStreamMessageSize = 1 << 20
+)
+
+var p2pHost host.Host
+
+// signerPeers caches the peer ID found for each signer
+var signerPeers sync.Map
+
+// nodePeerKey returns the node key as a libp2p identity
+func nodePeerKey() (libp2pcrypto.PrivKey, error) {
+	key, err := nodeKey()
+	if err != nil {
+		return nil, err
+	}
+	return libp2pcrypto.UnmarshalSecp256k1PrivateKey(crypto.FromECDSA(key))
+}
+
+// signerPeerIDs returns the peer IDs a signer can have. Signers are identified by the X
+// coordinate of their key, which leaves both parities of Y.
+func signerPeerIDs(signer string) ([]peer.ID, error) {
+	x, err := hexutil.Decode(signer)
+	if err != nil || len(x) != 32 {
+		return nil, fmt.Errorf("invalid signer public key %s", signer)
+	}
+
+	ids := []peer.ID{}
+	for _, prefix := range []byte{0x02, 0x03} {
+		pub, err := libp2pcrypto.UnmarshalSecp256k1PublicKey(append([]byte{prefix}, x...))
+		if err != nil {
+			return nil, fmt.Errorf("invalid signer public key %s: %w", signer, err)
+		}
+		id, err := peer.IDFromPublicKey(pub)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// findSignerPeer returns the peer of a signer, looking up its addresses in the DHT when it
+// is not connected
+func findSignerPeer(ctx context.Context, signer string) (peer.ID, error) {
+	if id, ok := signerPeers.Load(signer); ok {
+		return id.(peer.ID), nil
+	}
+
+	ids, err := signerPeerIDs(signer)
+	if err != nil {
+		return "", err
+	}
+
+	for _, id := range ids {
+		if p2pHost.Network().Connectedness(id) == network.Connected {
+			signerPeers.Store(signer, id)
+			return id, nil
+		}
+	}
+
+	if peerRouting == nil {
+		return "", errors.New("peer routing is not initialised")
+	}
+	for _, id := range ids {
+		info, err := peerRouting.FindPeer(ctx, id)
+		if err != nil {
+			continue
+		}
+		p2pHost.Peerstore().AddAddrs(info.ID, info.Addrs, peerstore.TempAddrTTL)
+		signerPeers.Store(signer, id)
+		return id, nil
+	}
+	return "", fmt.Errorf("signer %s not found", signer)
+}
+
+// sendDirect sends a signed message to a signer over a stream
+func sendDirect(signer string, data []byte) error {
+	if p2pHost == nil {
+		return errors.New("host is not initialised")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), directSendTimeout)
+	defer cancel()
+
+	id, err := findSignerPeer(ctx, signer)
+	if err != nil {
+		return err
+	}
+
+	s, err := p2pHost.NewStream(ctx, id, tssProtocol)
+	if err != nil {
+		signerPeers.Delete(signer)
+		return fmt.Errorf("failed to open stream to %s: %w", id, err)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		s.SetWriteDeadline(deadline)
+	}
+	if _, err := s.Write(data); err != nil {
+		s.Reset()
+		return fmt.Errorf("failed to write to %s: %w", id, err)
+	}
+	return s.Close()
+}
+
+// streamHandler receives the messages sent directly to this node, within the limits of
+// the sending peer
+func (l *peerRateLimiter) streamHandler(s network.Stream) {
+	defer s.Close()
+
+	if !l.Allow(s.Conn().RemotePeer()) {
+		s.Reset()
+		return
+	}
+
+	s.SetReadDeadline(time.Now().Add(directSendTimeout))
+	data, err := io.ReadAll(io.LimitReader(s, maxStreamMessageSize+1))
+	if err != nil {
+		logger.Sugar().Warnw("failed to read direct message", "peer", s.Conn().RemotePeer(), "error", err)
+		s.Reset()
+		return
+	}
+	if len(data) > maxStreamMessageSize {
+		logger.Sugar().Warnw("dropping oversized direct message", "peer", s.Conn().RemotePeer())
+		s.Reset()
+		return
+	}
+
+	go handleIncomingMessage(data)
+}
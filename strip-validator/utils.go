@@ -23,6 +23,21 @@ func getParties(totalSigners int) (tss.SortedPartyIDs, []*tss.PartyID) {
 	return parties, partiesIds
 }
 
+// getPartiesFromKeys rebuilds the parties of a key share from the party keys (Ks) saved
+// with it. Keygen assigns the keys 1..n, resharing moves a wallet to fresh keys.
+func getPartiesFromKeys(keys []*big.Int) (tss.SortedPartyIDs, []*tss.PartyID) {
+	partiesIds := []*tss.PartyID{}
+
+	for _, key := range keys {
+		party := tss.NewPartyID(key.String(), "", new(big.Int).Set(key))
+		partiesIds = append(partiesIds, party)
+	}
+
+	parties := tss.SortPartyIDs(partiesIds)
+
+	return parties, partiesIds
+}
+
 func publicKeyToAddress(pubkey []byte) string {
 	var buf []byte
 	_hash := sha3.NewKeccak256()
@@ -54,11 +69,3 @@ func SliceIndexOfString(s []string, e string) int {
 	}
 	return -1
 }
-
-func CalculateThreshold(totalSigners int) uint {
-	if totalSigners == 1 || totalSigners == 2 {
-		return 1
-	} else {
-		return uint((totalSigners / 2) + 1)
-	}
-}
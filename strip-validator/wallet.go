@@ -0,0 +1,484 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/StripChain/strip-node/common"
+	"github.com/StripChain/strip-node/libs"
+	"github.com/StripChain/strip-node/libs/blockchains"
+	pb "github.com/StripChain/strip-node/libs/proto"
+	"github.com/StripChain/strip-node/util/logger"
+	tssCrypto "github.com/bnb-chain/tss-lib/v2/crypto"
+	ecdsaKeygen "github.com/bnb-chain/tss-lib/v2/ecdsa/keygen"
+	eddsaKeygen "github.com/bnb-chain/tss-lib/v2/eddsa/keygen"
+	"github.com/bnb-chain/tss-lib/v2/tss"
+	"github.com/decred/dcrd/dcrec/edwards/v2"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Wallets the validator checks operations against, such as the bridge wallet, are derived
+// from its own key shares instead of being looked up from the sequencer, which could
+// otherwise redirect deposits to addresses it controls. The public keys of a wallet are
+// cross-checked with the other signers of its key over a direct stream, and the wallet is
+// only trusted once enough signers to sign with it agree on them. A validator holding no
+// share of a wallet takes its keys from the connected signers that hold one.
+
+const walletProtocol = protocol.ID("/strip/wallet/2.0.0")
+
+const (
+	// walletCheckTimeout bounds asking the other signers for their keys of a wallet
+	walletCheckTimeout = 15 * time.Second
+	// maxWalletMessageSize bounds the queries and replies of the wallet protocol
+	maxWalletMessageSize = 4 << 10
+)
+
+// walletKeys are the public keys of a wallet (hex), from which all its addresses follow
+type walletKeys struct {
+	EcdsaPublicKey string `json:"ecdsaPublicKey"`
+	EddsaPublicKey string `json:"eddsaPublicKey"`
+}
+
+type walletQuery struct {
+	Identity      string       `json:"identity"`
+	IdentityCurve common.Curve `json:"identityCurve"`
+}
+
+// walletReply holds the keys of a wallet and the committee holding them, as known to the
+// signer replying
+type walletReply struct {
+	Keys      walletKeys `json:"keys"`
+	Signers   []string   `json:"signers,omitempty"`
+	Threshold int        `json:"threshold,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
+
+// walletAnswer is the reply of a signer to a wallet query
+type walletAnswer struct {
+	signer string
+	reply  walletReply
+	err    error
+}
+
+// verifiedWallets caches the wallets confirmed by the other signers. The keys of a wallet
+// never change, a reshare keeps its public key.
+var verifiedWallets sync.Map
+
+// walletIdentityCurve returns the identity curve of the wallets of a blockchain, the curve
+// of its keys as when the sequencer creates them
+func walletIdentityCurve(blockchainID blockchains.BlockchainID) (common.Curve, error) {
+	chain, err := blockchains.GetBlockchain(blockchainID, blockchains.Mainnet)
+	if err != nil {
+		return "", err
+	}
+	return chain.KeyCurve(), nil
+}
+
+// bridgeWallet returns the verified addresses of the bridge wallet
+func bridgeWallet() (*pb.GetAddressesResponse, error) {
+	return blockchainWallet(BridgeContractAddress, blockchains.Ethereum)
+}
+
+// blockchainWallet returns the verified addresses of the wallet of an identity on a blockchain
+func blockchainWallet(identity string, blockchainID blockchains.BlockchainID) (*pb.GetAddressesResponse, error) {
+	identityCurve, err := walletIdentityCurve(blockchainID)
+	if err != nil {
+		return nil, err
+	}
+	return verifiedWallet(identity, identityCurve)
+}
+
+// verifiedWallet derives the addresses of a wallet from the key shares of this node and
+// confirms them with the other signers of the wallet. Without a share of the wallet, the
+// keys are those the connected signers holding one agree on.
+func verifiedWallet(identity string, identityCurve common.Curve) (*pb.GetAddressesResponse, error) {
+	key := identity + "_" + string(identityCurve)
+	if wallet, ok := verifiedWallets.Load(key); ok {
+		return wallet.(*pb.GetAddressesResponse), nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), walletCheckTimeout)
+	defer cancel()
+	query := walletQuery{Identity: identity, IdentityCurve: identityCurve}
+	keys, err := ownWalletKeys(identity, identityCurve)
+	if status.Code(err) == codes.NotFound {
+		keys, err = peerWalletKeys(ctx, query)
+	} else if err == nil {
+		var signers []string
+		var threshold int
+		if signers, threshold, err = walletCommittee(identity, identityCurve); err == nil {
+			answers := queryWalletKeys(ctx, signers, query)
+			err = confirmWallet(keys, answers, len(signers), threshold)
+		}
+	}
+	if err != nil {
+		logger.Sugar().Errorw("wallet not confirmed by the other signers", "identity", identity, "identityCurve", identityCurve, "error", err)
+		return nil, err
+	}
+
+	wallet, err := keysAddresses(keys)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to derive the wallet addresses: %v", err)
+	}
+	verifiedWallets.Store(key, wallet)
+	return wallet, nil
+}
+
+// ownWalletKeys returns the public keys of a wallet from the key shares of this node, or a
+// NotFound error when it holds none
+func ownWalletKeys(identity string, identityCurve common.Curve) (walletKeys, error) {
+	keyCurves, err := walletKeyCurves(identity, identityCurve)
+	if err != nil {
+		return walletKeys{}, status.Errorf(codes.Internal, "%v", err)
+	}
+	keys := walletKeys{}
+	found := false
+	for _, keyCurve := range keyCurves {
+		share, err := GetKeyShare(identity, identityCurve, keyCurve)
+		if err != nil {
+			return walletKeys{}, status.Errorf(codes.Internal, "error retrieving key share for curve %s: %v", keyCurve, err)
+		}
+		if share == "" {
+			continue
+		}
+		publicKey, err := keySharePublicKey(keyCurve, share)
+		if err != nil {
+			return walletKeys{}, status.Errorf(codes.Internal, "key share for curve %s: %v", keyCurve, err)
+		}
+		if keyCurve == common.CurveEcdsa {
+			keys.EcdsaPublicKey = publicKey
+		} else {
+			keys.EddsaPublicKey = publicKey
+		}
+		found = true
+	}
+	if !found {
+		return walletKeys{}, status.Errorf(codes.NotFound, "no key shares found for identity %s with identitycurve %s", identity, identityCurve)
+	}
+	return keys, nil
+}
+
+// keysAddresses returns the addresses of a wallet on every registered blockchain from its
+// public keys
+func keysAddresses(keys walletKeys) (*pb.GetAddressesResponse, error) {
+	var rawKeyEcdsa *ecdsaKeygen.LocalPartySaveData
+	var rawKeyEddsa *eddsaKeygen.LocalPartySaveData
+	if keys.EcdsaPublicKey != "" {
+		data, err := hex.DecodeString(keys.EcdsaPublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ECDSA public key: %w", err)
+		}
+		pub, err := crypto.DecompressPubkey(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ECDSA public key: %w", err)
+		}
+		point, err := tssCrypto.NewECPoint(tss.S256(), pub.X, pub.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ECDSA public key: %w", err)
+		}
+		rawKeyEcdsa = &ecdsaKeygen.LocalPartySaveData{}
+		rawKeyEcdsa.ECDSAPub = point
+	}
+	if keys.EddsaPublicKey != "" {
+		data, err := hex.DecodeString(keys.EddsaPublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EDDSA public key: %w", err)
+		}
+		pub, err := edwards.ParsePubKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EDDSA public key: %w", err)
+		}
+		point, err := tssCrypto.NewECPoint(tss.Edwards(), pub.X, pub.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EDDSA public key: %w", err)
+		}
+		rawKeyEddsa = &eddsaKeygen.LocalPartySaveData{}
+		rawKeyEddsa.EDDSAPub = point
+	}
+	if rawKeyEcdsa == nil || rawKeyEddsa == nil {
+		return nil, errors.New("wallet is missing a key")
+	}
+	return walletAddresses(rawKeyEcdsa, rawKeyEddsa, nil)
+}
+
+// walletCommittee returns the signers and threshold of the keys of a wallet
+func walletCommittee(identity string, identityCurve common.Curve) ([]string, int, error) {
+	keyCurves, err := walletKeyCurves(identity, identityCurve)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, keyCurve := range keyCurves {
+		signersString, err := GetSignersForKeyShare(identity, identityCurve, keyCurve)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to read signers: %w", err)
+		}
+		if signersString == "" {
+			continue
+		}
+		signers := []string{}
+		if err := json.Unmarshal([]byte(signersString), &signers); err != nil {
+			return nil, 0, fmt.Errorf("failed to parse signers: %w", err)
+		}
+		threshold, err := GetThresholdForKeyShare(identity, identityCurve, keyCurve, len(signers))
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to read threshold: %w", err)
+		}
+		return signers, threshold, nil
+	}
+	return nil, 0, fmt.Errorf("no signers found for %s", identity)
+}
+
+// confirmWallet checks the keys the other signers derived against those of this node. Any
+// signer deriving other keys fails the check, and at least threshold+1 signers, this node
+// included, must agree so that the wallet is one the committee can actually sign for.
+// Signers that could not be reached are tolerated within that quorum.
+func confirmWallet(own walletKeys, answers []walletAnswer, signers int, threshold int) error {
+	agreed := 1
+	for _, answer := range answers {
+		if answer.err != nil {
+			logger.Sugar().Warnw("signer did not confirm wallet", "signer", answer.signer, "error", answer.err)
+			continue
+		}
+		if answer.reply.Keys != own {
+			return status.Errorf(codes.FailedPrecondition, "signer %s derives different keys for the wallet", answer.signer)
+		}
+		agreed++
+	}
+
+	quorum := min(threshold+1, signers)
+	if agreed < quorum {
+		return status.Errorf(codes.Unavailable, "only %d of the %d signers needed confirmed the wallet", agreed, quorum)
+	}
+	return nil
+}
+
+// peerWalletKeys asks the connected signers for the keys of a wallet this node holds no
+// share of. The signers holding one must all agree on its keys and committee, of which at
+// least threshold+1 must answer.
+func peerWalletKeys(ctx context.Context, query walletQuery) (walletKeys, error) {
+	if p2pHost == nil {
+		return walletKeys{}, status.Error(codes.Unavailable, "host is not initialised")
+	}
+	signers := []string{}
+	for _, id := range p2pHost.Network().Peers() {
+		signer := peerSigner(id)
+		if signer == "" || slices.Contains(signers, signer) {
+			continue
+		}
+		if registered, err := signerRegistry.IsSigner(signer); err != nil || !registered {
+			continue
+		}
+		signers = append(signers, signer)
+	}
+	return agreedWallet(queryWalletKeys(ctx, signers, query))
+}
+
+// agreedWallet returns the keys of a wallet the signers holding it agree on
+func agreedWallet(answers []walletAnswer) (walletKeys, error) {
+	var agreed *walletReply
+	count := 0
+	for _, answer := range answers {
+		if answer.err != nil {
+			continue
+		}
+		if !slices.Contains(answer.reply.Signers, answer.signer) {
+			return walletKeys{}, status.Errorf(codes.FailedPrecondition, "signer %s reports a committee it is not part of", answer.signer)
+		}
+		if agreed == nil {
+			agreed = &answer.reply
+		} else if answer.reply.Keys != agreed.Keys || answer.reply.Threshold != agreed.Threshold || !equalSigners(answer.reply.Signers, agreed.Signers) {
+			return walletKeys{}, status.Errorf(codes.FailedPrecondition, "signer %s reports a different wallet", answer.signer)
+		}
+		count++
+	}
+	if agreed == nil {
+		return walletKeys{}, status.Error(codes.Unavailable, "no signer of the wallet answered")
+	}
+
+	quorum := min(agreed.Threshold+1, len(agreed.Signers))
+	if count < quorum {
+		return walletKeys{}, status.Errorf(codes.Unavailable, "only %d of the %d signers needed confirmed the wallet", count, quorum)
+	}
+	return agreed.Keys, nil
+}
+
+// queryWalletKeys asks the other signers of a wallet for its keys, in parallel
+func queryWalletKeys(ctx context.Context, signers []string, query walletQuery) []walletAnswer {
+	answers := make(chan walletAnswer, len(signers))
+	count := 0
+	for _, signer := range signers {
+		if signer == NodePublicKey {
+			continue
+		}
+		count++
+		go func(signer string) {
+			reply, err := queryWalletKeysOf(ctx, signer, query)
+			answers <- walletAnswer{signer: signer, reply: reply, err: err}
+		}(signer)
+	}
+
+	result := make([]walletAnswer, 0, count)
+	for i := 0; i < count; i++ {
+		result = append(result, <-answers)
+	}
+	return result
+}
+
+// queryWalletKeysOf asks a signer for the keys and committee of a wallet. The stream is opened to the
+// peer ID of the signer's key, so the reply is authenticated by libp2p.
+func queryWalletKeysOf(ctx context.Context, signer string, query walletQuery) (walletReply, error) {
+	if p2pHost == nil {
+		return walletReply{}, errors.New("host is not initialised")
+	}
+	id, err := findSignerPeer(ctx, signer)
+	if err != nil {
+		return walletReply{}, err
+	}
+	s, err := p2pHost.NewStream(ctx, id, walletProtocol)
+	if err != nil {
+		signerPeers.Delete(signer)
+		return walletReply{}, fmt.Errorf("failed to open stream to %s: %w", id, err)
+	}
+	defer s.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		s.SetDeadline(deadline)
+	}
+
+	if err := json.NewEncoder(s).Encode(query); err != nil {
+		s.Reset()
+		return walletReply{}, fmt.Errorf("failed to write to %s: %w", id, err)
+	}
+	if err := s.CloseWrite(); err != nil {
+		s.Reset()
+		return walletReply{}, fmt.Errorf("failed to write to %s: %w", id, err)
+	}
+
+	var reply walletReply
+	if err := json.NewDecoder(io.LimitReader(s, maxWalletMessageSize)).Decode(&reply); err != nil {
+		s.Reset()
+		return walletReply{}, fmt.Errorf("failed to read from %s: %w", id, err)
+	}
+	if reply.Error != "" {
+		return walletReply{}, errors.New(reply.Error)
+	}
+	return reply, nil
+}
+
+// walletHandler answers the wallet queries of other signers with the keys and committee of
+// the wallet this node holds
+func (l *peerRateLimiter) walletHandler(s network.Stream) {
+	defer s.Close()
+
+	if !l.Allow(s.Conn().RemotePeer()) {
+		s.Reset()
+		return
+	}
+
+	s.SetDeadline(time.Now().Add(walletCheckTimeout))
+	var query walletQuery
+	if err := json.NewDecoder(io.LimitReader(s, maxWalletMessageSize)).Decode(&query); err != nil {
+		logger.Sugar().Warnw("failed to read wallet query", "peer", s.Conn().RemotePeer(), "error", err)
+		s.Reset()
+		return
+	}
+
+	reply := walletReply{}
+	keys, err := ownWalletKeys(query.Identity, query.IdentityCurve)
+	if err == nil {
+		reply.Keys = keys
+		reply.Signers, reply.Threshold, err = walletCommittee(query.Identity, query.IdentityCurve)
+	}
+	if err != nil {
+		reply.Error = err.Error()
+	}
+	if err := json.NewEncoder(s).Encode(reply); err != nil {
+		logger.Sugar().Warnw("failed to answer wallet query", "peer", s.Conn().RemotePeer(), "error", err)
+		s.Reset()
+	}
+}
+
+// walletAddress returns the address of a wallet on a blockchain and network, falling back
+// to the mainnet address for chains whose addresses do not depend on the network
+func walletAddress(wallet *pb.GetAddressesResponse, blockchainID blockchains.BlockchainID, networkType blockchains.NetworkType) (string, error) {
+	protoID, err := libs.BlockchainsIDToProto(blockchainID)
+	if err != nil {
+		return "", err
+	}
+	addresses, ok := wallet.Addresses[int32(protoID)]
+	if !ok {
+		return "", fmt.Errorf("wallet has no address on %s", blockchainID)
+	}
+	if detail, ok := addresses.NetworkAddresses[pb.NetworkType_value[string(networkType)]]; ok {
+		return detail.Address, nil
+	}
+	if detail, ok := addresses.NetworkAddresses[int32(pb.NetworkType_MAINNET)]; ok {
+		return detail.Address, nil
+	}
+	return "", fmt.Errorf("wallet has no address on %s %s", blockchainID, networkType)
+}
+
+// verifyBridgeDestination checks that the transaction of an operation sending to the bridge
+// pays the bridge wallet
+func verifyBridgeDestination(chain blockchains.IBlockchain, operation libs.Operation) error {
+	if operation.SerializedTxn == nil || *operation.SerializedTxn == "" {
+		return status.Error(codes.InvalidArgument, "send to bridge operation has no serialized transaction")
+	}
+
+	wallet, err := bridgeWallet()
+	if err != nil {
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+		return status.Errorf(codes.Internal, "failed to derive the bridge wallet: %v", err)
+	}
+	expected, err := walletAddress(wallet, operation.BlockchainID, operation.NetworkType)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to derive the bridge wallet: %v", err)
+	}
+
+	destination, _, err := chain.ExtractDestinationAddress(*operation.SerializedTxn)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "failed to extract the destination of the transaction: %v", err)
+	}
+	if !sameAddress(destination, expected) {
+		logger.Sugar().Errorw("Send to bridge does not pay the bridge wallet", "chain", operation.BlockchainID, "expected", expected, "destination", destination)
+		return status.Error(codes.InvalidArgument, "transaction does not pay the bridge wallet")
+	}
+	return nil
+}
+
+// verifyTrustSet checks that the data to sign of a trust set operation is a TrustSet of the
+// intent's wallet for exactly the token and limit of its metadata. The data to sign is set
+// by the sequencer and not covered by the intent signature.
+func verifyTrustSet(chain blockchains.IBlockchain, identity string, identityCurve common.Curve, operation libs.Operation, metadata TrustSetMetadata) error {
+	ripple, ok := chain.(*blockchains.RippleBlockchain)
+	if !ok {
+		return status.Errorf(codes.InvalidArgument, "trust set is not supported on %s", operation.BlockchainID)
+	}
+
+	wallet, err := deriveAddresses(identity, identityCurve, operation.DerivationPath)
+	if err != nil {
+		return err
+	}
+	account, err := walletAddress(wallet, operation.BlockchainID, operation.NetworkType)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to derive the wallet: %v", err)
+	}
+
+	if err := ripple.VerifyTrustSetMessage(operation.SolverDataToSign, account, metadata.Token, metadata.Limit); err != nil {
+		logger.Sugar().Errorw("Data to sign is not the requested trust set", "account", account, "token", metadata.Token, "limit", metadata.Limit, "error", err)
+		return status.Errorf(codes.InvalidArgument, "data to sign is not the requested trust set: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/StripChain/strip-node/libs/blockchains"
+	pb "github.com/StripChain/strip-node/libs/proto"
+	"github.com/decred/dcrd/dcrec/edwards/v2"
+	"github.com/ethereum/go-ethereum/crypto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestConfirmWallet(t *testing.T) {
+	own := walletKeys{EcdsaPublicKey: "02abc", EddsaPublicKey: "ed01"}
+	agree := func(signer string) walletAnswer {
+		return walletAnswer{signer: signer, reply: walletReply{Keys: own}}
+	}
+	offline := func(signer string) walletAnswer {
+		return walletAnswer{signer: signer, err: errors.New("signer not found")}
+	}
+
+	// Threshold 2 of 4 signers needs 3 of them, this node included
+	if err := confirmWallet(own, []walletAnswer{agree("0xb"), agree("0xc"), offline("0xd")}, 4, 2); err != nil {
+		t.Errorf("confirmWallet = %v, want a quorum", err)
+	}
+	if err := confirmWallet(own, []walletAnswer{agree("0xb"), offline("0xc"), offline("0xd")}, 4, 2); status.Code(err) != codes.Unavailable {
+		t.Errorf("confirmWallet = %v, want no quorum", err)
+	}
+
+	// A signer deriving other keys fails the check whatever the others answer
+	other := walletAnswer{signer: "0xd", reply: walletReply{Keys: walletKeys{EcdsaPublicKey: "02def", EddsaPublicKey: "ed01"}}}
+	if err := confirmWallet(own, []walletAnswer{agree("0xb"), agree("0xc"), other}, 4, 2); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("confirmWallet = %v, want a mismatch", err)
+	}
+	// Keys are compared exactly
+	other = walletAnswer{signer: "0xd", reply: walletReply{Keys: walletKeys{EcdsaPublicKey: "02ABC", EddsaPublicKey: "ed01"}}}
+	if err := confirmWallet(own, []walletAnswer{agree("0xb"), agree("0xc"), other}, 4, 2); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("confirmWallet = %v, want a mismatch", err)
+	}
+
+	// A committee smaller than threshold+1 needs all of its signers
+	if err := confirmWallet(own, []walletAnswer{agree("0xb")}, 2, 2); err != nil {
+		t.Errorf("confirmWallet = %v, want a quorum", err)
+	}
+}
+
+func TestAgreedWallet(t *testing.T) {
+	keys := walletKeys{EcdsaPublicKey: "02abc", EddsaPublicKey: "ed01"}
+	committee := []string{"0xa", "0xb", "0xc", "0xd"}
+	holder := func(signer string) walletAnswer {
+		return walletAnswer{signer: signer, reply: walletReply{Keys: keys, Signers: committee, Threshold: 2}}
+	}
+	// Signers of other wallets do not hold a share
+	outsider := walletAnswer{signer: "0xe", err: errors.New("no key shares found")}
+
+	got, err := agreedWallet([]walletAnswer{holder("0xa"), outsider, holder("0xb"), holder("0xc")})
+	if err != nil || got != keys {
+		t.Errorf("agreedWallet = %+v, %v, want %+v", got, err, keys)
+	}
+	// Threshold 2 needs 3 holders
+	if _, err := agreedWallet([]walletAnswer{holder("0xa"), holder("0xb"), outsider}); status.Code(err) != codes.Unavailable {
+		t.Errorf("agreedWallet = %v, want no quorum", err)
+	}
+	if _, err := agreedWallet([]walletAnswer{outsider}); status.Code(err) != codes.Unavailable {
+		t.Errorf("agreedWallet = %v, want no quorum", err)
+	}
+
+	// Holders must agree on the keys and the committee
+	other := holder("0xd")
+	other.reply.Keys.EcdsaPublicKey = "02def"
+	if _, err := agreedWallet([]walletAnswer{holder("0xa"), holder("0xb"), holder("0xc"), other}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("agreedWallet = %v, want a mismatch", err)
+	}
+	other = holder("0xd")
+	other.reply.Threshold = 1
+	if _, err := agreedWallet([]walletAnswer{holder("0xa"), holder("0xb"), holder("0xc"), other}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("agreedWallet = %v, want a mismatch", err)
+	}
+	// A signer only answers for a committee it is part of
+	forged := holder("0xe")
+	if _, err := agreedWallet([]walletAnswer{holder("0xa"), holder("0xb"), holder("0xc"), forged}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("agreedWallet = %v, want a mismatch", err)
+	}
+}
+
+func TestKeysAddresses(t *testing.T) {
+	ecdsaKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	eddsaKey, err := edwards.GeneratePrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := walletKeys{
+		EcdsaPublicKey: hex.EncodeToString(crypto.CompressPubkey(&ecdsaKey.PublicKey)),
+		EddsaPublicKey: hex.EncodeToString(eddsaKey.PubKey().Serialize()),
+	}
+
+	wallet, err := keysAddresses(keys)
+	if err != nil {
+		t.Fatalf("keysAddresses: %v", err)
+	}
+	if want := strings.ToLower(crypto.PubkeyToAddress(ecdsaKey.PublicKey).Hex()); wallet.EcdsaAddress != want {
+		t.Errorf("ECDSA address = %s, want %s", wallet.EcdsaAddress, want)
+	}
+	if wallet.EddsaAddress != keys.EddsaPublicKey {
+		t.Errorf("EDDSA address = %s, want %s", wallet.EddsaAddress, keys.EddsaPublicKey)
+	}
+
+	if _, err := keysAddresses(walletKeys{EcdsaPublicKey: keys.EcdsaPublicKey}); err == nil {
+		t.Error("wallet without an EDDSA key was accepted")
+	}
+}
+
+func TestWalletAddress(t *testing.T) {
+	wallet := &pb.GetAddressesResponse{Addresses: map[int32]*pb.BlockchainAddressMap{}}
+	addAddressDetail(wallet, int32(pb.BlockchainID_BITCOIN), pb.NetworkType_MAINNET, "bc1main")
+	addAddressDetail(wallet, int32(pb.BlockchainID_BITCOIN), pb.NetworkType_TESTNET, "tb1test")
+	addAddressDetail(wallet, int32(pb.BlockchainID_ETHEREUM), pb.NetworkType_MAINNET, "0xabc")
+
+	tests := []struct {
+		blockchainID blockchains.BlockchainID
+		networkType  blockchains.NetworkType
+		want         string
+	}{
+		{blockchains.Bitcoin, blockchains.Mainnet, "bc1main"},
+		{blockchains.Bitcoin, blockchains.Testnet, "tb1test"},
+		// EVM addresses do not depend on the network
+		{blockchains.Ethereum, blockchains.Testnet, "0xabc"},
+	}
+	for _, test := range tests {
+		got, err := walletAddress(wallet, test.blockchainID, test.networkType)
+		if err != nil || got != test.want {
+			t.Errorf("walletAddress(%s, %s) = %q, %v, want %q", test.blockchainID, test.networkType, got, err, test.want)
+		}
+	}
+
+	if _, err := walletAddress(wallet, blockchains.Solana, blockchains.Mainnet); err == nil {
+		t.Error("walletAddress should fail for a chain the wallet has no address on")
+	}
+}